@@ -20,7 +20,9 @@ import (
 	"github.com/containous/flaeg"
 	"github.com/containous/mux"
 	"github.com/containous/staert"
+	"github.com/containous/traefik/audit"
 	"github.com/containous/traefik/cluster"
+	"github.com/containous/traefik/encryption"
 	"github.com/containous/traefik/log"
 	acmeprovider "github.com/containous/traefik/provider/acme"
 	"github.com/containous/traefik/safe"
@@ -53,6 +55,7 @@ type ACME struct {
 	TLSChallenge          *acmeprovider.TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge"`
 	ACMELogging           bool                        `description:"Enable debug logging of ACME actions."`
 	OverrideCertificates  bool                        `description:"Enable to override certificates in key-value store when using storeconfig"`
+	StorageEncryption     *encryption.Config          `description:"Encrypt the storage of the account and of the certificates"`
 	client                *acme.Client
 	store                 cluster.Store
 	challengeHTTPProvider *challengeHTTPProvider
@@ -139,14 +142,20 @@ func (a *ACME) CreateClusterConfig(leadership *cluster.Leadership, tlsConfig *tl
 		return nil
 	}
 
-	datastore, err := cluster.NewDataStore(
+	cipher, err := encryption.New(a.StorageEncryption, nil)
+	if err != nil {
+		return err
+	}
+
+	datastore, err := cluster.NewEncryptedDataStore(
 		leadership.Pool.Ctx(),
 		staert.KvSource{
 			Store:  leadership.Store,
 			Prefix: a.Storage,
 		},
 		&Account{},
-		listener)
+		listener,
+		cipher)
 	if err != nil {
 		return err
 	}
@@ -224,6 +233,7 @@ func (a *ACME) leadershipListener(elected bool) error {
 			}
 
 			account.Registration = reg
+			audit.Record(audit.Entry{Category: audit.CategoryACME, Action: "register", User: account.Email, Resource: reg.URI, Details: map[string]string{"caServer": a.CAServer}})
 		}
 
 		err = transaction.Commit(account)
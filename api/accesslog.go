@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/accesslog"
+)
+
+// AccessLogHandler exposes the last access logs kept in memory.
+type AccessLogHandler struct {
+	Buffer *accesslog.Buffer
+}
+
+// Append adds the access log route on a router.
+func (h AccessLogHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodGet).Path("/api/accesslog").HandlerFunc(h.getAccessLogHandler)
+}
+
+// getAccessLogHandler returns the last access logs, from the newest to the oldest.
+// The frontend, status and limit query parameters select the access logs of a router,
+// with a status in the given codes, ranges or classes (e.g. 404,500-504,5xx), and their maximum number.
+func (h AccessLogHandler) getAccessLogHandler(rw http.ResponseWriter, request *http.Request) {
+	filter := accesslog.BufferFilter{RouterName: request.URL.Query().Get("frontend")}
+
+	if status := request.URL.Query().Get("status"); len(status) > 0 {
+		statusCodes, err := accesslog.ParseStatusFilter(status)
+		if err != nil {
+			http.Error(rw, "invalid status", http.StatusBadRequest)
+			return
+		}
+		filter.StatusCodes = statusCodes
+	}
+
+	limit, err := queryInt(request, "limit")
+	if err != nil {
+		http.Error(rw, "invalid limit", http.StatusBadRequest)
+		return
+	}
+	filter.Limit = int(limit)
+
+	err = templateRenderer.JSON(rw, http.StatusOK, h.Buffer.Entries(filter))
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/middlewares/accesslog"
+	"github.com/containous/traefik/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogHandler(t *testing.T) {
+	buffer := accesslog.NewBuffer(&types.AccessLogBuffer{Size: 10})
+	buffer.Add(logrus.Fields{accesslog.RouterName: "foo", accesslog.DownstreamStatus: 200, accesslog.RequestPath: "/1"})
+	buffer.Add(logrus.Fields{accesslog.RouterName: "bar", accesslog.DownstreamStatus: 502, accesslog.RequestPath: "/2"})
+	buffer.Add(logrus.Fields{accesslog.RouterName: "foo", accesslog.DownstreamStatus: 503, accesslog.RequestPath: "/3"})
+	buffer.Add(logrus.Fields{accesslog.RouterName: "foo", accesslog.DownstreamStatus: 404, accesslog.RequestPath: "/4"})
+
+	testCases := []struct {
+		desc               string
+		path               string
+		expectedStatusCode int
+		expectedPaths      []string
+	}{
+		{
+			desc:               "all",
+			path:               "/api/accesslog",
+			expectedStatusCode: http.StatusOK,
+			expectedPaths:      []string{"/4", "/3", "/2", "/1"},
+		},
+		{
+			desc:               "frontend",
+			path:               "/api/accesslog?frontend=foo",
+			expectedStatusCode: http.StatusOK,
+			expectedPaths:      []string{"/4", "/3", "/1"},
+		},
+		{
+			desc:               "status class",
+			path:               "/api/accesslog?status=5xx",
+			expectedStatusCode: http.StatusOK,
+			expectedPaths:      []string{"/3", "/2"},
+		},
+		{
+			desc:               "frontend and status codes",
+			path:               "/api/accesslog?frontend=foo&status=200,404",
+			expectedStatusCode: http.StatusOK,
+			expectedPaths:      []string{"/4", "/1"},
+		},
+		{
+			desc:               "limit",
+			path:               "/api/accesslog?limit=1",
+			expectedStatusCode: http.StatusOK,
+			expectedPaths:      []string{"/4"},
+		},
+		{
+			desc:               "no match",
+			path:               "/api/accesslog?frontend=baz",
+			expectedStatusCode: http.StatusOK,
+			expectedPaths:      []string{},
+		},
+		{
+			desc:               "invalid status",
+			path:               "/api/accesslog?status=9xx",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "invalid limit",
+			path:               "/api/accesslog?limit=foo",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router := mux.NewRouter()
+			AccessLogHandler{Buffer: buffer}.Append(router)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			require.Equal(t, test.expectedStatusCode, recorder.Code)
+			if test.expectedPaths == nil {
+				return
+			}
+
+			var entries []map[string]interface{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+
+			paths := []string{}
+			for _, entry := range entries {
+				paths = append(paths, entry[accesslog.RequestPath].(string))
+			}
+			assert.Equal(t, test.expectedPaths, paths)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/bluegreen"
+	"github.com/containous/traefik/log"
+)
+
+// BlueGreenHandler exposes the live color of the blue/green services, and switches them.
+type BlueGreenHandler struct {
+	Switches *bluegreen.Registry
+}
+
+type switchRepresentation struct {
+	bluegreen.Status
+	Previous string `json:"previous"`
+	Drained  *bool  `json:"drained,omitempty"`
+}
+
+// Append adds the blue/green routes on a router.
+func (h BlueGreenHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodGet).Path("/api/bluegreen").HandlerFunc(h.getStatusesHandler)
+	router.Methods(http.MethodGet).Path("/api/services/{service}/bluegreen").HandlerFunc(h.getStatusHandler)
+	router.Methods(http.MethodPost).Path("/api/services/{service}/switch").HandlerFunc(h.switchHandler)
+}
+
+func (h BlueGreenHandler) getStatusesHandler(rw http.ResponseWriter, request *http.Request) {
+	err := templateRenderer.JSON(rw, http.StatusOK, h.Switches.Statuses())
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h BlueGreenHandler) getStatusHandler(rw http.ResponseWriter, request *http.Request) {
+	status, err := h.Switches.Get(mux.Vars(request)["service"])
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = templateRenderer.JSON(rw, http.StatusOK, status)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// switchHandler makes the color given by the color parameter live, or flips the colors without it.
+// With the drain parameter, it waits up to the given duration for the requests in flight on the previous color to finish.
+func (h BlueGreenHandler) switchHandler(rw http.ResponseWriter, request *http.Request) {
+	serviceName := mux.Vars(request)["service"]
+
+	var drainTimeout time.Duration
+	if value := request.URL.Query().Get("drain"); len(value) > 0 {
+		var err error
+		drainTimeout, err = time.ParseDuration(value)
+		if err != nil {
+			http.Error(rw, "invalid drain duration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	previous, err := h.Switches.Flip(serviceName, request.URL.Query().Get("color"))
+	switch err {
+	case nil:
+	case bluegreen.ErrServiceNotFound:
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	case bluegreen.ErrInvalidColor:
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	logger := log.FromContext(request.Context())
+	representation := switchRepresentation{Previous: previous}
+
+	if drainTimeout > 0 {
+		ctx, cancel := context.WithTimeout(request.Context(), drainTimeout)
+		drained := h.Switches.WaitDrained(ctx, serviceName, previous) == nil
+		cancel()
+
+		representation.Drained = &drained
+	}
+
+	representation.Status, err = h.Switches.Get(serviceName)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	logger.Infof("Switch of the service %s from %s to %s requested", serviceName, previous, representation.Live)
+
+	err = templateRenderer.JSON(rw, http.StatusOK, representation)
+	if err != nil {
+		logger.Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
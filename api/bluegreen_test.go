@@ -0,0 +1,114 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/bluegreen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlueGreenHandler(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		method             string
+		path               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc:               "statuses",
+			method:             http.MethodGet,
+			path:               "/api/bluegreen",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"foo":{"live":"blue","inFlight":{"blue":0,"green":0}}}`,
+		},
+		{
+			desc:               "status",
+			method:             http.MethodGet,
+			path:               "/api/services/foo/bluegreen",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"live":"blue","inFlight":{"blue":0,"green":0}}`,
+		},
+		{
+			desc:               "status of an unknown service",
+			method:             http.MethodGet,
+			path:               "/api/services/bar/bluegreen",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "flip",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/switch",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"live":"green","previous":"blue","inFlight":{"blue":0,"green":0}}`,
+		},
+		{
+			desc:               "switch to the live color",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/switch?color=blue",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"live":"blue","previous":"blue","inFlight":{"blue":0,"green":0}}`,
+		},
+		{
+			desc:               "switch with drain",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/switch?color=green&drain=1s",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"live":"green","previous":"blue","drained":true,"inFlight":{"blue":0,"green":0}}`,
+		},
+		{
+			desc:               "switch with an invalid drain",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/switch?drain=foo",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "switch to an invalid color",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/switch?color=red",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "switch an unknown service",
+			method:             http.MethodPost,
+			path:               "/api/services/bar/switch",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			switches := bluegreen.NewRegistry()
+			switches.Switch("foo", bluegreen.Blue)
+
+			router := mux.NewRouter()
+			BlueGreenHandler{Switches: switches}.Append(router)
+
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedStatusCode, resp.StatusCode)
+
+			if len(test.expectedBody) > 0 {
+				body, err := ioutil.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
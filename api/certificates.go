@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/certmonitor"
+	"github.com/containous/traefik/log"
+)
+
+// CertificatesHandler exposes the results of the last check of the served certificates.
+type CertificatesHandler struct {
+	Monitor *certmonitor.Monitor
+}
+
+// Append adds the certificates route on a router.
+func (h CertificatesHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodGet).Path("/api/certificates").HandlerFunc(h.getCertificatesHandler)
+}
+
+func (h CertificatesHandler) getCertificatesHandler(rw http.ResponseWriter, request *http.Request) {
+	err := templateRenderer.JSON(rw, http.StatusOK, h.Monitor.Statuses())
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/drain"
+	"github.com/containous/traefik/log"
+)
+
+// DrainHandler exposes the drain and undrain of the servers of the services.
+type DrainHandler struct {
+	Drains *drain.Registry
+}
+
+// Append adds the drain routes on a router.
+func (h DrainHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodGet).Path("/api/drains").HandlerFunc(h.getDrainedHandler)
+	router.Methods(http.MethodPost).Path("/api/services/{service}/drain").HandlerFunc(h.drainHandler)
+	router.Methods(http.MethodPost).Path("/api/services/{service}/undrain").HandlerFunc(h.undrainHandler)
+}
+
+func (h DrainHandler) getDrainedHandler(rw http.ResponseWriter, request *http.Request) {
+	err := templateRenderer.JSON(rw, http.StatusOK, h.Drains.Drained())
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h DrainHandler) drainHandler(rw http.ResponseWriter, request *http.Request) {
+	h.apply(rw, request, "Drain", h.Drains.Drain)
+}
+
+func (h DrainHandler) undrainHandler(rw http.ResponseWriter, request *http.Request) {
+	h.apply(rw, request, "Undrain", h.Drains.Undrain)
+}
+
+func (h DrainHandler) apply(rw http.ResponseWriter, request *http.Request, action string, fn func(serviceName, serverURL string) error) {
+	serviceName := mux.Vars(request)["service"]
+
+	serverURL := request.URL.Query().Get("url")
+	if len(serverURL) == 0 {
+		http.Error(rw, "missing server url", http.StatusBadRequest)
+		return
+	}
+
+	switch err := fn(serviceName, serverURL); err {
+	case nil:
+	case drain.ErrServiceNotFound, drain.ErrServerNotFound:
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	default:
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.FromContext(request.Context()).Infof("%s of the server %s of the service %s requested", action, serverURL, serviceName)
+	rw.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/drain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+type fakeBalancer struct{}
+
+func (fakeBalancer) RemoveServer(u *url.URL) error { return nil }
+
+func (fakeBalancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error { return nil }
+
+func TestDrainHandler(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		method             string
+		path               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc:               "drained servers",
+			method:             http.MethodGet,
+			path:               "/api/drains",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"foo":["http://10.0.0.2:80"]}`,
+		},
+		{
+			desc:               "drain",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/drain?url=http://10.0.0.1:80",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			desc:               "drain without url",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/drain",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "drain a server of an unknown service",
+			method:             http.MethodPost,
+			path:               "/api/services/bar/drain?url=http://10.0.0.1:80",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "drain an unknown server",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/drain?url=http://10.0.0.3:80",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "undrain",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/undrain?url=http://10.0.0.2:80",
+			expectedStatusCode: http.StatusNoContent,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			drains := drain.NewRegistry()
+			drains.SetServices(map[string]drain.Service{
+				"foo": {
+					Balancers: []drain.Balancer{fakeBalancer{}},
+					Servers: []config.Server{
+						{URL: "http://10.0.0.1:80", Weight: 1},
+						{URL: "http://10.0.0.2:80", Weight: 1},
+					},
+				},
+			})
+			require.NoError(t, drains.Drain("foo", "http://10.0.0.2:80"))
+
+			router := mux.NewRouter()
+			DrainHandler{Drains: drains}.Append(router)
+
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedStatusCode, resp.StatusCode)
+
+			if len(test.expectedBody) > 0 {
+				body, err := ioutil.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
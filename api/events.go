@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/events"
+	"github.com/containous/traefik/log"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// eventsBufferSize is the number of events buffered for a client of the stream.
+	eventsBufferSize = 100
+	// eventsKeepAlive is the interval of the keep-alive messages of the stream, so the idle connections are not closed by the proxies.
+	eventsKeepAlive = 15 * time.Second
+)
+
+var eventsUpgrader = websocket.Upgrader{}
+
+// EventsHandler streams the events of Traefik, as Server-Sent Events or through a WebSocket.
+type EventsHandler struct {
+	Broker *events.Broker
+}
+
+// Append adds the events route on a router.
+func (h EventsHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodGet).Path("/api/events").HandlerFunc(h.getEventsHandler)
+}
+
+func (h EventsHandler) getEventsHandler(rw http.ResponseWriter, request *http.Request) {
+	types := make(map[string]bool)
+	for _, value := range request.URL.Query()["type"] {
+		for _, eventType := range strings.Split(value, ",") {
+			types[strings.TrimSpace(eventType)] = true
+		}
+	}
+
+	subscriber, unsubscribe := h.Broker.Subscribe(eventsBufferSize)
+	defer unsubscribe()
+
+	// next returns the next event of the requested types, nil when it is time to keep the stream alive,
+	// and false when the stream is done.
+	next := func(keepAlive <-chan time.Time, done <-chan struct{}) (*events.Event, bool) {
+		for {
+			select {
+			case event := <-subscriber:
+				if len(types) == 0 || types[event.Type] {
+					return &event, true
+				}
+			case <-keepAlive:
+				return nil, true
+			case <-done:
+				return nil, false
+			}
+		}
+	}
+
+	if websocket.IsWebSocketUpgrade(request) {
+		h.streamWebSocket(rw, request, next)
+		return
+	}
+
+	h.streamSSE(rw, request, next)
+}
+
+func (h EventsHandler) streamSSE(rw http.ResponseWriter, request *http.Request, next func(<-chan time.Time, <-chan struct{}) (*events.Event, bool)) {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(eventsKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		event, ok := next(ticker.C, request.Context().Done())
+		if !ok {
+			return
+		}
+
+		if event == nil {
+			_, err := fmt.Fprint(rw, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+			continue
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			log.FromContext(request.Context()).Error(err)
+			continue
+		}
+
+		if _, err := fmt.Fprintf(rw, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func (h EventsHandler) streamWebSocket(rw http.ResponseWriter, request *http.Request, next func(<-chan time.Time, <-chan struct{}) (*events.Event, bool)) {
+	conn, err := eventsUpgrader.Upgrade(rw, request, nil)
+	if err != nil {
+		log.FromContext(request.Context()).Debugf("Unable to upgrade the events stream to a WebSocket: %v", err)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Reads the messages of the client to process the close and ping messages.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(eventsKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		event, ok := next(ticker.C, closed)
+		if !ok {
+			return
+		}
+
+		if event == nil {
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second))
+		} else {
+			err = conn.WriteJSON(event)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/events"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publishUntilReceived publishes a probe event until the stream gets it, as the subscription of the client is asynchronous.
+func publishUntilReceived(broker *events.Broker, received <-chan events.Event, eventType string) events.Event {
+	for {
+		broker.Publish(eventType, nil)
+		select {
+		case event := <-received:
+			return event
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestEventsHandlerSSE(t *testing.T) {
+	broker := events.NewBroker()
+
+	router := mux.NewRouter()
+	EventsHandler{Broker: broker}.Append(router)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/events?type=" + events.ServerDown + "," + events.ConfigurationReloaded)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	received := make(chan events.Event, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if !strings.HasPrefix(scanner.Text(), "data: ") {
+				continue
+			}
+
+			event := events.Event{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(scanner.Text(), "data: ")), &event); err == nil {
+				received <- event
+			}
+		}
+	}()
+
+	publishUntilReceived(broker, received, events.ConfigurationReloaded)
+
+	// The events of the other types are filtered out.
+	broker.Publish(events.ServerUp, map[string]string{"url": "http://10.0.0.1"})
+	broker.Publish(events.ServerDown, map[string]string{"url": "http://10.0.0.1"})
+
+	var event events.Event
+	for event = range received {
+		if event.Type != events.ConfigurationReloaded {
+			break
+		}
+	}
+	assert.Equal(t, events.ServerDown, event.Type)
+	assert.Equal(t, map[string]string{"url": "http://10.0.0.1"}, event.Attributes)
+}
+
+func TestEventsHandlerWebSocket(t *testing.T) {
+	broker := events.NewBroker()
+
+	router := mux.NewRouter()
+	EventsHandler{Broker: broker}.Append(router)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/events", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	received := make(chan events.Event, 10)
+	go func() {
+		for {
+			event := events.Event{}
+			if err := conn.ReadJSON(&event); err != nil {
+				return
+			}
+			received <- event
+		}
+	}()
+
+	event := publishUntilReceived(broker, received, events.CircuitBreakerTripped)
+	assert.Equal(t, events.CircuitBreakerTripped, event.Type)
+}
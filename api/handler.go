@@ -5,9 +5,17 @@ import (
 	"net/http"
 
 	"github.com/containous/mux"
+	"github.com/containous/traefik/bluegreen"
+	"github.com/containous/traefik/certmonitor"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/history"
+	"github.com/containous/traefik/drain"
+	"github.com/containous/traefik/events"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/accesslog"
+	"github.com/containous/traefik/namespace"
 	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/tap"
 	"github.com/containous/traefik/types"
 	"github.com/containous/traefik/version"
 	"github.com/elazarl/go-bindata-assetfs"
@@ -56,6 +64,13 @@ type Handler struct {
 	Stats                 *thoasstats.Stats
 	// StatsRecorder         *middlewares.StatsRecorder // FIXME stats
 	DashboardAssets *assetfs.AssetFS
+	History         *history.History
+	Drains          *drain.Registry
+	Switches        *bluegreen.Registry
+	Taps            *tap.Registry
+	Namespaces      *namespace.Registry
+	Certificates    *certmonitor.Monitor
+	AccessLogs      *accesslog.Buffer
 }
 
 var templateRenderer jsonRenderer = render.New(render.Options{Directory: "nowhere"})
@@ -83,6 +98,36 @@ func (p Handler) Append(router *mux.Router) {
 	// health route
 	//router.Methods(http.MethodGet).Path("/health").HandlerFunc(p.getHealthHandler)
 
+	if p.History != nil {
+		HistoryHandler{History: p.History}.Append(router)
+	}
+
+	if p.Drains != nil {
+		DrainHandler{Drains: p.Drains}.Append(router)
+	}
+
+	if p.Switches != nil {
+		BlueGreenHandler{Switches: p.Switches}.Append(router)
+	}
+
+	if p.Taps != nil {
+		TapHandler{Taps: p.Taps}.Append(router)
+	}
+
+	EventsHandler{Broker: events.Default()}.Append(router)
+
+	if p.AccessLogs != nil {
+		AccessLogHandler{Buffer: p.AccessLogs}.Append(router)
+	}
+
+	if p.Certificates != nil {
+		CertificatesHandler{Monitor: p.Certificates}.Append(router)
+	}
+
+	if p.Namespaces != nil {
+		router.Methods(http.MethodGet).Path("/api/namespaces").HandlerFunc(p.getNamespacesHandler)
+	}
+
 	version.Handler{}.Append(router)
 
 	if p.Dashboard {
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config/history"
+	"github.com/containous/traefik/log"
+)
+
+// HistoryHandler exposes the history of the applied dynamic configurations.
+type HistoryHandler struct {
+	History *history.History
+}
+
+// Append adds the history routes on a router.
+func (h HistoryHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodGet).Path("/api/history").HandlerFunc(h.getVersionsHandler)
+	router.Methods(http.MethodGet).Path("/api/history/diff").HandlerFunc(h.getDiffHandler)
+	router.Methods(http.MethodPost).Path("/api/history/{version:[0-9]+}/rollback").HandlerFunc(h.rollbackHandler)
+}
+
+func (h HistoryHandler) getVersionsHandler(rw http.ResponseWriter, request *http.Request) {
+	err := templateRenderer.JSON(rw, http.StatusOK, h.History.List())
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h HistoryHandler) getDiffHandler(rw http.ResponseWriter, request *http.Request) {
+	from, err := strconv.ParseUint(request.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		http.Error(rw, "invalid from version", http.StatusBadRequest)
+		return
+	}
+
+	to, err := strconv.ParseUint(request.URL.Query().Get("to"), 10, 64)
+	if err != nil {
+		http.Error(rw, "invalid to version", http.StatusBadRequest)
+		return
+	}
+
+	changes, err := h.History.Diff(from, to)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = templateRenderer.JSON(rw, http.StatusOK, changes)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h HistoryHandler) rollbackHandler(rw http.ResponseWriter, request *http.Request) {
+	version, err := strconv.ParseUint(mux.Vars(request)["version"], 10, 64)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := h.History.Get(version); !ok {
+		http.NotFound(rw, request)
+		return
+	}
+
+	if err := h.History.Rollback(version); err != nil {
+		http.Error(rw, err.Error(), http.StatusConflict)
+		return
+	}
+
+	log.FromContext(request.Context()).Warnf("Rollback to the dynamic configuration version %d requested", version)
+	rw.WriteHeader(http.StatusAccepted)
+}
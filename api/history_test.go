@@ -0,0 +1,88 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryHandler(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		method             string
+		path               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc:               "diff of two versions",
+			method:             http.MethodGet,
+			path:               "/api/history/diff?from=1&to=2",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `[{"provider":"file","kind":"router","name":"foo","action":"modified","from":{"entryPoints":null,"rule":"Host:foo"},"to":{"entryPoints":null,"rule":"Host:bar"}}]`,
+		},
+		{
+			desc:               "diff of an unknown version",
+			method:             http.MethodGet,
+			path:               "/api/history/diff?from=1&to=3",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "diff without version",
+			method:             http.MethodGet,
+			path:               "/api/history/diff",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "rollback",
+			method:             http.MethodPost,
+			path:               "/api/history/1/rollback",
+			expectedStatusCode: http.StatusAccepted,
+		},
+		{
+			desc:               "rollback to an unknown version",
+			method:             http.MethodPost,
+			path:               "/api/history/3/rollback",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h := history.New(10)
+			h.Add("file", config.Configurations{"file": {Routers: map[string]*config.Router{"foo": {Rule: "Host:foo"}}}})
+			h.Add("file", config.Configurations{"file": {Routers: map[string]*config.Router{"foo": {Rule: "Host:bar"}}}})
+
+			router := mux.NewRouter()
+			HistoryHandler{History: h}.Append(router)
+
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedStatusCode, resp.StatusCode)
+
+			if len(test.expectedBody) > 0 {
+				body, err := ioutil.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/audit"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/namespace"
+	"github.com/containous/traefik/safe"
+)
+
+// NamespaceRepresentation is the usage of a namespace, along with the configurations of its providers.
+type NamespaceRepresentation struct {
+	namespace.Usage
+	Name           string                `json:"name"`
+	Configurations config.Configurations `json:"configurations,omitempty"`
+}
+
+// NamespaceHandler exposes the configurations of a namespace to the holders of its tokens.
+// Its routes are authenticated by the tokens, instead of the middlewares of the API.
+type NamespaceHandler struct {
+	Namespaces            *namespace.Registry
+	CurrentConfigurations *safe.Safe
+}
+
+// Append adds the namespace routes on a router.
+func (h NamespaceHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodGet).Path("/api/namespaces/{namespace}").HandlerFunc(h.getNamespaceHandler)
+}
+
+func (h NamespaceHandler) getNamespaceHandler(rw http.ResponseWriter, request *http.Request) {
+	name := mux.Vars(request)["namespace"]
+
+	token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if len(token) == 0 || token == request.Header.Get("Authorization") {
+		audit.RecordRequest(request, audit.CategoryAuthentication, "api.token_missing", "", name)
+		rw.Header().Set("WWW-Authenticate", `Bearer realm="traefik"`)
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	// An unknown namespace is forbidden as well, not to disclose the names of the namespaces.
+	if !h.Namespaces.Authorize(name, token) {
+		audit.RecordRequest(request, audit.CategoryAuthentication, "api.token_invalid", "", name)
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	configurations := currentConfigurations(h.CurrentConfigurations)
+
+	usage, err := h.Namespaces.Usage(name, configurations)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	representation := NamespaceRepresentation{
+		Usage:          usage,
+		Name:           name,
+		Configurations: h.Namespaces.Configurations(name, configurations),
+	}
+
+	err = templateRenderer.JSON(rw, http.StatusOK, representation)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getNamespacesHandler returns the usage of all the namespaces.
+func (p Handler) getNamespacesHandler(rw http.ResponseWriter, request *http.Request) {
+	configurations := currentConfigurations(p.CurrentConfigurations)
+
+	namespaces := []NamespaceRepresentation{}
+	for _, name := range p.Namespaces.Names() {
+		usage, err := p.Namespaces.Usage(name, configurations)
+		if err != nil {
+			continue
+		}
+		namespaces = append(namespaces, NamespaceRepresentation{Usage: usage, Name: name})
+	}
+
+	err := templateRenderer.JSON(rw, http.StatusOK, namespaces)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func currentConfigurations(current *safe.Safe) config.Configurations {
+	if current == nil {
+		return nil
+	}
+
+	configurations, _ := current.Get().(config.Configurations)
+	return configurations
+}
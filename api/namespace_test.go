@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/namespace"
+	"github.com/containous/traefik/safe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaceHandler(t *testing.T) {
+	registry, err := namespace.New(map[string]*namespace.Namespace{
+		"team-a": {Providers: []string{"file"}, MaxRouters: 5, Tokens: []string{"secret-a"}},
+		"team-b": {Providers: []string{"docker"}, Tokens: []string{"secret-b"}},
+	})
+	require.NoError(t, err)
+
+	current := &safe.Safe{}
+	current.Set(config.Configurations{
+		"file":   {Routers: map[string]*config.Router{"foo": {Rule: "Host:foo.com"}}},
+		"docker": {Routers: map[string]*config.Router{"bar": {Rule: "Host:bar.com"}}},
+	})
+
+	testCases := []struct {
+		desc               string
+		path               string
+		authorization      string
+		expectedStatusCode int
+		expectedRouters    []string
+	}{
+		{
+			desc:               "namespace of the token",
+			path:               "/api/namespaces/team-a",
+			authorization:      "Bearer secret-a",
+			expectedStatusCode: http.StatusOK,
+			expectedRouters:    []string{"foo"},
+		},
+		{
+			desc:               "without token",
+			path:               "/api/namespaces/team-a",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			desc:               "token of another namespace",
+			path:               "/api/namespaces/team-a",
+			authorization:      "Bearer secret-b",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "unknown namespace",
+			path:               "/api/namespaces/team-c",
+			authorization:      "Bearer secret-a",
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router := mux.NewRouter()
+			NamespaceHandler{Namespaces: registry, CurrentConfigurations: current}.Append(router)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			router.ServeHTTP(recorder, request)
+
+			require.Equal(t, test.expectedStatusCode, recorder.Code)
+			if test.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			representation := NamespaceRepresentation{}
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&representation))
+
+			var routers []string
+			for _, conf := range representation.Configurations {
+				for name := range conf.Routers {
+					routers = append(routers, name)
+				}
+			}
+			assert.Equal(t, test.expectedRouters, routers)
+			assert.Equal(t, 1, representation.Routers)
+			assert.Equal(t, 5, representation.MaxRouters)
+		})
+	}
+}
+
+func TestGetNamespacesHandler(t *testing.T) {
+	registry, err := namespace.New(map[string]*namespace.Namespace{
+		"team-a": {Providers: []string{"file"}},
+		"team-b": {Providers: []string{"docker"}},
+	})
+	require.NoError(t, err)
+
+	current := &safe.Safe{}
+	current.Set(config.Configurations{
+		"file": {Routers: map[string]*config.Router{"foo": {}, "bar": {}}},
+	})
+
+	router := mux.NewRouter()
+	Handler{Namespaces: registry, CurrentConfigurations: current}.Append(router)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/namespaces", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var namespaces []NamespaceRepresentation
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&namespaces))
+
+	require.Len(t, namespaces, 2)
+	assert.Equal(t, "team-a", namespaces[0].Name)
+	assert.Equal(t, 2, namespaces[0].Routers)
+	assert.Equal(t, "team-b", namespaces[1].Name)
+	assert.Equal(t, 0, namespaces[1].Routers)
+}
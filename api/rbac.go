@@ -0,0 +1,120 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/audit"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/auth"
+)
+
+const (
+	// RoleReader can read the state of Traefik, but not the configurations, which can hold secrets.
+	RoleReader = "reader"
+	// RoleOperator can read the state of Traefik, and change it: drain servers, switch blue/green services...
+	RoleOperator = "operator"
+	// RoleAdmin can do everything, including reading the configurations, rolling them back, and tapping the traffic.
+	RoleAdmin = "admin"
+)
+
+var roleLevels = map[string]int{
+	RoleReader:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// adminPaths are the path prefixes of the routes exposing the configurations or the traffic, restricted to the admins.
+var adminPaths = []string{
+	"/api/providers",
+	"/api/history",
+	"/api/routers/",
+	"/api/taps",
+	"/api/accesslog",
+	"/debug/",
+}
+
+// RBAC holds the users of each role of the API and the dashboard.
+// The user of a request is the one authenticated by a BasicAuth or DigestAuth middleware of the API,
+// otherwise the value of the UserHeader, otherwise the common name of the verified client certificate.
+type RBAC struct {
+	Readers    []string `description:"Users with the reader role" export:"true"`
+	Operators  []string `description:"Users with the operator role" export:"true"`
+	Admins     []string `description:"Users with the admin role" export:"true"`
+	UserHeader string   `description:"Header holding the user authenticated by a ForwardAuth middleware of the API, e.g. through OpenID Connect" export:"true"`
+}
+
+type rbac struct {
+	next       http.Handler
+	roles      map[string]string
+	userHeader string
+}
+
+// NewRBAC creates a handler checking that the user of a request has the role required by its route.
+func NewRBAC(conf RBAC, next http.Handler) http.Handler {
+	r := &rbac{
+		next:       next,
+		roles:      make(map[string]string),
+		userHeader: conf.UserHeader,
+	}
+
+	// A user with several roles gets the highest one.
+	for role, users := range map[string][]string{RoleReader: conf.Readers, RoleOperator: conf.Operators, RoleAdmin: conf.Admins} {
+		for _, user := range users {
+			if roleLevels[role] > roleLevels[r.roles[user]] {
+				r.roles[user] = role
+			}
+		}
+	}
+
+	return r
+}
+
+func (r *rbac) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	user := r.user(req)
+	role := r.roles[user]
+	required := requiredRole(req)
+
+	if roleLevels[role] < roleLevels[required] {
+		log.FromContext(req.Context()).Debugf("The user %q with the role %q is not allowed to %s %s, which requires the role %q", user, role, req.Method, req.URL.Path, required)
+		audit.RecordRequest(req, audit.CategoryAuthorization, "api.denied", user, required)
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	r.next.ServeHTTP(rw, req)
+}
+
+func (r *rbac) user(req *http.Request) string {
+	if user := auth.UserFromContext(req.Context()); len(user) > 0 {
+		return user
+	}
+
+	if len(r.userHeader) > 0 {
+		if user := req.Header.Get(r.userHeader); len(user) > 0 {
+			return user
+		}
+	}
+
+	if req.TLS != nil && len(req.TLS.VerifiedChains) > 0 && len(req.TLS.VerifiedChains[0]) > 0 {
+		return req.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+
+	return ""
+}
+
+// requiredRole returns the role required by the route of the request.
+func requiredRole(req *http.Request) string {
+	for _, prefix := range adminPaths {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return RoleAdmin
+		}
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RoleReader
+	default:
+		return RoleOperator
+	}
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRBAC(t *testing.T) {
+	conf := RBAC{
+		Readers:    []string{"reader", "alice"},
+		Operators:  []string{"operator"},
+		Admins:     []string{"admin", "alice"},
+		UserHeader: "X-Forwarded-User",
+	}
+
+	testCases := []struct {
+		desc               string
+		method             string
+		path               string
+		user               string
+		header             string
+		commonName         string
+		expectedStatusCode int
+	}{
+		{
+			desc:               "reader reads the blue/green services",
+			method:             http.MethodGet,
+			path:               "/api/bluegreen",
+			user:               "reader",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "reader reads the configurations",
+			method:             http.MethodGet,
+			path:               "/api/providers/file",
+			user:               "reader",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "reader drains a server",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/drain",
+			user:               "reader",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "operator drains a server",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/drain",
+			user:               "operator",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "operator rolls back a configuration",
+			method:             http.MethodPost,
+			path:               "/api/history/3/rollback",
+			user:               "operator",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "operator taps a router",
+			method:             http.MethodPost,
+			path:               "/api/routers/foo/tap",
+			user:               "operator",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "admin reads the configurations",
+			method:             http.MethodGet,
+			path:               "/api/providers/file",
+			user:               "admin",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "highest role of the user",
+			method:             http.MethodGet,
+			path:               "/api/history",
+			user:               "alice",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "unknown user",
+			method:             http.MethodGet,
+			path:               "/dashboard/",
+			user:               "bob",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "anonymous user",
+			method:             http.MethodGet,
+			path:               "/dashboard/",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:               "user of the header",
+			method:             http.MethodPost,
+			path:               "/api/services/foo/switch",
+			header:             "operator",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "user of the client certificate",
+			method:             http.MethodGet,
+			path:               "/api/providers",
+			commonName:         "admin",
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var handler http.Handler = NewRBAC(conf, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+
+			req := httptest.NewRequest(test.method, test.path, nil)
+			if test.user != "" {
+				handler = newBasicAuth(t, handler, test.user)
+				req.SetBasicAuth(test.user, "test")
+			}
+			if test.header != "" {
+				req.Header.Set("X-Forwarded-User", test.header)
+			}
+			if test.commonName != "" {
+				cert := &x509.Certificate{Subject: pkix.Name{CommonName: test.commonName}}
+				req.TLS = &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{cert},
+					VerifiedChains:   [][]*x509.Certificate{{cert}},
+				}
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+		})
+	}
+}
+
+func TestRBACUnverifiedCertificate(t *testing.T) {
+	handler := NewRBAC(RBAC{Admins: []string{"admin"}}, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/providers", nil)
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "admin"}}},
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestRBACURLUser(t *testing.T) {
+	handler := NewRBAC(RBAC{Admins: []string{"admin"}}, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	// The user info of an absolute-form request URI is chosen by the client.
+	_, err = fmt.Fprint(conn, "GET http://admin@localhost/api/providers HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	require.NoError(t, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+// newBasicAuth returns a BasicAuth middleware authenticating the user with the password "test".
+func newBasicAuth(t *testing.T, next http.Handler, user string) http.Handler {
+	t.Helper()
+
+	handler, err := auth.NewBasic(context.Background(), next, config.BasicAuth{
+		Users: []string{user + ":$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+	}, "api-auth")
+	require.NoError(t, err)
+
+	return handler
+}
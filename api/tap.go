@@ -0,0 +1,98 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/tap"
+)
+
+// TapHandler exposes the taps capturing the next requests of the routers.
+type TapHandler struct {
+	Taps *tap.Registry
+}
+
+// Append adds the tap routes on a router.
+func (h TapHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodGet).Path("/api/taps").HandlerFunc(h.getTapsHandler)
+	router.Methods(http.MethodGet).Path("/api/routers/{router}/tap").HandlerFunc(h.getTapHandler)
+	router.Methods(http.MethodPost).Path("/api/routers/{router}/tap").HandlerFunc(h.startTapHandler)
+	router.Methods(http.MethodDelete).Path("/api/routers/{router}/tap").HandlerFunc(h.removeTapHandler)
+}
+
+func (h TapHandler) getTapsHandler(rw http.ResponseWriter, request *http.Request) {
+	err := templateRenderer.JSON(rw, http.StatusOK, h.Taps.List())
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h TapHandler) getTapHandler(rw http.ResponseWriter, request *http.Request) {
+	routerName := mux.Vars(request)["router"]
+
+	t, err := h.Taps.Get(routerName)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = templateRenderer.JSON(rw, http.StatusOK, t)
+	if err != nil {
+		log.FromContext(request.Context()).Error(err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// startTapHandler starts capturing the next requests of the router.
+// The count and maxBodyBytes query parameters set the number of requests captured, and the size of the captured bodies.
+func (h TapHandler) startTapHandler(rw http.ResponseWriter, request *http.Request) {
+	routerName := mux.Vars(request)["router"]
+
+	count, err := queryInt(request, "count")
+	if err != nil {
+		http.Error(rw, "invalid count", http.StatusBadRequest)
+		return
+	}
+
+	maxBodyBytes, err := queryInt(request, "maxBodyBytes")
+	if err != nil {
+		http.Error(rw, "invalid maxBodyBytes", http.StatusBadRequest)
+		return
+	}
+
+	switch err := h.Taps.Start(routerName, int(count), maxBodyBytes); err {
+	case nil:
+	case tap.ErrRouterNotFound:
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	default:
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.FromContext(request.Context()).Infof("Tap of the router %s started", routerName)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (h TapHandler) removeTapHandler(rw http.ResponseWriter, request *http.Request) {
+	routerName := mux.Vars(request)["router"]
+
+	if err := h.Taps.Remove(routerName); err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	log.FromContext(request.Context()).Infof("Tap of the router %s removed", routerName)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func queryInt(request *http.Request, name string) (int64, error) {
+	value := request.URL.Query().Get(name)
+	if len(value) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
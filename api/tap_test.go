@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/tap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTapHandler(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		method             string
+		path               string
+		expectedStatusCode int
+		expectedTaps       []string
+	}{
+		{
+			desc:               "taps",
+			method:             http.MethodGet,
+			path:               "/api/taps",
+			expectedStatusCode: http.StatusOK,
+			expectedTaps:       []string{"foo"},
+		},
+		{
+			desc:               "tap",
+			method:             http.MethodGet,
+			path:               "/api/routers/foo/tap",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "unknown tap",
+			method:             http.MethodGet,
+			path:               "/api/routers/bar/tap",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "start",
+			method:             http.MethodPost,
+			path:               "/api/routers/bar/tap?count=5&maxBodyBytes=1024",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			desc:               "start with an invalid count",
+			method:             http.MethodPost,
+			path:               "/api/routers/bar/tap?count=foo",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "start with a count too large",
+			method:             http.MethodPost,
+			path:               "/api/routers/bar/tap?count=100000",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "start on an unknown router",
+			method:             http.MethodPost,
+			path:               "/api/routers/baz/tap",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:               "remove",
+			method:             http.MethodDelete,
+			path:               "/api/routers/foo/tap",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			desc:               "remove an unknown tap",
+			method:             http.MethodDelete,
+			path:               "/api/routers/bar/tap",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			taps := tap.NewRegistry()
+			taps.SetRouters([]string{"foo", "bar"})
+			require.NoError(t, taps.Start("foo", 1, 0))
+
+			router := mux.NewRouter()
+			TapHandler{Taps: taps}.Append(router)
+
+			server := httptest.NewServer(router)
+			defer server.Close()
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedStatusCode, resp.StatusCode)
+
+			if test.expectedTaps != nil {
+				var result []tap.Tap
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+				var routers []string
+				for _, t := range result {
+					routers = append(routers, t.Router)
+				}
+				assert.Equal(t, test.expectedTaps, routers)
+			}
+		})
+	}
+}
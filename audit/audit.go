@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+// The categories of the audit entries.
+const (
+	// CategoryConfiguration records the changes of the dynamic configuration.
+	CategoryConfiguration = "configuration"
+	// CategoryAuthentication records the failed authentications, to the API or through the auth middlewares.
+	CategoryAuthentication = "authentication"
+	// CategoryAuthorization records the requests denied by the API or by the security middlewares.
+	CategoryAuthorization = "authorization"
+	// CategoryACME records the operations on the ACME account.
+	CategoryACME = "acme"
+)
+
+// Config holds the audit log configuration.
+type Config struct {
+	FilePath string `description:"Audit log file path" export:"true"`
+}
+
+// Entry is an administrative or security event.
+// Hash chains the entries: it is the SHA-256 of the previous hash and of the entry,
+// so removing or altering an entry breaks the chain of the following ones.
+type Entry struct {
+	Time       time.Time         `json:"time"`
+	Category   string            `json:"category"`
+	Action     string            `json:"action"`
+	User       string            `json:"user,omitempty"`
+	RemoteAddr string            `json:"remoteAddr,omitempty"`
+	Resource   string            `json:"resource,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	Hash       string            `json:"hash"`
+}
+
+// Logger writes the audit entries as JSON lines, chained by their hashes.
+// A nil Logger records nothing.
+type Logger struct {
+	lock     sync.Mutex
+	writer   io.Writer
+	closer   io.Closer
+	lastHash string
+}
+
+// NewLogger creates a Logger writing to the writer, continuing the chain of the last hash.
+func NewLogger(writer io.Writer, lastHash string) *Logger {
+	return &Logger{writer: writer, lastHash: lastHash}
+}
+
+// Open creates a Logger appending to the file, nil if the configuration is nil.
+func Open(config *Config) (*Logger, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	if len(config.FilePath) == 0 {
+		return nil, errors.New("no audit log file path")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.FilePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log path %s: %v", filepath.Dir(config.FilePath), err)
+	}
+
+	file, err := os.OpenFile(config.FilePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log file %s: %v", config.FilePath, err)
+	}
+
+	// The new entries follow the last one, even if the file has been tampered with, to keep recording.
+	lastHash, err := Verify(file)
+	if err != nil {
+		log.WithoutContext().Errorf("The audit log file %s has been tampered with: %v", config.FilePath, err)
+	}
+
+	logger := NewLogger(file, lastHash)
+	logger.closer = file
+	return logger, nil
+}
+
+// Record chains and writes the entry.
+func (l *Logger) Record(entry Entry) {
+	if l == nil {
+		return
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	hash, err := chain(l.lastHash, entry)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to record the audit entry %s %s: %v", entry.Category, entry.Action, err)
+		return
+	}
+	entry.Hash = hash
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to record the audit entry %s %s: %v", entry.Category, entry.Action, err)
+		return
+	}
+
+	if _, err := l.writer.Write(append(data, '\n')); err != nil {
+		log.WithoutContext().Errorf("Unable to write the audit entry %s %s: %v", entry.Category, entry.Action, err)
+		return
+	}
+
+	l.lastHash = hash
+}
+
+// Close closes the file of the Logger.
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// Verify checks the chain of the entries read from the reader, and returns the hash of the last entry,
+// along with the first break of the chain.
+func Verify(reader io.Reader) (string, error) {
+	var lastHash string
+	var firstErr error
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("line %d: %v", line, err)
+			}
+			continue
+		}
+
+		hash, err := chain(lastHash, entry)
+		if err == nil && hash != entry.Hash {
+			err = errors.New("broken hash chain")
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("line %d: %v", line, err)
+		}
+
+		lastHash = entry.Hash
+	}
+
+	if err := scanner.Err(); err != nil {
+		return lastHash, err
+	}
+	return lastHash, firstErr
+}
+
+// chain returns the hash of the entry, without its own hash, following the previous hash.
+func chain(previousHash string, entry Entry) (string, error) {
+	entry.Hash = ""
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(append([]byte(previousHash), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+var (
+	defaultLock   sync.RWMutex
+	defaultLogger *Logger
+)
+
+// SetDefault sets the Logger the administrative and security events of Traefik are recorded to.
+func SetDefault(logger *Logger) {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+	defaultLogger = logger
+}
+
+// Record records the entry to the default Logger.
+func Record(entry Entry) {
+	defaultLock.RLock()
+	defer defaultLock.RUnlock()
+	defaultLogger.Record(entry)
+}
+
+// RecordRequest records an entry about the request to the default Logger.
+func RecordRequest(req *http.Request, category, action, user, resource string) {
+	Record(Entry{
+		Category:   category,
+		Action:     action,
+		User:       user,
+		RemoteAddr: req.RemoteAddr,
+		Resource:   resource,
+		Details:    map[string]string{"method": req.Method, "host": req.Host, "path": req.URL.Path},
+	})
+}
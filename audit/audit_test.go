@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := NewLogger(buffer, "")
+	logger.Record(Entry{Category: CategoryConfiguration, Action: "apply", Resource: "file"})
+	logger.Record(Entry{Category: CategoryAuthentication, Action: "basicauth.failed", User: "admin", Resource: "api-auth"})
+	logger.Record(Entry{Category: CategoryACME, Action: "register", User: "admin@example.com"})
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 3)
+
+	testCases := []struct {
+		desc          string
+		lines         []string
+		expectedError string
+	}{
+		{
+			desc:  "intact",
+			lines: lines,
+		},
+		{
+			desc:          "removed entry",
+			lines:         []string{lines[0], lines[2]},
+			expectedError: "line 2: broken hash chain",
+		},
+		{
+			desc:          "altered entry",
+			lines:         []string{lines[0], strings.Replace(lines[1], `"admin"`, `"guest"`, 1), lines[2]},
+			expectedError: "line 2: broken hash chain",
+		},
+		{
+			desc:          "reordered entries",
+			lines:         []string{lines[1], lines[0], lines[2]},
+			expectedError: "line 1: broken hash chain",
+		},
+		{
+			desc:          "malformed entry",
+			lines:         []string{lines[0], "{", lines[1], lines[2]},
+			expectedError: "line 2: unexpected end of JSON input",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			lastHash, err := Verify(strings.NewReader(strings.Join(test.lines, "\n")))
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, logger.lastHash, lastHash)
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	config := &Config{FilePath: filepath.Join(dir, "logs", "audit.log")}
+
+	logger, err := Open(config)
+	require.NoError(t, err)
+	logger.Record(Entry{Category: CategoryConfiguration, Action: "apply", Resource: "file"})
+	require.NoError(t, logger.Close())
+
+	// A restart continues the chain.
+	logger, err = Open(config)
+	require.NoError(t, err)
+	logger.Record(Entry{Category: CategoryConfiguration, Action: "rollback"})
+	require.NoError(t, logger.Close())
+
+	file, err := os.Open(config.FilePath)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	_, err = Verify(file)
+	assert.NoError(t, err)
+}
+
+func TestNilLogger(t *testing.T) {
+	var logger *Logger
+	logger.Record(Entry{Category: CategoryConfiguration, Action: "apply"})
+	assert.NoError(t, logger.Close())
+
+	logger, err := Open(nil)
+	require.NoError(t, err)
+	assert.Nil(t, logger)
+}
@@ -0,0 +1,250 @@
+package bluegreen
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+const (
+	// Blue is the color of the blue service.
+	Blue = "blue"
+	// Green is the color of the green service.
+	Green = "green"
+)
+
+var (
+	// ErrServiceNotFound is returned when switching an unknown blue/green service.
+	ErrServiceNotFound = errors.New("service not found")
+	// ErrInvalidColor is returned when switching a service to a color other than blue or green.
+	ErrInvalidColor = errors.New("invalid color, expected blue or green")
+)
+
+// Status is the status of a blue/green service.
+type Status struct {
+	Live     string         `json:"live"`
+	InFlight map[string]int `json:"inFlight"`
+}
+
+// Switch sends the requests to the live color of a blue/green service.
+// It outlives the configuration reloads, so the requests of the previous handlers are still counted.
+type Switch struct {
+	lock       sync.Mutex
+	configured string
+	live       string
+	inFlight   map[string]int
+	// changed is closed when a color has no more requests in flight.
+	changed chan struct{}
+}
+
+func newSwitch(live string) *Switch {
+	return &Switch{
+		configured: live,
+		live:       live,
+		inFlight:   make(map[string]int),
+		changed:    make(chan struct{}),
+	}
+}
+
+// Handler returns a handler sending the requests to the blue or green handler, depending on the live color.
+func (s *Switch) Handler(blue, green http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		color := s.acquire()
+		defer s.release(color)
+
+		if color == Green {
+			green.ServeHTTP(rw, req)
+			return
+		}
+		blue.ServeHTTP(rw, req)
+	})
+}
+
+func (s *Switch) acquire() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.inFlight[s.live]++
+	return s.live
+}
+
+func (s *Switch) release(color string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.inFlight[color]--
+	if s.inFlight[color] == 0 {
+		close(s.changed)
+		s.changed = make(chan struct{})
+	}
+}
+
+func (s *Switch) status() Status {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return Status{
+		Live: s.live,
+		InFlight: map[string]int{
+			Blue:  s.inFlight[Blue],
+			Green: s.inFlight[Green],
+		},
+	}
+}
+
+// waitIdle waits until the color has no more requests in flight.
+func (s *Switch) waitIdle(ctx context.Context, color string) error {
+	for {
+		s.lock.Lock()
+		if s.inFlight[color] == 0 {
+			s.lock.Unlock()
+			return nil
+		}
+		changed := s.changed
+		s.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Registry keeps track of the switches of the blue/green services, to switch them through the API.
+// A color switched through the API is kept when the configuration is reloaded,
+// until the live color of the configuration changes.
+type Registry struct {
+	lock     sync.RWMutex
+	switches map[string]*Switch
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		switches: make(map[string]*Switch),
+	}
+}
+
+// Switch returns the switch of the service, created with the live color of the configuration if it doesn't exist.
+// A nil registry always returns a new switch.
+func (r *Registry) Switch(serviceName, live string) *Switch {
+	if live == "" {
+		live = Blue
+	}
+
+	if r == nil {
+		return newSwitch(live)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	sw, ok := r.switches[serviceName]
+	if !ok {
+		sw = newSwitch(live)
+		r.switches[serviceName] = sw
+		return sw
+	}
+
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+
+	if sw.configured != live {
+		sw.configured = live
+		sw.live = live
+	}
+
+	return sw
+}
+
+// SetServices forgets the switches of the services which are no longer blue/green services.
+func (r *Registry) SetServices(serviceNames []string) {
+	if r == nil {
+		return
+	}
+
+	names := make(map[string]bool)
+	for _, name := range serviceNames {
+		names[name] = true
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for name := range r.switches {
+		if !names[name] {
+			delete(r.switches, name)
+		}
+	}
+}
+
+// Statuses returns the status of the blue/green services.
+func (r *Registry) Statuses() map[string]Status {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	statuses := make(map[string]Status)
+	for name, sw := range r.switches {
+		statuses[name] = sw.status()
+	}
+	return statuses
+}
+
+// Get returns the status of the blue/green service.
+func (r *Registry) Get(serviceName string) (Status, error) {
+	sw, err := r.get(serviceName)
+	if err != nil {
+		return Status{}, err
+	}
+	return sw.status(), nil
+}
+
+// Flip makes the color live, or the other color if empty, and returns the previous live color.
+// The new requests go to the new live color, while the requests in flight finish on the previous one.
+func (r *Registry) Flip(serviceName, color string) (string, error) {
+	sw, err := r.get(serviceName)
+	if err != nil {
+		return "", err
+	}
+
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+
+	previous := sw.live
+	switch color {
+	case "":
+		if previous == Blue {
+			sw.live = Green
+		} else {
+			sw.live = Blue
+		}
+	case Blue, Green:
+		sw.live = color
+	default:
+		return "", ErrInvalidColor
+	}
+
+	return previous, nil
+}
+
+// WaitDrained waits until the color of the service has no more requests in flight.
+func (r *Registry) WaitDrained(ctx context.Context, serviceName, color string) error {
+	sw, err := r.get(serviceName)
+	if err != nil {
+		return err
+	}
+	return sw.waitIdle(ctx, color)
+}
+
+func (r *Registry) get(serviceName string) (*Switch, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	sw, ok := r.switches[serviceName]
+	if !ok {
+		return nil, ErrServiceNotFound
+	}
+	return sw, nil
+}
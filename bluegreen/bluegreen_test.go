@@ -0,0 +1,115 @@
+package bluegreen
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func colorHandler(color string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(color))
+	})
+}
+
+func serve(handler http.Handler) string {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder.Body.String()
+}
+
+func TestFlip(t *testing.T) {
+	r := NewRegistry()
+	handler := r.Switch("foo", "").Handler(colorHandler(Blue), colorHandler(Green))
+
+	assert.Equal(t, Blue, serve(handler))
+
+	previous, err := r.Flip("foo", "")
+	require.NoError(t, err)
+	assert.Equal(t, Blue, previous)
+	assert.Equal(t, Green, serve(handler))
+
+	previous, err = r.Flip("foo", Green)
+	require.NoError(t, err)
+	assert.Equal(t, Green, previous)
+	assert.Equal(t, Green, serve(handler))
+
+	_, err = r.Flip("foo", "red")
+	assert.Equal(t, ErrInvalidColor, err)
+
+	_, err = r.Flip("bar", Blue)
+	assert.Equal(t, ErrServiceNotFound, err)
+}
+
+func TestReload(t *testing.T) {
+	r := NewRegistry()
+	r.Switch("foo", Blue)
+	r.Switch("bar", Blue)
+
+	_, err := r.Flip("foo", Green)
+	require.NoError(t, err)
+
+	// The switched color is kept while the configuration doesn't change.
+	handler := r.Switch("foo", Blue).Handler(colorHandler(Blue), colorHandler(Green))
+	assert.Equal(t, Green, serve(handler))
+
+	// The configuration wins when its live color changes.
+	handler = r.Switch("foo", Green).Handler(colorHandler(Blue), colorHandler(Green))
+	assert.Equal(t, Green, serve(handler))
+	handler = r.Switch("foo", Blue).Handler(colorHandler(Blue), colorHandler(Green))
+	assert.Equal(t, Blue, serve(handler))
+
+	r.SetServices([]string{"foo"})
+	_, err = r.Get("bar")
+	assert.Equal(t, ErrServiceNotFound, err)
+}
+
+func TestNilRegistry(t *testing.T) {
+	var r *Registry
+	handler := r.Switch("foo", Green).Handler(colorHandler(Blue), colorHandler(Green))
+	assert.Equal(t, Green, serve(handler))
+
+	r.SetServices(nil)
+}
+
+func TestWaitDrained(t *testing.T) {
+	r := NewRegistry()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	blue := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-release
+	})
+	handler := r.Switch("foo", Blue).Handler(blue, colorHandler(Green))
+
+	done := make(chan struct{})
+	go func() {
+		serve(handler)
+		close(done)
+	}()
+	<-started
+
+	_, err := r.Flip("foo", Green)
+	require.NoError(t, err)
+
+	status, err := r.Get("foo")
+	require.NoError(t, err)
+	assert.Equal(t, Status{Live: Green, InFlight: map[string]int{Blue: 1, Green: 0}}, status)
+
+	// The new requests go to the green service, while the blue one drains.
+	assert.Equal(t, Green, serve(handler))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, r.WaitDrained(ctx, "foo", Blue))
+
+	close(release)
+	require.NoError(t, r.WaitDrained(context.Background(), "foo", Blue))
+	<-done
+}
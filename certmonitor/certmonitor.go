@@ -0,0 +1,359 @@
+package certmonitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/events"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/tls/generate"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	defaultInterval        = 12 * time.Hour
+	defaultExpiryThreshold = 30 * 24 * time.Hour
+
+	// startDelay is the delay before the first check, so the providers have sent their certificates.
+	startDelay = time.Minute
+
+	ocspTimeout         = 10 * time.Second
+	maxOCSPResponseSize = 1 << 20
+)
+
+// Config holds the configuration of the monitoring of the served certificates.
+type Config struct {
+	Interval        parse.Duration `description:"Interval between two checks of the certificates (default 12h)" export:"true"`
+	ExpiryThreshold parse.Duration `description:"Remaining validity below which a certificate is expiring (default 720h)" export:"true"`
+	Issuers         []string       `description:"Common names or organizations of the allowed issuers, any issuer is allowed if empty" export:"true"`
+	OCSP            bool           `description:"Check the revocation of the certificates with their OCSP responders" export:"true"`
+}
+
+// Status is the result of the check of a served certificate.
+type Status struct {
+	CommonName       string    `json:"commonName"`
+	SANs             []string  `json:"sans,omitempty"`
+	Serial           string    `json:"serial"`
+	Issuer           string    `json:"issuer"`
+	NotAfter         time.Time `json:"notAfter"`
+	EntryPoints      []string  `json:"entryPoints"`
+	Expiring         bool      `json:"expiring"`
+	Expired          bool      `json:"expired"`
+	Revoked          bool      `json:"revoked"`
+	UnexpectedIssuer bool      `json:"unexpectedIssuer"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// Monitor periodically checks the certificates served by the entry points for their expiry,
+// their revocation and their issuer, and publishes an event the first time a certificate is found in trouble.
+// A nil Monitor checks nothing.
+type Monitor struct {
+	interval      time.Duration
+	threshold     time.Duration
+	issuers       map[string]bool
+	ocsp          bool
+	certificates  func() map[string][]*tls.Certificate
+	notAfterGauge gokitmetrics.Gauge
+	client        *http.Client
+	now           func() time.Time
+	publish       func(eventType string, attributes map[string]string)
+
+	lock     sync.RWMutex
+	statuses []Status
+	// alerts holds the events already published, by certificate fingerprint.
+	alerts map[string]map[string]bool
+}
+
+// New creates a Monitor of the certificates returned by the certificates function, by entry point.
+func New(config *Config, certificates func() map[string][]*tls.Certificate, notAfterGauge gokitmetrics.Gauge) *Monitor {
+	if config == nil {
+		return nil
+	}
+
+	m := &Monitor{
+		interval:      time.Duration(config.Interval),
+		threshold:     time.Duration(config.ExpiryThreshold),
+		issuers:       make(map[string]bool),
+		ocsp:          config.OCSP,
+		certificates:  certificates,
+		notAfterGauge: notAfterGauge,
+		client:        &http.Client{Timeout: ocspTimeout},
+		now:           time.Now,
+		publish:       events.Publish,
+		alerts:        make(map[string]map[string]bool),
+	}
+
+	if m.interval <= 0 {
+		m.interval = defaultInterval
+	}
+	if m.threshold <= 0 {
+		m.threshold = defaultExpiryThreshold
+	}
+	for _, issuer := range config.Issuers {
+		m.issuers[issuer] = true
+	}
+
+	return m
+}
+
+// Run checks the certificates at each interval, until stop is closed.
+func (m *Monitor) Run(stop chan bool) {
+	if m == nil {
+		return
+	}
+
+	timer := time.NewTimer(startDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-stop:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			m.Check(ctx)
+			cancel()
+			timer.Reset(m.interval)
+		}
+	}
+}
+
+// Statuses returns the results of the last check, sorted by common name.
+func (m *Monitor) Statuses() []Status {
+	if m == nil {
+		return nil
+	}
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]Status{}, m.statuses...)
+}
+
+// Check checks the served certificates, updates their statuses and the expiry gauge,
+// and publishes the events of the certificates newly found in trouble.
+func (m *Monitor) Check(ctx context.Context) {
+	if m == nil {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+
+	statuses := make(map[string]*Status)
+	chains := make(map[string][]*x509.Certificate)
+
+	for entryPointName, certificates := range m.certificates() {
+		for _, certificate := range certificates {
+			chain, err := parseChain(certificate)
+			if err != nil {
+				logger.Errorf("Unable to parse a certificate of the entry point %s: %v", entryPointName, err)
+				continue
+			}
+
+			leaf := chain[0]
+			// The certificate generated when none is configured is renewed at each start, and is not worth monitoring.
+			if leaf.Subject.CommonName == generate.DefaultDomain {
+				continue
+			}
+
+			fingerprint := fingerprintOf(leaf)
+			if status, ok := statuses[fingerprint]; ok {
+				status.EntryPoints = append(status.EntryPoints, entryPointName)
+				continue
+			}
+
+			statuses[fingerprint] = &Status{
+				CommonName:  leaf.Subject.CommonName,
+				SANs:        leaf.DNSNames,
+				Serial:      leaf.SerialNumber.String(),
+				Issuer:      leaf.Issuer.CommonName,
+				NotAfter:    leaf.NotAfter,
+				EntryPoints: []string{entryPointName},
+			}
+			chains[fingerprint] = chain
+		}
+	}
+
+	now := m.now()
+	for fingerprint, status := range statuses {
+		chain := chains[fingerprint]
+		leaf := chain[0]
+
+		status.Expired = now.After(leaf.NotAfter)
+		status.Expiring = leaf.NotAfter.Sub(now) < m.threshold
+		status.UnexpectedIssuer = !m.allowedIssuer(leaf)
+
+		if m.ocsp && len(chain) > 1 {
+			revoked, err := m.checkRevocation(ctx, leaf, chain[1])
+			if err != nil {
+				logger.Warnf("Unable to check the revocation of the certificate %s: %v", status.CommonName, err)
+				status.Error = err.Error()
+			}
+			status.Revoked = revoked
+		}
+
+		sort.Strings(status.EntryPoints)
+
+		if m.notAfterGauge != nil {
+			m.notAfterGauge.With("cn", status.CommonName, "serial", status.Serial, "sans", strings.Join(status.SANs, ",")).
+				Set(float64(leaf.NotAfter.Unix()))
+		}
+	}
+
+	m.alert(statuses)
+
+	var result []Status
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CommonName != result[j].CommonName {
+			return result[i].CommonName < result[j].CommonName
+		}
+		return result[i].Serial < result[j].Serial
+	})
+
+	m.lock.Lock()
+	m.statuses = result
+	m.lock.Unlock()
+}
+
+// alert publishes the events of the certificates in trouble which were not already published,
+// and forgets the certificates which are not served anymore.
+func (m *Monitor) alert(statuses map[string]*Status) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for fingerprint := range m.alerts {
+		if _, ok := statuses[fingerprint]; !ok {
+			delete(m.alerts, fingerprint)
+		}
+	}
+
+	for fingerprint, status := range statuses {
+		alerts, ok := m.alerts[fingerprint]
+		if !ok {
+			alerts = make(map[string]bool)
+			m.alerts[fingerprint] = alerts
+		}
+
+		for eventType, trouble := range map[string]bool{
+			events.CertificateExpiring:         status.Expiring,
+			events.CertificateRevoked:          status.Revoked,
+			events.CertificateUnexpectedIssuer: status.UnexpectedIssuer,
+		} {
+			if !trouble || alerts[eventType] {
+				continue
+			}
+			alerts[eventType] = true
+
+			m.publish(eventType, map[string]string{
+				"commonName":  status.CommonName,
+				"sans":        strings.Join(status.SANs, ","),
+				"serial":      status.Serial,
+				"issuer":      status.Issuer,
+				"notAfter":    status.NotAfter.UTC().Format(time.RFC3339),
+				"entryPoints": strings.Join(status.EntryPoints, ","),
+			})
+		}
+	}
+}
+
+// allowedIssuer tells whether the issuer of the certificate, by common name or organization, is allowed.
+func (m *Monitor) allowedIssuer(leaf *x509.Certificate) bool {
+	if len(m.issuers) == 0 || m.issuers[leaf.Issuer.CommonName] {
+		return true
+	}
+
+	for _, organization := range leaf.Issuer.Organization {
+		if m.issuers[organization] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRevocation asks the OCSP responder of the certificate whether it is revoked.
+// The certificates without OCSP responder are not revoked.
+func (m *Monitor) checkRevocation(ctx context.Context, leaf, issuer *x509.Certificate) (bool, error) {
+	if len(leaf.OCSPServer) == 0 {
+		return false, nil
+	}
+
+	ocspRequest, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, leaf.OCSPServer[0], bytes.NewReader(ocspRequest))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	req.Header.Set("Accept", "application/ocsp-response")
+
+	resp, err := m.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("the OCSP responder %s answered with the status %d", leaf.OCSPServer[0], resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxOCSPResponseSize))
+	if err != nil {
+		return false, err
+	}
+
+	ocspResponse, err := ocsp.ParseResponseForCert(body, leaf, issuer)
+	if err != nil {
+		return false, err
+	}
+
+	return ocspResponse.Status == ocsp.Revoked, nil
+}
+
+func parseChain(certificate *tls.Certificate) ([]*x509.Certificate, error) {
+	if certificate == nil || len(certificate.Certificate) == 0 {
+		return nil, errors.New("empty certificate")
+	}
+
+	var chain []*x509.Certificate
+	for _, der := range certificate.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, cert)
+	}
+	return chain, nil
+}
+
+func fingerprintOf(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,226 @@
+package certmonitor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/events"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/containous/traefik/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+type authority struct {
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+}
+
+func newAuthority(t *testing.T, commonName string) *authority {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{commonName + " Inc"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &authority{cert: cert, key: key}
+}
+
+func (a *authority) issue(t *testing.T, domain string, serial int64, notAfter time.Time, ocspServer string) *tls.Certificate {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	if len(ocspServer) > 0 {
+		template.OCSPServer = []string{ocspServer}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	require.NoError(t, err)
+
+	return &tls.Certificate{Certificate: [][]byte{der, a.cert.Raw}, PrivateKey: key}
+}
+
+// ocspResponder answers that the certificates with the revoked serial numbers are revoked, and the others are good.
+func (a *authority) ocspResponder(t *testing.T, revoked ...int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		ocspRequest, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		template := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: ocspRequest.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}
+		for _, serial := range revoked {
+			if ocspRequest.SerialNumber.Int64() == serial {
+				template.Status = ocsp.Revoked
+				template.RevokedAt = time.Now().Add(-time.Minute)
+			}
+		}
+
+		response, err := ocsp.CreateResponse(a.cert, a.cert, template, a.key)
+		require.NoError(t, err)
+
+		rw.Header().Set("Content-Type", "application/ocsp-response")
+		_, _ = rw.Write(response)
+	}))
+}
+
+func TestCheck(t *testing.T) {
+	ca := newAuthority(t, "Trusted CA")
+	other := newAuthority(t, "Other CA")
+
+	responder := ca.ocspResponder(t, 3)
+	defer responder.Close()
+
+	defaultCertificate, err := generate.DefaultCertificate()
+	require.NoError(t, err)
+
+	valid := ca.issue(t, "valid.com", 1, time.Now().Add(90*24*time.Hour), responder.URL)
+	expiring := ca.issue(t, "expiring.com", 2, time.Now().Add(24*time.Hour), responder.URL)
+	revoked := ca.issue(t, "revoked.com", 3, time.Now().Add(90*24*time.Hour), responder.URL)
+	unexpected := other.issue(t, "unexpected.com", 4, time.Now().Add(90*24*time.Hour), "")
+
+	certificates := map[string][]*tls.Certificate{
+		"websecure": {defaultCertificate, valid, expiring, revoked, unexpected},
+		"admin":     {valid},
+	}
+
+	gauge := &testhelpers.CollectingGauge{}
+	monitor := New(&Config{
+		ExpiryThreshold: parse.Duration(30 * 24 * time.Hour),
+		Issuers:         []string{"Trusted CA"},
+		OCSP:            true,
+	}, func() map[string][]*tls.Certificate { return certificates }, gauge)
+
+	published := make(map[string][]string)
+	monitor.publish = func(eventType string, attributes map[string]string) {
+		published[eventType] = append(published[eventType], attributes["commonName"])
+	}
+
+	monitor.Check(context.Background())
+
+	statuses := monitor.Statuses()
+	require.Len(t, statuses, 4)
+
+	byName := make(map[string]Status)
+	for _, status := range statuses {
+		byName[status.CommonName] = status
+	}
+
+	assert.Equal(t, []string{"admin", "websecure"}, byName["valid.com"].EntryPoints)
+	assert.False(t, byName["valid.com"].Expiring)
+	assert.False(t, byName["valid.com"].Revoked)
+	assert.False(t, byName["valid.com"].UnexpectedIssuer)
+	assert.Empty(t, byName["valid.com"].Error)
+
+	assert.True(t, byName["expiring.com"].Expiring)
+	assert.False(t, byName["expiring.com"].Expired)
+	assert.True(t, byName["revoked.com"].Revoked)
+	assert.True(t, byName["unexpected.com"].UnexpectedIssuer)
+	assert.Equal(t, "Other CA", byName["unexpected.com"].Issuer)
+
+	assert.Equal(t, map[string][]string{
+		events.CertificateExpiring:         {"expiring.com"},
+		events.CertificateRevoked:          {"revoked.com"},
+		events.CertificateUnexpectedIssuer: {"unexpected.com"},
+	}, published)
+
+	assert.NotZero(t, gauge.GaugeValue)
+
+	// The certificates already reported are not reported again.
+	monitor.Check(context.Background())
+	assert.Len(t, published[events.CertificateExpiring], 1)
+	assert.Len(t, published[events.CertificateRevoked], 1)
+	assert.Len(t, published[events.CertificateUnexpectedIssuer], 1)
+}
+
+func TestAllowedIssuer(t *testing.T) {
+	ca := newAuthority(t, "Trusted CA")
+	cert, err := x509.ParseCertificate(ca.issue(t, "foo.com", 1, time.Now().Add(time.Hour), "").Certificate[0])
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		issuers  []string
+		expected bool
+	}{
+		{
+			desc:     "any issuer",
+			expected: true,
+		},
+		{
+			desc:     "common name",
+			issuers:  []string{"Trusted CA"},
+			expected: true,
+		},
+		{
+			desc:     "organization",
+			issuers:  []string{"Trusted CA Inc"},
+			expected: true,
+		},
+		{
+			desc:     "other issuer",
+			issuers:  []string{"Other CA"},
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			monitor := New(&Config{Issuers: test.issuers}, nil, nil)
+			assert.Equal(t, test.expected, monitor.allowedIssuer(cert))
+		})
+	}
+}
+
+func TestNilMonitor(t *testing.T) {
+	var monitor *Monitor
+
+	monitor.Check(context.Background())
+	assert.Nil(t, monitor.Statuses())
+	assert.Nil(t, New(nil, nil, nil))
+}
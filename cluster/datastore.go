@@ -10,6 +10,7 @@ import (
 	"github.com/abronan/valkeyrie/store"
 	"github.com/cenk/backoff"
 	"github.com/containous/staert"
+	"github.com/containous/traefik/encryption"
 	"github.com/containous/traefik/job"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/safe"
@@ -19,6 +20,7 @@ import (
 // Metadata stores Object plus metadata
 type Metadata struct {
 	object Object
+	cipher *encryption.Cipher
 	Object []byte
 	Lock   string
 }
@@ -28,10 +30,19 @@ func NewMetadata(object Object) *Metadata {
 	return &Metadata{object: object}
 }
 
+// NewEncryptedMetadata returns new Metadata, encrypting the object with the cipher.
+func NewEncryptedMetadata(object Object, cipher *encryption.Cipher) *Metadata {
+	return &Metadata{object: object, cipher: cipher}
+}
+
 // Marshall marshalls object
 func (m *Metadata) Marshall() error {
-	var err error
-	m.Object, err = json.Marshal(m.object)
+	data, err := json.Marshal(m.object)
+	if err != nil {
+		return err
+	}
+
+	m.Object, err = m.cipher.Encrypt(data)
 	return err
 }
 
@@ -39,7 +50,12 @@ func (m *Metadata) unmarshall() error {
 	if len(m.Object) == 0 {
 		return nil
 	}
-	return json.Unmarshal(m.Object, m.object)
+
+	data, err := m.cipher.Decrypt(m.Object)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, m.object)
 }
 
 // Listener is called when Object has been changed in KV store
@@ -59,10 +75,15 @@ type Datastore struct {
 
 // NewDataStore creates a Datastore
 func NewDataStore(ctx context.Context, kvSource staert.KvSource, object Object, listener Listener) (*Datastore, error) {
+	return NewEncryptedDataStore(ctx, kvSource, object, listener, nil)
+}
+
+// NewEncryptedDataStore creates a Datastore, encrypting the object in the KV store with the cipher.
+func NewEncryptedDataStore(ctx context.Context, kvSource staert.KvSource, object Object, listener Listener, cipher *encryption.Cipher) (*Datastore, error) {
 	datastore := Datastore{
 		kv:        kvSource,
 		ctx:       ctx,
-		meta:      &Metadata{object: object},
+		meta:      NewEncryptedMetadata(object, cipher),
 		lockKey:   kvSource.Prefix + "/lock",
 		localLock: &sync.RWMutex{},
 		listener:  listener,
@@ -4,7 +4,9 @@ import (
 	"time"
 
 	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config/history"
 	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/diagnostics"
 	"github.com/containous/traefik/old/configuration"
 	"github.com/containous/traefik/old/middlewares/accesslog"
 	"github.com/containous/traefik/old/provider/boltdb"
@@ -21,10 +23,14 @@ import (
 	"github.com/containous/traefik/old/provider/rancher"
 	"github.com/containous/traefik/old/provider/zk"
 	"github.com/containous/traefik/ping"
+	"github.com/containous/traefik/provider/dns"
 	"github.com/containous/traefik/provider/file"
+	"github.com/containous/traefik/provider/grpc"
+	"github.com/containous/traefik/provider/kubernetes/crd"
 	"github.com/containous/traefik/provider/rest"
 	"github.com/containous/traefik/tracing/datadog"
 	"github.com/containous/traefik/tracing/jaeger"
+	"github.com/containous/traefik/tracing/opentelemetry"
 	"github.com/containous/traefik/tracing/zipkin"
 	"github.com/containous/traefik/types"
 )
@@ -67,6 +73,11 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 		EntryPoint: "traefik",
 	}
 
+	// default Diagnostics
+	var defaultDiagnostics = diagnostics.Handler{
+		EntryPoint: diagnostics.DefaultEntryPointName,
+	}
+
 	// default TraefikLog
 	defaultTraefikLog := types.TraefikLog{
 		Format:   "common",
@@ -111,12 +122,22 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 			GlobalTag:          "",
 			Debug:              false,
 		},
+		OpenTelemetry: &opentelemetry.Config{
+			Endpoint:      "localhost:4317",
+			Protocol:      opentelemetry.ProtocolGRPC,
+			Sampler:       opentelemetry.SamplerParentBasedRatio,
+			SamplingRatio: 1.0,
+			BatchSize:     512,
+			FlushInterval: parse.Duration(5 * time.Second),
+			ExportTimeout: parse.Duration(10 * time.Second),
+		},
 	}
 
 	// default ApiConfiguration
 	defaultAPI := static.API{
-		EntryPoint: "traefik",
-		Dashboard:  true,
+		EntryPoint:  "traefik",
+		Dashboard:   true,
+		HistorySize: history.DefaultSize,
 	}
 	defaultAPI.Statistics = &types.Statistics{
 		RecentErrors: 10,
@@ -141,6 +162,12 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 			Protocol:     "udp",
 			PushInterval: "10s",
 		},
+		Push: &types.Push{
+			Protocol:     "pushgateway",
+			Address:      "http://localhost:9091",
+			Job:          "traefik",
+			PushInterval: "10s",
+		},
 	}
 
 	defaultResolver := static.HostResolverConfig{
@@ -160,6 +187,10 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 	var defaultRest rest.Provider
 	defaultRest.EntryPoint = configuration.DefaultInternalEntryPointName
 
+	// default gRPC
+	var defaultGRPC grpc.Provider
+	defaultGRPC.Address = ":8500"
+
 	// default Marathon
 	var defaultMarathon marathon.Provider
 	defaultMarathon.Watch = true
@@ -206,6 +237,17 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 	var defaultKubernetes kubernetes.Provider
 	defaultKubernetes.Watch = true
 
+	// default Kubernetes CRD
+	var defaultKubernetesCRD crd.Provider
+	defaultKubernetesCRD.Watch = true
+
+	// default DNS
+	var defaultDNS dns.Provider
+	defaultDNS.ResolvConfig = "/etc/resolv.conf"
+	defaultDNS.MinRefresh = parse.Duration(5 * time.Second)
+	defaultDNS.MaxRefresh = parse.Duration(5 * time.Minute)
+	defaultDNS.MaxDropRatio = 0.5
+
 	// default Mesos
 	var defaultMesos mesos.Provider
 	defaultMesos.Watch = true
@@ -243,6 +285,7 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 		File:          &defaultFile,
 		Docker:        &defaultDocker,
 		Rest:          &defaultRest,
+		GRPC:          &defaultGRPC,
 		Marathon:      &defaultMarathon,
 		Consul:        &defaultConsul,
 		ConsulCatalog: &defaultConsulCatalog,
@@ -250,6 +293,8 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 		Zookeeper:     &defaultZookeeper,
 		Boltdb:        &defaultBoltDb,
 		Kubernetes:    &defaultKubernetes,
+		KubernetesCRD: &defaultKubernetesCRD,
+		DNS:           &defaultDNS,
 		Mesos:         &defaultMesos,
 		ECS:           &defaultECS,
 		Rancher:       &defaultRancher,
@@ -263,6 +308,7 @@ func NewTraefikDefaultPointersConfiguration() *TraefikConfiguration {
 			Log:          &defaultTraefikLog,
 			AccessLog:    &defaultAccessLog,
 			Ping:         &defaultPing,
+			Diagnostics:  &defaultDiagnostics,
 			API:          &defaultAPI,
 			Metrics:      &defaultMetrics,
 			Tracing:      &defaultTracing,
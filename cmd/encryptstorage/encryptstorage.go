@@ -0,0 +1,103 @@
+package encryptstorage
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	stdlog "log"
+	"os"
+
+	"github.com/containous/flaeg"
+	"github.com/containous/staert"
+	"github.com/containous/traefik/cluster"
+	"github.com/containous/traefik/cmd"
+	"github.com/containous/traefik/encryption"
+	"github.com/containous/traefik/secrets"
+)
+
+// NewCmd builds a new EncryptStorage command
+func NewCmd(traefikConfiguration *cmd.TraefikConfiguration, traefikPointersConfiguration *cmd.TraefikConfiguration) *flaeg.Command {
+	return &flaeg.Command{
+		Name:                  "encryptstorage",
+		Description:           `Encrypt the ACME storage, file or key-value store, with the key of acme.storageEncryption. Traefik will not start.`,
+		Config:                traefikConfiguration,
+		DefaultPointersConfig: traefikPointersConfiguration,
+		Metadata: map[string]string{
+			"parseAllSources": "true",
+		},
+	}
+}
+
+// Run encrypts the ACME storage, in the file or in the KV store if the file doesn't exist.
+func Run(kv *staert.KvSource, traefikConfiguration *cmd.TraefikConfiguration) func() error {
+	return func() error {
+		acmeConfiguration := traefikConfiguration.Configuration.ACME
+		if acmeConfiguration == nil || len(acmeConfiguration.Storage) == 0 {
+			return errors.New("error using command encryptstorage, no ACME storage defined")
+		}
+
+		if acmeConfiguration.StorageEncryption == nil {
+			return errors.New("error using command encryptstorage, no ACME storage encryption defined")
+		}
+
+		secretStore, err := secrets.New(traefikConfiguration.Configuration.Secrets)
+		if err != nil {
+			return err
+		}
+
+		cipher, err := encryption.New(acmeConfiguration.StorageEncryption, secretStore)
+		if err != nil {
+			return err
+		}
+
+		if _, err := os.Stat(acmeConfiguration.Storage); err == nil || kv == nil {
+			stdlog.Printf("Encrypting the file %s\n", acmeConfiguration.Storage)
+			return EncryptFile(acmeConfiguration.Storage, cipher)
+		}
+
+		stdlog.Printf("Encrypting the key %s of the KV store\n", acmeConfiguration.Storage)
+		return encryptKV(staert.KvSource{Store: kv.Store, Prefix: acmeConfiguration.Storage}, cipher)
+	}
+}
+
+// EncryptFile encrypts the file with the cipher, with a new data key if it is already encrypted.
+func EncryptFile(filename string, cipher *encryption.Cipher) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	data, err = reencrypt(data, cipher)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt the file %s: %v", filename, err)
+	}
+
+	return ioutil.WriteFile(filename, data, 0600)
+}
+
+func encryptKV(source staert.KvSource, cipher *encryption.Cipher) error {
+	meta := &cluster.Metadata{}
+	if err := source.LoadConfig(meta); err != nil {
+		return err
+	}
+
+	if len(meta.Object) == 0 {
+		return fmt.Errorf("no ACME data under the key %s", source.Prefix)
+	}
+
+	data, err := reencrypt(meta.Object, cipher)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt the key %s: %v", source.Prefix, err)
+	}
+	meta.Object = data
+
+	return source.StoreConfig(meta)
+}
+
+func reencrypt(data []byte, cipher *encryption.Cipher) ([]byte, error) {
+	plaintext, err := cipher.Decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.Encrypt(plaintext)
+}
@@ -0,0 +1,43 @@
+package encryptstorage
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "acme")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	plaintext := []byte(`{"Account":{"Email":"admin@example.com"}}`)
+	_, err = file.Write(plaintext)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	require.NoError(t, EncryptFile(file.Name(), cipher))
+
+	encrypted, err := ioutil.ReadFile(file.Name())
+	require.NoError(t, err)
+	assert.True(t, encryption.IsEncrypted(encrypted))
+
+	// An encrypted file is encrypted again with a new data key.
+	require.NoError(t, EncryptFile(file.Name(), cipher))
+
+	reencrypted, err := ioutil.ReadFile(file.Name())
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, reencrypted)
+
+	decrypted, err := cipher.Decrypt(reencrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/containous/flaeg"
+	"github.com/containous/staert"
+	"github.com/containous/traefik/config/format"
+)
+
+var _ staert.Source = (*FileSource)(nil)
+
+// fileExtensions are the extensions of the configuration files, by order of preference.
+var fileExtensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+// FileSource is a staert.Source reading the static configuration from a TOML, YAML or JSON file.
+type FileSource struct {
+	filename     string
+	dirNFullPath []string
+	fullPath     string
+}
+
+// NewFileSource creates a FileSource.
+// filename is the file name without extension, dirNFullPath may contain directories or full paths to the file.
+func NewFileSource(filename string, dirNFullPath []string) *FileSource {
+	return &FileSource{filename: filename, dirNFullPath: dirNFullPath}
+}
+
+// ConfigFileUsed returns the configuration file used.
+func (s *FileSource) ConfigFileUsed() string {
+	return s.fullPath
+}
+
+// Parse loads the configuration file into the command configuration.
+func (s *FileSource) Parse(cmd *flaeg.Command) (*flaeg.Command, error) {
+	s.fullPath = findFile(s.filename, s.dirNFullPath)
+	if len(s.fullPath) < 2 {
+		return cmd, nil
+	}
+
+	content, err := ioutil.ReadFile(s.fullPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The YAML and JSON files are converted to TOML, so all the formats share the same schema and defaults handling.
+	content, err = format.ToTOML(format.FromFilename(s.fullPath), content)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := toml.Decode(string(content), cmd.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	boolFlags, err := flaeg.GetBoolFlags(cmd.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	flgArgs, hasUnderField := generateArgs(metadata, boolFlags)
+
+	err = flaeg.Load(cmd.Config, cmd.DefaultPointersConfig, flgArgs)
+	if err != nil && err != flaeg.ErrParserNotFound {
+		return nil, err
+	}
+
+	if hasUnderField {
+		if _, err := toml.Decode(string(content), cmd.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	return cmd, nil
+}
+
+func findFile(filename string, dirNFullPath []string) string {
+	for _, df := range dirNFullPath {
+		if df == "" {
+			continue
+		}
+
+		fullPath, _ := filepath.Abs(os.ExpandEnv(df))
+		if fileInfo, err := os.Stat(fullPath); err == nil && !fileInfo.IsDir() {
+			return fullPath
+		}
+
+		for _, ext := range fileExtensions {
+			filePath := filepath.Join(fullPath, filename+ext)
+			if fileInfo, err := os.Stat(filePath); err == nil && !fileInfo.IsDir() {
+				return filePath
+			}
+		}
+	}
+	return ""
+}
+
+// generateArgs returns the flags enabling the pointer sections defined in the file,
+// and whether some sections have sub fields.
+func generateArgs(metadata toml.MetaData, flags []string) ([]string, bool) {
+	var flgArgs []string
+	keys := metadata.Keys()
+	hasUnderField := false
+
+	for i, key := range keys {
+		if metadata.Type(key.String()) != "Hash" {
+			continue
+		}
+
+		// TOML hashes correspond to Go structs or maps.
+		for j := i; j < len(keys); j++ {
+			if strings.Contains(keys[j].String(), key.String()+".") {
+				hasUnderField = true
+				break
+			}
+		}
+
+		for _, flag := range flags {
+			if flag == strings.ToLower(key.String()) {
+				flgArgs = append(flgArgs, "--"+strings.ToLower(key.String()))
+				break
+			}
+		}
+	}
+
+	return flgArgs, hasUnderField
+}
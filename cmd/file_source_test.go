@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/flaeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSource_Parse(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		filename string
+		content  string
+	}{
+		{
+			desc:     "TOML",
+			filename: "traefik.toml",
+			content: `
+[global]
+  debug = true
+[entryPoints.web]
+  address = ":8080"
+[api]
+  entryPoint = "web"
+`,
+		},
+		{
+			desc:     "YAML",
+			filename: "traefik.yaml",
+			content: `
+global:
+  debug: true
+entryPoints:
+  web:
+    address: ":8080"
+api:
+  entryPoint: web
+`,
+		},
+		{
+			desc:     "JSON",
+			filename: "traefik.json",
+			content:  `{"global": {"debug": true}, "entryPoints": {"web": {"address": ":8080"}}, "api": {"entryPoint": "web"}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "traefik-config")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, test.filename), []byte(test.content), 0644))
+
+			configuration := NewTraefikConfiguration()
+			command := &flaeg.Command{
+				Config:                configuration,
+				DefaultPointersConfig: NewTraefikDefaultPointersConfiguration(),
+			}
+
+			source := NewFileSource("traefik", []string{dir})
+			_, err = source.Parse(command)
+			require.NoError(t, err)
+
+			assert.Equal(t, filepath.Join(dir, test.filename), source.ConfigFileUsed())
+			assert.True(t, configuration.Global.Debug)
+			require.Contains(t, configuration.EntryPoints, "web")
+			assert.Equal(t, ":8080", configuration.EntryPoints["web"].Address)
+			require.NotNil(t, configuration.API)
+			assert.Equal(t, "web", configuration.API.EntryPoint)
+		})
+	}
+}
+
+func TestFileSource_ParseError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "traefik.json"), []byte("{\n  \"global\": {\n    \"debug\": true,\n  }\n}"), 0644))
+
+	command := &flaeg.Command{
+		Config:                NewTraefikConfiguration(),
+		DefaultPointersConfig: NewTraefikDefaultPointersConfiguration(),
+	}
+
+	_, err = NewFileSource("traefik", []string{dir}).Parse(command)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 4, column 3")
+}
@@ -1,11 +1,14 @@
 package healthcheck
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/containous/flaeg"
@@ -58,16 +61,27 @@ func Do(staticConfiguration static.Configuration) (*http.Response, error) {
 
 	client := &http.Client{Timeout: 5 * time.Second}
 	protocol := "http"
+	address := pingEntryPoint.Address
 
+	tr := &http.Transport{}
 	if pingEntryPoint.TLS != nil {
 		protocol = "https"
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = tr
+	}
+
+	// The entry points listening on a Unix socket are reached through the socket.
+	if strings.HasPrefix(address, "unix://") {
+		socket := strings.TrimPrefix(address, "unix://")
+		tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			dialer := &net.Dialer{}
+			return dialer.DialContext(ctx, "unix", socket)
 		}
 		client.Transport = tr
+		address = "localhost"
 	}
 
 	path := "/"
 
-	return client.Head(protocol + "://" + pingEntryPoint.Address + path + "ping")
+	return client.Head(protocol + "://" + address + path + "ping")
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/containous/traefik/acme"
 	"github.com/containous/traefik/cluster"
 	"github.com/containous/traefik/cmd"
+	"github.com/containous/traefik/encryption"
 )
 
 // NewCmd builds a new StoreConfig command
@@ -84,7 +85,12 @@ func Run(kv *staert.KvSource, traefikConfiguration *cmd.TraefikConfiguration) fu
 
 				// Store the ACME Account into the KV Store
 				// Certificates in KV Store will be overridden
-				meta := cluster.NewMetadata(account)
+				cipher, err := encryption.New(traefikConfiguration.Configuration.ACME.StorageEncryption, nil)
+				if err != nil {
+					return err
+				}
+
+				meta := cluster.NewEncryptedMetadata(account, cipher)
 				err = meta.Marshall()
 				if err != nil {
 					return err
@@ -18,8 +18,10 @@ import (
 	"github.com/containous/traefik/autogen/genstatic"
 	"github.com/containous/traefik/cmd"
 	"github.com/containous/traefik/cmd/bug"
+	"github.com/containous/traefik/cmd/encryptstorage"
 	"github.com/containous/traefik/cmd/healthcheck"
 	"github.com/containous/traefik/cmd/storeconfig"
+	"github.com/containous/traefik/cmd/validate"
 	cmdVersion "github.com/containous/traefik/cmd/version"
 	"github.com/containous/traefik/collector"
 	"github.com/containous/traefik/config"
@@ -29,7 +31,9 @@ import (
 	"github.com/containous/traefik/old/provider/ecs"
 	"github.com/containous/traefik/old/provider/kubernetes"
 	oldtypes "github.com/containous/traefik/old/types"
+	"github.com/containous/traefik/privileges"
 	"github.com/containous/traefik/provider/aggregator"
+	"github.com/containous/traefik/provider/internalca"
 	"github.com/containous/traefik/safe"
 	"github.com/containous/traefik/server"
 	"github.com/containous/traefik/server/router"
@@ -102,6 +106,9 @@ Complete documentation is available at https://traefik.io`,
 	// storeconfig Command init
 	storeConfigCmd := storeconfig.NewCmd(traefikConfiguration, traefikPointersConfiguration)
 
+	// encryptstorage Command init
+	encryptStorageCmd := encryptstorage.NewCmd(traefikConfiguration, traefikPointersConfiguration)
+
 	// init flaeg source
 	f := flaeg.New(traefikCmd, os.Args[1:])
 	// add custom parsers
@@ -129,7 +136,9 @@ Complete documentation is available at https://traefik.io`,
 	f.AddCommand(cmdVersion.NewCmd())
 	f.AddCommand(bug.NewCmd(traefikConfiguration, traefikPointersConfiguration))
 	f.AddCommand(storeConfigCmd)
+	f.AddCommand(encryptStorageCmd)
 	f.AddCommand(healthcheck.NewCmd(traefikConfiguration, traefikPointersConfiguration))
+	f.AddCommand(validate.NewCmd(traefikConfiguration, traefikPointersConfiguration))
 
 	usedCmd, err := f.GetCommand()
 	if err != nil {
@@ -147,18 +156,18 @@ Complete documentation is available at https://traefik.io`,
 
 	// staert init
 	s := staert.NewStaert(traefikCmd)
-	// init TOML source
-	toml := staert.NewTomlSource("traefik", []string{traefikConfiguration.ConfigFile, "/etc/traefik/", "$HOME/.traefik/", "."})
+	// init file source (TOML, YAML or JSON)
+	file := cmd.NewFileSource("traefik", []string{traefikConfiguration.ConfigFile, "/etc/traefik/", "$HOME/.traefik/", "."})
 
 	// add sources to staert
-	s.AddSource(toml)
+	s.AddSource(file)
 	s.AddSource(f)
 	if _, err := s.LoadConfig(); err != nil {
-		fmtlog.Printf("Error reading TOML config file %s : %s\n", toml.ConfigFileUsed(), err)
+		fmtlog.Printf("Error reading config file %s : %s\n", file.ConfigFileUsed(), err)
 		os.Exit(1)
 	}
 
-	traefikConfiguration.ConfigFile = toml.ConfigFileUsed()
+	traefikConfiguration.ConfigFile = file.ConfigFileUsed()
 
 	kv, err := storeconfig.CreateKvSource(traefikConfiguration)
 	if err != nil {
@@ -166,6 +175,7 @@ Complete documentation is available at https://traefik.io`,
 		os.Exit(1)
 	}
 	storeConfigCmd.Run = storeconfig.Run(kv, traefikConfiguration)
+	encryptStorageCmd.Run = encryptstorage.Run(kv, traefikConfiguration)
 
 	// if a KV Store is enable and no sub-command called in args
 	if kv != nil && usedCmd == traefikCmd {
@@ -240,12 +250,21 @@ func runCmd(staticConfiguration *static.Configuration, configFile string) {
 		}
 	}
 
+	var internalCAProvider *internalca.Provider
+	if staticConfiguration.InternalCA != nil {
+		internalCAProvider = &internalca.Provider{Configuration: staticConfiguration.InternalCA}
+		if err := providerAggregator.AddProvider(internalCAProvider); err != nil {
+			log.WithoutContext().Errorf("Unable to add the internal CA provider to the providers list: %v", err)
+			internalCAProvider = nil
+		}
+	}
+
 	serverEntryPoints := make(server.EntryPoints)
 	for entryPointName, config := range staticConfiguration.EntryPoints {
 		ctx := log.With(context.Background(), log.Str(log.EntryPointName, entryPointName))
 		logger := log.FromContext(ctx)
 
-		serverEntryPoint, err := server.NewEntryPoint(ctx, config)
+		serverEntryPoint, err := server.NewEntryPoint(ctx, entryPointName, config)
 		if err != nil {
 			logger.Errorf("Error while building entryPoint: %v", err)
 			continue
@@ -270,12 +289,27 @@ func runCmd(staticConfiguration *static.Configuration, configFile string) {
 		serverEntryPoints[entryPointName] = serverEntryPoint
 	}
 
+	// The entry points are bound, Traefik doesn't need its privileges anymore.
+	if err := privileges.Drop(staticConfiguration.Privileges); err != nil {
+		log.WithoutContext().Fatalf("Unable to drop the privileges: %v", err)
+	}
+
 	svr := server.NewServer(*staticConfiguration, providerAggregator, serverEntryPoints)
 
+	svr.AddReadinessCheck("providers", providerAggregator.Check)
+	if acmeProvider != nil {
+		svr.AddReadinessCheck("acme", acmeProvider.CheckStore)
+	}
+
 	if acmeProvider != nil && acmeProvider.OnHostRule {
 		acmeProvider.SetConfigListenerChan(make(chan config.Configuration))
 		svr.AddListener(acmeProvider.ListenConfiguration)
 	}
+
+	if internalCAProvider != nil && internalCAProvider.OnHostRule {
+		internalCAProvider.SetConfigListenerChan(make(chan config.Configuration))
+		svr.AddListener(internalCAProvider.ListenConfiguration)
+	}
 	ctx := cmd.ContextWithSignal(context.Background())
 
 	if staticConfiguration.Ping != nil {
@@ -0,0 +1,71 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/containous/flaeg"
+	"github.com/containous/traefik/cmd"
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/plugins"
+	"github.com/containous/traefik/server/router"
+)
+
+// NewCmd builds a new Validate command
+func NewCmd(traefikConfiguration *cmd.TraefikConfiguration, traefikPointersConfiguration *cmd.TraefikConfiguration) *flaeg.Command {
+	return &flaeg.Command{
+		Name:                  "validate",
+		Description:           `Validates the dynamic configuration of the file provider, without applying it`,
+		Config:                traefikConfiguration,
+		DefaultPointersConfig: traefikPointersConfiguration,
+		Run:                   runCmd(traefikConfiguration),
+		Metadata: map[string]string{
+			"parseAllSources": "true",
+		},
+	}
+}
+
+func runCmd(traefikConfiguration *cmd.TraefikConfiguration) func() error {
+	return func() error {
+		traefikConfiguration.Configuration.SetEffectiveConfiguration(traefikConfiguration.ConfigFile)
+
+		result, err := Do(traefikConfiguration.Configuration)
+		if err != nil {
+			fmt.Printf("Error validating the configuration: %s\n", err)
+			os.Exit(1)
+		}
+
+		if !result.Valid {
+			for _, validationError := range result.Errors {
+				fmt.Printf("%s %s: %s\n", validationError.Kind, validationError.Name, validationError.Message)
+			}
+			os.Exit(1)
+		}
+
+		fmt.Println("OK: the configuration is valid")
+		os.Exit(0)
+		return nil
+	}
+}
+
+// Do loads the dynamic configuration of the file provider, and validates it.
+func Do(staticConfiguration static.Configuration) (*router.ValidationResult, error) {
+	if staticConfiguration.Providers == nil || staticConfiguration.Providers.File == nil {
+		return nil, errors.New("please enable the file provider to validate its configuration")
+	}
+
+	conf, err := staticConfiguration.Providers.File.BuildConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	var entryPoints []string
+	for name := range staticConfiguration.EntryPoints {
+		entryPoints = append(entryPoints, name)
+	}
+
+	result := router.ValidateConfiguration(context.Background(), conf, entryPoints, plugins.Load(context.Background(), staticConfiguration.Plugins))
+	return &result, nil
+}
@@ -7,16 +7,63 @@ import (
 	"io/ioutil"
 	"os"
 
+	"github.com/containous/flaeg/parse"
 	traefiktls "github.com/containous/traefik/tls"
 )
 
 // Router holds the router configuration.
 type Router struct {
-	EntryPoints []string `json:"entryPoints"`
-	Middlewares []string `json:"middlewares,omitempty" toml:",omitempty"`
-	Service     string   `json:"service,omitempty" toml:",omitempty"`
-	Rule        string   `json:"rule,omitempty" toml:",omitempty"`
-	Priority    int      `json:"priority,omitempty" toml:"priority,omitzero"`
+	EntryPoints   []string       `json:"entryPoints"`
+	Middlewares   []string       `json:"middlewares,omitempty" toml:",omitempty"`
+	Service       string         `json:"service,omitempty" toml:",omitempty"`
+	Rule          string         `json:"rule,omitempty" toml:",omitempty"`
+	Priority      int            `json:"priority,omitempty" toml:"priority,omitzero"`
+	LowPriority   bool           `json:"lowPriority,omitempty" toml:",omitempty"`
+	PriorityClass string         `json:"priorityClass,omitempty" toml:",omitempty"`
+	SLO           *RouterSLO     `json:"slo,omitempty" toml:",omitempty"`
+	Tracing       *RouterTracing `json:"tracing,omitempty" toml:",omitempty"`
+}
+
+// RouterSLO holds the latency objective of a router: the Objective ratio of its requests are expected to take less than Latency.
+// The requests over Latency are logged to the slow log, and the burn rate of the objective is computed over Window.
+type RouterSLO struct {
+	Latency   parse.Duration `json:"latency,omitempty" toml:",omitempty"`
+	Objective float64        `json:"objective,omitempty" toml:",omitempty"`
+	Window    parse.Duration `json:"window,omitempty" toml:",omitempty"`
+}
+
+// RouterTracing holds the tracing of a router: its traces are sampled at SampleRate (between 0.0 and 1.0),
+// instead of the sampler of the tracing backend.
+type RouterTracing struct {
+	SampleRate float64 `json:"sampleRate,omitempty" toml:",omitempty"`
+}
+
+// TCPRouter holds the TCP router configuration.
+type TCPRouter struct {
+	EntryPoints []string            `json:"entryPoints"`
+	Service     string              `json:"service,omitempty" toml:",omitempty"`
+	Rule        string              `json:"rule,omitempty" toml:",omitempty"`
+	TLS         *RouterTCPTLSConfig `json:"tls,omitempty" toml:"tls,omitempty" label:"allowEmpty"`
+}
+
+// RouterTCPTLSConfig holds the TLS configuration of a TCP router.
+// Without passthrough, TLS is terminated with the certificates of the entry point.
+type RouterTCPTLSConfig struct {
+	Passthrough bool `json:"passthrough" toml:"passthrough"`
+}
+
+// TCPLoadBalancerService holds the TCP load balancer configuration.
+// With Splice, the bytes of the connections not terminated by Traefik are copied by the kernel, on Linux.
+// With ProxyProtocol, the connections to the servers start with a PROXY protocol version 2 header.
+type TCPLoadBalancerService struct {
+	Servers       []TCPServer `json:"servers,omitempty" toml:",omitempty" label-slice-as-struct:"server"`
+	Splice        bool        `json:"splice,omitempty" toml:",omitempty"`
+	ProxyProtocol bool        `json:"proxyProtocol,omitempty" toml:",omitempty"`
+}
+
+// TCPServer holds a TCP server configuration.
+type TCPServer struct {
+	Address string `json:"address"`
 }
 
 // LoadBalancerService holds the LoadBalancerService configuration.
@@ -27,11 +74,96 @@ type LoadBalancerService struct {
 	HealthCheck        *HealthCheck        `json:"healthCheck,omitempty" toml:",omitempty"`
 	PassHostHeader     bool                `json:"passHostHeader" toml:",omitempty"`
 	ResponseForwarding *ResponseForwarding `json:"forwardingResponse,omitempty" toml:",omitempty"`
+	Scheduling         *Scheduling         `json:"scheduling,omitempty" toml:",omitempty"`
+	Failover           *Failover           `json:"failover,omitempty" toml:",omitempty"`
+	ServersTLS         *ServersTLS         `json:"serversTLS,omitempty" toml:",omitempty"`
+	DNSResolution      *DNSResolution      `json:"dnsResolution,omitempty" toml:",omitempty"`
+	Dial               *Dial               `json:"dial,omitempty" toml:",omitempty"`
+	Proxy              *Proxy              `json:"proxy,omitempty" toml:",omitempty"`
+	// RemovalGracePeriod is how long the requests in flight to a server removed from the service can take, before being canceled.
+	RemovalGracePeriod parse.Duration `json:"removalGracePeriod,omitempty" toml:",omitempty"`
+}
+
+// DNSResolution holds the resolution of the hostnames of the servers of a service:
+// they are resolved again once the TTL of their records expires, or every Interval when set,
+// and the connections are spread over their addresses.
+// ResolvConfig is the resolver configuration file, /etc/resolv.conf by default.
+type DNSResolution struct {
+	Interval     parse.Duration `json:"interval,omitempty" toml:",omitempty"`
+	ResolvConfig string         `json:"resolvConfig,omitempty" toml:",omitempty"`
+}
+
+// Dial holds how the connections to the servers of a service are opened.
+// AddressFamily restricts the addresses dialed to ipv4 or ipv6, or dials the IPv6 addresses first with preferIPv6,
+// both families are dialed by default, the IPv4 addresses first.
+// The next address, alternating the families, is dialed when the previous attempt fails or lasts FallbackDelay (Happy Eyeballs), 250ms by default.
+// LocalAddress is the source IP address of the connections, and Interface the network interface they are bound to (Linux only).
+type Dial struct {
+	AddressFamily string         `json:"addressFamily,omitempty" toml:",omitempty"`
+	FallbackDelay parse.Duration `json:"fallbackDelay,omitempty" toml:",omitempty"`
+	LocalAddress  string         `json:"localAddress,omitempty" toml:",omitempty"`
+	Interface     string         `json:"interface,omitempty" toml:",omitempty"`
+}
+
+// Proxy holds the outbound proxy the connections to the servers go through.
+// URL is the address of an HTTP proxy (http or https), the connections being tunneled with CONNECT, or of a SOCKS5 proxy (socks5),
+// with the credentials in its user info, if any.
+// NoProxy holds the hosts connected to directly: hostnames, domains with a leading dot matching their subdomains, IP addresses and CIDR ranges.
+type Proxy struct {
+	URL     string   `json:"url,omitempty" toml:",omitempty"`
+	NoProxy []string `json:"noProxy,omitempty" toml:",omitempty"`
+}
+
+// ServersTLS holds the TLS configuration of the connections to the HTTPS servers of a service.
+// The certificate of a server is verified by the CA, or by the system roots if there is none,
+// and must have a certificate of its verified chain matching one of the pinned SPKI hashes, if any.
+// PinnedSPKI holds the base64 encoded SHA-256 hashes of the SubjectPublicKeyInfo of the certificates.
+// With InsecureSkipVerify, the chain is not verified, but the leaf certificate must still match one of the pinned SPKI hashes.
+// CA, Cert and Key can be either paths or contents.
+// ServerName overrides the SNI sent to the servers, and the name their certificates are verified for.
+// SessionCacheSize is the number of TLS sessions kept for resumption, the sessions are not resumed when it is zero.
+// ALPN holds the protocols offered to the servers, in order of preference, HTTP/2 is only used when it holds h2.
+type ServersTLS struct {
+	CA                 string   `json:"ca,omitempty" toml:",omitempty"`
+	Cert               string   `json:"cert,omitempty" toml:",omitempty"`
+	Key                string   `json:"key,omitempty" toml:",omitempty"`
+	ServerName         string   `json:"serverName,omitempty" toml:",omitempty"`
+	PinnedSPKI         []string `json:"pinnedSPKI,omitempty" toml:",omitempty"`
+	InsecureSkipVerify bool     `json:"insecureSkipVerify,omitempty" toml:",omitempty"`
+	SessionCacheSize   int      `json:"sessionCacheSize,omitempty" toml:",omitempty"`
+	ALPN               []string `json:"alpn,omitempty" toml:",omitempty"`
+}
+
+// Failover holds the secondary service of a service,
+// which gets the requests while all the servers of the service are down.
+// The requests come back to the service once one of its servers has been up for FailbackDelay.
+type Failover struct {
+	Service       string         `json:"service,omitempty" toml:",omitempty"`
+	FailbackDelay parse.Duration `json:"failbackDelay,omitempty" toml:",omitempty"`
+}
+
+// Scheduling holds the configuration of the scheduling of the requests of a service.
+// Once MaxConcurrency requests are in flight, the next ones wait in a queue,
+// from which they leave by decreasing priority of their class.
+type Scheduling struct {
+	MaxConcurrency int                       `json:"maxConcurrency,omitempty" toml:",omitempty"`
+	QueueTimeout   parse.Duration            `json:"queueTimeout,omitempty" toml:",omitempty"`
+	Classes        map[string]*PriorityClass `json:"classes,omitempty" toml:",omitempty"`
+}
+
+// PriorityClass holds the priority of the requests of a class.
+// A waiting request gains a priority level every Aging duration, so it is not starved by higher priority requests.
+type PriorityClass struct {
+	Priority int            `json:"priority,omitempty" toml:",omitempty"`
+	Aging    parse.Duration `json:"aging,omitempty" toml:",omitempty"`
 }
 
 // ResponseForwarding holds configuration for the forward of the response.
+// BufferSize is the size in bytes of the buffers copying the bodies of the responses, 32KB by default:
+// larger buffers suit the services sending large files, smaller ones the services with many concurrent small responses.
 type ResponseForwarding struct {
 	FlushInterval string `json:"flushInterval,omitempty" toml:",omitempty"`
+	BufferSize    int    `json:"bufferSize,omitempty" toml:",omitempty"`
 }
 
 // Stickiness holds the stickiness configuration.
@@ -40,9 +172,13 @@ type Stickiness struct {
 }
 
 // Server holds the server configuration.
+// A last resort server gets requests only while all the other servers of its service are down:
+// it is typically a remote deployment, health checked with its own HealthCheck when defined.
 type Server struct {
-	URL    string `json:"url"`
-	Weight int    `json:"weight"`
+	URL         string       `json:"url"`
+	Weight      int          `json:"weight"`
+	LastResort  bool         `json:"lastResort,omitempty" toml:",omitempty"`
+	HealthCheck *HealthCheck `json:"healthCheck,omitempty" toml:",omitempty"`
 }
 
 // HealthCheck holds the HealthCheck configuration.
@@ -151,10 +287,57 @@ type Configuration struct {
 	Routers     map[string]*Router          `json:"routers,omitempty" toml:",omitempty"`
 	Middlewares map[string]*Middleware      `json:"middlewares,omitempty" toml:",omitempty"`
 	Services    map[string]*Service         `json:"services,omitempty" toml:",omitempty"`
+	TCPRouters  map[string]*TCPRouter       `json:"tcpRouters,omitempty" toml:",omitempty"`
+	TCPServices map[string]*TCPService      `json:"tcpServices,omitempty" toml:",omitempty"`
 	TLS         []*traefiktls.Configuration `json:"-" label:"-"`
 }
 
 // Service holds a service configuration (can only be of one type at the same time).
 type Service struct {
 	LoadBalancer *LoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
+	BlueGreen    *BlueGreenService    `json:"blueGreen,omitempty" toml:",omitempty,omitzero"`
+	Function     *FunctionService     `json:"function,omitempty" toml:",omitempty,omitzero"`
+}
+
+// BlueGreenService sends the requests to one of two services, the live one, which can be switched through the API.
+// The live service is Blue or Green, Blue by default.
+type BlueGreenService struct {
+	Blue  string `json:"blue,omitempty" toml:",omitempty"`
+	Green string `json:"green,omitempty" toml:",omitempty"`
+	Live  string `json:"live,omitempty" toml:",omitempty"`
+}
+
+// FunctionService invokes a function for each request, instead of proxying it to servers:
+// an AWS Lambda function, or a function behind a CloudEvents HTTP gateway.
+// The request is translated to an API Gateway proxy event, and the function answers with an API Gateway proxy response.
+type FunctionService struct {
+	Lambda      *LambdaFunction      `json:"lambda,omitempty" toml:",omitempty"`
+	CloudEvents *CloudEventsFunction `json:"cloudEvents,omitempty" toml:",omitempty"`
+	// MaxConcurrency is the maximum number of invocations in flight, the requests over it are rejected (no limit when 0).
+	MaxConcurrency int            `json:"maxConcurrency,omitempty" toml:",omitempty"`
+	Timeout        parse.Duration `json:"timeout,omitempty" toml:",omitempty"`
+}
+
+// LambdaFunction holds the AWS Lambda function invoked by a function service.
+// The credentials default to the ones of the environment, the shared credentials file, or the instance role.
+type LambdaFunction struct {
+	Region          string `json:"region,omitempty" toml:",omitempty"`
+	FunctionName    string `json:"functionName,omitempty" toml:",omitempty"`
+	Qualifier       string `json:"qualifier,omitempty" toml:",omitempty"`
+	AccessKeyID     string `json:"accessKeyID,omitempty" toml:",omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty" toml:",omitempty"`
+	// Endpoint overrides the Lambda endpoint of the region.
+	Endpoint string `json:"endpoint,omitempty" toml:",omitempty"`
+}
+
+// CloudEventsFunction holds the CloudEvents HTTP gateway invoked by a function service.
+type CloudEventsFunction struct {
+	URL    string `json:"url,omitempty" toml:",omitempty"`
+	Type   string `json:"type,omitempty" toml:",omitempty"`
+	Source string `json:"source,omitempty" toml:",omitempty"`
+}
+
+// TCPService holds a TCP service configuration (can only be of one type at the same time).
+type TCPService struct {
+	LoadBalancer *TCPLoadBalancerService `json:"loadbalancer,omitempty" toml:",omitempty,omitzero"`
 }
@@ -0,0 +1,149 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ghodss/yaml"
+)
+
+// Formats of the configuration files.
+const (
+	TOML = "toml"
+	YAML = "yaml"
+	JSON = "json"
+)
+
+// FromFilename returns the format of a configuration file from its extension, ignoring the .tmpl suffix of templates.
+// TOML is the default format.
+func FromFilename(filename string) string {
+	switch filepath.Ext(strings.TrimSuffix(filename, ".tmpl")) {
+	case ".yml", ".yaml":
+		return YAML
+	case ".json":
+		return JSON
+	default:
+		return TOML
+	}
+}
+
+// Decode decodes a TOML, YAML or JSON content into the target.
+// The errors include the position (line and column) of the failure when it is known.
+func Decode(format string, content []byte, target interface{}) error {
+	switch format {
+	case YAML:
+		jsonContent, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(jsonContent, target); err != nil {
+			return fmt.Errorf("yaml: %v", err)
+		}
+		return nil
+	case JSON:
+		if err := json.Unmarshal(content, target); err != nil {
+			return jsonError(content, err)
+		}
+		return nil
+	default:
+		_, err := toml.Decode(string(content), target)
+		return err
+	}
+}
+
+// ToTOML converts a YAML or JSON content to TOML.
+func ToTOML(format string, content []byte) ([]byte, error) {
+	if format == TOML {
+		return content, nil
+	}
+
+	if format == YAML {
+		var err error
+		content, err = yaml.YAMLToJSON(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	data := make(map[string]interface{})
+	if err := decoder.Decode(&data); err != nil {
+		return nil, jsonError(content, err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(normalize(data)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// normalize converts the JSON numbers to int64 or float64, and removes the null values which can't be encoded in TOML.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if item == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = normalize(item)
+		}
+		return v
+	case []interface{}:
+		var items []interface{}
+		for _, item := range v {
+			if item != nil {
+				items = append(items, normalize(item))
+			}
+		}
+		return items
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// jsonError adds the line and column of the failure to a JSON error.
+func jsonError(content []byte, err error) error {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return err
+	}
+
+	line, column := position(content, offset)
+	return fmt.Errorf("json: line %d, column %d: %v", line, column, err)
+}
+
+// position returns the line and column of the byte preceding an offset in a content.
+// The offsets of the JSON errors are the number of bytes read, including the failing one.
+func position(content []byte, offset int64) (int, int) {
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	if offset > 0 {
+		offset--
+	}
+
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
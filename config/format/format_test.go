@@ -0,0 +1,135 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sample struct {
+	Name    string
+	Port    int
+	Servers []struct {
+		URL    string
+		Weight int
+	}
+}
+
+func TestFromFilename(t *testing.T) {
+	testCases := map[string]string{
+		"traefik.toml":      TOML,
+		"dynamic.yml":       YAML,
+		"dynamic.yaml.tmpl": YAML,
+		"dynamic.json":      JSON,
+		"dynamic.tmpl":      TOML,
+		"dynamic":           TOML,
+	}
+
+	for filename, expected := range testCases {
+		assert.Equal(t, expected, FromFilename(filename), filename)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		format      string
+		content     string
+		expectedErr string
+	}{
+		{
+			desc:   "TOML",
+			format: TOML,
+			content: `
+name = "foo"
+port = 80
+[[servers]]
+  url = "http://127.0.0.1"
+  weight = 1
+`,
+		},
+		{
+			desc:   "YAML",
+			format: YAML,
+			content: `
+name: foo
+port: 80
+servers:
+  - url: http://127.0.0.1
+    weight: 1
+`,
+		},
+		{
+			desc:    "JSON",
+			format:  JSON,
+			content: `{"name": "foo", "port": 80, "servers": [{"url": "http://127.0.0.1", "weight": 1}]}`,
+		},
+		{
+			desc:        "invalid YAML",
+			format:      YAML,
+			content:     "name: foo\nport: 80\n servers: []\n",
+			expectedErr: "yaml: line 2: mapping values are not allowed in this context",
+		},
+		{
+			desc:        "invalid JSON",
+			format:      JSON,
+			content:     "{\n  \"name\": \"foo\",\n  \"port\": 80,\n}",
+			expectedErr: "json: line 4, column 1: invalid character '}' looking for beginning of object key string",
+		},
+		{
+			desc:        "invalid JSON type",
+			format:      JSON,
+			content:     "{\n  \"name\": \"foo\",\n  \"port\": \"80\"\n}",
+			expectedErr: "json: line 3, column 14: json: cannot unmarshal string into Go struct field",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var s sample
+			err := Decode(test.format, []byte(test.content), &s)
+			if len(test.expectedErr) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "foo", s.Name)
+			assert.Equal(t, 80, s.Port)
+			require.Len(t, s.Servers, 1)
+			assert.Equal(t, "http://127.0.0.1", s.Servers[0].URL)
+			assert.Equal(t, 1, s.Servers[0].Weight)
+		})
+	}
+}
+
+func TestToTOML(t *testing.T) {
+	content := `
+name: foo
+port: 80
+ratio: 0.5
+empty: null
+servers:
+  - url: http://127.0.0.1
+    weight: 1
+`
+
+	tomlContent, err := ToTOML(YAML, []byte(content))
+	require.NoError(t, err)
+
+	var s struct {
+		sample
+		Ratio float64
+	}
+	require.NoError(t, Decode(TOML, tomlContent, &s))
+	assert.Equal(t, "foo", s.Name)
+	assert.Equal(t, 80, s.Port)
+	assert.Equal(t, 0.5, s.Ratio)
+	require.Len(t, s.Servers, 1)
+	assert.Equal(t, 1, s.Servers[0].Weight)
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/containous/traefik/config"
+)
+
+// Actions of a change.
+const (
+	ActionAdded    = "added"
+	ActionRemoved  = "removed"
+	ActionModified = "modified"
+)
+
+// Change is a difference of an element between two configurations.
+type Change struct {
+	ProviderName string      `json:"provider"`
+	Kind         string      `json:"kind"`
+	Name         string      `json:"name"`
+	Action       string      `json:"action"`
+	From         interface{} `json:"from,omitempty"`
+	To           interface{} `json:"to,omitempty"`
+}
+
+// Diff returns the routers, middlewares, services and TLS changes between two sets of provider configurations.
+func Diff(from, to config.Configurations) []Change {
+	providers := make(map[string]struct{})
+	for name := range from {
+		providers[name] = struct{}{}
+	}
+	for name := range to {
+		providers[name] = struct{}{}
+	}
+
+	changes := []Change{}
+	for providerName := range providers {
+		fromConf := from[providerName]
+		if fromConf == nil {
+			fromConf = &config.Configuration{}
+		}
+
+		toConf := to[providerName]
+		if toConf == nil {
+			toConf = &config.Configuration{}
+		}
+
+		changes = append(changes, diffMaps(providerName, "router", routersMap(fromConf.Routers), routersMap(toConf.Routers))...)
+		changes = append(changes, diffMaps(providerName, "middleware", middlewaresMap(fromConf.Middlewares), middlewaresMap(toConf.Middlewares))...)
+		changes = append(changes, diffMaps(providerName, "service", servicesMap(fromConf.Services), servicesMap(toConf.Services))...)
+
+		if !reflect.DeepEqual(fromConf.TLS, toConf.TLS) {
+			change := Change{ProviderName: providerName, Kind: "tls", Action: ActionModified}
+			switch {
+			case len(fromConf.TLS) == 0:
+				change.Action = ActionAdded
+			case len(toConf.TLS) == 0:
+				change.Action = ActionRemoved
+			}
+			// The certificates contents are not exposed.
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ProviderName != changes[j].ProviderName {
+			return changes[i].ProviderName < changes[j].ProviderName
+		}
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+func diffMaps(providerName, kind string, from, to map[string]interface{}) []Change {
+	var changes []Change
+
+	for name, fromValue := range from {
+		toValue, ok := to[name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{ProviderName: providerName, Kind: kind, Name: name, Action: ActionRemoved, From: fromValue})
+		case !reflect.DeepEqual(fromValue, toValue):
+			changes = append(changes, Change{ProviderName: providerName, Kind: kind, Name: name, Action: ActionModified, From: fromValue, To: toValue})
+		}
+	}
+
+	for name, toValue := range to {
+		if _, ok := from[name]; !ok {
+			changes = append(changes, Change{ProviderName: providerName, Kind: kind, Name: name, Action: ActionAdded, To: toValue})
+		}
+	}
+
+	return changes
+}
+
+func routersMap(routers map[string]*config.Router) map[string]interface{} {
+	m := make(map[string]interface{}, len(routers))
+	for name, router := range routers {
+		m[name] = router
+	}
+	return m
+}
+
+func middlewaresMap(middlewares map[string]*config.Middleware) map[string]interface{} {
+	m := make(map[string]interface{}, len(middlewares))
+	for name, middleware := range middlewares {
+		m[name] = middleware
+	}
+	return m
+}
+
+func servicesMap(services map[string]*config.Service) map[string]interface{} {
+	m := make(map[string]interface{}, len(services))
+	for name, service := range services {
+		m[name] = service
+	}
+	return m
+}
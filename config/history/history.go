@@ -0,0 +1,144 @@
+package history
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+)
+
+// DefaultSize is the number of versions kept when no size is configured.
+const DefaultSize = 10
+
+// Version is a set of provider configurations which has been applied.
+type Version struct {
+	Version        uint64                `json:"version"`
+	Date           time.Time             `json:"date"`
+	ProviderName   string                `json:"provider"`
+	RollbackOf     uint64                `json:"rollbackOf,omitempty"`
+	Configurations config.Configurations `json:"-"`
+}
+
+// Summary describes a version, without its configurations.
+type Summary struct {
+	Version      uint64    `json:"version"`
+	Date         time.Time `json:"date"`
+	ProviderName string    `json:"provider"`
+	RollbackOf   uint64    `json:"rollbackOf,omitempty"`
+}
+
+// History keeps the last applied dynamic configurations in memory.
+type History struct {
+	lock     sync.RWMutex
+	size     int
+	last     uint64
+	versions []Version
+
+	rollbacks chan Version
+}
+
+// New creates a history keeping the given number of versions.
+func New(size int) *History {
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	return &History{
+		size:      size,
+		rollbacks: make(chan Version, 1),
+	}
+}
+
+// Add records the configurations applied after a change from the given provider, and returns the new version.
+func (h *History) Add(providerName string, configurations config.Configurations) uint64 {
+	return h.add(Version{ProviderName: providerName, Configurations: configurations})
+}
+
+// AddRollback records the configurations applied by a rollback to the given version, and returns the new version.
+func (h *History) AddRollback(rollbackOf uint64, configurations config.Configurations) uint64 {
+	return h.add(Version{RollbackOf: rollbackOf, Configurations: configurations})
+}
+
+func (h *History) add(version Version) uint64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.last++
+	version.Version = h.last
+	version.Date = time.Now()
+
+	h.versions = append(h.versions, version)
+	if len(h.versions) > h.size {
+		h.versions = h.versions[len(h.versions)-h.size:]
+	}
+
+	return version.Version
+}
+
+// List returns the summaries of the kept versions, the most recent first.
+func (h *History) List() []Summary {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	summaries := make([]Summary, 0, len(h.versions))
+	for i := len(h.versions) - 1; i >= 0; i-- {
+		v := h.versions[i]
+		summaries = append(summaries, Summary{
+			Version:      v.Version,
+			Date:         v.Date,
+			ProviderName: v.ProviderName,
+			RollbackOf:   v.RollbackOf,
+		})
+	}
+	return summaries
+}
+
+// Get returns a kept version.
+func (h *History) Get(version uint64) (Version, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for _, v := range h.versions {
+		if v.Version == version {
+			return v, true
+		}
+	}
+	return Version{}, false
+}
+
+// Diff returns the changes between two kept versions.
+func (h *History) Diff(from, to uint64) ([]Change, error) {
+	fromVersion, ok := h.Get(from)
+	if !ok {
+		return nil, fmt.Errorf("version %d not found", from)
+	}
+
+	toVersion, ok := h.Get(to)
+	if !ok {
+		return nil, fmt.Errorf("version %d not found", to)
+	}
+
+	return Diff(fromVersion.Configurations, toVersion.Configurations), nil
+}
+
+// Rollback requests the configurations of a kept version to be applied again.
+// The request is handled asynchronously by the consumer of Rollbacks.
+func (h *History) Rollback(version uint64) error {
+	v, ok := h.Get(version)
+	if !ok {
+		return fmt.Errorf("version %d not found", version)
+	}
+
+	select {
+	case h.rollbacks <- v:
+		return nil
+	default:
+		return fmt.Errorf("a rollback is already pending")
+	}
+}
+
+// Rollbacks returns the channel of the versions to apply again.
+func (h *History) Rollbacks() <-chan Version {
+	return h.rollbacks
+}
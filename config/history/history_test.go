@@ -0,0 +1,131 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory_Add(t *testing.T) {
+	h := New(2)
+
+	for _, providerName := range []string{"file", "docker", "rest"} {
+		h.Add(providerName, config.Configurations{providerName: &config.Configuration{}})
+	}
+
+	summaries := h.List()
+	require.Len(t, summaries, 2)
+	assert.Equal(t, uint64(3), summaries[0].Version)
+	assert.Equal(t, "rest", summaries[0].ProviderName)
+	assert.Equal(t, uint64(2), summaries[1].Version)
+	assert.Equal(t, "docker", summaries[1].ProviderName)
+
+	_, ok := h.Get(1)
+	assert.False(t, ok)
+
+	version, ok := h.Get(2)
+	require.True(t, ok)
+	assert.Contains(t, version.Configurations, "docker")
+}
+
+func TestHistory_Rollback(t *testing.T) {
+	h := New(0)
+	h.Add("file", config.Configurations{"file": &config.Configuration{}})
+
+	require.Error(t, h.Rollback(2))
+	require.NoError(t, h.Rollback(1))
+	assert.Error(t, h.Rollback(1), "a rollback is already pending")
+
+	version := <-h.Rollbacks()
+	assert.Equal(t, uint64(1), version.Version)
+
+	newVersion := h.AddRollback(version.Version, version.Configurations)
+	assert.Equal(t, uint64(2), newVersion)
+	assert.Equal(t, uint64(1), h.List()[0].RollbackOf)
+}
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		from     config.Configurations
+		to       config.Configurations
+		expected []Change
+	}{
+		{
+			desc: "same configurations",
+			from: config.Configurations{
+				"file": {Routers: map[string]*config.Router{"foo": {Rule: "Host:foo"}}},
+			},
+			to: config.Configurations{
+				"file": {Routers: map[string]*config.Router{"foo": {Rule: "Host:foo"}}},
+			},
+			expected: []Change{},
+		},
+		{
+			desc: "added, modified and removed elements",
+			from: config.Configurations{
+				"file": {
+					Routers:  map[string]*config.Router{"foo": {Rule: "Host:foo"}},
+					Services: map[string]*config.Service{"old": {}},
+				},
+			},
+			to: config.Configurations{
+				"file": {
+					Routers:     map[string]*config.Router{"foo": {Rule: "Host:bar"}},
+					Middlewares: map[string]*config.Middleware{"prefix": {AddPrefix: &config.AddPrefix{Prefix: "/foo"}}},
+				},
+			},
+			expected: []Change{
+				{
+					ProviderName: "file",
+					Kind:         "middleware",
+					Name:         "prefix",
+					Action:       ActionAdded,
+					To:           &config.Middleware{AddPrefix: &config.AddPrefix{Prefix: "/foo"}},
+				},
+				{
+					ProviderName: "file",
+					Kind:         "router",
+					Name:         "foo",
+					Action:       ActionModified,
+					From:         &config.Router{Rule: "Host:foo"},
+					To:           &config.Router{Rule: "Host:bar"},
+				},
+				{
+					ProviderName: "file",
+					Kind:         "service",
+					Name:         "old",
+					Action:       ActionRemoved,
+					From:         &config.Service{},
+				},
+			},
+		},
+		{
+			desc: "removed provider",
+			from: config.Configurations{
+				"docker": {Routers: map[string]*config.Router{"foo": {Rule: "Host:foo"}}},
+			},
+			to: config.Configurations{},
+			expected: []Change{
+				{
+					ProviderName: "docker",
+					Kind:         "router",
+					Name:         "foo",
+					Action:       ActionRemoved,
+					From:         &config.Router{Rule: "Host:foo"},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, Diff(test.from, test.to))
+		})
+	}
+}
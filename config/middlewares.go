@@ -1,32 +1,55 @@
 package config
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/ip"
 )
 
 // Middleware holds the Middleware configuration.
 type Middleware struct {
-	AddPrefix         *AddPrefix         `json:"addPrefix,omitempty"`
-	StripPrefix       *StripPrefix       `json:"stripPrefix,omitempty"`
-	StripPrefixRegex  *StripPrefixRegex  `json:"stripPrefixRegex,omitempty"`
-	ReplacePath       *ReplacePath       `json:"replacePath,omitempty"`
-	ReplacePathRegex  *ReplacePathRegex  `json:"replacePathRegex,omitempty"`
-	Chain             *Chain             `json:"chain,omitempty"`
-	IPWhiteList       *IPWhiteList       `json:"ipWhiteList,omitempty"`
-	Headers           *Headers           `json:"headers,omitempty"`
-	Errors            *ErrorPage         `json:"errors,omitempty"`
-	RateLimit         *RateLimit         `json:"rateLimit,omitempty"`
-	Redirect          *Redirect          `json:"redirect,omitempty"`
-	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty"`
-	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty"`
-	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty"`
-	MaxConn           *MaxConn           `json:"maxConn,omitempty"`
-	Buffering         *Buffering         `json:"buffering,omitempty"`
-	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty"`
-	Compress          *Compress          `json:"compress,omitempty" label:"allowEmpty"`
-	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty"`
-	Retry             *Retry             `json:"retry,omitempty"`
+	AddPrefix           *AddPrefix           `json:"addPrefix,omitempty"`
+	AnyAuth             *AnyAuth             `json:"anyAuth,omitempty"`
+	StripPrefix         *StripPrefix         `json:"stripPrefix,omitempty"`
+	StripPrefixRegex    *StripPrefixRegex    `json:"stripPrefixRegex,omitempty"`
+	ReplacePath         *ReplacePath         `json:"replacePath,omitempty"`
+	ReplacePathRegex    *ReplacePathRegex    `json:"replacePathRegex,omitempty"`
+	Chain               *Chain               `json:"chain,omitempty"`
+	IPWhiteList         *IPWhiteList         `json:"ipWhiteList,omitempty"`
+	Headers             *Headers             `json:"headers,omitempty"`
+	Errors              *ErrorPage           `json:"errors,omitempty"`
+	FaultInjection      *FaultInjection      `json:"faultInjection,omitempty"`
+	GraphQL             *GraphQL             `json:"graphQL,omitempty"`
+	GRPCTranscoding     *GRPCTranscoding     `json:"grpcTranscoding,omitempty"`
+	RateLimit           *RateLimit           `json:"rateLimit,omitempty"`
+	Redirect            *Redirect            `json:"redirect,omitempty"`
+	BasicAuth           *BasicAuth           `json:"basicAuth,omitempty"`
+	Bandwidth           *Bandwidth           `json:"bandwidth,omitempty"`
+	DigestAuth          *DigestAuth          `json:"digestAuth,omitempty"`
+	ForwardAuth         *ForwardAuth         `json:"forwardAuth,omitempty"`
+	MaxConn             *MaxConn             `json:"maxConn,omitempty"`
+	Buffering           *Buffering           `json:"buffering,omitempty"`
+	Capture             *Capture             `json:"capture,omitempty"`
+	CircuitBreaker      *CircuitBreaker      `json:"circuitBreaker,omitempty"`
+	CollapseForwarding  *CollapseForwarding  `json:"collapseForwarding,omitempty" label:"allowEmpty"`
+	Compress            *Compress            `json:"compress,omitempty" label:"allowEmpty"`
+	ConditionalRequests *ConditionalRequests `json:"conditionalRequests,omitempty" label:"allowEmpty"`
+	ContentRouting      *ContentRouting      `json:"contentRouting,omitempty"`
+	CORS                *CORS                `json:"cors,omitempty"`
+	CSRF                *CSRF                `json:"csrf,omitempty"`
+	Experiment          *Experiment          `json:"experiment,omitempty"`
+	PassTLSClientCert   *PassTLSClientCert   `json:"passTLSClientCert,omitempty"`
+	Plugin              *Plugin              `json:"plugin,omitempty"`
+	Retry               *Retry               `json:"retry,omitempty"`
+	RequestBuffering    *RequestBuffering    `json:"requestBuffering,omitempty"`
+	RequestID           *RequestID           `json:"requestID,omitempty" label:"allowEmpty"`
+	SecureHeaders       *SecureHeaders       `json:"secureHeaders,omitempty" label:"allowEmpty"`
+	SessionGateway      *SessionGateway      `json:"sessionGateway,omitempty"`
+	Tenant              *Tenant              `json:"tenant,omitempty"`
+	TimeWindow          *TimeWindow          `json:"timeWindow,omitempty"`
+	UpstreamAuth        *UpstreamAuth        `json:"upstreamAuth,omitempty"`
 }
 
 // AddPrefix holds the AddPrefix configuration.
@@ -34,6 +57,11 @@ type AddPrefix struct {
 	Prefix string `json:"prefix,omitempty"`
 }
 
+// AnyAuth holds the AnyAuth configuration.
+type AnyAuth struct {
+	Middlewares []string `description:"Authentication middlewares, tried in order until one of them authenticates the request" json:"middlewares,omitempty"`
+}
+
 // Auth holds the authentication configuration (BASIC, DIGEST, users).
 type Auth struct {
 	Basic   *BasicAuth   `json:"basic,omitempty" export:"true"`
@@ -41,6 +69,14 @@ type Auth struct {
 	Forward *ForwardAuth `json:"forward,omitempty" export:"true"`
 }
 
+// Bandwidth holds the bandwidth limiting configuration.
+type Bandwidth struct {
+	Download      int64  `description:"Maximum download rate, in bytes per second" json:"download,omitempty"`
+	Upload        int64  `description:"Maximum upload rate, in bytes per second" json:"upload,omitempty"`
+	Burst         int64  `description:"Number of bytes which can be sent at once (default one second of the rate)" json:"burst,omitempty"`
+	ExtractorFunc string `description:"Source sharing the bandwidth (client.ip, request.host, request.header.<name>), per connection if empty" json:"extractorFunc,omitempty"`
+}
+
 // BasicAuth holds the HTTP basic authentication configuration.
 type BasicAuth struct {
 	Users        `json:"users,omitempty" mapstructure:","`
@@ -59,6 +95,22 @@ type Buffering struct {
 	RetryExpression      string `json:"retryExpression,omitempty"`
 }
 
+// Capture holds the traffic capture configuration.
+type Capture struct {
+	SampleRate   float64       `description:"Ratio of the requests captured, between 0 and 1 (all the requests when 0)" json:"sampleRate,omitempty"`
+	MaxBodyBytes int64         `description:"Maximum size of the captured request bodies (default 64KB)" json:"maxBodyBytes,omitempty"`
+	FilePath     string        `description:"File the captured requests are appended to" json:"filePath,omitempty"`
+	Kafka        *CaptureKafka `description:"Kafka topic the captured requests are produced to" json:"kafka,omitempty"`
+	ScrubHeaders []string      `description:"Headers whose values are redacted" json:"scrubHeaders,omitempty"`
+	ScrubFields  []string      `description:"Query parameters, form and JSON body fields whose values are redacted" json:"scrubFields,omitempty"`
+}
+
+// CaptureKafka holds the Kafka destination of the captured requests.
+type CaptureKafka struct {
+	Brokers []string `description:"Addresses of the Kafka brokers" json:"brokers,omitempty"`
+	Topic   string   `description:"Kafka topic" json:"topic,omitempty"`
+}
+
 // Chain holds a chain of middlewares
 type Chain struct {
 	Middlewares []string `json:"middlewares"`
@@ -69,9 +121,46 @@ type CircuitBreaker struct {
 	Expression string `json:"expression,omitempty"`
 }
 
+// CollapseForwarding holds the configuration of the collapsing of the identical GET requests.
+type CollapseForwarding struct {
+	VaryHeaders []string `description:"Request headers whose values must match, besides the URL, for requests to be collapsed" json:"varyHeaders,omitempty"`
+}
+
 // Compress holds the compress configuration.
 type Compress struct{}
 
+// ConditionalRequests holds the configuration of the conditional requests handling.
+type ConditionalRequests struct {
+	MaxBodyBytes int64 `description:"Maximum size of a response body hashed to compute its ETag (default 1MB)" json:"maxBodyBytes,omitempty"`
+}
+
+// CORS holds the Cross-Origin Resource Sharing configuration.
+type CORS struct {
+	AllowedOrigins      []string `description:"Origins allowed to access the resources: exact origins, * for all of them (not along with the credentials), or wildcard subdomains (https://*.example.com)" json:"allowedOrigins,omitempty"`
+	AllowedOriginsRegex []string `description:"Regular expressions matching the whole origins allowed to access the resources" json:"allowedOriginsRegex,omitempty"`
+	AllowedMethods      []string `description:"Methods allowed in the actual requests (default GET, HEAD and POST)" json:"allowedMethods,omitempty"`
+	AllowedHeaders      []string `description:"Headers allowed in the actual requests, or * for all of them" json:"allowedHeaders,omitempty"`
+	ExposedHeaders      []string `description:"Response headers exposed to the clients" json:"exposedHeaders,omitempty"`
+	AllowCredentials    bool     `description:"Allow the requests with credentials" json:"allowCredentials,omitempty"`
+	MaxAge              int64    `description:"Number of seconds the result of a preflight request can be cached" json:"maxAge,omitempty"`
+}
+
+// CSRF holds the Cross-Site Request Forgery protection configuration.
+type CSRF struct {
+	Mode           string         `description:"Protection mode: doubleSubmit (default) or synchronizer" json:"mode,omitempty"`
+	Secret         string         `description:"Secret signing the tokens, required by the synchronizer mode" json:"secret,omitempty"`
+	SafeMethods    []string       `description:"Methods which are not checked (default GET, HEAD, OPTIONS and TRACE)" json:"safeMethods,omitempty"`
+	HeaderName     string         `description:"Header carrying the token (default X-CSRF-Token)" json:"headerName,omitempty"`
+	FormField      string         `description:"Form field carrying the token (default csrf_token)" json:"formField,omitempty"`
+	TokenTTL       parse.Duration `description:"Lifetime of the tokens (default 12h)" json:"tokenTTL,omitempty"`
+	CookieName     string         `description:"Name of the cookie (default _csrf)" json:"cookieName,omitempty"`
+	CookieDomain   string         `description:"Domain of the cookie" json:"cookieDomain,omitempty"`
+	CookiePath     string         `description:"Path of the cookie (default /)" json:"cookiePath,omitempty"`
+	CookieSecure   bool           `description:"Send the cookie over HTTPS only" json:"cookieSecure,omitempty"`
+	CookieSameSite string         `description:"SameSite attribute of the cookie: lax (default) or strict" json:"cookieSameSite,omitempty"`
+	ExemptPaths    []string       `description:"Path prefixes which are not checked, like API paths authenticated otherwise" json:"exemptPaths,omitempty"`
+}
+
 // DigestAuth holds the Digest HTTP authentication configuration.
 type DigestAuth struct {
 	Users        `json:"users,omitempty" mapstructure:","`
@@ -88,12 +177,83 @@ type ErrorPage struct {
 	Query   string   `json:"query,omitempty"`
 }
 
+// ContentRouting holds the configuration of the routing of the requests by a value of their body,
+// extracted from a JSON body by a path like "order.version", or from an XML body by an XPath like "/Envelope/Body/*/@version".
+// The requests without a matching route, or with a body exceeding the maximum size, go on to the service of the router.
+type ContentRouting struct {
+	JSONPath    string                `description:"Path of the value of a JSON body, its keys and array indexes separated by dots" json:"jsonPath,omitempty"`
+	XPath       string                `description:"XPath of the value of an XML body, like /Envelope/Body/GetOrder/Version or local-name(/Envelope/Body/*)" json:"xPath,omitempty"`
+	MaxBodySize int64                 `description:"Maximum size of the bodies read to extract the value (default 1MB)" json:"maxBodySize,omitempty"`
+	Routes      []ContentRoutingRoute `description:"Services of the extracted values" json:"routes,omitempty"`
+}
+
+// ContentRoutingRoute holds the service of a value extracted from the body of the requests.
+type ContentRoutingRoute struct {
+	Value   string `description:"Extracted value" json:"value,omitempty"`
+	Service string `description:"Service getting the requests with the value" json:"service,omitempty"`
+}
+
+// GraphQL holds the configuration of the protection of a GraphQL server against the abusive queries.
+// The depth of a query is the number of its nested fields, its complexity the number of the fields it selects, the fragments being expanded.
+type GraphQL struct {
+	MaxDepth             int    `description:"Maximum depth of the queries (no maximum when 0)" json:"maxDepth,omitempty"`
+	MaxComplexity        int    `description:"Maximum complexity of the queries (no maximum when 0)" json:"maxComplexity,omitempty"`
+	DisableIntrospection bool   `description:"Reject the queries of the schema" json:"disableIntrospection,omitempty"`
+	OperationHeader      string `description:"Request header set to the name of the operation, as a key for the rate limiting (default X-GraphQL-Operation)" json:"operationHeader,omitempty"`
+	MaxBodySize          int64  `description:"Maximum size of the request bodies (default 1MB)" json:"maxBodySize,omitempty"`
+}
+
+// GRPCTranscoding holds the configuration of the transcoding of the RESTful JSON requests into calls of the methods of a gRPC service,
+// mapped by the google.api.http annotations of the methods.
+type GRPCTranscoding struct {
+	DescriptorSet string   `description:"File of the protobuf descriptor set of the services, built by protoc with --include_imports --descriptor_set_out" json:"descriptorSet,omitempty"`
+	Services      []string `description:"Fully qualified names of the transcoded services (default all the services of the descriptor set)" json:"services,omitempty"`
+}
+
+// Experiment holds the configuration of an A/B test, assigning each request to a variant by the hash of a key:
+// the value of the header, else of the cookie, else the client IP. A request with the variant cookie keeps its variant.
+type Experiment struct {
+	HeaderName    string              `description:"Header whose value assigns a request to a variant, like an API key" json:"headerName,omitempty"`
+	CookieName    string              `description:"Cookie whose value assigns a request to a variant, without the header" json:"cookieName,omitempty"`
+	VariantHeader string              `description:"Header of the requests holding their variant (default X-Experiment-Variant)" json:"variantHeader,omitempty"`
+	VariantCookie string              `description:"Cookie keeping the variant of the client (none when empty)" json:"variantCookie,omitempty"`
+	Variants      []ExperimentVariant `description:"Variants of the experiment" json:"variants,omitempty"`
+}
+
+// ExperimentVariant holds a variant of an experiment.
+type ExperimentVariant struct {
+	Name    string `description:"Name of the variant" json:"name,omitempty"`
+	Weight  int    `description:"Share of the requests assigned to the variant, relative to the other variants (default 1)" json:"weight,omitempty"`
+	Service string `description:"Service getting the requests assigned to the variant, instead of the service of the router" json:"service,omitempty"`
+}
+
+// FaultInjection holds the latency and fault injection configuration.
+type FaultInjection struct {
+	Delay       *FaultDelay `description:"Delay added to the requests" json:"delay,omitempty"`
+	Abort       *FaultAbort `description:"Error response returned instead of forwarding the requests" json:"abort,omitempty"`
+	HeaderName  string      `description:"Header a request must have to get faults injected (all the requests when empty)" json:"headerName,omitempty"`
+	HeaderValue string      `description:"Value the header must have (any value when empty)" json:"headerValue,omitempty"`
+}
+
+// FaultDelay holds the delay injected in the requests.
+type FaultDelay struct {
+	Duration   parse.Duration `description:"Delay added before forwarding a request" json:"duration,omitempty"`
+	Percentage float64        `description:"Percentage of the requests delayed (all the requests when 0)" json:"percentage,omitempty"`
+}
+
+// FaultAbort holds the error response injected instead of forwarding the requests.
+type FaultAbort struct {
+	StatusCode int     `description:"Status code of the error response" json:"statusCode,omitempty"`
+	Percentage float64 `description:"Percentage of the requests aborted (all the requests when 0)" json:"percentage,omitempty"`
+}
+
 // ForwardAuth holds the http forward authentication configuration.
 type ForwardAuth struct {
-	Address             string     `description:"Authentication server address" json:"address,omitempty"`
-	TLS                 *ClientTLS `description:"Enable TLS support" json:"tls,omitempty" export:"true"`
-	TrustForwardHeader  bool       `description:"Trust X-Forwarded-* headers" json:"trustForwardHeader,omitempty" export:"true"`
-	AuthResponseHeaders []string   `description:"Headers to be forwarded from auth response" json:"authResponseHeaders,omitempty"`
+	Address             string            `description:"Authentication server address" json:"address,omitempty"`
+	TLS                 *ClientTLS        `description:"Enable TLS support" json:"tls,omitempty" export:"true"`
+	TrustForwardHeader  bool              `description:"Trust X-Forwarded-* headers" json:"trustForwardHeader,omitempty" export:"true"`
+	AuthResponseHeaders []string          `description:"Headers to be forwarded from auth response" json:"authResponseHeaders,omitempty"`
+	AuthRequestHeaders  map[string]string `description:"Headers added to the requests to the authentication server, whose values can be secret references" json:"authRequestHeaders,omitempty"`
 }
 
 // Headers holds the custom header configuration.
@@ -205,6 +365,12 @@ type PassTLSClientCert struct {
 	Infos *TLSClientCertificateInfos `description:"Enable header with configured client cert infos" json:"infos,omitempty"`
 }
 
+// Plugin holds the configuration of a middleware provided by a plugin.
+type Plugin struct {
+	Name     string            `json:"name,omitempty"`
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
 // Rate holds the rate limiting configuration for a specific time period.
 type Rate struct {
 	Period  parse.Duration `json:"period,omitempty"`
@@ -213,10 +379,28 @@ type Rate struct {
 }
 
 // RateLimit holds the rate limiting configuration for a given frontend.
+// In the reject mode (the default), the requests over the rates are rejected right away.
+// In the leakyBucket mode, they are delayed to conform to the rates, and rejected only if they would wait longer than MaxDelay.
 type RateLimit struct {
 	RateSet map[string]*Rate `json:"rateset,omitempty"`
 	// FIXME replace by ipStrategy see oxy and replace
-	ExtractorFunc string `json:"extractorFunc,omitempty"`
+	ExtractorFunc string               `json:"extractorFunc,omitempty"`
+	Mode          string               `json:"mode,omitempty"`
+	MaxDelay      parse.Duration       `json:"maxDelay,omitempty"`
+	Exemptions    *RateLimitExemptions `json:"exemptions,omitempty"`
+}
+
+// RateLimitExemptions holds the requests which bypass the rate limiting:
+// a request is exempted if it matches any of the source ranges, API keys, headers or paths.
+type RateLimitExemptions struct {
+	SourceRange []string    `json:"sourceRange,omitempty"`
+	IPStrategy  *IPStrategy `json:"ipStrategy,omitempty"`
+	APIKeys     []string    `json:"apiKeys,omitempty"`
+	// APIKeyHeader is the header holding the API key, X-Api-Key by default.
+	APIKeyHeader string            `json:"apiKeyHeader,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	// Paths are regular expressions matched against the request path.
+	Paths []string `json:"paths,omitempty"`
 }
 
 // Redirect holds the redirection configuration of an entry point to another, or to an URL.
@@ -237,11 +421,133 @@ type ReplacePathRegex struct {
 	Replacement string `json:"replacement,omitempty"`
 }
 
+// RequestBuffering holds the request buffering configuration.
+type RequestBuffering struct {
+	MaxRequestBodyBytes int64 `description:"Maximum size of a request body, larger requests are rejected (no limit when 0)" json:"maxRequestBodyBytes,omitempty"`
+	MemRequestBodyBytes int64 `description:"Size of a request body kept in memory, the rest is written to a temporary file (default 1MB)" json:"memRequestBodyBytes,omitempty"`
+}
+
+// RequestID holds the request ID configuration.
+type RequestID struct {
+	HeaderName string   `description:"Header used to read and propagate the request ID (default X-Request-ID)" json:"headerName,omitempty"`
+	Format     string   `description:"Format of the generated request IDs: uuidv7 | uuidv4" json:"format,omitempty"`
+	TrustedIPs []string `description:"Peers allowed to provide their own request ID (any peer when empty)" json:"trustedIPs,omitempty"`
+}
+
 // Retry contains request retry config
 type Retry struct {
 	Attempts int `description:"Number of attempts" export:"true"`
 }
 
+// SecureHeaders holds a preset of security response headers.
+// Strict-Transport-Security (for 1 year), X-Content-Type-Options and Referrer-Policy are sent unless disabled,
+// Permissions-Policy and Content-Security-Policy when their directives are defined.
+type SecureHeaders struct {
+	DisableSTS                bool                `description:"Disable the Strict-Transport-Security header" json:"disableSTS,omitempty"`
+	STSSeconds                int64               `description:"max-age of the Strict-Transport-Security header (default 1 year)" json:"stsSeconds,omitempty"`
+	STSIncludeSubdomains      bool                `description:"Add includeSubDomains to the Strict-Transport-Security header" json:"stsIncludeSubdomains,omitempty"`
+	STSPreload                bool                `description:"Add preload to the Strict-Transport-Security header" json:"stsPreload,omitempty"`
+	DisableContentTypeNosniff bool                `description:"Disable the X-Content-Type-Options header" json:"disableContentTypeNosniff,omitempty"`
+	ReferrerPolicy            string              `description:"Value of the Referrer-Policy header (default strict-origin-when-cross-origin)" json:"referrerPolicy,omitempty"`
+	DisableReferrerPolicy     bool                `description:"Disable the Referrer-Policy header" json:"disableReferrerPolicy,omitempty"`
+	PermissionsPolicy         map[string][]string `description:"Allowlists of the Permissions-Policy features (self, * or origins)" json:"permissionsPolicy,omitempty"`
+	ContentSecurityPolicy     map[string][]string `description:"Sources of the Content-Security-Policy directives" json:"contentSecurityPolicy,omitempty"`
+	CSPReportURI              string              `description:"URI the Content-Security-Policy violations are reported to" json:"cspReportURI,omitempty"`
+	CSPReportOnly             bool                `description:"Send the Content-Security-Policy-Report-Only header instead of Content-Security-Policy" json:"cspReportOnly,omitempty"`
+}
+
+// DefaultSTSSeconds is the max-age of the Strict-Transport-Security header of the secure headers preset.
+const DefaultSTSSeconds = 365 * 24 * 60 * 60
+
+// DefaultReferrerPolicy is the Referrer-Policy of the secure headers preset.
+const DefaultReferrerPolicy = "strict-origin-when-cross-origin"
+
+// Headers returns the configuration of the Headers middleware applying the preset.
+func (s *SecureHeaders) Headers() *Headers {
+	headers := &Headers{
+		CustomResponseHeaders: make(map[string]string),
+	}
+
+	if !s.DisableSTS {
+		headers.STSSeconds = s.STSSeconds
+		if headers.STSSeconds <= 0 {
+			headers.STSSeconds = DefaultSTSSeconds
+		}
+		headers.STSIncludeSubdomains = s.STSIncludeSubdomains
+		headers.STSPreload = s.STSPreload
+	}
+
+	headers.ContentTypeNosniff = !s.DisableContentTypeNosniff
+
+	if !s.DisableReferrerPolicy {
+		headers.ReferrerPolicy = s.ReferrerPolicy
+		if len(headers.ReferrerPolicy) == 0 {
+			headers.ReferrerPolicy = DefaultReferrerPolicy
+		}
+	}
+
+	if policy := s.permissionsPolicy(); len(policy) > 0 {
+		headers.CustomResponseHeaders["Permissions-Policy"] = policy
+	}
+
+	if policy := s.contentSecurityPolicy(); len(policy) > 0 {
+		if s.CSPReportOnly {
+			headers.CustomResponseHeaders["Content-Security-Policy-Report-Only"] = policy
+		} else {
+			headers.ContentSecurityPolicy = policy
+		}
+	}
+
+	return headers
+}
+
+// contentSecurityPolicy builds the Content-Security-Policy value, with the directives sorted by name.
+func (s *SecureHeaders) contentSecurityPolicy() string {
+	var directives []string
+	for name, sources := range s.ContentSecurityPolicy {
+		directives = append(directives, strings.TrimSpace(strings.Join(append([]string{name}, sources...), " ")))
+	}
+	sort.Strings(directives)
+
+	if len(s.CSPReportURI) > 0 {
+		directives = append(directives, "report-uri "+s.CSPReportURI)
+	}
+
+	return strings.Join(directives, "; ")
+}
+
+// permissionsPolicy builds the Permissions-Policy value, with the features sorted by name.
+func (s *SecureHeaders) permissionsPolicy() string {
+	var features []string
+	for name, allowlist := range s.PermissionsPolicy {
+		var origins []string
+		for _, origin := range allowlist {
+			switch origin {
+			case "self", "*":
+				origins = append(origins, origin)
+			default:
+				origins = append(origins, `"`+strings.Trim(origin, `"`)+`"`)
+			}
+		}
+		features = append(features, name+"=("+strings.Join(origins, " ")+")")
+	}
+	sort.Strings(features)
+
+	return strings.Join(features, ", ")
+}
+
+// SessionGateway holds the session gateway configuration.
+type SessionGateway struct {
+	Secret          string         `description:"Secret encrypting and signing the session cookie" json:"secret,omitempty"`
+	IdleTimeout     parse.Duration `description:"Duration after which a session without requests expires (default 30m)" json:"idleTimeout,omitempty"`
+	AbsoluteTimeout parse.Duration `description:"Duration after which a session expires, whatever its activity (default 12h)" json:"absoluteTimeout,omitempty"`
+	CookieName      string         `description:"Name of the session cookie (default _traefik_session)" json:"cookieName,omitempty"`
+	CookieDomain    string         `description:"Domain of the session cookie" json:"cookieDomain,omitempty"`
+	CookiePath      string         `description:"Path of the session cookie (default /)" json:"cookiePath,omitempty"`
+	CookieSecure    bool           `description:"Send the session cookie over HTTPS only" json:"cookieSecure,omitempty"`
+	AllowedCookies  []string       `description:"Client cookies sent to the backend, the other ones being removed" json:"allowedCookies,omitempty"`
+}
+
 // StripPrefix holds the StripPrefix configuration.
 type StripPrefix struct {
 	Prefixes []string `json:"prefixes,omitempty"`
@@ -252,6 +558,59 @@ type StripPrefixRegex struct {
 	Regex []string `json:"regex,omitempty"`
 }
 
+// Tenant holds the tenant isolation configuration.
+// The tenant identifier of the request, from its subdomain, a header or a claim of its JWT, must be one of the allowed tenants,
+// and is forced in the tenant header sent to the servers.
+type Tenant struct {
+	Source     string   `description:"Source of the tenant identifier: header (default), subdomain or claim" json:"source,omitempty"`
+	HeaderName string   `description:"Header carrying the tenant identifier to the servers, and from the client with the header source (default X-Tenant-Id)" json:"headerName,omitempty"`
+	Domain     string   `description:"Parent domain of the tenant subdomains, required by the subdomain source" json:"domain,omitempty"`
+	Claim      string   `description:"Claim of the bearer JWT holding the tenant identifier, with the claim source (default tenant)" json:"claim,omitempty"`
+	Secret     string   `description:"Secret verifying the HMAC signed JWTs, with the claim source" json:"secret,omitempty"`
+	PublicKey  string   `description:"Public key (file or content) verifying the RSA or ECDSA signed JWTs, with the claim source" json:"publicKey,omitempty"`
+	Allowed    []string `description:"Tenants allowed on the route, * allowing any tenant" json:"allowed,omitempty"`
+}
+
+// TimeWindow holds the time windows during which the requests are allowed, or denied.
+// A time window is made of optional days and of a time range, like "Mon-Fri 06:00-22:00" or "Sat,Sun 08:00-12:00".
+// A time range ending before it starts spans midnight, and belongs to the day it starts.
+type TimeWindow struct {
+	Windows    []string `description:"Time windows, like Mon-Fri 06:00-22:00 (every day when the days are omitted)" json:"windows,omitempty"`
+	TimeZone   string   `description:"Time zone of the time windows, like Europe/Paris (default UTC)" json:"timeZone,omitempty"`
+	Deny       bool     `description:"Deny the requests during the time windows, instead of allowing them during the time windows only" json:"deny,omitempty"`
+	StatusCode int      `description:"Status code of the response to the denied requests (default 403)" json:"statusCode,omitempty"`
+	Message    string   `description:"Body of the response to the denied requests (default the status text)" json:"message,omitempty"`
+}
+
+// UpstreamAuth holds the credentials attached to the requests sent to the servers, replacing the ones of the client:
+// a static bearer token, an OAuth2 token fetched with the client credentials grant, or an AWS Signature Version 4.
+type UpstreamAuth struct {
+	Bearer string              `description:"Static bearer token (file or content)" json:"bearer,omitempty"`
+	OAuth2 *UpstreamAuthOAuth2 `description:"OAuth2 client credentials grant, the tokens being refreshed before they expire" json:"oauth2,omitempty"`
+	AWS    *UpstreamAuthAWS    `description:"AWS Signature Version 4 signing" json:"aws,omitempty"`
+}
+
+// UpstreamAuthOAuth2 holds the configuration of the OAuth2 client credentials grant.
+type UpstreamAuthOAuth2 struct {
+	TokenURL       string            `description:"Token endpoint of the authorization server" json:"tokenURL,omitempty"`
+	ClientID       string            `description:"Client ID" json:"clientID,omitempty"`
+	ClientSecret   string            `description:"Client secret" json:"clientSecret,omitempty"`
+	Scopes         []string          `description:"Requested scopes" json:"scopes,omitempty"`
+	EndpointParams map[string]string `description:"Additional parameters of the token requests, like an audience" json:"endpointParams,omitempty"`
+}
+
+// UpstreamAuthAWS holds the configuration of the AWS Signature Version 4 signing.
+// Without an access key, the credentials come from the environment, then from the shared credentials file.
+type UpstreamAuthAWS struct {
+	Region          string `description:"Region of the service" json:"region,omitempty"`
+	Service         string `description:"Signing name of the service, like execute-api or es" json:"service,omitempty"`
+	Host            string `description:"Host of the signed requests, as sent to the servers (default the host of the request)" json:"host,omitempty"`
+	AccessKeyID     string `description:"Access key ID" json:"accessKeyID,omitempty"`
+	SecretAccessKey string `description:"Secret access key" json:"secretAccessKey,omitempty"`
+	SessionToken    string `description:"Session token of temporary credentials" json:"sessionToken,omitempty"`
+	MaxBodySize     int64  `description:"Maximum size of the request bodies buffered to be signed, larger requests are rejected (default 1MB)" json:"maxBodySize,omitempty"`
+}
+
 // TLSClientCertificateInfos holds the client TLS certificate infos configuration.
 type TLSClientCertificateInfos struct {
 	NotAfter  bool                              `description:"Add NotAfter info in header" json:"notAfter"`
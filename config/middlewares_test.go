@@ -0,0 +1,104 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureHeaders_Headers(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		preset   SecureHeaders
+		expected *Headers
+	}{
+		{
+			desc:   "default preset",
+			preset: SecureHeaders{},
+			expected: &Headers{
+				CustomResponseHeaders: map[string]string{},
+				STSSeconds:            DefaultSTSSeconds,
+				ContentTypeNosniff:    true,
+				ReferrerPolicy:        DefaultReferrerPolicy,
+			},
+		},
+		{
+			desc: "disabled headers",
+			preset: SecureHeaders{
+				DisableSTS:                true,
+				STSPreload:                true,
+				DisableContentTypeNosniff: true,
+				DisableReferrerPolicy:     true,
+			},
+			expected: &Headers{
+				CustomResponseHeaders: map[string]string{},
+			},
+		},
+		{
+			desc: "custom values",
+			preset: SecureHeaders{
+				STSSeconds:           600,
+				STSIncludeSubdomains: true,
+				STSPreload:           true,
+				ReferrerPolicy:       "no-referrer",
+			},
+			expected: &Headers{
+				CustomResponseHeaders: map[string]string{},
+				STSSeconds:            600,
+				STSIncludeSubdomains:  true,
+				STSPreload:            true,
+				ContentTypeNosniff:    true,
+				ReferrerPolicy:        "no-referrer",
+			},
+		},
+		{
+			desc: "content security and permissions policies",
+			preset: SecureHeaders{
+				DisableSTS: true,
+				PermissionsPolicy: map[string][]string{
+					"geolocation": {"self", "https://example.com"},
+					"camera":      {},
+					"fullscreen":  {"*"},
+				},
+				ContentSecurityPolicy: map[string][]string{
+					"script-src":                {"'self'", "https://cdn.example.com"},
+					"default-src":               {"'self'"},
+					"upgrade-insecure-requests": {},
+				},
+				CSPReportURI: "/csp-report",
+			},
+			expected: &Headers{
+				CustomResponseHeaders: map[string]string{
+					"Permissions-Policy": `camera=(), fullscreen=(*), geolocation=(self "https://example.com")`,
+				},
+				ContentTypeNosniff:    true,
+				ReferrerPolicy:        DefaultReferrerPolicy,
+				ContentSecurityPolicy: "default-src 'self'; script-src 'self' https://cdn.example.com; upgrade-insecure-requests; report-uri /csp-report",
+			},
+		},
+		{
+			desc: "report only content security policy",
+			preset: SecureHeaders{
+				DisableSTS:            true,
+				ContentSecurityPolicy: map[string][]string{"default-src": {"'self'"}},
+				CSPReportOnly:         true,
+			},
+			expected: &Headers{
+				CustomResponseHeaders: map[string]string{
+					"Content-Security-Policy-Report-Only": "default-src 'self'",
+				},
+				ContentTypeNosniff: true,
+				ReferrerPolicy:     DefaultReferrerPolicy,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, test.preset.Headers())
+		})
+	}
+}
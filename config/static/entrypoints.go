@@ -2,18 +2,54 @@ package static
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/tls"
 )
 
 // EntryPoint holds the entry point configuration.
+// The entry points with an address like unix:///path/to.sock listen on a Unix socket,
+// whose permissions are set to SocketMode, an octal mode like 0660, when not empty.
 type EntryPoint struct {
-	Address       string
-	Transport     *EntryPointsTransport
-	TLS           *tls.TLS
-	ProxyProtocol *ProxyProtocol
+	Address          string
+	SocketMode       string
+	Transport        *EntryPointsTransport
+	TLS              *tls.TLS
+	ProxyProtocol    *ProxyProtocol
+	ForwardedHeaders *ForwardedHeaders
+	Limits           *Limits
+	ServerTiming     bool       `description:"Emit the Server-Timing headers breaking down the processing time of the requests" export:"true"`
+	ReusePort        *ReusePort `description:"Open several SO_REUSEPORT listeners, each with its own accept loop" export:"true"`
+}
+
+// ReusePort holds the number of SO_REUSEPORT listeners opened on the address of an entry point,
+// the kernel spreading the connections over them.
+type ReusePort struct {
+	Listeners int `description:"Number of listeners, GOMAXPROCS by default" export:"true"`
+}
+
+const (
+	// OverflowReject rejects the connections and the requests over the limits.
+	OverflowReject = "reject"
+	// OverflowQueue makes the connections and the requests over the limits wait for a free slot.
+	OverflowQueue = "queue"
+)
+
+// Limits holds the maximum numbers of concurrent connections and requests.
+type Limits struct {
+	MaxConnections int            `description:"Maximum number of open connections" export:"true"`
+	MaxRequests    int            `description:"Maximum number of requests in flight" export:"true"`
+	Overflow       string         `description:"Handling of the connections and requests over the limits: reject (default) or queue" export:"true"`
+	QueueTimeout   parse.Duration `description:"Maximum duration a connection or a request waits in the queue (default 1s)" export:"true"`
+}
+
+// ForwardedHeaders holds the peers whose X-Forwarded-* headers are trusted.
+type ForwardedHeaders struct {
+	Insecure   bool `export:"true"`
+	TrustedIPs []string
 }
 
 // ProxyProtocol contains Proxy-Protocol configuration.
@@ -64,9 +100,13 @@ func (ep *EntryPoints) Set(value string) error {
 	}
 
 	(*ep)[result["name"]] = &EntryPoint{
-		Address:       result["address"],
-		TLS:           configTLS,
-		ProxyProtocol: makeEntryPointProxyProtocol(result),
+		Address:          result["address"],
+		SocketMode:       result["socketmode"],
+		TLS:              configTLS,
+		ProxyProtocol:    makeEntryPointProxyProtocol(result),
+		ForwardedHeaders: makeEntryPointForwardedHeaders(result),
+		ServerTiming:     toBool(result, "servertiming"),
+		ReusePort:        makeEntryPointReusePort(result),
 	}
 
 	return nil
@@ -92,6 +132,39 @@ func makeEntryPointProxyProtocol(result map[string]string) *ProxyProtocol {
 	return proxyProtocol
 }
 
+func makeEntryPointForwardedHeaders(result map[string]string) *ForwardedHeaders {
+	var forwardedHeaders *ForwardedHeaders
+
+	fhTrustedIPs := result["forwardedheaders_trustedips"]
+	if len(result["forwardedheaders_insecure"]) > 0 || len(fhTrustedIPs) > 0 {
+		forwardedHeaders = &ForwardedHeaders{
+			Insecure: toBool(result, "forwardedheaders_insecure"),
+		}
+		if len(fhTrustedIPs) > 0 {
+			forwardedHeaders.TrustedIPs = strings.Split(fhTrustedIPs, ",")
+		}
+	}
+
+	if forwardedHeaders != nil && forwardedHeaders.Insecure {
+		log.Warn("ForwardedHeaders.insecure:true is dangerous. Please use 'ForwardedHeaders.TrustedIPs:IPs' and remove 'ForwardedHeaders.insecure:true'")
+	}
+
+	return forwardedHeaders
+}
+
+func makeEntryPointReusePort(result map[string]string) *ReusePort {
+	if !toBool(result, "reuseport") && len(result["reuseport_listeners"]) == 0 {
+		return nil
+	}
+
+	listeners, err := strconv.Atoi(result["reuseport_listeners"])
+	if err != nil && len(result["reuseport_listeners"]) > 0 {
+		log.Warnf("Invalid number of listeners %q, using GOMAXPROCS", result["reuseport_listeners"])
+	}
+
+	return &ReusePort{Listeners: listeners}
+}
+
 func makeEntryPointTLS(result map[string]string) (*tls.TLS, error) {
 	var configTLS *tls.TLS
 
@@ -128,6 +201,10 @@ func makeEntryPointTLS(result map[string]string) (*tls.TLS, error) {
 			configTLS.SniStrict = toBool(result, "tls_snistrict")
 		}
 
+		if len(result["tls_defaultdomain"]) > 0 {
+			configTLS.DefaultDomain = result["tls_defaultdomain"]
+		}
+
 		if len(result["tls_defaultcertificate_cert"]) > 0 && len(result["tls_defaultcertificate_key"]) > 0 {
 			configTLS.DefaultCertificate = &tls.Certificate{
 				CertFile: tls.FileOrContent(result["tls_defaultcertificate_cert"]),
@@ -237,6 +237,26 @@ func TestEntryPoints_Set(t *testing.T) {
 				// FIXME Test ServersTransport
 			},
 		},
+		{
+			name:                   "TLS default domain and strict SNI",
+			expression:             "Name:foo TLS TLS.DefaultDomain:foo.com TLS.SniStrict:true",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				TLS: &tls.TLS{
+					DefaultDomain: "foo.com",
+					SniStrict:     true,
+				},
+			},
+		},
+		{
+			name:                   "Unix socket",
+			expression:             "Name:foo Address:unix:///var/run/traefik.sock SocketMode:0660",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				Address:    "unix:///var/run/traefik.sock",
+				SocketMode: "0660",
+			},
+		},
 		{
 			name:                   "default",
 			expression:             "Name:foo",
@@ -269,6 +289,24 @@ func TestEntryPoints_Set(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:                   "ForwardedHeaders insecure true",
+			expression:             "Name:foo ForwardedHeaders.insecure:true",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				ForwardedHeaders: &ForwardedHeaders{Insecure: true},
+			},
+		},
+		{
+			name:                   "ForwardedHeaders TrustedIPs",
+			expression:             "Name:foo ForwardedHeaders.TrustedIPs:10.0.0.3/24,20.0.0.3/24",
+			expectedEntryPointName: "foo",
+			expectedEntryPoint: &EntryPoint{
+				ForwardedHeaders: &ForwardedHeaders{
+					TrustedIPs: []string{"10.0.0.3/24", "20.0.0.3/24"},
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -285,3 +323,35 @@ func TestEntryPoints_Set(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeEntryPointReusePort(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected *ReusePort
+	}{
+		{
+			desc:  "no reuse port",
+			value: "Name:foo Address::8000",
+		},
+		{
+			desc:     "GOMAXPROCS listeners",
+			value:    "Name:foo Address::8000 ReusePort:true",
+			expected: &ReusePort{},
+		},
+		{
+			desc:     "listeners",
+			value:    "Name:foo Address::8000 ReusePort.Listeners:4",
+			expected: &ReusePort{Listeners: 4},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, makeEntryPointReusePort(parseEntryPointsConfiguration(test.value)))
+		})
+	}
+}
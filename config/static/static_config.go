@@ -2,12 +2,29 @@ package static
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/acme"
+	"github.com/containous/traefik/api"
+	"github.com/containous/traefik/audit"
+	"github.com/containous/traefik/bluegreen"
+	"github.com/containous/traefik/certmonitor"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/history"
+	"github.com/containous/traefik/diagnostics"
+	"github.com/containous/traefik/drain"
+	"github.com/containous/traefik/encryption"
+	"github.com/containous/traefik/events"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/containous/traefik/loadshedding"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/lru"
+	"github.com/containous/traefik/middlewares/accesslog"
+	"github.com/containous/traefik/namespace"
+	"github.com/containous/traefik/notification"
 	"github.com/containous/traefik/old/provider/boltdb"
 	"github.com/containous/traefik/old/provider/consul"
 	"github.com/containous/traefik/old/provider/consulcatalog"
@@ -22,14 +39,25 @@ import (
 	"github.com/containous/traefik/old/provider/rancher"
 	"github.com/containous/traefik/old/provider/zk"
 	"github.com/containous/traefik/ping"
+	"github.com/containous/traefik/plugins"
+	"github.com/containous/traefik/privileges"
 	acmeprovider "github.com/containous/traefik/provider/acme"
+	"github.com/containous/traefik/provider/dns"
 	"github.com/containous/traefik/provider/file"
+	"github.com/containous/traefik/provider/grpc"
+	"github.com/containous/traefik/provider/internalca"
+	"github.com/containous/traefik/provider/kubernetes/crd"
 	"github.com/containous/traefik/provider/rest"
+	"github.com/containous/traefik/secrets"
+	"github.com/containous/traefik/slo"
+	"github.com/containous/traefik/tap"
 	"github.com/containous/traefik/tls"
 	"github.com/containous/traefik/tracing/datadog"
 	"github.com/containous/traefik/tracing/jaeger"
+	"github.com/containous/traefik/tracing/opentelemetry"
 	"github.com/containous/traefik/tracing/zipkin"
 	"github.com/containous/traefik/types"
+	"github.com/containous/traefik/watchdog"
 	"github.com/elazarl/go-bindata-assetfs"
 	lego "github.com/xenolf/lego/acme"
 )
@@ -60,15 +88,39 @@ type Configuration struct {
 	API     *API           `description:"Enable api/dashboard" export:"true"`
 	Metrics *types.Metrics `description:"Enable a metrics exporter" export:"true"`
 	Ping    *ping.Handler  `description:"Enable ping" export:"true"`
+
+	Diagnostics *diagnostics.Handler `description:"Expose the runtime diagnostics on an entry point of their own" export:"true"`
 	// Rest    *rest.Provider `description:"Enable Rest backend with default settings" export:"true"`
 
 	Log       *types.TraefikLog
 	AccessLog *types.AccessLog `description:"Access log settings" export:"true"`
+	AuditLog  *audit.Config    `description:"Audit log of the administrative and security events" export:"true"`
+	SlowLog   *slo.Config      `description:"Slow log of the requests over the latency objective of their router" export:"true"`
 	Tracing   *Tracing         `description:"OpenTracing configuration" export:"true"`
 
 	HostResolver *HostResolverConfig `description:"Enable CNAME Flattening" export:"true"`
 
 	ACME *acme.ACME `description:"Enable ACME (Let's Encrypt): automatic SSL" export:"true"`
+
+	InternalCA *internalca.Configuration `description:"Issue the certificates from an internal CA, for the development environments and the internal domains" export:"true"`
+
+	Plugins map[string]*plugins.Descriptor `description:"Plugins providing middlewares" export:"true"`
+
+	Limits       *Limits              `description:"Limits shared by all the entry points" export:"true"`
+	LoadShedding *loadshedding.Config `description:"Reject requests of the low priority routers when Traefik is overloaded" export:"true"`
+	Caches       *lru.Config          `description:"Budget of the in-process caches keyed by the requests" export:"true"`
+	Watchdog     *watchdog.Config     `description:"Watch the goroutines and the file descriptors for leaks" export:"true"`
+	HealthChecks *healthcheck.Config  `description:"Settings shared by the health checks of all the services" export:"true"`
+
+	Namespaces map[string]*namespace.Namespace `description:"Namespaces isolating the configurations of the providers of different teams" export:"true"`
+
+	Webhooks map[string]*notification.Webhook `description:"Webhooks notified of the operational events" export:"true"`
+
+	Secrets *secrets.Config `description:"Secret backends resolving the secret references of the dynamic configuration" export:"true"`
+
+	CertificatesMonitor *certmonitor.Config `description:"Check the served certificates for their expiry, revocation and issuer" export:"true"`
+
+	Privileges *privileges.Config `description:"Unprivileged user and group Traefik runs as once the entry points are bound" export:"true"`
 }
 
 // Global holds the global configuration.
@@ -84,15 +136,28 @@ type ServersTransport struct {
 	RootCAs             tls.FilesOrContents `description:"Add cert file for self-signed certificate"`
 	MaxIdleConnsPerHost int                 `description:"If non-zero, controls the maximum idle (keep-alive) to keep per-host.  If zero, DefaultMaxIdleConnsPerHost is used" export:"true"`
 	ForwardingTimeouts  *ForwardingTimeouts `description:"Timeouts for requests forwarded to the backend servers" export:"true"`
+	Proxy               *config.Proxy       `description:"Outbound proxy the connections to the servers go through, unless their services have their own"`
+	LocalAddress        string              `description:"Source IP address of the connections to the servers" export:"true"`
+	Interface           string              `description:"Network interface the connections to the servers are bound to (Linux only)" export:"true"`
 }
 
 // API holds the API configuration
 type API struct {
-	EntryPoint      string            `description:"EntryPoint" export:"true"`
-	Dashboard       bool              `description:"Activate dashboard" export:"true"`
-	Statistics      *types.Statistics `description:"Enable more detailed statistics" export:"true"`
-	Middlewares     []string          `description:"Middleware list" export:"true"`
-	DashboardAssets *assetfs.AssetFS  `json:"-"`
+	EntryPoint      string               `description:"EntryPoint" export:"true"`
+	Dashboard       bool                 `description:"Activate dashboard" export:"true"`
+	Statistics      *types.Statistics    `description:"Enable more detailed statistics" export:"true"`
+	Middlewares     []string             `description:"Middleware list" export:"true"`
+	HistorySize     int                  `description:"Number of applied dynamic configurations kept to be diffed or rolled back" export:"true"`
+	RBAC            *api.RBAC            `description:"Restrict the API and the dashboard to the users of the roles" export:"true"`
+	DashboardAssets *assetfs.AssetFS     `json:"-"`
+	History         *history.History     `json:"-"`
+	Drains          *drain.Registry      `json:"-"`
+	Switches        *bluegreen.Registry  `json:"-"`
+	Plugins         *plugins.Registry    `json:"-"`
+	Taps            *tap.Registry        `json:"-"`
+	Namespaces      *namespace.Registry  `json:"-"`
+	Certificates    *certmonitor.Monitor `json:"-"`
+	AccessLogs      *accesslog.Buffer    `json:"-"`
 }
 
 // RespondingTimeouts contains timeout configurations for incoming requests to the Traefik instance.
@@ -116,12 +181,15 @@ type LifeCycle struct {
 
 // Tracing holds the tracing configuration.
 type Tracing struct {
-	Backend       string          `description:"Selects the tracking backend ('jaeger','zipkin', 'datadog')." export:"true"`
-	ServiceName   string          `description:"Set the name for this service" export:"true"`
-	SpanNameLimit int             `description:"Set the maximum character limit for Span names (default 0 = no limit)" export:"true"`
-	Jaeger        *jaeger.Config  `description:"Settings for jaeger"`
-	Zipkin        *zipkin.Config  `description:"Settings for zipkin"`
-	DataDog       *datadog.Config `description:"Settings for DataDog"`
+	Backend         string                `description:"Selects the tracking backend ('jaeger','zipkin', 'datadog', 'opentelemetry')." export:"true"`
+	ServiceName     string                `description:"Set the name for this service" export:"true"`
+	SpanNameLimit   int                   `description:"Set the maximum character limit for Span names (default 0 = no limit)" export:"true"`
+	DebugHeader     string                `description:"Header forcing the sampling of the traces of the requests sent from the DebugTrustedIPs" export:"true"`
+	DebugTrustedIPs []string              `description:"IPs allowed to force the sampling of the traces with the DebugHeader" export:"true"`
+	Jaeger          *jaeger.Config        `description:"Settings for jaeger"`
+	Zipkin          *zipkin.Config        `description:"Settings for zipkin"`
+	DataDog         *datadog.Config       `description:"Settings for DataDog"`
+	OpenTelemetry   *opentelemetry.Config `description:"Settings for OpenTelemetry (OTLP)"`
 }
 
 // HostResolverConfig contain configuration for CNAME Flattening.
@@ -131,9 +199,19 @@ type HostResolverConfig struct {
 	ResolvDepth     int    `description:"The maximal depth of DNS recursive resolving" export:"true"`
 }
 
+// ReloadDebounce holds the debouncing of the reloads of the dynamic configuration:
+// the configurations sent by all the providers are applied together, in a single reload,
+// once no configuration has been received for Delay.
+type ReloadDebounce struct {
+	Delay       parse.Duration `description:"Quiet period after the last configuration received before the reload (default 500ms)" export:"true"`
+	MaxDelay    parse.Duration `description:"Maximum delay of a reload while the configurations keep coming (default 5s)" export:"true"`
+	MinInterval parse.Duration `description:"Minimum interval between two reloads" export:"true"`
+}
+
 // Providers contains providers configuration
 type Providers struct {
 	ProvidersThrottleDuration parse.Duration          `description:"Backends throttle duration: minimum duration between 2 events from providers before applying a new configuration. It avoids unnecessary reloads if multiples events are sent in a short amount of time." export:"true"`
+	ReloadDebounce            *ReloadDebounce         `description:"Apply the configurations sent by the providers in quick succession together" export:"true"`
 	Docker                    *docker.Provider        `description:"Enable Docker backend with default settings" export:"true"`
 	File                      *file.Provider          `description:"Enable File backend with default settings" export:"true"`
 	Marathon                  *marathon.Provider      `description:"Enable Marathon backend with default settings" export:"true"`
@@ -149,6 +227,9 @@ type Providers struct {
 	Rancher                   *rancher.Provider       `description:"Enable Rancher backend with default settings" export:"true"`
 	DynamoDB                  *dynamodb.Provider      `description:"Enable DynamoDB backend with default settings" export:"true"`
 	Rest                      *rest.Provider          `description:"Enable Rest backend with default settings" export:"true"`
+	GRPC                      *grpc.Provider          `description:"Enable gRPC backend with default settings" export:"true"`
+	KubernetesCRD             *crd.Provider           `description:"Enable Kubernetes backend with CRD and default settings" export:"true"`
+	DNS                       *dns.Provider           `description:"Enable DNS backend with default settings" export:"true"`
 }
 
 // SetEffectiveConfiguration adds missing configuration parameters derived from existing ones.
@@ -171,6 +252,15 @@ func (c *Configuration) SetEffectiveConfiguration(configFile string) {
 		}
 	}
 
+	if c.Diagnostics != nil {
+		if len(c.Diagnostics.EntryPoint) == 0 {
+			c.Diagnostics.EntryPoint = diagnostics.DefaultEntryPointName
+		}
+		if _, ok := c.EntryPoints[diagnostics.DefaultEntryPointName]; !ok && c.Diagnostics.EntryPoint == diagnostics.DefaultEntryPointName {
+			c.EntryPoints[diagnostics.DefaultEntryPointName] = &EntryPoint{Address: diagnostics.DefaultAddress}
+		}
+	}
+
 	for _, entryPoint := range c.EntryPoints {
 		if entryPoint.Transport == nil {
 			entryPoint.Transport = &EntryPointsTransport{}
@@ -240,6 +330,10 @@ func (c *Configuration) initTracing() {
 				log.Warn("DataDog configuration will be ignored")
 				c.Tracing.DataDog = nil
 			}
+			if c.Tracing.OpenTelemetry != nil {
+				log.Warn("OpenTelemetry configuration will be ignored")
+				c.Tracing.OpenTelemetry = nil
+			}
 		case zipkin.Name:
 			if c.Tracing.Zipkin == nil {
 				c.Tracing.Zipkin = &zipkin.Config{
@@ -258,6 +352,10 @@ func (c *Configuration) initTracing() {
 				log.Warn("DataDog configuration will be ignored")
 				c.Tracing.DataDog = nil
 			}
+			if c.Tracing.OpenTelemetry != nil {
+				log.Warn("OpenTelemetry configuration will be ignored")
+				c.Tracing.OpenTelemetry = nil
+			}
 		case datadog.Name:
 			if c.Tracing.DataDog == nil {
 				c.Tracing.DataDog = &datadog.Config{
@@ -274,6 +372,34 @@ func (c *Configuration) initTracing() {
 				log.Warn("Jaeger configuration will be ignored")
 				c.Tracing.Jaeger = nil
 			}
+			if c.Tracing.OpenTelemetry != nil {
+				log.Warn("OpenTelemetry configuration will be ignored")
+				c.Tracing.OpenTelemetry = nil
+			}
+		case opentelemetry.Name:
+			if c.Tracing.OpenTelemetry == nil {
+				c.Tracing.OpenTelemetry = &opentelemetry.Config{
+					Endpoint:      "localhost:4317",
+					Protocol:      opentelemetry.ProtocolGRPC,
+					Sampler:       opentelemetry.SamplerParentBasedRatio,
+					SamplingRatio: 1.0,
+					BatchSize:     512,
+					FlushInterval: parse.Duration(5 * time.Second),
+					ExportTimeout: parse.Duration(10 * time.Second),
+				}
+			}
+			if c.Tracing.Jaeger != nil {
+				log.Warn("Jaeger configuration will be ignored")
+				c.Tracing.Jaeger = nil
+			}
+			if c.Tracing.Zipkin != nil {
+				log.Warn("Zipkin configuration will be ignored")
+				c.Tracing.Zipkin = nil
+			}
+			if c.Tracing.DataDog != nil {
+				log.Warn("DataDog configuration will be ignored")
+				c.Tracing.DataDog = nil
+			}
 		default:
 			log.Warnf("Unknown tracer %q", c.Tracing.Backend)
 			return
@@ -318,9 +444,22 @@ func (c *Configuration) InitACMEProvider() (*acmeprovider.Provider, error) {
 		provider := &acmeprovider.Provider{}
 		provider.Configuration = convertACMEChallenge(c.ACME)
 
-		store := acmeprovider.NewLocalStore(provider.Storage)
+		secretStore, err := secrets.New(c.Secrets)
+		if err != nil {
+			return nil, err
+		}
+
+		cipher, err := encryption.New(c.ACME.StorageEncryption, secretStore)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize the encryption of the ACME storage: %v", err)
+		}
+
+		store := acmeprovider.NewEncryptedLocalStore(provider.Storage, cipher)
 		provider.Store = store
-		acme.ConvertToNewFormat(provider.Storage)
+		// The conversion from the format of the ACME v1 storage doesn't support encrypted storages.
+		if cipher == nil {
+			acme.ConvertToNewFormat(provider.Storage)
+		}
 		c.ACME = nil
 		return provider, nil
 	}
@@ -338,6 +477,34 @@ func (c *Configuration) ValidateConfiguration() {
 			}
 		}
 	}
+
+	if c.InternalCA != nil {
+		if _, ok := c.EntryPoints[c.InternalCA.EntryPoint]; !ok {
+			log.Fatalf("Unknown entrypoint %q for the internal CA configuration", c.InternalCA.EntryPoint)
+		} else if c.EntryPoints[c.InternalCA.EntryPoint].TLS == nil {
+			log.Fatalf("Entrypoint %q has no TLS configuration for the internal CA configuration", c.InternalCA.EntryPoint)
+		}
+	}
+
+	if c.Diagnostics != nil {
+		if _, ok := c.EntryPoints[c.Diagnostics.EntryPoint]; !ok {
+			log.Fatalf("Unknown entrypoint %q for the diagnostics", c.Diagnostics.EntryPoint)
+		}
+		if c.API != nil && c.API.EntryPoint == c.Diagnostics.EntryPoint {
+			log.Warnf("The diagnostics share the entrypoint %q of the API", c.Diagnostics.EntryPoint)
+		}
+		if _, err := diagnostics.NewPusher(c.Diagnostics.Push); err != nil {
+			log.Fatalf("Invalid push of the profiles: %v", err)
+		}
+	}
+
+	if _, err := namespace.New(c.Namespaces); err != nil {
+		log.Fatalf("Invalid namespaces: %v", err)
+	}
+
+	if _, err := notification.New(events.Default(), c.Webhooks); err != nil {
+		log.Fatalf("Invalid webhooks: %v", err)
+	}
 }
 
 func getSafeACMECAServer(caServerSrc string) string {
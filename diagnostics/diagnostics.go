@@ -0,0 +1,70 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/api"
+	"github.com/containous/traefik/log"
+)
+
+const (
+	// DefaultEntryPointName is the entry point of the diagnostics when none is given.
+	DefaultEntryPointName = "diagnostics"
+	// DefaultAddress is the address of the default entry point of the diagnostics, only reachable from the host.
+	DefaultAddress = "127.0.0.1:8082"
+)
+
+// Handler exposes the runtime diagnostics, pprof, expvar and the snapshots of the goroutines and of the heap,
+// on an entry point of their own.
+type Handler struct {
+	EntryPoint  string      `description:"Diagnostics entryPoint (default diagnostics, listening on 127.0.0.1:8082)" export:"true"`
+	Middlewares []string    `description:"Middleware list, authenticating the diagnostics requests" export:"true"`
+	Push        *PushConfig `description:"Push the profiles to a continuous profiler" export:"true"`
+}
+
+// Append adds the diagnostics routes on a router.
+func (h *Handler) Append(router *mux.Router) {
+	api.DebugHandler{}.Append(router)
+
+	router.Methods(http.MethodPost).Path("/debug/snapshot").HandlerFunc(snapshot)
+}
+
+// snapshot answers a zip archive holding the stacks of all the goroutines, and a heap profile taken after a garbage collection.
+func snapshot(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(req.Context())
+
+	name := "traefik-snapshot-" + time.Now().UTC().Format("20060102T150405Z")
+	rw.Header().Set("Content-Type", "application/zip")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".zip"))
+
+	archive := zip.NewWriter(rw)
+
+	goroutines, err := archive.Create(name + "/goroutines.txt")
+	if err == nil {
+		err = pprof.Lookup("goroutine").WriteTo(goroutines, 2)
+	}
+	if err != nil {
+		logger.Errorf("Unable to write the goroutines of the snapshot: %v", err)
+		return
+	}
+
+	runtime.GC()
+	heap, err := archive.Create(name + "/heap.pb.gz")
+	if err == nil {
+		err = pprof.Lookup("heap").WriteTo(heap, 0)
+	}
+	if err != nil {
+		logger.Errorf("Unable to write the heap profile of the snapshot: %v", err)
+		return
+	}
+
+	if err := archive.Close(); err != nil {
+		logger.Errorf("Unable to write the snapshot: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerSnapshot(t *testing.T) {
+	router := mux.NewRouter()
+	(&Handler{}).Append(router)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/debug/snapshot", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+	require.NoError(t, err)
+
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name[len("traefik-snapshot-20060102T150405Z/"):])
+	}
+	assert.Equal(t, []string{"goroutines.txt", "heap.pb.gz"}, names)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestNewPusher(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        *PushConfig
+		expectedError bool
+	}{
+		{
+			desc: "nil configuration",
+		},
+		{
+			desc:   "defaults",
+			config: &PushConfig{URL: "http://pyroscope:4040"},
+		},
+		{
+			desc:          "no URL",
+			config:        &PushConfig{},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid URL",
+			config:        &PushConfig{URL: "http://pyroscope:port"},
+			expectedError: true,
+		},
+		{
+			desc:          "unknown profile",
+			config:        &PushConfig{URL: "http://pyroscope:4040", Profiles: []string{"cpu", "unknown"}},
+			expectedError: true,
+		},
+		{
+			desc:          "CPU duration over the interval",
+			config:        &PushConfig{URL: "http://pyroscope:4040", Interval: parse.Duration(10 * time.Second), CPUDuration: parse.Duration(time.Minute)},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pusher, err := NewPusher(test.config)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.config == nil, pusher == nil)
+		})
+	}
+}
+
+func TestPusherPush(t *testing.T) {
+	var lock sync.Mutex
+	pushes := make(map[string]url.Values)
+	var authorizations []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		assert.Equal(t, "/ingest", req.URL.Path)
+		assert.NotEmpty(t, body)
+		pushes[req.URL.Query().Get("name")] = req.URL.Query()
+		authorizations = append(authorizations, req.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	pusher, err := NewPusher(&PushConfig{
+		URL:             server.URL + "/",
+		ApplicationName: "edge",
+		Tags:            map[string]string{"region": "eu", "env": "prod"},
+		AuthToken:       "secret",
+		Profiles:        []string{"cpu", "heap", "goroutine"},
+		CPUDuration:     parse.Duration(50 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	pusher.Push(context.Background())
+
+	var names []string
+	for name := range pushes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"edge.cpu{env=prod,region=eu}", "edge.goroutine{env=prod,region=eu}", "edge.heap{env=prod,region=eu}"}, names)
+
+	query := pushes["edge.cpu{env=prod,region=eu}"]
+	assert.Equal(t, "pprof", query.Get("format"))
+	assert.NotEmpty(t, query.Get("from"))
+	assert.NotEmpty(t, query.Get("until"))
+
+	assert.Equal(t, []string{"Bearer secret", "Bearer secret", "Bearer secret"}, authorizations)
+}
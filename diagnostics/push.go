@@ -0,0 +1,237 @@
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/log"
+)
+
+const (
+	defaultApplicationName = "traefik"
+	defaultPushInterval    = time.Minute
+	defaultCPUDuration     = 10 * time.Second
+	pushTimeout            = 30 * time.Second
+
+	profileCPU = "cpu"
+)
+
+// defaultProfiles are pushed when no profile is given.
+var defaultProfiles = []string{profileCPU, "heap"}
+
+// PushConfig holds the configuration of the push of the profiles to a continuous profiler,
+// through the HTTP ingestion API of Pyroscope.
+type PushConfig struct {
+	URL             string            `description:"URL of the continuous profiler" export:"true"`
+	ApplicationName string            `description:"Name of the application of the profiles (default traefik)" export:"true"`
+	Tags            map[string]string `description:"Tags of the profiles" export:"true"`
+	AuthToken       string            `description:"Bearer token authenticating to the continuous profiler"`
+	Profiles        []string          `description:"Profiles to push: cpu, heap, allocs, goroutine, mutex, block (default cpu and heap)" export:"true"`
+	Interval        parse.Duration    `description:"Interval between two pushes (default 60s)" export:"true"`
+	CPUDuration     parse.Duration    `description:"Duration of the CPU profiles (default 10s)" export:"true"`
+}
+
+// Pusher periodically pushes the profiles of Traefik to a continuous profiler.
+// A nil Pusher pushes nothing.
+type Pusher struct {
+	ingestURL       *url.URL
+	applicationName string
+	tags            string
+	authToken       string
+	profiles        []string
+	interval        time.Duration
+	cpuDuration     time.Duration
+	client          *http.Client
+}
+
+// NewPusher creates a Pusher, nil if the configuration is nil.
+func NewPusher(config *PushConfig) (*Pusher, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	if len(config.URL) == 0 {
+		return nil, errors.New("no continuous profiler URL")
+	}
+	ingestURL, err := url.Parse(strings.TrimSuffix(config.URL, "/") + "/ingest")
+	if err != nil {
+		return nil, fmt.Errorf("invalid continuous profiler URL %q: %v", config.URL, err)
+	}
+
+	p := &Pusher{
+		ingestURL:       ingestURL,
+		applicationName: config.ApplicationName,
+		tags:            formatTags(config.Tags),
+		authToken:       config.AuthToken,
+		profiles:        config.Profiles,
+		interval:        time.Duration(config.Interval),
+		cpuDuration:     time.Duration(config.CPUDuration),
+		client:          &http.Client{Timeout: pushTimeout},
+	}
+
+	if len(p.applicationName) == 0 {
+		p.applicationName = defaultApplicationName
+	}
+	if len(p.profiles) == 0 {
+		p.profiles = defaultProfiles
+	}
+	if p.interval <= 0 {
+		p.interval = defaultPushInterval
+	}
+	if p.cpuDuration <= 0 {
+		p.cpuDuration = defaultCPUDuration
+	}
+	if p.cpuDuration > p.interval {
+		return nil, fmt.Errorf("the CPU duration %s exceeds the interval %s", p.cpuDuration, p.interval)
+	}
+
+	for _, profile := range p.profiles {
+		switch profile {
+		case profileCPU:
+		case "mutex":
+			runtime.SetMutexProfileFraction(5)
+		case "block":
+			runtime.SetBlockProfileRate(1)
+		default:
+			if pprof.Lookup(profile) == nil {
+				return nil, fmt.Errorf("unknown profile %q", profile)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// formatTags formats the tags of the profiles, sorted by name, as the suffix of their name.
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	var names []string
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		pairs = append(pairs, name+"="+tags[name])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Run pushes the profiles at each interval, until stop is closed.
+func (p *Pusher) Run(stop chan bool) {
+	if p == nil {
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-stop:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			p.Push(ctx)
+			cancel()
+		}
+	}
+}
+
+// Push collects the profiles, the CPU one over the CPU duration, and pushes them.
+func (p *Pusher) Push(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	for _, profile := range p.profiles {
+		from := time.Now()
+
+		buffer := &bytes.Buffer{}
+		var err error
+		if profile == profileCPU {
+			err = collectCPU(ctx, buffer, p.cpuDuration)
+		} else {
+			err = pprof.Lookup(profile).WriteTo(buffer, 0)
+		}
+		if err != nil {
+			logger.Errorf("Unable to collect the %s profile: %v", profile, err)
+			continue
+		}
+
+		if err := p.push(ctx, profile, from, time.Now(), buffer); err != nil {
+			logger.Errorf("Unable to push the %s profile: %v", profile, err)
+		}
+	}
+}
+
+// collectCPU writes a CPU profile of the duration, which fails if another CPU profile is in progress.
+func collectCPU(ctx context.Context, buffer *bytes.Buffer, duration time.Duration) error {
+	if err := pprof.StartCPUProfile(buffer); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	pprof.StopCPUProfile()
+	return ctx.Err()
+}
+
+func (p *Pusher) push(ctx context.Context, profile string, from, until time.Time, body *bytes.Buffer) error {
+	query := url.Values{}
+	query.Set("name", p.applicationName+"."+profile+p.tags)
+	query.Set("from", strconv.FormatInt(from.Unix(), 10))
+	query.Set("until", strconv.FormatInt(until.Unix(), 10))
+	query.Set("format", "pprof")
+	query.Set("spyName", "gospy")
+
+	ingestURL := *p.ingestURL
+	ingestURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, ingestURL.String(), body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if len(p.authToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+p.authToken)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package drain
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+var (
+	// ErrServiceNotFound is returned when draining a server of an unknown service.
+	ErrServiceNotFound = errors.New("service not found")
+	// ErrServerNotFound is returned when draining a server which is not a server of the service.
+	ErrServerNotFound = errors.New("server not found")
+)
+
+// Balancer is a load balancer whose servers can be drained.
+type Balancer interface {
+	RemoveServer(u *url.URL) error
+	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
+}
+
+// Service holds the load balancers of a service, and the servers of its configuration.
+// The requests in flight to a server removed from the configuration can take up to RemovalGracePeriod before being canceled,
+// they are not canceled when it is zero. DrainedRequests counts them, it can be nil.
+type Service struct {
+	Balancers          []Balancer
+	Servers            []config.Server
+	RemovalGracePeriod time.Duration
+	DrainedRequests    metrics.Counter
+}
+
+// Registry keeps track of the servers drained through the API.
+// A drained server is removed from the load balancers of its service: it gets no new requests,
+// while the requests in flight finish. It stays out of the load balancers when the configuration is reloaded,
+// until it is undrained.
+type Registry struct {
+	lock     sync.RWMutex
+	drained  map[string]map[string]bool
+	services map[string]Service
+	inFlight *inFlight
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		drained:  make(map[string]map[string]bool),
+		services: make(map[string]Service),
+		inFlight: newInFlight(),
+	}
+}
+
+// IsDrained returns true if the server of the service is drained.
+func (r *Registry) IsDrained(serviceName, serverURL string) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.drained[serviceName][serverURL]
+}
+
+// SetServices replaces the services whose servers can be drained, when a new configuration is applied.
+// The requests in flight to the servers no longer in the configuration are drained.
+func (r *Registry) SetServices(services map[string]Service) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for serviceName, previous := range r.services {
+		for _, server := range removedServers(previous.Servers, services[serviceName].Servers) {
+			r.inFlight.drain(serviceName, server.URL, previous.RemovalGracePeriod, previous.DrainedRequests)
+		}
+	}
+
+	r.services = services
+}
+
+// Drain removes the server from the load balancers of the service.
+func (r *Registry) Drain(serviceName, serverURL string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_, u, err := r.findServer(serviceName, serverURL)
+	if err != nil {
+		return err
+	}
+
+	for _, balancer := range r.services[serviceName].Balancers {
+		if err := balancer.RemoveServer(u); err != nil {
+			// The server can already be out of the load balancer, because of its health check.
+			log.WithoutContext().Debugf("Unable to remove the server %s of the service %s: %v", serverURL, serviceName, err)
+		}
+	}
+
+	if r.drained[serviceName] == nil {
+		r.drained[serviceName] = make(map[string]bool)
+	}
+	r.drained[serviceName][serverURL] = true
+
+	return nil
+}
+
+// Undrain adds the server back to the load balancers of the service.
+func (r *Registry) Undrain(serviceName, serverURL string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	wasDrained := r.drained[serviceName][serverURL]
+	delete(r.drained[serviceName], serverURL)
+	if len(r.drained[serviceName]) == 0 {
+		delete(r.drained, serviceName)
+	}
+
+	server, u, err := r.findServer(serviceName, serverURL)
+	if err != nil {
+		if wasDrained {
+			// The server is no longer in the configuration, forgetting it is enough.
+			return nil
+		}
+		return err
+	}
+
+	for _, balancer := range r.services[serviceName].Balancers {
+		if err := balancer.UpsertServer(u, roundrobin.Weight(server.Weight)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Drained returns the sorted URLs of the drained servers, by service.
+func (r *Registry) Drained() map[string][]string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	drained := make(map[string][]string)
+	for serviceName, servers := range r.drained {
+		for serverURL := range servers {
+			drained[serviceName] = append(drained[serviceName], serverURL)
+		}
+		sort.Strings(drained[serviceName])
+	}
+
+	return drained
+}
+
+func (r *Registry) findServer(serviceName, serverURL string) (config.Server, *url.URL, error) {
+	service, ok := r.services[serviceName]
+	if !ok {
+		return config.Server{}, nil, ErrServiceNotFound
+	}
+
+	for _, server := range service.Servers {
+		if server.URL != serverURL {
+			continue
+		}
+
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			return config.Server{}, nil, err
+		}
+		return server, u, nil
+	}
+
+	return config.Server{}, nil, ErrServerNotFound
+}
@@ -0,0 +1,89 @@
+package drain
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+type fakeBalancer struct {
+	servers map[string]bool
+}
+
+func (b *fakeBalancer) RemoveServer(u *url.URL) error {
+	delete(b.servers, u.String())
+	return nil
+}
+
+func (b *fakeBalancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	b.servers[u.String()] = true
+	return nil
+}
+
+func newRegistry(balancer *fakeBalancer) *Registry {
+	r := NewRegistry()
+	r.SetServices(map[string]Service{
+		"foo": {
+			Balancers: []Balancer{balancer},
+			Servers: []config.Server{
+				{URL: "http://10.0.0.1:80", Weight: 1},
+				{URL: "http://10.0.0.2:80", Weight: 1},
+			},
+		},
+	})
+	return r
+}
+
+func TestDrain(t *testing.T) {
+	balancer := &fakeBalancer{servers: map[string]bool{"http://10.0.0.1:80": true, "http://10.0.0.2:80": true}}
+	r := newRegistry(balancer)
+
+	require.NoError(t, r.Drain("foo", "http://10.0.0.1:80"))
+
+	assert.True(t, r.IsDrained("foo", "http://10.0.0.1:80"))
+	assert.False(t, r.IsDrained("foo", "http://10.0.0.2:80"))
+	assert.Equal(t, map[string]bool{"http://10.0.0.2:80": true}, balancer.servers)
+	assert.Equal(t, map[string][]string{"foo": {"http://10.0.0.1:80"}}, r.Drained())
+
+	// A new configuration keeps the server drained.
+	r.SetServices(map[string]Service{})
+	assert.True(t, r.IsDrained("foo", "http://10.0.0.1:80"))
+}
+
+func TestDrainUnknownServer(t *testing.T) {
+	r := newRegistry(&fakeBalancer{servers: map[string]bool{}})
+
+	assert.Equal(t, ErrServiceNotFound, r.Drain("bar", "http://10.0.0.1:80"))
+	assert.Equal(t, ErrServerNotFound, r.Drain("foo", "http://10.0.0.3:80"))
+	assert.Empty(t, r.Drained())
+}
+
+func TestUndrain(t *testing.T) {
+	balancer := &fakeBalancer{servers: map[string]bool{"http://10.0.0.1:80": true, "http://10.0.0.2:80": true}}
+	r := newRegistry(balancer)
+
+	require.NoError(t, r.Drain("foo", "http://10.0.0.1:80"))
+	require.NoError(t, r.Undrain("foo", "http://10.0.0.1:80"))
+
+	assert.False(t, r.IsDrained("foo", "http://10.0.0.1:80"))
+	assert.Equal(t, map[string]bool{"http://10.0.0.1:80": true, "http://10.0.0.2:80": true}, balancer.servers)
+	assert.Empty(t, r.Drained())
+
+	assert.Equal(t, ErrServerNotFound, r.Undrain("foo", "http://10.0.0.3:80"))
+}
+
+func TestUndrainRemovedServer(t *testing.T) {
+	r := newRegistry(&fakeBalancer{servers: map[string]bool{}})
+
+	require.NoError(t, r.Drain("foo", "http://10.0.0.1:80"))
+
+	// The server is no longer in the configuration.
+	r.SetServices(map[string]Service{})
+
+	require.NoError(t, r.Undrain("foo", "http://10.0.0.1:80"))
+	assert.Empty(t, r.Drained())
+}
@@ -0,0 +1,165 @@
+package drain
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// The outcomes of the drained requests.
+const (
+	outcomeCompleted = "completed"
+	outcomeCanceled  = "canceled"
+)
+
+// inFlightRequest is a request in flight to a server.
+type inFlightRequest struct {
+	cancel   context.CancelFunc
+	draining bool
+	canceled bool
+	counter  metrics.Counter
+}
+
+// inFlight keeps track of the requests in flight to the servers, by service and server.
+type inFlight struct {
+	lock     sync.Mutex
+	requests map[string]map[string]map[*inFlightRequest]struct{}
+}
+
+func newInFlight() *inFlight {
+	return &inFlight{requests: make(map[string]map[string]map[*inFlightRequest]struct{})}
+}
+
+// Track wraps the forwarder of the servers of a service, to keep track of the requests in flight to them,
+// so that the requests to a server removed from the configuration are drained.
+func (r *Registry) Track(serviceName string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+
+		// The load balancer has set the URL of the request to the URL of its server.
+		server := serverKey(req.URL)
+		request := &inFlightRequest{cancel: cancel}
+
+		r.inFlight.add(serviceName, server, request)
+		defer r.inFlight.remove(serviceName, server, request)
+
+		next.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+func (f *inFlight) add(serviceName, server string, request *inFlightRequest) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.requests[serviceName] == nil {
+		f.requests[serviceName] = make(map[string]map[*inFlightRequest]struct{})
+	}
+	if f.requests[serviceName][server] == nil {
+		f.requests[serviceName][server] = make(map[*inFlightRequest]struct{})
+	}
+	f.requests[serviceName][server][request] = struct{}{}
+}
+
+func (f *inFlight) remove(serviceName, server string, request *inFlightRequest) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.requests[serviceName][server], request)
+	if len(f.requests[serviceName][server]) == 0 {
+		delete(f.requests[serviceName], server)
+	}
+	if len(f.requests[serviceName]) == 0 {
+		delete(f.requests, serviceName)
+	}
+
+	if request.draining && !request.canceled && request.counter != nil {
+		request.counter.With("outcome", outcomeCompleted, "backend", serviceName).Add(1)
+	}
+}
+
+// drain lets the requests in flight to a server removed from the configuration finish,
+// and cancels those still in flight after the grace period, if any.
+func (f *inFlight) drain(serviceName, serverURL string, gracePeriod time.Duration, counter metrics.Counter) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return
+	}
+	server := serverKey(u)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var draining []*inFlightRequest
+	for request := range f.requests[serviceName][server] {
+		if request.draining {
+			continue
+		}
+		request.draining = true
+		request.counter = counter
+		draining = append(draining, request)
+	}
+
+	if len(draining) == 0 {
+		return
+	}
+
+	logger := log.WithoutContext().WithField(log.ServiceName, serviceName)
+	logger.Debugf("Draining the %d requests in flight to the removed server %s", len(draining), serverURL)
+
+	if gracePeriod <= 0 {
+		return
+	}
+
+	time.AfterFunc(gracePeriod, func() {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		var canceled []*inFlightRequest
+		for _, request := range draining {
+			if _, ok := f.requests[serviceName][server][request]; ok {
+				request.canceled = true
+				canceled = append(canceled, request)
+			}
+		}
+
+		if len(canceled) == 0 {
+			return
+		}
+
+		logger.Warnf("Canceling the %d requests still in flight to the removed server %s after %s", len(canceled), serverURL, gracePeriod)
+		if counter != nil {
+			counter.With("outcome", outcomeCanceled, "backend", serviceName).Add(float64(len(canceled)))
+		}
+
+		for _, request := range canceled {
+			request.cancel()
+		}
+	})
+}
+
+// removedServers returns the previous servers which are not in the current servers.
+func removedServers(previous, current []config.Server) []config.Server {
+	urls := make(map[string]bool)
+	for _, server := range current {
+		urls[server.URL] = true
+	}
+
+	var removed []config.Server
+	for _, server := range previous {
+		if !urls[server.URL] {
+			removed = append(removed, server)
+		}
+	}
+	return removed
+}
+
+func serverKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
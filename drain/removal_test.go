@@ -0,0 +1,94 @@
+package drain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Track(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		servers         []config.Server
+		gracePeriod     time.Duration
+		expectedCode    int
+		expectedCounter float64
+		expectedLabels  []string
+	}{
+		{
+			desc:         "server kept",
+			servers:      []config.Server{{URL: "http://10.0.0.1:80"}},
+			gracePeriod:  10 * time.Millisecond,
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:            "server removed without grace period",
+			expectedCode:    http.StatusOK,
+			expectedCounter: 1,
+			expectedLabels:  []string{"outcome", outcomeCompleted, "backend", "foo"},
+		},
+		{
+			desc:            "server removed with a grace period",
+			gracePeriod:     10 * time.Millisecond,
+			expectedCode:    http.StatusBadGateway,
+			expectedCounter: 1,
+			expectedLabels:  []string{"outcome", outcomeCanceled, "backend", "foo"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			counter := &testhelpers.CollectingCounter{}
+
+			r := NewRegistry()
+			r.SetServices(map[string]Service{
+				"foo": {
+					Servers:            []config.Server{{URL: "http://10.0.0.1:80"}},
+					RemovalGracePeriod: test.gracePeriod,
+					DrainedRequests:    counter,
+				},
+			})
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			handler := r.Track("foo", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				close(started)
+				select {
+				case <-release:
+					rw.WriteHeader(http.StatusOK)
+				case <-req.Context().Done():
+					rw.WriteHeader(http.StatusBadGateway)
+				}
+			}))
+
+			recorder := httptest.NewRecorder()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://10.0.0.1:80/path", nil))
+			}()
+
+			<-started
+			r.SetServices(map[string]Service{"foo": {Servers: test.servers}})
+
+			select {
+			case <-done:
+			case <-time.After(100 * time.Millisecond):
+				close(release)
+				<-done
+			}
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			assert.Equal(t, test.expectedCounter, counter.CounterValue)
+			assert.Equal(t, test.expectedLabels, counter.LastLabelValues)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/containous/traefik/secrets"
+)
+
+const (
+	// KeyEnvVar is the environment variable holding the key, when the configuration doesn't.
+	KeyEnvVar = "TRAEFIK_STORAGE_KEY"
+
+	algorithm = "aes-256-gcm"
+	keySize   = 32
+)
+
+// ErrNoKey is returned when decrypting data without a key.
+var ErrNoKey = errors.New("the data is encrypted, and no encryption key is configured")
+
+// Config holds the configuration of the encryption of a storage.
+type Config struct {
+	Key string `description:"Base64 encoded 256 bits key, or a secret reference to it (default $TRAEFIK_STORAGE_KEY)"`
+}
+
+// envelope is the encrypted form of the data: the data is encrypted with a random data key,
+// itself encrypted with the key of the configuration.
+type envelope struct {
+	Encryption string `json:"encryption"`
+	DataKey    []byte `json:"dataKey"`
+	Data       []byte `json:"data"`
+}
+
+// Cipher encrypts and decrypts the data of a storage with an envelope encryption.
+// The data which is not encrypted is decrypted as is, so a storage is encrypted by its next write.
+// A nil Cipher doesn't encrypt the data, and fails to decrypt encrypted data.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New creates a Cipher with the key of the configuration, nil if the configuration is nil.
+// The secret references are resolved by the store, or by the default store if it is nil.
+func New(config *Config, store *secrets.Store) (*Cipher, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	value := config.Key
+	if len(value) == 0 {
+		value = os.Getenv(KeyEnvVar)
+	}
+
+	if secrets.IsReference(value) {
+		var err error
+		if store != nil {
+			value, err = store.Resolve(value)
+		} else {
+			value, err = secrets.Resolve(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve the encryption key: %v", err)
+		}
+	}
+
+	if len(value) == 0 {
+		return nil, fmt.Errorf("no encryption key, set it in the configuration or in $%s", KeyEnvVar)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("the encryption key is not base64 encoded: %v", err)
+	}
+
+	return NewCipher(key)
+}
+
+// NewCipher creates a Cipher with the 256 bits key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("the encryption key must be %d bytes long, not %d", keySize, len(key))
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// IsEncrypted tells whether the data has been encrypted by a Cipher.
+func IsEncrypted(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+
+	env := envelope{}
+	return json.Unmarshal(data, &env) == nil && env.Encryption == algorithm
+}
+
+// Encrypt encrypts the data, with a new data key.
+func (c *Cipher) Encrypt(plaintext []byte) ([]byte, error) {
+	if c == nil {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := seal(c.aead, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := seal(dataAEAD, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(envelope{Encryption: algorithm, DataKey: encryptedKey, Data: data})
+}
+
+// Decrypt decrypts the data, or returns it as is if it is not encrypted.
+func (c *Cipher) Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+
+	if c == nil {
+		return nil, ErrNoKey
+	}
+
+	env := envelope{}
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+
+	dataKey, err := open(c.aead, env.DataKey)
+	if err != nil {
+		return nil, errors.New("unable to decrypt the data key, the encryption key is wrong")
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(dataAEAD, env.Data)
+	if err != nil {
+		return nil, errors.New("unable to decrypt the data, it has been altered")
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext, prefixed by a random nonce.
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("data too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
@@ -0,0 +1,132 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+
+	testCases := []struct {
+		desc          string
+		config        *Config
+		env           string
+		expectedNil   bool
+		expectedError bool
+	}{
+		{
+			desc:        "no configuration",
+			expectedNil: true,
+		},
+		{
+			desc:   "key",
+			config: &Config{Key: key},
+		},
+		{
+			desc:   "key from the environment",
+			config: &Config{},
+			env:    key,
+		},
+		{
+			desc:          "no key",
+			config:        &Config{},
+			expectedError: true,
+		},
+		{
+			desc:          "not base64",
+			config:        &Config{Key: "not base64!"},
+			expectedError: true,
+		},
+		{
+			desc:          "short key",
+			config:        &Config{Key: base64.StdEncoding.EncodeToString([]byte("short"))},
+			expectedError: true,
+		},
+		{
+			desc:          "unresolved secret",
+			config:        &Config{Key: "file:///does/not/exist"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			require.NoError(t, os.Setenv(KeyEnvVar, test.env))
+			defer func() { _ = os.Unsetenv(KeyEnvVar) }()
+
+			cipher, err := New(test.config, nil)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedNil, cipher == nil)
+		})
+	}
+}
+
+func TestCipher(t *testing.T) {
+	cipher, err := NewCipher(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	plaintext := []byte(`{"Account":{"Email":"admin@example.com"}}`)
+
+	encrypted, err := cipher.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "admin@example.com")
+
+	// Each encryption uses a new data key.
+	other, err := cipher.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, other)
+
+	decrypted, err := cipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// The data which is not encrypted is decrypted as is.
+	decrypted, err = cipher.Decrypt(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Another key can't decrypt the data.
+	wrongCipher, err := NewCipher(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = wrongCipher.Decrypt(encrypted)
+	assert.EqualError(t, err, "unable to decrypt the data key, the encryption key is wrong")
+
+	// No key can't decrypt the data.
+	var noCipher *Cipher
+	_, err = noCipher.Decrypt(encrypted)
+	assert.Equal(t, ErrNoKey, err)
+
+	data, err := noCipher.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, data)
+}
+
+func TestCipherAlteredData(t *testing.T) {
+	cipher, err := NewCipher(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	encrypted, err := cipher.Encrypt([]byte("secret"))
+	require.NoError(t, err)
+
+	env := envelope{}
+	require.NoError(t, json.Unmarshal(encrypted, &env))
+	env.Data[len(env.Data)-1] ^= 1
+	altered, err := json.Marshal(env)
+	require.NoError(t, err)
+
+	_, err = cipher.Decrypt(altered)
+	assert.EqualError(t, err, "unable to decrypt the data, it has been altered")
+}
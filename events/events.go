@@ -0,0 +1,107 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// The types of the events.
+const (
+	// ConfigurationReloaded is published when a dynamic configuration is applied.
+	ConfigurationReloaded = "configuration.reloaded"
+	// ConfigurationRejected is published when a dynamic configuration is rejected.
+	ConfigurationRejected = "configuration.rejected"
+	// ConfigurationSettled is published when the configurations received in quick succession have been applied together.
+	ConfigurationSettled = "configuration.settled"
+	// ServerDown is published when a server fails its health check, and is removed from its load balancer.
+	ServerDown = "server.down"
+	// ServerUp is published when a server passes its health check again, and is added back to its load balancer.
+	ServerUp = "server.up"
+	// CertificateRenewed is published when an ACME certificate is renewed.
+	CertificateRenewed = "certificate.renewed"
+	// CertificateRenewalFailed is published when an ACME certificate can't be renewed.
+	CertificateRenewalFailed = "certificate.renewal_failed"
+	// CertificateExpiring is published when a served certificate expires soon, or has expired.
+	CertificateExpiring = "certificate.expiring"
+	// CertificateRevoked is published when the OCSP responder of a served certificate reports it as revoked.
+	CertificateRevoked = "certificate.revoked"
+	// CertificateUnexpectedIssuer is published when a served certificate is issued by an issuer which is not allowed.
+	CertificateUnexpectedIssuer = "certificate.unexpected_issuer"
+	// ProviderDisconnected is published when a provider loses the connection to its source of configuration, and retries.
+	ProviderDisconnected = "provider.disconnected"
+	// CircuitBreakerTripped is published when a circuit breaker trips, and starts answering on behalf of its service.
+	CircuitBreakerTripped = "circuitbreaker.tripped"
+	// CircuitBreakerStandby is published when a circuit breaker recovers, and forwards the requests again.
+	CircuitBreakerStandby = "circuitbreaker.standby"
+)
+
+// Event is a change of the configuration or of the health of Traefik.
+type Event struct {
+	Type       string            `json:"type"`
+	Time       time.Time         `json:"time"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Broker dispatches the published events to the subscribers.
+// A subscriber which doesn't keep up misses the events which don't fit in its buffer.
+type Broker struct {
+	lock        sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBroker creates a new Broker.
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+var defaultBroker = NewBroker()
+
+// Default returns the broker the events of Traefik are published to.
+func Default() *Broker {
+	return defaultBroker
+}
+
+// Publish publishes an event to the default broker.
+func Publish(eventType string, attributes map[string]string) {
+	defaultBroker.Publish(eventType, attributes)
+}
+
+// Publish publishes an event to the subscribers, without waiting for them.
+func (b *Broker) Publish(eventType string, attributes map[string]string) {
+	event := Event{
+		Type:       eventType,
+		Time:       time.Now().UTC(),
+		Attributes: attributes,
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the next events, buffering up to size events,
+// and the function to call to unsubscribe.
+func (b *Broker) Subscribe(size int) (<-chan Event, func()) {
+	subscriber := make(chan Event, size)
+
+	b.lock.Lock()
+	b.subscribers[subscriber] = struct{}{}
+	b.lock.Unlock()
+
+	var once sync.Once
+	return subscriber, func() {
+		once.Do(func() {
+			b.lock.Lock()
+			delete(b.subscribers, subscriber)
+			b.lock.Unlock()
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker()
+
+	first, unsubscribeFirst := broker.Subscribe(10)
+	second, unsubscribeSecond := broker.Subscribe(1)
+	defer unsubscribeSecond()
+
+	broker.Publish(ServerDown, map[string]string{"service": "foo", "url": "http://10.0.0.1"})
+	broker.Publish(ServerUp, map[string]string{"service": "foo", "url": "http://10.0.0.1"})
+
+	require.Len(t, first, 2)
+	event := <-first
+	assert.Equal(t, ServerDown, event.Type)
+	assert.Equal(t, map[string]string{"service": "foo", "url": "http://10.0.0.1"}, event.Attributes)
+	assert.False(t, event.Time.IsZero())
+	assert.Equal(t, ServerUp, (<-first).Type)
+
+	// The second subscriber misses the events over its buffer.
+	require.Len(t, second, 1)
+	assert.Equal(t, ServerDown, (<-second).Type)
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+
+	broker.Publish(ConfigurationReloaded, nil)
+	assert.Len(t, first, 0)
+	assert.Len(t, second, 1)
+}
@@ -0,0 +1,101 @@
+package function
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"github.com/satori/go.uuid"
+)
+
+const (
+	defaultEventType = "io.traefik.http.request"
+	// structuredContentType is the content type of the events in the structured content mode.
+	structuredContentType = "application/cloudevents+json"
+)
+
+// cloudEventsInvoker invokes a function behind a CloudEvents HTTP gateway.
+// The events are sent in the binary content mode, and the responses can be events in the binary or structured content modes.
+type cloudEventsInvoker struct {
+	client    *http.Client
+	url       string
+	eventType string
+	source    string
+}
+
+func newCloudEventsInvoker(serviceName string, conf *config.CloudEventsFunction, client *http.Client) (*cloudEventsInvoker, error) {
+	if conf.URL == "" {
+		return nil, errors.New("no CloudEvents gateway URL defined")
+	}
+
+	eventType := conf.Type
+	if eventType == "" {
+		eventType = defaultEventType
+	}
+
+	source := conf.Source
+	if source == "" {
+		source = "/traefik/services/" + serviceName
+	}
+
+	return &cloudEventsInvoker{
+		client:    client,
+		url:       conf.URL,
+		eventType: eventType,
+		source:    source,
+	}, nil
+}
+
+func (c *cloudEventsInvoker) invoke(ctx context.Context, event []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(event))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Ce-Specversion", "1.0")
+	req.Header.Set("Ce-Id", uuid.NewV4().String())
+	req.Header.Set("Ce-Type", c.eventType)
+	req.Header.Set("Ce-Source", c.source)
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, errThrottled
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), structuredContentType) {
+		return body, nil
+	}
+
+	var structured struct {
+		Data       json.RawMessage `json:"data"`
+		DataBase64 string          `json:"data_base64"`
+	}
+	if err := json.Unmarshal(body, &structured); err != nil {
+		return nil, err
+	}
+
+	if structured.DataBase64 != "" {
+		return base64.StdEncoding.DecodeString(structured.DataBase64)
+	}
+	return structured.Data, nil
+}
@@ -0,0 +1,124 @@
+package function
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/satori/go.uuid"
+)
+
+// proxyRequest is the event of a request, in the format of the API Gateway proxy integration.
+type proxyRequest struct {
+	HTTPMethod                      string              `json:"httpMethod"`
+	Path                            string              `json:"path"`
+	Headers                         map[string]string   `json:"headers"`
+	MultiValueHeaders               map[string][]string `json:"multiValueHeaders"`
+	QueryStringParameters           map[string]string   `json:"queryStringParameters"`
+	MultiValueQueryStringParameters map[string][]string `json:"multiValueQueryStringParameters"`
+	RequestContext                  proxyRequestContext `json:"requestContext"`
+	Body                            string              `json:"body"`
+	IsBase64Encoded                 bool                `json:"isBase64Encoded"`
+}
+
+type proxyRequestContext struct {
+	RequestID  string        `json:"requestId"`
+	HTTPMethod string        `json:"httpMethod"`
+	Path       string        `json:"path"`
+	Identity   proxyIdentity `json:"identity"`
+}
+
+type proxyIdentity struct {
+	SourceIP string `json:"sourceIp"`
+}
+
+// proxyResponse is the response of a function, in the format of the API Gateway proxy integration.
+type proxyResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+// newEvent translates the request to the event of its invocation.
+// The body is base64 encoded if it isn't valid UTF-8.
+func newEvent(req *http.Request, body []byte) ([]byte, error) {
+	event := proxyRequest{
+		HTTPMethod:                      req.Method,
+		Path:                            req.URL.Path,
+		Headers:                         make(map[string]string),
+		MultiValueHeaders:               make(map[string][]string),
+		QueryStringParameters:           make(map[string]string),
+		MultiValueQueryStringParameters: make(map[string][]string),
+		RequestContext: proxyRequestContext{
+			RequestID:  uuid.NewV4().String(),
+			HTTPMethod: req.Method,
+			Path:       req.URL.Path,
+		},
+	}
+
+	for name, values := range req.Header {
+		event.Headers[name] = values[len(values)-1]
+		event.MultiValueHeaders[name] = values
+	}
+	if req.Host != "" {
+		event.Headers["Host"] = req.Host
+		event.MultiValueHeaders["Host"] = []string{req.Host}
+	}
+
+	for name, values := range req.URL.Query() {
+		event.QueryStringParameters[name] = values[len(values)-1]
+		event.MultiValueQueryStringParameters[name] = values
+	}
+
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		event.RequestContext.Identity.SourceIP = host
+	} else {
+		event.RequestContext.Identity.SourceIP = req.RemoteAddr
+	}
+
+	if utf8.Valid(body) {
+		event.Body = string(body)
+	} else {
+		event.Body = base64.StdEncoding.EncodeToString(body)
+		event.IsBase64Encoded = true
+	}
+
+	return json.Marshal(event)
+}
+
+// writeResponse translates the response of the function to the HTTP response.
+func writeResponse(rw http.ResponseWriter, result []byte) error {
+	var resp proxyResponse
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 100 || resp.StatusCode > 999 {
+		return errors.New("invalid status code")
+	}
+
+	body := []byte(resp.Body)
+	if resp.IsBase64Encoded {
+		var err error
+		body, err = base64.StdEncoding.DecodeString(resp.Body)
+		if err != nil {
+			return err
+		}
+	}
+
+	for name, value := range resp.Headers {
+		rw.Header().Set(name, value)
+	}
+	for name, values := range resp.MultiValueHeaders {
+		rw.Header()[http.CanonicalHeaderKey(name)] = values
+	}
+
+	rw.WriteHeader(resp.StatusCode)
+	_, err := rw.Write(body)
+	return err
+}
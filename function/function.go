@@ -0,0 +1,174 @@
+package function
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+)
+
+const (
+	// maxPayloadBytes is the maximum size of a request body, the limit of the synchronous Lambda invocations.
+	maxPayloadBytes = 6 * 1024 * 1024
+	// warmPeriod is the period an idle instance of a function is assumed to be kept warm.
+	warmPeriod = 5 * time.Minute
+)
+
+// errThrottled is returned by an invoker when the function platform throttles the invocations.
+var errThrottled = errors.New("invocation throttled")
+
+// invoker invokes a function with an event, and returns the response of the function.
+type invoker interface {
+	invoke(ctx context.Context, event []byte) ([]byte, error)
+}
+
+// Handler invokes a function for each request.
+// The invocations which can't reuse an instance idle for less than the warm period are counted as cold starts.
+type Handler struct {
+	serviceName    string
+	invoker        invoker
+	timeout        time.Duration
+	maxConcurrency int
+	durations      gokitmetrics.Histogram
+
+	lock     sync.Mutex
+	inFlight int
+	// idle holds the time the idle instances were last used, the most recent last.
+	idle []time.Time
+}
+
+// New creates a handler invoking the function of the service.
+// The invocations are sent with the transport, and their durations reported to the metrics registry, which can be nil.
+func New(serviceName string, conf *config.FunctionService, transport http.RoundTripper, registry metrics.Registry) (*Handler, error) {
+	if registry == nil {
+		registry = metrics.NewVoidRegistry()
+	}
+
+	client := &http.Client{Transport: transport}
+
+	var inv invoker
+	var err error
+	switch {
+	case conf.Lambda != nil && conf.CloudEvents != nil:
+		return nil, errors.New("a function service can only invoke one function")
+	case conf.Lambda != nil:
+		inv, err = newLambdaInvoker(conf.Lambda, client)
+	case conf.CloudEvents != nil:
+		inv, err = newCloudEventsInvoker(serviceName, conf.CloudEvents, client)
+	default:
+		return nil, errors.New("no function defined")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{
+		serviceName:    serviceName,
+		invoker:        inv,
+		timeout:        time.Duration(conf.Timeout),
+		maxConcurrency: conf.MaxConcurrency,
+		durations:      registry.BackendInvocationDurationHistogram(),
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(req.Context())
+
+	cold, ok := h.acquire()
+	if !ok {
+		logger.Debugf("Too many invocations in flight for the service %s", h.serviceName)
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	defer h.release()
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxPayloadBytes+1))
+	if err != nil {
+		logger.Debugf("Error while reading the request body: %v", err)
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxPayloadBytes {
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	event, err := newEvent(req, body)
+	if err != nil {
+		logger.Errorf("Error while building the event of the request: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := req.Context()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result, err := h.invoker.invoke(ctx, event)
+	h.durations.With("backend", h.serviceName, "cold", strconv.FormatBool(cold)).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		logger.Debugf("Error while invoking the function of the service %s: %v", h.serviceName, err)
+
+		switch {
+		case err == errThrottled:
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		case ctx.Err() == context.DeadlineExceeded:
+			http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+		default:
+			http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		}
+		return
+	}
+
+	if err := writeResponse(rw, result); err != nil {
+		logger.Debugf("Invalid response of the function of the service %s: %v", h.serviceName, err)
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+	}
+}
+
+// acquire reserves an instance of the function, and returns whether it is a cold one,
+// or false if the maximum concurrency is reached.
+func (h *Handler) acquire() (bool, bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.maxConcurrency > 0 && h.inFlight >= h.maxConcurrency {
+		return false, false
+	}
+	h.inFlight++
+
+	// The instances idle for longer than the warm period are assumed to be reclaimed.
+	expired := time.Now().Add(-warmPeriod)
+	for len(h.idle) > 0 && h.idle[0].Before(expired) {
+		h.idle = h.idle[1:]
+	}
+
+	if len(h.idle) == 0 {
+		return true, true
+	}
+
+	h.idle = h.idle[:len(h.idle)-1]
+	return false, true
+}
+
+func (h *Handler) release() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.inFlight--
+	h.idle = append(h.idle, time.Now())
+}
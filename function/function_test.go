@@ -0,0 +1,327 @@
+package function
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		conf        *config.FunctionService
+		expectedErr bool
+	}{
+		{
+			desc: "Lambda function",
+			conf: &config.FunctionService{Lambda: &config.LambdaFunction{Region: "eu-west-1", FunctionName: "foo"}},
+		},
+		{
+			desc: "CloudEvents function",
+			conf: &config.FunctionService{CloudEvents: &config.CloudEventsFunction{URL: "http://foo"}},
+		},
+		{
+			desc:        "no function",
+			conf:        &config.FunctionService{},
+			expectedErr: true,
+		},
+		{
+			desc: "two functions",
+			conf: &config.FunctionService{
+				Lambda:      &config.LambdaFunction{Region: "eu-west-1", FunctionName: "foo"},
+				CloudEvents: &config.CloudEventsFunction{URL: "http://foo"},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:        "Lambda function without region",
+			conf:        &config.FunctionService{Lambda: &config.LambdaFunction{FunctionName: "foo"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "Lambda function without name",
+			conf:        &config.FunctionService{Lambda: &config.LambdaFunction{Region: "eu-west-1"}},
+			expectedErr: true,
+		},
+		{
+			desc:        "CloudEvents function without URL",
+			conf:        &config.FunctionService{CloudEvents: &config.CloudEventsFunction{}},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New("foo", test.conf, http.DefaultTransport, nil)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLambda(t *testing.T) {
+	var event proxyRequest
+	var invocation *http.Request
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		invocation = req
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &event))
+
+		if event.Path == "/error" {
+			rw.Header().Set("X-Amz-Function-Error", "Unhandled")
+			_, _ = rw.Write([]byte(`{"errorMessage":"boom"}`))
+			return
+		}
+		if event.Path == "/throttled" {
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		_, _ = rw.Write([]byte(`{
+			"statusCode": 201,
+			"headers": {"Content-Type": "text/plain"},
+			"multiValueHeaders": {"X-Foo": ["a", "b"]},
+			"body": "` + base64.StdEncoding.EncodeToString([]byte("created")) + `",
+			"isBase64Encoded": true
+		}`))
+	}))
+	defer server.Close()
+
+	handler, err := New("foo", &config.FunctionService{
+		Lambda: &config.LambdaFunction{
+			Region:          "eu-west-1",
+			FunctionName:    "my-function",
+			Qualifier:       "live",
+			AccessKeyID:     "AKID",
+			SecretAccessKey: "SECRET",
+			Endpoint:        server.URL,
+		},
+	}, http.DefaultTransport, nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/foo?bar=1&bar=2", strings.NewReader("hello"))
+	req.Header.Set("X-Baz", "baz")
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, "/2015-03-31/functions/my-function/invocations", invocation.URL.Path)
+	assert.Equal(t, "live", invocation.URL.Query().Get("Qualifier"))
+	assert.Contains(t, invocation.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/")
+	assert.Contains(t, invocation.Header.Get("Authorization"), "/eu-west-1/lambda/aws4_request")
+
+	assert.Equal(t, http.MethodPost, event.HTTPMethod)
+	assert.Equal(t, "/foo", event.Path)
+	assert.Equal(t, "2", event.QueryStringParameters["bar"])
+	assert.Equal(t, []string{"1", "2"}, event.MultiValueQueryStringParameters["bar"])
+	assert.Equal(t, "baz", event.Headers["X-Baz"])
+	assert.Equal(t, "example.com", event.Headers["Host"])
+	assert.Equal(t, "10.0.0.1", event.RequestContext.Identity.SourceIP)
+	assert.Equal(t, "hello", event.Body)
+	assert.False(t, event.IsBase64Encoded)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "text/plain", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, []string{"a", "b"}, recorder.Header()["X-Foo"])
+	assert.Equal(t, "created", recorder.Body.String())
+
+	testCases := []struct {
+		path         string
+		expectedCode int
+	}{
+		{path: "/error", expectedCode: http.StatusBadGateway},
+		{path: "/throttled", expectedCode: http.StatusServiceUnavailable},
+	}
+
+	for _, test := range testCases {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com"+test.path, nil))
+
+		assert.Equal(t, test.expectedCode, recorder.Code, test.path)
+	}
+}
+
+func TestCloudEvents(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		contentType  string
+		body         string
+		code         int
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			desc:         "binary content mode",
+			contentType:  "application/json",
+			body:         `{"statusCode": 200, "body": "foo"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: "foo",
+		},
+		{
+			desc:         "structured content mode",
+			contentType:  "application/cloudevents+json; charset=utf-8",
+			body:         `{"specversion": "1.0", "id": "1", "data": {"statusCode": 202, "body": "bar"}}`,
+			expectedCode: http.StatusAccepted,
+			expectedBody: "bar",
+		},
+		{
+			desc:         "structured content mode with base64 data",
+			contentType:  "application/cloudevents+json",
+			body:         `{"specversion": "1.0", "id": "1", "data_base64": "` + base64.StdEncoding.EncodeToString([]byte(`{"statusCode": 200, "body": "baz"}`)) + `"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: "baz",
+		},
+		{
+			desc:         "invalid response",
+			contentType:  "application/json",
+			body:         `{}`,
+			expectedCode: http.StatusBadGateway,
+		},
+		{
+			desc:         "gateway error",
+			code:         http.StatusInternalServerError,
+			expectedCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			var event *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				event = req
+
+				rw.Header().Set("Content-Type", test.contentType)
+				if test.code != 0 {
+					rw.WriteHeader(test.code)
+				}
+				_, _ = rw.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			handler, err := New("foo", &config.FunctionService{
+				CloudEvents: &config.CloudEventsFunction{URL: server.URL},
+			}, http.DefaultTransport, nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil))
+
+			assert.Equal(t, "1.0", event.Header.Get("Ce-Specversion"))
+			assert.Equal(t, defaultEventType, event.Header.Get("Ce-Type"))
+			assert.Equal(t, "/traefik/services/foo", event.Header.Get("Ce-Source"))
+			assert.NotEmpty(t, event.Header.Get("Ce-Id"))
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	handler, err := New("foo", &config.FunctionService{
+		CloudEvents: &config.CloudEventsFunction{URL: server.URL},
+		Timeout:     parse.Duration(50 * time.Millisecond),
+	}, http.DefaultTransport, nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil))
+
+	assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	invoked := make(chan struct{})
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		invoked <- struct{}{}
+		<-release
+		_, _ = rw.Write([]byte(`{"statusCode": 200}`))
+	}))
+	defer server.Close()
+
+	handler, err := New("foo", &config.FunctionService{
+		CloudEvents:    &config.CloudEventsFunction{URL: server.URL},
+		MaxConcurrency: 1,
+	}, http.DefaultTransport, nil)
+	require.NoError(t, err)
+
+	first := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil))
+	}()
+	<-invoked
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	close(release)
+	<-done
+	assert.Equal(t, http.StatusOK, first.Code)
+}
+
+func TestColdStarts(t *testing.T) {
+	h := &Handler{}
+
+	cold, ok := h.acquire()
+	require.True(t, ok)
+	assert.True(t, cold)
+
+	cold, ok = h.acquire()
+	require.True(t, ok)
+	assert.True(t, cold, "no idle instance")
+
+	h.release()
+	h.release()
+
+	cold, ok = h.acquire()
+	require.True(t, ok)
+	assert.False(t, cold, "idle instance")
+	h.release()
+
+	// The instances idle for longer than the warm period are reclaimed.
+	h.idle = []time.Time{time.Now().Add(-2 * warmPeriod), time.Now().Add(-time.Minute)}
+
+	cold, ok = h.acquire()
+	require.True(t, ok)
+	assert.False(t, cold)
+
+	cold, ok = h.acquire()
+	require.True(t, ok)
+	assert.True(t, cold)
+}
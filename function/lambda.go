@@ -0,0 +1,98 @@
+package function
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/containous/traefik/config"
+)
+
+// lambdaInvoker invokes an AWS Lambda function synchronously.
+type lambdaInvoker struct {
+	client *http.Client
+	signer *v4.Signer
+	region string
+	url    string
+}
+
+func newLambdaInvoker(conf *config.LambdaFunction, client *http.Client) (*lambdaInvoker, error) {
+	if conf.Region == "" {
+		return nil, errors.New("no region defined for the Lambda function")
+	}
+	if conf.FunctionName == "" {
+		return nil, errors.New("no Lambda function name defined")
+	}
+
+	endpoint := conf.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://lambda.%s.amazonaws.com", conf.Region)
+	}
+
+	invocationURL := fmt.Sprintf("%s/2015-03-31/functions/%s/invocations", endpoint, url.PathEscape(conf.FunctionName))
+	if conf.Qualifier != "" {
+		invocationURL += "?Qualifier=" + url.QueryEscape(conf.Qualifier)
+	}
+
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.StaticProvider{
+			Value: credentials.Value{
+				AccessKeyID:     conf.AccessKeyID,
+				SecretAccessKey: conf.SecretAccessKey,
+			},
+		},
+		&credentials.EnvProvider{},
+		&credentials.SharedCredentialsProvider{},
+		defaults.RemoteCredProvider(*(defaults.Config()), defaults.Handlers()),
+	})
+
+	return &lambdaInvoker{
+		client: client,
+		signer: v4.NewSigner(creds),
+		region: conf.Region,
+		url:    invocationURL,
+	}, nil
+}
+
+func (l *lambdaInvoker) invoke(ctx context.Context, event []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, l.url, bytes.NewReader(event))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Amz-Invocation-Type", "RequestResponse")
+
+	if _, err = l.signer.Sign(req, bytes.NewReader(event), "lambda", l.region, time.Now()); err != nil {
+		return nil, fmt.Errorf("unable to sign the invocation: %v", err)
+	}
+
+	resp, err := l.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, errThrottled
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	case resp.Header.Get("X-Amz-Function-Error") != "":
+		return nil, fmt.Errorf("function error %s: %s", resp.Header.Get("X-Amz-Function-Error"), body)
+	}
+
+	return body, nil
+}
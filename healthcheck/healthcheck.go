@@ -3,6 +3,7 @@ package healthcheck
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -10,8 +11,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/containous/traefik/events"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/watchdog"
 	"github.com/go-kit/kit/metrics"
 	"github.com/vulcand/oxy/roundrobin"
 )
@@ -55,6 +58,10 @@ type BackendConfig struct {
 	Options
 	name         string
 	disabledURLs []*url.URL
+	// weights holds the weights of the disabled servers, to add them back with their weight.
+	weights map[string]int
+	// lock serializes the checks of the backend, which is kept across the configuration reloads of its unchanged service.
+	lock sync.Mutex
 }
 
 func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
@@ -91,10 +98,27 @@ type HealthCheck struct {
 	Backends map[string]*BackendConfig
 	metrics  metricsRegistry
 	cancel   context.CancelFunc
+	probes   *prober
+}
+
+// Configure sets the settings shared by the health checks, before they are launched.
+func (hc *HealthCheck) Configure(config *Config) {
+	var maxConcurrentProbes int
+	if config != nil {
+		maxConcurrentProbes = config.MaxConcurrentProbes
+	}
+	hc.probes = newProber(maxConcurrentProbes)
 }
 
 // SetBackendsConfiguration set backends configuration
+// The servers disabled by the previous backend of the same name stay disabled until they pass their health check again.
 func (hc *HealthCheck) SetBackendsConfiguration(parentCtx context.Context, backends map[string]*BackendConfig) {
+	for name, backend := range backends {
+		if previous, ok := hc.Backends[name]; ok && previous != backend {
+			backend.carryOver(previous)
+		}
+	}
+
 	hc.Backends = backends
 	if hc.cancel != nil {
 		hc.cancel()
@@ -105,39 +129,55 @@ func (hc *HealthCheck) SetBackendsConfiguration(parentCtx context.Context, backe
 	for _, backend := range backends {
 		currentBackend := backend
 		safe.Go(func() {
-			hc.execute(ctx, currentBackend)
+			watchdog.Do(ctx, watchdog.SubsystemHealthChecks, func(ctx context.Context) {
+				hc.execute(ctx, currentBackend)
+			})
 		})
 	}
 }
 
 func (hc *HealthCheck) execute(ctx context.Context, backend *BackendConfig) {
 	log.Debugf("Initial health check for backend: %q", backend.name)
-	hc.checkBackend(backend)
-	ticker := time.NewTicker(backend.Interval)
-	defer ticker.Stop()
+	hc.checkBackend(ctx, backend)
+
+	// The first refresh happens after a random part of the interval,
+	// so that the backends launched together do not probe their servers at the same time.
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(backend.Interval))) + 1)
+	defer timer.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			log.Debugf("Stopping current health check goroutines of backend: %s", backend.name)
 			return
-		case <-ticker.C:
+		case <-timer.C:
 			log.Debugf("Refreshing health check for backend: %s", backend.name)
-			hc.checkBackend(backend)
+			hc.checkBackend(ctx, backend)
+			timer.Reset(backend.Interval)
 		}
 	}
 }
 
-func (hc *HealthCheck) checkBackend(backend *BackendConfig) {
+func (hc *HealthCheck) checkBackend(ctx context.Context, backend *BackendConfig) {
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+
 	enabledURLs := backend.LB.Servers()
 	var newDisabledURLs []*url.URL
 	// FIXME re enable metrics
 	for _, disableURL := range backend.disabledURLs {
 		//serverUpMetricValue := float64(0)
-		if err := checkHealth(disableURL, backend); err == nil {
+		err := hc.probes.check(ctx, disableURL, backend)
+		if err != nil && err == ctx.Err() {
+			// The health check was stopped before probing the server, which stays disabled.
+			newDisabledURLs = append(newDisabledURLs, disableURL)
+			continue
+		}
+		if err == nil {
 			log.Warnf("Health check up: Returning to server list. Backend: %q URL: %q", backend.name, disableURL.String())
-			if err := backend.LB.UpsertServer(disableURL, roundrobin.Weight(1)); err != nil {
+			if err := backend.LB.UpsertServer(disableURL, roundrobin.Weight(backend.weight(disableURL))); err != nil {
 				log.Error(err)
 			}
+			events.Publish(events.ServerUp, map[string]string{"service": backend.name, "url": disableURL.String()})
 			//serverUpMetricValue = 1
 		} else {
 			log.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s", backend.name, disableURL.String(), err)
@@ -151,12 +191,14 @@ func (hc *HealthCheck) checkBackend(backend *BackendConfig) {
 	// FIXME re enable metrics
 	for _, enableURL := range enabledURLs {
 		//serverUpMetricValue := float64(1)
-		if err := checkHealth(enableURL, backend); err != nil {
+		err := hc.probes.check(ctx, enableURL, backend)
+		if err != nil && err == ctx.Err() {
+			return
+		}
+		if err != nil {
 			log.Warnf("Health check failed: Remove from server list. Backend: %q URL: %q Reason: %s", backend.name, enableURL.String(), err)
-			if err := backend.LB.RemoveServer(enableURL); err != nil {
-				log.Error(err)
-			}
-			backend.disabledURLs = append(backend.disabledURLs, enableURL)
+			backend.disable(enableURL)
+			events.Publish(events.ServerDown, map[string]string{"service": backend.name, "url": enableURL.String(), "reason": err.Error()})
 			//serverUpMetricValue = 0
 		}
 		//labelValues := []string{"backend", backend.name, "url", enableURL.String()}
@@ -164,6 +206,68 @@ func (hc *HealthCheck) checkBackend(backend *BackendConfig) {
 	}
 }
 
+// weightedBalancer is a load balancer which knows the weights of its servers.
+type weightedBalancer interface {
+	ServerWeight(u *url.URL) (int, bool)
+}
+
+// disable removes a server from the load balancer of the backend, remembering its weight.
+func (b *BackendConfig) disable(u *url.URL) {
+	if lb, ok := b.LB.(weightedBalancer); ok {
+		if weight, ok := lb.ServerWeight(u); ok {
+			if b.weights == nil {
+				b.weights = make(map[string]int)
+			}
+			b.weights[u.String()] = weight
+		}
+	}
+
+	if err := b.LB.RemoveServer(u); err != nil {
+		log.Error(err)
+	}
+	b.disabledURLs = append(b.disabledURLs, u)
+}
+
+// weight returns the weight of a disabled server, 1 if unknown.
+func (b *BackendConfig) weight(u *url.URL) int {
+	weight, ok := b.weights[u.String()]
+	if !ok {
+		return 1
+	}
+	delete(b.weights, u.String())
+	return weight
+}
+
+// carryOver disables the servers of the backend which the previous backend disabled,
+// rather than presuming them healthy until their first health check.
+func (b *BackendConfig) carryOver(previous *BackendConfig) {
+	previous.lock.Lock()
+	disabledURLs := previous.disabledURLs
+	previous.lock.Unlock()
+
+	if len(disabledURLs) == 0 {
+		return
+	}
+
+	servers := make(map[string]*url.URL)
+	for _, u := range b.LB.Servers() {
+		servers[u.String()] = u
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, disabledURL := range disabledURLs {
+		u, ok := servers[disabledURL.String()]
+		if !ok {
+			continue
+		}
+
+		log.Debugf("Keeping the server disabled. Backend: %q URL: %q", b.name, u.String())
+		b.disable(u)
+	}
+}
+
 // FIXME re add metrics
 //func GetHealthCheck(metrics metricsRegistry) *HealthCheck {
 
@@ -181,6 +285,7 @@ func GetHealthCheck() *HealthCheck {
 func newHealthCheck() *HealthCheck {
 	return &HealthCheck{
 		Backends: make(map[string]*BackendConfig),
+		probes:   newProber(defaultMaxConcurrentProbes),
 		//metrics:  metrics,
 	}
 }
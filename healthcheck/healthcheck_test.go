@@ -152,6 +152,29 @@ func TestSetBackendsConfiguration(t *testing.T) {
 	}
 }
 
+func TestBackendConfig_carryOver(t *testing.T) {
+	lb, err := roundrobin.New(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	require.NoError(t, err)
+
+	sick := testhelpers.MustParseURL("http://sick:80")
+	healthy := testhelpers.MustParseURL("http://healthy:80")
+	require.NoError(t, lb.UpsertServer(sick, roundrobin.Weight(3)))
+	require.NoError(t, lb.UpsertServer(healthy, roundrobin.Weight(1)))
+
+	previous := NewBackendConfig(Options{}, "backendName")
+	previous.disabledURLs = []*url.URL{
+		testhelpers.MustParseURL("http://sick:80"),
+		testhelpers.MustParseURL("http://removed:80"),
+	}
+
+	backend := NewBackendConfig(Options{LB: lb}, "backendName")
+	backend.carryOver(previous)
+
+	assert.Equal(t, []*url.URL{healthy}, lb.Servers())
+	assert.Equal(t, []*url.URL{sick}, backend.disabledURLs)
+	assert.Equal(t, 3, backend.weight(sick))
+}
+
 func TestNewRequest(t *testing.T) {
 	type expected struct {
 		err   bool
@@ -0,0 +1,189 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxConcurrentProbes = 100
+	// pruneInterval is the interval between two removals of the expired probes.
+	pruneInterval = time.Minute
+)
+
+// Config holds the settings shared by the health checks of all the services.
+type Config struct {
+	MaxConcurrentProbes int `description:"Maximum number of health check requests in flight at once (default 100)" export:"true"`
+}
+
+// prober sends the health check requests, at most a given number at once,
+// and shares the result of a request with the backends checking the same URL with the same options,
+// so that a server shared by many services is not probed by each of them.
+// A nil prober sends all the requests.
+type prober struct {
+	slots chan struct{}
+
+	lock   sync.Mutex
+	probes map[string]*probe
+	pruned time.Time
+}
+
+// probe is a health check request, in flight until done is closed.
+type probe struct {
+	done      chan struct{}
+	transport http.RoundTripper
+	err       error
+	ended     time.Time
+	// expires is the end of the sharing of the result, half of the interval of the backend which sent the probe.
+	expires  time.Time
+	canceled bool
+}
+
+func newProber(max int) *prober {
+	if max <= 0 {
+		max = defaultMaxConcurrentProbes
+	}
+
+	return &prober{
+		slots:  make(chan struct{}, max),
+		probes: make(map[string]*probe),
+		pruned: time.Now(),
+	}
+}
+
+// check returns the health of the server, sharing the probe of the same URL with the same options
+// which is in flight, or which ended less than half of the interval of the backend ago.
+// It returns the error of the context when the context ends before the server is probed.
+func (p *prober) check(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	if p == nil {
+		return checkHealth(serverURL, backend)
+	}
+
+	key, ok := probeKey(serverURL, backend)
+	if !ok {
+		return p.send(ctx, serverURL, backend)
+	}
+
+	for {
+		now := time.Now()
+
+		p.lock.Lock()
+		p.prune(now)
+
+		pr, found := p.probes[key]
+		if found && !pr.shareable(backend, now) {
+			found = false
+		}
+
+		if !found {
+			pr = &probe{done: make(chan struct{}), transport: backend.Transport}
+			p.probes[key] = pr
+			p.lock.Unlock()
+
+			err := p.send(ctx, serverURL, backend)
+
+			p.lock.Lock()
+			if ctx.Err() != nil && err == ctx.Err() {
+				// The probe was not sent: the backends waiting for it send their own.
+				pr.canceled = true
+				delete(p.probes, key)
+			} else {
+				pr.err = err
+				pr.ended = time.Now()
+				pr.expires = pr.ended.Add(backend.Interval / 2)
+			}
+			p.lock.Unlock()
+			close(pr.done)
+
+			return err
+		}
+		p.lock.Unlock()
+
+		select {
+		case <-pr.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		p.lock.Lock()
+		canceled, err := pr.canceled, pr.err
+		p.lock.Unlock()
+
+		if !canceled {
+			return err
+		}
+	}
+}
+
+// shareable returns whether the probe is in flight, or ended less than half of the interval of the backend ago,
+// through the same transport. It must be called with the lock of the prober held.
+func (pr *probe) shareable(backend *BackendConfig, now time.Time) bool {
+	if pr.transport != backend.Transport {
+		return false
+	}
+
+	select {
+	case <-pr.done:
+		return now.Before(pr.expires) && now.Sub(pr.ended) < backend.Interval/2
+	default:
+		return true
+	}
+}
+
+// send sends the health check request once a slot is free.
+func (p *prober) send(ctx context.Context, serverURL *url.URL, backend *BackendConfig) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	return checkHealth(serverURL, backend)
+}
+
+// prune removes the probes which ended and expired. It must be called with the lock held.
+func (p *prober) prune(now time.Time) {
+	if now.Sub(p.pruned) < pruneInterval {
+		return
+	}
+	p.pruned = now
+
+	for key, pr := range p.probes {
+		select {
+		case <-pr.done:
+			if !now.Before(pr.expires) {
+				delete(p.probes, key)
+			}
+		default:
+		}
+	}
+}
+
+// probeKey returns the key identifying the health check request of the server, with its options,
+// or false if the request cannot be shared, as its transport is not comparable.
+func probeKey(serverURL *url.URL, backend *BackendConfig) (string, bool) {
+	if backend.Transport != nil && reflect.ValueOf(backend.Transport).Kind() != reflect.Ptr {
+		return "", false
+	}
+
+	req, err := backend.newRequest(serverURL)
+	if err != nil {
+		return "", false
+	}
+
+	headers := make([]string, 0, len(backend.Headers))
+	for name, value := range backend.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(name)+": "+value)
+	}
+	sort.Strings(headers)
+
+	return fmt.Sprintf("%s\n%s\n%s\n%s", req.URL, backend.Hostname, backend.Timeout, strings.Join(headers, "\n")), true
+}
@@ -0,0 +1,171 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProberSharesProbes(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&requests, 1)
+	}))
+	defer ts.Close()
+
+	serverURL := testhelpers.MustParseURL(ts.URL)
+	transport := &http.Transport{}
+
+	newBackend := func(name string, headers map[string]string, roundTripper http.RoundTripper) *BackendConfig {
+		return NewBackendConfig(Options{
+			Path:      "/health",
+			Headers:   headers,
+			Transport: roundTripper,
+			Interval:  time.Hour,
+			Timeout:   time.Second,
+		}, name)
+	}
+
+	testCases := []struct {
+		desc     string
+		backend  *BackendConfig
+		expected int64
+	}{
+		{
+			desc:     "first probe",
+			backend:  newBackend("foo", map[string]string{"X-Foo": "foo"}, transport),
+			expected: 1,
+		},
+		{
+			desc:     "same options",
+			backend:  newBackend("bar", map[string]string{"x-foo": "foo"}, transport),
+			expected: 1,
+		},
+		{
+			desc:     "other headers",
+			backend:  newBackend("baz", map[string]string{"X-Foo": "bar"}, transport),
+			expected: 2,
+		},
+		{
+			desc:     "other transport",
+			backend:  newBackend("qux", map[string]string{"X-Foo": "foo"}, &http.Transport{}),
+			expected: 3,
+		},
+		{
+			desc:     "shorter interval",
+			backend:  &BackendConfig{Options: Options{Path: "/health", Headers: map[string]string{"X-Foo": "foo"}, Transport: transport, Interval: time.Nanosecond, Timeout: time.Second}},
+			expected: 4,
+		},
+	}
+
+	p := newProber(10)
+	for _, test := range testCases {
+		require.NoError(t, p.check(context.Background(), serverURL, test.backend), test.desc)
+		assert.Equal(t, test.expected, atomic.LoadInt64(&requests), test.desc)
+	}
+}
+
+func TestProberSharesProbesInFlight(t *testing.T) {
+	var requests int64
+	received := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt64(&requests, 1) == 1 {
+			close(received)
+		}
+		<-release
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	serverURL := testhelpers.MustParseURL(ts.URL)
+	backend := NewBackendConfig(Options{Path: "/health", Interval: time.Hour, Timeout: time.Second}, "foo")
+
+	p := newProber(10)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = p.check(context.Background(), serverURL, backend)
+		}(i)
+
+		if i == 0 {
+			<-received
+		}
+	}
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+	for _, err := range errs {
+		assert.EqualError(t, err, "received error status code: 503")
+	}
+}
+
+func TestProberMaxConcurrentProbes(t *testing.T) {
+	var inFlight, maxInFlight int64
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		current := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+
+		for {
+			max := atomic.LoadInt64(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt64(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	p := newProber(2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			serverURL := testhelpers.MustParseURL(ts.URL)
+			serverURL.Path = "/" + string(rune('a'+i))
+			backend := NewBackendConfig(Options{Interval: time.Hour, Timeout: time.Second}, "foo")
+			assert.NoError(t, p.check(context.Background(), serverURL, backend))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(2), atomic.LoadInt64(&maxInFlight))
+}
+
+func TestProberCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	defer ts.Close()
+
+	serverURL := testhelpers.MustParseURL(ts.URL)
+	backend := NewBackendConfig(Options{Interval: time.Hour, Timeout: time.Second}, "foo")
+
+	p := newProber(1)
+	// All the slots are taken.
+	p.slots <- struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := p.check(ctx, serverURL, backend)
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, p.probes)
+
+	<-p.slots
+	assert.NoError(t, p.check(context.Background(), serverURL, backend))
+}
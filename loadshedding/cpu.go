@@ -0,0 +1,18 @@
+// +build !windows
+
+package loadshedding
+
+import (
+	"syscall"
+	"time"
+)
+
+// cpuTime returns the CPU time used by the process.
+func cpuTime() (time.Duration, error) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, err
+	}
+
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), nil
+}
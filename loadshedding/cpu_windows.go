@@ -0,0 +1,13 @@
+// +build windows
+
+package loadshedding
+
+import (
+	"errors"
+	"time"
+)
+
+// cpuTime returns the CPU time used by the process.
+func cpuTime() (time.Duration, error) {
+	return 0, errors.New("CPU usage is not supported on Windows")
+}
@@ -0,0 +1,265 @@
+package loadshedding
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/log"
+)
+
+const (
+	defaultInterval       = time.Second
+	defaultMaxRejectRatio = 0.9
+
+	// increaseStep and decreaseStep are the changes of the reject ratio at each evaluation,
+	// depending on whether Traefik is overloaded or not: the shedding starts fast, and stops slowly.
+	increaseStep = 0.1
+	decreaseStep = 0.05
+
+	// maxSamples is the maximum number of request durations kept between two evaluations.
+	maxSamples = 10000
+)
+
+// Config holds the load shedding configuration.
+// The thresholds which are not set are not checked.
+type Config struct {
+	MaxLatency     parse.Duration `description:"99th percentile of the request durations above which Traefik is overloaded" export:"true"`
+	MaxGoroutines  int            `description:"Number of goroutines above which Traefik is overloaded" export:"true"`
+	MaxCPU         float64        `description:"Ratio (between 0 and 1) of the CPUs used by Traefik above which it is overloaded" export:"true"`
+	Interval       parse.Duration `description:"Interval between two evaluations of the load (default 1s)" export:"true"`
+	MaxRejectRatio float64        `description:"Maximum ratio (between 0 and 1) of the low priority requests rejected (default 0.9)" export:"true"`
+}
+
+// Shedder rejects a part of the requests of the low priority routers while Traefik is overloaded.
+// The ratio of the rejected requests grows at each evaluation finding Traefik overloaded, and decreases otherwise.
+// A nil Shedder never rejects requests.
+type Shedder struct {
+	maxLatency     time.Duration
+	maxGoroutines  int
+	maxCPU         float64
+	interval       time.Duration
+	maxRejectRatio float64
+
+	lock        sync.Mutex
+	samples     []time.Duration
+	seen        int
+	rejectRatio float64
+	lastCPU     time.Duration
+	lastCheck   time.Time
+
+	random     func() float64
+	cpuTime    func() (time.Duration, error)
+	numCPU     int
+	goroutines func() int
+}
+
+// New creates a new Shedder, nil if the configuration is nil.
+func New(config *Config) (*Shedder, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	if config.MaxLatency <= 0 && config.MaxGoroutines <= 0 && config.MaxCPU <= 0 {
+		return nil, errors.New("a latency, goroutines or CPU threshold is required")
+	}
+	if config.MaxCPU < 0 || config.MaxCPU > 1 {
+		return nil, errors.New("the CPU threshold must be between 0 and 1")
+	}
+	if config.MaxRejectRatio < 0 || config.MaxRejectRatio > 1 {
+		return nil, errors.New("the maximum reject ratio must be between 0 and 1")
+	}
+
+	s := &Shedder{
+		maxLatency:     time.Duration(config.MaxLatency),
+		maxGoroutines:  config.MaxGoroutines,
+		maxCPU:         config.MaxCPU,
+		interval:       time.Duration(config.Interval),
+		maxRejectRatio: config.MaxRejectRatio,
+		random:         rand.Float64,
+		cpuTime:        cpuTime,
+		numCPU:         runtime.NumCPU(),
+		goroutines:     runtime.NumGoroutine,
+	}
+
+	if s.interval <= 0 {
+		s.interval = defaultInterval
+	}
+	if s.maxRejectRatio == 0 {
+		s.maxRejectRatio = defaultMaxRejectRatio
+	}
+
+	return s, nil
+}
+
+// Run evaluates the load periodically, until stop is closed.
+func (s *Shedder) Run(stop chan bool) {
+	if s == nil {
+		return
+	}
+
+	s.lastCPU, _ = s.cpuTime()
+	s.lastCheck = time.Now()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.evaluate(time.Now())
+		}
+	}
+}
+
+// Observe returns a handler recording the durations of the requests, to compute the latency of Traefik.
+func (s *Shedder) Observe(next http.Handler) http.Handler {
+	if s == nil || s.maxLatency <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(rw, req)
+		s.record(time.Since(start))
+	})
+}
+
+// Wrap returns a handler rejecting a part of the requests of a low priority router with a 503,
+// while Traefik is overloaded.
+func (s *Shedder) Wrap(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if ratio := s.RejectRatio(); ratio > 0 && s.random() < ratio {
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// RejectRatio returns the ratio of the low priority requests currently rejected.
+func (s *Shedder) RejectRatio() float64 {
+	if s == nil {
+		return 0
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.rejectRatio
+}
+
+// record keeps a uniform sample of the request durations since the last evaluation.
+func (s *Shedder) record(duration time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.seen++
+	if len(s.samples) < maxSamples {
+		s.samples = append(s.samples, duration)
+		return
+	}
+
+	if i := int(s.random() * float64(s.seen)); i < maxSamples {
+		s.samples[i] = duration
+	}
+}
+
+// evaluate updates the reject ratio according to the load measured since the last evaluation.
+func (s *Shedder) evaluate(now time.Time) {
+	reasons := s.overloadReasons(now)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.samples = s.samples[:0]
+	s.seen = 0
+
+	logger := log.WithoutContext()
+	previous := s.rejectRatio
+
+	if len(reasons) > 0 {
+		s.rejectRatio += increaseStep
+		if s.rejectRatio > s.maxRejectRatio {
+			s.rejectRatio = s.maxRejectRatio
+		}
+		if previous == 0 {
+			logger.Warnf("Traefik is overloaded (%v), rejecting low priority requests", reasons)
+		}
+		return
+	}
+
+	s.rejectRatio -= decreaseStep
+	if s.rejectRatio <= 0 {
+		s.rejectRatio = 0
+		if previous > 0 {
+			logger.Info("Traefik is no longer overloaded, accepting all the low priority requests")
+		}
+	}
+}
+
+// overloadReasons returns the thresholds exceeded since the last evaluation.
+func (s *Shedder) overloadReasons(now time.Time) []string {
+	var reasons []string
+
+	if s.maxLatency > 0 {
+		if latency := s.latency(); latency > s.maxLatency {
+			reasons = append(reasons, "p99 latency "+latency.String())
+		}
+	}
+
+	if s.maxGoroutines > 0 {
+		if goroutines := s.goroutines(); goroutines > s.maxGoroutines {
+			reasons = append(reasons, "goroutines "+strconv.Itoa(goroutines))
+		}
+	}
+
+	if s.maxCPU > 0 {
+		cpu, err := s.cpuTime()
+		if err != nil {
+			log.WithoutContext().Debugf("Unable to get the CPU usage: %v", err)
+		} else {
+			elapsed := now.Sub(s.lastCheck)
+			if elapsed > 0 {
+				usage := float64(cpu-s.lastCPU) / float64(elapsed) / float64(s.numCPU)
+				if usage > s.maxCPU {
+					reasons = append(reasons, "CPU "+strconv.FormatFloat(usage, 'f', 2, 64))
+				}
+			}
+			s.lastCPU = cpu
+		}
+	}
+	s.lastCheck = now
+
+	return reasons
+}
+
+// latency returns the 99th percentile of the request durations since the last evaluation.
+func (s *Shedder) latency() time.Duration {
+	s.lock.Lock()
+	samples := make([]time.Duration, len(s.samples))
+	copy(samples, s.samples)
+	s.lock.Unlock()
+
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+
+	return samples[(len(samples)-1)*99/100]
+}
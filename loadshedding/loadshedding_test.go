@@ -0,0 +1,152 @@
+package loadshedding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      *Config
+		expectedErr bool
+	}{
+		{
+			desc: "no configuration",
+		},
+		{
+			desc:   "latency threshold",
+			config: &Config{MaxLatency: parse.Duration(time.Second)},
+		},
+		{
+			desc:        "no threshold",
+			config:      &Config{},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid CPU threshold",
+			config:      &Config{MaxCPU: 2},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid maximum reject ratio",
+			config:      &Config{MaxGoroutines: 10, MaxRejectRatio: -1},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(test.config)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	s, err := New(&Config{MaxLatency: parse.Duration(100 * time.Millisecond), MaxGoroutines: 100, MaxCPU: 0.5})
+	require.NoError(t, err)
+
+	goroutines := 10
+	s.goroutines = func() int { return goroutines }
+	var cpu time.Duration
+	s.cpuTime = func() (time.Duration, error) { return cpu, nil }
+	s.numCPU = 2
+
+	now := time.Now()
+	s.lastCheck = now
+
+	evaluate := func() float64 {
+		now = now.Add(time.Second)
+		s.evaluate(now)
+		return s.RejectRatio()
+	}
+
+	assert.Equal(t, float64(0), evaluate())
+
+	// The p99 latency exceeds the threshold.
+	for i := 0; i < 98; i++ {
+		s.record(time.Millisecond)
+	}
+	s.record(time.Second)
+	s.record(time.Second)
+	assert.InDelta(t, 0.1, evaluate(), 1e-9)
+
+	// Too many goroutines.
+	goroutines = 1000
+	assert.InDelta(t, 0.2, evaluate(), 1e-9)
+	goroutines = 10
+
+	// More than half of the 2 CPUs are used.
+	cpu += 1500 * time.Millisecond
+	assert.InDelta(t, 0.3, evaluate(), 1e-9)
+
+	// The ratio decreases slowly.
+	assert.InDelta(t, 0.25, evaluate(), 1e-9)
+
+	for i := 0; i < 10; i++ {
+		evaluate()
+	}
+	assert.Equal(t, float64(0), s.RejectRatio())
+}
+
+func TestMaxRejectRatio(t *testing.T) {
+	s, err := New(&Config{MaxGoroutines: 1, MaxRejectRatio: 0.25})
+	require.NoError(t, err)
+	s.goroutines = func() int { return 2 }
+
+	for i := 0; i < 5; i++ {
+		s.evaluate(time.Now())
+	}
+	assert.Equal(t, 0.25, s.RejectRatio())
+}
+
+func TestWrap(t *testing.T) {
+	s, err := New(&Config{MaxGoroutines: 1})
+	require.NoError(t, err)
+	s.rejectRatio = 0.5
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+	handler := s.Wrap(next)
+
+	testCases := []struct {
+		random         float64
+		expectedStatus int
+	}{
+		{random: 0.2, expectedStatus: http.StatusServiceUnavailable},
+		{random: 0.7, expectedStatus: http.StatusOK},
+	}
+
+	for _, test := range testCases {
+		random := test.random
+		s.random = func() float64 { return random }
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, test.expectedStatus, recorder.Code)
+	}
+}
+
+func TestNilShedder(t *testing.T) {
+	var s *Shedder
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+	recorder := httptest.NewRecorder()
+	s.Observe(s.Wrap(next)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, float64(0), s.RejectRatio())
+}
@@ -0,0 +1,148 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/multi"
+)
+
+// DefaultMaxEntries is the default maximum number of entries of each cache.
+const DefaultMaxEntries = 65536
+
+// Config holds the budget of the in-process caches keyed by the requests.
+type Config struct {
+	MaxEntries int `description:"Maximum number of entries of each cache keyed by the requests (default 65536)" export:"true"`
+}
+
+var maxEntries int64 = DefaultMaxEntries
+
+var (
+	evictionsLock sync.RWMutex
+	evictions     metrics.Counter = multi.NewCounter()
+)
+
+// SetDefault sets the maximum number of entries of all the caches, including the existing ones,
+// and the counter of their evictions, labeled by the name of the cache.
+func SetDefault(config *Config, counter metrics.Counter) {
+	max := int64(DefaultMaxEntries)
+	if config != nil && config.MaxEntries > 0 {
+		max = int64(config.MaxEntries)
+	}
+	atomic.StoreInt64(&maxEntries, max)
+
+	if counter == nil {
+		counter = multi.NewCounter()
+	}
+
+	evictionsLock.Lock()
+	defer evictionsLock.Unlock()
+	evictions = counter
+}
+
+// MaxEntries returns the maximum number of entries of each cache.
+func MaxEntries() int {
+	return int(atomic.LoadInt64(&maxEntries))
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// Cache holds at most MaxEntries entries, and evicts the least recently used one when it is full,
+// so that the keys chosen by the clients cannot make it grow without bounds.
+// An entry also expires after its ttl, if any.
+type Cache struct {
+	name string
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+// New creates a Cache, whose evictions are counted under its name.
+func New(name string) *Cache {
+	return &Cache{
+		name:    name,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the value of the key, if it has not expired, and marks it as the most recently used.
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elt, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := elt.Value.(*entry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.remove(elt)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elt)
+	return e.value, true
+}
+
+// Set sets the value of the key, which expires after the ttl, or never if the ttl is zero.
+func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elt, ok := c.entries[key]; ok {
+		e := elt.Value.(*entry)
+		e.value = value
+		e.expires = expires
+		c.order.MoveToFront(elt)
+		return
+	}
+
+	var evicted int
+	for max := MaxEntries(); c.order.Len() >= max; evicted++ {
+		c.remove(c.order.Back())
+	}
+	if evicted > 0 {
+		evictionsLock.RLock()
+		evictions.With("cache", c.name).Add(float64(evicted))
+		evictionsLock.RUnlock()
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+}
+
+// Len returns the number of entries, including the expired ones not evicted yet.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Purge removes all the entries.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *Cache) remove(elt *list.Element) {
+	c.order.Remove(elt)
+	delete(c.entries, elt.Value.(*entry).key)
+}
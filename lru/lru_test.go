@@ -0,0 +1,86 @@
+package lru_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/lru"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	counter := &testhelpers.CollectingCounter{}
+	lru.SetDefault(&lru.Config{MaxEntries: 3}, counter)
+	defer lru.SetDefault(nil, nil)
+
+	cache := lru.New("test")
+	for i := 0; i < 3; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i, 0)
+	}
+
+	// key0 becomes the most recently used entry, key1 is evicted first.
+	value, ok := cache.Get("key0")
+	assert.True(t, ok)
+	assert.Equal(t, 0, value)
+
+	cache.Set("key3", 3, 0)
+	assert.Equal(t, 3, cache.Len())
+	assert.Equal(t, float64(1), counter.CounterValue)
+	assert.Equal(t, []string{"cache", "test"}, counter.LastLabelValues)
+
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+
+	for _, key := range []string{"key0", "key2", "key3"} {
+		_, ok = cache.Get(key)
+		assert.True(t, ok, key)
+	}
+
+	// Updating an entry does not evict another one.
+	cache.Set("key2", 20, 0)
+	value, ok = cache.Get("key2")
+	assert.True(t, ok)
+	assert.Equal(t, 20, value)
+	assert.Equal(t, float64(1), counter.CounterValue)
+
+	// A lower budget applies to the existing caches.
+	lru.SetDefault(&lru.Config{MaxEntries: 1}, counter)
+	cache.Set("key4", 4, 0)
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, float64(4), counter.CounterValue)
+
+	cache.Purge()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCacheTTL(t *testing.T) {
+	cache := lru.New("test")
+	cache.Set("expired", "value", time.Nanosecond)
+	cache.Set("valid", "value", time.Hour)
+	cache.Set("forever", "value", 0)
+
+	time.Sleep(time.Millisecond)
+
+	_, ok := cache.Get("expired")
+	assert.False(t, ok)
+	_, ok = cache.Get("valid")
+	assert.True(t, ok)
+	_, ok = cache.Get("forever")
+	assert.True(t, ok)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestSetDefault(t *testing.T) {
+	defer lru.SetDefault(nil, nil)
+
+	lru.SetDefault(&lru.Config{MaxEntries: 10}, nil)
+	assert.Equal(t, 10, lru.MaxEntries())
+
+	lru.SetDefault(&lru.Config{}, nil)
+	assert.Equal(t, lru.DefaultMaxEntries, lru.MaxEntries())
+
+	lru.SetDefault(nil, nil)
+	assert.Equal(t, lru.DefaultMaxEntries, lru.MaxEntries())
+}
@@ -27,11 +27,28 @@ const (
 	ddConfigReloadsFailureTagName = "failure"
 	ddLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
 	ddLastConfigReloadFailureName = "config.reload.lastFailureTimestamp"
+	ddConfigReloadDurationName    = "config.reload.duration"
+	ddReadyName                   = "ready"
+	ddTLSCertsNotAfterName        = "tls.certs.notAfterTimestamp"
+	ddExperimentExposuresName     = "experiment.exposures.total"
+	ddGraphQLOperationsName       = "graphql.operations.total"
+	ddRouterSLOBurnRateName       = "router.slo.burn_rate"
+	ddAccessLogDroppedName        = "accesslog.dropped.total"
+	ddCacheEvictionsName          = "cache.evictions.total"
+	ddGoroutinesName              = "goroutines"
+	ddFileDescriptorsName         = "open.fds"
 	ddEntrypointReqsName          = "entrypoint.request.total"
 	ddEntrypointReqDurationName   = "entrypoint.request.duration"
 	ddEntrypointOpenConnsName     = "entrypoint.connections.open"
+	ddEntrypointSaturationName    = "entrypoint.saturation"
+	ddEntrypointLimitRejectsName  = "entrypoint.limit.rejects.total"
+	ddEntrypointShardConnsName    = "entrypoint.shard.connections.total"
 	ddOpenConnsName               = "backend.connections.open"
 	ddServerUpName                = "backend.server.up"
+	ddInvocationDurationName      = "backend.invocation.duration"
+	ddTLSVerificationFailuresName = "backend.tls.verification.failures.total"
+	ddDrainedRequestsName         = "backend.drained.requests.total"
+	ddDialFallbacksName           = "backend.dial.fallbacks.total"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -41,19 +58,36 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 	}
 
 	registry := &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           datadogClient.NewCounter(ddConfigReloadsName, 1.0),
-		configReloadsFailureCounter:    datadogClient.NewCounter(ddConfigReloadsName, 1.0).With(ddConfigReloadsFailureTagName, "true"),
-		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   datadogClient.NewGauge(ddLastConfigReloadFailureName),
-		entrypointReqsCounter:          datadogClient.NewCounter(ddEntrypointReqsName, 1.0),
-		entrypointReqDurationHistogram: datadogClient.NewHistogram(ddEntrypointReqDurationName, 1.0),
-		entrypointOpenConnsGauge:       datadogClient.NewGauge(ddEntrypointOpenConnsName),
-		backendReqsCounter:             datadogClient.NewCounter(ddMetricsBackendReqsName, 1.0),
-		backendReqDurationHistogram:    datadogClient.NewHistogram(ddMetricsBackendLatencyName, 1.0),
-		backendRetriesCounter:          datadogClient.NewCounter(ddRetriesTotalName, 1.0),
-		backendOpenConnsGauge:          datadogClient.NewGauge(ddOpenConnsName),
-		backendServerUpGauge:           datadogClient.NewGauge(ddServerUpName),
+		enabled:                               true,
+		configReloadsCounter:                  datadogClient.NewCounter(ddConfigReloadsName, 1.0),
+		configReloadsFailureCounter:           datadogClient.NewCounter(ddConfigReloadsName, 1.0).With(ddConfigReloadsFailureTagName, "true"),
+		lastConfigReloadSuccessGauge:          datadogClient.NewGauge(ddLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:          datadogClient.NewGauge(ddLastConfigReloadFailureName),
+		configReloadDurationHistogram:         datadogClient.NewHistogram(ddConfigReloadDurationName, 1.0),
+		readyGauge:                            datadogClient.NewGauge(ddReadyName),
+		tlsCertsNotAfterGauge:                 datadogClient.NewGauge(ddTLSCertsNotAfterName),
+		experimentExposuresCounter:            datadogClient.NewCounter(ddExperimentExposuresName, 1.0),
+		graphQLOperationsCounter:              datadogClient.NewCounter(ddGraphQLOperationsName, 1.0),
+		routerSLOBurnRateGauge:                datadogClient.NewGauge(ddRouterSLOBurnRateName),
+		accessLogDroppedCounter:               datadogClient.NewCounter(ddAccessLogDroppedName, 1.0),
+		cacheEvictionsCounter:                 datadogClient.NewCounter(ddCacheEvictionsName, 1.0),
+		goroutinesGauge:                       datadogClient.NewGauge(ddGoroutinesName),
+		fileDescriptorsGauge:                  datadogClient.NewGauge(ddFileDescriptorsName),
+		entrypointReqsCounter:                 datadogClient.NewCounter(ddEntrypointReqsName, 1.0),
+		entrypointReqDurationHistogram:        datadogClient.NewHistogram(ddEntrypointReqDurationName, 1.0),
+		entrypointOpenConnsGauge:              datadogClient.NewGauge(ddEntrypointOpenConnsName),
+		entrypointSaturationGauge:             datadogClient.NewGauge(ddEntrypointSaturationName),
+		entrypointLimitRejectsCounter:         datadogClient.NewCounter(ddEntrypointLimitRejectsName, 1.0),
+		entrypointShardConnsCounter:           datadogClient.NewCounter(ddEntrypointShardConnsName, 1.0),
+		backendReqsCounter:                    datadogClient.NewCounter(ddMetricsBackendReqsName, 1.0),
+		backendReqDurationHistogram:           datadogClient.NewHistogram(ddMetricsBackendLatencyName, 1.0),
+		backendRetriesCounter:                 datadogClient.NewCounter(ddRetriesTotalName, 1.0),
+		backendOpenConnsGauge:                 datadogClient.NewGauge(ddOpenConnsName),
+		backendServerUpGauge:                  datadogClient.NewGauge(ddServerUpName),
+		backendInvocationDurationHistogram:    datadogClient.NewHistogram(ddInvocationDurationName, 1.0),
+		backendTLSVerificationFailuresCounter: datadogClient.NewCounter(ddTLSVerificationFailuresName, 1.0),
+		backendDrainedRequestsCounter:         datadogClient.NewCounter(ddDrainedRequestsName, 1.0),
+		backendDialFallbacksCounter:           datadogClient.NewCounter(ddDialFallbacksName, 1.0),
 	}
 
 	return registry
@@ -33,11 +33,28 @@ const (
 	influxDBConfigReloadsFailureName    = influxDBConfigReloadsName + ".failure"
 	influxDBLastConfigReloadSuccessName = "traefik.config.reload.lastSuccessTimestamp"
 	influxDBLastConfigReloadFailureName = "traefik.config.reload.lastFailureTimestamp"
+	influxDBConfigReloadDurationName    = "traefik.config.reload.duration"
+	influxDBReadyName                   = "traefik.ready"
+	influxDBTLSCertsNotAfterName        = "traefik.tls.certs.notAfterTimestamp"
+	influxDBExperimentExposuresName     = "traefik.experiment.exposures.total"
+	influxDBGraphQLOperationsName       = "traefik.graphql.operations.total"
+	influxDBRouterSLOBurnRateName       = "traefik.router.slo.burn_rate"
+	influxDBAccessLogDroppedName        = "traefik.accesslog.dropped.total"
+	influxDBCacheEvictionsName          = "traefik.cache.evictions.total"
+	influxDBGoroutinesName              = "traefik.goroutines"
+	influxDBFileDescriptorsName         = "traefik.open.fds"
 	influxDBEntrypointReqsName          = "traefik.entrypoint.requests.total"
 	influxDBEntrypointReqDurationName   = "traefik.entrypoint.request.duration"
 	influxDBEntrypointOpenConnsName     = "traefik.entrypoint.connections.open"
+	influxDBEntrypointSaturationName    = "traefik.entrypoint.saturation"
+	influxDBEntrypointLimitRejectsName  = "traefik.entrypoint.limit.rejects.total"
+	influxDBEntrypointShardConnsName    = "traefik.entrypoint.shard.connections.total"
 	influxDBOpenConnsName               = "traefik.backend.connections.open"
 	influxDBServerUpName                = "traefik.backend.server.up"
+	influxDBInvocationDurationName      = "traefik.backend.invocation.duration"
+	influxDBTLSVerificationFailuresName = "traefik.backend.tls.verification.failures.total"
+	influxDBDrainedRequestsName         = "traefik.backend.drained.requests.total"
+	influxDBDialFallbacksName           = "traefik.backend.dial.fallbacks.total"
 )
 
 const (
@@ -55,19 +72,36 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 	}
 
 	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           influxDBClient.NewCounter(influxDBConfigReloadsName),
-		configReloadsFailureCounter:    influxDBClient.NewCounter(influxDBConfigReloadsFailureName),
-		lastConfigReloadSuccessGauge:   influxDBClient.NewGauge(influxDBLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   influxDBClient.NewGauge(influxDBLastConfigReloadFailureName),
-		entrypointReqsCounter:          influxDBClient.NewCounter(influxDBEntrypointReqsName),
-		entrypointReqDurationHistogram: influxDBClient.NewHistogram(influxDBEntrypointReqDurationName),
-		entrypointOpenConnsGauge:       influxDBClient.NewGauge(influxDBEntrypointOpenConnsName),
-		backendReqsCounter:             influxDBClient.NewCounter(influxDBMetricsBackendReqsName),
-		backendReqDurationHistogram:    influxDBClient.NewHistogram(influxDBMetricsBackendLatencyName),
-		backendRetriesCounter:          influxDBClient.NewCounter(influxDBRetriesTotalName),
-		backendOpenConnsGauge:          influxDBClient.NewGauge(influxDBOpenConnsName),
-		backendServerUpGauge:           influxDBClient.NewGauge(influxDBServerUpName),
+		enabled:                               true,
+		configReloadsCounter:                  influxDBClient.NewCounter(influxDBConfigReloadsName),
+		configReloadsFailureCounter:           influxDBClient.NewCounter(influxDBConfigReloadsFailureName),
+		lastConfigReloadSuccessGauge:          influxDBClient.NewGauge(influxDBLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:          influxDBClient.NewGauge(influxDBLastConfigReloadFailureName),
+		configReloadDurationHistogram:         influxDBClient.NewHistogram(influxDBConfigReloadDurationName),
+		readyGauge:                            influxDBClient.NewGauge(influxDBReadyName),
+		tlsCertsNotAfterGauge:                 influxDBClient.NewGauge(influxDBTLSCertsNotAfterName),
+		experimentExposuresCounter:            influxDBClient.NewCounter(influxDBExperimentExposuresName),
+		graphQLOperationsCounter:              influxDBClient.NewCounter(influxDBGraphQLOperationsName),
+		routerSLOBurnRateGauge:                influxDBClient.NewGauge(influxDBRouterSLOBurnRateName),
+		accessLogDroppedCounter:               influxDBClient.NewCounter(influxDBAccessLogDroppedName),
+		cacheEvictionsCounter:                 influxDBClient.NewCounter(influxDBCacheEvictionsName),
+		goroutinesGauge:                       influxDBClient.NewGauge(influxDBGoroutinesName),
+		fileDescriptorsGauge:                  influxDBClient.NewGauge(influxDBFileDescriptorsName),
+		entrypointReqsCounter:                 influxDBClient.NewCounter(influxDBEntrypointReqsName),
+		entrypointReqDurationHistogram:        influxDBClient.NewHistogram(influxDBEntrypointReqDurationName),
+		entrypointOpenConnsGauge:              influxDBClient.NewGauge(influxDBEntrypointOpenConnsName),
+		entrypointSaturationGauge:             influxDBClient.NewGauge(influxDBEntrypointSaturationName),
+		entrypointLimitRejectsCounter:         influxDBClient.NewCounter(influxDBEntrypointLimitRejectsName),
+		entrypointShardConnsCounter:           influxDBClient.NewCounter(influxDBEntrypointShardConnsName),
+		backendReqsCounter:                    influxDBClient.NewCounter(influxDBMetricsBackendReqsName),
+		backendReqDurationHistogram:           influxDBClient.NewHistogram(influxDBMetricsBackendLatencyName),
+		backendRetriesCounter:                 influxDBClient.NewCounter(influxDBRetriesTotalName),
+		backendOpenConnsGauge:                 influxDBClient.NewGauge(influxDBOpenConnsName),
+		backendServerUpGauge:                  influxDBClient.NewGauge(influxDBServerUpName),
+		backendInvocationDurationHistogram:    influxDBClient.NewHistogram(influxDBInvocationDurationName),
+		backendTLSVerificationFailuresCounter: influxDBClient.NewCounter(influxDBTLSVerificationFailuresName),
+		backendDrainedRequestsCounter:         influxDBClient.NewCounter(influxDBDrainedRequestsName),
+		backendDialFallbacksCounter:           influxDBClient.NewCounter(influxDBDialFallbacksName),
 	}
 }
 
@@ -15,11 +15,40 @@ type Registry interface {
 	ConfigReloadsFailureCounter() metrics.Counter
 	LastConfigReloadSuccessGauge() metrics.Gauge
 	LastConfigReloadFailureGauge() metrics.Gauge
+	ConfigReloadDurationHistogram() metrics.Histogram
+	ReadyGauge() metrics.Gauge
+
+	// TLS metrics
+	TLSCertsNotAfterGauge() metrics.Gauge
+
+	// experiment metrics
+	ExperimentExposuresCounter() metrics.Counter
+
+	// GraphQL metrics
+	GraphQLOperationsCounter() metrics.Counter
+
+	// router metrics
+	RouterSLOBurnRateGauge() metrics.Gauge
+
+	// access log metrics
+	AccessLogDroppedCounter() metrics.Counter
+
+	// cache metrics
+	CacheEvictionsCounter() metrics.Counter
+
+	// runtime metrics
+	GoroutinesGauge() metrics.Gauge
+	FileDescriptorsGauge() metrics.Gauge
 
 	// entry point metrics
 	EntrypointReqsCounter() metrics.Counter
 	EntrypointReqDurationHistogram() metrics.Histogram
 	EntrypointOpenConnsGauge() metrics.Gauge
+	EntrypointReqsBytesCounter() metrics.Counter
+	EntrypointRespsBytesCounter() metrics.Counter
+	EntrypointSaturationGauge() metrics.Gauge
+	EntrypointLimitRejectsCounter() metrics.Counter
+	EntrypointShardConnsCounter() metrics.Counter
 
 	// backend metrics
 	BackendReqsCounter() metrics.Counter
@@ -27,6 +56,12 @@ type Registry interface {
 	BackendOpenConnsGauge() metrics.Gauge
 	BackendRetriesCounter() metrics.Counter
 	BackendServerUpGauge() metrics.Gauge
+	BackendReqsBytesCounter() metrics.Counter
+	BackendRespsBytesCounter() metrics.Counter
+	BackendInvocationDurationHistogram() metrics.Histogram
+	BackendTLSVerificationFailuresCounter() metrics.Counter
+	BackendDrainedRequestsCounter() metrics.Counter
+	BackendDialFallbacksCounter() metrics.Counter
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -43,14 +78,35 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var configReloadsFailureCounter []metrics.Counter
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var lastConfigReloadFailureGauge []metrics.Gauge
+	var configReloadDurationHistogram []metrics.Histogram
+	var readyGauge []metrics.Gauge
+	var tlsCertsNotAfterGauge []metrics.Gauge
+	var experimentExposuresCounter []metrics.Counter
+	var graphQLOperationsCounter []metrics.Counter
+	var routerSLOBurnRateGauge []metrics.Gauge
+	var accessLogDroppedCounter []metrics.Counter
+	var cacheEvictionsCounter []metrics.Counter
+	var goroutinesGauge []metrics.Gauge
+	var fileDescriptorsGauge []metrics.Gauge
 	var entrypointReqsCounter []metrics.Counter
 	var entrypointReqDurationHistogram []metrics.Histogram
 	var entrypointOpenConnsGauge []metrics.Gauge
+	var entrypointReqsBytesCounter []metrics.Counter
+	var entrypointRespsBytesCounter []metrics.Counter
+	var entrypointSaturationGauge []metrics.Gauge
+	var entrypointLimitRejectsCounter []metrics.Counter
+	var entrypointShardConnsCounter []metrics.Counter
 	var backendReqsCounter []metrics.Counter
 	var backendReqDurationHistogram []metrics.Histogram
 	var backendOpenConnsGauge []metrics.Gauge
 	var backendRetriesCounter []metrics.Counter
 	var backendServerUpGauge []metrics.Gauge
+	var backendReqsBytesCounter []metrics.Counter
+	var backendRespsBytesCounter []metrics.Counter
+	var backendInvocationDurationHistogram []metrics.Histogram
+	var backendTLSVerificationFailuresCounter []metrics.Counter
+	var backendDrainedRequestsCounter []metrics.Counter
+	var backendDialFallbacksCounter []metrics.Counter
 
 	for _, r := range registries {
 		if r.ConfigReloadsCounter() != nil {
@@ -65,6 +121,36 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.LastConfigReloadFailureGauge() != nil {
 			lastConfigReloadFailureGauge = append(lastConfigReloadFailureGauge, r.LastConfigReloadFailureGauge())
 		}
+		if r.ConfigReloadDurationHistogram() != nil {
+			configReloadDurationHistogram = append(configReloadDurationHistogram, r.ConfigReloadDurationHistogram())
+		}
+		if r.ReadyGauge() != nil {
+			readyGauge = append(readyGauge, r.ReadyGauge())
+		}
+		if r.TLSCertsNotAfterGauge() != nil {
+			tlsCertsNotAfterGauge = append(tlsCertsNotAfterGauge, r.TLSCertsNotAfterGauge())
+		}
+		if r.ExperimentExposuresCounter() != nil {
+			experimentExposuresCounter = append(experimentExposuresCounter, r.ExperimentExposuresCounter())
+		}
+		if r.GraphQLOperationsCounter() != nil {
+			graphQLOperationsCounter = append(graphQLOperationsCounter, r.GraphQLOperationsCounter())
+		}
+		if r.RouterSLOBurnRateGauge() != nil {
+			routerSLOBurnRateGauge = append(routerSLOBurnRateGauge, r.RouterSLOBurnRateGauge())
+		}
+		if r.AccessLogDroppedCounter() != nil {
+			accessLogDroppedCounter = append(accessLogDroppedCounter, r.AccessLogDroppedCounter())
+		}
+		if r.CacheEvictionsCounter() != nil {
+			cacheEvictionsCounter = append(cacheEvictionsCounter, r.CacheEvictionsCounter())
+		}
+		if r.GoroutinesGauge() != nil {
+			goroutinesGauge = append(goroutinesGauge, r.GoroutinesGauge())
+		}
+		if r.FileDescriptorsGauge() != nil {
+			fileDescriptorsGauge = append(fileDescriptorsGauge, r.FileDescriptorsGauge())
+		}
 		if r.EntrypointReqsCounter() != nil {
 			entrypointReqsCounter = append(entrypointReqsCounter, r.EntrypointReqsCounter())
 		}
@@ -74,6 +160,21 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.EntrypointOpenConnsGauge() != nil {
 			entrypointOpenConnsGauge = append(entrypointOpenConnsGauge, r.EntrypointOpenConnsGauge())
 		}
+		if r.EntrypointReqsBytesCounter() != nil {
+			entrypointReqsBytesCounter = append(entrypointReqsBytesCounter, r.EntrypointReqsBytesCounter())
+		}
+		if r.EntrypointRespsBytesCounter() != nil {
+			entrypointRespsBytesCounter = append(entrypointRespsBytesCounter, r.EntrypointRespsBytesCounter())
+		}
+		if r.EntrypointSaturationGauge() != nil {
+			entrypointSaturationGauge = append(entrypointSaturationGauge, r.EntrypointSaturationGauge())
+		}
+		if r.EntrypointLimitRejectsCounter() != nil {
+			entrypointLimitRejectsCounter = append(entrypointLimitRejectsCounter, r.EntrypointLimitRejectsCounter())
+		}
+		if r.EntrypointShardConnsCounter() != nil {
+			entrypointShardConnsCounter = append(entrypointShardConnsCounter, r.EntrypointShardConnsCounter())
+		}
 		if r.BackendReqsCounter() != nil {
 			backendReqsCounter = append(backendReqsCounter, r.BackendReqsCounter())
 		}
@@ -89,39 +190,99 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.BackendServerUpGauge() != nil {
 			backendServerUpGauge = append(backendServerUpGauge, r.BackendServerUpGauge())
 		}
+		if r.BackendReqsBytesCounter() != nil {
+			backendReqsBytesCounter = append(backendReqsBytesCounter, r.BackendReqsBytesCounter())
+		}
+		if r.BackendRespsBytesCounter() != nil {
+			backendRespsBytesCounter = append(backendRespsBytesCounter, r.BackendRespsBytesCounter())
+		}
+		if r.BackendInvocationDurationHistogram() != nil {
+			backendInvocationDurationHistogram = append(backendInvocationDurationHistogram, r.BackendInvocationDurationHistogram())
+		}
+		if r.BackendTLSVerificationFailuresCounter() != nil {
+			backendTLSVerificationFailuresCounter = append(backendTLSVerificationFailuresCounter, r.BackendTLSVerificationFailuresCounter())
+		}
+		if r.BackendDrainedRequestsCounter() != nil {
+			backendDrainedRequestsCounter = append(backendDrainedRequestsCounter, r.BackendDrainedRequestsCounter())
+		}
+		if r.BackendDialFallbacksCounter() != nil {
+			backendDialFallbacksCounter = append(backendDialFallbacksCounter, r.BackendDialFallbacksCounter())
+		}
 	}
 
 	return &standardRegistry{
-		enabled:                        len(registries) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		configReloadsFailureCounter:    multi.NewCounter(configReloadsFailureCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		lastConfigReloadFailureGauge:   multi.NewGauge(lastConfigReloadFailureGauge...),
-		entrypointReqsCounter:          multi.NewCounter(entrypointReqsCounter...),
-		entrypointReqDurationHistogram: multi.NewHistogram(entrypointReqDurationHistogram...),
-		entrypointOpenConnsGauge:       multi.NewGauge(entrypointOpenConnsGauge...),
-		backendReqsCounter:             multi.NewCounter(backendReqsCounter...),
-		backendReqDurationHistogram:    multi.NewHistogram(backendReqDurationHistogram...),
-		backendOpenConnsGauge:          multi.NewGauge(backendOpenConnsGauge...),
-		backendRetriesCounter:          multi.NewCounter(backendRetriesCounter...),
-		backendServerUpGauge:           multi.NewGauge(backendServerUpGauge...),
+		enabled:                               len(registries) > 0,
+		configReloadsCounter:                  multi.NewCounter(configReloadsCounter...),
+		configReloadsFailureCounter:           multi.NewCounter(configReloadsFailureCounter...),
+		lastConfigReloadSuccessGauge:          multi.NewGauge(lastConfigReloadSuccessGauge...),
+		lastConfigReloadFailureGauge:          multi.NewGauge(lastConfigReloadFailureGauge...),
+		configReloadDurationHistogram:         multi.NewHistogram(configReloadDurationHistogram...),
+		readyGauge:                            multi.NewGauge(readyGauge...),
+		tlsCertsNotAfterGauge:                 multi.NewGauge(tlsCertsNotAfterGauge...),
+		experimentExposuresCounter:            multi.NewCounter(experimentExposuresCounter...),
+		graphQLOperationsCounter:              multi.NewCounter(graphQLOperationsCounter...),
+		routerSLOBurnRateGauge:                multi.NewGauge(routerSLOBurnRateGauge...),
+		accessLogDroppedCounter:               multi.NewCounter(accessLogDroppedCounter...),
+		cacheEvictionsCounter:                 multi.NewCounter(cacheEvictionsCounter...),
+		goroutinesGauge:                       multi.NewGauge(goroutinesGauge...),
+		fileDescriptorsGauge:                  multi.NewGauge(fileDescriptorsGauge...),
+		entrypointReqsCounter:                 multi.NewCounter(entrypointReqsCounter...),
+		entrypointReqDurationHistogram:        multi.NewHistogram(entrypointReqDurationHistogram...),
+		entrypointOpenConnsGauge:              multi.NewGauge(entrypointOpenConnsGauge...),
+		entrypointReqsBytesCounter:            multi.NewCounter(entrypointReqsBytesCounter...),
+		entrypointRespsBytesCounter:           multi.NewCounter(entrypointRespsBytesCounter...),
+		entrypointSaturationGauge:             multi.NewGauge(entrypointSaturationGauge...),
+		entrypointLimitRejectsCounter:         multi.NewCounter(entrypointLimitRejectsCounter...),
+		entrypointShardConnsCounter:           multi.NewCounter(entrypointShardConnsCounter...),
+		backendReqsCounter:                    multi.NewCounter(backendReqsCounter...),
+		backendReqDurationHistogram:           multi.NewHistogram(backendReqDurationHistogram...),
+		backendOpenConnsGauge:                 multi.NewGauge(backendOpenConnsGauge...),
+		backendRetriesCounter:                 multi.NewCounter(backendRetriesCounter...),
+		backendServerUpGauge:                  multi.NewGauge(backendServerUpGauge...),
+		backendReqsBytesCounter:               multi.NewCounter(backendReqsBytesCounter...),
+		backendRespsBytesCounter:              multi.NewCounter(backendRespsBytesCounter...),
+		backendInvocationDurationHistogram:    multi.NewHistogram(backendInvocationDurationHistogram...),
+		backendTLSVerificationFailuresCounter: multi.NewCounter(backendTLSVerificationFailuresCounter...),
+		backendDrainedRequestsCounter:         multi.NewCounter(backendDrainedRequestsCounter...),
+		backendDialFallbacksCounter:           multi.NewCounter(backendDialFallbacksCounter...),
 	}
 }
 
 type standardRegistry struct {
-	enabled                        bool
-	configReloadsCounter           metrics.Counter
-	configReloadsFailureCounter    metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	lastConfigReloadFailureGauge   metrics.Gauge
-	entrypointReqsCounter          metrics.Counter
-	entrypointReqDurationHistogram metrics.Histogram
-	entrypointOpenConnsGauge       metrics.Gauge
-	backendReqsCounter             metrics.Counter
-	backendReqDurationHistogram    metrics.Histogram
-	backendOpenConnsGauge          metrics.Gauge
-	backendRetriesCounter          metrics.Counter
-	backendServerUpGauge           metrics.Gauge
+	enabled                               bool
+	configReloadsCounter                  metrics.Counter
+	configReloadsFailureCounter           metrics.Counter
+	lastConfigReloadSuccessGauge          metrics.Gauge
+	lastConfigReloadFailureGauge          metrics.Gauge
+	configReloadDurationHistogram         metrics.Histogram
+	readyGauge                            metrics.Gauge
+	tlsCertsNotAfterGauge                 metrics.Gauge
+	experimentExposuresCounter            metrics.Counter
+	graphQLOperationsCounter              metrics.Counter
+	routerSLOBurnRateGauge                metrics.Gauge
+	accessLogDroppedCounter               metrics.Counter
+	cacheEvictionsCounter                 metrics.Counter
+	goroutinesGauge                       metrics.Gauge
+	fileDescriptorsGauge                  metrics.Gauge
+	entrypointReqsCounter                 metrics.Counter
+	entrypointReqDurationHistogram        metrics.Histogram
+	entrypointOpenConnsGauge              metrics.Gauge
+	entrypointReqsBytesCounter            metrics.Counter
+	entrypointRespsBytesCounter           metrics.Counter
+	entrypointSaturationGauge             metrics.Gauge
+	entrypointLimitRejectsCounter         metrics.Counter
+	entrypointShardConnsCounter           metrics.Counter
+	backendReqsCounter                    metrics.Counter
+	backendReqDurationHistogram           metrics.Histogram
+	backendOpenConnsGauge                 metrics.Gauge
+	backendRetriesCounter                 metrics.Counter
+	backendServerUpGauge                  metrics.Gauge
+	backendReqsBytesCounter               metrics.Counter
+	backendRespsBytesCounter              metrics.Counter
+	backendInvocationDurationHistogram    metrics.Histogram
+	backendTLSVerificationFailuresCounter metrics.Counter
+	backendDrainedRequestsCounter         metrics.Counter
+	backendDialFallbacksCounter           metrics.Counter
 }
 
 func (r *standardRegistry) IsEnabled() bool {
@@ -144,6 +305,46 @@ func (r *standardRegistry) LastConfigReloadFailureGauge() metrics.Gauge {
 	return r.lastConfigReloadFailureGauge
 }
 
+func (r *standardRegistry) ConfigReloadDurationHistogram() metrics.Histogram {
+	return r.configReloadDurationHistogram
+}
+
+func (r *standardRegistry) ReadyGauge() metrics.Gauge {
+	return r.readyGauge
+}
+
+func (r *standardRegistry) TLSCertsNotAfterGauge() metrics.Gauge {
+	return r.tlsCertsNotAfterGauge
+}
+
+func (r *standardRegistry) ExperimentExposuresCounter() metrics.Counter {
+	return r.experimentExposuresCounter
+}
+
+func (r *standardRegistry) GraphQLOperationsCounter() metrics.Counter {
+	return r.graphQLOperationsCounter
+}
+
+func (r *standardRegistry) RouterSLOBurnRateGauge() metrics.Gauge {
+	return r.routerSLOBurnRateGauge
+}
+
+func (r *standardRegistry) AccessLogDroppedCounter() metrics.Counter {
+	return r.accessLogDroppedCounter
+}
+
+func (r *standardRegistry) CacheEvictionsCounter() metrics.Counter {
+	return r.cacheEvictionsCounter
+}
+
+func (r *standardRegistry) GoroutinesGauge() metrics.Gauge {
+	return r.goroutinesGauge
+}
+
+func (r *standardRegistry) FileDescriptorsGauge() metrics.Gauge {
+	return r.fileDescriptorsGauge
+}
+
 func (r *standardRegistry) EntrypointReqsCounter() metrics.Counter {
 	return r.entrypointReqsCounter
 }
@@ -156,6 +357,26 @@ func (r *standardRegistry) EntrypointOpenConnsGauge() metrics.Gauge {
 	return r.entrypointOpenConnsGauge
 }
 
+func (r *standardRegistry) EntrypointReqsBytesCounter() metrics.Counter {
+	return r.entrypointReqsBytesCounter
+}
+
+func (r *standardRegistry) EntrypointRespsBytesCounter() metrics.Counter {
+	return r.entrypointRespsBytesCounter
+}
+
+func (r *standardRegistry) EntrypointSaturationGauge() metrics.Gauge {
+	return r.entrypointSaturationGauge
+}
+
+func (r *standardRegistry) EntrypointLimitRejectsCounter() metrics.Counter {
+	return r.entrypointLimitRejectsCounter
+}
+
+func (r *standardRegistry) EntrypointShardConnsCounter() metrics.Counter {
+	return r.entrypointShardConnsCounter
+}
+
 func (r *standardRegistry) BackendReqsCounter() metrics.Counter {
 	return r.backendReqsCounter
 }
@@ -175,3 +396,27 @@ func (r *standardRegistry) BackendRetriesCounter() metrics.Counter {
 func (r *standardRegistry) BackendServerUpGauge() metrics.Gauge {
 	return r.backendServerUpGauge
 }
+
+func (r *standardRegistry) BackendReqsBytesCounter() metrics.Counter {
+	return r.backendReqsBytesCounter
+}
+
+func (r *standardRegistry) BackendRespsBytesCounter() metrics.Counter {
+	return r.backendRespsBytesCounter
+}
+
+func (r *standardRegistry) BackendInvocationDurationHistogram() metrics.Histogram {
+	return r.backendInvocationDurationHistogram
+}
+
+func (r *standardRegistry) BackendTLSVerificationFailuresCounter() metrics.Counter {
+	return r.backendTLSVerificationFailuresCounter
+}
+
+func (r *standardRegistry) BackendDrainedRequestsCounter() metrics.Counter {
+	return r.backendDrainedRequestsCounter
+}
+
+func (r *standardRegistry) BackendDialFallbacksCounter() metrics.Counter {
+	return r.backendDialFallbacksCounter
+}
@@ -27,22 +27,57 @@ const (
 	configReloadsFailuresTotalName = metricConfigPrefix + "reloads_failure_total"
 	configLastReloadSuccessName    = metricConfigPrefix + "last_reload_success"
 	configLastReloadFailureName    = metricConfigPrefix + "last_reload_failure"
+	configReloadDurationName       = metricConfigPrefix + "reload_duration_seconds"
+	readyName                      = MetricNamePrefix + "ready"
+
+	// tls
+	tlsCertsNotAfterName = MetricNamePrefix + "tls_certs_not_after"
+
+	// experiments
+	experimentExposuresName = MetricNamePrefix + "experiment_exposures_total"
+
+	// GraphQL
+	graphQLOperationsName = MetricNamePrefix + "graphql_operations_total"
+
+	// router
+	routerSLOBurnRateName = MetricNamePrefix + "router_slo_burn_rate"
+
+	// access log
+	accessLogDroppedName = MetricNamePrefix + "accesslog_dropped_total"
+
+	// cache
+	cacheEvictionsName = MetricNamePrefix + "cache_evictions_total"
+
+	// runtime
+	goroutinesName      = MetricNamePrefix + "goroutines"
+	fileDescriptorsName = MetricNamePrefix + "open_fds"
 
 	// entrypoint
-	metricEntryPointPrefix    = MetricNamePrefix + "entrypoint_"
-	entrypointReqsTotalName   = metricEntryPointPrefix + "requests_total"
-	entrypointReqDurationName = metricEntryPointPrefix + "request_duration_seconds"
-	entrypointOpenConnsName   = metricEntryPointPrefix + "open_connections"
+	metricEntryPointPrefix     = MetricNamePrefix + "entrypoint_"
+	entrypointReqsTotalName    = metricEntryPointPrefix + "requests_total"
+	entrypointReqDurationName  = metricEntryPointPrefix + "request_duration_seconds"
+	entrypointOpenConnsName    = metricEntryPointPrefix + "open_connections"
+	entrypointReqsBytesName    = metricEntryPointPrefix + "requests_bytes_total"
+	entrypointRespsBytesName   = metricEntryPointPrefix + "responses_bytes_total"
+	entrypointSaturationName   = metricEntryPointPrefix + "saturation"
+	entrypointLimitRejectsName = metricEntryPointPrefix + "limit_rejects_total"
+	entrypointShardConnsName   = metricEntryPointPrefix + "shard_connections_total"
 
 	// backend level.
 
 	// MetricBackendPrefix prefix of all backend metric names
-	MetricBackendPrefix     = MetricNamePrefix + "backend_"
-	backendReqsTotalName    = MetricBackendPrefix + "requests_total"
-	backendReqDurationName  = MetricBackendPrefix + "request_duration_seconds"
-	backendOpenConnsName    = MetricBackendPrefix + "open_connections"
-	backendRetriesTotalName = MetricBackendPrefix + "retries_total"
-	backendServerUpName     = MetricBackendPrefix + "server_up"
+	MetricBackendPrefix                = MetricNamePrefix + "backend_"
+	backendReqsTotalName               = MetricBackendPrefix + "requests_total"
+	backendReqDurationName             = MetricBackendPrefix + "request_duration_seconds"
+	backendOpenConnsName               = MetricBackendPrefix + "open_connections"
+	backendRetriesTotalName            = MetricBackendPrefix + "retries_total"
+	backendServerUpName                = MetricBackendPrefix + "server_up"
+	backendReqsBytesName               = MetricBackendPrefix + "requests_bytes_total"
+	backendRespsBytesName              = MetricBackendPrefix + "responses_bytes_total"
+	backendInvocationDurationName      = MetricBackendPrefix + "invocation_duration_seconds"
+	backendTLSVerificationFailuresName = MetricBackendPrefix + "tls_verification_failures_total"
+	backendDrainedRequestsName         = MetricBackendPrefix + "drained_requests_total"
+	backendDialFallbacksName           = MetricBackendPrefix + "dial_fallbacks_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -89,6 +124,8 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		promState.ListenValueUpdates()
 	})
 
+	labels := newLabelFilter(config.Labels)
+
 	configReloads := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
 		Name: configReloadsTotalName,
 		Help: "Config reloads",
@@ -105,73 +142,215 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: configLastReloadFailureName,
 		Help: "Last config reload failure",
 	}, []string{})
-
-	entrypointReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
-		Name: entrypointReqsTotalName,
-		Help: "How many HTTP requests processed on an entrypoint, partitioned by status code, protocol, and method.",
-	}, []string{"code", "method", "protocol", "entrypoint"})
-	entrypointReqDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
-		Name:    entrypointReqDurationName,
-		Help:    "How long it took to process the request on an entrypoint, partitioned by status code, protocol, and method.",
-		Buckets: buckets,
-	}, []string{"code", "method", "protocol", "entrypoint"})
-	entrypointOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
-		Name: entrypointOpenConnsName,
-		Help: "How many open connections exist on an entrypoint, partitioned by method and protocol.",
-	}, []string{"method", "protocol", "entrypoint"})
-
-	backendReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
-		Name: backendReqsTotalName,
-		Help: "How many HTTP requests processed on a backend, partitioned by status code, protocol, and method.",
-	}, []string{"code", "method", "protocol", "backend"})
-	backendReqDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
-		Name:    backendReqDurationName,
-		Help:    "How long it took to process the request on a backend, partitioned by status code, protocol, and method.",
-		Buckets: buckets,
-	}, []string{"code", "method", "protocol", "backend"})
-	backendOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
-		Name: backendOpenConnsName,
-		Help: "How many open connections exist on a backend, partitioned by method and protocol.",
-	}, []string{"method", "protocol", "backend"})
-	backendRetries := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
-		Name: backendRetriesTotalName,
-		Help: "How many request retries happened on a backend.",
-	}, []string{"backend"})
-	backendServerUp := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
-		Name: backendServerUpName,
-		Help: "Backend server is up, described by gauge value of 0 or 1.",
-	}, []string{"backend", "url"})
+	configReloadDuration := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+		Name:    configReloadDurationName,
+		Help:    "How long it took to apply the configuration on a reload.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 15, 60},
+	}, []string{})
+	ready := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: readyName,
+		Help: "Whether Traefik is ready to serve traffic (1) or not (0)",
+	}, []string{})
+	tlsCertsNotAfter := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: tlsCertsNotAfterName,
+		Help: "Certificate expiration timestamp",
+	}, []string{"cn", "serial", "sans"})
+	experimentExposures := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: experimentExposuresName,
+		Help: "How many requests were assigned to a variant of an experiment.",
+	}, []string{"experiment", "variant"})
+	graphQLOperations := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: graphQLOperationsName,
+		Help: "How many GraphQL operations were processed, partitioned by middleware, operation name, type and outcome.",
+	}, []string{"middleware", "operation", "type", "outcome"})
+	routerSLOBurnRate := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: routerSLOBurnRateName,
+		Help: "Rate at which a router consumes the error budget of its latency objective, 1 consuming it exactly over the window.",
+	}, []string{"router"})
+	accessLogDropped := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: accessLogDroppedName,
+		Help: "How many access logs were dropped because the access log queue was full.",
+	}, []string{})
+	cacheEvictions := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+		Name: cacheEvictionsName,
+		Help: "How many entries were evicted from the in-process caches because they were full, partitioned by cache.",
+	}, []string{"cache"})
+	goroutines := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: goroutinesName,
+		Help: "How many goroutines are running, partitioned by subsystem.",
+	}, []string{"subsystem"})
+	fileDescriptors := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+		Name: fileDescriptorsName,
+		Help: "How many file descriptors are open.",
+	}, []string{})
 
 	promState.describers = []func(chan<- *stdprometheus.Desc){
 		configReloads.cv.Describe,
 		configReloadsFailures.cv.Describe,
 		lastConfigReloadSuccess.gv.Describe,
 		lastConfigReloadFailure.gv.Describe,
-		entrypointReqs.cv.Describe,
-		entrypointReqDurations.hv.Describe,
-		entrypointOpenConns.gv.Describe,
-		backendReqs.cv.Describe,
-		backendReqDurations.hv.Describe,
-		backendOpenConns.gv.Describe,
-		backendRetries.cv.Describe,
-		backendServerUp.gv.Describe,
-	}
-
-	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           configReloads,
-		configReloadsFailureCounter:    configReloadsFailures,
-		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
-		lastConfigReloadFailureGauge:   lastConfigReloadFailure,
-		entrypointReqsCounter:          entrypointReqs,
-		entrypointReqDurationHistogram: entrypointReqDurations,
-		entrypointOpenConnsGauge:       entrypointOpenConns,
-		backendReqsCounter:             backendReqs,
-		backendReqDurationHistogram:    backendReqDurations,
-		backendOpenConnsGauge:          backendOpenConns,
-		backendRetriesCounter:          backendRetries,
-		backendServerUpGauge:           backendServerUp,
+		configReloadDuration.hv.Describe,
+		ready.gv.Describe,
+		tlsCertsNotAfter.gv.Describe,
+		experimentExposures.cv.Describe,
+		graphQLOperations.cv.Describe,
+		routerSLOBurnRate.gv.Describe,
+		accessLogDropped.cv.Describe,
+		cacheEvictions.cv.Describe,
+		goroutines.gv.Describe,
+		fileDescriptors.gv.Describe,
+	}
+
+	reg := &standardRegistry{
+		enabled:                       true,
+		configReloadsCounter:          configReloads,
+		configReloadsFailureCounter:   configReloadsFailures,
+		lastConfigReloadSuccessGauge:  lastConfigReloadSuccess,
+		lastConfigReloadFailureGauge:  lastConfigReloadFailure,
+		configReloadDurationHistogram: configReloadDuration,
+		readyGauge:                    ready,
+		tlsCertsNotAfterGauge:         tlsCertsNotAfter,
+		experimentExposuresCounter:    experimentExposures,
+		graphQLOperationsCounter:      graphQLOperations,
+		routerSLOBurnRateGauge:        routerSLOBurnRate,
+		accessLogDroppedCounter:       accessLogDropped,
+		cacheEvictionsCounter:         cacheEvictions,
+		goroutinesGauge:               goroutines,
+		fileDescriptorsGauge:          fileDescriptors,
+	}
+
+	if !config.DisableEntryPointMetrics {
+		entrypointReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entrypointReqsTotalName,
+			Help: "How many HTTP requests processed on an entrypoint, partitioned by status code, protocol, and method.",
+		}, labels.keep("code", "method", "protocol", "entrypoint"))
+		entrypointReqDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+			Name:    entrypointReqDurationName,
+			Help:    "How long it took to process the request on an entrypoint, partitioned by status code, protocol, and method.",
+			Buckets: buckets,
+		}, labels.keep("code", "method", "protocol", "entrypoint"))
+		entrypointOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+			Name: entrypointOpenConnsName,
+			Help: "How many open connections exist on an entrypoint, partitioned by method and protocol.",
+		}, labels.keep("method", "protocol", "entrypoint"))
+		entrypointReqsBytes := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entrypointReqsBytesName,
+			Help: "The total size of HTTP requests in bytes processed on an entrypoint, partitioned by status code, protocol, and method.",
+		}, labels.keep("code", "method", "protocol", "entrypoint"))
+		entrypointRespsBytes := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entrypointRespsBytesName,
+			Help: "The total size of HTTP responses in bytes processed on an entrypoint, partitioned by status code, protocol, and method.",
+		}, labels.keep("code", "method", "protocol", "entrypoint"))
+		entrypointSaturation := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+			Name: entrypointSaturationName,
+			Help: "Ratio of the connections or requests limit of an entrypoint in use, partitioned by limit.",
+		}, labels.keep("limit", "entrypoint"))
+		entrypointLimitRejects := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entrypointLimitRejectsName,
+			Help: "How many connections or requests were rejected by the limits of an entrypoint, partitioned by limit.",
+		}, labels.keep("limit", "entrypoint"))
+		entrypointShardConns := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: entrypointShardConnsName,
+			Help: "How many connections were accepted by the listeners of an entrypoint, partitioned by shard.",
+		}, labels.keep("shard", "entrypoint"))
+
+		promState.describers = append(promState.describers,
+			entrypointReqs.cv.Describe,
+			entrypointReqDurations.hv.Describe,
+			entrypointOpenConns.gv.Describe,
+			entrypointReqsBytes.cv.Describe,
+			entrypointRespsBytes.cv.Describe,
+			entrypointSaturation.gv.Describe,
+			entrypointLimitRejects.cv.Describe,
+			entrypointShardConns.cv.Describe,
+		)
+
+		reg.entrypointReqsCounter = entrypointReqs
+		reg.entrypointReqDurationHistogram = entrypointReqDurations
+		reg.entrypointOpenConnsGauge = entrypointOpenConns
+		reg.entrypointReqsBytesCounter = entrypointReqsBytes
+		reg.entrypointRespsBytesCounter = entrypointRespsBytes
+		reg.entrypointSaturationGauge = entrypointSaturation
+		reg.entrypointLimitRejectsCounter = entrypointLimitRejects
+		reg.entrypointShardConnsCounter = entrypointShardConns
 	}
+
+	if !config.DisableBackendMetrics {
+		backendReqs := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: backendReqsTotalName,
+			Help: "How many HTTP requests processed on a backend, partitioned by status code, protocol, and method.",
+		}, labels.keep("code", "method", "protocol", "backend"))
+		backendReqDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+			Name:    backendReqDurationName,
+			Help:    "How long it took to process the request on a backend, partitioned by status code, protocol, and method.",
+			Buckets: buckets,
+		}, labels.keep("code", "method", "protocol", "backend"))
+		backendOpenConns := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+			Name: backendOpenConnsName,
+			Help: "How many open connections exist on a backend, partitioned by method and protocol.",
+		}, labels.keep("method", "protocol", "backend"))
+		backendRetries := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: backendRetriesTotalName,
+			Help: "How many request retries happened on a backend.",
+		}, labels.keep("backend"))
+		backendServerUp := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+			Name: backendServerUpName,
+			Help: "Backend server is up, described by gauge value of 0 or 1.",
+		}, []string{"backend", "url"})
+		backendReqsBytes := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: backendReqsBytesName,
+			Help: "The total size of HTTP requests in bytes processed on a backend, partitioned by status code, protocol, and method.",
+		}, labels.keep("code", "method", "protocol", "backend"))
+		backendRespsBytes := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: backendRespsBytesName,
+			Help: "The total size of HTTP responses in bytes processed on a backend, partitioned by status code, protocol, and method.",
+		}, labels.keep("code", "method", "protocol", "backend"))
+		backendInvocationDurations := newHistogramFrom(promState.collectors, stdprometheus.HistogramOpts{
+			Name:    backendInvocationDurationName,
+			Help:    "How long it took to invoke the function of a backend, partitioned by cold start.",
+			Buckets: buckets,
+		}, labels.keep("cold", "backend"))
+		backendTLSVerificationFailures := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: backendTLSVerificationFailuresName,
+			Help: "How many TLS connections to the servers of a backend failed the verification of their certificate, partitioned by reason.",
+		}, labels.keep("reason", "backend"))
+		backendDrainedRequests := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: backendDrainedRequestsName,
+			Help: "How many requests in flight to the servers removed from a backend were drained, partitioned by outcome.",
+		}, labels.keep("outcome", "backend"))
+		backendDialFallbacks := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: backendDialFallbacksName,
+			Help: "How many connections to the servers of a backend were established with another address family than the preferred one, partitioned by family.",
+		}, labels.keep("family", "backend"))
+
+		promState.describers = append(promState.describers,
+			backendReqs.cv.Describe,
+			backendReqDurations.hv.Describe,
+			backendOpenConns.gv.Describe,
+			backendRetries.cv.Describe,
+			backendServerUp.gv.Describe,
+			backendReqsBytes.cv.Describe,
+			backendRespsBytes.cv.Describe,
+			backendInvocationDurations.hv.Describe,
+			backendTLSVerificationFailures.cv.Describe,
+			backendDrainedRequests.cv.Describe,
+			backendDialFallbacks.cv.Describe,
+		)
+
+		reg.backendReqsCounter = backendReqs
+		reg.backendReqDurationHistogram = backendReqDurations
+		reg.backendOpenConnsGauge = backendOpenConns
+		reg.backendRetriesCounter = backendRetries
+		reg.backendServerUpGauge = backendServerUp
+		reg.backendReqsBytesCounter = backendReqsBytes
+		reg.backendRespsBytesCounter = backendRespsBytes
+		reg.backendInvocationDurationHistogram = backendInvocationDurations
+		reg.backendTLSVerificationFailuresCounter = backendTLSVerificationFailures
+		reg.backendDrainedRequestsCounter = backendDrainedRequests
+		reg.backendDialFallbacksCounter = backendDialFallbacks
+	}
+
+	return reg
 }
 
 func registerPromState(ctx context.Context) bool {
@@ -189,24 +368,25 @@ func registerPromState(ctx context.Context) bool {
 // OnConfigurationUpdate receives the current configuration from Traefik.
 // It then converts the configuration to the optimized package internal format
 // and sets it to the promState.
-func OnConfigurationUpdate(configurations config.Configurations) {
+func OnConfigurationUpdate(configurations config.Configurations, entryPoints []string) {
 	dynamicConfig := newDynamicConfig()
 
-	// FIXME metrics
-	// for _, config := range configurations {
-	// 	for _, frontend := range config.Frontends {
-	// 		for _, entrypointName := range frontend.EntryPoints {
-	// 			dynamicConfig.entrypoints[entrypointName] = true
-	// 		}
-	// 	}
-	//
-	// 	for backendName, backend := range config.Backends {
-	// 		dynamicConfig.backends[backendName] = make(map[string]bool)
-	// 		for _, server := range backend.Servers {
-	// 			dynamicConfig.backends[backendName][server.URL] = true
-	// 		}
-	// 	}
-	// }
+	for _, entryPointName := range entryPoints {
+		dynamicConfig.entrypoints[entryPointName] = true
+	}
+
+	for _, config := range configurations {
+		for serviceName, service := range config.Services {
+			dynamicConfig.backends[serviceName] = make(map[string]bool)
+			if service.LoadBalancer == nil {
+				continue
+			}
+
+			for _, server := range service.LoadBalancer.Servers {
+				dynamicConfig.backends[serviceName][server.URL] = true
+			}
+		}
+	}
 
 	promState.SetDynamicConfig(dynamicConfig)
 }
@@ -362,6 +542,7 @@ func newCounterFrom(collectors chan<- *collector, opts stdprometheus.CounterOpts
 	c := &counter{
 		name:       opts.Name,
 		cv:         cv,
+		labelNames: labelNames,
 		collectors: collectors,
 	}
 	if len(labelNames) == 0 {
@@ -373,6 +554,7 @@ func newCounterFrom(collectors chan<- *collector, opts stdprometheus.CounterOpts
 type counter struct {
 	name             string
 	cv               *stdprometheus.CounterVec
+	labelNames       []string
 	labelNamesValues labelNamesValues
 	collectors       chan<- *collector
 }
@@ -381,7 +563,8 @@ func (c *counter) With(labelValues ...string) metrics.Counter {
 	return &counter{
 		name:             c.name,
 		cv:               c.cv,
-		labelNamesValues: c.labelNamesValues.With(labelValues...),
+		labelNames:       c.labelNames,
+		labelNamesValues: c.labelNamesValues.With(labelValues...).Keep(c.labelNames),
 		collectors:       c.collectors,
 	}
 }
@@ -404,6 +587,7 @@ func newGaugeFrom(collectors chan<- *collector, opts stdprometheus.GaugeOpts, la
 	g := &gauge{
 		name:       opts.Name,
 		gv:         gv,
+		labelNames: labelNames,
 		collectors: collectors,
 	}
 	if len(labelNames) == 0 {
@@ -415,6 +599,7 @@ func newGaugeFrom(collectors chan<- *collector, opts stdprometheus.GaugeOpts, la
 type gauge struct {
 	name             string
 	gv               *stdprometheus.GaugeVec
+	labelNames       []string
 	labelNamesValues labelNamesValues
 	collectors       chan<- *collector
 }
@@ -423,7 +608,8 @@ func (g *gauge) With(labelValues ...string) metrics.Gauge {
 	return &gauge{
 		name:             g.name,
 		gv:               g.gv,
-		labelNamesValues: g.labelNamesValues.With(labelValues...),
+		labelNames:       g.labelNames,
+		labelNamesValues: g.labelNamesValues.With(labelValues...).Keep(g.labelNames),
 		collectors:       g.collectors,
 	}
 }
@@ -455,6 +641,7 @@ func newHistogramFrom(collectors chan<- *collector, opts stdprometheus.Histogram
 	return &histogram{
 		name:       opts.Name,
 		hv:         hv,
+		labelNames: labelNames,
 		collectors: collectors,
 	}
 }
@@ -462,6 +649,7 @@ func newHistogramFrom(collectors chan<- *collector, opts stdprometheus.Histogram
 type histogram struct {
 	name             string
 	hv               *stdprometheus.HistogramVec
+	labelNames       []string
 	labelNamesValues labelNamesValues
 	collectors       chan<- *collector
 }
@@ -470,7 +658,8 @@ func (h *histogram) With(labelValues ...string) metrics.Histogram {
 	return &histogram{
 		name:             h.name,
 		hv:               h.hv,
-		labelNamesValues: h.labelNamesValues.With(labelValues...),
+		labelNames:       h.labelNames,
+		labelNamesValues: h.labelNamesValues.With(labelValues...).Keep(h.labelNames),
 		collectors:       h.collectors,
 	}
 }
@@ -501,6 +690,21 @@ func (lvs labelNamesValues) With(labelValues ...string) labelNamesValues {
 	return append(lvs, labelValues...)
 }
 
+// Keep returns the label names and values whose name is part of labelNames.
+// It allows to drop the label dimensions which are not emitted.
+func (lvs labelNamesValues) Keep(labelNames []string) labelNamesValues {
+	var kept labelNamesValues
+	for i := 0; i < len(lvs); i += 2 {
+		for _, name := range labelNames {
+			if lvs[i] == name {
+				kept = append(kept, lvs[i], lvs[i+1])
+				break
+			}
+		}
+	}
+	return kept
+}
+
 // ToLabels is a convenience method to convert a labelNamesValues
 // to the native prometheus.Labels.
 func (lvs labelNamesValues) ToLabels() stdprometheus.Labels {
@@ -510,3 +714,33 @@ func (lvs labelNamesValues) ToLabels() stdprometheus.Labels {
 	}
 	return labels
 }
+
+// labelFilter holds the label dimensions of the request metrics to emit.
+type labelFilter map[string]bool
+
+func newLabelFilter(labelNames []string) labelFilter {
+	if len(labelNames) == 0 {
+		return nil
+	}
+
+	filter := make(labelFilter)
+	for _, name := range labelNames {
+		filter[name] = true
+	}
+	return filter
+}
+
+// keep returns the given label names which are enabled, all of them when no filter is configured.
+func (f labelFilter) keep(labelNames ...string) []string {
+	if f == nil {
+		return labelNames
+	}
+
+	var kept []string
+	for _, name := range labelNames {
+		if f[name] {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
@@ -91,9 +91,14 @@ func TestRegisterPromState(t *testing.T) {
 // reset is a utility method for unit testing. It should be called after each
 // test run that changes promState internally in order to avoid dependencies
 // between unit tests.
+// The collectors channel is kept, as the goroutines listening to it outlive the tests which started them,
+// and the state they update is replaced under the lock they take.
 func (ps *prometheusState) reset() {
-	ps.collectors = make(chan *collector)
 	ps.describers = []func(ch chan<- *prometheus.Desc){}
+
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
 	ps.dynamicConfig = newDynamicConfig()
 	ps.state = make(map[string]*collector)
 }
@@ -113,6 +118,38 @@ func TestPrometheus(t *testing.T) {
 	prometheusRegistry.ConfigReloadsFailureCounter().Add(1)
 	prometheusRegistry.LastConfigReloadSuccessGauge().Set(float64(time.Now().Unix()))
 	prometheusRegistry.LastConfigReloadFailureGauge().Set(float64(time.Now().Unix()))
+	prometheusRegistry.ConfigReloadDurationHistogram().Observe(2)
+	prometheusRegistry.ReadyGauge().Set(1)
+	prometheusRegistry.
+		TLSCertsNotAfterGauge().
+		With("cn", "foo.com", "serial", "1", "sans", "foo.com,www.foo.com").
+		Set(1)
+	prometheusRegistry.
+		ExperimentExposuresCounter().
+		With("experiment", "checkout", "variant", "b").
+		Add(1)
+	prometheusRegistry.
+		GraphQLOperationsCounter().
+		With("middleware", "graphql", "operation", "GetUser", "type", "query", "outcome", "allowed").
+		Add(1)
+	prometheusRegistry.
+		RouterSLOBurnRateGauge().
+		With("router", "api").
+		Set(2)
+	prometheusRegistry.
+		AccessLogDroppedCounter().
+		Add(1)
+	prometheusRegistry.
+		CacheEvictionsCounter().
+		With("cache", "certificates").
+		Add(1)
+	prometheusRegistry.
+		GoroutinesGauge().
+		With("subsystem", "providers").
+		Set(10)
+	prometheusRegistry.
+		FileDescriptorsGauge().
+		Set(20)
 
 	prometheusRegistry.
 		EntrypointReqsCounter().
@@ -126,6 +163,18 @@ func TestPrometheus(t *testing.T) {
 		EntrypointOpenConnsGauge().
 		With("method", http.MethodGet, "protocol", "http", "entrypoint", "http").
 		Set(1)
+	prometheusRegistry.
+		EntrypointSaturationGauge().
+		With("limit", "requests", "entrypoint", "http").
+		Set(1)
+	prometheusRegistry.
+		EntrypointLimitRejectsCounter().
+		With("limit", "requests", "entrypoint", "http").
+		Add(1)
+	prometheusRegistry.
+		EntrypointShardConnsCounter().
+		With("shard", "0", "entrypoint", "http").
+		Add(1)
 
 	prometheusRegistry.
 		BackendReqsCounter().
@@ -143,10 +192,42 @@ func TestPrometheus(t *testing.T) {
 		BackendRetriesCounter().
 		With("backend", "backend1").
 		Add(1)
+	prometheusRegistry.
+		BackendTLSVerificationFailuresCounter().
+		With("reason", "pin", "backend", "backend1").
+		Add(1)
+	prometheusRegistry.
+		BackendDrainedRequestsCounter().
+		With("outcome", "completed", "backend", "backend1").
+		Add(1)
+	prometheusRegistry.
+		BackendDialFallbacksCounter().
+		With("family", "ipv4", "backend", "backend1").
+		Add(1)
 	prometheusRegistry.
 		BackendServerUpGauge().
 		With("backend", "backend1", "url", "http://127.0.0.10:80").
 		Set(1)
+	prometheusRegistry.
+		EntrypointReqsBytesCounter().
+		With("code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http", "entrypoint", "http").
+		Add(10)
+	prometheusRegistry.
+		EntrypointRespsBytesCounter().
+		With("code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http", "entrypoint", "http").
+		Add(20)
+	prometheusRegistry.
+		BackendReqsBytesCounter().
+		With("backend", "backend1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http").
+		Add(10)
+	prometheusRegistry.
+		BackendRespsBytesCounter().
+		With("backend", "backend1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http").
+		Add(20)
+	prometheusRegistry.
+		BackendInvocationDurationHistogram().
+		With("backend", "backend1", "cold", "true").
+		Observe(1)
 
 	delayForTrackingCompletion()
 
@@ -173,6 +254,70 @@ func TestPrometheus(t *testing.T) {
 			name:   configLastReloadFailureName,
 			assert: buildTimestampAssert(t, configLastReloadFailureName),
 		},
+		{
+			name:   configReloadDurationName,
+			assert: buildHistogramAssert(t, configReloadDurationName, 1),
+		},
+		{
+			name:   readyName,
+			assert: buildGaugeAssert(t, readyName, 1),
+		},
+		{
+			name: tlsCertsNotAfterName,
+			labels: map[string]string{
+				"cn":     "foo.com",
+				"serial": "1",
+				"sans":   "foo.com,www.foo.com",
+			},
+			assert: buildGaugeAssert(t, tlsCertsNotAfterName, 1),
+		},
+		{
+			name: experimentExposuresName,
+			labels: map[string]string{
+				"experiment": "checkout",
+				"variant":    "b",
+			},
+			assert: buildCounterAssert(t, experimentExposuresName, 1),
+		},
+		{
+			name: graphQLOperationsName,
+			labels: map[string]string{
+				"middleware": "graphql",
+				"operation":  "GetUser",
+				"type":       "query",
+				"outcome":    "allowed",
+			},
+			assert: buildCounterAssert(t, graphQLOperationsName, 1),
+		},
+		{
+			name: routerSLOBurnRateName,
+			labels: map[string]string{
+				"router": "api",
+			},
+			assert: buildGaugeAssert(t, routerSLOBurnRateName, 2),
+		},
+		{
+			name:   accessLogDroppedName,
+			assert: buildCounterAssert(t, accessLogDroppedName, 1),
+		},
+		{
+			name: cacheEvictionsName,
+			labels: map[string]string{
+				"cache": "certificates",
+			},
+			assert: buildCounterAssert(t, cacheEvictionsName, 1),
+		},
+		{
+			name: goroutinesName,
+			labels: map[string]string{
+				"subsystem": "providers",
+			},
+			assert: buildGaugeAssert(t, goroutinesName, 10),
+		},
+		{
+			name:   fileDescriptorsName,
+			assert: buildGaugeAssert(t, fileDescriptorsName, 20),
+		},
 		{
 			name: entrypointReqsTotalName,
 			labels: map[string]string{
@@ -202,6 +347,30 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, entrypointOpenConnsName, 1),
 		},
+		{
+			name: entrypointSaturationName,
+			labels: map[string]string{
+				"limit":      "requests",
+				"entrypoint": "http",
+			},
+			assert: buildGaugeAssert(t, entrypointSaturationName, 1),
+		},
+		{
+			name: entrypointLimitRejectsName,
+			labels: map[string]string{
+				"limit":      "requests",
+				"entrypoint": "http",
+			},
+			assert: buildCounterAssert(t, entrypointLimitRejectsName, 1),
+		},
+		{
+			name: entrypointShardConnsName,
+			labels: map[string]string{
+				"shard":      "0",
+				"entrypoint": "http",
+			},
+			assert: buildCounterAssert(t, entrypointShardConnsName, 1),
+		},
 		{
 			name: backendReqsTotalName,
 			labels: map[string]string{
@@ -238,6 +407,30 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGreaterThanCounterAssert(t, backendRetriesTotalName, 1),
 		},
+		{
+			name: backendTLSVerificationFailuresName,
+			labels: map[string]string{
+				"reason":  "pin",
+				"backend": "backend1",
+			},
+			assert: buildCounterAssert(t, backendTLSVerificationFailuresName, 1),
+		},
+		{
+			name: backendDrainedRequestsName,
+			labels: map[string]string{
+				"outcome": "completed",
+				"backend": "backend1",
+			},
+			assert: buildCounterAssert(t, backendDrainedRequestsName, 1),
+		},
+		{
+			name: backendDialFallbacksName,
+			labels: map[string]string{
+				"family":  "ipv4",
+				"backend": "backend1",
+			},
+			assert: buildCounterAssert(t, backendDialFallbacksName, 1),
+		},
 		{
 			name: backendServerUpName,
 			labels: map[string]string{
@@ -246,6 +439,54 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, backendServerUpName, 1),
 		},
+		{
+			name: entrypointReqsBytesName,
+			labels: map[string]string{
+				"code":       "200",
+				"method":     http.MethodGet,
+				"protocol":   "http",
+				"entrypoint": "http",
+			},
+			assert: buildCounterAssert(t, entrypointReqsBytesName, 10),
+		},
+		{
+			name: entrypointRespsBytesName,
+			labels: map[string]string{
+				"code":       "200",
+				"method":     http.MethodGet,
+				"protocol":   "http",
+				"entrypoint": "http",
+			},
+			assert: buildCounterAssert(t, entrypointRespsBytesName, 20),
+		},
+		{
+			name: backendReqsBytesName,
+			labels: map[string]string{
+				"code":     "200",
+				"method":   http.MethodGet,
+				"protocol": "http",
+				"backend":  "backend1",
+			},
+			assert: buildCounterAssert(t, backendReqsBytesName, 10),
+		},
+		{
+			name: backendRespsBytesName,
+			labels: map[string]string{
+				"code":     "200",
+				"method":   http.MethodGet,
+				"protocol": "http",
+				"backend":  "backend1",
+			},
+			assert: buildCounterAssert(t, backendRespsBytesName, 20),
+		},
+		{
+			name: backendInvocationDurationName,
+			labels: map[string]string{
+				"cold":    "true",
+				"backend": "backend1",
+			},
+			assert: buildHistogramAssert(t, backendInvocationDurationName, 1),
+		},
 	}
 
 	for _, test := range tests {
@@ -266,10 +507,59 @@ func TestPrometheus(t *testing.T) {
 	}
 }
 
-func TestPrometheusMetricRemoval(t *testing.T) {
-	// FIXME metrics
-	t.Skip("waiting for metrics")
+func TestPrometheusDisabledMetrics(t *testing.T) {
+	// Reset state of global promState.
+	defer promState.reset()
+
+	prometheusRegistry := initStandardRegistry(&types.Prometheus{DisableBackendMetrics: true})
 
+	assert.NotNil(t, prometheusRegistry.EntrypointReqsCounter())
+	assert.NotNil(t, prometheusRegistry.EntrypointReqsBytesCounter())
+	assert.Nil(t, prometheusRegistry.BackendReqsCounter())
+	assert.Nil(t, prometheusRegistry.BackendReqDurationHistogram())
+	assert.Nil(t, prometheusRegistry.BackendRespsBytesCounter())
+	assert.Len(t, promState.describers, 22)
+}
+
+func TestLabelFilter(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		labels             []string
+		expectedNames      []string
+		expectedNamesValue labelNamesValues
+	}{
+		{
+			desc:               "no filter",
+			expectedNames:      []string{"code", "method", "entrypoint"},
+			expectedNamesValue: labelNamesValues{"code", "200", "method", "GET", "entrypoint", "http"},
+		},
+		{
+			desc:               "high cardinality labels dropped",
+			labels:             []string{"entrypoint", "code"},
+			expectedNames:      []string{"code", "entrypoint"},
+			expectedNamesValue: labelNamesValues{"code", "200", "entrypoint", "http"},
+		},
+		{
+			desc:   "unknown label",
+			labels: []string{"router"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			names := newLabelFilter(test.labels).keep("code", "method", "entrypoint")
+			assert.Equal(t, test.expectedNames, names)
+
+			lvs := labelNamesValues{}.With("code", "200", "method", "GET", "entrypoint", "http").Keep(names)
+			assert.Equal(t, test.expectedNamesValue, lvs)
+		})
+	}
+}
+
+func TestPrometheusMetricRemoval(t *testing.T) {
 	// Reset state of global promState.
 	defer promState.reset()
 
@@ -288,7 +578,7 @@ func TestPrometheusMetricRemoval(t *testing.T) {
 		),
 	)
 
-	OnConfigurationUpdate(configurations)
+	OnConfigurationUpdate(configurations, []string{"entrypoint1"})
 
 	// Register some metrics manually that are not part of the active configuration.
 	// Those metrics should be part of the /metrics output on the first scrape but
@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenk/backoff"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// PushProtocolPushgateway pushes the metrics to a Prometheus Pushgateway.
+	PushProtocolPushgateway = "pushgateway"
+	// PushProtocolRemoteWrite pushes the metrics with the Prometheus remote write protocol.
+	PushProtocolRemoteWrite = "remotewrite"
+	// PushProtocolOTLP pushes the metrics with OTLP/HTTP.
+	PushProtocolOTLP = "otlp"
+
+	defaultPushJob       = "traefik"
+	defaultPushBatchSize = 500
+	defaultPushRetries   = 3
+	pushTimeout          = 10 * time.Second
+)
+
+var (
+	metricsPusher *pusher
+	pusherMu      sync.Mutex
+)
+
+// pushExporter sends gathered metric families to a remote endpoint.
+type pushExporter interface {
+	push(ctx context.Context, families []*dto.MetricFamily) error
+}
+
+// RegisterPush starts pushing the metrics gathered by the Prometheus registry to a remote endpoint, if it is not already started.
+func RegisterPush(ctx context.Context, config *types.Push) error {
+	pusherMu.Lock()
+	defer pusherMu.Unlock()
+
+	if metricsPusher != nil {
+		return nil
+	}
+
+	exporter, err := newPushExporter(config)
+	if err != nil {
+		return err
+	}
+
+	pushInterval, err := time.ParseDuration(config.PushInterval)
+	if err != nil {
+		log.FromContext(ctx).Warnf("Unable to parse %s from config.PushInterval: using 10s as the default value", config.PushInterval)
+		pushInterval = 10 * time.Second
+	}
+
+	maxRetries := config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultPushRetries
+	}
+
+	metricsPusher = newPusher(ctx, stdprometheus.DefaultGatherer, exporter, pushInterval, maxRetries)
+
+	return nil
+}
+
+// StopPush stops pushing the metrics, after a last push of their current values.
+func StopPush() {
+	pusherMu.Lock()
+	defer pusherMu.Unlock()
+
+	if metricsPusher != nil {
+		metricsPusher.stop()
+	}
+	metricsPusher = nil
+}
+
+func newPushExporter(config *types.Push) (pushExporter, error) {
+	if len(config.Address) == 0 {
+		return nil, fmt.Errorf("no address defined to push the metrics")
+	}
+
+	job := config.Job
+	if len(job) == 0 {
+		job = defaultPushJob
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPushBatchSize
+	}
+
+	client := &http.Client{Timeout: pushTimeout}
+
+	switch config.Protocol {
+	case PushProtocolPushgateway, "":
+		return &pushgatewayExporter{
+			url:    strings.TrimSuffix(config.Address, "/") + "/metrics/job/" + url.PathEscape(job),
+			client: client,
+		}, nil
+	case PushProtocolRemoteWrite:
+		return &remoteWriteExporter{
+			url:       config.Address,
+			job:       job,
+			batchSize: batchSize,
+			client:    client,
+		}, nil
+	case PushProtocolOTLP:
+		return &otlpExporter{
+			url:         config.Address,
+			serviceName: job,
+			batchSize:   batchSize,
+			startTime:   time.Now(),
+			client:      client,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown metrics push protocol: %s", config.Protocol)
+	}
+}
+
+// pusher periodically gathers the metrics and sends them with its exporter.
+type pusher struct {
+	gatherer   stdprometheus.Gatherer
+	exporter   pushExporter
+	maxRetries int
+	logger     log.Logger
+
+	ticker *time.Ticker
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newPusher(ctx context.Context, gatherer stdprometheus.Gatherer, exporter pushExporter, interval time.Duration, maxRetries int) *pusher {
+	p := &pusher{
+		gatherer:   gatherer,
+		exporter:   exporter,
+		maxRetries: maxRetries,
+		logger:     log.FromContext(ctx),
+		ticker:     time.NewTicker(interval),
+		done:       make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	safe.Go(func() {
+		defer p.wg.Done()
+
+		for {
+			select {
+			case <-p.ticker.C:
+				p.push()
+			case <-p.done:
+				p.push()
+				return
+			}
+		}
+	})
+
+	return p
+}
+
+func (p *pusher) stop() {
+	p.ticker.Stop()
+	close(p.done)
+	p.wg.Wait()
+}
+
+func (p *pusher) push() {
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		p.logger.Errorf("Unable to gather metrics: %v", err)
+		return
+	}
+
+	if len(families) == 0 {
+		return
+	}
+
+	operation := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		defer cancel()
+
+		return p.exporter.push(ctx, families)
+	}
+
+	notify := func(err error, d time.Duration) {
+		p.logger.Warnf("Unable to push metrics: %v, retrying in %s", err, d)
+	}
+
+	ebo := backoff.NewExponentialBackOff()
+	ebo.MaxElapsedTime = 0
+	if err := backoff.RetryNotify(safe.OperationWithRecover(operation), backoff.WithMaxRetries(ebo, uint64(p.maxRetries)), notify); err != nil {
+		p.logger.Errorf("Unable to push metrics: %v", err)
+	}
+}
+
+// pushgatewayExporter replaces the metrics of the job on a Prometheus Pushgateway.
+type pushgatewayExporter struct {
+	url    string
+	client *http.Client
+}
+
+func (e *pushgatewayExporter) push(ctx context.Context, families []*dto.MetricFamily) error {
+	buf := &bytes.Buffer{}
+	enc := expfmt.NewEncoder(buf, expfmt.FmtText)
+	for _, family := range families {
+		if err := enc.Encode(family); err != nil {
+			return err
+		}
+	}
+
+	return send(ctx, e.client, http.MethodPut, e.url, buf.Bytes(), map[string]string{
+		"Content-Type": string(expfmt.FmtText),
+	})
+}
+
+func send(ctx context.Context, client *http.Client, method, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			// the same payload would be rejected again
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	otlpScopeName = "github.com/containous/traefik/metrics"
+	// aggregationTemporalityCumulative is the only temporality exposed by the Prometheus client.
+	aggregationTemporalityCumulative = 2
+)
+
+// otlpExporter sends the metrics with OTLP/HTTP using protobuf payloads.
+type otlpExporter struct {
+	url         string
+	serviceName string
+	batchSize   int
+	startTime   time.Time
+	client      *http.Client
+}
+
+func (e *otlpExporter) push(ctx context.Context, families []*dto.MetricFamily) error {
+	now := time.Now()
+
+	for start := 0; start < len(families); start += e.batchSize {
+		end := start + e.batchSize
+		if end > len(families) {
+			end = len(families)
+		}
+
+		payload := e.encodeRequest(families[start:end], now)
+
+		err := send(ctx, e.client, http.MethodPost, e.url, payload, map[string]string{
+			"Content-Type": "application/x-protobuf",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeRequest encodes an opentelemetry.proto.collector.metrics.v1.ExportMetricsServiceRequest.
+func (e *otlpExporter) encodeRequest(families []*dto.MetricFamily, now time.Time) []byte {
+	var b protoBuffer
+
+	// resource_metrics
+	b.message(1, func(rm *protoBuffer) {
+		// resource
+		rm.message(1, func(r *protoBuffer) {
+			encodeOTLPAttribute(r, 1, "service.name", e.serviceName)
+		})
+
+		// scope_metrics
+		rm.message(2, func(sm *protoBuffer) {
+			sm.message(1, func(scope *protoBuffer) {
+				scope.string(1, otlpScopeName)
+			})
+
+			for _, family := range families {
+				family := family
+				sm.message(2, func(m *protoBuffer) {
+					e.encodeMetric(m, family, now)
+				})
+			}
+		})
+	})
+
+	return b
+}
+
+func (e *otlpExporter) encodeMetric(b *protoBuffer, family *dto.MetricFamily, now time.Time) {
+	b.string(1, family.GetName())
+	b.string(2, family.GetHelp())
+
+	start := uint64(e.startTime.UnixNano())
+
+	timestamp := func(m *dto.Metric) uint64 {
+		if m.TimestampMs != nil {
+			return uint64(m.GetTimestampMs() * int64(time.Millisecond))
+		}
+		return uint64(now.UnixNano())
+	}
+
+	numberDataPoints := func(b *protoBuffer, value func(*dto.Metric) float64) {
+		for _, m := range family.Metric {
+			m := m
+			b.message(1, func(dp *protoBuffer) {
+				dp.fixed64(2, start)
+				dp.fixed64(3, timestamp(m))
+				dp.double(4, value(m))
+				encodeOTLPLabels(dp, 7, m.Label)
+			})
+		}
+	}
+
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		// sum
+		b.message(7, func(sum *protoBuffer) {
+			numberDataPoints(sum, func(m *dto.Metric) float64 { return m.GetCounter().GetValue() })
+			sum.varint(2, aggregationTemporalityCumulative)
+			sum.varint(3, 1)
+		})
+	case dto.MetricType_GAUGE:
+		b.message(5, func(gauge *protoBuffer) {
+			numberDataPoints(gauge, func(m *dto.Metric) float64 { return m.GetGauge().GetValue() })
+		})
+	case dto.MetricType_UNTYPED:
+		b.message(5, func(gauge *protoBuffer) {
+			numberDataPoints(gauge, func(m *dto.Metric) float64 { return m.GetUntyped().GetValue() })
+		})
+	case dto.MetricType_HISTOGRAM:
+		b.message(9, func(histogram *protoBuffer) {
+			for _, m := range family.Metric {
+				m := m
+				histogram.message(1, func(dp *protoBuffer) {
+					h := m.GetHistogram()
+
+					dp.fixed64(2, start)
+					dp.fixed64(3, timestamp(m))
+					dp.fixed64(4, h.GetSampleCount())
+					dp.double(5, h.GetSampleSum())
+
+					// OTLP buckets are not cumulative, and have an implicit +Inf upper bound.
+					var counts, bounds protoBuffer
+					var previous uint64
+					for _, bucket := range h.Bucket {
+						counts.fixed64Raw(bucket.GetCumulativeCount() - previous)
+						previous = bucket.GetCumulativeCount()
+						bounds.fixed64Raw(math.Float64bits(bucket.GetUpperBound()))
+					}
+					counts.fixed64Raw(h.GetSampleCount() - previous)
+
+					dp.bytes(6, counts)
+					dp.bytes(7, bounds)
+					encodeOTLPLabels(dp, 9, m.Label)
+				})
+			}
+			histogram.varint(2, aggregationTemporalityCumulative)
+		})
+	case dto.MetricType_SUMMARY:
+		b.message(11, func(summary *protoBuffer) {
+			for _, m := range family.Metric {
+				m := m
+				summary.message(1, func(dp *protoBuffer) {
+					s := m.GetSummary()
+
+					dp.fixed64(2, start)
+					dp.fixed64(3, timestamp(m))
+					dp.fixed64(4, s.GetSampleCount())
+					dp.double(5, s.GetSampleSum())
+					for _, q := range s.Quantile {
+						q := q
+						dp.message(6, func(qv *protoBuffer) {
+							qv.double(1, q.GetQuantile())
+							qv.double(2, q.GetValue())
+						})
+					}
+					encodeOTLPLabels(dp, 7, m.Label)
+				})
+			}
+		})
+	}
+}
+
+func encodeOTLPLabels(b *protoBuffer, field int, labels []*dto.LabelPair) {
+	for _, lp := range labels {
+		encodeOTLPAttribute(b, field, lp.GetName(), lp.GetValue())
+	}
+}
+
+// encodeOTLPAttribute encodes a KeyValue with a string value.
+func encodeOTLPAttribute(b *protoBuffer, field int, key, value string) {
+	b.message(field, func(kv *protoBuffer) {
+		kv.string(1, key)
+		kv.message(2, func(v *protoBuffer) {
+			v.string(1, value)
+		})
+	})
+}
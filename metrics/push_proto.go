@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// protoBuffer is a minimal protocol buffers writer, enough to encode the remote write and OTLP messages.
+type protoBuffer []byte
+
+func (b *protoBuffer) key(field int, wireType int) {
+	b.varintRaw(uint64(field<<3 | wireType))
+}
+
+func (b *protoBuffer) varintRaw(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	*b = append(*b, tmp[:n]...)
+}
+
+func (b *protoBuffer) varint(field int, v uint64) {
+	b.key(field, 0)
+	b.varintRaw(v)
+}
+
+func (b *protoBuffer) fixed64Raw(v uint64) {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	*b = append(*b, tmp[:]...)
+}
+
+func (b *protoBuffer) fixed64(field int, v uint64) {
+	b.key(field, 1)
+	b.fixed64Raw(v)
+}
+
+func (b *protoBuffer) double(field int, v float64) {
+	b.fixed64(field, math.Float64bits(v))
+}
+
+func (b *protoBuffer) bytes(field int, v []byte) {
+	b.key(field, 2)
+	b.varintRaw(uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *protoBuffer) string(field int, v string) {
+	b.bytes(field, []byte(v))
+}
+
+func (b *protoBuffer) message(field int, encode func(*protoBuffer)) {
+	var nested protoBuffer
+	encode(&nested)
+	b.bytes(field, nested)
+}
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/snappy"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// remoteWriteExporter sends the metrics with the Prometheus remote write protocol (snappy compressed protobuf).
+type remoteWriteExporter struct {
+	url       string
+	job       string
+	batchSize int
+	client    *http.Client
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	labels    []label
+	value     float64
+	timestamp int64
+}
+
+func (e *remoteWriteExporter) push(ctx context.Context, families []*dto.MetricFamily) error {
+	samples := toSamples(families, e.job, time.Now())
+
+	for start := 0; start < len(samples); start += e.batchSize {
+		end := start + e.batchSize
+		if end > len(samples) {
+			end = len(samples)
+		}
+
+		payload := snappy.Encode(nil, encodeWriteRequest(samples[start:end]))
+
+		err := send(ctx, e.client, http.MethodPost, e.url, payload, map[string]string{
+			"Content-Type":                      "application/x-protobuf",
+			"Content-Encoding":                  "snappy",
+			"X-Prometheus-Remote-Write-Version": "0.1.0",
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toSamples flattens the metric families into samples, the way the Prometheus server stores them.
+func toSamples(families []*dto.MetricFamily, job string, now time.Time) []sample {
+	var samples []sample
+
+	for _, family := range families {
+		name := family.GetName()
+
+		for _, m := range family.Metric {
+			timestamp := now.UnixNano() / int64(time.Millisecond)
+			if m.TimestampMs != nil {
+				timestamp = m.GetTimestampMs()
+			}
+
+			add := func(metricName string, value float64, extra ...label) {
+				labels := []label{{name: "__name__", value: metricName}, {name: "job", value: job}}
+				for _, lp := range m.Label {
+					labels = append(labels, label{name: lp.GetName(), value: lp.GetValue()})
+				}
+				labels = append(labels, extra...)
+				sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+
+				samples = append(samples, sample{labels: labels, value: value, timestamp: timestamp})
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m.GetUntyped().GetValue())
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				for _, b := range h.Bucket {
+					add(name+"_bucket", float64(b.GetCumulativeCount()), label{name: "le", value: formatFloat(b.GetUpperBound())})
+				}
+				add(name+"_bucket", float64(h.GetSampleCount()), label{name: "le", value: "+Inf"})
+				add(name+"_sum", h.GetSampleSum())
+				add(name+"_count", float64(h.GetSampleCount()))
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.Quantile {
+					add(name, q.GetValue(), label{name: "quantile", value: formatFloat(q.GetQuantile())})
+				}
+				add(name+"_sum", s.GetSampleSum())
+				add(name+"_count", float64(s.GetSampleCount()))
+			}
+		}
+	}
+
+	return samples
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequest encodes a prometheus.WriteRequest.
+func encodeWriteRequest(samples []sample) []byte {
+	var b protoBuffer
+
+	for _, s := range samples {
+		s := s
+		// timeseries
+		b.message(1, func(ts *protoBuffer) {
+			for _, l := range s.labels {
+				l := l
+				ts.message(1, func(lb *protoBuffer) {
+					lb.string(1, l.name)
+					lb.string(2, l.value)
+				})
+			}
+
+			ts.message(2, func(sb *protoBuffer) {
+				sb.double(1, s.value)
+				sb.varint(2, uint64(s.timestamp))
+			})
+		})
+	}
+
+	return b
+}
@@ -0,0 +1,264 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/types"
+	"github.com/golang/snappy"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPushExporter(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      *types.Push
+		expected    pushExporter
+		expectedErr bool
+	}{
+		{
+			desc:        "no address",
+			config:      &types.Push{},
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown protocol",
+			config:      &types.Push{Protocol: "foo", Address: "http://localhost:9091"},
+			expectedErr: true,
+		},
+		{
+			desc:   "pushgateway by default",
+			config: &types.Push{Address: "http://localhost:9091/", Job: "my job"},
+			expected: &pushgatewayExporter{
+				url: "http://localhost:9091/metrics/job/my%20job",
+			},
+		},
+		{
+			desc:   "remote write",
+			config: &types.Push{Protocol: PushProtocolRemoteWrite, Address: "http://localhost:9090/api/v1/write", BatchSize: 10},
+			expected: &remoteWriteExporter{
+				url:       "http://localhost:9090/api/v1/write",
+				job:       defaultPushJob,
+				batchSize: 10,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			exporter, err := newPushExporter(test.config)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			switch e := exporter.(type) {
+			case *pushgatewayExporter:
+				e.client = nil
+			case *remoteWriteExporter:
+				e.client = nil
+			}
+			assert.Equal(t, test.expected, exporter)
+		})
+	}
+}
+
+func TestPushgatewayExporter(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPut, req.Method)
+		assert.Equal(t, "/metrics/job/traefik", req.URL.Path)
+
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	exporter, err := newPushExporter(&types.Push{Address: server.URL})
+	require.NoError(t, err)
+
+	err = exporter.push(context.Background(), gatherTestFamilies(t))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `test_requests_total{code="200"} 2`)
+	assert.Contains(t, string(body), `test_request_duration_seconds_bucket{le="+Inf"} 1`)
+}
+
+func TestRemoteWriteExporter(t *testing.T) {
+	var payloads [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "snappy", req.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		payload, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	exporter, err := newPushExporter(&types.Push{Protocol: PushProtocolRemoteWrite, Address: server.URL, BatchSize: 4})
+	require.NoError(t, err)
+
+	err = exporter.push(context.Background(), gatherTestFamilies(t))
+	require.NoError(t, err)
+
+	// 4 bucket (3 + "+Inf"), sum and count samples for the histogram, then 1 counter sample.
+	require.Len(t, payloads, 2)
+	assert.True(t, bytes.Contains(payloads[0], []byte("__name__")))
+	assert.True(t, bytes.Contains(payloads[0], []byte("+Inf")))
+	assert.True(t, bytes.Contains(payloads[1], []byte("test_request_duration_seconds_count")))
+	assert.True(t, bytes.Contains(payloads[1], []byte("test_requests_total")))
+}
+
+func TestToSamples(t *testing.T) {
+	now := time.Unix(10, 0)
+
+	samples := toSamples(gatherTestFamilies(t), "traefik", now)
+	require.Len(t, samples, 7)
+
+	assert.Equal(t, []label{
+		{name: "__name__", value: "test_request_duration_seconds_bucket"},
+		{name: "job", value: "traefik"},
+		{name: "le", value: "0.1"},
+	}, samples[0].labels)
+	assert.Equal(t, float64(0), samples[0].value)
+	assert.Equal(t, int64(10000), samples[0].timestamp)
+
+	assert.Equal(t, []label{
+		{name: "__name__", value: "test_request_duration_seconds_bucket"},
+		{name: "job", value: "traefik"},
+		{name: "le", value: "+Inf"},
+	}, samples[3].labels)
+	assert.Equal(t, float64(1), samples[3].value)
+
+	assert.Equal(t, []label{
+		{name: "__name__", value: "test_requests_total"},
+		{name: "code", value: "200"},
+		{name: "job", value: "traefik"},
+	}, samples[6].labels)
+	assert.Equal(t, float64(2), samples[6].value)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	exporter, err := newPushExporter(&types.Push{Protocol: PushProtocolOTLP, Address: server.URL, Job: "traefik-test"})
+	require.NoError(t, err)
+
+	err = exporter.push(context.Background(), gatherTestFamilies(t))
+	require.NoError(t, err)
+
+	assert.True(t, bytes.Contains(body, []byte("service.name")))
+	assert.True(t, bytes.Contains(body, []byte("traefik-test")))
+	assert.True(t, bytes.Contains(body, []byte("test_requests_total")))
+	assert.True(t, bytes.Contains(body, []byte("test_request_duration_seconds")))
+}
+
+func TestPusherRetries(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		if calls == 1 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	exporter, err := newPushExporter(&types.Push{Address: server.URL})
+	require.NoError(t, err)
+
+	// The interval is never reached: stopping the pusher does the push.
+	p := newPusher(context.Background(), newTestGatherer(t), exporter, time.Hour, 3)
+	p.stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, calls)
+}
+
+func TestPusherDoesNotRetryClientErrors(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	exporter, err := newPushExporter(&types.Push{Address: server.URL})
+	require.NoError(t, err)
+
+	p := newPusher(context.Background(), newTestGatherer(t), exporter, time.Hour, 3)
+	p.stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, calls)
+}
+
+func newTestGatherer(t *testing.T) stdprometheus.Gatherer {
+	t.Helper()
+
+	registry := stdprometheus.NewRegistry()
+
+	counter := stdprometheus.NewCounterVec(stdprometheus.CounterOpts{
+		Name: "test_requests_total",
+		Help: "Test requests",
+	}, []string{"code"})
+	counter.WithLabelValues("200").Add(2)
+
+	histogram := stdprometheus.NewHistogram(stdprometheus.HistogramOpts{
+		Name:    "test_request_duration_seconds",
+		Help:    "Test request duration",
+		Buckets: []float64{0.1, 0.3, 1.2},
+	})
+	histogram.Observe(2)
+
+	require.NoError(t, registry.Register(counter))
+	require.NoError(t, registry.Register(histogram))
+
+	return registry
+}
+
+func gatherTestFamilies(t *testing.T) []*dto.MetricFamily {
+	t.Helper()
+
+	families, err := newTestGatherer(t).Gather()
+	require.NoError(t, err)
+
+	return families
+}
@@ -26,11 +26,27 @@ const (
 	statsdConfigReloadsFailureName    = statsdConfigReloadsName + ".failure"
 	statsdLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
 	statsdLastConfigReloadFailureName = "config.reload.lastFailureTimestamp"
+	statsdConfigReloadDurationName    = "config.reload.duration"
+	statsdReadyName                   = "ready"
+	statsdExperimentExposuresName     = "experiment.exposures.total"
+	statsdGraphQLOperationsName       = "graphql.operations.total"
+	statsdRouterSLOBurnRateName       = "router.slo.burn_rate"
+	statsdAccessLogDroppedName        = "accesslog.dropped.total"
+	statsdCacheEvictionsName          = "cache.evictions.total"
+	statsdGoroutinesName              = "goroutines"
+	statsdFileDescriptorsName         = "open.fds"
 	statsdEntrypointReqsName          = "entrypoint.request.total"
 	statsdEntrypointReqDurationName   = "entrypoint.request.duration"
 	statsdEntrypointOpenConnsName     = "entrypoint.connections.open"
+	statsdEntrypointSaturationName    = "entrypoint.saturation"
+	statsdEntrypointLimitRejectsName  = "entrypoint.limit.rejects.total"
+	statsdEntrypointShardConnsName    = "entrypoint.shard.connections.total"
 	statsdOpenConnsName               = "backend.connections.open"
 	statsdServerUpName                = "backend.server.up"
+	statsdInvocationDurationName      = "backend.invocation.duration"
+	statsdTLSVerificationFailuresName = "backend.tls.verification.failures.total"
+	statsdDrainedRequestsName         = "backend.drained.requests.total"
+	statsdDialFallbacksName           = "backend.dial.fallbacks.total"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -40,19 +56,35 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 	}
 
 	return &standardRegistry{
-		enabled:                        true,
-		configReloadsCounter:           statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
-		configReloadsFailureCounter:    statsdClient.NewCounter(statsdConfigReloadsFailureName, 1.0),
-		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
-		lastConfigReloadFailureGauge:   statsdClient.NewGauge(statsdLastConfigReloadFailureName),
-		entrypointReqsCounter:          statsdClient.NewCounter(statsdEntrypointReqsName, 1.0),
-		entrypointReqDurationHistogram: statsdClient.NewTiming(statsdEntrypointReqDurationName, 1.0),
-		entrypointOpenConnsGauge:       statsdClient.NewGauge(statsdEntrypointOpenConnsName),
-		backendReqsCounter:             statsdClient.NewCounter(statsdMetricsBackendReqsName, 1.0),
-		backendReqDurationHistogram:    statsdClient.NewTiming(statsdMetricsBackendLatencyName, 1.0),
-		backendRetriesCounter:          statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
-		backendOpenConnsGauge:          statsdClient.NewGauge(statsdOpenConnsName),
-		backendServerUpGauge:           statsdClient.NewGauge(statsdServerUpName),
+		enabled:                               true,
+		configReloadsCounter:                  statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
+		configReloadsFailureCounter:           statsdClient.NewCounter(statsdConfigReloadsFailureName, 1.0),
+		lastConfigReloadSuccessGauge:          statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
+		lastConfigReloadFailureGauge:          statsdClient.NewGauge(statsdLastConfigReloadFailureName),
+		configReloadDurationHistogram:         statsdClient.NewTiming(statsdConfigReloadDurationName, 1.0),
+		readyGauge:                            statsdClient.NewGauge(statsdReadyName),
+		experimentExposuresCounter:            statsdClient.NewCounter(statsdExperimentExposuresName, 1.0),
+		graphQLOperationsCounter:              statsdClient.NewCounter(statsdGraphQLOperationsName, 1.0),
+		routerSLOBurnRateGauge:                statsdClient.NewGauge(statsdRouterSLOBurnRateName),
+		accessLogDroppedCounter:               statsdClient.NewCounter(statsdAccessLogDroppedName, 1.0),
+		cacheEvictionsCounter:                 statsdClient.NewCounter(statsdCacheEvictionsName, 1.0),
+		goroutinesGauge:                       statsdClient.NewGauge(statsdGoroutinesName),
+		fileDescriptorsGauge:                  statsdClient.NewGauge(statsdFileDescriptorsName),
+		entrypointReqsCounter:                 statsdClient.NewCounter(statsdEntrypointReqsName, 1.0),
+		entrypointReqDurationHistogram:        statsdClient.NewTiming(statsdEntrypointReqDurationName, 1.0),
+		entrypointOpenConnsGauge:              statsdClient.NewGauge(statsdEntrypointOpenConnsName),
+		entrypointSaturationGauge:             statsdClient.NewGauge(statsdEntrypointSaturationName),
+		entrypointLimitRejectsCounter:         statsdClient.NewCounter(statsdEntrypointLimitRejectsName, 1.0),
+		entrypointShardConnsCounter:           statsdClient.NewCounter(statsdEntrypointShardConnsName, 1.0),
+		backendReqsCounter:                    statsdClient.NewCounter(statsdMetricsBackendReqsName, 1.0),
+		backendReqDurationHistogram:           statsdClient.NewTiming(statsdMetricsBackendLatencyName, 1.0),
+		backendRetriesCounter:                 statsdClient.NewCounter(statsdRetriesTotalName, 1.0),
+		backendOpenConnsGauge:                 statsdClient.NewGauge(statsdOpenConnsName),
+		backendServerUpGauge:                  statsdClient.NewGauge(statsdServerUpName),
+		backendInvocationDurationHistogram:    statsdClient.NewTiming(statsdInvocationDurationName, 1.0),
+		backendTLSVerificationFailuresCounter: statsdClient.NewCounter(statsdTLSVerificationFailuresName, 1.0),
+		backendDrainedRequestsCounter:         statsdClient.NewCounter(statsdDrainedRequestsName, 1.0),
+		backendDialFallbacksCounter:           statsdClient.NewCounter(statsdDialFallbacksName, 1.0),
 	}
 }
 
@@ -0,0 +1,124 @@
+package accesslog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/types"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultBufferSize = 1000
+
+// Buffer keeps the last access logs in memory, in a ring.
+// A nil Buffer keeps nothing.
+type Buffer struct {
+	mu      sync.RWMutex
+	entries []logrus.Fields
+	next    int
+	full    bool
+}
+
+// BufferFilter selects the access logs returned by a Buffer.
+type BufferFilter struct {
+	// RouterName keeps the access logs of a router, all of them when empty.
+	RouterName string
+	// StatusCodes keeps the access logs with a downstream status in the ranges, all of them when empty.
+	StatusCodes types.HTTPCodeRanges
+	// Limit is the maximum number of access logs returned, all of them when not positive.
+	Limit int
+}
+
+// NewBuffer creates a Buffer, nil if the configuration is nil.
+func NewBuffer(config *types.AccessLogBuffer) *Buffer {
+	if config == nil {
+		return nil
+	}
+
+	size := config.Size
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+
+	return &Buffer{entries: make([]logrus.Fields, size)}
+}
+
+// Add adds an access log to the buffer, replacing the oldest one when the buffer is full.
+func (b *Buffer) Add(fields logrus.Fields) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries[b.next] = fields
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Entries returns the access logs selected by the filter, from the newest to the oldest.
+func (b *Buffer) Entries(filter BufferFilter) []logrus.Fields {
+	result := []logrus.Fields{}
+	if b == nil {
+		return result
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	count := b.next
+	if b.full {
+		count = len(b.entries)
+	}
+
+	for i := 1; i <= count; i++ {
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+
+		fields := b.entries[(b.next-i+len(b.entries))%len(b.entries)]
+
+		if len(filter.RouterName) > 0 && fields[RouterName] != filter.RouterName {
+			continue
+		}
+
+		if len(filter.StatusCodes) > 0 {
+			status, ok := fields[DownstreamStatus].(int)
+			if !ok || !filter.StatusCodes.Contains(status) {
+				continue
+			}
+		}
+
+		result = append(result, fields)
+	}
+
+	return result
+}
+
+// ParseStatusFilter parses a comma separated list of status codes (404), ranges (500-504) and classes (5xx).
+func ParseStatusFilter(value string) (types.HTTPCodeRanges, error) {
+	var blocks []string
+	for _, block := range strings.Split(value, ",") {
+		block = strings.TrimSpace(block)
+		if len(block) == 0 {
+			continue
+		}
+
+		if len(block) == 3 && strings.HasSuffix(strings.ToLower(block), "xx") {
+			class, err := strconv.Atoi(block[:1])
+			if err != nil || class < 1 || class > 5 {
+				return nil, fmt.Errorf("invalid status class %q", block)
+			}
+			block = fmt.Sprintf("%d00-%d99", class, class)
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return types.NewHTTPCodeRanges(blocks)
+}
@@ -0,0 +1,83 @@
+package accesslog
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferRing(t *testing.T) {
+	buffer := NewBuffer(&types.AccessLogBuffer{Size: 3})
+
+	for i := 1; i <= 5; i++ {
+		buffer.Add(logrus.Fields{RequestCount: i})
+	}
+
+	var counts []int
+	for _, fields := range buffer.Entries(BufferFilter{}) {
+		counts = append(counts, fields[RequestCount].(int))
+	}
+	assert.Equal(t, []int{5, 4, 3}, counts)
+}
+
+func TestBufferNil(t *testing.T) {
+	var buffer *Buffer
+	buffer.Add(logrus.Fields{RequestCount: 1})
+	assert.Empty(t, buffer.Entries(BufferFilter{}))
+
+	assert.Nil(t, NewBuffer(nil))
+	assert.Len(t, NewBuffer(&types.AccessLogBuffer{}).entries, defaultBufferSize)
+}
+
+func TestParseStatusFilter(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		value         string
+		expected      types.HTTPCodeRanges
+		expectedError bool
+	}{
+		{
+			desc:     "code",
+			value:    "404",
+			expected: types.HTTPCodeRanges{{404, 404}},
+		},
+		{
+			desc:     "range",
+			value:    "500-504",
+			expected: types.HTTPCodeRanges{{500, 504}},
+		},
+		{
+			desc:     "classes",
+			value:    "4xx, 5XX",
+			expected: types.HTTPCodeRanges{{400, 499}, {500, 599}},
+		},
+		{
+			desc:          "invalid class",
+			value:         "6xx",
+			expectedError: true,
+		},
+		{
+			desc:          "invalid code",
+			value:         "foo",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ranges, err := ParseStatusFilter(test.value)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, ranges)
+		})
+	}
+}
@@ -52,8 +52,10 @@ func AddServiceFields(rw http.ResponseWriter, req *http.Request, next http.Handl
 	// use UTC to handle switchover of daylight saving correctly
 	data.Core[OriginDuration] = time.Now().UTC().Sub(start)
 	data.Core[OriginStatus] = crw.Status()
-	// make copy of headers so we can ensure there is no subsequent mutation during response processing
-	data.OriginResponse = make(http.Header)
-	utils.CopyHeaders(data.OriginResponse, crw.Header())
+	if !data.dropHeaders {
+		// make copy of headers so we can ensure there is no subsequent mutation during response processing
+		data.OriginResponse = make(http.Header, len(crw.Header()))
+		utils.CopyHeaders(data.OriginResponse, crw.Header())
+	}
 	data.Core[OriginContentSize] = crw.Size()
 }
@@ -30,6 +30,8 @@ const (
 	ClientPort = "ClientPort"
 	// ClientUsername is the map key used for the username provided in the URL, if present.
 	ClientUsername = "ClientUsername"
+	// ClientAuthMechanism is the map key used for the name of the middleware which authenticated the request, among the ones of an AnyAuth middleware.
+	ClientAuthMechanism = "ClientAuthMechanism"
 	// RequestAddr is the map key used for the HTTP Host header (usually IP:port). This is treated as not a header by the Go API.
 	RequestAddr = "RequestAddr"
 	// RequestHost is the map key used for the HTTP Host server name (not including port).
@@ -68,6 +70,24 @@ const (
 	Overhead = "Overhead"
 	// RetryAttempts is the map key used for the amount of attempts the request was retried.
 	RetryAttempts = "RetryAttempts"
+	// RequestID is the map key used for the unique ID assigned to the request by the request ID middleware.
+	RequestID = "RequestID"
+	// TLSVersion is the map key used for the TLS version negotiated with the client.
+	TLSVersion = "TLSVersion"
+	// TLSCipher is the map key used for the TLS cipher suite negotiated with the client.
+	TLSCipher = "TLSCipher"
+	// TLSServerName is the map key used for the server name requested by the client through SNI.
+	TLSServerName = "TLSServerName"
+	// QueueDuration is the map key used for the time spent waiting for the request limits of the entry points.
+	QueueDuration = "QueueDuration"
+	// MiddlewareDuration is the map key used for the time spent in the routers and middlewares before forwarding the request.
+	MiddlewareDuration = "MiddlewareDuration"
+	// OriginConnectDuration is the map key used for the time taken to get a connection to the origin server.
+	OriginConnectDuration = "OriginConnectDuration"
+	// OriginTTFB is the map key used for the time between the connection to the origin server and the first byte of its response.
+	OriginTTFB = "OriginTTFB"
+	// OriginTransferDuration is the map key used for the time taken to forward the response of the origin server to the client.
+	OriginTransferDuration = "OriginTransferDuration"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
@@ -103,11 +123,21 @@ func init() {
 	}
 	allCoreKeys[ServiceAddr] = struct{}{}
 	allCoreKeys[ClientAddr] = struct{}{}
+	allCoreKeys[ClientAuthMechanism] = struct{}{}
 	allCoreKeys[RequestAddr] = struct{}{}
 	allCoreKeys[GzipRatio] = struct{}{}
 	allCoreKeys[StartLocal] = struct{}{}
 	allCoreKeys[Overhead] = struct{}{}
 	allCoreKeys[RetryAttempts] = struct{}{}
+	allCoreKeys[RequestID] = struct{}{}
+	allCoreKeys[TLSVersion] = struct{}{}
+	allCoreKeys[TLSCipher] = struct{}{}
+	allCoreKeys[TLSServerName] = struct{}{}
+	allCoreKeys[QueueDuration] = struct{}{}
+	allCoreKeys[MiddlewareDuration] = struct{}{}
+	allCoreKeys[OriginConnectDuration] = struct{}{}
+	allCoreKeys[OriginTTFB] = struct{}{}
+	allCoreKeys[OriginTransferDuration] = struct{}{}
 }
 
 // CoreLogData holds the fields computed from the request/response.
@@ -119,4 +149,8 @@ type LogData struct {
 	Request            http.Header
 	OriginResponse     http.Header
 	DownstreamResponse http.Header
+
+	// dropHeaders is true when none of the headers are logged,
+	// so that they do not need to be copied.
+	dropHeaders bool
 }
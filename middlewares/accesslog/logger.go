@@ -2,7 +2,9 @@ package accesslog
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -15,6 +17,9 @@ import (
 	"github.com/containous/alice"
 	"github.com/containous/flaeg/parse"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
+	"github.com/containous/traefik/middlewares/servertiming"
+	traefiktls "github.com/containous/traefik/tls"
 	"github.com/containous/traefik/types"
 	"github.com/sirupsen/logrus"
 )
@@ -30,6 +35,9 @@ const (
 
 	// JSONFormat is the JSON logging format.
 	JSONFormat string = "json"
+
+	// LogfmtFormat is the logfmt logging format.
+	LogfmtFormat string = "logfmt"
 )
 
 type handlerParams struct {
@@ -40,13 +48,21 @@ type handlerParams struct {
 
 // Handler will write each request and its response to the access log.
 type Handler struct {
-	config         *types.AccessLog
-	logger         *logrus.Logger
-	file           *os.File
-	mu             sync.Mutex
-	httpCodeRanges types.HTTPCodeRanges
-	logHandlerChan chan handlerParams
-	wg             sync.WaitGroup
+	config          *types.AccessLog
+	logger          *logrus.Logger
+	sinks           multiSink
+	buffer          *Buffer
+	mu              sync.Mutex
+	httpCodeRanges  types.HTTPCodeRanges
+	excludedRouters map[string]struct{}
+	logHandlerChan  chan handlerParams
+	wg              sync.WaitGroup
+	dropped         int64
+
+	dropHeaders            bool
+	requestHeaderFields    *headerFieldNames
+	originHeaderFields     *headerFieldNames
+	downstreamHeaderFields *headerFieldNames
 }
 
 // WrapHandler Wraps access log handler into an Alice Constructor.
@@ -60,16 +76,6 @@ func WrapHandler(handler *Handler) alice.Constructor {
 
 // NewHandler creates a new Handler.
 func NewHandler(config *types.AccessLog) (*Handler, error) {
-	file := os.Stdout
-	if len(config.FilePath) > 0 {
-		f, err := openAccessLogFile(config.FilePath)
-		if err != nil {
-			return nil, fmt.Errorf("error opening access log file: %s", err)
-		}
-		file = f
-	}
-	logHandlerChan := make(chan handlerParams, config.BufferingSize)
-
 	var formatter logrus.Formatter
 
 	switch config.Format {
@@ -77,12 +83,21 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		formatter = new(CommonLogFormatter)
 	case JSONFormat:
 		formatter = new(logrus.JSONFormatter)
+	case LogfmtFormat:
+		formatter = new(LogfmtFormatter)
 	default:
 		return nil, fmt.Errorf("unsupported access log format: %s", config.Format)
 	}
 
+	sinks, err := newSinks(config)
+	if err != nil {
+		return nil, err
+	}
+
+	logHandlerChan := make(chan handlerParams, config.BufferingSize)
+
 	logger := &logrus.Logger{
-		Out:       file,
+		Out:       multiSink(sinks),
 		Formatter: formatter,
 		Hooks:     make(logrus.LevelHooks),
 		Level:     logrus.InfoLevel,
@@ -91,8 +106,14 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 	logHandler := &Handler{
 		config:         config,
 		logger:         logger,
-		file:           file,
+		sinks:          sinks,
+		buffer:         NewBuffer(config.Buffer),
 		logHandlerChan: logHandlerChan,
+
+		dropHeaders:            config.Fields.DropsAllHeaders(),
+		requestHeaderFields:    newHeaderFieldNames("request_"),
+		originHeaderFields:     newHeaderFieldNames("origin_"),
+		downstreamHeaderFields: newHeaderFieldNames("downstream_"),
 	}
 
 	if config.Filters != nil {
@@ -101,6 +122,13 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		} else {
 			logHandler.httpCodeRanges = httpCodeRanges
 		}
+
+		if len(config.Filters.ExcludedRouters) > 0 {
+			logHandler.excludedRouters = make(map[string]struct{})
+			for _, routerName := range config.Filters.ExcludedRouters {
+				logHandler.excludedRouters[routerName] = struct{}{}
+			}
+		}
 	}
 
 	if config.BufferingSize > 0 {
@@ -116,6 +144,11 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 	return logHandler, nil
 }
 
+// Buffer returns the in-memory buffer of the last access logs, nil if it is disabled.
+func (h *Handler) Buffer() *Buffer {
+	return h.buffer
+}
+
 func openAccessLogFile(filePath string) (*os.File, error) {
 	dir := filepath.Dir(filePath)
 
@@ -140,6 +173,14 @@ func GetLogData(req *http.Request) *LogData {
 	return nil
 }
 
+// SetField adds a field to the logging data of the request, if the access log is enabled.
+// It allows any middleware of the chain to contribute to the access log.
+func SetField(req *http.Request, key string, value interface{}) {
+	if ld := GetLogData(req); ld != nil {
+		ld.Core[key] = value
+	}
+}
+
 func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
 	now := time.Now().UTC()
 
@@ -148,7 +189,7 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 		StartLocal: now.Local(),
 	}
 
-	logDataTable := &LogData{Core: core, Request: req.Header}
+	logDataTable := &LogData{Core: core, Request: req.Header, dropHeaders: h.dropHeaders}
 
 	reqWithDataTable := req.WithContext(context.WithValue(req.Context(), DataTableKey, logDataTable))
 
@@ -179,53 +220,100 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 	core[ClientAddr] = req.RemoteAddr
 	core[ClientHost], core[ClientPort] = silentSplitHostPort(req.RemoteAddr)
 
-	if forwardedFor := req.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+	if clientIP, ok := forwardedheaders.ClientIP(req.Context()); ok {
+		core[ClientHost] = clientIP
+	} else if forwardedFor := req.Header.Get("X-Forwarded-For"); forwardedFor != "" {
 		core[ClientHost] = forwardedFor
 	}
 
+	if req.TLS != nil {
+		core[TLSVersion] = tlsVersionName(req.TLS.Version)
+		core[TLSCipher] = tlsCipherName(req.TLS.CipherSuite)
+		if req.TLS.ServerName != "" {
+			core[TLSServerName] = req.TLS.ServerName
+		}
+	}
+
 	crw := &captureResponseWriter{rw: rw}
 
 	next.ServeHTTP(crw, reqWithDataTable)
 
-	core[ClientUsername] = usernameIfPresent(reqWithDataTable.URL)
+	// the duration is measured here, as the access log line can wait in the buffering queue before being processed.
+	// n.b. take care to perform time arithmetic using UTC to avoid errors at DST boundaries.
+	core[Duration] = time.Now().UTC().Sub(core[StartUTC].(time.Time))
+
+	// the username can already have been set by an authentication middleware
+	if _, ok := core[ClientUsername]; !ok {
+		core[ClientUsername] = usernameIfPresent(reqWithDataTable.URL)
+	}
 
 	logDataTable.DownstreamResponse = crw.Header()
 
+	if timing := servertiming.FromContext(req.Context()); timing != nil {
+		addTimingFields(core, timing.Durations())
+	}
+
 	if h.config.BufferingSize > 0 {
-		h.logHandlerChan <- handlerParams{
+		params := handlerParams{
 			logDataTable: logDataTable,
 			crr:          crr,
 			crw:          crw,
 		}
+
+		if !h.config.DropWhenFull {
+			h.logHandlerChan <- params
+			return
+		}
+
+		select {
+		case h.logHandlerChan <- params:
+		default:
+			atomic.AddInt64(&h.dropped, 1)
+		}
 	} else {
 		h.logTheRoundTrip(logDataTable, crr, crw)
 	}
 }
 
-// Close closes the Logger (i.e. the file, drain logHandlerChan, etc).
+// addTimingFields adds the breakdown of the processing time of a request, timed by its entry point.
+func addTimingFields(core CoreLogData, durations servertiming.Durations) {
+	core[QueueDuration] = durations.Queue
+	core[MiddlewareDuration] = durations.Middleware
+	if durations.Forwarded {
+		core[OriginConnectDuration] = durations.Connect
+		core[OriginTTFB] = durations.TTFB
+		core[OriginTransferDuration] = durations.Transfer
+	}
+}
+
+// Close closes the Logger (i.e. the sinks, drain logHandlerChan, etc).
 func (h *Handler) Close() error {
 	close(h.logHandlerChan)
 	h.wg.Wait()
-	return h.file.Close()
+	return h.sinks.Close()
 }
 
-// Rotate closes and reopens the log file to allow for rotation by an external source.
-func (h *Handler) Rotate() error {
-	var err error
-
-	if h.file != nil {
-		defer func(f *os.File) {
-			f.Close()
-		}(h.file)
+// TakeDropped returns the number of access log lines dropped because the buffering queue, or the queue of a sink, was full,
+// since the previous call.
+func (h *Handler) TakeDropped() int64 {
+	dropped := atomic.SwapInt64(&h.dropped, 0)
+	for _, s := range h.sinks {
+		if d, ok := s.(dropper); ok {
+			dropped += d.takeDropped()
+		}
 	}
+	return dropped
+}
 
-	h.file, err = os.OpenFile(h.config.FilePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0664)
-	if err != nil {
-		return err
+// Rotate closes and reopens the log file to allow for rotation by an external source.
+func (h *Handler) Rotate() error {
+	for _, s := range h.sinks {
+		if f, ok := s.(*fileSink); ok {
+			if err := f.Reopen(); err != nil {
+				return err
+			}
+		}
 	}
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	h.logger.Out = h.file
 	return nil
 }
 
@@ -237,6 +325,32 @@ func silentSplitHostPort(value string) (host string, port string) {
 	return host, port
 }
 
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionSSL30:
+		return "SSL3.0"
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case 0x0304: // tls.VersionTLS13, not defined before Go 1.12
+		return "1.3"
+	default:
+		return fmt.Sprintf("0x%04x", version)
+	}
+}
+
+func tlsCipherName(cipherSuite uint16) string {
+	for name, id := range traefiktls.CipherSuites {
+		if id == cipherSuite {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04x", cipherSuite)
+}
+
 func usernameIfPresent(theURL *url.URL) string {
 	if theURL.User != nil {
 		if name := theURL.User.Username(); name != "" {
@@ -262,11 +376,11 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData, crr *captureRequestRead
 
 	core[DownstreamStatus] = crw.Status()
 
-	// n.b. take care to perform time arithmetic using UTC to avoid errors at DST boundaries.
-	totalDuration := time.Now().UTC().Sub(core[StartUTC].(time.Time))
-	core[Duration] = totalDuration
+	totalDuration := core[Duration].(time.Duration)
+
+	routerName, _ := core[RouterName].(string)
 
-	if h.keepAccessLog(crw.Status(), retryAttempts, totalDuration) {
+	if h.keepAccessLog(routerName, crw.Status(), retryAttempts, totalDuration) {
 		core[DownstreamContentSize] = crw.Size()
 		if original, ok := core[OriginContentSize]; ok {
 			o64 := original.(int64)
@@ -280,7 +394,15 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData, crr *captureRequestRead
 			core[Overhead] = totalDuration - origin.(time.Duration)
 		}
 
-		fields := logrus.Fields{}
+		size := len(h.config.StaticFields) + len(logDataTable.Core)
+		if !h.dropHeaders {
+			size += len(logDataTable.Request) + len(logDataTable.OriginResponse) + len(logDataTable.DownstreamResponse)
+		}
+		fields := make(logrus.Fields, size)
+
+		for k, v := range h.config.StaticFields {
+			fields[k] = v
+		}
 
 		for k, v := range logDataTable.Core {
 			if h.config.Fields.Keep(k) {
@@ -288,9 +410,13 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData, crr *captureRequestRead
 			}
 		}
 
-		h.redactHeaders(logDataTable.Request, fields, "request_")
-		h.redactHeaders(logDataTable.OriginResponse, fields, "origin_")
-		h.redactHeaders(logDataTable.DownstreamResponse, fields, "downstream_")
+		if !h.dropHeaders {
+			h.redactHeaders(logDataTable.Request, fields, h.requestHeaderFields)
+			h.redactHeaders(logDataTable.OriginResponse, fields, h.originHeaderFields)
+			h.redactHeaders(logDataTable.DownstreamResponse, fields, h.downstreamHeaderFields)
+		}
+
+		h.buffer.Add(fields)
 
 		h.mu.Lock()
 		defer h.mu.Unlock()
@@ -298,25 +424,74 @@ func (h *Handler) logTheRoundTrip(logDataTable *LogData, crr *captureRequestRead
 	}
 }
 
-func (h *Handler) redactHeaders(headers http.Header, fields logrus.Fields, prefix string) {
-	for k := range headers {
+func (h *Handler) redactHeaders(headers http.Header, fields logrus.Fields, names *headerFieldNames) {
+	for k, values := range headers {
 		v := h.config.Fields.KeepHeader(k)
 		if v == types.AccessLogKeep {
-			fields[prefix+k] = headers.Get(k)
+			var value string
+			if len(values) > 0 {
+				value = values[0]
+			}
+			fields[names.get(k)] = value
 		} else if v == types.AccessLogRedact {
-			fields[prefix+k] = "REDACTED"
+			fields[names.get(k)] = "REDACTED"
 		}
 	}
 }
 
-func (h *Handler) keepAccessLog(statusCode, retryAttempts int, duration time.Duration) bool {
+// maxHeaderFieldNames bounds the number of interned names per headerFieldNames,
+// as the header names are chosen by the clients and the servers.
+const maxHeaderFieldNames = 1024
+
+// headerFieldNames interns the access log field names of the headers, i.e. the header names with their prefix,
+// so that the prefix is not concatenated again for every logged request.
+type headerFieldNames struct {
+	prefix string
+	mu     sync.RWMutex
+	names  map[string]string
+}
+
+func newHeaderFieldNames(prefix string) *headerFieldNames {
+	return &headerFieldNames{prefix: prefix, names: make(map[string]string)}
+}
+
+func (n *headerFieldNames) get(header string) string {
+	n.mu.RLock()
+	name, ok := n.names[header]
+	n.mu.RUnlock()
+	if ok {
+		return name
+	}
+
+	name = n.prefix + header
+
+	n.mu.Lock()
+	if len(n.names) < maxHeaderFieldNames {
+		n.names[header] = name
+	}
+	n.mu.Unlock()
+
+	return name
+}
+
+func (h *Handler) keepAccessLog(routerName string, statusCode, retryAttempts int, duration time.Duration) bool {
 	if h.config.Filters == nil {
 		// no filters were specified
 		return true
 	}
 
+	if _, excluded := h.excludedRouters[routerName]; excluded {
+		return false
+	}
+
+	// the successful access logs kept by the other filters are sampled as well
+	return h.matchKeepFilters(statusCode, retryAttempts, duration) && h.sampleSuccess(statusCode)
+}
+
+// matchKeepFilters reports whether an access log matches one of the keep filters, or whether none is specified.
+func (h *Handler) matchKeepFilters(statusCode, retryAttempts int, duration time.Duration) bool {
 	if len(h.httpCodeRanges) == 0 && !h.config.Filters.RetryAttempts && h.config.Filters.MinDuration == 0 {
-		// empty filters were specified, e.g. by passing --accessLog.filters only (without other filter options)
+		// no keep filters were specified, e.g. by passing --accessLog.filters only (without other filter options)
 		return true
 	}
 
@@ -335,6 +510,16 @@ func (h *Handler) keepAccessLog(statusCode, retryAttempts int, duration time.Dur
 	return false
 }
 
+// sampleSuccess keeps only a ratio of the successful access logs, when the sampling is enabled.
+func (h *Handler) sampleSuccess(statusCode int) bool {
+	rate := h.config.Filters.SuccessSampling
+	if rate <= 0 || rate >= 1 || statusCode >= http.StatusBadRequest {
+		return true
+	}
+
+	return rand.Float64() < rate
+}
+
 var requestCounter uint64 // Request ID
 
 func nextRequestCount() uint64 {
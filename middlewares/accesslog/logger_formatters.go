@@ -3,8 +3,10 @@ package accesslog
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/go-logfmt/logfmt"
 	"github.com/sirupsen/logrus"
 )
 
@@ -50,6 +52,42 @@ func (f *CommonLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	return b.Bytes(), err
 }
 
+// LogfmtFormatter provides formatting in the logfmt format (key=value pairs).
+type LogfmtFormatter struct{}
+
+// Format formats the log entry in the logfmt format, with the fields sorted by key.
+func (f *LogfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := &bytes.Buffer{}
+	enc := logfmt.NewEncoder(b)
+
+	for _, k := range keys {
+		var value interface{}
+		switch v := entry.Data[k].(type) {
+		case time.Time:
+			value = v.Format(time.RFC3339Nano)
+		case time.Duration:
+			value = v.Nanoseconds()
+		default:
+			value = v
+		}
+
+		if err := enc.EncodeKeyval(k, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.EndRecord(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
 func toLog(fields logrus.Fields, key string, defaultValue string, quoted bool) interface{} {
 	if v, ok := fields[key]; ok {
 		if v == nil {
@@ -1,6 +1,7 @@
 package accesslog
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/middlewares/servertiming"
 	"github.com/containous/traefik/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -305,6 +307,33 @@ func TestLoggerJSON(t *testing.T) {
 				RequestRefererHeader: assertString(testReferer),
 			},
 		},
+		{
+			desc: "static fields",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   JSONFormat,
+				Fields: &types.AccessLogFields{
+					DefaultMode: "drop",
+					Names: types.FieldNames{
+						RouterName: "keep",
+					},
+					Headers: &types.FieldHeaders{
+						DefaultMode: "drop",
+					},
+				},
+				StaticFields: map[string]string{
+					"env":      "production",
+					RouterName: "overridden",
+				},
+			},
+			expected: map[string]func(t *testing.T, value interface{}){
+				"env":      assertString("production"),
+				RouterName: assertString(testRouterName),
+				"level":    assertString("info"),
+				"msg":      assertString(""),
+				"time":     assertNotEmpty(),
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -336,6 +365,41 @@ func TestLoggerJSON(t *testing.T) {
 	}
 }
 
+func TestLoggerLogfmt(t *testing.T) {
+	tmpDir := createTempDir(t, LogfmtFormat)
+	defer os.RemoveAll(tmpDir)
+
+	logFilePath := filepath.Join(tmpDir, logFileNameSuffix)
+	config := &types.AccessLog{
+		FilePath: logFilePath,
+		Format:   LogfmtFormat,
+		Fields: &types.AccessLogFields{
+			DefaultMode: "drop",
+			Names: types.FieldNames{
+				ClientUsername: "keep",
+				RequestMethod:  "keep",
+				RouterName:     "keep",
+			},
+			Headers: &types.FieldHeaders{
+				DefaultMode: "drop",
+				Names: types.FieldHeaderNames{
+					"User-Agent": "keep",
+				},
+			},
+		},
+		StaticFields: map[string]string{
+			"env": "production",
+		},
+	}
+	doLogging(t, config)
+
+	logData, err := ioutil.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	expected := `ClientUsername=TestUser RequestMethod=POST RouterName=testRouter env=production request_User-Agent=testUserAgent` + "\n"
+	assert.Equal(t, expected, string(logData))
+}
+
 func TestNewLogHandlerOutputStdout(t *testing.T) {
 	testCases := []struct {
 		desc        string
@@ -414,6 +478,40 @@ func TestNewLogHandlerOutputStdout(t *testing.T) {
 			},
 			expectedLog: `TestHost - TestUser [13/Apr/2016:07:14:19 -0700] "POST testpath HTTP/0.0" 123 12 "testReferer" "testUserAgent" 23 "testRouter" "http://127.0.0.1/testService" 1ms`,
 		},
+		{
+			desc: "Excluded router",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Filters: &types.AccessLogFilters{
+					ExcludedRouters: []string{testRouterName},
+				},
+			},
+			expectedLog: ``,
+		},
+		{
+			desc: "Excluded router not matching",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Filters: &types.AccessLogFilters{
+					ExcludedRouters: []string{"otherRouter"},
+				},
+			},
+			expectedLog: `TestHost - TestUser [13/Apr/2016:07:14:19 -0700] "POST testpath HTTP/0.0" 123 12 "testReferer" "testUserAgent" 23 "testRouter" "http://127.0.0.1/testService" 1ms`,
+		},
+		{
+			desc: "Excluded router takes precedence over keep filters",
+			config: &types.AccessLog{
+				FilePath: "",
+				Format:   CommonFormat,
+				Filters: &types.AccessLogFilters{
+					StatusCodes:     []string{"123"},
+					ExcludedRouters: []string{testRouterName},
+				},
+			},
+			expectedLog: ``,
+		},
 		{
 			desc: "Default mode keep",
 			config: &types.AccessLog{
@@ -647,3 +745,298 @@ func logWriterTestHandlerFunc(rw http.ResponseWriter, r *http.Request) {
 
 	rw.WriteHeader(testStatus)
 }
+
+func TestSampleSuccess(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		rate        float64
+		statusCodes types.StatusCodes
+		statusCode  int
+		expected    bool
+	}{
+		{
+			desc:       "sampling disabled",
+			rate:       0,
+			statusCode: http.StatusOK,
+			expected:   true,
+		},
+		{
+			desc:       "full sampling",
+			rate:       1,
+			statusCode: http.StatusOK,
+			expected:   true,
+		},
+		{
+			desc:       "successful request sampled out",
+			rate:       1e-12,
+			statusCode: http.StatusOK,
+			expected:   false,
+		},
+		{
+			desc:       "failed request never sampled out",
+			rate:       1e-12,
+			statusCode: http.StatusBadGateway,
+			expected:   true,
+		},
+		{
+			desc:        "successful request kept by the status codes filter sampled out",
+			rate:        1e-12,
+			statusCodes: types.StatusCodes{"200-299"},
+			statusCode:  http.StatusOK,
+			expected:    false,
+		},
+		{
+			desc:        "successful request dropped by the status codes filter",
+			rate:        1,
+			statusCodes: types.StatusCodes{"500-599"},
+			statusCode:  http.StatusOK,
+			expected:    false,
+		},
+		{
+			desc:        "failed request kept by the status codes filter never sampled out",
+			rate:        1e-12,
+			statusCodes: types.StatusCodes{"500-599"},
+			statusCode:  http.StatusBadGateway,
+			expected:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			httpCodeRanges, err := types.NewHTTPCodeRanges(test.statusCodes)
+			require.NoError(t, err)
+
+			handler := &Handler{
+				config: &types.AccessLog{
+					Filters: &types.AccessLogFilters{SuccessSampling: test.rate, StatusCodes: test.statusCodes},
+				},
+				httpCodeRanges: httpCodeRanges,
+			}
+
+			assert.Equal(t, test.expected, handler.keepAccessLog(testRouterName, test.statusCode, 0, time.Millisecond))
+		})
+	}
+}
+
+func TestLoggerTLSAndContributedFields(t *testing.T) {
+	tmpDir := createTempDir(t, JSONFormat)
+	defer os.RemoveAll(tmpDir)
+
+	logFilePath := filepath.Join(tmpDir, logFileNameSuffix)
+	config := &types.AccessLog{
+		FilePath: logFilePath,
+		Format:   JSONFormat,
+		Fields: &types.AccessLogFields{
+			DefaultMode: "drop",
+			Names: types.FieldNames{
+				TLSVersion:     "keep",
+				TLSCipher:      "keep",
+				TLSServerName:  "keep",
+				ClientUsername: "keep",
+			},
+			Headers: &types.FieldHeaders{
+				DefaultMode: "drop",
+			},
+		},
+	}
+
+	logger, err := NewHandler(config)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	req.TLS = &tls.ConnectionState{
+		Version:     tls.VersionTLS12,
+		CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		ServerName:  "example.com",
+	}
+
+	logger.ServeHTTP(httptest.NewRecorder(), req, func(rw http.ResponseWriter, req *http.Request) {
+		SetField(req, ClientUsername, "subject")
+	})
+	require.NoError(t, logger.Close())
+
+	logData, err := ioutil.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	jsonData := make(map[string]interface{})
+	err = json.Unmarshal(logData, &jsonData)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.2", jsonData[TLSVersion])
+	assert.Equal(t, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", jsonData[TLSCipher])
+	assert.Equal(t, "example.com", jsonData[TLSServerName])
+	assert.Equal(t, "subject", jsonData[ClientUsername])
+}
+
+func TestLoggerTimingFields(t *testing.T) {
+	tmpDir := createTempDir(t, JSONFormat)
+	defer os.RemoveAll(tmpDir)
+
+	logFilePath := filepath.Join(tmpDir, logFileNameSuffix)
+	config := &types.AccessLog{
+		FilePath: logFilePath,
+		Format:   JSONFormat,
+	}
+
+	logger, err := NewHandler(config)
+	require.NoError(t, err)
+
+	handler := servertiming.Start(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		logger.ServeHTTP(rw, req, func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusNoContent)
+		})
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+	require.NoError(t, logger.Close())
+
+	logData, err := ioutil.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	jsonData := make(map[string]interface{})
+	err = json.Unmarshal(logData, &jsonData)
+	require.NoError(t, err)
+
+	assert.Contains(t, jsonData, QueueDuration)
+	assert.Contains(t, jsonData, MiddlewareDuration)
+	assert.NotContains(t, jsonData, OriginConnectDuration)
+	assert.NotContains(t, jsonData, OriginTTFB)
+	assert.NotContains(t, jsonData, OriginTransferDuration)
+}
+
+func TestLoggerBuffer(t *testing.T) {
+	config := &types.AccessLog{
+		Format: CommonFormat,
+		Buffer: &types.AccessLogBuffer{Size: 10},
+	}
+
+	logger, err := NewHandler(config)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	assert.Empty(t, logger.sinks)
+
+	logger.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil), func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	entries := logger.Buffer().Entries(BufferFilter{})
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/foo", entries[0][RequestPath])
+	assert.Equal(t, http.StatusBadGateway, entries[0][DownstreamStatus])
+}
+
+func TestLoggerDropHeaders(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		fields          *types.AccessLogFields
+		expectedHeaders map[string]interface{}
+	}{
+		{
+			desc: "all headers kept",
+			expectedHeaders: map[string]interface{}{
+				"request_X-Request":   "foo",
+				"origin_X-Origin":     "bar",
+				"downstream_X-Origin": "bar",
+			},
+		},
+		{
+			desc: "all headers dropped",
+			fields: &types.AccessLogFields{
+				Headers: &types.FieldHeaders{DefaultMode: types.AccessLogDrop},
+			},
+			expectedHeaders: map[string]interface{}{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := &types.AccessLog{
+				Format: CommonFormat,
+				Buffer: &types.AccessLogBuffer{Size: 10},
+				Fields: test.fields,
+			}
+
+			logger, err := NewHandler(config)
+			require.NoError(t, err)
+			defer logger.Close()
+
+			var originResponse http.Header
+			origin := NewFieldHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("X-Origin", "bar")
+				rw.WriteHeader(http.StatusOK)
+			}), ServiceName, testServiceName, func(rw http.ResponseWriter, req *http.Request, next http.Handler, data *LogData) {
+				AddServiceFields(rw, req, next, data)
+				originResponse = data.OriginResponse
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil)
+			req.Header.Set("X-Request", "foo")
+
+			logger.ServeHTTP(httptest.NewRecorder(), req, origin.ServeHTTP)
+
+			assert.Equal(t, len(test.expectedHeaders) == 0, originResponse == nil)
+
+			entries := logger.Buffer().Entries(BufferFilter{})
+			require.Len(t, entries, 1)
+
+			headers := make(map[string]interface{})
+			for k, v := range entries[0] {
+				if strings.HasPrefix(k, "request_") || strings.HasPrefix(k, "origin_") || strings.HasPrefix(k, "downstream_") {
+					headers[k] = v
+				}
+			}
+			assert.Equal(t, test.expectedHeaders, headers)
+		})
+	}
+}
+
+func TestHeaderFieldNames(t *testing.T) {
+	names := newHeaderFieldNames("request_")
+
+	assert.Equal(t, "request_X-Foo", names.get("X-Foo"))
+	assert.Equal(t, "request_X-Foo", names.get("X-Foo"))
+	assert.Len(t, names.names, 1)
+
+	for i := 0; i < 2*maxHeaderFieldNames; i++ {
+		header := fmt.Sprintf("X-Foo-%d", i)
+		assert.Equal(t, "request_"+header, names.get(header))
+	}
+	assert.Len(t, names.names, maxHeaderFieldNames)
+}
+
+func TestLoggerDropWhenFull(t *testing.T) {
+	config := &types.AccessLog{
+		Format:        CommonFormat,
+		BufferingSize: 1,
+		DropWhenFull:  true,
+		Buffer:        &types.AccessLogBuffer{Size: 10},
+	}
+
+	logger, err := NewHandler(config)
+	require.NoError(t, err)
+
+	// blocks the processing of the queue, as if the sinks were slow
+	logger.mu.Lock()
+
+	for i := 0; i < 5; i++ {
+		logger.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil), func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		})
+	}
+
+	dropped := logger.TakeDropped()
+	assert.True(t, dropped >= 3, "dropped %d access logs", dropped)
+	assert.Zero(t, logger.TakeDropped())
+
+	logger.mu.Unlock()
+	require.NoError(t, logger.Close())
+
+	assert.Len(t, logger.Buffer().Entries(BufferFilter{}), 5-int(dropped))
+}
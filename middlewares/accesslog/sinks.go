@@ -0,0 +1,534 @@
+package accesslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+)
+
+const (
+	defaultHTTPBatchSize     = 100
+	defaultHTTPFlushInterval = 5 * time.Second
+
+	// httpFlushQueueSize is the number of batches waiting to be posted, beyond which the new batches are dropped.
+	httpFlushQueueSize = 10
+	// kafkaQueueSize is the number of lines waiting to be produced, beyond which the new lines are dropped.
+	kafkaQueueSize = 1024
+)
+
+// errSinkClosed is returned when writing to a closed sink.
+var errSinkClosed = errors.New("access log sink closed")
+
+// sink is a destination of the formatted access log lines.
+type sink interface {
+	io.WriteCloser
+}
+
+// dropper is a sink dropping the lines it can't keep up with, rather than blocking the requests.
+type dropper interface {
+	// takeDropped returns the number of lines dropped since the previous call.
+	takeDropped() int64
+}
+
+// newSinks creates all the sinks enabled in the configuration.
+// Stdout is used when no sink, nor the in-memory buffer, is configured.
+func newSinks(config *types.AccessLog) ([]sink, error) {
+	var sinks []sink
+
+	if len(config.FilePath) > 0 {
+		var maxSize int64
+		var maxBackups int
+		if config.Rotation != nil {
+			maxSize = config.Rotation.MaxSize * 1024 * 1024
+			maxBackups = config.Rotation.MaxBackups
+		}
+
+		s, err := newFileSink(config.FilePath, maxSize, maxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("error opening access log file: %s", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if config.Syslog != nil {
+		s, err := newSyslogSink(config.Syslog)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, fmt.Errorf("error connecting to syslog: %s", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if config.Kafka != nil {
+		s, err := newKafkaSink(config.Kafka)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, fmt.Errorf("error creating Kafka producer: %s", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if config.HTTP != nil {
+		s, err := newHTTPSink(config.HTTP)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+
+	if len(sinks) == 0 && config.Buffer == nil {
+		sinks = append(sinks, stdoutSink{out: os.Stdout})
+	}
+
+	return sinks, nil
+}
+
+func closeSinks(sinks []sink) {
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			log.WithoutContext().Errorf("Error closing access log sink: %v", err)
+		}
+	}
+}
+
+// multiSink writes each access log line to all its sinks.
+// A failing sink does not prevent the others from receiving the line.
+type multiSink []sink
+
+func (m multiSink) Write(p []byte) (int, error) {
+	for _, s := range m {
+		if _, err := s.Write(p); err != nil {
+			log.WithoutContext().Errorf("Error writing access log: %v", err)
+		}
+	}
+	return len(p), nil
+}
+
+func (m multiSink) Close() error {
+	var errs []string
+	for _, s := range m {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error closing access log sinks: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// stdoutSink writes to stdout, which is never closed.
+type stdoutSink struct {
+	out io.Writer
+}
+
+func (s stdoutSink) Write(p []byte) (int, error) {
+	return s.out.Write(p)
+}
+
+func (s stdoutSink) Close() error {
+	return nil
+}
+
+// fileSink writes to a file, and rotates it when it exceeds maxSize bytes.
+type fileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newFileSink(path string, maxSize int64, maxBackups int) (*fileSink, error) {
+	s := &fileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) open() error {
+	file, err := openAccessLogFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *fileSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(p)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+// Reopen closes and reopens the file to allow for rotation by an external source.
+func (s *fileSink) Reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.file
+	if err := s.open(); err != nil {
+		return err
+	}
+	return old.Close()
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// rotate shifts the backups (file.1 becomes file.2, etc.), moves the current file to file.1,
+// and removes the backups exceeding maxBackups.
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+
+	backups, err := s.backups()
+	if err != nil {
+		return err
+	}
+
+	for i := len(backups) - 1; i >= 0; i-- {
+		index := backups[i]
+		if s.maxBackups > 0 && index >= s.maxBackups {
+			if err := os.Remove(s.backupName(index)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.Rename(s.backupName(index), s.backupName(index+1)); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(s.path, s.backupName(1)); err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+// backups returns the sorted indexes of the existing backup files.
+func (s *fileSink) backups() ([]int, error) {
+	matches, err := filepath.Glob(s.path + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []int
+	for _, match := range matches {
+		index, err := strconv.Atoi(strings.TrimPrefix(match, s.path+"."))
+		if err != nil || index <= 0 {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	return indexes, nil
+}
+
+func (s *fileSink) backupName(index int) string {
+	return s.path + "." + strconv.Itoa(index)
+}
+
+// kafkaSink produces each access log line as a message of a Kafka topic.
+// The lines are queued for the producer, and dropped when the queue is full.
+type kafkaSink struct {
+	topic    string
+	producer sarama.AsyncProducer
+	messages chan *sarama.ProducerMessage
+	dropped  int64
+	wg       sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newKafkaSink(config *types.AccessLogKafka) (*kafkaSink, error) {
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("no Kafka broker defined")
+	}
+	if len(config.Topic) == 0 {
+		return nil, fmt.Errorf("no Kafka topic defined")
+	}
+
+	producer, err := sarama.NewAsyncProducer(config.Brokers, sarama.NewConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return newKafkaProducerSink(config.Topic, producer), nil
+}
+
+func newKafkaProducerSink(topic string, producer sarama.AsyncProducer) *kafkaSink {
+	s := &kafkaSink{
+		topic:    topic,
+		producer: producer,
+		messages: make(chan *sarama.ProducerMessage, kafkaQueueSize),
+	}
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		for err := range producer.Errors() {
+			log.WithoutContext().Errorf("Error sending access log to Kafka: %v", err)
+		}
+	}()
+	go func() {
+		defer s.wg.Done()
+		for message := range s.messages {
+			producer.Input() <- message
+		}
+		producer.AsyncClose()
+	}()
+
+	return s
+}
+
+func (s *kafkaSink) Write(p []byte) (int, error) {
+	// The producer sends the message asynchronously, so it needs its own copy of the line.
+	value := make([]byte, len(bytes.TrimSuffix(p, []byte("\n"))))
+	copy(value, p)
+
+	message := &sarama.ProducerMessage{
+		Topic: s.topic,
+		Value: sarama.ByteEncoder(value),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return 0, errSinkClosed
+	}
+
+	select {
+	case s.messages <- message:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+	return len(p), nil
+}
+
+func (s *kafkaSink) takeDropped() int64 {
+	return atomic.SwapInt64(&s.dropped, 0)
+}
+
+// Close produces the queued access log lines, and closes the producer.
+func (s *kafkaSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.messages)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// httpSink posts the access log lines in batches (newline delimited) to an HTTP endpoint.
+// The batches are queued to be posted, and dropped when the queue is full.
+type httpSink struct {
+	endpoint      string
+	headers       map[string]string
+	batchSize     int
+	flushInterval time.Duration
+	client        *http.Client
+	dropped       int64
+
+	mu     sync.Mutex
+	lines  [][]byte
+	closed bool
+
+	flushes chan [][]byte
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newHTTPSink(config *types.AccessLogHTTP) (*httpSink, error) {
+	if len(config.Endpoint) == 0 {
+		return nil, fmt.Errorf("no endpoint defined for the access log HTTP sink")
+	}
+
+	s := &httpSink{
+		endpoint:      config.Endpoint,
+		headers:       config.Headers,
+		batchSize:     config.BatchSize,
+		flushInterval: time.Duration(config.FlushInterval),
+		client:        &http.Client{Timeout: 10 * time.Second},
+		flushes:       make(chan [][]byte, httpFlushQueueSize),
+		done:          make(chan struct{}),
+	}
+
+	if s.batchSize <= 0 {
+		s.batchSize = defaultHTTPBatchSize
+	}
+	if s.flushInterval <= 0 {
+		s.flushInterval = defaultHTTPFlushInterval
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+func (s *httpSink) Write(p []byte) (int, error) {
+	line := make([]byte, len(p))
+	copy(line, p)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, errSinkClosed
+	}
+
+	s.lines = append(s.lines, line)
+	if len(s.lines) < s.batchSize {
+		return len(p), nil
+	}
+
+	select {
+	case s.flushes <- s.lines:
+	default:
+		atomic.AddInt64(&s.dropped, int64(len(s.lines)))
+	}
+	s.lines = nil
+	return len(p), nil
+}
+
+func (s *httpSink) takeDropped() int64 {
+	return atomic.SwapInt64(&s.dropped, 0)
+}
+
+func (s *httpSink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case batch := <-s.flushes:
+			s.send(batch)
+		case <-ticker.C:
+			s.send(s.take())
+		case <-s.done:
+			for {
+				select {
+				case batch := <-s.flushes:
+					s.send(batch)
+				default:
+					s.send(s.take())
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *httpSink) take() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := s.lines
+	s.lines = nil
+	return batch
+}
+
+func (s *httpSink) send(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(bytes.Join(batch, nil)))
+	if err != nil {
+		log.WithoutContext().Errorf("Error creating access log request: %v", err)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.WithoutContext().Errorf("Error sending %d access log lines: %v", len(batch), err)
+		return
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		log.WithoutContext().Errorf("Error sending %d access log lines: unexpected status code %d", len(batch), resp.StatusCode)
+	}
+}
+
+// Close sends the pending access log lines.
+func (s *httpSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+	return nil
+}
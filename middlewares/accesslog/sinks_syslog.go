@@ -0,0 +1,22 @@
+// +build !windows
+
+package accesslog
+
+import (
+	"log/syslog"
+
+	"github.com/containous/traefik/types"
+)
+
+func newSyslogSink(config *types.AccessLogSyslog) (sink, error) {
+	tag := config.Tag
+	if len(tag) == 0 {
+		tag = "traefik"
+	}
+
+	writer, err := syslog.Dial(config.Network, config.Address, syslog.LOG_INFO|syslog.LOG_LOCAL0, tag)
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
@@ -0,0 +1,13 @@
+// +build windows
+
+package accesslog
+
+import (
+	"errors"
+
+	"github.com/containous/traefik/types"
+)
+
+func newSyslogSink(config *types.AccessLogSyslog) (sink, error) {
+	return nil, errors.New("syslog is not supported on Windows")
+}
@@ -0,0 +1,261 @@
+package accesslog
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSinkRotation(t *testing.T) {
+	tmpDir := createTempDir(t, "rotation")
+	defer os.RemoveAll(tmpDir)
+
+	filePath := filepath.Join(tmpDir, "access.log")
+
+	s, err := newFileSink(filePath, 10, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4\n"} {
+		_, err = s.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Close())
+
+	testCases := map[string]string{
+		filePath:        "line 4\n",
+		filePath + ".1": "line 3\n",
+		filePath + ".2": "line 2\n",
+	}
+
+	for name, expected := range testCases {
+		content, err := ioutil.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), name)
+	}
+
+	_, err = os.Stat(filePath + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestHTTPSink(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "application/x-ndjson", req.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", req.Header.Get("X-Token"))
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	s, err := newHTTPSink(&types.AccessLogHTTP{
+		Endpoint:      server.URL,
+		Headers:       map[string]string{"X-Token": "secret"},
+		BatchSize:     2,
+		FlushInterval: parse.Duration(60e9),
+	})
+	require.NoError(t, err)
+
+	for _, line := range []string{"{\"a\":1}\n", "{\"a\":2}\n", "{\"a\":3}\n"} {
+		_, err = s.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	// Close must send the pending line even though the flush interval has not elapsed.
+	require.NoError(t, s.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"{\"a\":1}\n{\"a\":2}\n", "{\"a\":3}\n"}, bodies)
+	assert.Equal(t, 3, strings.Count(strings.Join(bodies, ""), "\n"))
+}
+
+func TestHTTPSinkStalledEndpoint(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	s, err := newHTTPSink(&types.AccessLogHTTP{
+		Endpoint:      server.URL,
+		BatchSize:     1,
+		FlushInterval: parse.Duration(60e9),
+	})
+	require.NoError(t, err)
+
+	// The first batch stalls the endpoint, the next ones fill the queue, the remaining ones are dropped.
+	lines := httpFlushQueueSize + 10
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < lines; i++ {
+			_, err := s.Write([]byte("{\"a\":1}\n"))
+			assert.NoError(t, err)
+		}
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the writes are blocked by the stalled endpoint")
+	}
+
+	dropped := s.takeDropped()
+	assert.True(t, dropped >= int64(lines-httpFlushQueueSize-1), "dropped %d lines", dropped)
+	assert.Zero(t, s.takeDropped())
+
+	close(release)
+	require.NoError(t, s.Close())
+
+	_, err = s.Write([]byte("{\"a\":1}\n"))
+	assert.Equal(t, errSinkClosed, err)
+}
+
+func TestKafkaSinkStalledProducer(t *testing.T) {
+	producer := newStalledProducer()
+	s := newKafkaProducerSink("access-logs", producer)
+
+	// One line is held by the stalled producer, the next ones fill the queue, the remaining ones are dropped.
+	lines := kafkaQueueSize + 10
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < lines; i++ {
+			_, err := s.Write([]byte("{\"a\":1}\n"))
+			assert.NoError(t, err)
+		}
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the writes are blocked by the stalled producer")
+	}
+
+	dropped := s.takeDropped()
+	assert.True(t, dropped >= int64(lines-kafkaQueueSize-1), "dropped %d lines", dropped)
+
+	close(producer.release)
+	require.NoError(t, s.Close())
+	assert.Equal(t, int64(lines)-dropped, producer.produced)
+
+	_, err := s.Write([]byte("{\"a\":1}\n"))
+	assert.Equal(t, errSinkClosed, err)
+}
+
+// stalledProducer is a Kafka producer taking the messages only once released.
+type stalledProducer struct {
+	input    chan *sarama.ProducerMessage
+	errors   chan *sarama.ProducerError
+	release  chan struct{}
+	done     chan struct{}
+	produced int64
+}
+
+func newStalledProducer() *stalledProducer {
+	p := &stalledProducer{
+		input:   make(chan *sarama.ProducerMessage),
+		errors:  make(chan *sarama.ProducerError),
+		release: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.done)
+		<-p.release
+		for range p.input {
+			p.produced++
+		}
+	}()
+
+	return p
+}
+
+func (p *stalledProducer) AsyncClose() {
+	close(p.input)
+	<-p.done
+	close(p.errors)
+}
+
+func (p *stalledProducer) Close() error {
+	p.AsyncClose()
+	return nil
+}
+
+func (p *stalledProducer) Input() chan<- *sarama.ProducerMessage {
+	return p.input
+}
+
+func (p *stalledProducer) Successes() <-chan *sarama.ProducerMessage {
+	return nil
+}
+
+func (p *stalledProducer) Errors() <-chan *sarama.ProducerError {
+	return p.errors
+}
+
+func TestNewSinks(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      *types.AccessLog
+		expectedErr bool
+		expectedLen int
+	}{
+		{
+			desc:        "stdout when no sink is configured",
+			config:      &types.AccessLog{},
+			expectedLen: 1,
+		},
+		{
+			desc: "Kafka without broker",
+			config: &types.AccessLog{
+				Kafka: &types.AccessLogKafka{Topic: "access"},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "HTTP without endpoint",
+			config: &types.AccessLog{
+				HTTP: &types.AccessLogHTTP{},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			sinks, err := newSinks(test.config)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Len(t, sinks, test.expectedLen)
+			closeSinks(sinks)
+		})
+	}
+}
@@ -47,13 +47,15 @@ func (ap *addPrefix) GetTracingInformation() (string, ext.SpanKindEnum) {
 func (ap *addPrefix) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	logger := middlewares.GetLogger(req.Context(), ap.name, typeName)
 
+	prefix := middlewares.ExpandRouteVars(req, ap.prefix)
+
 	oldURLPath := req.URL.Path
-	req.URL.Path = ap.prefix + req.URL.Path
+	req.URL.Path = prefix + req.URL.Path
 	logger.Debugf("URL.Path is now %s (was %s).", req.URL.Path, oldURLPath)
 
 	if req.URL.RawPath != "" {
 		oldURLRawPath := req.URL.RawPath
-		req.URL.RawPath = ap.prefix + req.URL.RawPath
+		req.URL.RawPath = prefix + req.URL.RawPath
 		logger.Debugf("URL.RawPath is now %s (was %s).", req.URL.RawPath, oldURLRawPath)
 	}
 	req.RequestURI = req.URL.RequestURI()
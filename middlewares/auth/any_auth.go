@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/containous/alice"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/accesslog"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	anyTypeName = "AnyAuth"
+)
+
+type mechanismKey struct{}
+
+// attemptKey is the context key of the attempts of an AnyAuth middleware, distinct from the ones of the nested AnyAuth middlewares.
+type attemptKey struct {
+	auth *anyAuth
+}
+
+type chainBuilder interface {
+	BuildChain(ctx context.Context, middlewares []string) (*alice.Chain, error)
+}
+
+// mechanism is an authentication middleware of an AnyAuth middleware.
+type mechanism struct {
+	name    string
+	handler http.Handler
+}
+
+// attempt holds the outcome of the authentication of a request by a mechanism.
+type attempt struct {
+	rw            http.ResponseWriter
+	authenticated bool
+}
+
+type anyAuth struct {
+	next       http.Handler
+	mechanisms []mechanism
+	name       string
+}
+
+// NewAnyAuth creates an AnyAuth middleware, which tries the authentication middlewares in order,
+// until one of them authenticates the request.
+func NewAnyAuth(ctx context.Context, next http.Handler, authConfig config.AnyAuth, builder chainBuilder, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, anyTypeName).Debug("Creating middleware")
+
+	if len(authConfig.Middlewares) == 0 {
+		return nil, fmt.Errorf("no authentication middleware defined")
+	}
+
+	a := &anyAuth{next: next, name: name}
+
+	for _, middlewareName := range authConfig.Middlewares {
+		if middlewareName == name {
+			return nil, fmt.Errorf("the middleware %q cannot reference itself", name)
+		}
+
+		chain, err := builder.BuildChain(ctx, []string{middlewareName})
+		if err != nil {
+			return nil, err
+		}
+
+		handler, err := chain.Then(http.HandlerFunc(a.authenticated(middlewareName)))
+		if err != nil {
+			return nil, err
+		}
+
+		a.mechanisms = append(a.mechanisms, mechanism{name: middlewareName, handler: handler})
+	}
+
+	return a, nil
+}
+
+// MechanismFromContext returns the name of the middleware which authenticated the request, among the ones of an AnyAuth middleware.
+func MechanismFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(mechanismKey{}).(string)
+	return name
+}
+
+func (a *anyAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *anyAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), a.name, anyTypeName)
+
+	var failure *failureRecorder
+	for _, m := range a.mechanisms {
+		recorder := newFailureRecorder()
+		att := &attempt{rw: rw}
+
+		m.handler.ServeHTTP(recorder, req.WithContext(context.WithValue(req.Context(), attemptKey{auth: a}, att)))
+		if att.authenticated {
+			return
+		}
+
+		logger.Debugf("Authentication failed with the middleware %s", m.name)
+
+		if failure == nil {
+			failure = recorder
+		} else {
+			// Advertises all the authentication schemes.
+			for _, challenge := range recorder.Header()["Www-Authenticate"] {
+				failure.Header().Add("Www-Authenticate", challenge)
+			}
+		}
+	}
+
+	logger.Debug("Authentication failed")
+	tracing.SetErrorWithEvent(req, "Authentication failed")
+
+	failure.writeTo(rw)
+}
+
+// authenticated returns the handler ending the chain of a mechanism, called when it authenticates the request:
+// the request goes on to the next handler of the AnyAuth middleware, with the changes made by the mechanism.
+func (a *anyAuth) authenticated(mechanismName string) func(http.ResponseWriter, *http.Request) {
+	return func(_ http.ResponseWriter, req *http.Request) {
+		att, ok := req.Context().Value(attemptKey{auth: a}).(*attempt)
+		if !ok {
+			return
+		}
+		att.authenticated = true
+
+		middlewares.GetLogger(req.Context(), a.name, anyTypeName).Debugf("Authentication succeeded with the middleware %s", mechanismName)
+		accesslog.SetField(req, accesslog.ClientAuthMechanism, mechanismName)
+
+		a.next.ServeHTTP(att.rw, req.WithContext(context.WithValue(req.Context(), mechanismKey{}, mechanismName)))
+	}
+}
+
+// failureRecorder records the response of a mechanism which doesn't authenticate the request.
+type failureRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newFailureRecorder() *failureRecorder {
+	return &failureRecorder{header: make(http.Header)}
+}
+
+func (r *failureRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *failureRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *failureRecorder) WriteHeader(code int) {
+	r.code = code
+}
+
+// writeTo writes the recorded response, 401 if the mechanism didn't write any.
+func (r *failureRecorder) writeTo(rw http.ResponseWriter) {
+	for k, v := range r.header {
+		rw.Header()[k] = v
+	}
+
+	if r.code == 0 {
+		r.code = http.StatusUnauthorized
+	}
+	rw.WriteHeader(r.code)
+	_, _ = rw.Write(r.body.Bytes())
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/alice"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChainBuilder map[string]alice.Constructor
+
+func (b fakeChainBuilder) BuildChain(ctx context.Context, middlewares []string) (*alice.Chain, error) {
+	chain := alice.New()
+	for _, name := range middlewares {
+		constructor, ok := b[name]
+		if !ok {
+			return nil, fmt.Errorf("middleware %q does not exist", name)
+		}
+		chain = chain.Append(constructor)
+	}
+	return &chain, nil
+}
+
+func TestAnyAuth(t *testing.T) {
+	builder := fakeChainBuilder{
+		"basic": func(next http.Handler) (http.Handler, error) {
+			return NewBasic(context.Background(), next, config.BasicAuth{
+				Users: []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+				Realm: "basic",
+			}, "basic")
+		},
+		"token": func(next http.Handler) (http.Handler, error) {
+			return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("X-Token") != "secret" {
+					rw.Header().Set("Www-Authenticate", `Bearer realm="token"`)
+					http.Error(rw, "invalid token", http.StatusUnauthorized)
+					return
+				}
+				req.Header.Set("X-Token-User", "bot")
+				next.ServeHTTP(rw, req)
+			}), nil
+		},
+		"silent": func(next http.Handler) (http.Handler, error) {
+			return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), nil
+		},
+	}
+
+	testCases := []struct {
+		desc                string
+		middlewares         []string
+		setup               func(req *http.Request)
+		expectedStatus      int
+		expectedMechanism   string
+		expectedUser        string
+		expectedChallenges  []string
+		expectedErrorOnInit bool
+	}{
+		{
+			desc:        "first mechanism",
+			middlewares: []string{"basic", "token"},
+			setup: func(req *http.Request) {
+				req.SetBasicAuth("test", "test")
+			},
+			expectedStatus:    http.StatusOK,
+			expectedMechanism: "basic",
+		},
+		{
+			desc:        "second mechanism",
+			middlewares: []string{"basic", "token"},
+			setup: func(req *http.Request) {
+				req.Header.Set("X-Token", "secret")
+			},
+			expectedStatus:    http.StatusOK,
+			expectedMechanism: "token",
+			expectedUser:      "bot",
+		},
+		{
+			desc:           "no valid credentials",
+			middlewares:    []string{"basic", "token"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedChallenges: []string{
+				`Basic realm="basic"`,
+				`Bearer realm="token"`,
+			},
+		},
+		{
+			desc:           "mechanism without response",
+			middlewares:    []string{"silent"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:                "unknown middleware",
+			middlewares:         []string{"basic", "foo"},
+			expectedErrorOnInit: true,
+		},
+		{
+			desc:                "self reference",
+			middlewares:         []string{"basic", "any"},
+			expectedErrorOnInit: true,
+		},
+		{
+			desc:                "no middleware",
+			expectedErrorOnInit: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var mechanism, user string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				mechanism = MechanismFromContext(req.Context())
+				user = req.Header.Get("X-Token-User")
+			})
+
+			handler, err := NewAnyAuth(context.Background(), next, config.AnyAuth{Middlewares: test.middlewares}, builder, "any")
+			if test.expectedErrorOnInit {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.setup != nil {
+				test.setup(req)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedMechanism, mechanism)
+			assert.Equal(t, test.expectedUser, user)
+			if len(test.expectedChallenges) > 0 {
+				assert.Equal(t, test.expectedChallenges, recorder.Header()["Www-Authenticate"])
+			}
+		})
+	}
+}
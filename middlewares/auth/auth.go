@@ -1,8 +1,14 @@
 package auth
 
 import (
+	"context"
 	"io/ioutil"
+	"net/http"
 	"strings"
+	"sync"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/secrets"
 )
 
 // UserParser Parses a string and return a userName/userHash. An error if the format of the string is incorrect.
@@ -13,12 +19,107 @@ const (
 	authorizationHeader = "Authorization"
 )
 
+// userKey is the context key of the user authenticated by a BasicAuth or DigestAuth middleware.
+type userKey struct{}
+
+// UserFromContext returns the user authenticated by a BasicAuth or DigestAuth middleware, if any.
+// Unlike the user info of the request URL, it cannot be set by the client.
+func UserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+func withUser(req *http.Request, user string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), userKey{}, user))
+}
+
+// users holds the users of an auth middleware.
+// When the users file is a secret reference, the users are parsed again each time the secret is rotated.
+type users struct {
+	reference   string
+	appendUsers []string
+	parser      UserParser
+
+	lock  sync.RWMutex
+	raw   string
+	users map[string]string
+}
+
+func newUsers(fileName string, appendUsers []string, parser UserParser) (*users, error) {
+	u := &users{appendUsers: appendUsers, parser: parser}
+
+	if !secrets.IsReference(fileName) {
+		userMap, err := getUsers(fileName, appendUsers, parser)
+		if err != nil {
+			return nil, err
+		}
+		u.users = userMap
+		return u, nil
+	}
+
+	u.reference = fileName
+
+	raw, err := secrets.Resolve(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.update(raw); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// get returns the hash of the user, empty if the user is unknown.
+func (u *users) get(user string) string {
+	if len(u.reference) > 0 {
+		raw, err := secrets.Resolve(u.reference)
+		if err != nil {
+			log.WithoutContext().Errorf("Unable to resolve the users %s, keeping the current ones: %v", u.reference, err)
+		} else if err := u.update(raw); err != nil {
+			log.WithoutContext().Errorf("Invalid users %s, keeping the current ones: %v", u.reference, err)
+		}
+	}
+
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	return u.users[user]
+}
+
+// update parses the users of the secret, if it changed.
+func (u *users) update(raw string) error {
+	u.lock.RLock()
+	unchanged := u.users != nil && raw == u.raw
+	u.lock.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	userMap, err := parseUsers(append(getLines(raw), u.appendUsers...), u.parser)
+	if err != nil {
+		return err
+	}
+
+	u.lock.Lock()
+	u.raw = raw
+	u.users = userMap
+	u.lock.Unlock()
+
+	return nil
+}
+
 func getUsers(fileName string, appendUsers []string, parser UserParser) (map[string]string, error) {
 	users, err := loadUsers(fileName, appendUsers)
 	if err != nil {
 		return nil, err
 	}
 
+	return parseUsers(users, parser)
+}
+
+func parseUsers(users []string, parser UserParser) (map[string]string, error) {
 	userMap := make(map[string]string)
 	for _, user := range users {
 		userName, userHash, err := parser(user)
@@ -51,8 +152,12 @@ func getLinesFromFile(filename string) ([]string, error) {
 		return nil, err
 	}
 
-	// Trim lines and filter out blanks
-	rawLines := strings.Split(string(dat), "\n")
+	return getLines(string(dat)), nil
+}
+
+// getLines trims the lines, and filters out the blank lines and the comments.
+func getLines(data string) []string {
+	rawLines := strings.Split(data, "\n")
 	var filteredLines []string
 	for _, rawLine := range rawLines {
 		line := strings.TrimSpace(rawLine)
@@ -61,5 +166,5 @@ func getLinesFromFile(filename string) ([]string, error) {
 		}
 	}
 
-	return filteredLines, nil
+	return filteredLines
 }
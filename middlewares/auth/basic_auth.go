@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	goauth "github.com/abbot/go-http-auth"
+	"github.com/containous/traefik/audit"
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/middlewares/accesslog"
@@ -22,7 +23,7 @@ const (
 type basicAuth struct {
 	next         http.Handler
 	auth         *goauth.BasicAuth
-	users        map[string]string
+	users        *users
 	headerField  string
 	removeHeader bool
 	name         string
@@ -31,7 +32,7 @@ type basicAuth struct {
 // NewBasic creates a basicAuth middleware.
 func NewBasic(ctx context.Context, next http.Handler, authConfig config.BasicAuth, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, basicTypeName).Debug("Creating middleware")
-	users, err := getUsers(authConfig.UsersFile, authConfig.Users, basicUserParser)
+	users, err := newUsers(authConfig.UsersFile, authConfig.Users, basicUserParser)
 	if err != nil {
 		return nil, err
 	}
@@ -63,15 +64,15 @@ func (b *basicAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if username := b.auth.CheckAuth(req); username == "" {
 		logger.Debug("Authentication failed")
 		tracing.SetErrorWithEvent(req, "Authentication failed")
+		user, _, _ := req.BasicAuth()
+		audit.RecordRequest(req, audit.CategoryAuthentication, "basicauth.failed", user, b.name)
 		b.auth.RequireAuth(rw, req)
 	} else {
 		logger.Debug("Authentication succeeded")
 		req.URL.User = url.User(username)
+		req = withUser(req, username)
 
-		logData := accesslog.GetLogData(req)
-		if logData != nil {
-			logData.Core[accesslog.ClientUsername] = username
-		}
+		accesslog.SetField(req, accesslog.ClientUsername, username)
 
 		if b.headerField != "" {
 			req.Header[b.headerField] = []string{username}
@@ -86,11 +87,7 @@ func (b *basicAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 }
 
 func (b *basicAuth) secretBasic(user, realm string) string {
-	if secret, ok := b.users[user]; ok {
-		return secret
-	}
-
-	return ""
+	return b.users.get(user)
 }
 
 func basicUserParser(user string) (string, string, error) {
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/secrets"
 	"github.com/containous/traefik/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -282,3 +283,47 @@ func TestBasicAuthUsersFromFile(t *testing.T) {
 		})
 	}
 }
+
+func TestBasicAuthUsersFromSecret(t *testing.T) {
+	usersFile, err := ioutil.TempFile("", "auth-users")
+	require.NoError(t, err)
+	defer os.Remove(usersFile.Name())
+
+	_, err = usersFile.Write([]byte("test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/\n"))
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "traefik")
+	})
+
+	auth := config.BasicAuth{
+		UsersFile: "file://" + usersFile.Name(),
+	}
+	authMiddleware, err := NewBasic(context.Background(), next, auth, "authName")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(authMiddleware)
+	defer ts.Close()
+
+	checkUser := func(user string, expectedStatusCode int) {
+		req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+		req.SetBasicAuth(user, user)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		assert.Equal(t, expectedStatusCode, res.StatusCode, user)
+	}
+
+	checkUser("test", http.StatusOK)
+	checkUser("test2", http.StatusUnauthorized)
+
+	// Rotates the secret, and refreshes it with a new store.
+	require.NoError(t, ioutil.WriteFile(usersFile.Name(), []byte("test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0\n"), 0600))
+
+	store, err := secrets.New(nil)
+	require.NoError(t, err)
+	secrets.SetDefault(store)
+
+	checkUser("test", http.StatusUnauthorized)
+	checkUser("test2", http.StatusOK)
+}
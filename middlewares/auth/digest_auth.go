@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	goauth "github.com/abbot/go-http-auth"
+	"github.com/containous/traefik/audit"
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/middlewares/accesslog"
@@ -22,7 +23,7 @@ const (
 type digestAuth struct {
 	next         http.Handler
 	auth         *goauth.DigestAuth
-	users        map[string]string
+	users        *users
 	headerField  string
 	removeHeader bool
 	name         string
@@ -31,7 +32,7 @@ type digestAuth struct {
 // NewDigest creates a digest auth middleware.
 func NewDigest(ctx context.Context, next http.Handler, authConfig config.DigestAuth, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, digestTypeName).Debug("Creating middleware")
-	users, err := getUsers(authConfig.UsersFile, authConfig.Users, digestUserParser)
+	users, err := newUsers(authConfig.UsersFile, authConfig.Users, digestUserParser)
 	if err != nil {
 		return nil, err
 	}
@@ -63,15 +64,14 @@ func (d *digestAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if username, _ := d.auth.CheckAuth(req); username == "" {
 		logger.Debug("Digest authentication failed")
 		tracing.SetErrorWithEvent(req, "Digest authentication failed")
+		audit.RecordRequest(req, audit.CategoryAuthentication, "digestauth.failed", "", d.name)
 		d.auth.RequireAuth(rw, req)
 	} else {
 		logger.Debug("Digest authentication succeeded")
 		req.URL.User = url.User(username)
+		req = withUser(req, username)
 
-		logData := accesslog.GetLogData(req)
-		if logData != nil {
-			logData.Core[accesslog.ClientUsername] = username
-		}
+		accesslog.SetField(req, accesslog.ClientUsername, username)
 
 		if d.headerField != "" {
 			req.Header[d.headerField] = []string{username}
@@ -86,11 +86,7 @@ func (d *digestAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 }
 
 func (d *digestAuth) secretDigest(user, realm string) string {
-	if secret, ok := d.users[user+":"+realm]; ok {
-		return secret
-	}
-
-	return ""
+	return d.users.get(user + ":" + realm)
 }
 
 func digestUserParser(user string) (string, string, error) {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/secrets"
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/vulcand/oxy/forward"
@@ -26,6 +27,7 @@ const (
 type forwardAuth struct {
 	address             string
 	authResponseHeaders []string
+	authRequestHeaders  map[string]string
 	next                http.Handler
 	name                string
 	tlsConfig           *tls.Config
@@ -39,11 +41,18 @@ func NewForward(ctx context.Context, next http.Handler, config config.ForwardAut
 	fa := &forwardAuth{
 		address:             config.Address,
 		authResponseHeaders: config.AuthResponseHeaders,
+		authRequestHeaders:  config.AuthRequestHeaders,
 		next:                next,
 		name:                name,
 		trustForwardHeader:  config.TrustForwardHeader,
 	}
 
+	for name, value := range config.AuthRequestHeaders {
+		if _, err := secrets.ResolveValue(value); err != nil {
+			return nil, fmt.Errorf("unable to resolve the header %s: %v", name, err)
+		}
+	}
+
 	if config.TLS != nil {
 		tlsConfig, err := config.TLS.CreateTLSConfig()
 		if err != nil {
@@ -89,6 +98,19 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	writeHeader(req, forwardReq, fa.trustForwardHeader)
 
+	// The secrets are resolved at each request, to pick up their rotations.
+	for name, value := range fa.authRequestHeaders {
+		resolved, err := secrets.ResolveValue(value)
+		if err != nil {
+			logger.Debugf("Error resolving the header %s. Cause: %s", name, err)
+			tracing.SetErrorWithEvent(req, "Error resolving the header %s. Cause: %s", name, err)
+
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		forwardReq.Header.Set(name, resolved)
+	}
+
 	tracing.InjectRequestHeaders(forwardReq)
 
 	forwardResponse, forwardErr := httpClient.Do(forwardReq)
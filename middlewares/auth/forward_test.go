@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/containous/traefik/config"
@@ -391,3 +392,45 @@ func Test_writeHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestForwardAuthRequestHeaders(t *testing.T) {
+	tokenFile, err := ioutil.TempFile("", "auth-token")
+	require.NoError(t, err)
+	defer os.Remove(tokenFile.Name())
+
+	_, err = tokenFile.Write([]byte("s3cr3t\n"))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer static", r.Header.Get("Authorization"))
+		assert.Equal(t, "s3cr3t", r.Header.Get("X-Auth-Token"))
+		fmt.Fprintln(w, "Success")
+	}))
+	defer server.Close()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "traefik")
+	})
+
+	auth := config.ForwardAuth{
+		Address: server.URL,
+		AuthRequestHeaders: map[string]string{
+			"Authorization": "Bearer static",
+			"X-Auth-Token":  "file://" + tokenFile.Name(),
+		},
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(middleware)
+	defer ts.Close()
+
+	req := testhelpers.MustNewRequest(http.MethodGet, ts.URL, nil)
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	auth.AuthRequestHeaders = map[string]string{"X-Auth-Token": "file:///does/not/exist"}
+	_, err = NewForward(context.Background(), next, auth, "authTest")
+	assert.Error(t, err)
+}
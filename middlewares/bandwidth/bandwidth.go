@@ -0,0 +1,248 @@
+package bandwidth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/lru"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/vulcand/oxy/utils"
+	"golang.org/x/time/rate"
+)
+
+const (
+	typeName = "Bandwidth"
+)
+
+// limiters holds the download and upload limiters of a source, shared by its requests in flight.
+type limiters struct {
+	download *rate.Limiter
+	upload   *rate.Limiter
+	requests int
+}
+
+// bandwidth is a middleware limiting the download and upload rates of the sources,
+// identified by an extractor, or by the connection of the request.
+type bandwidth struct {
+	next      http.Handler
+	name      string
+	download  int64
+	upload    int64
+	burst     int
+	extractor utils.SourceExtractor
+	// idleTTL is the duration the limiters of a source without requests in flight are kept, the time they take to fill up again.
+	idleTTL time.Duration
+
+	lock sync.Mutex
+	// sources holds the limiters of the sources with requests in flight.
+	sources map[string]*limiters
+	// idle holds the limiters of the sources without requests in flight, the least recently used ones being evicted when it is full.
+	idle *lru.Cache
+}
+
+// New creates a new bandwidth limiting middleware.
+func New(ctx context.Context, next http.Handler, config config.Bandwidth, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if config.Download < 0 || config.Upload < 0 || config.Burst < 0 {
+		return nil, errors.New("the rates and the burst must be positive")
+	}
+	if config.Download == 0 && config.Upload == 0 {
+		return nil, errors.New("a download or an upload rate is required")
+	}
+
+	b := &bandwidth{
+		next:     next,
+		name:     name,
+		download: config.Download,
+		upload:   config.Upload,
+		burst:    int(config.Burst),
+		sources:  make(map[string]*limiters),
+		idle:     lru.New("bandwidth"),
+	}
+
+	if b.burst == 0 {
+		b.burst = int(maxInt64(config.Download, config.Upload))
+	}
+
+	for _, r := range []int64{config.Download, config.Upload} {
+		if r == 0 {
+			continue
+		}
+		if ttl := time.Duration(float64(b.burst)/float64(r)*float64(time.Second)) + time.Second; ttl > b.idleTTL {
+			b.idleTTL = ttl
+		}
+	}
+
+	if len(config.ExtractorFunc) > 0 {
+		extractor, err := forwardedheaders.NewExtractor(config.ExtractorFunc)
+		if err != nil {
+			return nil, err
+		}
+		b.extractor = extractor
+	}
+
+	return b, nil
+}
+
+func (b *bandwidth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return b.name, tracing.SpanKindNoneEnum
+}
+
+func (b *bandwidth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	source := req.RemoteAddr
+	if b.extractor != nil {
+		var err error
+		source, _, err = b.extractor.Extract(req)
+		if err != nil {
+			middlewares.GetLogger(req.Context(), b.name, typeName).Errorf("Unable to extract the source: %v", err)
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	l := b.acquire(source)
+	defer b.release(source)
+
+	if l.upload != nil && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &body{ReadCloser: req.Body, ctx: req.Context(), limiter: l.upload}
+	}
+
+	if l.download != nil {
+		rw = &responseWriter{ResponseWriter: rw, ctx: req.Context(), limiter: l.download}
+	}
+
+	b.next.ServeHTTP(rw, req)
+}
+
+// acquire returns the limiters of the source, kept from its previous requests if they are not full again yet.
+func (b *bandwidth) acquire(source string) *limiters {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	l, ok := b.sources[source]
+	if !ok {
+		if value, idle := b.idle.Get(source); idle {
+			l = value.(*limiters)
+		} else {
+			l = &limiters{}
+			if b.download > 0 {
+				l.download = rate.NewLimiter(rate.Limit(b.download), b.burst)
+			}
+			if b.upload > 0 {
+				l.upload = rate.NewLimiter(rate.Limit(b.upload), b.burst)
+			}
+		}
+		b.sources[source] = l
+	}
+	l.requests++
+
+	return l
+}
+
+// release keeps the limiters of the source as idle when its last request in flight ends,
+// so that its next requests don't start with a full burst.
+func (b *bandwidth) release(source string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	l := b.sources[source]
+	l.requests--
+	if l.requests == 0 {
+		delete(b.sources, source)
+		b.idle.Set(source, l, b.idleTTL)
+	}
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// body limits the rate at which the request body is read.
+type body struct {
+	io.ReadCloser
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (b *body) Read(p []byte) (int, error) {
+	if len(p) > b.limiter.Burst() {
+		p = p[:b.limiter.Burst()]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := b.limiter.WaitN(b.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// responseWriter limits the rate at which the response body is written.
+type responseWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (r *responseWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > r.limiter.Burst() {
+			chunk = chunk[:r.limiter.Burst()]
+		}
+
+		if err := r.limiter.WaitN(r.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := r.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseWriter) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// CloseNotify returns a channel that receives at most a single value (true)
+// when the client connection has gone away.
+func (r *responseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := r.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(<-chan bool)
+}
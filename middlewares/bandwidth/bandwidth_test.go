@@ -0,0 +1,177 @@
+package bandwidth
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.Bandwidth
+		expectedBurst int
+		expectedErr   bool
+	}{
+		{
+			desc:          "download rate",
+			config:        config.Bandwidth{Download: 1000},
+			expectedBurst: 1000,
+		},
+		{
+			desc:          "download and upload rates",
+			config:        config.Bandwidth{Download: 1000, Upload: 2000},
+			expectedBurst: 2000,
+		},
+		{
+			desc:          "explicit burst",
+			config:        config.Bandwidth{Upload: 2000, Burst: 100},
+			expectedBurst: 100,
+		},
+		{
+			desc:        "no rate",
+			config:      config.Bandwidth{Burst: 100},
+			expectedErr: true,
+		},
+		{
+			desc:        "negative rate",
+			config:      config.Bandwidth{Download: -1},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid extractor",
+			config:      config.Bandwidth{Download: 1000, ExtractorFunc: "foo"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := New(context.Background(), http.NotFoundHandler(), test.config, "bandwidth")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedBurst, handler.(*bandwidth).burst)
+		})
+	}
+}
+
+func TestDownload(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write(make([]byte, 300))
+		require.NoError(t, err)
+	})
+
+	handler, err := New(context.Background(), next, config.Bandwidth{Download: 1000, Burst: 100}, "bandwidth")
+	require.NoError(t, err)
+
+	start := time.Now()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// The first 100 bytes are sent at once, the next 200 take 200ms.
+	assert.True(t, time.Since(start) >= 150*time.Millisecond)
+	assert.Equal(t, 300, recorder.Body.Len())
+}
+
+func TestUpload(t *testing.T) {
+	var received int
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		data, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		received = len(data)
+	})
+
+	handler, err := New(context.Background(), next, config.Bandwidth{Upload: 1000, Burst: 100}, "bandwidth")
+	require.NoError(t, err)
+
+	start := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(make([]byte, 300))))
+
+	assert.True(t, time.Since(start) >= 150*time.Millisecond)
+	assert.Equal(t, 300, received)
+}
+
+func TestSharedBySource(t *testing.T) {
+	release := make(chan struct{})
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+	})
+
+	handler, err := New(context.Background(), next, config.Bandwidth{Download: 1000, ExtractorFunc: "client.ip"}, "bandwidth")
+	require.NoError(t, err)
+	b := handler.(*bandwidth)
+
+	done := make(chan struct{})
+	for _, remoteAddr := range []string{"10.0.0.1:1000", "10.0.0.1:2000"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		go func() {
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			done <- struct{}{}
+		}()
+	}
+
+	requests := func() int {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if l, ok := b.sources["10.0.0.1"]; ok {
+			return l.requests
+		}
+		return 0
+	}
+	for i := 0; i < 100 && requests() < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 2, requests())
+
+	close(release)
+	<-done
+	<-done
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	assert.Empty(t, b.sources)
+}
+
+func TestSequentialRequests(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write(make([]byte, 100))
+		require.NoError(t, err)
+	})
+
+	handler, err := New(context.Background(), next, config.Bandwidth{Download: 1000, Burst: 100, ExtractorFunc: "client.ip"}, "bandwidth")
+	require.NoError(t, err)
+
+	serve := func(remoteAddr string) time.Duration {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+
+		start := time.Now()
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return time.Since(start)
+	}
+
+	// The first request of the source uses the whole burst.
+	assert.True(t, serve("10.0.0.1:1000") < 50*time.Millisecond)
+
+	// The next request of the source waits for the burst to fill up again, even though the first one has ended.
+	assert.True(t, serve("10.0.0.1:2000") >= 80*time.Millisecond)
+
+	// The other sources have their own limiters.
+	assert.True(t, serve("10.0.0.2:1000") < 50*time.Millisecond)
+}
@@ -2,7 +2,9 @@ package buffering
 
 import (
 	"context"
+	"mime"
 	"net/http"
+	"strings"
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares"
@@ -12,11 +14,13 @@ import (
 )
 
 const (
-	typeName = "Buffer"
+	typeName             = "Buffer"
+	eventStreamMediaType = "text/event-stream"
 )
 
 type buffer struct {
 	name   string
+	next   http.Handler
 	buffer *oxybuffer.Buffer
 }
 
@@ -41,6 +45,7 @@ func New(ctx context.Context, next http.Handler, config config.Buffering, name s
 
 	return &buffer{
 		name:   name,
+		next:   next,
 		buffer: oxyBuffer,
 	}, nil
 }
@@ -50,5 +55,24 @@ func (b *buffer) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (b *buffer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// An event stream never ends: buffering its response would hold all the events back.
+	if acceptsEventStream(req) {
+		b.next.ServeHTTP(rw, req)
+		return
+	}
+
 	b.buffer.ServeHTTP(rw, req)
 }
+
+// acceptsEventStream returns true if the request asks for Server-Sent Events.
+func acceptsEventStream(req *http.Request) bool {
+	for _, accept := range req.Header[http.CanonicalHeaderKey("Accept")] {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(mediaRange)
+			if err == nil && mediaType == eventStreamMediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package buffering
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferingEventStream(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		accept           []string
+		expectedBuffered bool
+	}{
+		{
+			desc:             "no accept header",
+			expectedBuffered: true,
+		},
+		{
+			desc:             "JSON",
+			accept:           []string{"application/json"},
+			expectedBuffered: true,
+		},
+		{
+			desc:   "event stream",
+			accept: []string{"text/event-stream"},
+		},
+		{
+			desc:   "event stream among other media types",
+			accept: []string{"text/html, text/event-stream;q=0.9"},
+		},
+		{
+			desc:   "event stream in a second header",
+			accept: []string{"text/html", "text/event-stream"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := httptest.NewRecorder()
+
+			var buffered bool
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				// The buffer hands its own response writer to the next handler.
+				buffered = rw != http.ResponseWriter(recorder)
+				rw.WriteHeader(http.StatusOK)
+				_, _ = rw.Write([]byte("data: foo\n\n"))
+			})
+
+			handler, err := New(context.Background(), next, config.Buffering{
+				MaxRequestBodyBytes:  1024,
+				MemRequestBodyBytes:  1024,
+				MaxResponseBodyBytes: 1024,
+				MemResponseBodyBytes: 1024,
+			}, "buffer")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			for _, accept := range test.accept {
+				req.Header.Add("Accept", accept)
+			}
+
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedBuffered, buffered)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "data: foo\n\n", recorder.Body.String())
+		})
+	}
+}
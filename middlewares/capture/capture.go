@@ -0,0 +1,272 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "Capture"
+
+	defaultMaxBodyBytes = 64 * 1024
+
+	// Redacted replaces the values of the scrubbed headers and fields.
+	Redacted = "REDACTED"
+)
+
+// defaultScrubHeaders are always redacted, as they carry credentials.
+var defaultScrubHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// Record is a captured request, written as a line of JSON.
+// The body is base64 encoded, and holds at most MaxBodyBytes.
+type Record struct {
+	Time          time.Time   `json:"time"`
+	Method        string      `json:"method"`
+	URL           string      `json:"url"`
+	Proto         string      `json:"proto"`
+	Host          string      `json:"host"`
+	Headers       http.Header `json:"headers,omitempty"`
+	Body          []byte      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"bodyTruncated,omitempty"`
+	BodyOmitted   bool        `json:"bodyOmitted,omitempty"`
+}
+
+// capture is a middleware recording sampled requests.
+type capture struct {
+	next         http.Handler
+	name         string
+	sampleRate   float64
+	maxBodyBytes int64
+	writer       io.Writer
+	scrubHeaders []string
+	scrubFields  map[string]bool
+}
+
+// New creates a new capture middleware.
+func New(ctx context.Context, next http.Handler, config config.Capture, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if config.SampleRate < 0 || config.SampleRate > 1 {
+		return nil, fmt.Errorf("the sample rate must be between 0 and 1: %v", config.SampleRate)
+	}
+
+	writer, err := getWriter(config)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBodyBytes := config.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+
+	scrubFields := make(map[string]bool)
+	for _, field := range config.ScrubFields {
+		scrubFields[field] = true
+	}
+
+	return &capture{
+		next:         next,
+		name:         name,
+		sampleRate:   config.SampleRate,
+		maxBodyBytes: maxBodyBytes,
+		writer:       writer,
+		scrubHeaders: append(append([]string{}, defaultScrubHeaders...), config.ScrubHeaders...),
+		scrubFields:  scrubFields,
+	}, nil
+}
+
+func (c *capture) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *capture) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if c.sampleRate == 0 || rand.Float64() < c.sampleRate {
+		if err := c.capture(req); err != nil {
+			middlewares.GetLogger(req.Context(), c.name, typeName).Errorf("Unable to capture the request: %v", err)
+		}
+	}
+
+	c.next.ServeHTTP(rw, req)
+}
+
+func (c *capture) capture(req *http.Request) error {
+	body, truncated, err := c.readBody(req)
+	if err != nil {
+		return err
+	}
+
+	record := Record{
+		Time:          time.Now().UTC(),
+		Method:        req.Method,
+		URL:           c.requestURL(req),
+		Proto:         req.Proto,
+		Host:          req.Host,
+		Headers:       c.scrubHeaderValues(req.Header),
+		BodyTruncated: truncated,
+	}
+
+	record.Body, record.BodyOmitted = c.scrubBody(req.Header.Get("Content-Type"), body, truncated)
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.writer.Write(append(line, '\n'))
+	return err
+}
+
+// readBody reads at most maxBodyBytes of the body, and restores the body for the next handlers.
+func (c *capture) readBody(req *http.Request) ([]byte, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, false, nil
+	}
+
+	// Reads one more byte to know if the body is truncated.
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, c.maxBodyBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+
+	// A seekable body (buffered by the request buffering middleware) is rewound, so it stays seekable.
+	if seeker, ok := req.Body.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, false, err
+		}
+	} else {
+		req.Body = &readCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), req.Body),
+			Closer: req.Body,
+		}
+	}
+
+	if int64(len(body)) > c.maxBodyBytes {
+		return body[:c.maxBodyBytes], true, nil
+	}
+	return body, false, nil
+}
+
+func (c *capture) requestURL(req *http.Request) string {
+	u := *req.URL
+	u.Host = req.Host
+	u.Scheme = "http"
+	if req.TLS != nil {
+		u.Scheme = "https"
+	}
+
+	if len(c.scrubFields) > 0 && len(u.RawQuery) > 0 {
+		u.RawQuery = c.scrubValues(u.Query()).Encode()
+	}
+
+	return u.String()
+}
+
+func (c *capture) scrubHeaderValues(header http.Header) http.Header {
+	headers := make(http.Header, len(header))
+	for name, values := range header {
+		headers[name] = append([]string{}, values...)
+	}
+
+	for _, name := range c.scrubHeaders {
+		if _, ok := headers[http.CanonicalHeaderKey(name)]; ok {
+			headers.Set(name, Redacted)
+		}
+	}
+
+	return headers
+}
+
+// scrubBody redacts the scrubbed fields of form and JSON bodies.
+// A body which cannot be scrubbed, because it is truncated or malformed, is omitted.
+func (c *capture) scrubBody(contentType string, body []byte, truncated bool) ([]byte, bool) {
+	if len(c.scrubFields) == 0 || len(body) == 0 {
+		return body, false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		if truncated {
+			return nil, true
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, true
+		}
+		return []byte(c.scrubValues(values).Encode()), false
+
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if truncated {
+			return nil, true
+		}
+		scrubbed, err := c.scrubJSON(body)
+		if err != nil {
+			return nil, true
+		}
+		return scrubbed, false
+
+	default:
+		return body, false
+	}
+}
+
+func (c *capture) scrubValues(values url.Values) url.Values {
+	for name := range values {
+		if c.scrubFields[name] {
+			values[name] = []string{Redacted}
+		}
+	}
+	return values
+}
+
+func (c *capture) scrubJSON(body []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errors.New("empty JSON body")
+	}
+
+	return json.Marshal(c.scrubJSONValue(value))
+}
+
+func (c *capture) scrubJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if c.scrubFields[key] {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = c.scrubJSONValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = c.scrubJSONValue(item)
+		}
+	}
+	return value
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,171 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config config.Capture
+	}{
+		{
+			desc:   "no destination",
+			config: config.Capture{},
+		},
+		{
+			desc:   "file and Kafka",
+			config: config.Capture{FilePath: "capture.log", Kafka: &config.CaptureKafka{Brokers: []string{"localhost:9092"}, Topic: "foo"}},
+		},
+		{
+			desc:   "Kafka without topic",
+			config: config.Capture{Kafka: &config.CaptureKafka{Brokers: []string{"localhost:9092"}}},
+		},
+		{
+			desc:   "invalid sample rate",
+			config: config.Capture{SampleRate: 2, FilePath: "capture.log"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "capture")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCapture(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "traefik_capture")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	testCases := []struct {
+		desc           string
+		config         config.Capture
+		url            string
+		contentType    string
+		body           string
+		headers        map[string]string
+		expectedURL    string
+		expectedBody   string
+		expectedRecord Record
+	}{
+		{
+			desc:         "body",
+			url:          "http://foo.com/bar?a=b",
+			body:         "hello",
+			expectedURL:  "http://foo.com/bar?a=b",
+			expectedBody: "hello",
+		},
+		{
+			desc:           "truncated body",
+			config:         config.Capture{MaxBodyBytes: 3},
+			url:            "http://foo.com/bar",
+			body:           "hello",
+			expectedURL:    "http://foo.com/bar",
+			expectedBody:   "hel",
+			expectedRecord: Record{BodyTruncated: true},
+		},
+		{
+			desc:        "scrubbed headers",
+			config:      config.Capture{ScrubHeaders: []string{"X-Api-Key"}},
+			url:         "http://foo.com/bar",
+			headers:     map[string]string{"X-Api-Key": "secret", "Authorization": "Basic Zm9vOmJhcg==", "X-Foo": "bar"},
+			expectedURL: "http://foo.com/bar",
+			expectedRecord: Record{Headers: http.Header{
+				"X-Api-Key":     {Redacted},
+				"Authorization": {Redacted},
+				"X-Foo":         {"bar"},
+			}},
+		},
+		{
+			desc:         "scrubbed query and JSON fields",
+			config:       config.Capture{ScrubFields: []string{"password", "token"}},
+			url:          "http://foo.com/bar?token=secret&a=b",
+			contentType:  "application/json",
+			body:         `{"user":"foo","password":"secret","items":[{"token":"secret"}]}`,
+			expectedURL:  "http://foo.com/bar?a=b&token=REDACTED",
+			expectedBody: `{"items":[{"token":"REDACTED"}],"password":"REDACTED","user":"foo"}`,
+		},
+		{
+			desc:         "scrubbed form fields",
+			config:       config.Capture{ScrubFields: []string{"password"}},
+			url:          "http://foo.com/bar",
+			contentType:  "application/x-www-form-urlencoded",
+			body:         "user=foo&password=secret",
+			expectedURL:  "http://foo.com/bar",
+			expectedBody: "password=REDACTED&user=foo",
+		},
+		{
+			desc:           "truncated JSON body with scrubbed fields",
+			config:         config.Capture{ScrubFields: []string{"password"}, MaxBodyBytes: 10},
+			url:            "http://foo.com/bar",
+			contentType:    "application/json",
+			body:           `{"user":"foo","password":"secret"}`,
+			expectedURL:    "http://foo.com/bar",
+			expectedRecord: Record{BodyTruncated: true, BodyOmitted: true},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		filePath := filepath.Join(tempDir, strings.Replace(test.desc, " ", "_", -1)+".log")
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var forwardedBody []byte
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				var err error
+				forwardedBody, err = ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+			})
+
+			test.config.FilePath = filePath
+			handler, err := New(context.Background(), next, test.config, "capture")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, test.url, strings.NewReader(test.body))
+			if len(test.contentType) > 0 {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, test.body, string(forwardedBody))
+
+			content, err := ioutil.ReadFile(filePath)
+			require.NoError(t, err)
+
+			var record Record
+			require.NoError(t, json.Unmarshal(content, &record))
+
+			assert.Equal(t, http.MethodPost, record.Method)
+			assert.Equal(t, test.expectedURL, record.URL)
+			assert.Equal(t, test.expectedBody, string(record.Body))
+			assert.Equal(t, test.expectedRecord.BodyTruncated, record.BodyTruncated)
+			assert.Equal(t, test.expectedRecord.BodyOmitted, record.BodyOmitted)
+			for name, values := range test.expectedRecord.Headers {
+				assert.Equal(t, values, record.Headers[name])
+			}
+		})
+	}
+}
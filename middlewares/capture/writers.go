@@ -0,0 +1,124 @@
+package capture
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+)
+
+// The writers are shared by the middlewares with the same destination, and kept open for the lifetime of Traefik,
+// as the middlewares are created again each time the configuration is reloaded.
+var (
+	writersLock sync.Mutex
+	writers     = make(map[string]io.Writer)
+)
+
+func getWriter(config config.Capture) (io.Writer, error) {
+	var key string
+	var create func() (io.Writer, error)
+
+	switch {
+	case len(config.FilePath) > 0 && config.Kafka != nil:
+		return nil, errors.New("a capture can be written either to a file or to Kafka, not both")
+	case len(config.FilePath) > 0:
+		key = "file:" + config.FilePath
+		create = func() (io.Writer, error) {
+			return newFileWriter(config.FilePath)
+		}
+	case config.Kafka != nil:
+		key = "kafka:" + strings.Join(config.Kafka.Brokers, ",") + "/" + config.Kafka.Topic
+		create = func() (io.Writer, error) {
+			return newKafkaWriter(config.Kafka)
+		}
+	default:
+		return nil, errors.New("no file or Kafka topic defined to write the capture")
+	}
+
+	writersLock.Lock()
+	defer writersLock.Unlock()
+
+	if writer, ok := writers[key]; ok {
+		return writer, nil
+	}
+
+	writer, err := create()
+	if err != nil {
+		return nil, err
+	}
+	writers[key] = writer
+
+	return writer, nil
+}
+
+// fileWriter appends the captured requests to a file.
+type fileWriter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newFileWriter(path string) (*fileWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileWriter{file: file}, nil
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Write(p)
+}
+
+// kafkaWriter produces each captured request as a message of a Kafka topic.
+type kafkaWriter struct {
+	topic    string
+	producer sarama.AsyncProducer
+}
+
+func newKafkaWriter(config *config.CaptureKafka) (*kafkaWriter, error) {
+	if len(config.Brokers) == 0 {
+		return nil, errors.New("no Kafka broker defined")
+	}
+	if len(config.Topic) == 0 {
+		return nil, errors.New("no Kafka topic defined")
+	}
+
+	producer, err := sarama.NewAsyncProducer(config.Brokers, sarama.NewConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for err := range producer.Errors() {
+			log.WithoutContext().Errorf("Error sending captured request to Kafka: %v", err)
+		}
+	}()
+
+	return &kafkaWriter{topic: config.Topic, producer: producer}, nil
+}
+
+func (w *kafkaWriter) Write(p []byte) (int, error) {
+	value := make([]byte, len(bytes.TrimSuffix(p, []byte("\n"))))
+	copy(value, p)
+
+	w.producer.Input() <- &sarama.ProducerMessage{
+		Topic: w.topic,
+		Value: sarama.ByteEncoder(value),
+	}
+	return len(p), nil
+}
@@ -3,8 +3,11 @@ package circuitbreaker
 import (
 	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/events"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/tracing"
@@ -14,11 +17,24 @@ import (
 
 const (
 	typeName = "CircuitBreaker"
+
+	// fallbackDuration is how long a tripped circuit breaker answers on behalf of the next handler, before recovering.
+	fallbackDuration = 10 * time.Second
 )
 
+// trips holds until when the tripped circuit breakers, by name, answer on behalf of the next handler,
+// so that a circuit breaker rebuilt by a configuration reload stays tripped.
+var trips = struct {
+	sync.Mutex
+	until map[string]time.Time
+}{until: make(map[string]time.Time)}
+
 type circuitBreaker struct {
 	circuitBreaker *cbreaker.CircuitBreaker
 	name           string
+	fallback       http.Handler
+	// trippedUntil is when the circuit breaker it replaces would have started recovering.
+	trippedUntil time.Time
 }
 
 // New creates a new circuit breaker middleware.
@@ -29,26 +45,60 @@ func New(ctx context.Context, next http.Handler, confCircuitBreaker config.Circu
 	logger.Debug("Creating middleware")
 	logger.Debug("Setting up with expression: %s", expression)
 
-	oxyCircuitBreaker, err := cbreaker.New(next, expression, createCircuitBreakerOptions(expression))
+	fallback := createFallback(expression)
+	oxyCircuitBreaker, err := cbreaker.New(next, expression, cbreaker.Fallback(fallback),
+		cbreaker.FallbackDuration(fallbackDuration),
+		cbreaker.OnTripped(publisher{eventType: events.CircuitBreakerTripped, name: name}),
+		cbreaker.OnStandby(publisher{eventType: events.CircuitBreakerStandby, name: name}))
 	if err != nil {
 		return nil, err
 	}
+
+	trips.Lock()
+	trippedUntil := trips.until[name]
+	trips.Unlock()
+
+	if time.Now().Before(trippedUntil) {
+		logger.Debugf("Staying tripped until %s", trippedUntil)
+	}
+
 	return &circuitBreaker{
 		circuitBreaker: oxyCircuitBreaker,
 		name:           name,
+		fallback:       fallback,
+		trippedUntil:   trippedUntil,
 	}, nil
 }
 
-// NewCircuitBreakerOptions returns a new CircuitBreakerOption
-func createCircuitBreakerOptions(expression string) cbreaker.CircuitBreakerOption {
-	return cbreaker.Fallback(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+// createFallback returns the handler answering on behalf of the next handler, while the circuit breaker is tripped.
+func createFallback(expression string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		tracing.SetErrorWithEvent(req, "blocked by circuit-breaker (%q)", expression)
 		rw.WriteHeader(http.StatusServiceUnavailable)
 
 		if _, err := rw.Write([]byte(http.StatusText(http.StatusServiceUnavailable))); err != nil {
 			log.FromContext(req.Context()).Error(err)
 		}
-	}))
+	})
+}
+
+// publisher publishes the changes of state of a circuit breaker.
+type publisher struct {
+	eventType string
+	name      string
+}
+
+func (p publisher) Exec() error {
+	trips.Lock()
+	if p.eventType == events.CircuitBreakerTripped {
+		trips.until[p.name] = time.Now().Add(fallbackDuration)
+	} else {
+		delete(trips.until, p.name)
+	}
+	trips.Unlock()
+
+	events.Publish(p.eventType, map[string]string{"middleware": p.name})
+	return nil
 }
 
 func (c *circuitBreaker) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -57,5 +107,10 @@ func (c *circuitBreaker) GetTracingInformation() (string, ext.SpanKindEnum) {
 
 func (c *circuitBreaker) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	middlewares.GetLogger(req.Context(), c.name, typeName).Debug("Entering middleware")
+
+	if time.Now().Before(c.trippedUntil) {
+		c.fallback.ServeHTTP(rw, req)
+		return
+	}
 	c.circuitBreaker.ServeHTTP(rw, req)
 }
@@ -0,0 +1,36 @@
+package circuitbreaker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/events"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_carriesOverTrips(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+	conf := config.CircuitBreaker{Expression: "NetworkErrorRatio() > 0.5"}
+
+	serve := func() int {
+		handler, err := New(context.Background(), next, conf, "cb")
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil))
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve())
+
+	require.NoError(t, publisher{eventType: events.CircuitBreakerTripped, name: "cb"}.Exec())
+	assert.Equal(t, http.StatusServiceUnavailable, serve())
+
+	require.NoError(t, publisher{eventType: events.CircuitBreakerStandby, name: "cb"}.Exec())
+	assert.Equal(t, http.StatusOK, serve())
+}
@@ -0,0 +1,171 @@
+package collapse
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "CollapseForwarding"
+)
+
+// call is a request forwarded to the backend, whose response is shared with the identical requests received meanwhile.
+type call struct {
+	done      chan struct{}
+	waiters   int
+	completed bool
+	header    http.Header
+	code      int
+	body      bytes.Buffer
+}
+
+// collapse is a middleware coalescing the concurrent identical GET requests into a single backend call:
+// the first request is forwarded, and the requests received until it is answered get a copy of its response.
+type collapse struct {
+	next        http.Handler
+	name        string
+	varyHeaders []string
+
+	lock  sync.Mutex
+	calls map[string]*call
+}
+
+// New creates a new collapse forwarding middleware.
+func New(ctx context.Context, next http.Handler, config config.CollapseForwarding, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	varyHeaders := make([]string, len(config.VaryHeaders))
+	for i, header := range config.VaryHeaders {
+		varyHeaders[i] = http.CanonicalHeaderKey(header)
+	}
+
+	return &collapse{
+		next:        next,
+		name:        name,
+		varyHeaders: varyHeaders,
+		calls:       make(map[string]*call),
+	}, nil
+}
+
+func (c *collapse) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *collapse) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	key := c.key(req)
+
+	c.lock.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.calls[key] = cl
+	} else {
+		cl.waiters++
+	}
+	c.lock.Unlock()
+
+	if !ok {
+		c.forward(rw, req, key, cl)
+		return
+	}
+
+	select {
+	case <-cl.done:
+	case <-req.Context().Done():
+		return
+	}
+
+	if !cl.completed {
+		// The forwarded request didn't complete, this one is forwarded on its own.
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	for k, v := range cl.header {
+		rw.Header()[k] = append([]string(nil), v...)
+	}
+	rw.WriteHeader(cl.code)
+	_, _ = rw.Write(cl.body.Bytes())
+}
+
+// forward forwards the request to the backend, and records its response for the identical requests.
+func (c *collapse) forward(rw http.ResponseWriter, req *http.Request, key string, cl *call) {
+	defer func() {
+		c.lock.Lock()
+		delete(c.calls, key)
+		waiters := cl.waiters
+		c.lock.Unlock()
+
+		if waiters > 0 {
+			middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Response to %s shared with %d identical requests", req.URL, waiters)
+		}
+		close(cl.done)
+	}()
+
+	recorder := &responseRecorder{ResponseWriter: rw, call: cl}
+	c.next.ServeHTTP(recorder, req)
+
+	if cl.code == 0 {
+		recorder.WriteHeader(http.StatusOK)
+	}
+	cl.completed = req.Context().Err() == nil
+}
+
+func (c *collapse) key(req *http.Request) string {
+	var key strings.Builder
+	key.WriteString(req.Method)
+	key.WriteString(" ")
+	key.WriteString(req.Host)
+	key.WriteString(req.URL.RequestURI())
+
+	for _, header := range c.varyHeaders {
+		key.WriteString("\n")
+		key.WriteString(header)
+		key.WriteString(": ")
+		key.WriteString(strings.Join(req.Header[header], ","))
+	}
+
+	return key.String()
+}
+
+// responseRecorder writes the response of the forwarded request, and records it.
+type responseRecorder struct {
+	http.ResponseWriter
+	call *call
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.call.code != 0 {
+		return
+	}
+
+	r.call.code = code
+	r.call.header = make(http.Header, len(r.Header()))
+	for k, v := range r.Header() {
+		r.call.header[k] = append([]string(nil), v...)
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.call.code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	r.call.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,174 @@
+package collapse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollapse(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		varyHeaders   []string
+		method        string
+		paths         []string
+		headers       []string
+		expectedCalls int32
+	}{
+		{
+			desc:          "identical requests",
+			method:        http.MethodGet,
+			paths:         []string{"/foo", "/foo", "/foo"},
+			expectedCalls: 1,
+		},
+		{
+			desc:          "different URLs",
+			method:        http.MethodGet,
+			paths:         []string{"/foo", "/foo?bar=1", "/bar"},
+			expectedCalls: 3,
+		},
+		{
+			desc:          "not a GET",
+			method:        http.MethodPost,
+			paths:         []string{"/foo", "/foo", "/foo"},
+			expectedCalls: 3,
+		},
+		{
+			desc:          "same vary header",
+			varyHeaders:   []string{"accept-language"},
+			method:        http.MethodGet,
+			paths:         []string{"/foo", "/foo"},
+			headers:       []string{"fr", "fr"},
+			expectedCalls: 1,
+		},
+		{
+			desc:          "different vary headers",
+			varyHeaders:   []string{"Accept-Language"},
+			method:        http.MethodGet,
+			paths:         []string{"/foo", "/foo"},
+			headers:       []string{"fr", "en"},
+			expectedCalls: 2,
+		},
+		{
+			desc:          "different headers not in vary headers",
+			method:        http.MethodGet,
+			paths:         []string{"/foo", "/foo"},
+			headers:       []string{"fr", "en"},
+			expectedCalls: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			release := make(chan struct{})
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+
+				rw.Header().Set("X-Path", req.URL.RequestURI())
+				rw.WriteHeader(http.StatusTeapot)
+				_, _ = rw.Write([]byte("body"))
+			})
+
+			handler, err := New(context.Background(), next, config.CollapseForwarding{VaryHeaders: test.varyHeaders}, "test")
+			require.NoError(t, err)
+
+			recorders := make([]*httptest.ResponseRecorder, len(test.paths))
+			var served sync.WaitGroup
+			for i, path := range test.paths {
+				req := httptest.NewRequest(test.method, "http://foo"+path, nil)
+				if test.headers != nil {
+					req.Header.Set("Accept-Language", test.headers[i])
+				}
+				recorders[i] = httptest.NewRecorder()
+
+				served.Add(1)
+				go func(rw http.ResponseWriter, req *http.Request) {
+					defer served.Done()
+					handler.ServeHTTP(rw, req)
+				}(recorders[i], req)
+
+				// Waits for the request to be forwarded, or to wait for an identical request.
+				for int(atomic.LoadInt32(&calls))+waiters(handler.(*collapse)) < i+1 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+
+			close(release)
+			served.Wait()
+
+			assert.Equal(t, test.expectedCalls, atomic.LoadInt32(&calls))
+			for i, recorder := range recorders {
+				assert.Equal(t, http.StatusTeapot, recorder.Code)
+				assert.Equal(t, "body", recorder.Body.String())
+				assert.Equal(t, test.paths[i], recorder.Header().Get("X-Path"))
+			}
+		})
+	}
+}
+
+func waiters(c *collapse) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var count int
+	for _, cl := range c.calls {
+		count += cl.waiters
+	}
+	return count
+}
+
+func TestCollapseIncompleteCall(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-release
+			panic(http.ErrAbortHandler)
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	handler, err := New(context.Background(), next, config.CollapseForwarding{}, "test")
+	require.NoError(t, err)
+
+	go func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo/bar", nil))
+	}()
+
+	for atomic.LoadInt32(&calls) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	recorder := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo/bar", nil))
+	}()
+
+	for waiters(handler.(*collapse)) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	<-done
+
+	// The waiting request is forwarded on its own.
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,299 @@
+package conditional
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/mailgun/ttlmap"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "ConditionalRequests"
+
+	defaultMaxBodyBytes = 1024 * 1024
+	// validatorsCapacity is the maximum number of resources whose validators are kept at once.
+	validatorsCapacity = 65536
+)
+
+// validators holds the validators of the last fresh response of a resource.
+type validators struct {
+	etag         string
+	lastModified string
+	cacheControl string
+}
+
+// conditional is a middleware answering the conditional GET and HEAD requests at the edge.
+// It computes the ETag of the responses which have none, and answers 304 Not Modified when the validators match:
+// without contacting the backend while the last response of the resource is fresh, and instead of the body otherwise.
+type conditional struct {
+	next         http.Handler
+	name         string
+	maxBodyBytes int64
+	validators   *ttlmap.TtlMap
+}
+
+// New creates a new conditional requests middleware.
+func New(ctx context.Context, next http.Handler, config config.ConditionalRequests, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	maxBodyBytes := config.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+
+	validators, err := ttlmap.NewConcurrent(validatorsCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conditional{
+		next:         next,
+		name:         name,
+		maxBodyBytes: maxBodyBytes,
+		validators:   validators,
+	}, nil
+}
+
+func (c *conditional) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *conditional) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	key := req.Host + req.URL.RequestURI()
+
+	if value, ok := c.validators.Get(key); ok {
+		v := value.(validators)
+		if notModified(req, v.etag, v.lastModified) {
+			middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Answering %s %s with the validators of its last response", req.Method, req.URL)
+			writeNotModified(rw, v)
+			return
+		}
+	}
+
+	buffer := &responseBuffer{rw: rw, maxBytes: c.maxBodyBytes, bufferable: req.Method == http.MethodGet}
+	c.next.ServeHTTP(buffer, req)
+
+	if buffer.code == 0 {
+		buffer.WriteHeader(http.StatusOK)
+	}
+
+	header := rw.Header()
+	if !buffer.streaming && header.Get("ETag") == "" {
+		header.Set("ETag", computeETag(buffer.body.Bytes()))
+	}
+
+	if buffer.code == http.StatusOK {
+		c.store(key, req, header)
+	}
+
+	if buffer.streaming {
+		return
+	}
+
+	if notModified(req, header.Get("ETag"), header.Get("Last-Modified")) {
+		writeNotModified(rw, validators{
+			etag:         header.Get("ETag"),
+			lastModified: header.Get("Last-Modified"),
+			cacheControl: header.Get("Cache-Control"),
+		})
+		return
+	}
+
+	if err := buffer.stream(); err != nil {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Error while writing the response: %v", err)
+	}
+}
+
+// store keeps the validators of the response while it is fresh.
+func (c *conditional) store(key string, req *http.Request, header http.Header) {
+	if req.Header.Get("Authorization") != "" || header.Get("Vary") != "" || header.Get("Set-Cookie") != "" {
+		return
+	}
+
+	etag, lastModified := header.Get("ETag"), header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return
+	}
+
+	ttl := freshness(header)
+	if ttl <= 0 {
+		return
+	}
+
+	v := validators{etag: etag, lastModified: lastModified, cacheControl: header.Get("Cache-Control")}
+	if err := c.validators.Set(key, v, ttl); err != nil {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Errorf("Unable to keep the validators of %s: %v", req.URL, err)
+	}
+}
+
+// freshness returns the number of seconds the response is fresh for a shared cache.
+func freshness(header http.Header) int {
+	maxAge, sharedMaxAge := -1, -1
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		switch {
+		case directive == "no-store", directive == "no-cache", directive == "private":
+			return 0
+		case strings.HasPrefix(directive, "s-maxage="):
+			sharedMaxAge, _ = strconv.Atoi(strings.TrimPrefix(directive, "s-maxage="))
+		case strings.HasPrefix(directive, "max-age="):
+			maxAge, _ = strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+		}
+	}
+
+	if sharedMaxAge >= 0 {
+		return sharedMaxAge
+	}
+	if maxAge >= 0 {
+		return maxAge
+	}
+
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	return int(time.Until(expires) / time.Second)
+}
+
+// notModified evaluates the If-None-Match header of the request, or its If-Modified-Since header in the absence of the former.
+func notModified(req *http.Request, etag, lastModified string) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && matchETag(inm, etag)
+	}
+
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+
+	return !modified.After(ims)
+}
+
+// matchETag compares the ETags of an If-None-Match header to the ETag, with the weak comparison.
+func matchETag(inm, etag string) bool {
+	for _, candidate := range strings.Split(inm, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+func writeNotModified(rw http.ResponseWriter, v validators) {
+	header := rw.Header()
+	for _, name := range []string{"Content-Length", "Content-Type", "Content-Encoding", "Transfer-Encoding"} {
+		header.Del(name)
+	}
+
+	if v.etag != "" {
+		header.Set("ETag", v.etag)
+	}
+	if v.lastModified != "" {
+		header.Set("Last-Modified", v.lastModified)
+	}
+	if v.cacheControl != "" {
+		header.Set("Cache-Control", v.cacheControl)
+	}
+
+	rw.WriteHeader(http.StatusNotModified)
+}
+
+// responseBuffer buffers the successful responses to compute their ETag,
+// and streams the other ones, the ones larger than the max size, and the flushed ones.
+type responseBuffer struct {
+	rw         http.ResponseWriter
+	maxBytes   int64
+	bufferable bool
+	code       int
+	body       bytes.Buffer
+	streaming  bool
+}
+
+func (r *responseBuffer) Header() http.Header {
+	return r.rw.Header()
+}
+
+func (r *responseBuffer) WriteHeader(code int) {
+	if r.code != 0 {
+		return
+	}
+
+	r.code = code
+	if code != http.StatusOK || !r.bufferable {
+		_ = r.stream()
+	}
+}
+
+func (r *responseBuffer) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.streaming && int64(r.body.Len()+len(b)) > r.maxBytes {
+		if err := r.stream(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.streaming {
+		return r.rw.Write(b)
+	}
+	return r.body.Write(b)
+}
+
+// Flush streams the response.
+func (r *responseBuffer) Flush() {
+	if r.code == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if err := r.stream(); err != nil {
+		return
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// stream writes the buffered response, and stops the buffering.
+func (r *responseBuffer) stream() error {
+	if r.streaming {
+		return nil
+	}
+	r.streaming = true
+
+	r.rw.WriteHeader(r.code)
+	if r.body.Len() == 0 {
+		return nil
+	}
+	_, err := r.rw.Write(r.body.Bytes())
+	return err
+}
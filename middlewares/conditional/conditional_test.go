@@ -0,0 +1,226 @@
+package conditional
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditional(t *testing.T) {
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	etag := computeETag([]byte("body"))
+
+	testCases := []struct {
+		desc            string
+		method          string
+		maxBodyBytes    int64
+		responseHeaders map[string]string
+		responseCode    int
+		requestHeaders  map[string]string
+		expectedCode    int
+		expectedETag    string
+		expectedBody    string
+	}{
+		{
+			desc:         "computed ETag",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			expectedETag: etag,
+			expectedBody: "body",
+		},
+		{
+			desc:           "matching computed ETag",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"If-None-Match": `"foo", ` + etag},
+			expectedCode:   http.StatusNotModified,
+			expectedETag:   etag,
+		},
+		{
+			desc:           "matching weak ETag",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"If-None-Match": "W/" + etag},
+			expectedCode:   http.StatusNotModified,
+			expectedETag:   etag,
+		},
+		{
+			desc:           "not matching ETag",
+			method:         http.MethodGet,
+			requestHeaders: map[string]string{"If-None-Match": `"foo"`},
+			expectedCode:   http.StatusOK,
+			expectedETag:   etag,
+			expectedBody:   "body",
+		},
+		{
+			desc:            "backend ETag",
+			method:          http.MethodGet,
+			responseHeaders: map[string]string{"ETag": `"bar"`},
+			requestHeaders:  map[string]string{"If-None-Match": `"bar"`},
+			expectedCode:    http.StatusNotModified,
+			expectedETag:    `"bar"`,
+		},
+		{
+			desc:            "not modified since",
+			method:          http.MethodGet,
+			responseHeaders: map[string]string{"Last-Modified": lastModified},
+			requestHeaders:  map[string]string{"If-Modified-Since": lastModified},
+			expectedCode:    http.StatusNotModified,
+			expectedETag:    etag,
+		},
+		{
+			desc:            "modified since",
+			method:          http.MethodGet,
+			responseHeaders: map[string]string{"Last-Modified": lastModified},
+			requestHeaders:  map[string]string{"If-Modified-Since": "Mon, 02 Jan 2006 15:04:04 GMT"},
+			expectedCode:    http.StatusOK,
+			expectedETag:    etag,
+			expectedBody:    "body",
+		},
+		{
+			desc:            "If-None-Match takes precedence over If-Modified-Since",
+			method:          http.MethodGet,
+			responseHeaders: map[string]string{"Last-Modified": lastModified},
+			requestHeaders:  map[string]string{"If-None-Match": `"foo"`, "If-Modified-Since": lastModified},
+			expectedCode:    http.StatusOK,
+			expectedETag:    etag,
+			expectedBody:    "body",
+		},
+		{
+			desc:           "body larger than the max size",
+			method:         http.MethodGet,
+			maxBodyBytes:   2,
+			requestHeaders: map[string]string{"If-None-Match": etag},
+			expectedCode:   http.StatusOK,
+			expectedBody:   "body",
+		},
+		{
+			desc:           "error response",
+			method:         http.MethodGet,
+			responseCode:   http.StatusNotFound,
+			requestHeaders: map[string]string{"If-None-Match": etag},
+			expectedCode:   http.StatusNotFound,
+			expectedBody:   "body",
+		},
+		{
+			desc:           "not a GET",
+			method:         http.MethodPost,
+			requestHeaders: map[string]string{"If-None-Match": etag},
+			expectedCode:   http.StatusOK,
+			expectedBody:   "body",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				for k, v := range test.responseHeaders {
+					rw.Header().Set(k, v)
+				}
+				if test.responseCode != 0 {
+					rw.WriteHeader(test.responseCode)
+				}
+				_, _ = rw.Write([]byte("body"))
+			})
+
+			handler, err := New(context.Background(), next, config.ConditionalRequests{MaxBodyBytes: test.maxBodyBytes}, "test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(test.method, "http://foo/bar", nil)
+			for k, v := range test.requestHeaders {
+				req.Header.Set(k, v)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			assert.Equal(t, test.expectedETag, recorder.Header().Get("ETag"))
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestConditionalFreshValidators(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		cacheControl  string
+		requestHeader http.Header
+		expectedCalls int
+	}{
+		{
+			desc:          "fresh response",
+			cacheControl:  "public, max-age=60",
+			expectedCalls: 1,
+		},
+		{
+			desc:          "fresh response for shared caches",
+			cacheControl:  "max-age=0, s-maxage=60",
+			expectedCalls: 1,
+		},
+		{
+			desc:          "stale response",
+			cacheControl:  "max-age=0",
+			expectedCalls: 2,
+		},
+		{
+			desc:          "private response",
+			cacheControl:  "private, max-age=60",
+			expectedCalls: 2,
+		},
+		{
+			desc:          "no cache control",
+			expectedCalls: 2,
+		},
+		{
+			desc:          "authorized request",
+			cacheControl:  "max-age=60",
+			requestHeader: http.Header{"Authorization": {"foo"}},
+			expectedCalls: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls++
+				if test.cacheControl != "" {
+					rw.Header().Set("Cache-Control", test.cacheControl)
+				}
+				_, _ = rw.Write([]byte("body"))
+			})
+
+			handler, err := New(context.Background(), next, config.ConditionalRequests{}, "test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo/bar", nil)
+			for k, v := range test.requestHeader {
+				req.Header[k] = v
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			req.Header.Set("If-None-Match", recorder.Header().Get("ETag"))
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusNotModified, recorder.Code)
+			assert.Equal(t, test.expectedCalls, calls)
+			if test.cacheControl != "" {
+				assert.Equal(t, test.cacheControl, recorder.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package contentrouting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "ContentRouting"
+
+	defaultMaxBodySize = 1024 * 1024
+)
+
+type serviceBuilder interface {
+	Build(ctx context.Context, serviceName string, responseModifier func(*http.Response) error) (http.Handler, error)
+}
+
+// extractor extracts a value from a body, reporting whether it found one.
+type extractor interface {
+	extract(body []byte) (string, bool)
+}
+
+// contentRouting is a middleware sending the requests to the service of a value of their body.
+type contentRouting struct {
+	next        http.Handler
+	name        string
+	extractor   extractor
+	maxBodySize int64
+	routes      map[string]http.Handler
+}
+
+// New creates a new content routing middleware.
+func New(ctx context.Context, next http.Handler, config config.ContentRouting, serviceBuilder serviceBuilder, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	c := &contentRouting{
+		next:        next,
+		name:        name,
+		maxBodySize: config.MaxBodySize,
+		routes:      make(map[string]http.Handler),
+	}
+
+	var err error
+	switch {
+	case len(config.JSONPath) > 0 && len(config.XPath) > 0:
+		return nil, errors.New("either a JSON path or an XPath is required, not both")
+	case len(config.JSONPath) > 0:
+		c.extractor, err = newJSONExtractor(config.JSONPath)
+	case len(config.XPath) > 0:
+		c.extractor, err = newXMLExtractor(config.XPath)
+	default:
+		return nil, errors.New("a JSON path or an XPath is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.maxBodySize < 0 {
+		return nil, fmt.Errorf("invalid maximum body size %d", c.maxBodySize)
+	}
+	if c.maxBodySize == 0 {
+		c.maxBodySize = defaultMaxBodySize
+	}
+
+	if len(config.Routes) == 0 {
+		return nil, errors.New("no route defined")
+	}
+	for _, route := range config.Routes {
+		if _, ok := c.routes[route.Value]; ok {
+			return nil, fmt.Errorf("duplicate route of the value %q", route.Value)
+		}
+		if len(route.Service) == 0 {
+			return nil, fmt.Errorf("no service for the value %q", route.Value)
+		}
+
+		handler, err := serviceBuilder.Build(ctx, route.Service, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error building the service of the value %q: %v", route.Value, err)
+		}
+		c.routes[route.Value] = handler
+	}
+
+	return c, nil
+}
+
+func (c *contentRouting) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *contentRouting) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), c.name, typeName)
+
+	if req.Body == nil || req.Body == http.NoBody {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, c.maxBodySize+1))
+	if err != nil {
+		logger.Debugf("Unable to read the body: %v", err)
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if int64(len(body)) > c.maxBodySize {
+		// The body is sent as is, the bytes already read followed by the rest.
+		req.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+		logger.Debugf("Body larger than %d bytes, the request goes on to the service of the router", c.maxBodySize)
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	_ = req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	value, ok := c.extractor.extract(body)
+	if ok {
+		if handler, ok := c.routes[value]; ok {
+			logger.Debugf("Routing the request by the value %q", value)
+			handler.ServeHTTP(rw, req)
+			return
+		}
+	}
+
+	c.next.ServeHTTP(rw, req)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,204 @@
+package contentrouting
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeServiceBuilder map[string]http.Handler
+
+func (f fakeServiceBuilder) Build(ctx context.Context, serviceName string, responseModifier func(*http.Response) error) (http.Handler, error) {
+	handler, ok := f[serviceName]
+	if !ok {
+		return nil, errors.New("service not found")
+	}
+	return handler, nil
+}
+
+func echo(name string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		_, _ = rw.Write([]byte(name + ":" + string(body)))
+	})
+}
+
+func TestNewConfiguration(t *testing.T) {
+	routes := []config.ContentRoutingRoute{{Value: "v1", Service: "v1"}}
+
+	testCases := []struct {
+		desc   string
+		config config.ContentRouting
+	}{
+		{
+			desc:   "no path",
+			config: config.ContentRouting{Routes: routes},
+		},
+		{
+			desc:   "both a JSON path and an XPath",
+			config: config.ContentRouting{JSONPath: "version", XPath: "/version", Routes: routes},
+		},
+		{
+			desc:   "invalid JSON path",
+			config: config.ContentRouting{JSONPath: "order..version", Routes: routes},
+		},
+		{
+			desc:   "relative XPath",
+			config: config.ContentRouting{XPath: "Envelope/Body", Routes: routes},
+		},
+		{
+			desc:   "descendant XPath",
+			config: config.ContentRouting{XPath: "//Version", Routes: routes},
+		},
+		{
+			desc:   "XPath with a predicate",
+			config: config.ContentRouting{XPath: "/Envelope/Body/*[1]", Routes: routes},
+		},
+		{
+			desc:   "negative maximum body size",
+			config: config.ContentRouting{JSONPath: "version", MaxBodySize: -1, Routes: routes},
+		},
+		{
+			desc:   "no route",
+			config: config.ContentRouting{JSONPath: "version"},
+		},
+		{
+			desc:   "duplicate route",
+			config: config.ContentRouting{JSONPath: "version", Routes: append(routes, config.ContentRoutingRoute{Value: "v1", Service: "v1"})},
+		},
+		{
+			desc:   "unknown service",
+			config: config.ContentRouting{JSONPath: "version", Routes: []config.ContentRoutingRoute{{Value: "v1", Service: "unknown"}}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, fakeServiceBuilder{"v1": echo("v1")}, "content")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestContentRouting(t *testing.T) {
+	const soapRequest = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <m:GetOrder xmlns:m="http://example.com/orders" version="2">
+      <m:Version> v2 </m:Version>
+    </m:GetOrder>
+  </soap:Body>
+</soap:Envelope>`
+
+	testCases := []struct {
+		desc         string
+		config       config.ContentRouting
+		body         string
+		expectedBody string
+	}{
+		{
+			desc:         "JSON string",
+			config:       config.ContentRouting{JSONPath: "operation"},
+			body:         `{"operation":"v2","id":1}`,
+			expectedBody: `v2:{"operation":"v2","id":1}`,
+		},
+		{
+			desc:         "JSON nested number",
+			config:       config.ContentRouting{JSONPath: "order.items.1.version"},
+			body:         `{"order":{"items":[{"version":1},{"version":2}]}}`,
+			expectedBody: `2:{"order":{"items":[{"version":1},{"version":2}]}}`,
+		},
+		{
+			desc:         "JSON escaped key",
+			config:       config.ContentRouting{JSONPath: `api\.version`},
+			body:         `{"api.version":"v2"}`,
+			expectedBody: `v2:{"api.version":"v2"}`,
+		},
+		{
+			desc:         "JSON value without route",
+			config:       config.ContentRouting{JSONPath: "operation"},
+			body:         `{"operation":"v3"}`,
+			expectedBody: `default:{"operation":"v3"}`,
+		},
+		{
+			desc:         "JSON object value",
+			config:       config.ContentRouting{JSONPath: "operation"},
+			body:         `{"operation":{"v2":true}}`,
+			expectedBody: `default:{"operation":{"v2":true}}`,
+		},
+		{
+			desc:         "invalid JSON",
+			config:       config.ContentRouting{JSONPath: "operation"},
+			body:         `operation=v2`,
+			expectedBody: `default:operation=v2`,
+		},
+		{
+			desc:         "XML text",
+			config:       config.ContentRouting{XPath: "/soap:Envelope/soap:Body/*/Version/text()"},
+			body:         soapRequest,
+			expectedBody: "v2:" + soapRequest,
+		},
+		{
+			desc:         "XML attribute",
+			config:       config.ContentRouting{XPath: "/Envelope/Body/GetOrder/@version"},
+			body:         soapRequest,
+			expectedBody: "2:" + soapRequest,
+		},
+		{
+			desc:         "XML SOAP operation",
+			config:       config.ContentRouting{XPath: "local-name(/Envelope/Body/*)"},
+			body:         soapRequest,
+			expectedBody: "GetOrder:" + soapRequest,
+		},
+		{
+			desc:         "XML path without match",
+			config:       config.ContentRouting{XPath: "/Envelope/Header/Version"},
+			body:         soapRequest,
+			expectedBody: "default:" + soapRequest,
+		},
+		{
+			desc:         "body exceeding the maximum size",
+			config:       config.ContentRouting{JSONPath: "operation", MaxBodySize: 10},
+			body:         `{"operation":"v2"}`,
+			expectedBody: `default:{"operation":"v2"}`,
+		},
+	}
+
+	serviceBuilder := fakeServiceBuilder{
+		"v2":       echo("v2"),
+		"2":        echo("2"),
+		"GetOrder": echo("GetOrder"),
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.config.Routes = []config.ContentRoutingRoute{
+				{Value: "v2", Service: "v2"},
+				{Value: "2", Service: "2"},
+				{Value: "GetOrder", Service: "GetOrder"},
+			}
+
+			handler, err := New(context.Background(), echo("default"), test.config, serviceBuilder, "content")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(test.body)))
+
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package contentrouting
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonExtractor extracts a value of a JSON body by its path, like "order.items.0.sku".
+// The keys and the array indexes are separated by dots, a dot of a key being escaped by a backslash.
+type jsonExtractor struct {
+	path []string
+}
+
+func newJSONExtractor(path string) (*jsonExtractor, error) {
+	var keys []string
+	var key strings.Builder
+	escaped := false
+	for _, r := range path {
+		switch {
+		case escaped:
+			key.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteRune(r)
+		}
+	}
+	keys = append(keys, key.String())
+
+	for _, key := range keys {
+		if len(key) == 0 {
+			return nil, fmt.Errorf("invalid JSON path %q, empty key", path)
+		}
+	}
+	return &jsonExtractor{path: keys}, nil
+}
+
+// extract returns the value of the path, when it is a string, a number or a boolean.
+func (e *jsonExtractor) extract(body []byte) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	for _, key := range e.path {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if value, ok = v[key]; !ok {
+				return "", false
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return "", false
+			}
+			value = v[index]
+		default:
+			return "", false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// The values an XPath selects.
+const (
+	selectText = iota
+	selectAttribute
+	selectName
+)
+
+// xmlExtractor extracts a value of an XML body by a subset of XPath: an absolute path of elements, matched by their local name or *,
+// selecting the text of the first matching element, one of its attributes with a last @attribute step,
+// or its local name with the local-name() function.
+type xmlExtractor struct {
+	steps     []string
+	selection int
+	attribute string
+}
+
+func newXMLExtractor(xpath string) (*xmlExtractor, error) {
+	e := &xmlExtractor{selection: selectText}
+
+	path := strings.TrimSpace(xpath)
+	for _, function := range []string{"local-name(", "name("} {
+		if strings.HasPrefix(path, function) && strings.HasSuffix(path, ")") {
+			path = strings.TrimSuffix(strings.TrimPrefix(path, function), ")")
+			e.selection = selectName
+			break
+		}
+	}
+
+	if !strings.HasPrefix(path, "/") || strings.Contains(path, "//") {
+		return nil, fmt.Errorf("unsupported XPath %q, expected an absolute path of elements", xpath)
+	}
+
+	steps := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	last := steps[len(steps)-1]
+	switch {
+	case e.selection == selectText && strings.HasPrefix(last, "@"):
+		e.selection = selectAttribute
+		e.attribute = localName(strings.TrimPrefix(last, "@"))
+		steps = steps[:len(steps)-1]
+	case e.selection == selectText && last == "text()":
+		steps = steps[:len(steps)-1]
+	}
+
+	for _, step := range steps {
+		name := localName(step)
+		if len(name) == 0 || strings.ContainsAny(name, "[]()@=") {
+			return nil, fmt.Errorf("unsupported XPath %q, invalid step %q", xpath, step)
+		}
+		e.steps = append(e.steps, name)
+	}
+	if len(e.steps) == 0 || (e.selection == selectAttribute && len(e.attribute) == 0) {
+		return nil, fmt.Errorf("unsupported XPath %q, expected an absolute path of elements", xpath)
+	}
+
+	return e, nil
+}
+
+// localName returns a name without its namespace prefix, the namespaces being ignored.
+func localName(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// extract returns the value selected in the first element matching the path.
+func (e *xmlExtractor) extract(body []byte) (string, bool) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	var stack []string
+	var text strings.Builder
+	capturing := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", false
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if capturing || !e.matches(stack) {
+				continue
+			}
+
+			switch e.selection {
+			case selectName:
+				return t.Name.Local, true
+			case selectAttribute:
+				for _, attr := range t.Attr {
+					if attr.Name.Local == e.attribute {
+						return attr.Value, true
+					}
+				}
+			default:
+				capturing = true
+			}
+		case xml.CharData:
+			if capturing {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if capturing && len(stack) == len(e.steps) {
+				return strings.TrimSpace(text.String()), true
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+func (e *xmlExtractor) matches(stack []string) bool {
+	if len(stack) != len(e.steps) {
+		return false
+	}
+	for i, step := range e.steps {
+		if step != "*" && step != stack[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,354 @@
+package cors
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "CORS"
+	wildcard = "*"
+)
+
+const (
+	headerOrigin           = "Origin"
+	headerRequestMethod    = "Access-Control-Request-Method"
+	headerRequestHeaders   = "Access-Control-Request-Headers"
+	headerAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAllowMethods     = "Access-Control-Allow-Methods"
+	headerAllowHeaders     = "Access-Control-Allow-Headers"
+	headerExposeHeaders    = "Access-Control-Expose-Headers"
+	headerMaxAge           = "Access-Control-Max-Age"
+	headerVary             = "Vary"
+)
+
+var defaultAllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// cors is a middleware implementing Cross-Origin Resource Sharing.
+// It answers the preflight requests itself, and sets the CORS headers of the responses of the actual requests,
+// replacing the ones set by the backends.
+type cors struct {
+	next             http.Handler
+	name             string
+	allowAll         bool
+	origins          map[string]bool
+	wildcards        []wildcardOrigin
+	regexps          []*regexp.Regexp
+	methods          map[string]bool
+	allowedMethods   string
+	allowAllHeaders  bool
+	headers          map[string]bool
+	allowedHeaders   string
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           int64
+}
+
+// wildcardOrigin matches the subdomains of a domain, like https://*.example.com.
+type wildcardOrigin struct {
+	prefix string
+	suffix string
+}
+
+func (w wildcardOrigin) match(origin string) bool {
+	return len(origin) > len(w.prefix)+len(w.suffix) && strings.HasPrefix(origin, w.prefix) && strings.HasSuffix(origin, w.suffix)
+}
+
+// New creates a new CORS middleware.
+func New(ctx context.Context, next http.Handler, config config.CORS, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	c := &cors{
+		next:             next,
+		name:             name,
+		origins:          make(map[string]bool),
+		methods:          make(map[string]bool),
+		headers:          make(map[string]bool),
+		exposedHeaders:   strings.Join(config.ExposedHeaders, ", "),
+		allowCredentials: config.AllowCredentials,
+		maxAge:           config.MaxAge,
+	}
+
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == wildcard:
+			c.allowAll = true
+		case strings.Contains(origin, "://*."):
+			index := strings.Index(origin, "://*.")
+			c.wildcards = append(c.wildcards, wildcardOrigin{
+				prefix: origin[:index+len("://")],
+				suffix: origin[index+len("://*"):],
+			})
+		case strings.Contains(origin, wildcard):
+			return nil, fmt.Errorf("invalid allowed origin %q: only wildcard subdomains are supported", origin)
+		default:
+			c.origins[origin] = true
+		}
+	}
+
+	if c.allowAll && c.allowCredentials {
+		return nil, errors.New("all the origins can't be allowed along with the credentials, any site could make authenticated requests")
+	}
+
+	for _, expr := range config.AllowedOriginsRegex {
+		// The whole origin must match, so that https://foo\.com doesn't allow https://foo.com.evil.com.
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed origin regex %q: %v", expr, err)
+		}
+		c.regexps = append(c.regexps, re)
+	}
+
+	allowedMethods := config.AllowedMethods
+	if len(allowedMethods) == 0 {
+		allowedMethods = defaultAllowedMethods
+	}
+
+	var methods []string
+	for _, method := range allowedMethods {
+		method = strings.ToUpper(method)
+		c.methods[method] = true
+		methods = append(methods, method)
+	}
+	c.allowedMethods = strings.Join(methods, ", ")
+
+	var headers []string
+	for _, header := range config.AllowedHeaders {
+		if header == wildcard {
+			c.allowAllHeaders = true
+			continue
+		}
+		header = http.CanonicalHeaderKey(header)
+		c.headers[header] = true
+		headers = append(headers, header)
+	}
+	c.allowedHeaders = strings.Join(headers, ", ")
+
+	return c, nil
+}
+
+func (c *cors) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *cors) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	origin := req.Header.Get(headerOrigin)
+
+	if req.Method == http.MethodOptions && len(origin) > 0 && len(req.Header.Get(headerRequestMethod)) > 0 {
+		c.handlePreflight(rw, req)
+		return
+	}
+
+	// The responses to the requests without origin are wrapped too, as they must vary on the origin.
+	c.next.ServeHTTP(&responseWriter{ResponseWriter: rw, cors: c, origin: origin}, req)
+}
+
+// handlePreflight answers a preflight request without forwarding it.
+// The CORS headers are left out when the origin, the method or the headers are not allowed.
+func (c *cors) handlePreflight(rw http.ResponseWriter, req *http.Request) {
+	header := rw.Header()
+	header.Add(headerVary, "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+
+	origin := req.Header.Get(headerOrigin)
+	if !c.isOriginAllowed(origin) {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Preflight request from the origin %q not allowed", origin)
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	method := strings.ToUpper(req.Header.Get(headerRequestMethod))
+	if !c.methods[method] {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Preflight request with the method %q not allowed", method)
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	requestHeaders := parseHeaderList(req.Header.Get(headerRequestHeaders))
+	if !c.areHeadersAllowed(requestHeaders) {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Preflight request with the headers %q not allowed", requestHeaders)
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	header.Set(headerAllowOrigin, c.allowOriginValue(origin))
+	header.Set(headerAllowMethods, c.allowedMethods)
+
+	if c.allowAllHeaders && len(requestHeaders) > 0 {
+		header.Set(headerAllowHeaders, strings.Join(requestHeaders, ", "))
+	} else if len(c.allowedHeaders) > 0 {
+		header.Set(headerAllowHeaders, c.allowedHeaders)
+	}
+
+	if c.allowCredentials {
+		header.Set(headerAllowCredentials, "true")
+	}
+
+	if c.maxAge > 0 {
+		header.Set(headerMaxAge, strconv.FormatInt(c.maxAge, 10))
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// setResponseHeaders replaces the CORS headers of the response of an actual request.
+func (c *cors) setResponseHeaders(header http.Header, origin string) {
+	for name := range header {
+		if strings.HasPrefix(name, "Access-Control-") {
+			delete(header, name)
+		}
+	}
+
+	if !c.allowAll {
+		addVary(header, headerOrigin)
+	}
+
+	if !c.isOriginAllowed(origin) {
+		return
+	}
+
+	header.Set(headerAllowOrigin, c.allowOriginValue(origin))
+
+	if c.allowCredentials {
+		header.Set(headerAllowCredentials, "true")
+	}
+
+	if len(c.exposedHeaders) > 0 {
+		header.Set(headerExposeHeaders, c.exposedHeaders)
+	}
+}
+
+// allowOriginValue returns * when all the origins are allowed, the origin of the request otherwise.
+func (c *cors) allowOriginValue(origin string) string {
+	if c.allowAll {
+		return wildcard
+	}
+	return origin
+}
+
+func (c *cors) isOriginAllowed(origin string) bool {
+	if len(origin) == 0 {
+		return false
+	}
+	if c.allowAll {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if c.origins[origin] {
+		return true
+	}
+
+	for _, w := range c.wildcards {
+		if w.match(origin) {
+			return true
+		}
+	}
+
+	for _, re := range c.regexps {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *cors) areHeadersAllowed(headers []string) bool {
+	if c.allowAllHeaders {
+		return true
+	}
+
+	for _, header := range headers {
+		if !c.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
+
+func parseHeaderList(value string) []string {
+	var headers []string
+	for _, header := range strings.Split(value, ",") {
+		if header = strings.TrimSpace(header); len(header) > 0 {
+			headers = append(headers, http.CanonicalHeaderKey(header))
+		}
+	}
+	return headers
+}
+
+// addVary adds the value to the Vary header, unless it is already there.
+func addVary(header http.Header, value string) {
+	for _, vary := range header[headerVary] {
+		for _, v := range strings.Split(vary, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), value) || strings.TrimSpace(v) == wildcard {
+				return
+			}
+		}
+	}
+	header.Add(headerVary, value)
+}
+
+// responseWriter sets the CORS headers when the response headers are written.
+type responseWriter struct {
+	http.ResponseWriter
+	cors        *cors
+	origin      string
+	wroteHeader bool
+}
+
+func (r *responseWriter) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.cors.setResponseHeaders(r.ResponseWriter.Header(), r.origin)
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseWriter) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseWriter) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// CloseNotify returns a channel that receives at most a single value (true)
+// when the client connection has gone away.
+func (r *responseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := r.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(<-chan bool)
+}
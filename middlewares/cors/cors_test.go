@@ -0,0 +1,311 @@
+package cors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config config.CORS
+	}{
+		{
+			desc:   "invalid wildcard",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.*.com"}},
+		},
+		{
+			desc:   "invalid regex",
+			config: config.CORS{AllowedOriginsRegex: []string{"("}},
+		},
+		{
+			desc:   "all origins allowed with credentials",
+			config: config.CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "cors")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          config.CORS
+		requestHeaders  map[string]string
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:   "allowed origin",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}, MaxAge: 600},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://foo.com",
+				"Access-Control-Request-Method": "POST",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://foo.com",
+				"Access-Control-Allow-Methods": "GET, HEAD, POST",
+				"Access-Control-Max-Age":       "600",
+				"Vary":                         "Origin, Access-Control-Request-Method, Access-Control-Request-Headers",
+			},
+		},
+		{
+			desc:   "origin not allowed",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://bar.com",
+				"Access-Control-Request-Method": "POST",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			desc:   "method not allowed",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://foo.com",
+				"Access-Control-Request-Method": "DELETE",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			desc:   "allowed headers",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}, AllowedHeaders: []string{"x-foo", "Content-Type"}},
+			requestHeaders: map[string]string{
+				"Origin":                         "https://foo.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "content-type, X-Foo",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://foo.com",
+				"Access-Control-Allow-Headers": "X-Foo, Content-Type",
+			},
+		},
+		{
+			desc:   "header not allowed",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}, AllowedHeaders: []string{"X-Foo"}},
+			requestHeaders: map[string]string{
+				"Origin":                         "https://foo.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "X-Bar",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			desc:   "all headers allowed",
+			config: config.CORS{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}},
+			requestHeaders: map[string]string{
+				"Origin":                         "https://foo.com",
+				"Access-Control-Request-Method":  "GET",
+				"Access-Control-Request-Headers": "X-Bar",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Headers": "X-Bar",
+			},
+		},
+		{
+			desc:   "credentials allowed",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}, AllowCredentials: true},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://foo.com",
+				"Access-Control-Request-Method": "GET",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://foo.com",
+				"Access-Control-Allow-Credentials": "true",
+			},
+		},
+		{
+			desc:   "wildcard subdomain",
+			config: config.CORS{AllowedOrigins: []string{"https://*.foo.com"}},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://bar.foo.com",
+				"Access-Control-Request-Method": "GET",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "https://bar.foo.com",
+			},
+		},
+		{
+			desc:   "wildcard subdomain does not match the domain",
+			config: config.CORS{AllowedOrigins: []string{"https://*.foo.com"}},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://foo.com",
+				"Access-Control-Request-Method": "GET",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			desc:   "regex",
+			config: config.CORS{AllowedOriginsRegex: []string{`^https://[a-z]+\.bar\.com$`}},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://foo.bar.com",
+				"Access-Control-Request-Method": "GET",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "https://foo.bar.com",
+			},
+		},
+		{
+			desc:   "regex matching a part of the origin",
+			config: config.CORS{AllowedOriginsRegex: []string{`https://foo\.com`}},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://foo.com.evil.com",
+				"Access-Control-Request-Method": "GET",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			desc:   "regex alternation",
+			config: config.CORS{AllowedOriginsRegex: []string{`https://foo\.com|https://bar\.com`}},
+			requestHeaders: map[string]string{
+				"Origin":                        "https://evil.com/https://bar.com",
+				"Access-Control-Request-Method": "GET",
+			},
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				t.Error("the preflight request must not be forwarded")
+			})
+
+			handler, err := New(context.Background(), next, test.config, "cors")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodOptions, "http://localhost", nil)
+			for name, value := range test.requestHeaders {
+				req.Header.Set(name, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusNoContent, recorder.Code)
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, recorder.Header().Get(name), name)
+			}
+		})
+	}
+}
+
+func TestActualRequest(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          config.CORS
+		method          string
+		origin          string
+		expectedHeaders map[string][]string
+	}{
+		{
+			desc:   "allowed origin",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}, ExposedHeaders: []string{"X-Foo", "X-Bar"}},
+			method: http.MethodGet,
+			origin: "https://foo.com",
+			expectedHeaders: map[string][]string{
+				"Access-Control-Allow-Origin":   {"https://foo.com"},
+				"Access-Control-Expose-Headers": {"X-Foo, X-Bar"},
+				"Vary":                          {"Accept-Encoding", "Origin"},
+			},
+		},
+		{
+			desc:   "origin not allowed",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}},
+			method: http.MethodGet,
+			origin: "https://bar.com",
+			expectedHeaders: map[string][]string{
+				"Access-Control-Allow-Origin": nil,
+				"Vary":                        {"Accept-Encoding", "Origin"},
+			},
+		},
+		{
+			desc:   "without origin",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}},
+			method: http.MethodGet,
+			expectedHeaders: map[string][]string{
+				"Access-Control-Allow-Origin": nil,
+				"Vary":                        {"Accept-Encoding", "Origin"},
+			},
+		},
+		{
+			desc:   "all origins allowed",
+			config: config.CORS{AllowedOrigins: []string{"*"}},
+			method: http.MethodGet,
+			origin: "https://bar.com",
+			expectedHeaders: map[string][]string{
+				"Access-Control-Allow-Origin": {"*"},
+				"Vary":                        {"Accept-Encoding"},
+			},
+		},
+		{
+			desc:   "options request which is not a preflight request",
+			config: config.CORS{AllowedOrigins: []string{"https://foo.com"}},
+			method: http.MethodOptions,
+			origin: "https://foo.com",
+			expectedHeaders: map[string][]string{
+				"Access-Control-Allow-Origin": {"https://foo.com"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				// The CORS headers of the backend are replaced.
+				rw.Header().Set("Access-Control-Allow-Origin", "https://backend.com")
+				rw.Header().Add("Vary", "Accept-Encoding")
+				rw.WriteHeader(http.StatusAccepted)
+			})
+
+			handler, err := New(context.Background(), next, test.config, "cors")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(test.method, "http://localhost", nil)
+			if len(test.origin) > 0 {
+				req.Header.Set("Origin", test.origin)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusAccepted, recorder.Code)
+			for name, values := range test.expectedHeaders {
+				assert.Equal(t, values, recorder.Header()[name], name)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package csrf
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/audit"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "CSRF"
+
+	// ModeDoubleSubmit checks that the token sent by the client is the one of its cookie.
+	ModeDoubleSubmit = "doubleSubmit"
+	// ModeSynchronizer checks that the token sent by the client is signed for the session of its cookie.
+	ModeSynchronizer = "synchronizer"
+
+	defaultHeaderName = "X-CSRF-Token"
+	defaultFormField  = "csrf_token"
+	defaultCookieName = "_csrf"
+	defaultTokenTTL   = 12 * time.Hour
+
+	// maxFormBytes is the maximum size of a form body read to find the token.
+	maxFormBytes = 1 << 20
+)
+
+var defaultSafeMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace}
+
+var (
+	errMissingCookie = errors.New("missing CSRF cookie")
+	errMissingToken  = errors.New("missing CSRF token")
+	errInvalidToken  = errors.New("invalid CSRF token")
+	errExpiredToken  = errors.New("expired CSRF token")
+)
+
+// csrf is a middleware rejecting the unsafe requests which don't carry a valid token.
+//
+// In the double submit mode, the cookie holds the token: the client reads it and sends it back in a header or a form field.
+// In the synchronizer mode, the cookie (HTTP only) holds a session ID, and the token, an HMAC of the session ID,
+// is sent in a response header of the safe requests: the client sends it back in a header or a form field.
+type csrf struct {
+	next        http.Handler
+	name        string
+	mode        string
+	secret      []byte
+	safeMethods map[string]bool
+	headerName  string
+	formField   string
+	tokenTTL    time.Duration
+	cookie      http.Cookie
+	exemptPaths []string
+	now         func() time.Time
+}
+
+// New creates a new CSRF middleware.
+func New(ctx context.Context, next http.Handler, config config.CSRF, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	c := &csrf{
+		next:        next,
+		name:        name,
+		mode:        config.Mode,
+		secret:      []byte(config.Secret),
+		safeMethods: make(map[string]bool),
+		headerName:  config.HeaderName,
+		formField:   config.FormField,
+		tokenTTL:    time.Duration(config.TokenTTL),
+		exemptPaths: config.ExemptPaths,
+		now:         time.Now,
+		cookie: http.Cookie{
+			Name:   config.CookieName,
+			Domain: config.CookieDomain,
+			Path:   config.CookiePath,
+			Secure: config.CookieSecure,
+		},
+	}
+
+	switch c.mode {
+	case "":
+		c.mode = ModeDoubleSubmit
+	case ModeDoubleSubmit:
+	case ModeSynchronizer:
+		if len(c.secret) == 0 {
+			return nil, errors.New("a secret is required by the synchronizer mode")
+		}
+	default:
+		return nil, fmt.Errorf("unknown CSRF mode %q", c.mode)
+	}
+
+	switch strings.ToLower(config.CookieSameSite) {
+	case "", "lax":
+		c.cookie.SameSite = http.SameSiteLaxMode
+	case "strict":
+		c.cookie.SameSite = http.SameSiteStrictMode
+	default:
+		return nil, fmt.Errorf("unsupported SameSite attribute %q", config.CookieSameSite)
+	}
+
+	safeMethods := config.SafeMethods
+	if len(safeMethods) == 0 {
+		safeMethods = defaultSafeMethods
+	}
+	for _, method := range safeMethods {
+		c.safeMethods[strings.ToUpper(method)] = true
+	}
+
+	if len(c.headerName) == 0 {
+		c.headerName = defaultHeaderName
+	}
+	if len(c.formField) == 0 {
+		c.formField = defaultFormField
+	}
+	if c.tokenTTL <= 0 {
+		c.tokenTTL = defaultTokenTTL
+	}
+	if len(c.cookie.Name) == 0 {
+		c.cookie.Name = defaultCookieName
+	}
+	if len(c.cookie.Path) == 0 {
+		c.cookie.Path = "/"
+	}
+	// The client reads the token from the cookie in the double submit mode.
+	c.cookie.HttpOnly = c.mode == ModeSynchronizer
+
+	return c, nil
+}
+
+func (c *csrf) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *csrf) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if c.isExempt(req.URL.Path) {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if c.safeMethods[req.Method] {
+		if err := c.issueToken(rw, req); err != nil {
+			middlewares.GetLogger(req.Context(), c.name, typeName).Errorf("Unable to issue a CSRF token: %v", err)
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if err := c.check(req); err != nil {
+		middlewares.GetLogger(req.Context(), c.name, typeName).Debugf("Request rejected: %v", err)
+		audit.RecordRequest(req, audit.CategoryAuthorization, "csrf.denied", "", c.name)
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	c.next.ServeHTTP(rw, req)
+}
+
+func (c *csrf) isExempt(path string) bool {
+	for _, prefix := range c.exemptPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// issueToken sets a new cookie when the request has no valid one,
+// and sends the token in a response header in the synchronizer mode.
+func (c *csrf) issueToken(rw http.ResponseWriter, req *http.Request) error {
+	value, ok := c.cookieValue(req)
+	if !ok {
+		var err error
+		value, err = c.newCookieValue()
+		if err != nil {
+			return err
+		}
+
+		cookie := c.cookie
+		cookie.Value = value
+		cookie.MaxAge = int(c.tokenTTL / time.Second)
+		http.SetCookie(rw, &cookie)
+	}
+
+	if c.mode == ModeSynchronizer {
+		sessionID, _, err := splitValue(value)
+		if err != nil {
+			return err
+		}
+		rw.Header().Set(c.headerName, c.sign(sessionID, c.now().Add(c.tokenTTL)))
+	}
+
+	return nil
+}
+
+// cookieValue returns the value of the cookie of the request, if it has not expired.
+func (c *csrf) cookieValue(req *http.Request) (string, bool) {
+	cookie, err := req.Cookie(c.cookie.Name)
+	if err != nil {
+		return "", false
+	}
+
+	_, expiry, err := splitValue(cookie.Value)
+	if err != nil || c.now().After(expiry) {
+		return "", false
+	}
+
+	return cookie.Value, true
+}
+
+// newCookieValue returns a random value followed by its expiry.
+func (c *csrf) newCookieValue() (string, error) {
+	random := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, random); err != nil {
+		return "", err
+	}
+
+	expiry := c.now().Add(c.tokenTTL).Unix()
+	return base64.RawURLEncoding.EncodeToString(random) + "." + strconv.FormatInt(expiry, 10), nil
+}
+
+// sign returns a token for the session: its expiry, followed by an HMAC of the session ID and the expiry.
+func (c *csrf) sign(sessionID string, expiry time.Time) string {
+	expiryValue := strconv.FormatInt(expiry.Unix(), 10)
+
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(sessionID + "|" + expiryValue))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) + "." + expiryValue
+}
+
+func (c *csrf) check(req *http.Request) error {
+	value, ok := c.cookieValue(req)
+	if !ok {
+		return errMissingCookie
+	}
+
+	token, err := c.requestToken(req)
+	if err != nil {
+		return err
+	}
+	if len(token) == 0 {
+		return errMissingToken
+	}
+
+	switch c.mode {
+	case ModeSynchronizer:
+		sessionID, _, err := splitValue(value)
+		if err != nil {
+			return errInvalidToken
+		}
+
+		_, expiry, err := splitValue(token)
+		if err != nil {
+			return errInvalidToken
+		}
+		if c.now().After(expiry) {
+			return errExpiredToken
+		}
+
+		if !hmac.Equal([]byte(token), []byte(c.sign(sessionID, expiry))) {
+			return errInvalidToken
+		}
+
+	default:
+		if subtle.ConstantTimeCompare([]byte(token), []byte(value)) != 1 {
+			return errInvalidToken
+		}
+	}
+
+	return nil
+}
+
+// requestToken returns the token of the header, or else the one of the form field of a URL encoded body.
+// The body is read at most once, and restored for the next handlers.
+func (c *csrf) requestToken(req *http.Request) (string, error) {
+	if token := req.Header.Get(c.headerName); len(token) > 0 {
+		return token, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" || req.Body == nil {
+		return "", nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxFormBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > maxFormBytes {
+		return "", errors.New("form too large to find the CSRF token")
+	}
+	req.Body = &readCloser{Reader: bytes.NewReader(body), Closer: req.Body}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", nil
+	}
+
+	return values.Get(c.formField), nil
+}
+
+// splitValue splits a cookie value or a token into its value and its expiry.
+func splitValue(value string) (string, time.Time, error) {
+	index := strings.LastIndex(value, ".")
+	if index <= 0 {
+		return "", time.Time{}, errInvalidToken
+	}
+
+	expiry, err := strconv.ParseInt(value[index+1:], 10, 64)
+	if err != nil {
+		return "", time.Time{}, errInvalidToken
+	}
+
+	return value[:index], time.Unix(expiry, 0), nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,225 @@
+package csrf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      config.CSRF
+		expectedErr bool
+	}{
+		{
+			desc: "default configuration",
+		},
+		{
+			desc:   "synchronizer mode with a secret",
+			config: config.CSRF{Mode: ModeSynchronizer, Secret: "secret"},
+		},
+		{
+			desc:        "synchronizer mode without secret",
+			config:      config.CSRF{Mode: ModeSynchronizer},
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown mode",
+			config:      config.CSRF{Mode: "foo"},
+			expectedErr: true,
+		},
+		{
+			desc:        "unsupported SameSite attribute",
+			config:      config.CSRF{CookieSameSite: "foo"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "csrf")
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// issue sends a safe request to the middleware, and returns the cookie and the token it issues.
+func issue(t *testing.T, handler http.Handler) (*http.Cookie, string) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	return cookies[0], recorder.Header().Get(defaultHeaderName)
+}
+
+func TestDoubleSubmit(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, config.CSRF{ExemptPaths: []string{"/api/"}}, "csrf")
+	require.NoError(t, err)
+
+	cookie, token := issue(t, handler)
+	assert.Equal(t, defaultCookieName, cookie.Name)
+	assert.False(t, cookie.HttpOnly)
+	assert.Empty(t, token)
+
+	testCases := []struct {
+		desc           string
+		path           string
+		cookie         *http.Cookie
+		header         string
+		form           url.Values
+		expectedStatus int
+	}{
+		{
+			desc:           "token in header",
+			cookie:         cookie,
+			header:         cookie.Value,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "token in form field",
+			cookie:         cookie,
+			form:           url.Values{defaultFormField: {cookie.Value}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "wrong token",
+			cookie:         cookie,
+			header:         cookie.Value + "0",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "missing token",
+			cookie:         cookie,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "missing cookie",
+			header:         cookie.Value,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "exempt path",
+			path:           "/api/foo",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := "/"
+			if len(test.path) > 0 {
+				path = test.path
+			}
+
+			var req *http.Request
+			if test.form != nil {
+				req = httptest.NewRequest(http.MethodPost, path, strings.NewReader(test.form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req = httptest.NewRequest(http.MethodPost, path, nil)
+			}
+			if test.cookie != nil {
+				req.AddCookie(test.cookie)
+			}
+			if len(test.header) > 0 {
+				req.Header.Set(defaultHeaderName, test.header)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestFormBodyIsRestored(t *testing.T) {
+	var form url.Values
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+		form = req.PostForm
+	})
+
+	handler, err := New(context.Background(), next, config.CSRF{}, "csrf")
+	require.NoError(t, err)
+
+	cookie, _ := issue(t, handler)
+
+	values := url.Values{defaultFormField: {cookie.Value}, "foo": {"bar"}}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "bar", form.Get("foo"))
+}
+
+func TestSynchronizer(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	h, err := New(context.Background(), next, config.CSRF{Mode: ModeSynchronizer, Secret: "secret"}, "csrf")
+	require.NoError(t, err)
+
+	cookie, token := issue(t, h)
+	assert.True(t, cookie.HttpOnly)
+	require.NotEmpty(t, token)
+
+	post := func(cookie *http.Cookie, token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(cookie)
+		req.Header.Set(defaultHeaderName, token)
+
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, post(cookie, token))
+	// The session ID must not be accepted as a token.
+	assert.Equal(t, http.StatusForbidden, post(cookie, cookie.Value))
+
+	// The token is bound to the session of the cookie.
+	otherCookie, _ := issue(t, h)
+	assert.Equal(t, http.StatusForbidden, post(otherCookie, token))
+
+	// A token signed with another secret is rejected.
+	other, err := New(context.Background(), next, config.CSRF{Mode: ModeSynchronizer, Secret: "other"}, "csrf")
+	require.NoError(t, err)
+	_, otherToken := issue(t, other)
+	assert.Equal(t, http.StatusForbidden, post(cookie, otherToken))
+
+	// An expired token is rejected.
+	sessionID, _, err := splitValue(cookie.Value)
+	require.NoError(t, err)
+	expiredToken := h.(*csrf).sign(sessionID, time.Now().Add(-time.Minute))
+	assert.Equal(t, http.StatusForbidden, post(cookie, expiredToken))
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/requestid"
 	"github.com/containous/traefik/old/types"
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
@@ -90,6 +91,7 @@ func (c *customErrors) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			if len(c.backendQuery) > 0 {
 				query = "/" + strings.TrimPrefix(c.backendQuery, "/")
 				query = strings.Replace(query, "{status}", strconv.Itoa(recorder.GetCode()), -1)
+				query = strings.Replace(query, "{requestID}", url.QueryEscape(requestid.FromContext(req.Context())), -1)
 			}
 
 			pageReq, err := newRequest(backendURL + query)
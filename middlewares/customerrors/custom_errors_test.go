@@ -9,6 +9,7 @@ import (
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/requestid"
 	"github.com/containous/traefik/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -120,6 +121,35 @@ func TestHandler(t *testing.T) {
 	}
 }
 
+func TestHandlerRequestID(t *testing.T) {
+	serviceBuilderMock := &mockServiceBuilder{
+		handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(requestid.DefaultHeaderName, "error-page-id")
+			fmt.Fprintf(w, "My %s page, request %s.", r.URL.Path, r.URL.Query().Get("id"))
+		}),
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	errorPage := config.ErrorPage{Service: "error", Query: "/{status}?id={requestID}", Status: []string{"503"}}
+	errorPageHandler, err := New(context.Background(), handler, errorPage, serviceBuilderMock, "test")
+	require.NoError(t, err)
+
+	requestIDHandler, err := requestid.New(context.Background(), errorPageHandler, config.RequestID{}, "test")
+	require.NoError(t, err)
+
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost/test", nil)
+	req.Header.Set(requestid.DefaultHeaderName, "foo-bar")
+
+	recorder := httptest.NewRecorder()
+	requestIDHandler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "My /503 page, request foo-bar.", recorder.Body.String())
+	assert.Equal(t, []string{"foo-bar"}, recorder.Header()[http.CanonicalHeaderKey(requestid.DefaultHeaderName)])
+}
+
 type mockServiceBuilder struct {
 	handler http.Handler
 }
@@ -0,0 +1,172 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/http"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "Experiment"
+
+	defaultVariantHeader = "X-Experiment-Variant"
+)
+
+type serviceBuilder interface {
+	Build(ctx context.Context, serviceName string, responseModifier func(*http.Response) error) (http.Handler, error)
+}
+
+// experiment is a middleware assigning the requests to the variants of an A/B test,
+// the same key always getting the same variant.
+type experiment struct {
+	name          string
+	headerName    string
+	cookieName    string
+	variantHeader string
+	variantCookie string
+	variants      []variant
+	totalWeight   uint32
+	exposures     gokitmetrics.Counter
+}
+
+type variant struct {
+	name    string
+	weight  uint32
+	handler http.Handler
+}
+
+// New creates a new experiment middleware.
+// The requests of the variants without their own service are sent to the next handler.
+// The exposures counter counts the requests assigned to each variant, it can be nil.
+func New(ctx context.Context, next http.Handler, config config.Experiment, serviceBuilder serviceBuilder, exposures gokitmetrics.Counter, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if len(config.Variants) == 0 {
+		return nil, fmt.Errorf("no variant defined")
+	}
+
+	e := &experiment{
+		name:          name,
+		headerName:    config.HeaderName,
+		cookieName:    config.CookieName,
+		variantHeader: config.VariantHeader,
+		variantCookie: config.VariantCookie,
+		exposures:     exposures,
+	}
+	if len(e.variantHeader) == 0 {
+		e.variantHeader = defaultVariantHeader
+	}
+
+	names := make(map[string]bool)
+	for _, conf := range config.Variants {
+		if len(conf.Name) == 0 {
+			return nil, fmt.Errorf("a variant has no name")
+		}
+		if names[conf.Name] {
+			return nil, fmt.Errorf("duplicate variant %q", conf.Name)
+		}
+		names[conf.Name] = true
+
+		if conf.Weight < 0 {
+			return nil, fmt.Errorf("invalid weight %d of the variant %q", conf.Weight, conf.Name)
+		}
+		weight := uint32(conf.Weight)
+		if weight == 0 {
+			weight = 1
+		}
+
+		handler := next
+		if len(conf.Service) > 0 {
+			var err error
+			handler, err = serviceBuilder.Build(ctx, conf.Service, nil)
+			if err != nil {
+				return nil, fmt.Errorf("error building the service of the variant %q: %v", conf.Name, err)
+			}
+		}
+
+		e.variants = append(e.variants, variant{name: conf.Name, weight: weight, handler: handler})
+		e.totalWeight += weight
+	}
+
+	return e, nil
+}
+
+func (e *experiment) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return e.name, tracing.SpanKindNoneEnum
+}
+
+func (e *experiment) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	v := e.assign(req)
+
+	middlewares.GetLogger(req.Context(), e.name, typeName).Debugf("Assigning the request to the variant %s", v.name)
+	if e.exposures != nil {
+		e.exposures.With("experiment", e.name, "variant", v.name).Add(1)
+	}
+
+	req.Header.Set(e.variantHeader, v.name)
+
+	if len(e.variantCookie) > 0 {
+		if cookie, err := req.Cookie(e.variantCookie); err != nil || cookie.Value != v.name {
+			http.SetCookie(rw, &http.Cookie{Name: e.variantCookie, Value: v.name, Path: "/", HttpOnly: true})
+		}
+	}
+
+	v.handler.ServeHTTP(rw, req)
+}
+
+// assign returns the variant of the request: the one of its variant cookie,
+// else the one of the bucket its key hashes to.
+func (e *experiment) assign(req *http.Request) variant {
+	if len(e.variantCookie) > 0 {
+		if cookie, err := req.Cookie(e.variantCookie); err == nil {
+			for _, v := range e.variants {
+				if v.name == cookie.Value {
+					return v
+				}
+			}
+		}
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(e.name))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write([]byte(e.key(req)))
+
+	bucket := hash.Sum32() % e.totalWeight
+	for _, v := range e.variants {
+		if bucket < v.weight {
+			return v
+		}
+		bucket -= v.weight
+	}
+	return e.variants[len(e.variants)-1]
+}
+
+// key returns the value assigning the request to a variant.
+func (e *experiment) key(req *http.Request) string {
+	if len(e.headerName) > 0 {
+		if value := req.Header.Get(e.headerName); len(value) > 0 {
+			return value
+		}
+	}
+
+	if len(e.cookieName) > 0 {
+		if cookie, err := req.Cookie(e.cookieName); err == nil && len(cookie.Value) > 0 {
+			return cookie.Value
+		}
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,202 @@
+package experiment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeServiceBuilder map[string]http.Handler
+
+func (f fakeServiceBuilder) Build(ctx context.Context, serviceName string, responseModifier func(*http.Response) error) (http.Handler, error) {
+	handler, ok := f[serviceName]
+	if !ok {
+		return nil, errors.New("service not found")
+	}
+	return handler, nil
+}
+
+func TestNewConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config config.Experiment
+	}{
+		{
+			desc:   "no variant",
+			config: config.Experiment{},
+		},
+		{
+			desc:   "variant without name",
+			config: config.Experiment{Variants: []config.ExperimentVariant{{Weight: 1}}},
+		},
+		{
+			desc:   "duplicate variant",
+			config: config.Experiment{Variants: []config.ExperimentVariant{{Name: "a"}, {Name: "a"}}},
+		},
+		{
+			desc:   "negative weight",
+			config: config.Experiment{Variants: []config.ExperimentVariant{{Name: "a", Weight: -1}}},
+		},
+		{
+			desc:   "unknown service",
+			config: config.Experiment{Variants: []config.ExperimentVariant{{Name: "a", Service: "unknown"}}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, fakeServiceBuilder{}, nil, "experiment")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExperiment(t *testing.T) {
+	serviceBuilder := fakeServiceBuilder{
+		"new": http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = rw.Write([]byte("new"))
+		}),
+	}
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("current"))
+	})
+
+	testCases := []struct {
+		desc            string
+		config          config.Experiment
+		header          http.Header
+		cookies         []*http.Cookie
+		expectedVariant string
+		expectedBody    string
+		expectedCookie  string
+	}{
+		{
+			desc: "variant without weight",
+			config: config.Experiment{
+				Variants: []config.ExperimentVariant{{Name: "only", Weight: 0}},
+			},
+			expectedVariant: "only",
+			expectedBody:    "current",
+		},
+		{
+			desc: "variant with its own service",
+			config: config.Experiment{
+				Variants: []config.ExperimentVariant{{Name: "treatment", Weight: 1, Service: "new"}},
+			},
+			expectedVariant: "treatment",
+			expectedBody:    "new",
+		},
+		{
+			desc: "variant cookie overriding the assignment",
+			config: config.Experiment{
+				VariantCookie: "variant",
+				Variants:      []config.ExperimentVariant{{Name: "control", Weight: 1}, {Name: "treatment", Service: "new"}},
+			},
+			cookies:         []*http.Cookie{{Name: "variant", Value: "treatment"}},
+			expectedVariant: "treatment",
+			expectedBody:    "new",
+		},
+		{
+			desc: "unknown variant cookie",
+			config: config.Experiment{
+				VariantCookie: "variant",
+				Variants:      []config.ExperimentVariant{{Name: "control", Weight: 1}},
+			},
+			cookies:         []*http.Cookie{{Name: "variant", Value: "removed"}},
+			expectedVariant: "control",
+			expectedBody:    "current",
+			expectedCookie:  "variant=control",
+		},
+		{
+			desc: "custom variant header",
+			config: config.Experiment{
+				VariantHeader: "X-Variant",
+				Variants:      []config.ExperimentVariant{{Name: "control", Weight: 1}},
+			},
+			expectedVariant: "control",
+			expectedBody:    "current",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			exposures := &testhelpers.CollectingCounter{}
+			handler, err := New(context.Background(), next, test.config, serviceBuilder, exposures, "experiment")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			for _, cookie := range test.cookies {
+				req.AddCookie(cookie)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			variantHeader := test.config.VariantHeader
+			if len(variantHeader) == 0 {
+				variantHeader = defaultVariantHeader
+			}
+			assert.Equal(t, test.expectedVariant, req.Header.Get(variantHeader))
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+			assert.Equal(t, test.expectedCookie, firstCookie(recorder))
+
+			assert.Equal(t, float64(1), exposures.CounterValue)
+			assert.Equal(t, []string{"experiment", "experiment", "variant", test.expectedVariant}, exposures.LastLabelValues)
+		})
+	}
+}
+
+func TestExperimentDeterministicAssignment(t *testing.T) {
+	conf := config.Experiment{
+		HeaderName: "X-User",
+		Variants:   []config.ExperimentVariant{{Name: "control", Weight: 1}, {Name: "treatment", Weight: 1}},
+	}
+
+	handler, err := New(context.Background(), http.NotFoundHandler(), conf, nil, nil, "experiment")
+	require.NoError(t, err)
+
+	assigned := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		user := fmt.Sprintf("user-%d", i)
+
+		var variants []string
+		for j := 0; j < 3; j++ {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Set("X-User", user)
+			req.RemoteAddr = fmt.Sprintf("10.0.0.%d:1234", j)
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			variants = append(variants, req.Header.Get(defaultVariantHeader))
+		}
+
+		assert.Equal(t, variants[0], variants[1], user)
+		assert.Equal(t, variants[0], variants[2], user)
+		assigned[variants[0]]++
+	}
+
+	assert.Len(t, assigned, 2)
+	assert.NotZero(t, assigned["control"])
+	assert.NotZero(t, assigned["treatment"])
+}
+
+func firstCookie(recorder *httptest.ResponseRecorder) string {
+	cookies := recorder.Result().Cookies()
+	if len(cookies) == 0 {
+		return ""
+	}
+	return cookies[0].Name + "=" + cookies[0].Value
+}
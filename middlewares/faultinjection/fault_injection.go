@@ -0,0 +1,131 @@
+package faultinjection
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "FaultInjection"
+)
+
+// faultInjection is a middleware delaying requests and answering errors instead of forwarding them,
+// to test how clients handle timeouts and failures through the real proxy path.
+type faultInjection struct {
+	next            http.Handler
+	name            string
+	delay           time.Duration
+	delayPercentage float64
+	abortStatusCode int
+	abortPercentage float64
+	headerName      string
+	headerValue     string
+	random          func() float64
+}
+
+// New creates a new fault injection middleware.
+func New(ctx context.Context, next http.Handler, config config.FaultInjection, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	f := &faultInjection{
+		next:        next,
+		name:        name,
+		headerName:  config.HeaderName,
+		headerValue: config.HeaderValue,
+		random:      rand.Float64,
+	}
+
+	if config.Delay != nil {
+		if config.Delay.Duration <= 0 {
+			return nil, fmt.Errorf("invalid delay: %v", config.Delay.Duration)
+		}
+		if err := checkPercentage(config.Delay.Percentage); err != nil {
+			return nil, err
+		}
+		f.delay = time.Duration(config.Delay.Duration)
+		f.delayPercentage = config.Delay.Percentage
+	}
+
+	if config.Abort != nil {
+		if config.Abort.StatusCode < 100 || config.Abort.StatusCode > 599 {
+			return nil, fmt.Errorf("invalid abort status code: %d", config.Abort.StatusCode)
+		}
+		if err := checkPercentage(config.Abort.Percentage); err != nil {
+			return nil, err
+		}
+		f.abortStatusCode = config.Abort.StatusCode
+		f.abortPercentage = config.Abort.Percentage
+	}
+
+	return f, nil
+}
+
+func checkPercentage(percentage float64) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("the percentage must be between 0 and 100: %v", percentage)
+	}
+	return nil
+}
+
+func (f *faultInjection) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return f.name, tracing.SpanKindNoneEnum
+}
+
+func (f *faultInjection) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !f.matches(req) {
+		f.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if f.delay > 0 && f.selected(f.delayPercentage) {
+		timer := time.NewTimer(f.delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	if f.abortStatusCode > 0 && f.selected(f.abortPercentage) {
+		middlewares.GetLogger(req.Context(), f.name, typeName).Debugf("Aborting the request with the status code %d", f.abortStatusCode)
+		http.Error(rw, http.StatusText(f.abortStatusCode), f.abortStatusCode)
+		return
+	}
+
+	f.next.ServeHTTP(rw, req)
+}
+
+func (f *faultInjection) matches(req *http.Request) bool {
+	if len(f.headerName) == 0 {
+		return true
+	}
+
+	values, ok := req.Header[http.CanonicalHeaderKey(f.headerName)]
+	if !ok {
+		return false
+	}
+	if len(f.headerValue) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		if value == f.headerValue {
+			return true
+		}
+	}
+	return false
+}
+
+// selected returns true for the given percentage of the requests, or for all of them when the percentage is 0.
+func (f *faultInjection) selected(percentage float64) bool {
+	return percentage == 0 || f.random()*100 < percentage
+}
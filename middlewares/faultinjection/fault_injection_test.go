@@ -0,0 +1,176 @@
+package faultinjection
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config config.FaultInjection
+	}{
+		{
+			desc:   "invalid delay",
+			config: config.FaultInjection{Delay: &config.FaultDelay{}},
+		},
+		{
+			desc:   "invalid delay percentage",
+			config: config.FaultInjection{Delay: &config.FaultDelay{Duration: parse.Duration(time.Second), Percentage: 101}},
+		},
+		{
+			desc:   "invalid abort status code",
+			config: config.FaultInjection{Abort: &config.FaultAbort{StatusCode: 42}},
+		},
+		{
+			desc:   "invalid abort percentage",
+			config: config.FaultInjection{Abort: &config.FaultAbort{StatusCode: 503, Percentage: -1}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "fault")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             config.FaultInjection
+		random             float64
+		headers            map[string]string
+		expectedStatusCode int
+		expectedDelay      bool
+	}{
+		{
+			desc:               "abort all the requests",
+			config:             config.FaultInjection{Abort: &config.FaultAbort{StatusCode: http.StatusServiceUnavailable}},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			desc:               "abort selected request",
+			config:             config.FaultInjection{Abort: &config.FaultAbort{StatusCode: http.StatusServiceUnavailable, Percentage: 50}},
+			random:             0.4,
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			desc:               "abort not selected request",
+			config:             config.FaultInjection{Abort: &config.FaultAbort{StatusCode: http.StatusServiceUnavailable, Percentage: 50}},
+			random:             0.6,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc: "delay",
+			config: config.FaultInjection{
+				Delay: &config.FaultDelay{Duration: parse.Duration(50 * time.Millisecond)},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedDelay:      true,
+		},
+		{
+			desc: "delay and abort",
+			config: config.FaultInjection{
+				Delay: &config.FaultDelay{Duration: parse.Duration(50 * time.Millisecond)},
+				Abort: &config.FaultAbort{StatusCode: http.StatusGatewayTimeout},
+			},
+			expectedStatusCode: http.StatusGatewayTimeout,
+			expectedDelay:      true,
+		},
+		{
+			desc: "request without header",
+			config: config.FaultInjection{
+				HeaderName: "X-Chaos",
+				Abort:      &config.FaultAbort{StatusCode: http.StatusServiceUnavailable},
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc: "request with header",
+			config: config.FaultInjection{
+				HeaderName: "X-Chaos",
+				Abort:      &config.FaultAbort{StatusCode: http.StatusServiceUnavailable},
+			},
+			headers:            map[string]string{"X-Chaos": "foo"},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			desc: "request with header value",
+			config: config.FaultInjection{
+				HeaderName:  "X-Chaos",
+				HeaderValue: "true",
+				Abort:       &config.FaultAbort{StatusCode: http.StatusServiceUnavailable},
+			},
+			headers:            map[string]string{"X-Chaos": "true"},
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			desc: "request with another header value",
+			config: config.FaultInjection{
+				HeaderName:  "X-Chaos",
+				HeaderValue: "true",
+				Abort:       &config.FaultAbort{StatusCode: http.StatusServiceUnavailable},
+			},
+			headers:            map[string]string{"X-Chaos": "false"},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			handler, err := New(context.Background(), next, test.config, "fault")
+			require.NoError(t, err)
+			handler.(*faultInjection).random = func() float64 { return test.random }
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			start := time.Now()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			if test.expectedDelay {
+				assert.True(t, time.Since(start) >= 50*time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestFaultInjectionCanceledRequest(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Error("the request must not be forwarded")
+	})
+
+	handler, err := New(context.Background(), next, config.FaultInjection{
+		Delay: &config.FaultDelay{Duration: parse.Duration(time.Hour)},
+	}, "fault")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil).WithContext(ctx))
+}
@@ -0,0 +1,130 @@
+package forwardedheaders
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/ip"
+	"github.com/vulcand/oxy/forward"
+	"github.com/vulcand/oxy/utils"
+)
+
+type key string
+
+const clientIPKey key = "clientIP"
+
+// XForwarded is the entry point middleware handling the X-Forwarded-* headers.
+// The headers sent by untrusted peers are removed: the forwarder then sets them from the connection.
+// The headers sent by trusted peers are kept, and the forwarder appends the peer to X-Forwarded-For.
+// The middleware resolves the IP of the client, and stores it in the request context.
+type XForwarded struct {
+	insecure  bool
+	ipChecker *ip.Checker
+	next      http.Handler
+}
+
+// NewXForwarded creates a new XForwarded.
+// When insecure is true, all the peers are trusted.
+func NewXForwarded(insecure bool, trustedIPs []string, next http.Handler) (*XForwarded, error) {
+	var ipChecker *ip.Checker
+	if len(trustedIPs) > 0 {
+		var err error
+		ipChecker, err = ip.NewChecker(trustedIPs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &XForwarded{
+		insecure:  insecure,
+		ipChecker: ipChecker,
+		next:      next,
+	}, nil
+}
+
+func (x *XForwarded) isTrustedIP(addr string) bool {
+	if x.insecure {
+		return true
+	}
+	if x.ipChecker == nil {
+		return false
+	}
+	return x.ipChecker.IsAuthorized(addr) == nil
+}
+
+func (x *XForwarded) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	trusted := x.isTrustedIP(req.RemoteAddr)
+	if !trusted {
+		utils.RemoveHeaders(req.Header, forward.XHeaders...)
+	}
+
+	clientIP := x.clientIP(req, trusted)
+
+	x.next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), clientIPKey, clientIP)))
+}
+
+// clientIP returns the rightmost address of X-Forwarded-For which is not a trusted proxy,
+// the addresses on its left being set by the client itself, or the peer address when it isn't trusted.
+func (x *XForwarded) clientIP(req *http.Request, trusted bool) string {
+	peer := hostOf(req.RemoteAddr)
+	if !trusted {
+		return peer
+	}
+
+	var forwardedFor []string
+	for _, value := range req.Header[forward.XForwardedFor] {
+		for _, address := range strings.Split(value, ",") {
+			if address = strings.TrimSpace(address); len(address) > 0 {
+				forwardedFor = append(forwardedFor, address)
+			}
+		}
+	}
+
+	if len(forwardedFor) == 0 {
+		return peer
+	}
+
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		if !x.isTrustedIP(forwardedFor[i]) {
+			return forwardedFor[i]
+		}
+	}
+
+	// All the addresses are trusted proxies: the leftmost one is the closest to the client.
+	return forwardedFor[0]
+}
+
+// ClientIP returns the IP of the client resolved by the middleware, if the request went through it.
+func ClientIP(ctx context.Context) (string, bool) {
+	clientIP, ok := ctx.Value(clientIPKey).(string)
+	return clientIP, ok
+}
+
+// GetClientIP returns the IP of the client resolved by the middleware, or the IP of the peer.
+func GetClientIP(req *http.Request) string {
+	if clientIP, ok := ClientIP(req.Context()); ok {
+		return clientIP
+	}
+	return hostOf(req.RemoteAddr)
+}
+
+// NewExtractor creates the source extractor of the rate limiting and max connection middlewares.
+// The client.ip source is the IP of the client resolved by the middleware.
+func NewExtractor(variable string) (utils.SourceExtractor, error) {
+	if variable == "client.ip" {
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			return GetClientIP(req), 1, nil
+		}), nil
+	}
+	return utils.NewExtractor(variable)
+}
+
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,158 @@
+package forwardedheaders
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		insecure         bool
+		trustedIPs       []string
+		remoteAddr       string
+		incomingHeaders  map[string]string
+		expectedHeaders  map[string]string
+		expectedClientIP string
+	}{
+		{
+			desc:             "all empty",
+			remoteAddr:       "10.0.1.100:80",
+			expectedHeaders:  map[string]string{"X-Forwarded-For": ""},
+			expectedClientIP: "10.0.1.100",
+		},
+		{
+			desc:       "insecure true with incoming X-Forwarded-For",
+			insecure:   true,
+			remoteAddr: "10.0.1.100:80",
+			incomingHeaders: map[string]string{
+				"X-Forwarded-For": "10.0.1.0, 10.0.1.12",
+			},
+			expectedHeaders: map[string]string{
+				"X-Forwarded-For": "10.0.1.0, 10.0.1.12",
+			},
+			expectedClientIP: "10.0.1.0",
+		},
+		{
+			desc:       "untrusted peer",
+			remoteAddr: "10.0.1.100:80",
+			incomingHeaders: map[string]string{
+				"X-Forwarded-For":   "10.0.1.0, 10.0.1.12",
+				"X-Forwarded-Proto": "https",
+				"X-Forwarded-Host":  "foo.com",
+			},
+			expectedHeaders: map[string]string{
+				"X-Forwarded-For":   "",
+				"X-Forwarded-Proto": "",
+				"X-Forwarded-Host":  "",
+			},
+			expectedClientIP: "10.0.1.100",
+		},
+		{
+			desc:       "trusted peer",
+			trustedIPs: []string{"10.0.1.100"},
+			remoteAddr: "10.0.1.100:80",
+			incomingHeaders: map[string]string{
+				"X-Forwarded-For":   "10.0.1.0, 10.0.1.12",
+				"X-Forwarded-Proto": "https",
+			},
+			expectedHeaders: map[string]string{
+				"X-Forwarded-For":   "10.0.1.0, 10.0.1.12",
+				"X-Forwarded-Proto": "https",
+			},
+			expectedClientIP: "10.0.1.12",
+		},
+		{
+			desc:       "trusted peer behind trusted proxies",
+			trustedIPs: []string{"10.0.1.0/24"},
+			remoteAddr: "10.0.1.100:80",
+			incomingHeaders: map[string]string{
+				"X-Forwarded-For": "1.2.3.4, 5.6.7.8, 10.0.1.12",
+			},
+			expectedClientIP: "5.6.7.8",
+		},
+		{
+			desc:       "trusted peer with only trusted proxies",
+			trustedIPs: []string{"10.0.1.0/24"},
+			remoteAddr: "10.0.1.100:80",
+			incomingHeaders: map[string]string{
+				"X-Forwarded-For": "10.0.1.1, 10.0.1.12",
+			},
+			expectedClientIP: "10.0.1.1",
+		},
+		{
+			desc:             "trusted peer without X-Forwarded-For",
+			trustedIPs:       []string{"10.0.1.100"},
+			remoteAddr:       "10.0.1.100:80",
+			expectedClientIP: "10.0.1.100",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.remoteAddr
+			for k, v := range test.incomingHeaders {
+				req.Header.Set(k, v)
+			}
+
+			var clientIP string
+			var headers http.Header
+			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				clientIP = GetClientIP(r)
+				headers = r.Header
+			})
+
+			m, err := NewXForwarded(test.insecure, test.trustedIPs, next)
+			require.NoError(t, err)
+
+			m.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, test.expectedClientIP, clientIP)
+			for k, v := range test.expectedHeaders {
+				assert.Equal(t, v, headers.Get(k))
+			}
+		})
+	}
+}
+
+func TestNewXForwardedInvalidTrustedIPs(t *testing.T) {
+	_, err := NewXForwarded(false, []string{"foo"}, nil)
+	assert.Error(t, err)
+}
+
+func TestNewExtractor(t *testing.T) {
+	extractor, err := NewExtractor("client.ip")
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		source, amount, err := extractor.Extract(r)
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.2.3.4", source)
+		assert.EqualValues(t, 1, amount)
+	})
+
+	m, err := NewXForwarded(false, []string{"10.0.1.100"}, next)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.1.100:80"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+
+	m.ServeHTTP(httptest.NewRecorder(), req)
+
+	extractor, err = NewExtractor("request.host")
+	require.NoError(t, err)
+
+	source, _, err := extractor.Extract(req)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", source)
+}
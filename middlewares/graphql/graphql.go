@@ -0,0 +1,236 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "GraphQL"
+
+	defaultOperationHeader = "X-GraphQL-Operation"
+	defaultMaxBodySize     = 1024 * 1024
+)
+
+// The outcomes of the operations.
+const (
+	outcomeAllowed  = "allowed"
+	outcomeRejected = "rejected"
+)
+
+// request is a GraphQL request, several of them being sent together in a batch.
+type request struct {
+	Query         string `json:"query"`
+	OperationName string `json:"operationName"`
+}
+
+// graphQL is a middleware rejecting the GraphQL requests whose queries are too deep or too complex, or query the schema,
+// before they reach the GraphQL server.
+type graphQL struct {
+	next                 http.Handler
+	name                 string
+	maxDepth             int
+	maxComplexity        int
+	disableIntrospection bool
+	operationHeader      string
+	maxBodySize          int64
+	operations           gokitmetrics.Counter
+}
+
+// New creates a new GraphQL protection middleware.
+// The operations counter counts the operations by name, type and outcome, it can be nil.
+func New(ctx context.Context, next http.Handler, config config.GraphQL, operations gokitmetrics.Counter, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if config.MaxDepth < 0 {
+		return nil, fmt.Errorf("invalid maximum depth %d", config.MaxDepth)
+	}
+	if config.MaxComplexity < 0 {
+		return nil, fmt.Errorf("invalid maximum complexity %d", config.MaxComplexity)
+	}
+	if config.MaxBodySize < 0 {
+		return nil, fmt.Errorf("invalid maximum body size %d", config.MaxBodySize)
+	}
+
+	g := &graphQL{
+		next:                 next,
+		name:                 name,
+		maxDepth:             config.MaxDepth,
+		maxComplexity:        config.MaxComplexity,
+		disableIntrospection: config.DisableIntrospection,
+		operationHeader:      config.OperationHeader,
+		maxBodySize:          config.MaxBodySize,
+		operations:           operations,
+	}
+	if len(g.operationHeader) == 0 {
+		g.operationHeader = defaultOperationHeader
+	}
+	if g.maxBodySize == 0 {
+		g.maxBodySize = defaultMaxBodySize
+	}
+
+	return g, nil
+}
+
+func (g *graphQL) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return g.name, tracing.SpanKindNoneEnum
+}
+
+func (g *graphQL) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), g.name, typeName)
+
+	// The client can't choose the key of its operations.
+	req.Header.Del(g.operationHeader)
+
+	requests, err := g.readRequests(req)
+	if err != nil {
+		logger.Debugf("Invalid GraphQL request: %v", err)
+		writeError(rw, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(requests) == 0 {
+		g.next.ServeHTTP(rw, req)
+		return
+	}
+
+	var names []string
+	for _, r := range requests {
+		op, err := g.check(r)
+		if err != nil {
+			logger.Debugf("GraphQL operation %q rejected: %v", r.OperationName, err)
+			g.count(op, outcomeRejected)
+			writeError(rw, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		g.count(op, outcomeAllowed)
+		if len(op.name) > 0 {
+			names = append(names, op.name)
+		}
+	}
+
+	if len(names) > 0 {
+		req.Header.Set(g.operationHeader, strings.Join(names, ","))
+	}
+
+	g.next.ServeHTTP(rw, req)
+}
+
+// readRequests returns the GraphQL requests of a GET request, or of the body of a POST request, restoring the body.
+// The requests without a GraphQL query are not GraphQL requests.
+func (g *graphQL) readRequests(req *http.Request) ([]request, error) {
+	switch req.Method {
+	case http.MethodGet:
+		query := req.URL.Query()
+		if len(query.Get("query")) == 0 {
+			return nil, nil
+		}
+		return []request{{Query: query.Get("query"), OperationName: query.Get("operationName")}}, nil
+	case http.MethodPost:
+	default:
+		return nil, nil
+	}
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, g.maxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the body: %v", err)
+	}
+	if int64(len(body)) > g.maxBodySize {
+		return nil, fmt.Errorf("body larger than %d bytes", g.maxBodySize)
+	}
+	_ = req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType == "application/graphql" {
+		return []request{{Query: string(body), OperationName: req.URL.Query().Get("operationName")}}, nil
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var requests []request
+		if err := json.Unmarshal(trimmed, &requests); err != nil {
+			return nil, fmt.Errorf("invalid JSON body: %v", err)
+		}
+		for _, r := range requests {
+			if len(r.Query) == 0 {
+				return nil, errors.New("missing query in the batch")
+			}
+		}
+		return requests, nil
+	}
+
+	var r request
+	if err := json.Unmarshal(trimmed, &r); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+	if len(r.Query) == 0 {
+		return nil, errors.New("missing query")
+	}
+	return []request{r}, nil
+}
+
+// check returns the operation to execute of a request, or why it is rejected.
+func (g *graphQL) check(r request) (*operation, error) {
+	doc, err := parse(r.Query)
+	if err != nil {
+		return &operation{name: r.OperationName}, fmt.Errorf("invalid query: %v", err)
+	}
+
+	op, err := doc.operation(r.OperationName)
+	if err != nil {
+		return &operation{name: r.OperationName}, err
+	}
+
+	m, err := doc.analyze(op)
+	if err != nil {
+		return op, fmt.Errorf("invalid query: %v", err)
+	}
+
+	if g.disableIntrospection && m.introspection {
+		return op, errors.New("introspection is disabled")
+	}
+	if g.maxDepth > 0 && m.depth > g.maxDepth {
+		return op, fmt.Errorf("query depth %d exceeds the maximum %d", m.depth, g.maxDepth)
+	}
+	if g.maxComplexity > 0 && m.complexity > g.maxComplexity {
+		return op, fmt.Errorf("query complexity %d exceeds the maximum %d", m.complexity, g.maxComplexity)
+	}
+	return op, nil
+}
+
+func (g *graphQL) count(op *operation, outcome string) {
+	if g.operations == nil {
+		return
+	}
+	g.operations.With("middleware", g.name, "operation", op.name, "type", op.kind, "outcome", outcome).Add(1)
+}
+
+// writeError writes a GraphQL error response.
+func writeError(rw http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
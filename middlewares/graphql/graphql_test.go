@@ -0,0 +1,297 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		query         string
+		operationName string
+		expected      measure
+		expectedError bool
+	}{
+		{
+			desc:     "shorthand query",
+			query:    `{ user { name } }`,
+			expected: measure{depth: 2, complexity: 2},
+		},
+		{
+			desc: "arguments, aliases, directives and comments",
+			query: `query GetUser($id: ID!, $filter: Filter = {tags: ["a", "b"]}) @cached(ttl: 60) {
+				# the user
+				me: user(id: $id, bio: """a "quoted" bio""") @include(if: true) {
+					name(format: "full\"name")
+					friends(first: 10, filter: $filter) { name }
+				}
+			}`,
+			expected: measure{depth: 3, complexity: 4},
+		},
+		{
+			desc: "fragments",
+			query: `query { user { ...UserFields ... on Admin { permissions { name } } } }
+				fragment UserFields on User { name friends { ...FriendFields } }
+				fragment FriendFields on User { name }`,
+			expected: measure{depth: 3, complexity: 6},
+		},
+		{
+			desc:     "introspection",
+			query:    `{ __schema { types { name } } }`,
+			expected: measure{depth: 3, complexity: 3, introspection: true},
+		},
+		{
+			desc:     "type name",
+			query:    `{ user { __typename } }`,
+			expected: measure{depth: 2, complexity: 2},
+		},
+		{
+			desc:          "selected operation",
+			query:         `query A { a } query B { b { c } }`,
+			operationName: "B",
+			expected:      measure{depth: 2, complexity: 2},
+		},
+		{
+			desc:          "several operations without name",
+			query:         `query A { a } query B { b }`,
+			expectedError: true,
+		},
+		{
+			desc:          "unknown operation",
+			query:         `query A { a }`,
+			operationName: "B",
+			expectedError: true,
+		},
+		{
+			desc:          "fragment cycle",
+			query:         `{ ...A } fragment A on Query { a { ...B } } fragment B on A { ...A }`,
+			expectedError: true,
+		},
+		{
+			desc:          "unknown fragment",
+			query:         `{ ...A }`,
+			expectedError: true,
+		},
+		{
+			desc:          "unterminated selection set",
+			query:         `{ user { name }`,
+			expectedError: true,
+		},
+		{
+			desc:          "schema definition",
+			query:         `type User { name: String }`,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			doc, err := parse(test.query)
+			var m measure
+			if err == nil {
+				var op *operation
+				op, err = doc.operation(test.operationName)
+				if err == nil {
+					m, err = doc.analyze(op)
+				}
+			}
+
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, m)
+		})
+	}
+}
+
+func TestAnalyzeFragmentBomb(t *testing.T) {
+	// Each fragment spreads the next one twice, doubling the complexity at each level.
+	query := "{ ...F0 }"
+	for i := 0; i < 50; i++ {
+		query += fmt.Sprintf(" fragment F%d on Q { a ...F%d b: a ...F%d }", i, i+1, i+1)
+	}
+	query += " fragment F50 on Q { a }"
+
+	doc, err := parse(query)
+	require.NoError(t, err)
+	op, err := doc.operation("")
+	require.NoError(t, err)
+
+	m, err := doc.analyze(op)
+	require.NoError(t, err)
+	assert.Equal(t, maxComplexity, m.complexity)
+}
+
+func TestGraphQL(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		config            config.GraphQL
+		method            string
+		contentType       string
+		query             url.Values
+		body              string
+		expectedStatus    int
+		expectedOperation string
+		expectedCount     float64
+		expectedLabels    []string
+	}{
+		{
+			desc:              "allowed query",
+			config:            config.GraphQL{MaxDepth: 3, MaxComplexity: 5},
+			method:            http.MethodPost,
+			body:              `{"query":"query GetUser { user { name } }","variables":{"id":1}}`,
+			expectedStatus:    http.StatusOK,
+			expectedOperation: "GetUser",
+			expectedCount:     1,
+			expectedLabels:    []string{"middleware", "graphql", "operation", "GetUser", "type", "query", "outcome", "allowed"},
+		},
+		{
+			desc:           "too deep query",
+			config:         config.GraphQL{MaxDepth: 2},
+			method:         http.MethodPost,
+			body:           `{"query":"query Deep { a { b { c } } }"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCount:  1,
+			expectedLabels: []string{"middleware", "graphql", "operation", "Deep", "type", "query", "outcome", "rejected"},
+		},
+		{
+			desc:           "too complex query",
+			config:         config.GraphQL{MaxComplexity: 2},
+			method:         http.MethodPost,
+			body:           `{"query":"mutation Update { a b c }"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCount:  1,
+			expectedLabels: []string{"middleware", "graphql", "operation", "Update", "type", "mutation", "outcome", "rejected"},
+		},
+		{
+			desc:           "disabled introspection",
+			config:         config.GraphQL{DisableIntrospection: true},
+			method:         http.MethodGet,
+			query:          url.Values{"query": {"{ __type(name: \"User\") { name } }"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedCount:  1,
+		},
+		{
+			desc:              "GET query",
+			config:            config.GraphQL{DisableIntrospection: true},
+			method:            http.MethodGet,
+			query:             url.Values{"query": {"query A { a } query B { b }"}, "operationName": {"B"}},
+			expectedStatus:    http.StatusOK,
+			expectedOperation: "B",
+			expectedCount:     1,
+		},
+		{
+			desc:              "application/graphql body",
+			config:            config.GraphQL{MaxDepth: 1},
+			method:            http.MethodPost,
+			contentType:       "application/graphql",
+			body:              `query Me { me }`,
+			expectedStatus:    http.StatusOK,
+			expectedOperation: "Me",
+			expectedCount:     1,
+		},
+		{
+			desc:              "batch",
+			config:            config.GraphQL{MaxDepth: 2},
+			method:            http.MethodPost,
+			body:              `[{"query":"query A { a }"},{"query":"query B { b { c } }"}]`,
+			expectedStatus:    http.StatusOK,
+			expectedOperation: "A,B",
+			expectedCount:     2,
+		},
+		{
+			desc:           "batch with a rejected operation",
+			config:         config.GraphQL{MaxDepth: 1},
+			method:         http.MethodPost,
+			body:           `[{"query":"query A { a }"},{"query":"query B { b { c } }"}]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCount:  2,
+		},
+		{
+			desc:           "invalid query",
+			config:         config.GraphQL{},
+			method:         http.MethodPost,
+			body:           `{"query":"{ a "}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCount:  1,
+		},
+		{
+			desc:           "invalid body",
+			config:         config.GraphQL{},
+			method:         http.MethodPost,
+			body:           `query { a }`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "body too large",
+			config:         config.GraphQL{MaxBodySize: 10},
+			method:         http.MethodPost,
+			body:           `{"query":"{ a }"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "not a GraphQL request",
+			config:         config.GraphQL{},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var operation, body string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				operation = req.Header.Get(defaultOperationHeader)
+				content, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				body = string(content)
+			})
+
+			counter := &testhelpers.CollectingCounter{}
+			handler, err := New(context.Background(), next, test.config, counter, "graphql")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(test.method, "http://localhost/graphql?"+test.query.Encode(), strings.NewReader(test.body))
+			req.Header.Set(defaultOperationHeader, "spoofed")
+			if len(test.contentType) > 0 {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedCount, counter.CounterValue)
+			if test.expectedLabels != nil {
+				assert.Equal(t, test.expectedLabels, counter.LastLabelValues)
+			}
+
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, test.expectedOperation, operation)
+				assert.Equal(t, test.body, body)
+			} else {
+				assert.Contains(t, recorder.Body.String(), `"errors"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,492 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The kinds of the tokens of a GraphQL document.
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenValue
+)
+
+type token struct {
+	kind  int
+	value string
+}
+
+// lexer splits a GraphQL document into tokens, skipping the white spaces, the commas and the comments.
+// The numbers and the strings are value tokens, their content being irrelevant here.
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.input) && l.input[l.pos] != '\n' && l.input[l.pos] != '\r' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.input[l.pos:], "\xef\xbb\xbf"):
+			l.pos += 3
+		default:
+			return l.read()
+		}
+	}
+	return token{kind: tokenEOF}, nil
+}
+
+func (l *lexer) read() (token, error) {
+	start := l.pos
+	c := l.input[l.pos]
+
+	switch {
+	case strings.HasPrefix(l.input[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokenPunctuator, value: "..."}, nil
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunctuator, value: string(c)}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.input) && (l.input[l.pos] == '_' || isLetter(l.input[l.pos]) || isDigit(l.input[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.input[start:l.pos]}, nil
+	case c == '-' || isDigit(c):
+		l.pos++
+		for l.pos < len(l.input) && strings.IndexByte("0123456789.eE+-", l.input[l.pos]) >= 0 {
+			l.pos++
+		}
+		return token{kind: tokenValue, value: l.input[start:l.pos]}, nil
+	case strings.HasPrefix(l.input[l.pos:], `"""`):
+		end := strings.Index(l.input[l.pos+3:], `"""`)
+		for end >= 0 && l.input[l.pos+3+end-1] == '\\' {
+			next := strings.Index(l.input[l.pos+3+end+3:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += 3 + next
+		}
+		if end < 0 {
+			return token{}, errors.New("unterminated block string")
+		}
+		l.pos += 3 + end + 3
+		return token{kind: tokenValue, value: l.input[start:l.pos]}, nil
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.input) {
+			switch l.input[l.pos] {
+			case '\\':
+				l.pos += 2
+				continue
+			case '"':
+				l.pos++
+				return token{kind: tokenValue, value: l.input[start:l.pos]}, nil
+			case '\n', '\r':
+				return token{}, errors.New("unterminated string")
+			}
+			l.pos++
+		}
+		return token{}, errors.New("unterminated string")
+	default:
+		return token{}, fmt.Errorf("unexpected character %q", c)
+	}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// selection is a field, a fragment spread or an inline fragment of a selection set.
+type selection struct {
+	name     string
+	spread   string
+	children []*selection
+}
+
+type operation struct {
+	kind       string
+	name       string
+	selections []*selection
+}
+
+type document struct {
+	operations []*operation
+	fragments  map[string][]*selection
+}
+
+// parser parses the executable definitions of a GraphQL document, keeping their selections only.
+type parser struct {
+	lexer   *lexer
+	current token
+}
+
+func parse(query string) (*document, error) {
+	p := &parser{lexer: &lexer{input: query}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string][]*selection)}
+	for p.current.kind != tokenEOF {
+		if p.is(tokenPunctuator, "{") {
+			selections, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", selections: selections})
+			continue
+		}
+
+		if p.current.kind != tokenName {
+			return nil, fmt.Errorf("unexpected %q", p.current.value)
+		}
+
+		switch keyword := p.current.value; keyword {
+		case "query", "mutation", "subscription":
+			op, err := p.operation(keyword)
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case "fragment":
+			name, selections, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[name]; ok {
+				return nil, fmt.Errorf("duplicate fragment %q", name)
+			}
+			doc.fragments[name] = selections
+		default:
+			return nil, fmt.Errorf("unsupported definition %q", keyword)
+		}
+	}
+
+	if len(doc.operations) == 0 {
+		return nil, errors.New("no operation")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() error {
+	var err error
+	p.current, err = p.lexer.next()
+	return err
+}
+
+func (p *parser) is(kind int, value string) bool {
+	return p.current.kind == kind && p.current.value == value
+}
+
+func (p *parser) expect(kind int, value string) error {
+	if !p.is(kind, value) {
+		return fmt.Errorf("expected %q, got %q", value, p.current.value)
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.current.kind != tokenName {
+		return "", fmt.Errorf("expected a name, got %q", p.current.value)
+	}
+	name := p.current.value
+	return name, p.advance()
+}
+
+func (p *parser) operation(kind string) (*operation, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	op := &operation{kind: kind}
+	if p.current.kind == tokenName {
+		op.name = p.current.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	// The variable definitions and the directives don't change the selections.
+	if p.is(tokenPunctuator, "(") {
+		if err := p.skipBalanced(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	op.selections, err = p.selectionSet()
+	return op, err
+}
+
+func (p *parser) fragment() (string, []*selection, error) {
+	if err := p.advance(); err != nil {
+		return "", nil, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return "", nil, err
+	}
+	if !p.is(tokenName, "on") {
+		return "", nil, fmt.Errorf("expected the type condition of the fragment %q", name)
+	}
+	if err := p.advance(); err != nil {
+		return "", nil, err
+	}
+	if _, err := p.name(); err != nil {
+		return "", nil, err
+	}
+	if err := p.directives(); err != nil {
+		return "", nil, err
+	}
+
+	selections, err := p.selectionSet()
+	return name, selections, err
+}
+
+func (p *parser) selectionSet() ([]*selection, error) {
+	if err := p.expect(tokenPunctuator, "{"); err != nil {
+		return nil, err
+	}
+
+	var selections []*selection
+	for !p.is(tokenPunctuator, "}") {
+		if p.current.kind == tokenEOF {
+			return nil, errors.New("unterminated selection set")
+		}
+
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, s)
+	}
+
+	if len(selections) == 0 {
+		return nil, errors.New("empty selection set")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (*selection, error) {
+	if p.is(tokenPunctuator, "...") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		// Fragment spread.
+		if p.current.kind == tokenName && p.current.value != "on" {
+			s := &selection{spread: p.current.value}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			return s, p.directives()
+		}
+
+		// Inline fragment.
+		if p.is(tokenName, "on") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.directives(); err != nil {
+			return nil, err
+		}
+		children, err := p.selectionSet()
+		return &selection{children: children}, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if p.is(tokenPunctuator, ":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &selection{name: name}
+	if p.is(tokenPunctuator, "(") {
+		if err := p.skipBalanced(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	if p.is(tokenPunctuator, "{") {
+		if s.children, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) directives() error {
+	for p.is(tokenPunctuator, "@") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if p.is(tokenPunctuator, "(") {
+			if err := p.skipBalanced(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// skipBalanced skips the tokens up to the bracket closing the current one, like the arguments of a field.
+func (p *parser) skipBalanced() error {
+	var stack []string
+	closing := map[string]string{"(": ")", "[": "]", "{": "}"}
+	for {
+		if p.current.kind == tokenEOF {
+			return errors.New("unbalanced brackets")
+		}
+		if p.current.kind == tokenPunctuator {
+			if c, ok := closing[p.current.value]; ok {
+				stack = append(stack, c)
+			} else if len(stack) > 0 && p.current.value == stack[len(stack)-1] {
+				stack = stack[:len(stack)-1]
+			} else if p.current.value == ")" || p.current.value == "]" || p.current.value == "}" {
+				return fmt.Errorf("unexpected %q", p.current.value)
+			}
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if len(stack) == 0 {
+			return nil
+		}
+	}
+}
+
+// operation returns the operation of the document to execute, by its name when the document has several operations.
+func (d *document) operation(name string) (*operation, error) {
+	if len(name) == 0 {
+		if len(d.operations) > 1 {
+			return nil, errors.New("an operation name is required by a document with several operations")
+		}
+		return d.operations[0], nil
+	}
+
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// maxComplexity caps the complexity of the selection sets, which grows exponentially with the nested fragments.
+const maxComplexity = 1 << 30
+
+// measure holds the measures of a selection set.
+type measure struct {
+	depth         int
+	complexity    int
+	introspection bool
+}
+
+// analyzer measures the selection sets, the fragments being expanded and measured once.
+type analyzer struct {
+	fragments map[string][]*selection
+	measured  map[string]measure
+	visiting  map[string]bool
+}
+
+func (d *document) analyze(op *operation) (measure, error) {
+	a := &analyzer{
+		fragments: d.fragments,
+		measured:  make(map[string]measure),
+		visiting:  make(map[string]bool),
+	}
+	return a.measure(op.selections)
+}
+
+// measure returns the depth of a selection set, its complexity, being the number of the fields it selects,
+// and whether it queries the schema.
+func (a *analyzer) measure(selections []*selection) (measure, error) {
+	var m measure
+	for _, s := range selections {
+		var child measure
+		var err error
+		switch {
+		case len(s.spread) > 0:
+			child, err = a.measureFragment(s.spread)
+		case len(s.name) == 0:
+			child, err = a.measure(s.children)
+		default:
+			child, err = a.measure(s.children)
+			child.depth++
+			child.complexity++
+			child.introspection = child.introspection || s.name == "__schema" || s.name == "__type"
+		}
+		if err != nil {
+			return measure{}, err
+		}
+
+		if child.depth > m.depth {
+			m.depth = child.depth
+		}
+		m.complexity += child.complexity
+		if m.complexity > maxComplexity {
+			m.complexity = maxComplexity
+		}
+		m.introspection = m.introspection || child.introspection
+	}
+	return m, nil
+}
+
+func (a *analyzer) measureFragment(name string) (measure, error) {
+	if m, ok := a.measured[name]; ok {
+		return m, nil
+	}
+
+	selections, ok := a.fragments[name]
+	if !ok {
+		return measure{}, fmt.Errorf("unknown fragment %q", name)
+	}
+	if a.visiting[name] {
+		return measure{}, fmt.Errorf("cycle through the fragment %q", name)
+	}
+
+	a.visiting[name] = true
+	m, err := a.measure(selections)
+	delete(a.visiting, name)
+	if err != nil {
+		return measure{}, err
+	}
+
+	a.measured[name] = m
+	return m, nil
+}
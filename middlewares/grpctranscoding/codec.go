@@ -0,0 +1,668 @@
+package grpctranscoding
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wrapperKinds are the types of the values of the well-known wrapper messages, represented in JSON by their value.
+var wrapperKinds = map[string]int{
+	"google.protobuf.DoubleValue": typeDouble,
+	"google.protobuf.FloatValue":  typeFloat,
+	"google.protobuf.Int64Value":  typeInt64,
+	"google.protobuf.UInt64Value": typeUint64,
+	"google.protobuf.Int32Value":  typeInt32,
+	"google.protobuf.UInt32Value": typeUint32,
+	"google.protobuf.BoolValue":   typeBool,
+	"google.protobuf.StringValue": typeString,
+	"google.protobuf.BytesValue":  typeBytes,
+}
+
+const (
+	timestampType = "google.protobuf.Timestamp"
+	durationType  = "google.protobuf.Duration"
+)
+
+// setField sets the value of a field of a JSON object of a message, by the path of the field, like "book.author.name".
+// The value is appended to the repeated fields.
+func (r *registry) setField(md *messageDesc, obj map[string]interface{}, fieldPath []string, value interface{}) error {
+	for i, name := range fieldPath {
+		f := md.field(name)
+		if f == nil {
+			return fmt.Errorf("unknown field %q of %s", name, md.name)
+		}
+		if f.jsonName != f.name {
+			if v, ok := obj[f.jsonName]; ok {
+				obj[f.name] = v
+				delete(obj, f.jsonName)
+			}
+		}
+
+		if i == len(fieldPath)-1 {
+			if f.repeated {
+				list, _ := obj[f.name].([]interface{})
+				obj[f.name] = append(list, value)
+			} else {
+				obj[f.name] = value
+			}
+			return nil
+		}
+
+		if f.kind != typeMessage || f.repeated {
+			return fmt.Errorf("field %q of %s is not a message", name, md.name)
+		}
+		sub, ok := obj[f.name].(map[string]interface{})
+		if !ok {
+			sub = make(map[string]interface{})
+			obj[f.name] = sub
+		}
+		obj = sub
+
+		md = r.messages[f.typeName]
+		if md == nil {
+			return fmt.Errorf("unknown message %s", f.typeName)
+		}
+	}
+	return nil
+}
+
+// encodeMessage encodes the JSON object of a message in the protobuf wire format.
+func (r *registry) encodeMessage(md *messageDesc, obj map[string]interface{}) ([]byte, error) {
+	var fields []*fieldDesc
+	values := make(map[*fieldDesc]interface{})
+	for name, value := range obj {
+		f := md.field(name)
+		if f == nil {
+			return nil, fmt.Errorf("unknown field %q of %s", name, md.name)
+		}
+		if value == nil {
+			continue
+		}
+		if _, ok := values[f]; !ok {
+			fields = append(fields, f)
+		}
+		values[f] = value
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].number < fields[j].number })
+
+	var buf []byte
+	for _, f := range fields {
+		var err error
+		buf, err = r.appendField(buf, f, values[f])
+		if err != nil {
+			return nil, fmt.Errorf("field %q of %s: %v", f.name, md.name, err)
+		}
+	}
+	return buf, nil
+}
+
+func (r *registry) appendField(buf []byte, f *fieldDesc, value interface{}) ([]byte, error) {
+	if entry := r.messages[f.typeName]; f.repeated && entry != nil && entry.mapEntry {
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object, got %v", value)
+		}
+		keyField, valueField := entry.fieldByNumber(1), entry.fieldByNumber(2)
+		if keyField == nil || valueField == nil {
+			return nil, fmt.Errorf("invalid map entry %s", entry.name)
+		}
+
+		keys := make([]string, 0, len(entries))
+		for key := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			b, err := r.appendValue(nil, keyField, key)
+			if err != nil {
+				return nil, err
+			}
+			if entries[key] != nil {
+				if b, err = r.appendValue(b, valueField, entries[key]); err != nil {
+					return nil, err
+				}
+			}
+			buf = appendBytes(appendTag(buf, f.number, wireBytes), b)
+		}
+		return buf, nil
+	}
+
+	if f.repeated {
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array, got %v", value)
+		}
+		for _, item := range list {
+			var err error
+			if buf, err = r.appendValue(buf, f, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+
+	return r.appendValue(buf, f, value)
+}
+
+// appendValue encodes a single value of a field, from its JSON value.
+func (r *registry) appendValue(buf []byte, f *fieldDesc, value interface{}) ([]byte, error) {
+	switch f.kind {
+	case typeMessage:
+		b, err := r.encodeMessageValue(f.typeName, value)
+		if err != nil {
+			return nil, err
+		}
+		return appendBytes(appendTag(buf, f.number, wireBytes), b), nil
+	case typeString:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %v", value)
+		}
+		return appendBytes(appendTag(buf, f.number, wireBytes), []byte(s)), nil
+	case typeBytes:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a base64 string, got %v", value)
+		}
+		b, err := decodeBase64(s)
+		if err != nil {
+			return nil, err
+		}
+		return appendBytes(appendTag(buf, f.number, wireBytes), b), nil
+	case typeBool:
+		b, err := toBool(value)
+		if err != nil {
+			return nil, err
+		}
+		var v uint64
+		if b {
+			v = 1
+		}
+		return appendVarint(appendTag(buf, f.number, wireVarint), v), nil
+	case typeEnum:
+		v, err := r.toEnum(f.typeName, value)
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(appendTag(buf, f.number, wireVarint), uint64(int64(v))), nil
+	case typeDouble:
+		v, err := toFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed64(appendTag(buf, f.number, wireFixed64), math.Float64bits(v)), nil
+	case typeFloat:
+		v, err := toFloat(value, 32)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed32(appendTag(buf, f.number, wireFixed32), math.Float32bits(float32(v))), nil
+	case typeInt32, typeInt64:
+		v, err := toInt(value, bitSize(f.kind))
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(appendTag(buf, f.number, wireVarint), uint64(v)), nil
+	case typeSint32, typeSint64:
+		v, err := toInt(value, bitSize(f.kind))
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(appendTag(buf, f.number, wireVarint), uint64(v<<1)^uint64(v>>63)), nil
+	case typeUint32, typeUint64:
+		v, err := toUint(value, bitSize(f.kind))
+		if err != nil {
+			return nil, err
+		}
+		return appendVarint(appendTag(buf, f.number, wireVarint), v), nil
+	case typeFixed32:
+		v, err := toUint(value, 32)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed32(appendTag(buf, f.number, wireFixed32), uint32(v)), nil
+	case typeSfixed32:
+		v, err := toInt(value, 32)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed32(appendTag(buf, f.number, wireFixed32), uint32(v)), nil
+	case typeFixed64:
+		v, err := toUint(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed64(appendTag(buf, f.number, wireFixed64), v), nil
+	case typeSfixed64:
+		v, err := toInt(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return appendFixed64(appendTag(buf, f.number, wireFixed64), uint64(v)), nil
+	default:
+		return nil, fmt.Errorf("unsupported field type %d", f.kind)
+	}
+}
+
+// encodeMessageValue encodes a message from its JSON value, an object, or the JSON representation of a well-known type.
+func (r *registry) encodeMessageValue(typeName string, value interface{}) ([]byte, error) {
+	if kind, ok := wrapperKinds[typeName]; ok {
+		return r.appendValue(nil, &fieldDesc{number: 1, kind: kind}, value)
+	}
+
+	switch typeName {
+	case timestampType:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected an RFC 3339 timestamp, got %v", value)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, err
+		}
+		return encodeSecondsAndNanos(t.Unix(), int32(t.Nanosecond())), nil
+	case durationType:
+		s, ok := value.(string)
+		if !ok || !strings.HasSuffix(s, "s") {
+			return nil, fmt.Errorf("expected a duration in seconds, like 1.5s, got %v", value)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, err
+		}
+		return encodeSecondsAndNanos(int64(d/time.Second), int32(d%time.Second)), nil
+	}
+
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object, got %v", value)
+	}
+	md := r.messages[typeName]
+	if md == nil {
+		return nil, fmt.Errorf("unknown message %s", typeName)
+	}
+	return r.encodeMessage(md, obj)
+}
+
+func encodeSecondsAndNanos(seconds int64, nanos int32) []byte {
+	var buf []byte
+	if seconds != 0 {
+		buf = appendVarint(appendTag(buf, 1, wireVarint), uint64(seconds))
+	}
+	if nanos != 0 {
+		buf = appendVarint(appendTag(buf, 2, wireVarint), uint64(int64(nanos)))
+	}
+	return buf
+}
+
+// decodeMessage decodes a message in the protobuf wire format into its JSON object, keyed by the JSON names of the fields.
+// The unknown fields are skipped.
+func (r *registry) decodeMessage(md *messageDesc, b []byte) (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+	err := readFields(b, func(number, wireType int, v uint64, value []byte) error {
+		f := md.fieldByNumber(number)
+		if f == nil {
+			return nil
+		}
+
+		if entry := r.messages[f.typeName]; f.repeated && entry != nil && entry.mapEntry {
+			decoded, err := r.decodeMessage(entry, value)
+			if err != nil {
+				return err
+			}
+			key, entryValue := decoded[entry.fieldByNumber(1).jsonName], decoded[entry.fieldByNumber(2).jsonName]
+			if key == nil {
+				key = r.zeroValue(entry.fieldByNumber(1))
+			}
+			if entryValue == nil {
+				entryValue = r.zeroValue(entry.fieldByNumber(2))
+			}
+
+			entries, _ := obj[f.jsonName].(map[string]interface{})
+			if entries == nil {
+				entries = make(map[string]interface{})
+				obj[f.jsonName] = entries
+			}
+			entries[fmt.Sprint(key)] = entryValue
+			return nil
+		}
+
+		if f.repeated && wireType == wireBytes && packable(f.kind) {
+			list, _ := obj[f.jsonName].([]interface{})
+			values, err := r.decodePacked(f, value)
+			if err != nil {
+				return err
+			}
+			obj[f.jsonName] = append(list, values...)
+			return nil
+		}
+
+		decoded, err := r.decodeValue(f, wireType, v, value)
+		if err != nil {
+			return fmt.Errorf("field %q of %s: %v", f.name, md.name, err)
+		}
+		if f.repeated {
+			list, _ := obj[f.jsonName].([]interface{})
+			obj[f.jsonName] = append(list, decoded)
+		} else {
+			obj[f.jsonName] = decoded
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (r *registry) decodePacked(f *fieldDesc, b []byte) ([]interface{}, error) {
+	var values []interface{}
+	for len(b) > 0 {
+		var v uint64
+		var value []byte
+		wireType := scalarWireType(f.kind)
+		switch wireType {
+		case wireFixed64:
+			if len(b) < 8 {
+				return nil, errTruncated
+			}
+			value, b = b[:8], b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return nil, errTruncated
+			}
+			value, b = b[:4], b[4:]
+		default:
+			var n int
+			v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errTruncated
+			}
+			b = b[n:]
+		}
+
+		decoded, err := r.decodeValue(f, wireType, v, value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, decoded)
+	}
+	return values, nil
+}
+
+// decodeValue decodes a single value of a field into its JSON value.
+// The 64 bits integers are represented by strings, as JSON numbers can't hold them.
+func (r *registry) decodeValue(f *fieldDesc, wireType int, v uint64, value []byte) (interface{}, error) {
+	expected := wireBytes
+	if packable(f.kind) {
+		expected = scalarWireType(f.kind)
+	}
+	if wireType != expected {
+		return nil, fmt.Errorf("unexpected wire type %d", wireType)
+	}
+
+	switch f.kind {
+	case typeMessage:
+		return r.decodeMessageValue(f.typeName, value)
+	case typeString:
+		return string(value), nil
+	case typeBytes:
+		return base64.StdEncoding.EncodeToString(value), nil
+	case typeBool:
+		return v != 0, nil
+	case typeEnum:
+		if e := r.enums[f.typeName]; e != nil {
+			if name, ok := e.names[int32(v)]; ok {
+				return name, nil
+			}
+		}
+		return int32(v), nil
+	case typeInt32:
+		return int32(v), nil
+	case typeInt64:
+		return strconv.FormatInt(int64(v), 10), nil
+	case typeUint32:
+		return uint32(v), nil
+	case typeUint64:
+		return strconv.FormatUint(v, 10), nil
+	case typeSint32:
+		return int32(uint32(v)>>1) ^ -int32(v&1), nil
+	case typeSint64:
+		return strconv.FormatInt(int64(v>>1)^-int64(v&1), 10), nil
+	case typeFixed32:
+		return binary.LittleEndian.Uint32(value), nil
+	case typeSfixed32:
+		return int32(binary.LittleEndian.Uint32(value)), nil
+	case typeFixed64:
+		return strconv.FormatUint(binary.LittleEndian.Uint64(value), 10), nil
+	case typeSfixed64:
+		return strconv.FormatInt(int64(binary.LittleEndian.Uint64(value)), 10), nil
+	case typeFloat:
+		return jsonFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(value)))), nil
+	case typeDouble:
+		return jsonFloat(math.Float64frombits(binary.LittleEndian.Uint64(value))), nil
+	default:
+		return nil, fmt.Errorf("unsupported field type %d", f.kind)
+	}
+}
+
+// decodeMessageValue decodes a message into its JSON value, an object, or the JSON representation of a well-known type.
+func (r *registry) decodeMessageValue(typeName string, b []byte) (interface{}, error) {
+	if kind, ok := wrapperKinds[typeName]; ok {
+		f := &fieldDesc{number: 1, kind: kind, jsonName: "value"}
+		decoded, err := r.decodeMessage(&messageDesc{name: typeName, fields: []*fieldDesc{f}}, b)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := decoded["value"]; ok {
+			return value, nil
+		}
+		return r.zeroValue(f), nil
+	}
+
+	switch typeName {
+	case timestampType, durationType:
+		var seconds, nanos int64
+		err := readFields(b, func(number, wireType int, v uint64, _ []byte) error {
+			switch {
+			case number == 1 && wireType == wireVarint:
+				seconds = int64(v)
+			case number == 2 && wireType == wireVarint:
+				nanos = int64(int32(v))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if typeName == timestampType {
+			return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano), nil
+		}
+		return strconv.FormatFloat(float64(seconds)+float64(nanos)/1e9, 'f', -1, 64) + "s", nil
+	}
+
+	md := r.messages[typeName]
+	if md == nil {
+		return nil, fmt.Errorf("unknown message %s", typeName)
+	}
+	return r.decodeMessage(md, b)
+}
+
+// zeroValue returns the JSON value of the default value of a field.
+func (r *registry) zeroValue(f *fieldDesc) interface{} {
+	switch f.kind {
+	case typeMessage:
+		if _, ok := wrapperKinds[f.typeName]; ok {
+			return nil
+		}
+		return map[string]interface{}{}
+	case typeString, typeBytes:
+		return ""
+	case typeBool:
+		return false
+	case typeEnum:
+		if e := r.enums[f.typeName]; e != nil {
+			if name, ok := e.names[0]; ok {
+				return name
+			}
+		}
+		return 0
+	case typeInt64, typeUint64, typeSint64, typeFixed64, typeSfixed64:
+		return "0"
+	default:
+		return 0
+	}
+}
+
+func (r *registry) toEnum(typeName string, value interface{}) (int32, error) {
+	if s, ok := value.(string); ok {
+		if e := r.enums[typeName]; e != nil {
+			if number, ok := e.numbers[s]; ok {
+				return number, nil
+			}
+		}
+	}
+
+	v, err := toInt(value, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unknown value %v of the enum %s", value, typeName)
+	}
+	return int32(v), nil
+}
+
+func packable(kind int) bool {
+	return kind != typeString && kind != typeBytes && kind != typeMessage && kind != typeGroup
+}
+
+func scalarWireType(kind int) int {
+	switch kind {
+	case typeDouble, typeFixed64, typeSfixed64:
+		return wireFixed64
+	case typeFloat, typeFixed32, typeSfixed32:
+		return wireFixed32
+	default:
+		return wireVarint
+	}
+}
+
+func bitSize(kind int) int {
+	switch kind {
+	case typeInt32, typeSint32, typeUint32, typeFixed32, typeSfixed32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+func appendTag(buf []byte, number, wireType int) []byte {
+	return appendVarint(buf, uint64(number)<<3|uint64(wireType))
+}
+
+func appendVarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func appendFixed32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendFixed64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendBytes(buf, b []byte) []byte {
+	return append(appendVarint(buf, uint64(len(b))), b...)
+}
+
+// The JSON values of the numbers are either JSON numbers or strings.
+
+func toInt(value interface{}, bitSize int) (int64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return strconv.ParseInt(string(v), 10, bitSize)
+	case string:
+		return strconv.ParseInt(v, 10, bitSize)
+	default:
+		return 0, fmt.Errorf("expected an integer, got %v", value)
+	}
+}
+
+func toUint(value interface{}, bitSize int) (uint64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return strconv.ParseUint(string(v), 10, bitSize)
+	case string:
+		return strconv.ParseUint(v, 10, bitSize)
+	default:
+		return 0, fmt.Errorf("expected an unsigned integer, got %v", value)
+	}
+}
+
+func toFloat(value interface{}, bitSize int) (float64, error) {
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return 0, fmt.Errorf("expected a number, got %v", value)
+	}
+
+	switch s {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(s, bitSize)
+}
+
+func toBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	default:
+		return false, fmt.Errorf("expected a boolean, got %v", value)
+	}
+}
+
+func jsonFloat(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	default:
+		return f
+	}
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := encoding.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid base64 value %q", s)
+}
@@ -0,0 +1,476 @@
+package grpctranscoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// The wire types of the protobuf encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// The types of the fields of the protobuf descriptors.
+const (
+	typeDouble   = 1
+	typeFloat    = 2
+	typeInt64    = 3
+	typeUint64   = 4
+	typeInt32    = 5
+	typeFixed64  = 6
+	typeFixed32  = 7
+	typeBool     = 8
+	typeString   = 9
+	typeGroup    = 10
+	typeMessage  = 11
+	typeBytes    = 12
+	typeUint32   = 13
+	typeEnum     = 14
+	typeSfixed32 = 15
+	typeSfixed64 = 16
+	typeSint32   = 17
+	typeSint64   = 18
+)
+
+const labelRepeated = 3
+
+// httpRuleExtension is the number of the google.api.http extension of the method options.
+const httpRuleExtension = 72295728
+
+type fieldDesc struct {
+	name     string
+	jsonName string
+	number   int
+	kind     int
+	repeated bool
+	typeName string
+}
+
+type messageDesc struct {
+	name     string
+	fields   []*fieldDesc
+	mapEntry bool
+}
+
+// field returns the field of the message by its name or JSON name.
+func (m *messageDesc) field(name string) *fieldDesc {
+	for _, f := range m.fields {
+		if f.name == name || f.jsonName == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (m *messageDesc) fieldByNumber(number int) *fieldDesc {
+	for _, f := range m.fields {
+		if f.number == number {
+			return f
+		}
+	}
+	return nil
+}
+
+type enumDesc struct {
+	numbers map[string]int32
+	names   map[int32]string
+}
+
+type methodDesc struct {
+	path            string
+	input           string
+	output          string
+	clientStreaming bool
+	serverStreaming bool
+	rules           []httpRule
+}
+
+// httpRule is a google.api.http rule, mapping the HTTP requests matching its method and path template to a method.
+type httpRule struct {
+	method       string
+	pattern      string
+	body         string
+	responseBody string
+}
+
+// registry holds the messages, enums and methods of a descriptor set, by their fully qualified name.
+type registry struct {
+	messages map[string]*messageDesc
+	enums    map[string]*enumDesc
+	services map[string][]*methodDesc
+}
+
+// parseDescriptorSet parses a serialized google.protobuf.FileDescriptorSet.
+func parseDescriptorSet(b []byte) (*registry, error) {
+	r := &registry{
+		messages: make(map[string]*messageDesc),
+		enums:    make(map[string]*enumDesc),
+		services: make(map[string][]*methodDesc),
+	}
+
+	err := readFields(b, func(number, wireType int, _ uint64, value []byte) error {
+		if number == 1 && wireType == wireBytes {
+			return r.parseFile(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %v", err)
+	}
+	return r, nil
+}
+
+// parseFile parses a google.protobuf.FileDescriptorProto.
+func (r *registry) parseFile(b []byte) error {
+	var pkg string
+	var messages, enums, services [][]byte
+	err := readFields(b, func(number, wireType int, _ uint64, value []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch number {
+		case 2:
+			pkg = string(value)
+		case 4:
+			messages = append(messages, value)
+		case 5:
+			enums = append(enums, value)
+		case 6:
+			services = append(services, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if err := r.parseMessage(pkg, message); err != nil {
+			return err
+		}
+	}
+	for _, enum := range enums {
+		if err := r.parseEnum(pkg, enum); err != nil {
+			return err
+		}
+	}
+	for _, service := range services {
+		if err := r.parseService(pkg, service); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseMessage parses a google.protobuf.DescriptorProto, and its nested messages and enums.
+func (r *registry) parseMessage(scope string, b []byte) error {
+	m := &messageDesc{}
+	var nestedMessages, nestedEnums [][]byte
+	err := readFields(b, func(number, wireType int, _ uint64, value []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch number {
+		case 1:
+			m.name = qualify(scope, string(value))
+		case 2:
+			f, err := parseField(value)
+			if err != nil {
+				return err
+			}
+			m.fields = append(m.fields, f)
+		case 3:
+			nestedMessages = append(nestedMessages, value)
+		case 4:
+			nestedEnums = append(nestedEnums, value)
+		case 7:
+			// MessageOptions.map_entry
+			return readFields(value, func(number, wireType int, v uint64, _ []byte) error {
+				if number == 7 && wireType == wireVarint {
+					m.mapEntry = v != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.messages[m.name] = m
+	for _, nested := range nestedMessages {
+		if err := r.parseMessage(m.name, nested); err != nil {
+			return err
+		}
+	}
+	for _, nested := range nestedEnums {
+		if err := r.parseEnum(m.name, nested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseField parses a google.protobuf.FieldDescriptorProto.
+func parseField(b []byte) (*fieldDesc, error) {
+	f := &fieldDesc{}
+	err := readFields(b, func(number, wireType int, v uint64, value []byte) error {
+		switch {
+		case number == 1 && wireType == wireBytes:
+			f.name = string(value)
+		case number == 3 && wireType == wireVarint:
+			f.number = int(v)
+		case number == 4 && wireType == wireVarint:
+			f.repeated = v == labelRepeated
+		case number == 5 && wireType == wireVarint:
+			f.kind = int(v)
+		case number == 6 && wireType == wireBytes:
+			f.typeName = strings.TrimPrefix(string(value), ".")
+		case number == 10 && wireType == wireBytes:
+			f.jsonName = string(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(f.jsonName) == 0 {
+		f.jsonName = jsonName(f.name)
+	}
+	return f, nil
+}
+
+// parseEnum parses a google.protobuf.EnumDescriptorProto.
+func (r *registry) parseEnum(scope string, b []byte) error {
+	var name string
+	e := &enumDesc{numbers: make(map[string]int32), names: make(map[int32]string)}
+	err := readFields(b, func(number, wireType int, _ uint64, value []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch number {
+		case 1:
+			name = qualify(scope, string(value))
+		case 2:
+			var valueName string
+			var valueNumber int32
+			err := readFields(value, func(number, wireType int, v uint64, value []byte) error {
+				switch {
+				case number == 1 && wireType == wireBytes:
+					valueName = string(value)
+				case number == 2 && wireType == wireVarint:
+					valueNumber = int32(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			e.numbers[valueName] = valueNumber
+			if _, ok := e.names[valueNumber]; !ok {
+				e.names[valueNumber] = valueName
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.enums[name] = e
+	return nil
+}
+
+// parseService parses a google.protobuf.ServiceDescriptorProto.
+func (r *registry) parseService(pkg string, b []byte) error {
+	var name string
+	var methods [][]byte
+	err := readFields(b, func(number, wireType int, _ uint64, value []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch number {
+		case 1:
+			name = qualify(pkg, string(value))
+		case 2:
+			methods = append(methods, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, method := range methods {
+		m, err := parseMethod(name, method)
+		if err != nil {
+			return err
+		}
+		r.services[name] = append(r.services[name], m)
+	}
+	return nil
+}
+
+// parseMethod parses a google.protobuf.MethodDescriptorProto, and its google.api.http rules.
+func parseMethod(service string, b []byte) (*methodDesc, error) {
+	m := &methodDesc{}
+	err := readFields(b, func(number, wireType int, v uint64, value []byte) error {
+		switch {
+		case number == 1 && wireType == wireBytes:
+			m.path = "/" + service + "/" + string(value)
+		case number == 2 && wireType == wireBytes:
+			m.input = strings.TrimPrefix(string(value), ".")
+		case number == 3 && wireType == wireBytes:
+			m.output = strings.TrimPrefix(string(value), ".")
+		case number == 4 && wireType == wireBytes:
+			return readFields(value, func(number, wireType int, _ uint64, value []byte) error {
+				if number != httpRuleExtension || wireType != wireBytes {
+					return nil
+				}
+				rules, err := parseHTTPRule(value, true)
+				m.rules = append(m.rules, rules...)
+				return err
+			})
+		case number == 5 && wireType == wireVarint:
+			m.clientStreaming = v != 0
+		case number == 6 && wireType == wireVarint:
+			m.serverStreaming = v != 0
+		}
+		return nil
+	})
+	return m, err
+}
+
+// parseHTTPRule parses a google.api.HttpRule, along with its additional bindings.
+func parseHTTPRule(b []byte, withBindings bool) ([]httpRule, error) {
+	rule := httpRule{}
+	var bindings [][]byte
+	err := readFields(b, func(number, wireType int, _ uint64, value []byte) error {
+		if wireType != wireBytes {
+			return nil
+		}
+		switch number {
+		case 2, 3, 4, 5, 6:
+			rule.method = [...]string{"GET", "PUT", "POST", "DELETE", "PATCH"}[number-2]
+			rule.pattern = string(value)
+		case 7:
+			rule.body = string(value)
+		case 8:
+			// CustomHttpPattern
+			return readFields(value, func(number, wireType int, _ uint64, value []byte) error {
+				switch {
+				case number == 1 && wireType == wireBytes:
+					rule.method = string(value)
+				case number == 2 && wireType == wireBytes:
+					rule.pattern = string(value)
+				}
+				return nil
+			})
+		case 11:
+			if withBindings {
+				bindings = append(bindings, value)
+			}
+		case 12:
+			rule.responseBody = string(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []httpRule
+	if len(rule.pattern) > 0 {
+		rules = append(rules, rule)
+	}
+	for _, binding := range bindings {
+		additional, err := parseHTTPRule(binding, false)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, additional...)
+	}
+	return rules, nil
+}
+
+func qualify(scope, name string) string {
+	if len(scope) == 0 {
+		return name
+	}
+	return scope + "." + name
+}
+
+// jsonName returns the lowerCamelCase JSON name of a field, as protoc does.
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// readFields calls fn for each field of a protobuf message, with the value of the varint fields,
+// or the bytes of the other fields.
+func readFields(b []byte, fn func(number, wireType int, v uint64, value []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		b = b[n:]
+
+		number, wireType := int(tag>>3), int(tag&7)
+		var v uint64
+		var value []byte
+		switch wireType {
+		case wireVarint:
+			v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return errTruncated
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errTruncated
+			}
+			value, b = b[:8], b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errTruncated
+			}
+			value, b = b[:4], b[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < length {
+				return errTruncated
+			}
+			value, b = b[n:n+int(length)], b[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wireType)
+		}
+
+		if err := fn(number, wireType, v, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package grpctranscoding
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "GRPCTranscoding"
+
+	// maxMessageSize is the default maximum size of the messages of the gRPC servers.
+	maxMessageSize = 4 * 1024 * 1024
+
+	grpcContentType = "application/grpc"
+)
+
+// The gRPC status codes.
+const (
+	codeOK = iota
+	codeCanceled
+	codeUnknown
+	codeInvalidArgument
+	codeDeadlineExceeded
+	codeNotFound
+	codeAlreadyExists
+	codePermissionDenied
+	codeResourceExhausted
+	codeFailedPrecondition
+	codeAborted
+	codeOutOfRange
+	codeUnimplemented
+	codeInternal
+	codeUnavailable
+	codeDataLoss
+	codeUnauthenticated
+)
+
+// httpStatuses are the HTTP statuses of the gRPC status codes, as mapped by grpc-gateway.
+var httpStatuses = map[int]int{
+	codeOK:                 http.StatusOK,
+	codeCanceled:           http.StatusRequestTimeout,
+	codeUnknown:            http.StatusInternalServerError,
+	codeInvalidArgument:    http.StatusBadRequest,
+	codeDeadlineExceeded:   http.StatusGatewayTimeout,
+	codeNotFound:           http.StatusNotFound,
+	codeAlreadyExists:      http.StatusConflict,
+	codePermissionDenied:   http.StatusForbidden,
+	codeResourceExhausted:  http.StatusTooManyRequests,
+	codeFailedPrecondition: http.StatusBadRequest,
+	codeAborted:            http.StatusConflict,
+	codeOutOfRange:         http.StatusBadRequest,
+	codeUnimplemented:      http.StatusNotImplemented,
+	codeInternal:           http.StatusInternalServerError,
+	codeUnavailable:        http.StatusServiceUnavailable,
+	codeDataLoss:           http.StatusInternalServerError,
+	codeUnauthenticated:    http.StatusUnauthorized,
+}
+
+// binding maps the HTTP requests matching a rule to a gRPC method.
+type binding struct {
+	method   *methodDesc
+	rule     httpRule
+	template *pathTemplate
+}
+
+// transcoding is a middleware transcoding the RESTful JSON requests into calls of the methods of a gRPC service,
+// and their responses into JSON responses.
+// The requests which don't match the HTTP rule of a method, like the gRPC requests, are forwarded as is.
+type transcoding struct {
+	next     http.Handler
+	name     string
+	registry *registry
+	bindings []binding
+}
+
+// New creates a new gRPC transcoding middleware.
+func New(ctx context.Context, next http.Handler, config config.GRPCTranscoding, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug("Creating middleware")
+
+	if len(config.DescriptorSet) == 0 {
+		return nil, errors.New("a descriptor set is required")
+	}
+	content, err := ioutil.ReadFile(config.DescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the descriptor set: %v", err)
+	}
+
+	r, err := parseDescriptorSet(content)
+	if err != nil {
+		return nil, err
+	}
+
+	services := config.Services
+	if len(services) == 0 {
+		for service := range r.services {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+	}
+
+	t := &transcoding{next: next, name: name, registry: r}
+	for _, service := range services {
+		methods, ok := r.services[service]
+		if !ok {
+			return nil, fmt.Errorf("unknown service %s", service)
+		}
+
+		for _, method := range methods {
+			if len(method.rules) == 0 {
+				continue
+			}
+			if method.clientStreaming || method.serverStreaming {
+				logger.Warnf("The streaming method %s is not transcoded", method.path)
+				continue
+			}
+			if r.messages[method.input] == nil || r.messages[method.output] == nil {
+				return nil, fmt.Errorf("unknown messages of the method %s, the descriptor set must include the imports", method.path)
+			}
+
+			for _, rule := range method.rules {
+				template, err := parseTemplate(rule.pattern)
+				if err != nil {
+					return nil, fmt.Errorf("invalid HTTP rule of the method %s: %v", method.path, err)
+				}
+				t.bindings = append(t.bindings, binding{method: method, rule: rule, template: template})
+			}
+		}
+	}
+
+	if len(t.bindings) == 0 {
+		return nil, errors.New("no method with an HTTP rule")
+	}
+	return t, nil
+}
+
+func (t *transcoding) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return t.name, tracing.SpanKindNoneEnum
+}
+
+func (t *transcoding) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if strings.HasPrefix(req.Header.Get("Content-Type"), grpcContentType) {
+		t.next.ServeHTTP(rw, req)
+		return
+	}
+
+	b, values, ok := t.match(req)
+	if !ok {
+		t.next.ServeHTTP(rw, req)
+		return
+	}
+
+	logger := middlewares.GetLogger(req.Context(), t.name, typeName)
+	logger.Debugf("Transcoding the request into a call of %s", b.method.path)
+
+	message, err := t.buildMessage(b, values, req)
+	if err != nil {
+		logger.Debugf("Invalid request: %v", err)
+		writeError(rw, codeInvalidArgument, err.Error())
+		return
+	}
+
+	recorder := newResponseRecorder()
+	t.next.ServeHTTP(recorder, grpcRequest(req, b.method.path, message))
+
+	t.writeResponse(req.Context(), rw, b, recorder)
+}
+
+func (t *transcoding) match(req *http.Request) (binding, map[string]string, bool) {
+	for _, b := range t.bindings {
+		if b.rule.method != req.Method {
+			continue
+		}
+		if values, ok := b.template.match(req.URL.EscapedPath()); ok {
+			return b, values, true
+		}
+	}
+	return binding{}, nil, false
+}
+
+// buildMessage builds the input message of the method from the body, the path and the query of the request.
+func (t *transcoding) buildMessage(b binding, values map[string]string, req *http.Request) ([]byte, error) {
+	md := t.registry.messages[b.method.input]
+	obj := make(map[string]interface{})
+
+	if len(b.rule.body) > 0 && req.Body != nil {
+		content, err := ioutil.ReadAll(io.LimitReader(req.Body, maxMessageSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the body: %v", err)
+		}
+		if len(content) > maxMessageSize {
+			return nil, fmt.Errorf("body larger than %d bytes", maxMessageSize)
+		}
+
+		if len(bytes.TrimSpace(content)) > 0 {
+			decoder := json.NewDecoder(bytes.NewReader(content))
+			decoder.UseNumber()
+
+			var body interface{}
+			if err := decoder.Decode(&body); err != nil {
+				return nil, fmt.Errorf("invalid JSON body: %v", err)
+			}
+
+			if b.rule.body == "*" {
+				var ok bool
+				if obj, ok = body.(map[string]interface{}); !ok {
+					return nil, errors.New("expected a JSON object body")
+				}
+			} else if err := t.registry.setField(md, obj, strings.Split(b.rule.body, "."), body); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The fields of the path override the ones of the body.
+	for fieldPath, value := range values {
+		if err := t.registry.setField(md, obj, strings.Split(fieldPath, "."), value); err != nil {
+			return nil, err
+		}
+	}
+
+	if b.rule.body != "*" {
+		for key, params := range req.URL.Query() {
+			if _, ok := values[key]; ok {
+				continue
+			}
+			for _, param := range params {
+				// The unknown parameters are ignored.
+				_ = t.registry.setField(md, obj, strings.Split(key, "."), param)
+			}
+		}
+	}
+
+	return t.registry.encodeMessage(md, obj)
+}
+
+// grpcRequest returns the gRPC request of a method, with the same context and headers.
+func grpcRequest(req *http.Request, path string, message []byte) *http.Request {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	frame = append(frame, message...)
+
+	outReq := new(http.Request)
+	*outReq = *req
+
+	outReq.Method = http.MethodPost
+	outReq.URL = &url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: path}
+	outReq.RequestURI = path
+
+	outReq.Header = make(http.Header)
+	for key, values := range req.Header {
+		outReq.Header[key] = values
+	}
+	outReq.Header.Del("Content-Length")
+	outReq.Header.Del("Accept-Encoding")
+	outReq.Header.Set("Content-Type", grpcContentType)
+	outReq.Header.Set("Te", "trailers")
+
+	outReq.Body = ioutil.NopCloser(bytes.NewReader(frame))
+	outReq.ContentLength = int64(len(frame))
+	return outReq
+}
+
+// writeResponse writes the JSON response of a call, from its gRPC response.
+// The responses which are not gRPC responses, like the errors of the forwarding, are written as is.
+func (t *transcoding) writeResponse(ctx context.Context, rw http.ResponseWriter, b binding, recorder *responseRecorder) {
+	status, ok := recorder.grpcValue("Grpc-Status")
+	if !ok {
+		if strings.HasPrefix(recorder.header.Get("Content-Type"), grpcContentType) {
+			writeError(rw, codeInternal, "gRPC response without status")
+			return
+		}
+
+		for key, values := range recorder.header {
+			rw.Header()[key] = values
+		}
+		rw.WriteHeader(recorder.code)
+		_, _ = rw.Write(recorder.body.Bytes())
+		return
+	}
+
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		writeError(rw, codeUnknown, fmt.Sprintf("invalid gRPC status %q", status))
+		return
+	}
+	if code != codeOK {
+		message, _ := recorder.grpcValue("Grpc-Message")
+		if unescaped, err := url.PathUnescape(message); err == nil {
+			message = unescaped
+		}
+		writeError(rw, code, message)
+		return
+	}
+
+	response, err := t.decodeResponse(b, recorder.body.Bytes())
+	if err != nil {
+		middlewares.GetLogger(ctx, t.name, typeName).Errorf("Invalid response of %s: %v", b.method.path, err)
+		writeError(rw, codeInternal, "invalid gRPC response")
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(response)
+}
+
+func (t *transcoding) decodeResponse(b binding, body []byte) ([]byte, error) {
+	if len(body) < 5 {
+		return nil, errors.New("missing message")
+	}
+	if body[0] != 0 {
+		return nil, errors.New("unsupported compressed message")
+	}
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint64(len(body)-5) < uint64(length) {
+		return nil, errTruncated
+	}
+
+	md := t.registry.messages[b.method.output]
+	obj, err := t.registry.decodeMessage(md, body[5:5+length])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b.rule.responseBody) == 0 {
+		return json.Marshal(obj)
+	}
+
+	f := md.field(b.rule.responseBody)
+	if f == nil {
+		return nil, fmt.Errorf("unknown response body field %q", b.rule.responseBody)
+	}
+	value, ok := obj[f.jsonName]
+	if !ok {
+		value = t.registry.zeroValue(f)
+	}
+	return json.Marshal(value)
+}
+
+func writeError(rw http.ResponseWriter, code int, message string) {
+	status, ok := httpStatuses[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"code": code, "message": message})
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
+
+// responseRecorder buffers a gRPC response, along with its trailers.
+type responseRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), code: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.code = code
+}
+
+// Flush is a no-op, the response being buffered.
+func (r *responseRecorder) Flush() {}
+
+// grpcValue returns the value of a gRPC header, sent as a trailer, or as a header in a response without message.
+func (r *responseRecorder) grpcValue(name string) (string, bool) {
+	for _, key := range []string{name, http.TrailerPrefix + name} {
+		if values, ok := r.header[key]; ok && len(values) > 0 {
+			return values[0], true
+		}
+	}
+	return "", false
+}
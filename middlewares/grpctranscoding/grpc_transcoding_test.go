@@ -0,0 +1,445 @@
+package grpctranscoding
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The helpers below build the descriptor set of a bookstore service:
+//
+//   enum Genre { UNKNOWN = 0; FICTION = 1; }
+//   message Book {
+//     string name = 1; int64 id = 2; repeated string tags = 3; Genre genre = 4; map<string, int32> ratings = 5;
+//     google.protobuf.Timestamp published = 6; double price = 7; sint32 rank = 8; bytes cover = 9;
+//   }
+//   message GetBookRequest { string shelf = 1; int64 book_id = 2; bool full = 3; }
+//   message CreateBookRequest { string shelf = 1; Book book = 2; }
+//   service Bookstore {
+//     rpc GetBook(GetBookRequest) returns (Book) { option (google.api.http) = { get: "/v1/shelves/{shelf}/books/{book_id}" }; }
+//     rpc CreateBook(CreateBookRequest) returns (Book) {
+//       option (google.api.http) = { post: "/v1/shelves/{shelf}/books" body: "book" additional_bindings { put: "/v1/{shelf=shelves/*}/books:create" body: "*" } };
+//     }
+//     rpc ListTags(GetBookRequest) returns (Book) { option (google.api.http) = { get: "/v1/tags/{shelf=**}" response_body: "tags" }; }
+//     rpc WatchBook(GetBookRequest) returns (stream Book) { option (google.api.http) = { get: "/v1/watch" }; }
+//   }
+
+func concat(fields ...[]byte) []byte {
+	var b []byte
+	for _, field := range fields {
+		b = append(b, field...)
+	}
+	return b
+}
+
+func str(number int, s string) []byte {
+	return appendBytes(appendTag(nil, number, wireBytes), []byte(s))
+}
+
+func num(number int, v uint64) []byte {
+	return appendVarint(appendTag(nil, number, wireVarint), v)
+}
+
+func sub(number int, fields ...[]byte) []byte {
+	return appendBytes(appendTag(nil, number, wireBytes), concat(fields...))
+}
+
+func field(name string, number, kind int, repeated bool, typeName string) []byte {
+	label := uint64(1)
+	if repeated {
+		label = labelRepeated
+	}
+	fields := [][]byte{str(1, name), num(3, uint64(number)), num(4, label), num(5, uint64(kind)), str(10, jsonName(name))}
+	if len(typeName) > 0 {
+		fields = append(fields, str(6, typeName))
+	}
+	return sub(2, fields...)
+}
+
+func method(name, input, output string, serverStreaming bool, rule []byte) []byte {
+	fields := [][]byte{str(1, name), str(2, input), str(3, output), sub(4, appendBytes(appendTag(nil, httpRuleExtension, wireBytes), rule))}
+	if serverStreaming {
+		fields = append(fields, num(6, 1))
+	}
+	return sub(2, fields...)
+}
+
+func bookstoreDescriptorSet() []byte {
+	file := concat(
+		str(1, "bookstore.proto"),
+		str(2, "bookstore"),
+		sub(5, str(1, "Genre"), sub(2, str(1, "UNKNOWN"), num(2, 0)), sub(2, str(1, "FICTION"), num(2, 1))),
+		sub(4,
+			str(1, "Book"),
+			field("name", 1, typeString, false, ""),
+			field("id", 2, typeInt64, false, ""),
+			field("tags", 3, typeString, true, ""),
+			field("genre", 4, typeEnum, false, ".bookstore.Genre"),
+			field("ratings", 5, typeMessage, true, ".bookstore.Book.RatingsEntry"),
+			field("published", 6, typeMessage, false, ".google.protobuf.Timestamp"),
+			field("price", 7, typeDouble, false, ""),
+			field("rank", 8, typeSint32, false, ""),
+			field("cover", 9, typeBytes, false, ""),
+			sub(3,
+				str(1, "RatingsEntry"),
+				field("key", 1, typeString, false, ""),
+				field("value", 2, typeInt32, false, ""),
+				sub(7, num(7, 1)),
+			),
+		),
+		sub(4,
+			str(1, "GetBookRequest"),
+			field("shelf", 1, typeString, false, ""),
+			field("book_id", 2, typeInt64, false, ""),
+			field("full", 3, typeBool, false, ""),
+		),
+		sub(4,
+			str(1, "CreateBookRequest"),
+			field("shelf", 1, typeString, false, ""),
+			field("book", 2, typeMessage, false, ".bookstore.Book"),
+		),
+		sub(6,
+			str(1, "Bookstore"),
+			method("GetBook", ".bookstore.GetBookRequest", ".bookstore.Book", false,
+				str(2, "/v1/shelves/{shelf}/books/{book_id}")),
+			method("CreateBook", ".bookstore.CreateBookRequest", ".bookstore.Book", false,
+				concat(str(4, "/v1/shelves/{shelf}/books"), str(7, "book"), sub(11, str(3, "/v1/{shelf=shelves/*}/books:create"), str(7, "*")))),
+			method("ListTags", ".bookstore.GetBookRequest", ".bookstore.Book", false,
+				concat(str(2, "/v1/tags/{shelf=**}"), str(12, "tags"))),
+			method("WatchBook", ".bookstore.GetBookRequest", ".bookstore.Book", true,
+				str(2, "/v1/watch")),
+		),
+	)
+	return str(1, string(file))
+}
+
+func writeDescriptorSet(t *testing.T) string {
+	file, err := ioutil.TempFile("", "descriptor-set")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.Write(bookstoreDescriptorSet())
+	require.NoError(t, err)
+	return file.Name()
+}
+
+// grpcServer is a fake gRPC server, recording the method and the message of the last call.
+type grpcServer struct {
+	registry *registry
+	path     string
+	request  map[string]interface{}
+	response map[string]interface{}
+	status   string
+	message  string
+}
+
+func (s *grpcServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil || len(body) < 5 || req.Header.Get("Content-Type") != grpcContentType {
+		http.Error(rw, "not a gRPC request", http.StatusBadRequest)
+		return
+	}
+
+	s.path = req.URL.Path
+	input := map[string]string{
+		"/bookstore.Bookstore/GetBook":    "bookstore.GetBookRequest",
+		"/bookstore.Bookstore/ListTags":   "bookstore.GetBookRequest",
+		"/bookstore.Bookstore/CreateBook": "bookstore.CreateBookRequest",
+	}[s.path]
+	s.request, err = s.registry.decodeMessage(s.registry.messages[input], body[5:])
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", grpcContentType)
+	if s.status != "0" {
+		// Trailers-Only response.
+		rw.Header().Set("Grpc-Status", s.status)
+		rw.Header().Set("Grpc-Message", s.message)
+		return
+	}
+
+	message, err := s.registry.encodeMessage(s.registry.messages["bookstore.Book"], s.response)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	frame := make([]byte, 5)
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+
+	_, _ = rw.Write(append(frame, message...))
+	rw.Header().Set(http.TrailerPrefix+"Grpc-Status", "0")
+}
+
+func TestNewConfiguration(t *testing.T) {
+	descriptorSet := writeDescriptorSet(t)
+	defer os.Remove(descriptorSet)
+
+	testCases := []struct {
+		desc   string
+		config config.GRPCTranscoding
+	}{
+		{
+			desc:   "no descriptor set",
+			config: config.GRPCTranscoding{},
+		},
+		{
+			desc:   "missing descriptor set",
+			config: config.GRPCTranscoding{DescriptorSet: "/does/not/exist"},
+		},
+		{
+			desc:   "unknown service",
+			config: config.GRPCTranscoding{DescriptorSet: descriptorSet, Services: []string{"bookstore.Library"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "transcoding")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTranscoding(t *testing.T) {
+	descriptorSet := writeDescriptorSet(t)
+	defer os.Remove(descriptorSet)
+
+	r, err := parseDescriptorSet(bookstoreDescriptorSet())
+	require.NoError(t, err)
+
+	book := map[string]interface{}{
+		"name":      "Dune",
+		"id":        "42",
+		"tags":      []interface{}{"sf", "classic"},
+		"genre":     "FICTION",
+		"ratings":   map[string]interface{}{"alice": json.Number("5")},
+		"published": "1965-08-01T00:00:00Z",
+		"price":     json.Number("9.5"),
+		"rank":      json.Number("-3"),
+		"cover":     "aW1n",
+	}
+
+	testCases := []struct {
+		desc             string
+		method           string
+		target           string
+		contentType      string
+		body             string
+		status           string
+		message          string
+		expectedPath     string
+		expectedRequest  string
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			desc:             "path and query parameters",
+			method:           http.MethodGet,
+			target:           "/v1/shelves/sf%20books/books/150?full=true&unknown=1",
+			status:           "0",
+			expectedPath:     "/bookstore.Bookstore/GetBook",
+			expectedRequest:  `{"bookId":"150","full":true,"shelf":"sf books"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"cover":"aW1n","genre":"FICTION","id":"42","name":"Dune","price":9.5,"published":"1965-08-01T00:00:00Z","rank":-3,"ratings":{"alice":5},"tags":["sf","classic"]}`,
+		},
+		{
+			desc:             "body field",
+			method:           http.MethodPost,
+			target:           "/v1/shelves/sf/books",
+			body:             `{"name":"Dune","genre":1,"ratings":{"bob":4}}`,
+			status:           "0",
+			expectedPath:     "/bookstore.Bookstore/CreateBook",
+			expectedRequest:  `{"book":{"genre":"FICTION","name":"Dune","ratings":{"bob":4}},"shelf":"sf"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"cover":"aW1n","genre":"FICTION","id":"42","name":"Dune","price":9.5,"published":"1965-08-01T00:00:00Z","rank":-3,"ratings":{"alice":5},"tags":["sf","classic"]}`,
+		},
+		{
+			desc:            "additional binding with the whole body and a verb",
+			method:          http.MethodPut,
+			target:          "/v1/shelves/sf/books:create",
+			body:            `{"shelf":"ignored","book":{"name":"Dune"}}`,
+			status:          "0",
+			expectedPath:    "/bookstore.Bookstore/CreateBook",
+			expectedRequest: `{"book":{"name":"Dune"},"shelf":"shelves/sf"}`,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			desc:             "response body field",
+			method:           http.MethodGet,
+			target:           "/v1/tags/sf/classic",
+			status:           "0",
+			expectedPath:     "/bookstore.Bookstore/ListTags",
+			expectedRequest:  `{"shelf":"sf/classic"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `["sf","classic"]`,
+		},
+		{
+			desc:             "gRPC error",
+			method:           http.MethodGet,
+			target:           "/v1/shelves/sf/books/1",
+			status:           "5",
+			message:          "book%201%20not%20found",
+			expectedPath:     "/bookstore.Bookstore/GetBook",
+			expectedRequest:  `{"bookId":"1","shelf":"sf"}`,
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"code":5,"message":"book 1 not found"}`,
+		},
+		{
+			desc:             "invalid field value",
+			method:           http.MethodGet,
+			target:           "/v1/shelves/sf/books/one",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"code":3,"message":"field \"book_id\" of bookstore.GetBookRequest: strconv.ParseInt: parsing \"one\": invalid syntax"}`,
+		},
+		{
+			desc:             "unknown body field",
+			method:           http.MethodPost,
+			target:           "/v1/shelves/sf/books",
+			body:             `{"title":"Dune"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"code":3,"message":"field \"book\" of bookstore.CreateBookRequest: unknown field \"title\" of bookstore.Book"}`,
+		},
+		{
+			desc:           "streaming method",
+			method:         http.MethodGet,
+			target:         "/v1/watch",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "gRPC request",
+			method:         http.MethodPost,
+			target:         "/v1/shelves/sf/books",
+			contentType:    grpcContentType,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			server := &grpcServer{registry: r, response: book, status: test.status, message: test.message}
+
+			handler, err := New(context.Background(), server, config.GRPCTranscoding{DescriptorSet: descriptorSet}, "transcoding")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(test.method, "http://localhost"+test.target, strings.NewReader(test.body))
+			if len(test.contentType) > 0 {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedPath, server.path)
+			if len(test.expectedRequest) > 0 {
+				request, err := json.Marshal(server.request)
+				require.NoError(t, err)
+				assert.JSONEq(t, test.expectedRequest, string(request))
+			}
+			if len(test.expectedResponse) > 0 {
+				assert.JSONEq(t, test.expectedResponse, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestEncodeMessage(t *testing.T) {
+	r, err := parseDescriptorSet(bookstoreDescriptorSet())
+	require.NoError(t, err)
+
+	message, err := r.encodeMessage(r.messages["bookstore.GetBookRequest"], map[string]interface{}{"bookId": "150", "shelf": "a"})
+	require.NoError(t, err)
+
+	// The fields are encoded in the order of their numbers, 150 being the varint 0x96 0x01.
+	assert.Equal(t, []byte{0x0a, 0x01, 'a', 0x10, 0x96, 0x01}, message)
+}
+
+func TestParseTemplate(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		template       string
+		path           string
+		expectedValues map[string]string
+	}{
+		{
+			desc:           "literal",
+			template:       "/v1/books",
+			path:           "/v1/books",
+			expectedValues: map[string]string{},
+		},
+		{
+			desc:     "literal mismatch",
+			template: "/v1/books",
+			path:     "/v1/shelves",
+		},
+		{
+			desc:           "variables",
+			template:       "/v1/shelves/{shelf}/books/{book.id}",
+			path:           "/v1/shelves/sf/books/42",
+			expectedValues: map[string]string{"shelf": "sf", "book.id": "42"},
+		},
+		{
+			desc:     "missing segment",
+			template: "/v1/shelves/{shelf}/books/{book_id}",
+			path:     "/v1/shelves/sf/books",
+		},
+		{
+			desc:           "variable with segments",
+			template:       "/v1/{name=shelves/*/books/*}",
+			path:           "/v1/shelves/sf/books/42",
+			expectedValues: map[string]string{"name": "shelves/sf/books/42"},
+		},
+		{
+			desc:           "deep wildcard",
+			template:       "/v1/{path=**}",
+			path:           "/v1/a/b/c",
+			expectedValues: map[string]string{"path": "a/b/c"},
+		},
+		{
+			desc:           "verb",
+			template:       "/v1/{name}:publish",
+			path:           "/v1/dune:publish",
+			expectedValues: map[string]string{"name": "dune"},
+		},
+		{
+			desc:     "missing verb",
+			template: "/v1/{name}:publish",
+			path:     "/v1/dune",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			template, err := parseTemplate(test.template)
+			require.NoError(t, err)
+
+			values, ok := template.match(test.path)
+			assert.Equal(t, test.expectedValues != nil, ok)
+			if ok {
+				assert.Equal(t, test.expectedValues, values)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"v1/books", "/v1//books", "/v1/{name", "/v1/**/books", "/v1/{=a}"} {
+		_, err := parseTemplate(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,166 @@
+package grpctranscoding
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// The kinds of the segments of a path template.
+const (
+	segmentLiteral = iota
+	segmentSingle
+	segmentMulti
+)
+
+type segment struct {
+	kind    int
+	literal string
+}
+
+// variable binds the segments [start, end) of a path to a field, end being -1 for the remaining segments.
+type variable struct {
+	fieldPath string
+	start     int
+	end       int
+}
+
+// pathTemplate is a google.api.http path template, like "/v1/{name=shelves/*}/books/{book_id}:publish".
+type pathTemplate struct {
+	segments  []segment
+	variables []variable
+	verb      string
+}
+
+func parseTemplate(pattern string) (*pathTemplate, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("invalid path template %q, expected an absolute path", pattern)
+	}
+
+	t := &pathTemplate{}
+	path := pattern[1:]
+	if i := strings.LastIndex(path, ":"); i >= 0 && i > strings.LastIndex(path, "/") && i > strings.LastIndex(path, "}") {
+		t.verb = path[i+1:]
+		path = path[:i]
+	}
+
+	for len(path) > 0 {
+		if path[0] == '{' {
+			end := strings.Index(path, "}")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path template %q, unterminated variable", pattern)
+			}
+
+			fieldPath, segments := path[1:end], "*"
+			if i := strings.Index(fieldPath, "="); i >= 0 {
+				fieldPath, segments = fieldPath[:i], fieldPath[i+1:]
+			}
+			if len(fieldPath) == 0 {
+				return nil, fmt.Errorf("invalid path template %q, variable without field", pattern)
+			}
+
+			v := variable{fieldPath: fieldPath, start: len(t.segments)}
+			for _, s := range strings.Split(segments, "/") {
+				t.segments = append(t.segments, newSegment(s))
+			}
+			v.end = len(t.segments)
+			if t.segments[len(t.segments)-1].kind == segmentMulti {
+				v.end = -1
+			}
+			t.variables = append(t.variables, v)
+
+			path = path[end+1:]
+		} else {
+			end := strings.Index(path, "/")
+			if end < 0 {
+				end = len(path)
+			}
+			t.segments = append(t.segments, newSegment(path[:end]))
+			path = path[end:]
+		}
+
+		if len(path) > 0 {
+			if path[0] != '/' || len(path) == 1 {
+				return nil, fmt.Errorf("invalid path template %q", pattern)
+			}
+			path = path[1:]
+		}
+	}
+
+	for i, s := range t.segments {
+		if s.kind == segmentLiteral && len(s.literal) == 0 {
+			return nil, fmt.Errorf("invalid path template %q, empty segment", pattern)
+		}
+		if s.kind == segmentMulti && i != len(t.segments)-1 {
+			return nil, fmt.Errorf("invalid path template %q, ** must be the last segment", pattern)
+		}
+	}
+	return t, nil
+}
+
+func newSegment(s string) segment {
+	switch s {
+	case "*":
+		return segment{kind: segmentSingle}
+	case "**":
+		return segment{kind: segmentMulti}
+	default:
+		return segment{kind: segmentLiteral, literal: s}
+	}
+}
+
+// match returns the values of the variables of the template in an escaped path, when the path matches the template.
+func (t *pathTemplate) match(escapedPath string) (map[string]string, bool) {
+	path := strings.TrimPrefix(escapedPath, "/")
+	if len(t.verb) > 0 {
+		if !strings.HasSuffix(path, ":"+t.verb) {
+			return nil, false
+		}
+		path = strings.TrimSuffix(path, ":"+t.verb)
+	}
+
+	var parts []string
+	if len(path) > 0 {
+		parts = strings.Split(path, "/")
+	}
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			return nil, false
+		}
+		parts[i] = unescaped
+	}
+
+	for i, s := range t.segments {
+		switch s.kind {
+		case segmentMulti:
+			continue
+		case segmentSingle:
+			if i >= len(parts) || len(parts[i]) == 0 {
+				return nil, false
+			}
+		default:
+			if i >= len(parts) || parts[i] != s.literal {
+				return nil, false
+			}
+		}
+	}
+	last := len(t.segments) - 1
+	if last >= 0 && t.segments[last].kind == segmentMulti {
+		if len(parts) < last {
+			return nil, false
+		}
+	} else if len(parts) != len(t.segments) {
+		return nil, false
+	}
+
+	values := make(map[string]string)
+	for _, v := range t.variables {
+		end := v.end
+		if end < 0 {
+			end = len(parts)
+		}
+		values[v.fieldPath] = strings.Join(parts[v.start:end], "/")
+	}
+	return values, true
+}
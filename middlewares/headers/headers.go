@@ -96,7 +96,7 @@ func newSecure(next http.Handler, headers config.Headers) *secureHeader {
 	}
 }
 
-func (s secureHeader) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+func (s *secureHeader) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	s.secure.HandlerFuncWithNextForRequestOnly(rw, req, s.next.ServeHTTP)
 }
 
@@ -105,15 +105,35 @@ func (s secureHeader) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 type header struct {
 	next http.Handler
 	// If Custom request headers are set, these will be added to the request
-	customRequestHeaders map[string]string
+	setRequestHeaders []headerValue
+	// If Custom request headers are set to an empty value, these will be removed from the request
+	deleteRequestHeaders []string
+}
+
+// headerValue is a header key in its canonical form, with its value precomputed as a header value slice.
+type headerValue struct {
+	key    string
+	values []string
 }
 
 // NewHeader constructs a new header instance from supplied frontend header struct.
+// The header keys are canonicalized once here, so that the requests do not pay for it.
 func newHeader(next http.Handler, headers config.Headers) *header {
-	return &header{
-		next:                 next,
-		customRequestHeaders: headers.CustomRequestHeaders,
+	h := &header{next: next}
+
+	for name, value := range headers.CustomRequestHeaders {
+		key := http.CanonicalHeaderKey(name)
+		if value == "" {
+			h.deleteRequestHeaders = append(h.deleteRequestHeaders, key)
+			continue
+		}
+
+		// The capacity is capped to the length,
+		// so that an Add further down the chain reallocates instead of writing in the shared slice.
+		h.setRequestHeaders = append(h.setRequestHeaders, headerValue{key: key, values: []string{value}[:1:1]})
 	}
+
+	return h
 }
 
 func (s *header) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
@@ -123,12 +143,11 @@ func (s *header) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 // modifyRequestHeaders set or delete request headers.
 func (s *header) modifyRequestHeaders(req *http.Request) {
-	// Loop through Custom request headers
-	for header, value := range s.customRequestHeaders {
-		if value == "" {
-			req.Header.Del(header)
-		} else {
-			req.Header.Set(header, value)
-		}
+	for _, key := range s.deleteRequestHeaders {
+		delete(req.Header, key)
+	}
+
+	for _, h := range s.setRequestHeaders {
+		req.Header[h.key] = h.values
 	}
 }
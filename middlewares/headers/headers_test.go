@@ -61,6 +61,29 @@ func TestCustomRequestHeaderEmptyValue(t *testing.T) {
 	assert.Equal(t, "", req.Header.Get("X-Custom-Request-Header"))
 }
 
+func TestCustomRequestHeaderNonCanonicalKey(t *testing.T) {
+	emptyHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Add("X-Custom-Request-Header", "added")
+	})
+
+	header := newHeader(emptyHandler, config.Headers{
+		CustomRequestHeaders: map[string]string{
+			"x-custom-request-header": "test_request",
+			"x-removed-header":        "",
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, "/foo", nil)
+		req.Header.Set("X-Removed-Header", "foo")
+
+		header.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, []string{"test_request", "added"}, req.Header["X-Custom-Request-Header"])
+		assert.NotContains(t, req.Header, "X-Removed-Header")
+	}
+}
+
 func TestSecureHeader(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/containous/traefik/audit"
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/ip"
 	"github.com/containous/traefik/middlewares"
@@ -66,6 +67,7 @@ func (wl *ipWhiteLister) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		logMessage := fmt.Sprintf("rejecting request %+v: %v", req, err)
 		logger.Debug(logMessage)
 		tracing.SetErrorWithEvent(req, logMessage)
+		audit.RecordRequest(req, audit.CategoryAuthorization, "ipwhitelist.denied", "", wl.name)
 		reject(logger, rw)
 		return
 	}
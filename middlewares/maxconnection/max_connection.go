@@ -7,10 +7,10 @@ import (
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/vulcand/oxy/connlimit"
-	"github.com/vulcand/oxy/utils"
 )
 
 const (
@@ -26,7 +26,7 @@ type maxConnection struct {
 func New(ctx context.Context, next http.Handler, maxConns config.MaxConn, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 
-	extractFunc, err := utils.NewExtractor(maxConns.ExtractorFunc)
+	extractFunc, err := forwardedheaders.NewExtractor(maxConns.ExtractorFunc)
 	if err != nil {
 		return nil, fmt.Errorf("error creating connection limit: %v", err)
 	}
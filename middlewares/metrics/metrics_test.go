@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/metrics"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectingCounter and collectingGauge ignore the labels, so that the values can be checked.
+type collectingCounter struct {
+	value float64
+}
+
+func (c *collectingCounter) With(labelValues ...string) gokitmetrics.Counter {
+	return c
+}
+
+func (c *collectingCounter) Add(delta float64) {
+	c.value += delta
+}
+
+type collectingGauge struct {
+	value float64
+}
+
+func (g *collectingGauge) With(labelValues ...string) gokitmetrics.Gauge {
+	return g
+}
+
+func (g *collectingGauge) Set(value float64) {
+	g.value = value
+}
+
+func (g *collectingGauge) Add(delta float64) {
+	g.value += delta
+}
+
+type testRegistry struct {
+	metrics.Registry
+	reqsCounter       *collectingCounter
+	reqsBytesCounter  *collectingCounter
+	respsBytesCounter *collectingCounter
+	openConnsGauge    *collectingGauge
+}
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{
+		Registry:          metrics.NewVoidRegistry(),
+		reqsCounter:       &collectingCounter{},
+		reqsBytesCounter:  &collectingCounter{},
+		respsBytesCounter: &collectingCounter{},
+		openConnsGauge:    &collectingGauge{},
+	}
+}
+
+func (r *testRegistry) BackendReqsCounter() gokitmetrics.Counter {
+	return r.reqsCounter
+}
+
+func (r *testRegistry) BackendReqsBytesCounter() gokitmetrics.Counter {
+	return r.reqsBytesCounter
+}
+
+func (r *testRegistry) BackendRespsBytesCounter() gokitmetrics.Counter {
+	return r.respsBytesCounter
+}
+
+func (r *testRegistry) BackendOpenConnsGauge() gokitmetrics.Gauge {
+	return r.openConnsGauge
+}
+
+func TestServiceMiddleware(t *testing.T) {
+	registry := newTestRegistry()
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, float64(1), registry.openConnsGauge.value)
+
+		buf := make([]byte, 64)
+		for {
+			if _, err := req.Body.Read(buf); err != nil {
+				break
+			}
+		}
+
+		rw.WriteHeader(http.StatusCreated)
+		_, _ = rw.Write([]byte("response body"))
+	})
+
+	handler := NewServiceMiddleware(context.Background(), next, registry, "service1")
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader("request"))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, float64(1), registry.reqsCounter.value)
+	assert.Equal(t, float64(len("request")), registry.reqsBytesCounter.value)
+	assert.Equal(t, float64(len("response body")), registry.respsBytesCounter.value)
+	assert.Equal(t, float64(0), registry.openConnsGauge.value)
+}
+
+func TestGetRequestProtocol(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			desc:     "http",
+			expected: protoHTTP,
+		},
+		{
+			desc:     "websocket",
+			headers:  map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket"},
+			expected: protoWebsocket,
+		},
+		{
+			desc:     "server sent events",
+			headers:  map[string]string{"Accept": "text/event-stream"},
+			expected: protoSSE,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+
+			assert.Equal(t, test.expected, getRequestProtocol(req))
+		})
+	}
+}
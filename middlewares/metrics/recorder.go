@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/containous/traefik/middlewares"
+)
+
+var (
+	_ middlewares.Stateful = &responseRecorder{}
+)
+
+// responseRecorder captures information from the response and preserves it for later analysis.
+type responseRecorder struct {
+	rw         http.ResponseWriter
+	statusCode int
+	size       int64
+}
+
+func newResponseRecorder(rw http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{rw: rw, statusCode: http.StatusOK}
+}
+
+func (r *responseRecorder) getCode() int {
+	return r.statusCode
+}
+
+func (r *responseRecorder) getSize() int64 {
+	return r.size
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.rw.Header()
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	size, err := r.rw.Write(b)
+	r.size += int64(size)
+	return size, err
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (r *responseRecorder) WriteHeader(status int) {
+	r.rw.WriteHeader(status)
+	r.statusCode = status
+}
+
+// Hijack hijacks the connection
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.rw.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("not a hijacker: %T", r.rw)
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone
+// away.
+func (r *responseRecorder) CloseNotify() <-chan bool {
+	if c, ok := r.rw.(http.CloseNotifier); ok {
+		return c.CloseNotify()
+	}
+	return nil
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorder) Flush() {
+	if f, ok := r.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/plugins"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const typeName = "Plugin"
+
+// pluginMiddleware is a middleware provided by a plugin.
+type pluginMiddleware struct {
+	next http.Handler
+	name string
+}
+
+// New creates a middleware of the plugin.
+func New(ctx context.Context, next http.Handler, config config.Plugin, registry *plugins.Registry, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debugf("Creating middleware of the plugin %s", config.Name)
+
+	handler, err := registry.Build(ctx, next, config.Name, config.Settings, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pluginMiddleware{next: handler, name: name}, nil
+}
+
+func (p *pluginMiddleware) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return p.name, tracing.SpanKindNoneEnum
+}
+
+func (p *pluginMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	p.next.ServeHTTP(rw, req)
+}
@@ -0,0 +1,95 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/ip"
+)
+
+const defaultAPIKeyHeader = "X-Api-Key"
+
+// exemptions matches the requests which bypass the rate limiting.
+type exemptions struct {
+	checker      *ip.Checker
+	strategy     ip.Strategy
+	apiKeyHeader string
+	apiKeys      map[string]bool
+	headers      map[string]string
+	paths        []*regexp.Regexp
+}
+
+func newExemptions(conf *config.RateLimitExemptions) (*exemptions, error) {
+	if conf == nil {
+		return nil, nil
+	}
+
+	e := &exemptions{
+		apiKeyHeader: conf.APIKeyHeader,
+		apiKeys:      make(map[string]bool),
+		headers:      conf.Headers,
+	}
+
+	if len(conf.SourceRange) > 0 {
+		checker, err := ip.NewChecker(conf.SourceRange)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CIDR exemptions %s: %v", conf.SourceRange, err)
+		}
+		e.checker = checker
+
+		e.strategy, err = conf.IPStrategy.Get()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if e.apiKeyHeader == "" {
+		e.apiKeyHeader = defaultAPIKeyHeader
+	}
+	for _, key := range conf.APIKeys {
+		e.apiKeys[key] = true
+	}
+
+	for _, path := range conf.Paths {
+		exp, err := regexp.Compile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse path exemption %q: %v", path, err)
+		}
+		e.paths = append(e.paths, exp)
+	}
+
+	return e, nil
+}
+
+// match returns a description of the exemption matching the request, or an empty string.
+func (e *exemptions) match(req *http.Request) string {
+	if e == nil {
+		return ""
+	}
+
+	if e.checker != nil {
+		if clientIP := e.strategy.GetIP(req); e.checker.IsAuthorized(clientIP) == nil {
+			return "source " + clientIP
+		}
+	}
+
+	if len(e.apiKeys) > 0 && e.apiKeys[req.Header.Get(e.apiKeyHeader)] {
+		return "API key"
+	}
+
+	for name, value := range e.headers {
+		if req.Header.Get(name) == value {
+			return "header " + name
+		}
+	}
+
+	for _, exp := range e.paths {
+		if exp.MatchString(req.URL.Path) {
+			return "path " + exp.String()
+		}
+	}
+
+	return ""
+}
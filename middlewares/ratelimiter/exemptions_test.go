@@ -0,0 +1,126 @@
+package ratelimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExemptions(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		exemptions   *config.RateLimitExemptions
+		remoteAddr   string
+		path         string
+		headers      map[string]string
+		expectedCode int
+	}{
+		{
+			desc:         "no exemption",
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusTooManyRequests,
+		},
+		{
+			desc:         "exempted source",
+			exemptions:   &config.RateLimitExemptions{SourceRange: []string{"10.0.0.0/24"}},
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "other source",
+			exemptions:   &config.RateLimitExemptions{SourceRange: []string{"10.0.1.0/24"}},
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusTooManyRequests,
+		},
+		{
+			desc:         "exempted source with ip strategy",
+			exemptions:   &config.RateLimitExemptions{SourceRange: []string{"192.168.0.1"}, IPStrategy: &config.IPStrategy{Depth: 1}},
+			remoteAddr:   "10.0.0.1:1234",
+			headers:      map[string]string{"X-Forwarded-For": "192.168.0.1"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "exempted API key",
+			exemptions:   &config.RateLimitExemptions{APIKeys: []string{"foo", "bar"}},
+			remoteAddr:   "10.0.0.1:1234",
+			headers:      map[string]string{"X-Api-Key": "bar"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "exempted API key in another header",
+			exemptions:   &config.RateLimitExemptions{APIKeys: []string{"foo"}, APIKeyHeader: "Authorization"},
+			remoteAddr:   "10.0.0.1:1234",
+			headers:      map[string]string{"X-Api-Key": "foo"},
+			expectedCode: http.StatusTooManyRequests,
+		},
+		{
+			desc:         "exempted header",
+			exemptions:   &config.RateLimitExemptions{Headers: map[string]string{"User-Agent": "health-checker"}},
+			remoteAddr:   "10.0.0.1:1234",
+			headers:      map[string]string{"User-Agent": "health-checker"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "exempted path",
+			exemptions:   &config.RateLimitExemptions{Paths: []string{"^/health"}},
+			remoteAddr:   "10.0.0.1:1234",
+			path:         "/healthz",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "other path",
+			exemptions:   &config.RateLimitExemptions{Paths: []string{"^/health"}},
+			remoteAddr:   "10.0.0.1:1234",
+			path:         "/api/health",
+			expectedCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			handler, err := New(context.Background(), next, config.RateLimit{
+				ExtractorFunc: "client.ip",
+				RateSet: map[string]*config.Rate{
+					"foo": {Period: parse.Duration(time.Minute), Average: 1, Burst: 1},
+				},
+				Exemptions: test.exemptions,
+			}, "test")
+			require.NoError(t, err)
+
+			var code int
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodGet, "http://foo"+test.path, nil)
+				req.RemoteAddr = test.remoteAddr
+				for name, value := range test.headers {
+					req.Header.Set(name, value)
+				}
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+				code = recorder.Code
+			}
+
+			assert.Equal(t, test.expectedCode, code)
+		})
+	}
+}
+
+func TestNewExemptionsErrors(t *testing.T) {
+	_, err := newExemptions(&config.RateLimitExemptions{SourceRange: []string{"foo"}})
+	assert.Error(t, err)
+
+	_, err = newExemptions(&config.RateLimitExemptions{Paths: []string{"("}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package ratelimiter
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/lru"
+	"github.com/vulcand/oxy/utils"
+	"golang.org/x/time/rate"
+)
+
+const defaultMaxDelay = time.Second
+
+// leakyBucket delays the requests of a source to conform to its rates,
+// and rejects the ones which would wait longer than the max delay.
+type leakyBucket struct {
+	next      http.Handler
+	extractor utils.SourceExtractor
+	rates     []*config.Rate
+	maxDelay  time.Duration
+	// ttl is the duration the limiters of an inactive source are kept.
+	ttl time.Duration
+
+	lock sync.Mutex
+	// sources holds the limiters of the sources, the least recently seen ones being evicted when it is full.
+	sources *lru.Cache
+}
+
+func newLeakyBucket(next http.Handler, extractor utils.SourceExtractor, rates map[string]*config.Rate, maxDelay time.Duration) (*leakyBucket, error) {
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("no rate defined")
+	}
+
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+
+	lb := &leakyBucket{
+		next:      next,
+		extractor: extractor,
+		maxDelay:  maxDelay,
+		sources:   lru.New("ratelimiter"),
+	}
+
+	var maxPeriod time.Duration
+	for name, r := range rates {
+		if r.Period <= 0 || r.Average <= 0 {
+			return nil, fmt.Errorf("invalid rate %s: the period and the average must be positive", name)
+		}
+		if time.Duration(r.Period) > maxPeriod {
+			maxPeriod = time.Duration(r.Period)
+		}
+		lb.rates = append(lb.rates, r)
+	}
+
+	// As in the reject mode, the limiters expire after 10 times the longest period of inactivity.
+	lb.ttl = maxPeriod*10 + time.Second
+
+	return lb, nil
+}
+
+func (lb *leakyBucket) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	source, amount, err := lb.extractor.Extract(req)
+	if err != nil {
+		utils.DefaultHandler.ServeHTTP(rw, req, err)
+		return
+	}
+
+	delay, ok := lb.reserve(source, int(amount))
+	if !ok {
+		log.FromContext(req.Context()).Debugf("Limiting request %s %s: it would wait more than %s", req.Method, req.URL, lb.maxDelay)
+		rw.Header().Set("Retry-After", fmt.Sprintf("%.0f", lb.maxDelay.Seconds()))
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			// The slot of the request is not given back: the client is gone, its rate is consumed.
+			timer.Stop()
+			return
+		}
+	}
+
+	lb.next.ServeHTTP(rw, req)
+}
+
+// reserve reserves the amount of tokens on all the limiters of the source,
+// and returns the delay to wait for them, or false if it is longer than the max delay.
+func (lb *leakyBucket) reserve(source string, amount int) (time.Duration, bool) {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+
+	limiters := lb.getLimiters(source)
+
+	now := time.Now()
+	var delay time.Duration
+	var reservations []*rate.Reservation
+	for _, limiter := range limiters {
+		reservation := limiter.ReserveN(now, amount)
+		reservations = append(reservations, reservation)
+
+		if !reservation.OK() || reservation.DelayFrom(now) > lb.maxDelay {
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			return 0, false
+		}
+
+		if d := reservation.DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+
+	return delay, true
+}
+
+func (lb *leakyBucket) getLimiters(source string) []*rate.Limiter {
+	value, ok := lb.sources.Get(source)
+	if !ok {
+		var limiters []*rate.Limiter
+		for _, r := range lb.rates {
+			burst := int(r.Burst)
+			if burst < 1 {
+				burst = 1
+			}
+			limit := rate.Limit(float64(r.Average) / time.Duration(r.Period).Seconds())
+			limiters = append(limiters, rate.NewLimiter(limit, burst))
+		}
+		value = limiters
+	}
+
+	// Each request extends the lifetime of the limiters of the source.
+	lb.sources.Set(source, value, lb.ttl)
+
+	return value.([]*rate.Limiter)
+}
@@ -0,0 +1,119 @@
+package ratelimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMode(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	testCases := []struct {
+		desc        string
+		config      config.RateLimit
+		expectedErr bool
+	}{
+		{
+			desc: "reject",
+			config: config.RateLimit{
+				ExtractorFunc: "client.ip",
+				RateSet:       map[string]*config.Rate{"foo": {Period: parse.Duration(time.Second), Average: 10, Burst: 10}},
+			},
+		},
+		{
+			desc: "leaky bucket",
+			config: config.RateLimit{
+				ExtractorFunc: "client.ip",
+				Mode:          ModeLeakyBucket,
+				RateSet:       map[string]*config.Rate{"foo": {Period: parse.Duration(time.Second), Average: 10}},
+			},
+		},
+		{
+			desc: "leaky bucket without period",
+			config: config.RateLimit{
+				ExtractorFunc: "client.ip",
+				Mode:          ModeLeakyBucket,
+				RateSet:       map[string]*config.Rate{"foo": {Average: 10}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "unknown mode",
+			config: config.RateLimit{
+				ExtractorFunc: "client.ip",
+				Mode:          "foo",
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), next, test.config, "test")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLeakyBucket(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(context.Background(), next, config.RateLimit{
+		ExtractorFunc: "client.ip",
+		Mode:          ModeLeakyBucket,
+		MaxDelay:      parse.Duration(250 * time.Millisecond),
+		RateSet: map[string]*config.Rate{
+			"foo": {Period: parse.Duration(100 * time.Millisecond), Average: 1, Burst: 1},
+		},
+	}, "test")
+	require.NoError(t, err)
+
+	serve := func(remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	start := time.Now()
+
+	// The requests are spaced by 100ms: the first one passes, the next two wait 100ms and 200ms,
+	// and the last one would wait 300ms.
+	codes := make(chan int, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			codes <- serve("10.0.0.1:1234")
+		}()
+	}
+
+	var rejected int
+	for i := 0; i < 4; i++ {
+		if <-codes == http.StatusTooManyRequests {
+			rejected++
+		}
+	}
+	assert.Equal(t, 1, rejected)
+	assert.True(t, time.Since(start) >= 150*time.Millisecond)
+
+	// Another source has its own bucket.
+	start = time.Now()
+	assert.Equal(t, http.StatusOK, serve("10.0.0.2:1234"))
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+}
@@ -2,35 +2,63 @@ package ratelimiter
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/lru"
 	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
 	"github.com/containous/traefik/tracing"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/vulcand/oxy/ratelimit"
-	"github.com/vulcand/oxy/utils"
 )
 
 const (
 	typeName = "RateLimiterType"
 )
 
+const (
+	// ModeReject rejects the requests over the rates right away.
+	ModeReject = "reject"
+	// ModeLeakyBucket delays the requests over the rates, up to the max delay.
+	ModeLeakyBucket = "leakyBucket"
+)
+
 type rateLimiter struct {
-	handler http.Handler
-	name    string
+	handler    http.Handler
+	next       http.Handler
+	exemptions *exemptions
+	name       string
 }
 
 // New creates rate limiter middleware.
 func New(ctx context.Context, next http.Handler, config config.RateLimit, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
 
-	extractFunc, err := utils.NewExtractor(config.ExtractorFunc)
+	extractFunc, err := forwardedheaders.NewExtractor(config.ExtractorFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	exempt, err := newExemptions(config.Exemptions)
 	if err != nil {
 		return nil, err
 	}
 
+	switch config.Mode {
+	case ModeReject, "":
+	case ModeLeakyBucket:
+		lb, err := newLeakyBucket(next, extractFunc, config.RateSet, time.Duration(config.MaxDelay))
+		if err != nil {
+			return nil, err
+		}
+		return &rateLimiter{handler: lb, next: next, exemptions: exempt, name: name}, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit mode %q", config.Mode)
+	}
+
 	rateSet := ratelimit.NewRateSet()
 	for _, rate := range config.RateSet {
 		if err = rateSet.Add(time.Duration(rate.Period), rate.Average, rate.Burst); err != nil {
@@ -38,11 +66,12 @@ func New(ctx context.Context, next http.Handler, config config.RateLimit, name s
 		}
 	}
 
-	rl, err := ratelimit.New(next, extractFunc, rateSet)
+	// The sources are bounded by the budget of the caches, the ones expiring first being evicted when it is reached.
+	rl, err := ratelimit.New(next, extractFunc, rateSet, ratelimit.Capacity(lru.MaxEntries()))
 	if err != nil {
 		return nil, err
 	}
-	return &rateLimiter{handler: rl, name: name}, nil
+	return &rateLimiter{handler: rl, next: next, exemptions: exempt, name: name}, nil
 }
 
 func (r *rateLimiter) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -50,5 +79,11 @@ func (r *rateLimiter) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (r *rateLimiter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if exemption := r.exemptions.match(req); exemption != "" {
+		middlewares.GetLogger(req.Context(), r.name, typeName).Debugf("Request exempted from rate limiting by %s", exemption)
+		r.next.ServeHTTP(rw, req)
+		return
+	}
+
 	r.handler.ServeHTTP(rw, req)
 }
@@ -40,7 +40,7 @@ func (r *replacePath) GetTracingInformation() (string, ext.SpanKindEnum) {
 
 func (r *replacePath) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	req.Header.Add(ReplacedPathHeader, req.URL.Path)
-	req.URL.Path = r.path
+	req.URL.Path = middlewares.ExpandRouteVars(req, r.path)
 	req.RequestURI = req.URL.RequestURI()
 	r.next.ServeHTTP(rw, req)
 }
@@ -50,7 +50,7 @@ func (rp *replacePathRegex) GetTracingInformation() (string, ext.SpanKindEnum) {
 func (rp *replacePathRegex) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	if rp.regexp != nil && len(rp.replacement) > 0 && rp.regexp.MatchString(req.URL.Path) {
 		req.Header.Add(replacepath.ReplacedPathHeader, req.URL.Path)
-		req.URL.Path = rp.regexp.ReplaceAllString(req.URL.Path, rp.replacement)
+		req.URL.Path = rp.regexp.ReplaceAllString(req.URL.Path, middlewares.ExpandRouteVars(req, rp.replacement))
 		req.RequestURI = req.URL.RequestURI()
 	}
 	rp.next.ServeHTTP(rw, req)
@@ -0,0 +1,113 @@
+package requestbuffering
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/mailgun/multibuf"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "RequestBuffering"
+)
+
+// requestBuffering is a middleware reading the whole request body before forwarding the request.
+// The body is kept in memory up to a limit, and written to a temporary file beyond it.
+// The backend connection is opened only once the body has been received, so slow clients don't hold it,
+// and the body can be replayed: a retry middleware after this one rewinds it for each attempt.
+type requestBuffering struct {
+	next     http.Handler
+	name     string
+	maxBytes int64
+	memBytes int64
+}
+
+// New creates a new request buffering middleware.
+func New(ctx context.Context, next http.Handler, config config.RequestBuffering, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug("Creating middleware")
+	logger.Debugf("Setting up request buffering: %d (mem), %d (max)", config.MemRequestBodyBytes, config.MaxRequestBodyBytes)
+
+	memBytes := config.MemRequestBodyBytes
+	if memBytes <= 0 {
+		memBytes = multibuf.DefaultMemBytes
+	}
+
+	maxBytes := config.MaxRequestBodyBytes
+	if maxBytes <= 0 {
+		maxBytes = multibuf.DefaultMaxBytes
+	}
+
+	return &requestBuffering{
+		next:     next,
+		name:     name,
+		maxBytes: maxBytes,
+		memBytes: memBytes,
+	}, nil
+}
+
+func (r *requestBuffering) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return r.name, tracing.SpanKindNoneEnum
+}
+
+func (r *requestBuffering) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), r.name, typeName)
+
+	if r.maxBytes > 0 && req.ContentLength > r.maxBytes {
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body, err := multibuf.New(req.Body, multibuf.MaxBytes(r.maxBytes), multibuf.MemBytes(r.memBytes))
+	if err != nil {
+		if _, ok := err.(*multibuf.MaxSizeReachedError); ok {
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		logger.Debugf("Error while reading the request body: %v", err)
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	defer func() {
+		if err := body.Close(); err != nil {
+			logger.Errorf("Error while closing the buffered request body: %v", err)
+		}
+	}()
+
+	size, err := body.Size()
+	if err != nil {
+		logger.Errorf("Error while getting the size of the request body: %v", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	outReq := *req
+	outReq.ContentLength = size
+	// The body is no longer chunked, its length is known.
+	outReq.TransferEncoding = nil
+	outReq.Body = http.NoBody
+	if size > 0 {
+		outReq.Body = &bufferedBody{MultiReader: body}
+	}
+
+	r.next.ServeHTTP(rw, &outReq)
+}
+
+// bufferedBody is a request body that can be rewound.
+// Closing it is a no-op: the middleware removes the buffer once the request has been served.
+type bufferedBody struct {
+	multibuf.MultiReader
+}
+
+// Close does nothing, the buffer is closed by the middleware.
+func (b *bufferedBody) Close() error {
+	return nil
+}
+
+var _ io.ReadSeeker = (*bufferedBody)(nil)
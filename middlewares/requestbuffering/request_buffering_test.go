@@ -0,0 +1,105 @@
+package requestbuffering
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBuffering(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             config.RequestBuffering
+		body               string
+		chunked            bool
+		expectedStatusCode int
+	}{
+		{
+			desc:               "body in memory",
+			config:             config.RequestBuffering{MaxRequestBodyBytes: 100, MemRequestBodyBytes: 100},
+			body:               "foo bar",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "body written to a file",
+			config:             config.RequestBuffering{MaxRequestBodyBytes: 100, MemRequestBodyBytes: 2},
+			body:               "foo bar",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "chunked body",
+			config:             config.RequestBuffering{},
+			body:               "foo bar",
+			chunked:            true,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "empty body",
+			config:             config.RequestBuffering{},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "content length over the limit",
+			config:             config.RequestBuffering{MaxRequestBodyBytes: 2},
+			body:               "foo bar",
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:               "chunked body over the limit",
+			config:             config.RequestBuffering{MaxRequestBodyBytes: 2},
+			body:               "foo bar",
+			chunked:            true,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, int64(len(test.body)), req.ContentLength)
+				assert.Empty(t, req.TransferEncoding)
+
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.body, string(body))
+
+				if len(test.body) > 0 {
+					// The body can be replayed.
+					seeker, ok := req.Body.(io.Seeker)
+					require.True(t, ok)
+					_, err = seeker.Seek(0, io.SeekStart)
+					require.NoError(t, err)
+
+					body, err = ioutil.ReadAll(req.Body)
+					require.NoError(t, err)
+					assert.Equal(t, test.body, string(body))
+				}
+			})
+
+			handler, err := New(context.Background(), next, test.config, "requestBuffering")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(test.body))
+			if test.chunked {
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+		})
+	}
+}
@@ -9,10 +9,13 @@ import (
 	"time"
 
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/lru"
 	"github.com/miekg/dns"
-	"github.com/patrickmn/go-cache"
 )
 
+// defaultCacheDuration is the duration a host is cached when it is not a CNAME record, or when its record has no TTL.
+const defaultCacheDuration = 30 * time.Minute
+
 type cnameResolv struct {
 	TTL    time.Duration
 	Record string
@@ -29,13 +32,13 @@ type Resolver struct {
 	CnameFlattening bool
 	ResolvConfig    string
 	ResolvDepth     int
-	cache           *cache.Cache
+	cache           *lru.Cache
 }
 
 // CNAMEFlatten check if CNAME record exists, flatten if possible.
 func (hr *Resolver) CNAMEFlatten(ctx context.Context, host string) string {
 	if hr.cache == nil {
-		hr.cache = cache.New(30*time.Minute, 5*time.Minute)
+		hr.cache = lru.New("hostresolver")
 	}
 
 	result := host
@@ -65,9 +68,10 @@ func (hr *Resolver) CNAMEFlatten(ctx context.Context, host string) string {
 		request = resolv.Record
 	}
 
-	if err := hr.cache.Add(host, result, cacheDuration); err != nil {
-		logger.Error(err)
+	if cacheDuration <= 0 {
+		cacheDuration = defaultCacheDuration
 	}
+	hr.cache.Set(host, result, cacheDuration)
 
 	return result
 }
@@ -0,0 +1,229 @@
+package requestid
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/ip"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/accesslog"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	guuid "github.com/satori/go.uuid"
+)
+
+const (
+	typeName = "RequestID"
+
+	// DefaultHeaderName is the header used when none is configured.
+	DefaultHeaderName = "X-Request-ID"
+
+	// FormatUUIDv7 generates time-ordered UUIDs (draft RFC 4122 version 7).
+	FormatUUIDv7 = "uuidv7"
+	// FormatUUIDv4 generates random UUIDs.
+	FormatUUIDv4 = "uuidv4"
+
+	// maxLength is the maximum length accepted for an incoming request ID.
+	maxLength = 200
+)
+
+type key int
+
+const requestIDKey key = iota
+
+// requestID is a middleware that ensures every request carries a unique ID.
+// The ID is sent back in the response, replacing the one set by the backend, if any,
+// so that the client gets the ID the request was forwarded and logged with.
+type requestID struct {
+	next       http.Handler
+	name       string
+	headerName string
+	generate   func() string
+	checker    *ip.Checker
+}
+
+// New creates a new request ID middleware.
+func New(ctx context.Context, next http.Handler, config config.RequestID, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	headerName := config.HeaderName
+	if len(headerName) == 0 {
+		headerName = DefaultHeaderName
+	}
+
+	var generate func() string
+	switch config.Format {
+	case FormatUUIDv7, "":
+		generate = newUUIDv7
+	case FormatUUIDv4:
+		generate = newUUIDv4
+	default:
+		return nil, fmt.Errorf("unknown request ID format: %q", config.Format)
+	}
+
+	var checker *ip.Checker
+	if len(config.TrustedIPs) > 0 {
+		var err error
+		checker, err = ip.NewChecker(config.TrustedIPs)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse trusted IPs %s: %v", config.TrustedIPs, err)
+		}
+	}
+
+	return &requestID{
+		next:       next,
+		name:       name,
+		headerName: http.CanonicalHeaderKey(headerName),
+		generate:   generate,
+		checker:    checker,
+	}, nil
+}
+
+func (r *requestID) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return r.name, tracing.SpanKindNoneEnum
+}
+
+func (r *requestID) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	id := req.Header.Get(r.headerName)
+	if !r.isTrusted(req, id) {
+		id = r.generate()
+		middlewares.GetLogger(req.Context(), r.name, typeName).Debugf("Assigning request ID %s", id)
+	}
+
+	req.Header.Set(r.headerName, id)
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		logData.Core[accesslog.RequestID] = id
+	}
+
+	if span := tracing.GetSpan(req); span != nil {
+		span.SetTag("request.id", id)
+	}
+
+	writer := &responseWriter{ResponseWriter: rw, headerName: r.headerName, id: id}
+	r.next.ServeHTTP(writer, req.WithContext(context.WithValue(req.Context(), requestIDKey, id)))
+
+	// The response headers are sent even without a response written by the backend.
+	if !writer.wroteHeader && !writer.hijacked {
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// isTrusted reports whether the incoming request ID can be kept as is.
+func (r *requestID) isTrusted(req *http.Request, id string) bool {
+	if !isValid(id) {
+		return false
+	}
+
+	if r.checker == nil {
+		return true
+	}
+
+	return r.checker.IsAuthorized(req.RemoteAddr) == nil
+}
+
+// FromContext returns the request ID stored in the context, if any.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// isValid rejects empty, oversized, or non-printable request IDs.
+func isValid(id string) bool {
+	if len(id) == 0 || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newUUIDv4() string {
+	return guuid.NewV4().String()
+}
+
+// newUUIDv7 builds a UUID whose first 48 bits are the Unix timestamp in milliseconds,
+// so that IDs sort by creation time.
+func newUUIDv7() string {
+	var u guuid.UUID
+
+	if _, err := rand.Read(u[6:]); err != nil {
+		return newUUIDv4()
+	}
+
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(u[:6], ts[2:])
+
+	u[6] = (u[6] & 0x0f) | 0x70
+	u.SetVariant(guuid.VariantRFC4122)
+
+	return u.String()
+}
+
+// responseWriter sets the request ID header when the response headers are written.
+type responseWriter struct {
+	http.ResponseWriter
+	headerName  string
+	id          string
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (r *responseWriter) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.ResponseWriter.Header().Set(r.headerName, r.id)
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseWriter) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseWriter) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+	r.hijacked = true
+	return hijacker.Hijack()
+}
+
+// CloseNotify returns a channel that receives at most a single value (true)
+// when the client connection has gone away.
+func (r *responseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := r.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(<-chan bool)
+}
@@ -0,0 +1,206 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-([0-9a-f])[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.RequestID
+		expectedError bool
+	}{
+		{
+			desc:   "default configuration",
+			config: config.RequestID{},
+		},
+		{
+			desc:   "uuidv4 format",
+			config: config.RequestID{Format: FormatUUIDv4},
+		},
+		{
+			desc:          "unknown format",
+			config:        config.RequestID{Format: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid trusted IPs",
+			config:        config.RequestID{TrustedIPs: []string{"foo"}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, handler)
+			}
+		})
+	}
+}
+
+func TestRequestID_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        config.RequestID
+		remoteAddr    string
+		incomingID    string
+		expectedID    string
+		expectedValid bool
+	}{
+		{
+			desc:       "generates an ID when absent",
+			remoteAddr: "10.0.0.1:1234",
+		},
+		{
+			desc:       "keeps an incoming ID without trusted IPs",
+			remoteAddr: "10.0.0.1:1234",
+			incomingID: "foo-bar",
+			expectedID: "foo-bar",
+		},
+		{
+			desc:       "keeps an incoming ID from a trusted peer",
+			config:     config.RequestID{TrustedIPs: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.0.0.1:1234",
+			incomingID: "foo-bar",
+			expectedID: "foo-bar",
+		},
+		{
+			desc:       "replaces an incoming ID from an untrusted peer",
+			config:     config.RequestID{TrustedIPs: []string{"10.0.0.0/8"}},
+			remoteAddr: "192.168.0.1:1234",
+			incomingID: "foo-bar",
+		},
+		{
+			desc:       "replaces an invalid incoming ID",
+			remoteAddr: "10.0.0.1:1234",
+			incomingID: "foo bar",
+		},
+		{
+			desc:       "uses a custom header",
+			config:     config.RequestID{HeaderName: "X-Correlation-ID"},
+			remoteAddr: "10.0.0.1:1234",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			headerName := DefaultHeaderName
+			if test.config.HeaderName != "" {
+				headerName = test.config.HeaderName
+			}
+
+			var backendID, contextID string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				backendID = req.Header.Get(headerName)
+				contextID = FromContext(req.Context())
+			})
+
+			handler, err := New(context.Background(), next, test.config, "traefikTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.incomingID != "" {
+				req.Header.Set(headerName, test.incomingID)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			responseID := recorder.Header().Get(headerName)
+			assert.Equal(t, responseID, backendID)
+			assert.Equal(t, responseID, contextID)
+
+			if test.expectedID != "" {
+				assert.Equal(t, test.expectedID, responseID)
+			} else {
+				assert.NotEqual(t, test.incomingID, responseID)
+				assert.Regexp(t, uuidRegexp, responseID)
+			}
+		})
+	}
+}
+
+func TestRequestID_ResponseHeader(t *testing.T) {
+	testCases := []struct {
+		desc string
+		next http.HandlerFunc
+	}{
+		{
+			desc: "without header from the backend",
+			next: func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("foo"))
+			},
+		},
+		{
+			desc: "replaces the header set by the backend",
+			next: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set(DefaultHeaderName, "backend-id")
+				rw.WriteHeader(http.StatusNotFound)
+			},
+		},
+		{
+			desc: "replaces the header added by the backend",
+			next: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Add(DefaultHeaderName, "backend-id")
+				_, _ = rw.Write([]byte("foo"))
+			},
+		},
+		{
+			desc: "without response from the backend",
+			next: func(rw http.ResponseWriter, req *http.Request) {},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := New(context.Background(), test.next, config.RequestID{}, "traefikTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Set(DefaultHeaderName, "foo-bar")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, []string{"foo-bar"}, recorder.Header()[http.CanonicalHeaderKey(DefaultHeaderName)])
+		})
+	}
+}
+
+func TestNewUUIDv7(t *testing.T) {
+	first := newUUIDv7()
+	second := newUUIDv7()
+
+	match := uuidRegexp.FindStringSubmatch(first)
+	require.Len(t, match, 2)
+	assert.Equal(t, "7", match[1])
+
+	assert.NotEqual(t, first, second)
+	assert.True(t, first[:8] <= second[:8], "UUIDv7 should be time-ordered")
+}
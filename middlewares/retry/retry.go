@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -71,8 +72,20 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		req.Body = ioutil.NopCloser(body)
 	}
 
+	// A body buffered by a previous middleware is rewound before each new attempt.
+	seeker, _ := req.Body.(io.Seeker)
+
 	attempts := 1
 	for {
+		if attempts > 1 && seeker != nil {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				logger := middlewares.GetLogger(req.Context(), r.name, typeName)
+				logger.Errorf("Unable to rewind the request body for attempt %d: %v", attempts, err)
+				rw.WriteHeader(http.StatusBadGateway)
+				return
+			}
+		}
+
 		attemptsExhausted := attempts >= r.attempts
 		shouldRetry := !attemptsExhausted
 		retryResponseWriter := newResponseWriter(rw, shouldRetry)
@@ -2,6 +2,7 @@ package retry
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -261,3 +262,34 @@ func TestRetryWebsocket(t *testing.T) {
 		})
 	}
 }
+
+type seekableBody struct {
+	*strings.Reader
+}
+
+func (seekableBody) Close() error { return nil }
+
+func TestRetryRewindsBufferedBody(t *testing.T) {
+	var bodies []string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+
+		// Nothing reaches a backend: the request is retried until the attempts are exhausted.
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, config.Retry{Attempts: 3}, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+	req.Body = seekableBody{strings.NewReader("foo")}
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, []string{"foo", "foo", "foo"}, bodies)
+}
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/containous/mux"
+)
+
+var routeVarPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandRouteVars replaces the {name} placeholders of the template with the variables captured by the rule of the router,
+// e.g. tenant for HostRegexp:{tenant:[a-z]+}.example.com.
+// The placeholders of unknown variables are kept as is.
+func ExpandRouteVars(req *http.Request, template string) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+
+	vars := mux.Vars(req)
+	if len(vars) == 0 {
+		return template
+	}
+
+	return routeVarPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		if value, ok := vars[placeholder[1:len(placeholder)-1]]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
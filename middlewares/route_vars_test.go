@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandRouteVars(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		template string
+		expected string
+	}{
+		{
+			desc:     "no placeholder",
+			template: "/api",
+			expected: "/api",
+		},
+		{
+			desc:     "host variable",
+			template: "/tenants/{tenant}/api",
+			expected: "/tenants/acme/api",
+		},
+		{
+			desc:     "several variables",
+			template: "/{tenant}/{version}",
+			expected: "/acme/v2",
+		},
+		{
+			desc:     "unknown variable",
+			template: "/{tenant}/{region}",
+			expected: "/acme/{region}",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var expanded string
+			router := mux.NewRouter()
+			router.Host("{tenant:[a-z]+}.example.com").Path("/{version}").
+				HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+					expanded = ExpandRouteVars(req, test.template)
+				})
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://acme.example.com/v2", nil)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, test.expected, expanded)
+		})
+	}
+}
+
+func TestExpandRouteVarsWithoutVars(t *testing.T) {
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://acme.example.com/v2", nil)
+
+	assert.Equal(t, "/{tenant}", ExpandRouteVars(req, "/{tenant}"))
+}
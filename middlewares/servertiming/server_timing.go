@@ -0,0 +1,259 @@
+package servertiming
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderName is the name of the header carrying the timing breakdown of the responses.
+const HeaderName = "Server-Timing"
+
+type key int
+
+const timingKey key = iota
+
+// Timing holds the instants of the phases of a request, from its arrival on the entry point to the end of its response.
+// The instants of the last attempt are kept when a request is forwarded several times.
+type Timing struct {
+	lock      sync.Mutex
+	start     time.Time
+	dequeued  time.Time
+	forwarded time.Time
+	getConn   time.Time
+	gotConn   time.Time
+	firstByte time.Time
+	end       time.Time
+}
+
+// Durations is the breakdown of the processing time of a request.
+// The durations of the phases which are not reached yet run until now.
+type Durations struct {
+	// Queue is the time spent waiting for the request limits of the entry points.
+	Queue time.Duration
+	// Middleware is the time spent in the routers and middlewares, until the request is forwarded to a server.
+	Middleware time.Duration
+	// Forwarded is true when the request reached a server, and the durations below are set.
+	Forwarded bool
+	// Connect is the time taken to get a connection to the server, zero for a reused connection.
+	Connect time.Duration
+	// TTFB is the time between the connection to the server and the first byte of its response.
+	TTFB time.Duration
+	// Transfer is the time taken to forward the response to the client, from its first byte.
+	Transfer time.Duration
+}
+
+// FromContext returns the timing of the request of the context, nil if its entry point doesn't time the requests.
+func FromContext(ctx context.Context) *Timing {
+	timing, _ := ctx.Value(timingKey).(*Timing)
+	return timing
+}
+
+// Track returns the timing of the request of the context,
+// or a context with a new timing starting now if the entry point of the request doesn't time the requests.
+func Track(ctx context.Context) (context.Context, *Timing) {
+	if timing := FromContext(ctx); timing != nil {
+		return ctx, timing
+	}
+
+	now := time.Now()
+	timing := &Timing{start: now, dequeued: now}
+	return context.WithValue(ctx, timingKey, timing), timing
+}
+
+func (t *Timing) mark(instant *time.Time) {
+	t.lock.Lock()
+	*instant = time.Now()
+	t.lock.Unlock()
+}
+
+// Durations returns the breakdown of the processing time of the request so far.
+func (t *Timing) Durations() Durations {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := time.Now()
+	until := func(instant time.Time) time.Time {
+		if instant.IsZero() {
+			return now
+		}
+		return instant
+	}
+
+	dequeued := until(t.dequeued)
+	durations := Durations{
+		Queue:      dequeued.Sub(t.start),
+		Middleware: until(t.forwarded).Sub(dequeued),
+		Forwarded:  !t.forwarded.IsZero(),
+	}
+
+	if !durations.Forwarded {
+		return durations
+	}
+
+	if !t.getConn.IsZero() {
+		durations.Connect = until(t.gotConn).Sub(t.getConn)
+	}
+	if !t.gotConn.IsZero() {
+		durations.TTFB = until(t.firstByte).Sub(t.gotConn)
+	}
+	if !t.firstByte.IsZero() {
+		durations.Transfer = until(t.end).Sub(t.firstByte)
+	}
+	return durations
+}
+
+// Start returns a handler timing the requests, and writing the breakdown of their processing time in the Server-Timing header of their responses.
+// The transfer time is only known once the response is written, and is sent in a Server-Timing trailer, which only the chunked responses can carry.
+func Start(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		timing := &Timing{start: time.Now()}
+		writer := newResponseWriter(rw, timing)
+
+		next.ServeHTTP(writer, req.WithContext(context.WithValue(req.Context(), timingKey, timing)))
+
+		if !writer.wroteHeader() {
+			return
+		}
+
+		durations := timing.Durations()
+		if durations.Forwarded {
+			rw.Header().Set(http.TrailerPrefix+HeaderName, format("transfer", durations.Transfer))
+		}
+	})
+}
+
+// Dequeue returns a handler marking the end of the wait for the request limits of the entry points.
+func Dequeue(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if timing := FromContext(req.Context()); timing != nil {
+			timing.mark(&timing.dequeued)
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// TraceForwarder returns a handler timing the connection to the server, and the response of the server, of the requests forwarded by next.
+func TraceForwarder(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		timing := FromContext(req.Context())
+		if timing == nil {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		timing.lock.Lock()
+		timing.forwarded = time.Now()
+		timing.getConn = time.Time{}
+		timing.gotConn = time.Time{}
+		timing.firstByte = time.Time{}
+		timing.end = time.Time{}
+		timing.lock.Unlock()
+
+		trace := &httptrace.ClientTrace{
+			GetConn: func(string) {
+				timing.mark(&timing.getConn)
+			},
+			GotConn: func(info httptrace.GotConnInfo) {
+				timing.lock.Lock()
+				defer timing.lock.Unlock()
+
+				timing.gotConn = time.Now()
+				if info.Reused {
+					timing.getConn = time.Time{}
+				}
+			},
+			GotFirstResponseByte: func() {
+				timing.mark(&timing.firstByte)
+			},
+		}
+
+		next.ServeHTTP(rw, req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+
+		timing.mark(&timing.end)
+	})
+}
+
+// Header returns the value of the Server-Timing header of a breakdown, without the transfer time.
+func Header(durations Durations) string {
+	metrics := []string{
+		format("queue", durations.Queue),
+		format("middleware", durations.Middleware),
+	}
+	if durations.Forwarded {
+		metrics = append(metrics, format("connect", durations.Connect), format("ttfb", durations.TTFB))
+	}
+	return strings.Join(metrics, ", ")
+}
+
+// format formats a metric of the Server-Timing header, whose durations are in milliseconds.
+func format(name string, duration time.Duration) string {
+	return name + ";dur=" + strconv.FormatFloat(float64(duration)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+type responseWriterWithoutCloseNotify struct {
+	http.ResponseWriter
+	timing  *Timing
+	written bool
+}
+
+func (w *responseWriterWithoutCloseNotify) WriteHeader(code int) {
+	if !w.written {
+		w.written = true
+		w.Header().Add(HeaderName, Header(w.timing.Durations()))
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriterWithoutCloseNotify) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseWriterWithoutCloseNotify) wroteHeader() bool {
+	return w.written
+}
+
+// Hijack hijacks the connection.
+func (w *responseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (w *responseWriterWithoutCloseNotify) Flush() {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type responseWriterWithCloseNotify struct {
+	*responseWriterWithoutCloseNotify
+}
+
+func (w *responseWriterWithCloseNotify) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+type timingResponseWriter interface {
+	http.ResponseWriter
+	wroteHeader() bool
+}
+
+func newResponseWriter(rw http.ResponseWriter, timing *Timing) timingResponseWriter {
+	writer := &responseWriterWithoutCloseNotify{ResponseWriter: rw, timing: timing}
+	if _, ok := rw.(http.CloseNotifier); ok {
+		return &responseWriterWithCloseNotify{writer}
+	}
+	return writer
+}
@@ -0,0 +1,125 @@
+package servertiming
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerTiming(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		forward         bool
+		chunked         bool
+		expectedHeader  string
+		expectedTrailer string
+	}{
+		{
+			desc:           "answered by a middleware",
+			expectedHeader: `^queue;dur=\d+\.\d{3}, middleware;dur=\d+\.\d{3}$`,
+		},
+		{
+			desc:           "forwarded",
+			forward:        true,
+			expectedHeader: `^queue;dur=\d+\.\d{3}, middleware;dur=\d+\.\d{3}, connect;dur=\d+\.\d{3}, ttfb;dur=\d+\.\d{3}$`,
+		},
+		{
+			desc:            "forwarded chunked response",
+			forward:         true,
+			chunked:         true,
+			expectedHeader:  `^queue;dur=\d+\.\d{3}, middleware;dur=\d+\.\d{3}, connect;dur=\d+\.\d{3}, ttfb;dur=\d+\.\d{3}$`,
+			expectedTrailer: `^transfer;dur=\d+\.\d{3}$`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set(HeaderName, "db;dur=1")
+				if !test.chunked {
+					rw.Header().Set("Content-Length", "5")
+				}
+				_, _ = rw.Write([]byte("hello"))
+				if test.chunked {
+					rw.(http.Flusher).Flush()
+				}
+			}))
+			defer backend.Close()
+
+			backendURL, err := url.Parse(backend.URL)
+			require.NoError(t, err)
+
+			var next http.Handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusNoContent)
+			})
+			if test.forward {
+				next = TraceForwarder(httputil.NewSingleHostReverseProxy(backendURL))
+			}
+
+			durationsChan := make(chan Durations, 1)
+			handler := Start(Dequeue(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				time.Sleep(time.Millisecond)
+				next.ServeHTTP(rw, req)
+				durationsChan <- FromContext(req.Context()).Durations()
+			})))
+
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			resp, err := http.Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			_, err = ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			values := resp.Header[HeaderName]
+			if test.forward {
+				require.Len(t, values, 2)
+				assert.Equal(t, "db;dur=1", values[0])
+				values = values[1:]
+			}
+			require.Len(t, values, 1)
+			assert.Regexp(t, regexp.MustCompile(test.expectedHeader), values[0])
+
+			if len(test.expectedTrailer) > 0 {
+				assert.Regexp(t, regexp.MustCompile(test.expectedTrailer), resp.Trailer.Get(HeaderName))
+			} else {
+				assert.Empty(t, resp.Trailer.Get(HeaderName))
+			}
+
+			durations := <-durationsChan
+			assert.Equal(t, test.forward, durations.Forwarded)
+			assert.True(t, durations.Middleware >= time.Millisecond)
+			if test.forward {
+				assert.True(t, durations.Connect > 0)
+				assert.True(t, durations.TTFB > 0)
+			}
+		})
+	}
+}
+
+func TestTraceForwarderWithoutTiming(t *testing.T) {
+	var traced bool
+	handler := TraceForwarder(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		traced = FromContext(req.Context()) != nil
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.False(t, traced)
+	assert.Empty(t, recorder.Header().Get(HeaderName))
+}
@@ -0,0 +1,355 @@
+package sessiongateway
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "SessionGateway"
+
+	defaultCookieName      = "_traefik_session"
+	defaultIdleTimeout     = 30 * time.Minute
+	defaultAbsoluteTimeout = 12 * time.Hour
+
+	// maxCookieSize is the size of a cookie most browsers accept.
+	maxCookieSize = 4096
+)
+
+var errInvalidSession = errors.New("invalid session cookie")
+
+// session holds the cookies set by the backend, and the lifetime of the session.
+type session struct {
+	Cookies  map[string]string `json:"c"`
+	Created  int64             `json:"t"`
+	LastSeen int64             `json:"l"`
+}
+
+// sessionGateway is a middleware keeping the cookies set by the backend in an encrypted and signed cookie.
+// The client only gets the session cookie: the backend cookies are extracted from it, and sent to the backend,
+// as long as the session has not expired.
+// The attributes of the backend cookies (domain, path, ...) are not kept: the cookies are sent with every request
+// going through the middleware.
+// The other cookies of the client are not sent to the backend, unless they are allowed,
+// so that the backend only gets cookies it set itself or expects from the client.
+type sessionGateway struct {
+	next            http.Handler
+	name            string
+	aead            cipher.AEAD
+	idleTimeout     time.Duration
+	absoluteTimeout time.Duration
+	cookie          http.Cookie
+	allowedCookies  map[string]struct{}
+	now             func() time.Time
+}
+
+// New creates a new session gateway middleware.
+func New(ctx context.Context, next http.Handler, config config.SessionGateway, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if len(config.Secret) == 0 {
+		return nil, errors.New("a secret is required")
+	}
+
+	key := sha256.Sum256([]byte(config.Secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &sessionGateway{
+		next:            next,
+		name:            name,
+		aead:            aead,
+		idleTimeout:     time.Duration(config.IdleTimeout),
+		absoluteTimeout: time.Duration(config.AbsoluteTimeout),
+		allowedCookies:  make(map[string]struct{}),
+		now:             time.Now,
+		cookie: http.Cookie{
+			Name:     config.CookieName,
+			Domain:   config.CookieDomain,
+			Path:     config.CookiePath,
+			Secure:   config.CookieSecure,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	}
+
+	if g.idleTimeout <= 0 {
+		g.idleTimeout = defaultIdleTimeout
+	}
+	if g.absoluteTimeout <= 0 {
+		g.absoluteTimeout = defaultAbsoluteTimeout
+	}
+	if len(g.cookie.Name) == 0 {
+		g.cookie.Name = defaultCookieName
+	}
+	if len(g.cookie.Path) == 0 {
+		g.cookie.Path = "/"
+	}
+
+	for _, name := range config.AllowedCookies {
+		g.allowedCookies[name] = struct{}{}
+	}
+
+	return g, nil
+}
+
+func (g *sessionGateway) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return g.name, tracing.SpanKindNoneEnum
+}
+
+func (g *sessionGateway) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), g.name, typeName)
+
+	var sess *session
+	_, err := req.Cookie(g.cookie.Name)
+	hasCookie := err == nil
+	if hasCookie {
+		sess, err = g.load(req)
+		if err != nil {
+			logger.Debugf("Session dropped: %v", err)
+		}
+	}
+
+	g.setRequestCookies(req, sess)
+
+	writer := &responseWriter{ResponseWriter: rw, gateway: g, session: sess, hasCookie: hasCookie, req: req}
+	g.next.ServeHTTP(writer, req)
+
+	// The backend can set cookies without writing a response.
+	if !writer.wroteHeader && !writer.hijacked {
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// load returns the session of the request cookie, if it is valid and has not expired.
+func (g *sessionGateway) load(req *http.Request) (*session, error) {
+	cookie, err := req.Cookie(g.cookie.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return nil, errInvalidSession
+	}
+
+	nonceSize := g.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errInvalidSession
+	}
+
+	plaintext, err := g.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(g.cookie.Name))
+	if err != nil {
+		return nil, errInvalidSession
+	}
+
+	sess := &session{}
+	if err := json.Unmarshal(plaintext, sess); err != nil {
+		return nil, errInvalidSession
+	}
+
+	now := g.now()
+	if now.Sub(time.Unix(sess.LastSeen, 0)) > g.idleTimeout {
+		return nil, errors.New("idle session expired")
+	}
+	if now.Sub(time.Unix(sess.Created, 0)) > g.absoluteTimeout {
+		return nil, errors.New("session expired")
+	}
+
+	return sess, nil
+}
+
+// setRequestCookies replaces the cookies of the request with the allowed client cookies and the cookies of the session,
+// the session cookies taking precedence.
+func (g *sessionGateway) setRequestCookies(req *http.Request, sess *session) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+
+	for _, cookie := range cookies {
+		if _, ok := g.allowedCookies[cookie.Name]; !ok || cookie.Name == g.cookie.Name {
+			continue
+		}
+		if _, ok := sess.cookies()[cookie.Name]; ok {
+			continue
+		}
+		req.AddCookie(cookie)
+	}
+
+	for name, value := range sess.cookies() {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+}
+
+// commit moves the cookies set by the backend into the session, and sets the session cookie of the response.
+func (g *sessionGateway) commit(header http.Header, sess *session, hasCookie bool) error {
+	setCookies := header[http.CanonicalHeaderKey("Set-Cookie")]
+	header.Del("Set-Cookie")
+
+	now := g.now()
+
+	if len(setCookies) > 0 {
+		if sess == nil {
+			sess = &session{Created: now.Unix()}
+		}
+		if sess.Cookies == nil {
+			sess.Cookies = make(map[string]string)
+		}
+
+		for _, cookie := range (&http.Response{Header: http.Header{"Set-Cookie": setCookies}}).Cookies() {
+			if cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && cookie.Expires.Before(now)) {
+				delete(sess.Cookies, cookie.Name)
+				continue
+			}
+			sess.Cookies[cookie.Name] = cookie.Value
+		}
+	}
+
+	if len(sess.cookies()) == 0 {
+		if hasCookie {
+			cookie := g.cookie
+			cookie.MaxAge = -1
+			http.SetCookie(&headerWriter{header: header}, &cookie)
+		}
+		return nil
+	}
+
+	sess.LastSeen = now.Unix()
+
+	value, err := g.seal(sess)
+	if err != nil {
+		return err
+	}
+
+	maxAge := g.idleTimeout
+	if remaining := time.Unix(sess.Created, 0).Add(g.absoluteTimeout).Sub(now); remaining < maxAge {
+		maxAge = remaining
+	}
+
+	cookie := g.cookie
+	cookie.Value = value
+	cookie.MaxAge = int(maxAge / time.Second)
+	if cookie.MaxAge <= 0 {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(&headerWriter{header: header}, &cookie)
+
+	return nil
+}
+
+// seal encrypts and signs the session.
+func (g *sessionGateway) seal(sess *session) (string, error) {
+	plaintext, err := json.Marshal(sess)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, g.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(g.aead.Seal(nonce, nonce, plaintext, []byte(g.cookie.Name)))
+	if len(value)+len(g.cookie.Name) > maxCookieSize {
+		return "", fmt.Errorf("the session cookie is too large (%d bytes)", len(value))
+	}
+
+	return value, nil
+}
+
+// cookies returns the cookies of the session, which can be nil.
+func (s *session) cookies() map[string]string {
+	if s == nil {
+		return nil
+	}
+	return s.Cookies
+}
+
+// headerWriter allows http.SetCookie to set a cookie in a header.
+type headerWriter struct {
+	http.ResponseWriter
+	header http.Header
+}
+
+func (w *headerWriter) Header() http.Header {
+	return w.header
+}
+
+// responseWriter updates the session when the response headers are written.
+type responseWriter struct {
+	http.ResponseWriter
+	gateway     *sessionGateway
+	session     *session
+	hasCookie   bool
+	req         *http.Request
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (r *responseWriter) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		if err := r.gateway.commit(r.ResponseWriter.Header(), r.session, r.hasCookie); err != nil {
+			middlewares.GetLogger(r.req.Context(), r.gateway.name, typeName).Errorf("Unable to set the session cookie: %v", err)
+		}
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseWriter) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseWriter) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+	r.hijacked = true
+	return hijacker.Hijack()
+}
+
+// CloseNotify returns a channel that receives at most a single value (true)
+// when the client connection has gone away.
+func (r *responseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := r.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(<-chan bool)
+}
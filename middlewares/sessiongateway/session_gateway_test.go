@@ -0,0 +1,161 @@
+package sessiongateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithoutSecret(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), config.SessionGateway{}, "session")
+	assert.Error(t, err)
+}
+
+// backend sets the cookies of the login query parameter, deletes the ones of the logout query parameter,
+// and echoes the cookies it receives.
+func backend() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if name := req.URL.Query().Get("login"); len(name) > 0 {
+			http.SetCookie(rw, &http.Cookie{Name: name, Value: "value-" + name, Path: "/", HttpOnly: true})
+		}
+		if name := req.URL.Query().Get("logout"); len(name) > 0 {
+			http.SetCookie(rw, &http.Cookie{Name: name, MaxAge: -1})
+		}
+		rw.Header().Set("X-Cookies", req.Header.Get("Cookie"))
+	})
+}
+
+func serve(handler http.Handler, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func sessionCookie(t *testing.T, recorder *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, defaultCookieName, cookies[0].Name)
+	return cookies[0]
+}
+
+func TestSessionGateway(t *testing.T) {
+	handler, err := New(context.Background(), backend(), config.SessionGateway{Secret: "secret", AllowedCookies: []string{"foo", "sid"}}, "session")
+	require.NoError(t, err)
+
+	// No session: the allowed client cookies are sent to the backend, and no cookie is set.
+	recorder := serve(handler, "/", &http.Cookie{Name: "foo", Value: "bar"}, &http.Cookie{Name: "injected", Value: "baz"})
+	assert.Equal(t, "foo=bar", recorder.Header().Get("X-Cookies"))
+	assert.Empty(t, recorder.Result().Cookies())
+
+	// The backend cookie is moved to the session cookie.
+	recorder = serve(handler, "/?login=sid")
+	cookie := sessionCookie(t, recorder)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, int(defaultIdleTimeout/time.Second), cookie.MaxAge)
+	assert.NotContains(t, cookie.Value, "value-sid")
+
+	// The session cookie is replaced with the backend cookies.
+	recorder = serve(handler, "/", cookie, &http.Cookie{Name: "foo", Value: "bar"}, &http.Cookie{Name: "injected", Value: "baz"})
+	assert.Equal(t, "foo=bar; sid=value-sid", recorder.Header().Get("X-Cookies"))
+	cookie = sessionCookie(t, recorder)
+
+	// The client can't override a backend cookie, even an allowed one.
+	recorder = serve(handler, "/", cookie, &http.Cookie{Name: "sid", Value: "forged"})
+	assert.Equal(t, "sid=value-sid", recorder.Header().Get("X-Cookies"))
+
+	// A tampered session cookie is dropped.
+	tampered := *cookie
+	first := "A"
+	if tampered.Value[0] == 'A' {
+		first = "B"
+	}
+	tampered.Value = first + tampered.Value[1:]
+	recorder = serve(handler, "/", &tampered)
+	assert.Empty(t, recorder.Header().Get("X-Cookies"))
+
+	// Deleting the last backend cookie deletes the session cookie.
+	recorder = serve(handler, "/?logout=sid", cookie)
+	cookie = sessionCookie(t, recorder)
+	assert.Equal(t, -1, cookie.MaxAge)
+}
+
+func TestSessionGatewayStripsClientCookies(t *testing.T) {
+	handler, err := New(context.Background(), backend(), config.SessionGateway{Secret: "secret"}, "session")
+	require.NoError(t, err)
+
+	// Without allowed cookies, the backend only gets the cookies of the session.
+	recorder := serve(handler, "/", &http.Cookie{Name: "foo", Value: "bar"})
+	assert.Empty(t, recorder.Header().Get("X-Cookies"))
+
+	cookie := sessionCookie(t, serve(handler, "/?login=sid"))
+
+	recorder = serve(handler, "/", cookie, &http.Cookie{Name: "admin", Value: "true"})
+	assert.Equal(t, "sid=value-sid", recorder.Header().Get("X-Cookies"))
+}
+
+func TestSessionGatewayExpiry(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		elapsed         []time.Duration
+		expectedCookies string
+	}{
+		{
+			desc:            "active session",
+			elapsed:         []time.Duration{20 * time.Minute, 20 * time.Minute},
+			expectedCookies: "sid=value-sid",
+		},
+		{
+			desc:    "idle session",
+			elapsed: []time.Duration{20 * time.Minute, 40 * time.Minute},
+		},
+		{
+			desc:    "session past its absolute timeout",
+			elapsed: []time.Duration{20 * time.Minute, 20 * time.Minute, 20 * time.Minute, 20 * time.Minute},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			gatewayConfig := config.SessionGateway{
+				Secret:          "secret",
+				IdleTimeout:     parse.Duration(30 * time.Minute),
+				AbsoluteTimeout: parse.Duration(time.Hour),
+			}
+			handler, err := New(context.Background(), backend(), gatewayConfig, "session")
+			require.NoError(t, err)
+
+			now := time.Now()
+			handler.(*sessionGateway).now = func() time.Time { return now }
+
+			cookie := sessionCookie(t, serve(handler, "/?login=sid"))
+
+			var recorder *httptest.ResponseRecorder
+			for _, elapsed := range test.elapsed {
+				now = now.Add(elapsed)
+				recorder = serve(handler, "/", cookie)
+				if cookies := recorder.Result().Cookies(); len(cookies) > 0 {
+					cookie = cookies[0]
+				}
+			}
+
+			assert.Equal(t, test.expectedCookies, recorder.Header().Get("X-Cookies"))
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/audit"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/containous/traefik/tracing"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "Tenant"
+
+	// SourceHeader reads the tenant identifier from a request header.
+	SourceHeader = "header"
+	// SourceSubdomain reads the tenant identifier from the subdomain of the request host.
+	SourceSubdomain = "subdomain"
+	// SourceClaim reads the tenant identifier from a claim of the bearer JWT of the request, once its signature is verified.
+	SourceClaim = "claim"
+
+	defaultHeaderName = "X-Tenant-Id"
+	defaultClaim      = "tenant"
+
+	anyTenant = "*"
+)
+
+var (
+	errMissingTenant = errors.New("missing tenant identifier")
+	errMissingToken  = errors.New("missing bearer token")
+)
+
+// tenant is a middleware rejecting the requests of the tenants which are not allowed on the route,
+// and forcing the tenant header of the allowed requests, so that a server shared by the tenants can trust it.
+type tenant struct {
+	next       http.Handler
+	name       string
+	source     string
+	headerName string
+	domain     string
+	claim      string
+	parser     *jwt.Parser
+	key        interface{}
+	allowed    map[string]bool
+}
+
+// New creates a new tenant isolation middleware.
+func New(ctx context.Context, next http.Handler, config config.Tenant, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	t := &tenant{
+		next:       next,
+		name:       name,
+		source:     config.Source,
+		headerName: config.HeaderName,
+		domain:     strings.ToLower(strings.Trim(config.Domain, ".")),
+		claim:      config.Claim,
+		allowed:    make(map[string]bool),
+	}
+
+	if len(t.headerName) == 0 {
+		t.headerName = defaultHeaderName
+	}
+
+	switch t.source {
+	case "":
+		t.source = SourceHeader
+	case SourceHeader:
+	case SourceSubdomain:
+		if len(t.domain) == 0 {
+			return nil, errors.New("a domain is required by the subdomain source")
+		}
+	case SourceClaim:
+		if len(t.claim) == 0 {
+			t.claim = defaultClaim
+		}
+		if err := t.setVerificationKey(config.Secret, config.PublicKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown tenant source %q", t.source)
+	}
+
+	if len(config.Allowed) == 0 {
+		return nil, errors.New("no allowed tenant defined")
+	}
+	for _, allowed := range config.Allowed {
+		if t.source == SourceSubdomain {
+			allowed = strings.ToLower(allowed)
+		}
+		t.allowed[allowed] = true
+	}
+
+	return t, nil
+}
+
+// setVerificationKey sets the key verifying the signature of the JWTs, and the signing methods it verifies.
+func (t *tenant) setVerificationKey(secret, publicKey string) error {
+	switch {
+	case len(secret) > 0 && len(publicKey) > 0:
+		return errors.New("either a secret or a public key is required by the claim source, not both")
+	case len(secret) > 0:
+		t.key = []byte(secret)
+		t.parser = &jwt.Parser{ValidMethods: []string{"HS256", "HS384", "HS512"}}
+	case len(publicKey) > 0:
+		content, err := traefiktls.FileOrContent(publicKey).Read()
+		if err != nil {
+			return fmt.Errorf("unable to read the public key: %v", err)
+		}
+
+		if key, err := jwt.ParseRSAPublicKeyFromPEM(content); err == nil {
+			t.key = key
+			t.parser = &jwt.Parser{ValidMethods: []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}}
+			return nil
+		}
+		if key, err := jwt.ParseECPublicKeyFromPEM(content); err == nil {
+			t.key = key
+			t.parser = &jwt.Parser{ValidMethods: []string{"ES256", "ES384", "ES512"}}
+			return nil
+		}
+		return errors.New("unable to parse the public key, expected a PEM encoded RSA or ECDSA public key")
+	default:
+		return errors.New("a secret or a public key is required by the claim source")
+	}
+	return nil
+}
+
+func (t *tenant) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return t.name, tracing.SpanKindNoneEnum
+}
+
+func (t *tenant) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	id, err := t.identify(req)
+	if err == nil && !t.allowed[id] && !t.allowed[anyTenant] {
+		err = fmt.Errorf("tenant %q not allowed", id)
+	}
+
+	if err != nil {
+		middlewares.GetLogger(req.Context(), t.name, typeName).Debugf("Request rejected: %v", err)
+		audit.RecordRequest(req, audit.CategoryAuthorization, "tenant.denied", id, t.name)
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	// Replaces any tenant header sent by the client, including its duplicates.
+	req.Header.Set(t.headerName, id)
+
+	t.next.ServeHTTP(rw, req)
+}
+
+// identify returns the tenant identifier of the request.
+func (t *tenant) identify(req *http.Request) (string, error) {
+	var id string
+	switch t.source {
+	case SourceSubdomain:
+		id = t.subdomain(req.Host)
+	case SourceClaim:
+		var err error
+		id, err = t.claimValue(req)
+		if err != nil {
+			return "", err
+		}
+	default:
+		values := req.Header[http.CanonicalHeaderKey(t.headerName)]
+		if len(values) > 1 {
+			return "", fmt.Errorf("ambiguous tenant identifier, %d %s headers", len(values), t.headerName)
+		}
+		if len(values) == 1 {
+			id = strings.TrimSpace(values[0])
+		}
+	}
+
+	if len(id) == 0 {
+		return "", errMissingTenant
+	}
+	return id, nil
+}
+
+// subdomain returns the label of the host right below the domain, empty when the host is not a direct subdomain of the domain.
+func (t *tenant) subdomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	label := strings.TrimSuffix(host, "."+t.domain)
+	if label == host || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+// claimValue returns the tenant claim of the bearer JWT of the request, once its signature is verified.
+func (t *tenant) claimValue(req *http.Request) (string, error) {
+	authorization := req.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return "", errMissingToken
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := t.parser.ParseWithClaims(strings.TrimSpace(authorization[7:]), claims, func(token *jwt.Token) (interface{}, error) {
+		return t.key, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid bearer token: %v", err)
+	}
+
+	value, ok := claims[t.claim]
+	if !ok {
+		return "", errMissingTenant
+	}
+	id, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid tenant claim %v", value)
+	}
+	return id, nil
+}
@@ -0,0 +1,228 @@
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config config.Tenant
+	}{
+		{
+			desc:   "no allowed tenant",
+			config: config.Tenant{},
+		},
+		{
+			desc:   "unknown source",
+			config: config.Tenant{Source: "query", Allowed: []string{"acme"}},
+		},
+		{
+			desc:   "subdomain source without domain",
+			config: config.Tenant{Source: SourceSubdomain, Allowed: []string{"acme"}},
+		},
+		{
+			desc:   "claim source without key",
+			config: config.Tenant{Source: SourceClaim, Allowed: []string{"acme"}},
+		},
+		{
+			desc:   "claim source with both a secret and a public key",
+			config: config.Tenant{Source: SourceClaim, Secret: "secret", PublicKey: "key", Allowed: []string{"acme"}},
+		},
+		{
+			desc:   "claim source with an invalid public key",
+			config: config.Tenant{Source: SourceClaim, PublicKey: "not a key", Allowed: []string{"acme"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "tenant")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTenant(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	publicKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+
+	testCases := []struct {
+		desc           string
+		config         config.Tenant
+		host           string
+		headers        map[string][]string
+		expectedStatus int
+		expectedTenant string
+	}{
+		{
+			desc:           "allowed header",
+			config:         config.Tenant{Allowed: []string{"acme"}},
+			headers:        map[string][]string{"X-Tenant-Id": {"acme"}},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "acme",
+		},
+		{
+			desc:           "not allowed header",
+			config:         config.Tenant{Allowed: []string{"acme"}},
+			headers:        map[string][]string{"X-Tenant-Id": {"globex"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "missing header",
+			config:         config.Tenant{Allowed: []string{anyTenant}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "duplicate headers",
+			config:         config.Tenant{Allowed: []string{"acme", "globex"}},
+			headers:        map[string][]string{"X-Tenant-Id": {"acme", "globex"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "any tenant allowed",
+			config:         config.Tenant{HeaderName: "X-Org", Allowed: []string{anyTenant}},
+			headers:        map[string][]string{"X-Org": {"globex"}},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "globex",
+		},
+		{
+			desc:           "subdomain forcing the header",
+			config:         config.Tenant{Source: SourceSubdomain, Domain: "example.com", Allowed: []string{"acme"}},
+			host:           "ACME.example.com:8080",
+			headers:        map[string][]string{"X-Tenant-Id": {"globex"}},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "acme",
+		},
+		{
+			desc:           "nested subdomain",
+			config:         config.Tenant{Source: SourceSubdomain, Domain: "example.com", Allowed: []string{anyTenant}},
+			host:           "www.acme.example.com",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "other domain",
+			config:         config.Tenant{Source: SourceSubdomain, Domain: "example.com", Allowed: []string{anyTenant}},
+			host:           "acme.example.org",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:   "HMAC signed claim",
+			config: config.Tenant{Source: SourceClaim, Secret: "secret", Allowed: []string{"acme"}},
+			headers: map[string][]string{
+				"Authorization": {sign(jwt.SigningMethodHS256, []byte("secret"), jwt.MapClaims{"tenant": "acme"})},
+				"X-Tenant-Id":   {"globex"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "acme",
+		},
+		{
+			desc:   "RSA signed custom claim",
+			config: config.Tenant{Source: SourceClaim, Claim: "org", PublicKey: publicKeyPEM, Allowed: []string{"acme"}},
+			headers: map[string][]string{
+				"Authorization": {sign(jwt.SigningMethodRS256, privateKey, jwt.MapClaims{"org": "acme"})},
+			},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "acme",
+		},
+		{
+			desc:   "claim signed with another secret",
+			config: config.Tenant{Source: SourceClaim, Secret: "secret", Allowed: []string{"acme"}},
+			headers: map[string][]string{
+				"Authorization": {sign(jwt.SigningMethodHS256, []byte("other"), jwt.MapClaims{"tenant": "acme"})},
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:   "claim of an expired token",
+			config: config.Tenant{Source: SourceClaim, Secret: "secret", Allowed: []string{"acme"}},
+			headers: map[string][]string{
+				"Authorization": {sign(jwt.SigningMethodHS256, []byte("secret"), jwt.MapClaims{"tenant": "acme", "exp": time.Now().Add(-time.Hour).Unix()})},
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:   "claim of an unsigned token",
+			config: config.Tenant{Source: SourceClaim, Secret: "secret", Allowed: []string{"acme"}},
+			headers: map[string][]string{
+				"Authorization": {sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"tenant": "acme"})},
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:   "non string claim",
+			config: config.Tenant{Source: SourceClaim, Secret: "secret", Allowed: []string{anyTenant}},
+			headers: map[string][]string{
+				"Authorization": {sign(jwt.SigningMethodHS256, []byte("secret"), jwt.MapClaims{"tenant": 42})},
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "missing token",
+			config:         config.Tenant{Source: SourceClaim, Secret: "secret", Allowed: []string{"acme"}},
+			headers:        map[string][]string{"X-Tenant-Id": {"acme"}},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			headerName := test.config.HeaderName
+			if len(headerName) == 0 {
+				headerName = defaultHeaderName
+			}
+
+			var tenantHeader []string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				tenantHeader = req.Header[http.CanonicalHeaderKey(headerName)]
+			})
+
+			handler, err := New(context.Background(), next, test.config, "tenant")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			if len(test.host) > 0 {
+				req.Host = test.host
+			}
+			for name, values := range test.headers {
+				req.Header[name] = values
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, []string{test.expectedTenant}, tenantHeader)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package timewindow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "TimeWindow"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// timeWindow is a middleware allowing the requests during its time windows only, or denying them during its time windows.
+type timeWindow struct {
+	next       http.Handler
+	name       string
+	windows    []window
+	location   *time.Location
+	deny       bool
+	statusCode int
+	message    string
+	now        func() time.Time
+}
+
+// New creates a new time window middleware.
+func New(ctx context.Context, next http.Handler, config config.TimeWindow, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	if len(config.Windows) == 0 {
+		return nil, fmt.Errorf("no time window defined")
+	}
+
+	t := &timeWindow{
+		next:       next,
+		name:       name,
+		location:   time.UTC,
+		deny:       config.Deny,
+		statusCode: http.StatusForbidden,
+		message:    config.Message,
+		now:        time.Now,
+	}
+
+	for _, value := range config.Windows {
+		w, err := parseWindow(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time window %q: %v", value, err)
+		}
+		t.windows = append(t.windows, w)
+	}
+
+	if len(config.TimeZone) > 0 {
+		location, err := time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %v", config.TimeZone, err)
+		}
+		t.location = location
+	}
+
+	if config.StatusCode != 0 {
+		if config.StatusCode < 100 || config.StatusCode > 599 {
+			return nil, fmt.Errorf("invalid status code: %d", config.StatusCode)
+		}
+		t.statusCode = config.StatusCode
+	}
+
+	if len(t.message) == 0 {
+		t.message = http.StatusText(t.statusCode)
+	}
+
+	return t, nil
+}
+
+func (t *timeWindow) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return t.name, tracing.SpanKindNoneEnum
+}
+
+func (t *timeWindow) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if t.allowed(t.now().In(t.location)) {
+		t.next.ServeHTTP(rw, req)
+		return
+	}
+
+	middlewares.GetLogger(req.Context(), t.name, typeName).Debug("Denying the request outside of the allowed time windows")
+	tracing.SetErrorWithEvent(req, "denied by the time windows")
+	http.Error(rw, t.message, t.statusCode)
+}
+
+func (t *timeWindow) allowed(now time.Time) bool {
+	for _, w := range t.windows {
+		if w.contains(now) {
+			return !t.deny
+		}
+	}
+	return t.deny
+}
+
+// window is a time range, in minutes since midnight, on some days of the week.
+type window struct {
+	days  [7]bool
+	start int
+	end   int
+}
+
+// parseWindow parses a time window, like "Mon-Fri 06:00-22:00", "Sat,Sun 08:00-12:00", or "22:00-06:00" for every day.
+func parseWindow(value string) (window, error) {
+	fields := strings.Fields(value)
+
+	var w window
+	var timeRange string
+	switch len(fields) {
+	case 1:
+		for day := range w.days {
+			w.days[day] = true
+		}
+		timeRange = fields[0]
+	case 2:
+		if err := w.parseDays(fields[0]); err != nil {
+			return window{}, err
+		}
+		timeRange = fields[1]
+	default:
+		return window{}, fmt.Errorf("expected optional days and a time range, like Mon-Fri 06:00-22:00")
+	}
+
+	bounds := strings.Split(timeRange, "-")
+	if len(bounds) != 2 {
+		return window{}, fmt.Errorf("invalid time range %q, expected a range like 06:00-22:00", timeRange)
+	}
+
+	var err error
+	if w.start, err = parseMinutes(bounds[0]); err != nil {
+		return window{}, err
+	}
+	if w.end, err = parseMinutes(bounds[1]); err != nil {
+		return window{}, err
+	}
+	if w.start == w.end || w.start == 24*60 {
+		return window{}, fmt.Errorf("empty time range %q", timeRange)
+	}
+
+	return w, nil
+}
+
+// parseDays parses days like "Mon", "Mon-Fri", or "Mon,Wed,Fri".
+func (w *window) parseDays(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		bounds := strings.Split(part, "-")
+		if len(bounds) > 2 {
+			return fmt.Errorf("invalid days %q", part)
+		}
+
+		first, ok := weekdays[strings.ToLower(bounds[0])]
+		if !ok {
+			return fmt.Errorf("invalid day %q", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdays[strings.ToLower(bounds[1])]; !ok {
+				return fmt.Errorf("invalid day %q", bounds[1])
+			}
+		}
+
+		// A range like Sat-Mon wraps around the end of the week.
+		for day := first; ; day = (day + 1) % 7 {
+			w.days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseMinutes parses a time of the day, like 06:30, into minutes since midnight. 24:00 is the end of the day.
+func parseMinutes(value string) (int, error) {
+	var hours, minutes int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hours, &minutes); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected a time like 06:00", value)
+	}
+
+	if hours < 0 || minutes < 0 || minutes > 59 || hours > 24 || (hours == 24 && minutes > 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return hours*60 + minutes, nil
+}
+
+// contains returns true if the time is in the time window.
+// A time range spanning midnight belongs to the day it starts.
+func (w window) contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if w.start < w.end {
+		return w.days[day] && minutes >= w.start && minutes < w.end
+	}
+
+	if minutes >= w.start {
+		return w.days[day]
+	}
+	return minutes < w.end && w.days[(day+6)%7]
+}
@@ -0,0 +1,155 @@
+package timewindow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfiguration(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config config.TimeWindow
+	}{
+		{
+			desc:   "no time window",
+			config: config.TimeWindow{},
+		},
+		{
+			desc:   "invalid day",
+			config: config.TimeWindow{Windows: []string{"Monday 06:00-22:00"}},
+		},
+		{
+			desc:   "invalid time",
+			config: config.TimeWindow{Windows: []string{"Mon 6:00-22:00"}},
+		},
+		{
+			desc:   "out of range time",
+			config: config.TimeWindow{Windows: []string{"Mon 06:00-24:30"}},
+		},
+		{
+			desc:   "empty time range",
+			config: config.TimeWindow{Windows: []string{"Mon 06:00-06:00"}},
+		},
+		{
+			desc:   "missing time range",
+			config: config.TimeWindow{Windows: []string{"Mon-Fri"}},
+		},
+		{
+			desc:   "invalid time zone",
+			config: config.TimeWindow{Windows: []string{"06:00-22:00"}, TimeZone: "Europe/Nowhere"},
+		},
+		{
+			desc:   "invalid status code",
+			config: config.TimeWindow{Windows: []string{"06:00-22:00"}, StatusCode: 42},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "window")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	// 2026-10-12 is a Monday.
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 12, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		desc               string
+		config             config.TimeWindow
+		now                time.Time
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc:               "in the time window",
+			config:             config.TimeWindow{Windows: []string{"Mon-Fri 06:00-22:00"}},
+			now:                monday(6, 0),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "at the end of the time window",
+			config:             config.TimeWindow{Windows: []string{"Mon-Fri 06:00-22:00"}},
+			now:                monday(22, 0),
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       "Forbidden\n",
+		},
+		{
+			desc:               "another day",
+			config:             config.TimeWindow{Windows: []string{"Sat,Sun 08:00-12:00", "Tue-Fri 06:00-22:00"}},
+			now:                monday(10, 0),
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       "Forbidden\n",
+		},
+		{
+			desc:               "days wrapping around the end of the week",
+			config:             config.TimeWindow{Windows: []string{"Sat-Mon 08:00-12:00"}},
+			now:                monday(10, 0),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "time range spanning midnight, the day after",
+			config:             config.TimeWindow{Windows: []string{"Sun 22:00-06:00"}},
+			now:                monday(5, 59),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "time range spanning midnight, on another day",
+			config:             config.TimeWindow{Windows: []string{"Mon 22:00-06:00"}},
+			now:                monday(5, 59),
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       "Forbidden\n",
+		},
+		{
+			desc:               "time zone",
+			config:             config.TimeWindow{Windows: []string{"06:00-22:00"}, TimeZone: "Asia/Tokyo"},
+			now:                monday(22, 30),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "denied during the time window, with a custom response",
+			config:             config.TimeWindow{Windows: []string{"Mon 00:00-24:00"}, Deny: true, StatusCode: http.StatusServiceUnavailable, Message: "Closed for maintenance"},
+			now:                monday(23, 59),
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       "Closed for maintenance\n",
+		},
+		{
+			desc:               "allowed outside the denied time window",
+			config:             config.TimeWindow{Windows: []string{"Tue 00:00-24:00"}, Deny: true},
+			now:                monday(12, 0),
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+			handler, err := New(context.Background(), next, test.config, "window")
+			require.NoError(t, err)
+			handler.(*timeWindow).now = func() time.Time { return test.now }
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -41,6 +41,10 @@ func (e *entryPointMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Reque
 	ext.Component.Set(span, e.ServiceName)
 	tracing.LogRequest(span, req)
 
+	if e.IsDebugRequest(req) {
+		tracing.SetSampled(span, true)
+	}
+
 	req = req.WithContext(tracing.WithTracing(req.Context(), e.Tracing))
 
 	recorder := newStatusCodeRecoder(rw, http.StatusOK)
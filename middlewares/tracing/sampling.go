@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+
+	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/tracing"
+)
+
+const (
+	samplingTypeName = "TracingSampling"
+)
+
+type samplingMiddleware struct {
+	sampleRate float64
+	next       http.Handler
+}
+
+// NewSampling creates a middleware sampling the traces of the requests of a router at the sample rate of the router,
+// instead of the sampler of the tracing backend.
+// The requests sampled through the debug header are always sampled.
+func NewSampling(ctx context.Context, sampleRate float64, next http.Handler) http.Handler {
+	middlewares.GetLogger(ctx, "tracing", samplingTypeName).Debugf("Sampling the traces at the rate %v", sampleRate)
+
+	return &samplingMiddleware{
+		sampleRate: sampleRate,
+		next:       next,
+	}
+}
+
+func (s *samplingMiddleware) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	tr, err := tracing.FromContext(req.Context())
+	if err == nil && !tr.IsDebugRequest(req) {
+		tracing.SetSampled(tracing.GetSpan(req), rand.Float64() < s.sampleRate)
+	}
+
+	s.next.ServeHTTP(rw, req)
+}
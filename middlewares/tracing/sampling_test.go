@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplingMiddleware(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		sampleRate       float64
+		header           string
+		remoteAddr       string
+		expectedPriority interface{}
+	}{
+		{
+			desc:             "always sampled",
+			sampleRate:       1,
+			remoteAddr:       "10.0.0.1:1234",
+			expectedPriority: uint16(1),
+		},
+		{
+			desc:             "never sampled",
+			sampleRate:       0,
+			remoteAddr:       "10.0.0.1:1234",
+			expectedPriority: uint16(0),
+		},
+		{
+			desc:       "debug header from a trusted IP",
+			sampleRate: 0,
+			header:     "1",
+			remoteAddr: "10.0.0.1:1234",
+		},
+		{
+			desc:             "debug header from an untrusted IP",
+			sampleRate:       0,
+			header:           "1",
+			remoteAddr:       "192.168.0.1:1234",
+			expectedPriority: uint16(0),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			span := &MockSpan{Tags: make(map[string]interface{})}
+			tr, err := tracing.NewTracing("", 0, &trackingBackenMock{tracer: &MockTracer{Span: span}})
+			require.NoError(t, err)
+			require.NoError(t, tr.SetDebugHeader("X-Trace-Debug", []string{"10.0.0.0/8"}))
+
+			req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
+			req.RemoteAddr = test.remoteAddr
+			if len(test.header) > 0 {
+				req.Header.Set("X-Trace-Debug", test.header)
+			}
+			ctx := tracing.WithTracing(opentracing.ContextWithSpan(req.Context(), span), tr)
+
+			handler := NewSampling(context.Background(), test.sampleRate, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+			assert.Equal(t, test.expectedPriority, span.Tags[string(ext.SamplingPriority)])
+		})
+	}
+}
+
+func TestEntryPointMiddlewareDebugHeader(t *testing.T) {
+	span := &MockSpan{Tags: make(map[string]interface{})}
+	tr, err := tracing.NewTracing("", 0, &trackingBackenMock{tracer: &MockTracer{Span: span}})
+	require.NoError(t, err)
+	require.NoError(t, tr.SetDebugHeader("X-Trace-Debug", []string{"192.0.2.1"}))
+
+	req := httptest.NewRequest(http.MethodGet, "http://www.test.com", nil)
+	req.Header.Set("X-Trace-Debug", "true")
+
+	handler := NewEntryPoint(context.Background(), tr, "test", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, uint16(1), span.Tags[string(ext.SamplingPriority)])
+}
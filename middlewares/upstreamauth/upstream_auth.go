@@ -0,0 +1,272 @@
+package upstreamauth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	typeName = "UpstreamAuth"
+
+	defaultMaxBodySize = 1024 * 1024
+)
+
+// errBodyTooLarge is returned when the body of a request exceeds the size an authenticator can buffer.
+var errBodyTooLarge = errors.New("body too large")
+
+// authenticator attaches credentials to a request.
+type authenticator interface {
+	authenticate(req *http.Request) error
+}
+
+// upstreamAuth is a middleware attaching credentials to the requests sent to the servers,
+// so that the servers requiring an authentication can be reached by clients without their secrets.
+type upstreamAuth struct {
+	next          http.Handler
+	name          string
+	authenticator authenticator
+}
+
+// New creates a new upstream authentication middleware.
+func New(ctx context.Context, next http.Handler, config config.UpstreamAuth, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug("Creating middleware")
+
+	var authenticators []authenticator
+	if len(config.Bearer) > 0 {
+		auth, err := newBearer(config.Bearer)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, auth)
+	}
+	if config.OAuth2 != nil {
+		auth, err := newOAuth2(*config.OAuth2)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, auth)
+	}
+	if config.AWS != nil {
+		auth, err := newAWS(*config.AWS)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, auth)
+	}
+
+	if len(authenticators) != 1 {
+		return nil, errors.New("exactly one of a bearer token, an OAuth2 or an AWS configuration is required")
+	}
+
+	return &upstreamAuth{
+		next:          next,
+		name:          name,
+		authenticator: authenticators[0],
+	}, nil
+}
+
+func (u *upstreamAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return u.name, tracing.SpanKindNoneEnum
+}
+
+func (u *upstreamAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	err := u.authenticator.authenticate(req)
+	if err == errBodyTooLarge {
+		middlewares.GetLogger(req.Context(), u.name, typeName).Debugf("Unable to authenticate the request to the servers: %v", err)
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		middlewares.GetLogger(req.Context(), u.name, typeName).Errorf("Unable to authenticate the request to the servers: %v", err)
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	u.next.ServeHTTP(rw, req)
+}
+
+// bearer attaches a static bearer token.
+type bearer struct {
+	authorization string
+}
+
+func newBearer(token string) (*bearer, error) {
+	content, err := traefiktls.FileOrContent(token).Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the bearer token: %v", err)
+	}
+
+	value := strings.TrimSpace(string(content))
+	if len(value) == 0 {
+		return nil, errors.New("empty bearer token")
+	}
+	return &bearer{authorization: "Bearer " + value}, nil
+}
+
+func (b *bearer) authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", b.authorization)
+	return nil
+}
+
+// oauth2Client attaches the OAuth2 token obtained with the client credentials grant, fetching a new one when it expires.
+type oauth2Client struct {
+	tokenSource oauth2.TokenSource
+}
+
+func newOAuth2(conf config.UpstreamAuthOAuth2) (*oauth2Client, error) {
+	if len(conf.TokenURL) == 0 {
+		return nil, errors.New("a token URL is required by the OAuth2 client credentials grant")
+	}
+	if len(conf.ClientID) == 0 {
+		return nil, errors.New("a client ID is required by the OAuth2 client credentials grant")
+	}
+
+	params := url.Values{}
+	for name, value := range conf.EndpointParams {
+		params.Set(name, value)
+	}
+
+	credentialsConfig := &clientcredentials.Config{
+		ClientID:       conf.ClientID,
+		ClientSecret:   conf.ClientSecret,
+		TokenURL:       conf.TokenURL,
+		Scopes:         conf.Scopes,
+		EndpointParams: params,
+	}
+
+	// The token source outlives the context of the creation of the middleware.
+	return &oauth2Client{tokenSource: credentialsConfig.TokenSource(context.Background())}, nil
+}
+
+func (o *oauth2Client) authenticate(req *http.Request) error {
+	token, err := o.tokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("unable to get an OAuth2 token: %v", err)
+	}
+
+	token.SetAuthHeader(req)
+	return nil
+}
+
+// awsSigner signs the requests with the AWS Signature Version 4.
+type awsSigner struct {
+	signer      *v4.Signer
+	region      string
+	service     string
+	host        string
+	maxBodySize int64
+	now         func() time.Time
+}
+
+func newAWS(conf config.UpstreamAuthAWS) (*awsSigner, error) {
+	if len(conf.Region) == 0 {
+		return nil, errors.New("a region is required by the AWS signing")
+	}
+	if len(conf.Service) == 0 {
+		return nil, errors.New("a service is required by the AWS signing")
+	}
+	if conf.MaxBodySize < 0 {
+		return nil, fmt.Errorf("invalid maximum body size %d", conf.MaxBodySize)
+	}
+
+	maxBodySize := conf.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+
+	var creds *credentials.Credentials
+	switch {
+	case len(conf.AccessKeyID) > 0:
+		if len(conf.SecretAccessKey) == 0 {
+			return nil, errors.New("a secret access key is required along with the access key ID")
+		}
+		creds = credentials.NewStaticCredentials(conf.AccessKeyID, conf.SecretAccessKey, conf.SessionToken)
+	case len(conf.SecretAccessKey) > 0:
+		return nil, errors.New("an access key ID is required along with the secret access key")
+	default:
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvProvider{},
+			&credentials.SharedCredentialsProvider{},
+		})
+	}
+
+	return &awsSigner{
+		signer:      v4.NewSigner(creds),
+		region:      conf.Region,
+		service:     conf.Service,
+		host:        conf.Host,
+		maxBodySize: maxBodySize,
+		now:         time.Now,
+	}, nil
+}
+
+// authenticate signs the request, buffering its body to hash it.
+// Only the host, the content type and the x-amz-* headers are signed:
+// the other headers, like the hop-by-hop and the X-Forwarded-* ones, can be changed before the request reaches the servers.
+func (a *awsSigner) authenticate(req *http.Request) error {
+	if len(a.host) > 0 {
+		req.Host = a.host
+	}
+
+	var body io.ReadSeeker
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.ContentLength > a.maxBodySize {
+			return errBodyTooLarge
+		}
+
+		content, err := ioutil.ReadAll(io.LimitReader(req.Body, a.maxBodySize+1))
+		if err != nil {
+			return fmt.Errorf("unable to read the body: %v", err)
+		}
+		if int64(len(content)) > a.maxBodySize {
+			return errBodyTooLarge
+		}
+		_ = req.Body.Close()
+		body = bytes.NewReader(content)
+		req.ContentLength = int64(len(content))
+	}
+
+	req.Header.Del("Authorization")
+
+	unsignedHeaders := make(http.Header)
+	for name, values := range req.Header {
+		if !isSignedHeader(name) {
+			unsignedHeaders[name] = values
+			delete(req.Header, name)
+		}
+	}
+	defer func() {
+		for name, values := range unsignedHeaders {
+			req.Header[name] = values
+		}
+	}()
+
+	if _, err := a.signer.Sign(req, body, a.service, a.region, a.now()); err != nil {
+		return fmt.Errorf("unable to sign the request: %v", err)
+	}
+	return nil
+}
+
+// isSignedHeader reports whether a header is part of the AWS signature.
+func isSignedHeader(name string) bool {
+	name = strings.ToLower(name)
+	return name == "host" || name == "content-type" || strings.HasPrefix(name, "x-amz-")
+}
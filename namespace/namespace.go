@@ -0,0 +1,234 @@
+package namespace
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/containous/traefik/config"
+)
+
+// ErrNamespaceNotFound is returned when looking up an unknown namespace.
+var ErrNamespaceNotFound = errors.New("namespace not found")
+
+// Namespace holds the providers of a team, and the limits of their configurations.
+// The limits which are not set are not checked.
+type Namespace struct {
+	Providers       []string `description:"Names of the providers of the namespace" export:"true"`
+	MaxRouters      int      `description:"Maximum number of routers of the providers of the namespace" export:"true"`
+	MaxMiddlewares  int      `description:"Maximum number of middlewares of the providers of the namespace" export:"true"`
+	MaxCertificates int      `description:"Maximum number of certificates of the providers of the namespace" export:"true"`
+	Tokens          []string `description:"Tokens granting access to the namespace through the API"`
+}
+
+// Usage is the number of elements defined by the providers of a namespace, along with the limits of the namespace.
+type Usage struct {
+	Providers       []string `json:"providers"`
+	Routers         int      `json:"routers"`
+	MaxRouters      int      `json:"maxRouters,omitempty"`
+	Middlewares     int      `json:"middlewares"`
+	MaxMiddlewares  int      `json:"maxMiddlewares,omitempty"`
+	Certificates    int      `json:"certificates"`
+	MaxCertificates int      `json:"maxCertificates,omitempty"`
+}
+
+// Registry keeps the namespaces of the providers.
+// The providers which are not part of a namespace share the default, unlimited, namespace.
+// A nil Registry checks nothing.
+type Registry struct {
+	namespaces map[string]*Namespace
+	providers  map[string]string
+}
+
+// New creates a Registry of the namespaces, checking that a provider is part of a single namespace.
+func New(namespaces map[string]*Namespace) (*Registry, error) {
+	if len(namespaces) == 0 {
+		return nil, nil
+	}
+
+	r := &Registry{
+		namespaces: namespaces,
+		providers:  make(map[string]string),
+	}
+
+	for name, namespace := range namespaces {
+		if len(name) == 0 {
+			return nil, errors.New("empty namespace name")
+		}
+
+		for _, providerName := range namespace.Providers {
+			if other, ok := r.providers[providerName]; ok {
+				return nil, fmt.Errorf("the provider %s is part of the namespaces %s and %s", providerName, other, name)
+			}
+			r.providers[providerName] = name
+		}
+	}
+
+	return r, nil
+}
+
+// NamespaceOf returns the namespace of the provider, empty for the default namespace.
+func (r *Registry) NamespaceOf(providerName string) string {
+	if r == nil {
+		return ""
+	}
+	return r.providers[providerName]
+}
+
+// Check checks that the configuration of the provider, among the configurations, doesn't exceed the limits of its namespace,
+// and doesn't define routers, middlewares or services, HTTP or TCP, already defined by the providers of another namespace.
+func (r *Registry) Check(providerName string, configurations config.Configurations) error {
+	if r == nil {
+		return nil
+	}
+
+	conf, ok := configurations[providerName]
+	if !ok || conf == nil {
+		return nil
+	}
+
+	name := r.NamespaceOf(providerName)
+
+	for otherName, other := range configurations {
+		if other == nil || r.NamespaceOf(otherName) == name {
+			continue
+		}
+
+		if err := checkOverride(conf, other); err != nil {
+			return fmt.Errorf("%v, by the provider %s of another namespace", err, otherName)
+		}
+	}
+
+	namespace, ok := r.namespaces[name]
+	if !ok {
+		return nil
+	}
+
+	usage := r.usage(name, namespace, configurations)
+	if namespace.MaxRouters > 0 && usage.Routers > namespace.MaxRouters {
+		return fmt.Errorf("the namespace %s has %d routers, more than its limit of %d", name, usage.Routers, namespace.MaxRouters)
+	}
+	if namespace.MaxMiddlewares > 0 && usage.Middlewares > namespace.MaxMiddlewares {
+		return fmt.Errorf("the namespace %s has %d middlewares, more than its limit of %d", name, usage.Middlewares, namespace.MaxMiddlewares)
+	}
+	if namespace.MaxCertificates > 0 && usage.Certificates > namespace.MaxCertificates {
+		return fmt.Errorf("the namespace %s has %d certificates, more than its limit of %d", name, usage.Certificates, namespace.MaxCertificates)
+	}
+
+	return nil
+}
+
+// Usage returns the usage of the namespace by the configurations.
+func (r *Registry) Usage(name string, configurations config.Configurations) (Usage, error) {
+	if r == nil {
+		return Usage{}, ErrNamespaceNotFound
+	}
+
+	namespace, ok := r.namespaces[name]
+	if !ok {
+		return Usage{}, ErrNamespaceNotFound
+	}
+
+	return r.usage(name, namespace, configurations), nil
+}
+
+// Names returns the sorted names of the namespaces.
+func (r *Registry) Names() []string {
+	if r == nil {
+		return nil
+	}
+
+	var names []string
+	for name := range r.namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Authorize tells whether the token grants access to the namespace.
+func (r *Registry) Authorize(name, token string) bool {
+	if r == nil || len(token) == 0 {
+		return false
+	}
+
+	namespace, ok := r.namespaces[name]
+	if !ok {
+		return false
+	}
+
+	for _, t := range namespace.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Configurations returns the configurations of the providers of the namespace.
+func (r *Registry) Configurations(name string, configurations config.Configurations) config.Configurations {
+	result := make(config.Configurations)
+	for providerName, conf := range configurations {
+		if r.NamespaceOf(providerName) == name {
+			result[providerName] = conf
+		}
+	}
+	return result
+}
+
+func (r *Registry) usage(name string, namespace *Namespace, configurations config.Configurations) Usage {
+	usage := Usage{
+		Providers:       append([]string{}, namespace.Providers...),
+		MaxRouters:      namespace.MaxRouters,
+		MaxMiddlewares:  namespace.MaxMiddlewares,
+		MaxCertificates: namespace.MaxCertificates,
+	}
+	sort.Strings(usage.Providers)
+
+	for _, conf := range r.Configurations(name, configurations) {
+		if conf == nil {
+			continue
+		}
+		usage.Routers += len(conf.Routers)
+		usage.Middlewares += len(conf.Middlewares)
+		usage.Certificates += len(conf.TLS)
+	}
+
+	return usage
+}
+
+// checkOverride checks that the configuration doesn't define the routers, middlewares or services, HTTP or TCP, of the other configuration.
+func checkOverride(conf, other *config.Configuration) error {
+	for name := range conf.Routers {
+		if _, ok := other.Routers[name]; ok {
+			return fmt.Errorf("the router %s is already defined", name)
+		}
+	}
+
+	for name := range conf.Middlewares {
+		if _, ok := other.Middlewares[name]; ok {
+			return fmt.Errorf("the middleware %s is already defined", name)
+		}
+	}
+
+	for name := range conf.Services {
+		if _, ok := other.Services[name]; ok {
+			return fmt.Errorf("the service %s is already defined", name)
+		}
+	}
+
+	for name := range conf.TCPRouters {
+		if _, ok := other.TCPRouters[name]; ok {
+			return fmt.Errorf("the TCP router %s is already defined", name)
+		}
+	}
+
+	for name := range conf.TCPServices {
+		if _, ok := other.TCPServices[name]; ok {
+			return fmt.Errorf("the TCP service %s is already defined", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package namespace
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	registry, err := New(nil)
+	require.NoError(t, err)
+	assert.Nil(t, registry)
+
+	_, err = New(map[string]*Namespace{
+		"team-a": {Providers: []string{"file", "docker"}},
+		"team-b": {Providers: []string{"docker"}},
+	})
+	assert.Error(t, err)
+
+	registry, err = New(map[string]*Namespace{
+		"team-a": {Providers: []string{"file"}},
+		"team-b": {Providers: []string{"docker"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", registry.NamespaceOf("file"))
+	assert.Equal(t, "team-b", registry.NamespaceOf("docker"))
+	assert.Equal(t, "", registry.NamespaceOf("rest"))
+	assert.Equal(t, []string{"team-a", "team-b"}, registry.Names())
+}
+
+func TestCheck(t *testing.T) {
+	registry, err := New(map[string]*Namespace{
+		"team-a": {Providers: []string{"file", "rest"}, MaxRouters: 2, MaxMiddlewares: 1, MaxCertificates: 1},
+		"team-b": {Providers: []string{"docker"}},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		providerName   string
+		configurations config.Configurations
+		expectedErr    bool
+	}{
+		{
+			desc:         "within the limits",
+			providerName: "file",
+			configurations: config.Configurations{
+				"file": {Routers: map[string]*config.Router{"foo": {}}},
+				"rest": {Routers: map[string]*config.Router{"bar": {}}},
+			},
+		},
+		{
+			desc:         "too many routers in the namespace",
+			providerName: "file",
+			configurations: config.Configurations{
+				"file": {Routers: map[string]*config.Router{"foo": {}, "baz": {}}},
+				"rest": {Routers: map[string]*config.Router{"bar": {}}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:         "too many middlewares",
+			providerName: "rest",
+			configurations: config.Configurations{
+				"rest": {Middlewares: map[string]*config.Middleware{"foo": {}, "bar": {}}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:         "too many certificates",
+			providerName: "rest",
+			configurations: config.Configurations{
+				"rest": {TLS: []*tls.Configuration{{}, {}}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:         "same router in the same namespace",
+			providerName: "rest",
+			configurations: config.Configurations{
+				"file": {Routers: map[string]*config.Router{"foo": {}}},
+				"rest": {Routers: map[string]*config.Router{"foo": {}}},
+			},
+		},
+		{
+			desc:         "router of another namespace",
+			providerName: "docker",
+			configurations: config.Configurations{
+				"file":   {Routers: map[string]*config.Router{"foo": {}}},
+				"docker": {Routers: map[string]*config.Router{"foo": {}}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:         "service of the default namespace",
+			providerName: "docker",
+			configurations: config.Configurations{
+				"kubernetes": {Services: map[string]*config.Service{"foo": {}}},
+				"docker":     {Services: map[string]*config.Service{"foo": {}}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:         "TCP router of another namespace",
+			providerName: "docker",
+			configurations: config.Configurations{
+				"file":   {TCPRouters: map[string]*config.TCPRouter{"foo": {}}},
+				"docker": {TCPRouters: map[string]*config.TCPRouter{"foo": {}}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:         "TCP service of another namespace",
+			providerName: "docker",
+			configurations: config.Configurations{
+				"rest":   {TCPServices: map[string]*config.TCPService{"foo": {}}},
+				"docker": {TCPServices: map[string]*config.TCPService{"foo": {}}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:         "TCP router and HTTP router of the same name",
+			providerName: "docker",
+			configurations: config.Configurations{
+				"file":   {Routers: map[string]*config.Router{"foo": {}}},
+				"docker": {TCPRouters: map[string]*config.TCPRouter{"foo": {}}},
+			},
+		},
+		{
+			desc:         "no limits in the default namespace",
+			providerName: "kubernetes",
+			configurations: config.Configurations{
+				"kubernetes": {Routers: map[string]*config.Router{"foo": {}, "bar": {}, "baz": {}}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := registry.Check(test.providerName, test.configurations)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUsage(t *testing.T) {
+	registry, err := New(map[string]*Namespace{
+		"team-a": {Providers: []string{"rest", "file"}, MaxRouters: 10},
+	})
+	require.NoError(t, err)
+
+	configurations := config.Configurations{
+		"file":   {Routers: map[string]*config.Router{"foo": {}}, Middlewares: map[string]*config.Middleware{"foo": {}}},
+		"rest":   {Routers: map[string]*config.Router{"bar": {}}, TLS: []*tls.Configuration{{}}},
+		"docker": {Routers: map[string]*config.Router{"baz": {}}},
+	}
+
+	usage, err := registry.Usage("team-a", configurations)
+	require.NoError(t, err)
+
+	expected := Usage{
+		Providers:    []string{"file", "rest"},
+		Routers:      2,
+		MaxRouters:   10,
+		Middlewares:  1,
+		Certificates: 1,
+	}
+	assert.Equal(t, expected, usage)
+
+	_, err = registry.Usage("team-b", configurations)
+	assert.Equal(t, ErrNamespaceNotFound, err)
+}
+
+func TestAuthorize(t *testing.T) {
+	registry, err := New(map[string]*Namespace{
+		"team-a": {Providers: []string{"file"}, Tokens: []string{"secret-a"}},
+		"team-b": {Providers: []string{"docker"}},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, registry.Authorize("team-a", "secret-a"))
+	assert.False(t, registry.Authorize("team-a", "secret-b"))
+	assert.False(t, registry.Authorize("team-a", ""))
+	assert.False(t, registry.Authorize("team-b", "secret-a"))
+	assert.False(t, registry.Authorize("team-c", "secret-a"))
+}
@@ -0,0 +1,215 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cenk/backoff"
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/events"
+	"github.com/containous/traefik/log"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultTimeout    = 5 * time.Second
+
+	// bufferSize is the number of events queued for a webhook while it is retrying a post.
+	bufferSize = 100
+
+	// SignatureHeader is the header holding the HMAC-SHA256 signature of the payload, when the webhook has a secret.
+	SignatureHeader = "X-Traefik-Signature"
+	// EventHeader is the header holding the type of the event.
+	EventHeader = "X-Traefik-Event"
+)
+
+// Webhook holds the configuration of a webhook notified of the operational events.
+type Webhook struct {
+	URL        string         `description:"URL the events are posted to" export:"true"`
+	Secret     string         `description:"Secret signing the payloads with HMAC-SHA256"`
+	Events     []string       `description:"Types of the events posted to the webhook, all of them if empty" export:"true"`
+	MaxRetries int            `description:"Number of retries of a failed post (default 3)" export:"true"`
+	Timeout    parse.Duration `description:"Timeout of a post (default 5s)" export:"true"`
+}
+
+// Notifier posts the events published to a broker to the webhooks.
+// Each webhook has its own queue, so a failing webhook doesn't delay the others.
+// A nil Notifier notifies nothing.
+type Notifier struct {
+	broker   *events.Broker
+	webhooks map[string]*webhook
+}
+
+type webhook struct {
+	name       string
+	url        string
+	secret     []byte
+	events     map[string]bool
+	maxRetries uint64
+	client     *http.Client
+	newBackOff func() backoff.BackOff
+}
+
+// New creates a Notifier of the webhooks, nil if there is no webhook.
+func New(broker *events.Broker, webhooks map[string]*Webhook) (*Notifier, error) {
+	if len(webhooks) == 0 {
+		return nil, nil
+	}
+
+	n := &Notifier{
+		broker:   broker,
+		webhooks: make(map[string]*webhook),
+	}
+
+	for name, conf := range webhooks {
+		if conf == nil {
+			return nil, fmt.Errorf("no configuration for the webhook %s", name)
+		}
+
+		u, err := url.Parse(conf.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return nil, fmt.Errorf("invalid URL %q for the webhook %s", conf.URL, name)
+		}
+
+		if conf.MaxRetries < 0 {
+			return nil, fmt.Errorf("negative number of retries for the webhook %s", name)
+		}
+
+		w := &webhook{
+			name:       name,
+			url:        conf.URL,
+			secret:     []byte(conf.Secret),
+			events:     make(map[string]bool),
+			maxRetries: uint64(conf.MaxRetries),
+			client:     &http.Client{Timeout: time.Duration(conf.Timeout)},
+			newBackOff: func() backoff.BackOff { return backoff.NewExponentialBackOff() },
+		}
+
+		if w.maxRetries == 0 {
+			w.maxRetries = defaultMaxRetries
+		}
+		if w.client.Timeout <= 0 {
+			w.client.Timeout = defaultTimeout
+		}
+
+		for _, eventType := range conf.Events {
+			w.events[eventType] = true
+		}
+
+		n.webhooks[name] = w
+	}
+
+	return n, nil
+}
+
+// Run posts the events to the webhooks, until stop is closed.
+func (n *Notifier) Run(stop chan bool) {
+	if n == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var names []string
+	for name := range n.webhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		w := n.webhooks[name]
+		subscriber, unsubscribe := n.broker.Subscribe(bufferSize)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer unsubscribe()
+			w.run(ctx, subscriber)
+		}()
+	}
+
+	<-stop
+	cancel()
+	wg.Wait()
+}
+
+func (w *webhook) run(ctx context.Context, subscriber <-chan events.Event) {
+	logger := log.WithoutContext().WithField("webhook", w.name)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-subscriber:
+			if len(w.events) > 0 && !w.events[event.Type] {
+				continue
+			}
+
+			if err := w.notify(ctx, event); err != nil {
+				logger.Errorf("Unable to post the event %s to the webhook: %v", event.Type, err)
+			}
+		}
+	}
+}
+
+// notify posts the event to the webhook, retrying on the network errors and the server errors.
+func (w *webhook) notify(ctx context.Context, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	operation := func() error {
+		req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(payload))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		req = req.WithContext(ctx)
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(EventHeader, event.Type)
+		if len(w.secret) > 0 {
+			req.Header.Set(SignatureHeader, Sign(w.secret, payload))
+		}
+
+		resp, err := w.client.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= http.StatusInternalServerError, resp.StatusCode == http.StatusTooManyRequests:
+			return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		case resp.StatusCode >= http.StatusBadRequest:
+			return backoff.Permanent(fmt.Errorf("unexpected status code %d", resp.StatusCode))
+		}
+
+		return nil
+	}
+
+	notify := func(err error, time time.Duration) {
+		log.WithoutContext().WithField("webhook", w.name).Debugf("Unable to post the event %s to the webhook: %v; retrying in %s", event.Type, err, time)
+	}
+
+	return backoff.RetryNotify(operation, backoff.WithContext(backoff.WithMaxRetries(w.newBackOff(), w.maxRetries), ctx), notify)
+}
+
+// Sign returns the signature of the payload with the secret, as sent in the SignatureHeader: sha256=<hex encoded HMAC-SHA256>.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,184 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenk/backoff"
+	"github.com/containous/traefik/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		webhooks      map[string]*Webhook
+		expectedError bool
+	}{
+		{
+			desc: "no webhook",
+		},
+		{
+			desc:     "valid webhook",
+			webhooks: map[string]*Webhook{"slack": {URL: "https://hooks.slack.com/services/T0/B0/X"}},
+		},
+		{
+			desc:          "missing URL",
+			webhooks:      map[string]*Webhook{"slack": {}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid scheme",
+			webhooks:      map[string]*Webhook{"slack": {URL: "ftp://hooks.slack.com"}},
+			expectedError: true,
+		},
+		{
+			desc:          "negative retries",
+			webhooks:      map[string]*Webhook{"slack": {URL: "https://hooks.slack.com", MaxRetries: -1}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			notifier, err := New(events.NewBroker(), test.webhooks)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, len(test.webhooks) == 0, notifier == nil)
+		})
+	}
+}
+
+func TestNotify(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		statusCodes      []int
+		maxRetries       int
+		expectedAttempts int
+		expectedError    bool
+	}{
+		{
+			desc:             "success",
+			statusCodes:      []int{http.StatusOK},
+			maxRetries:       3,
+			expectedAttempts: 1,
+		},
+		{
+			desc:             "retried server errors",
+			statusCodes:      []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusNoContent},
+			maxRetries:       3,
+			expectedAttempts: 3,
+		},
+		{
+			desc:             "too many server errors",
+			statusCodes:      []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			maxRetries:       2,
+			expectedAttempts: 3,
+			expectedError:    true,
+		},
+		{
+			desc:             "client error not retried",
+			statusCodes:      []int{http.StatusNotFound},
+			maxRetries:       3,
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var lock sync.Mutex
+			var attempts int
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+
+				payload, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, Sign([]byte("secret"), payload), req.Header.Get(SignatureHeader))
+				assert.Equal(t, events.ServerDown, req.Header.Get(EventHeader))
+
+				event := events.Event{}
+				require.NoError(t, json.Unmarshal(payload, &event))
+				assert.Equal(t, map[string]string{"url": "http://10.0.0.1"}, event.Attributes)
+
+				rw.WriteHeader(test.statusCodes[attempts])
+				attempts++
+			}))
+			defer server.Close()
+
+			notifier, err := New(events.NewBroker(), map[string]*Webhook{"pager": {URL: server.URL, Secret: "secret", MaxRetries: test.maxRetries}})
+			require.NoError(t, err)
+
+			w := notifier.webhooks["pager"]
+			w.newBackOff = func() backoff.BackOff { return &backoff.ZeroBackOff{} }
+
+			err = w.notify(context.Background(), events.Event{Type: events.ServerDown, Attributes: map[string]string{"url": "http://10.0.0.1"}})
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	received := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case received <- req.Header.Get(EventHeader):
+		default:
+		}
+	}))
+	defer server.Close()
+
+	broker := events.NewBroker()
+	notifier, err := New(broker, map[string]*Webhook{"pager": {URL: server.URL, Events: []string{events.ServerDown}}})
+	require.NoError(t, err)
+
+	stop := make(chan bool)
+	done := make(chan struct{})
+	go func() {
+		notifier.Run(stop)
+		close(done)
+	}()
+
+	// The subscription of the webhooks is asynchronous.
+	deadline := time.After(5 * time.Second)
+	for eventType := ""; eventType != events.ServerDown; {
+		broker.Publish(events.ServerUp, nil)
+		broker.Publish(events.ServerDown, nil)
+
+		select {
+		case eventType = <-received:
+			assert.Equal(t, events.ServerDown, eventType)
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no event received")
+		}
+	}
+
+	close(stop)
+	<-done
+}
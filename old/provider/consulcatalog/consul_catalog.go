@@ -2,6 +2,7 @@ package consulcatalog
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -38,6 +39,7 @@ type Provider struct {
 	TLS                   *types.ClientTLS `description:"Enable TLS support" export:"true"`
 	client                *api.Client
 	frontEndRuleTemplate  *template.Template
+	lastConfiguration     safe.Safe
 }
 
 // Service represent a Consul service.
@@ -178,6 +180,12 @@ func (p *Provider) watch(configurationChan chan<- types.ConfigMessage, stop chan
 				notifyError(err)
 			}
 			configuration := p.buildConfiguration(nodes)
+			if reflect.DeepEqual(p.lastConfiguration.Get(), configuration) {
+				log.Debug("Skipping same Consul catalog configuration")
+				continue
+			}
+			p.lastConfiguration.Set(configuration)
+
 			configurationChan <- types.ConfigMessage{
 				ProviderName:  "consul_catalog",
 				Configuration: configuration,
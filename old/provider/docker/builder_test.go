@@ -47,6 +47,14 @@ func nodeIP(ip string) func(*docker.ContainerJSON) {
 	}
 }
 
+func health(status string) func(*docker.ContainerJSON) {
+	return func(c *docker.ContainerJSON) {
+		c.ContainerJSONBase.State = &docker.ContainerState{
+			Health: &docker.Health{Status: status},
+		}
+	}
+}
+
 func labels(labels map[string]string) func(*docker.ContainerJSON) {
 	return func(c *docker.ContainerJSON) {
 		c.Config.Labels = labels
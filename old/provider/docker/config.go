@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -150,7 +151,7 @@ func (p *Provider) containerFilter(container dockerData) bool {
 		return false
 	}
 
-	if container.Health != "" && container.Health != "healthy" {
+	if !p.AllowUnhealthy && container.Health != "" && container.Health != "healthy" {
 		log.Debugf("Filtering unhealthy or starting container %s", container.Name)
 		return false
 	}
@@ -206,9 +207,10 @@ func (p Provider) getIPAddress(container dockerData) string {
 	if value := label.GetStringValue(container.Labels, labelDockerNetwork, p.Network); value != "" {
 		networkSettings := container.NetworkSettings
 		if networkSettings.Networks != nil {
-			network := networkSettings.Networks[value]
-			if network != nil {
-				return network.Addr
+			for _, name := range label.SplitAndTrimString(value, ",") {
+				if network := networkSettings.Networks[name]; network != nil {
+					return network.Addr
+				}
 			}
 
 			log.Warnf("Could not find network named '%s' for container '%s'! Maybe you're missing the project's prefix in the label? Defaulting to first available network.", value, container.Name)
@@ -240,8 +242,15 @@ func (p Provider) getIPAddress(container dockerData) string {
 		return p.getIPAddress(parseContainer(containerInspected))
 	}
 
-	for _, network := range container.NetworkSettings.Networks {
-		return network.Addr
+	// Use the networks in a stable order, so the address does not change on each refresh.
+	var names []string
+	for name := range container.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) > 0 {
+		return container.NetworkSettings.Networks[names[0]].Addr
 	}
 
 	log.Warnf("Unable to find the IP address for the container %q.", container.Name)
@@ -1122,6 +1122,55 @@ func TestDockerTraefikFilter(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			container: containerJSON(
+				name("container"),
+				labels(map[string]string{label.TraefikFrontendRule: "Host:i.love.this.host"}),
+				ports(nat.PortMap{"80/tcp": {}}),
+				health("unhealthy"),
+			),
+			provider: &Provider{
+				ExposedByDefault: true,
+			},
+			expected: false,
+		},
+		{
+			container: containerJSON(
+				name("container"),
+				labels(map[string]string{label.TraefikFrontendRule: "Host:i.love.this.host"}),
+				ports(nat.PortMap{"80/tcp": {}}),
+				health("starting"),
+			),
+			provider: &Provider{
+				ExposedByDefault: true,
+			},
+			expected: false,
+		},
+		{
+			container: containerJSON(
+				name("container"),
+				labels(map[string]string{label.TraefikFrontendRule: "Host:i.love.this.host"}),
+				ports(nat.PortMap{"80/tcp": {}}),
+				health("healthy"),
+			),
+			provider: &Provider{
+				ExposedByDefault: true,
+			},
+			expected: true,
+		},
+		{
+			container: containerJSON(
+				name("container"),
+				labels(map[string]string{label.TraefikFrontendRule: "Host:i.love.this.host"}),
+				ports(nat.PortMap{"80/tcp": {}}),
+				health("unhealthy"),
+			),
+			provider: &Provider{
+				ExposedByDefault: true,
+				AllowUnhealthy:   true,
+			},
+			expected: true,
+		},
 	}
 
 	for containerID, test := range testCases {
@@ -1375,6 +1424,26 @@ func TestDockerGetIPAddress(t *testing.T) {
 			),
 			expected: "10.0.0.5",
 		},
+		{
+			container: containerJSON(
+				labels(map[string]string{
+					labelDockerNetwork: "missingnet, testnet2, testnet",
+				}),
+				withNetwork("testnet", ipv4("10.11.12.13")),
+				withNetwork("testnet2", ipv4("10.11.12.14")),
+			),
+			expected: "10.11.12.14",
+		},
+		{
+			container: containerJSON(
+				labels(map[string]string{
+					labelDockerNetwork: "missingnet",
+				}),
+				withNetwork("testnet2", ipv4("10.11.12.14")),
+				withNetwork("testnet", ipv4("10.11.12.13")),
+			),
+			expected: "10.11.12.13",
+		},
 	}
 
 	for containerID, test := range testCases {
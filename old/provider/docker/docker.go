@@ -43,8 +43,9 @@ type Provider struct {
 	ExposedByDefault        bool             `description:"Expose containers by default" export:"true"`
 	UseBindPortIP           bool             `description:"Use the ip address from the bound port, rather than from the inner network" export:"true"`
 	SwarmMode               bool             `description:"Use Docker on Swarm Mode" export:"true"`
-	Network                 string           `description:"Default Docker network used" export:"true"`
+	Network                 string           `description:"Default Docker network used, or a comma separated list of networks by order of preference" export:"true"`
 	SwarmModeRefreshSeconds int              `description:"Polling interval for swarm mode (in seconds)" export:"true"`
+	AllowUnhealthy          bool             `description:"Include the containers whose Docker healthcheck does not report healthy" export:"true"`
 }
 
 // Init the provider
@@ -198,6 +199,7 @@ func (p *Provider) Provide(configurationChan chan<- types.ConfigMessage, pool *s
 				} else {
 					f := filters.NewArgs()
 					f.Add("type", "container")
+					f.Add("type", "network")
 					options := dockertypes.EventsOptions{
 						Filters: f,
 					}
@@ -228,9 +230,7 @@ func (p *Provider) Provide(configurationChan chan<- types.ConfigMessage, pool *s
 					for {
 						select {
 						case event := <-eventsc:
-							if event.Action == "start" ||
-								event.Action == "die" ||
-								strings.HasPrefix(event.Action, "health_status") {
+							if isRefreshEvent(event) {
 								startStopHandle(event)
 							}
 						case err := <-errc:
@@ -483,3 +483,18 @@ func parseTasks(task swarmtypes.Task, serviceDockerData dockerData,
 	}
 	return dData
 }
+
+// isRefreshEvent returns true if the event changes the containers Traefik routes to,
+// or the networks (and so the IP addresses) they can be reached on.
+func isRefreshEvent(event eventtypes.Message) bool {
+	switch event.Type {
+	case eventtypes.ContainerEventType:
+		return event.Action == "start" ||
+			event.Action == "die" ||
+			strings.HasPrefix(event.Action, "health_status")
+	case eventtypes.NetworkEventType:
+		return event.Action == "connect" || event.Action == "disconnect"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"testing"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRefreshEvent(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		event    eventtypes.Message
+		expected bool
+	}{
+		{
+			desc:     "container start",
+			event:    eventtypes.Message{Type: eventtypes.ContainerEventType, Action: "start"},
+			expected: true,
+		},
+		{
+			desc:     "container die",
+			event:    eventtypes.Message{Type: eventtypes.ContainerEventType, Action: "die"},
+			expected: true,
+		},
+		{
+			desc:     "container health status",
+			event:    eventtypes.Message{Type: eventtypes.ContainerEventType, Action: "health_status: healthy"},
+			expected: true,
+		},
+		{
+			desc:  "container exec",
+			event: eventtypes.Message{Type: eventtypes.ContainerEventType, Action: "exec_start: sh"},
+		},
+		{
+			desc:     "network connect",
+			event:    eventtypes.Message{Type: eventtypes.NetworkEventType, Action: "connect"},
+			expected: true,
+		},
+		{
+			desc:     "network disconnect",
+			event:    eventtypes.Message{Type: eventtypes.NetworkEventType, Action: "disconnect"},
+			expected: true,
+		},
+		{
+			desc:  "network create",
+			event: eventtypes.Message{Type: eventtypes.NetworkEventType, Action: "create"},
+		},
+		{
+			desc:  "image pull",
+			event: eventtypes.Message{Type: eventtypes.ImageEventType, Action: "pull"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isRefreshEvent(test.event))
+		})
+	}
+}
@@ -3,7 +3,9 @@ package ecs
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -37,6 +39,11 @@ type Provider struct {
 	Region               string   `description:"The AWS region to use for requests" export:"true"`
 	AccessKeyID          string   `description:"The AWS credentials access key to use for making requests"`
 	SecretAccessKey      string   `description:"The AWS credentials access key to use for making requests"`
+
+	lastConfiguration safe.Safe
+	// taskDefinitions caches the task definitions by ARN: a revision of a task definition never changes.
+	taskDefinitions     map[string]*ecs.TaskDefinition
+	taskDefinitionsLock sync.Mutex
 }
 
 type ecsInstance struct {
@@ -141,6 +148,7 @@ func (p *Provider) Provide(configurationChan chan<- types.ConfigMessage, pool *s
 			if err != nil {
 				return handleCanceled(ctx, err)
 			}
+			p.lastConfiguration.Set(configuration)
 
 			configurationChan <- types.ConfigMessage{
 				ProviderName:  "ecs",
@@ -158,6 +166,12 @@ func (p *Provider) Provide(configurationChan chan<- types.ConfigMessage, pool *s
 							return handleCanceled(ctx, err)
 						}
 
+						if reflect.DeepEqual(p.lastConfiguration.Get(), configuration) {
+							log.Debug("Skipping same ECS configuration")
+							continue
+						}
+						p.lastConfiguration.Set(configuration)
+
 						configurationChan <- types.ConfigMessage{
 							ProviderName:  "ecs",
 							Configuration: configuration,
@@ -214,6 +228,7 @@ func (p *Provider) listInstances(ctx context.Context, client *awsClient) ([]ecsI
 	}
 
 	var instances []ecsInstance
+	usedTaskDefinitions := make(map[string]bool)
 
 	log.Debugf("ECS Clusters: %s", clusters)
 
@@ -265,6 +280,7 @@ func (p *Provider) listInstances(ctx context.Context, client *awsClient) ([]ecsI
 		}
 
 		for key, task := range tasks {
+			usedTaskDefinitions[aws.StringValue(task.TaskDefinitionArn)] = true
 
 			containerInstance := ec2Instances[aws.StringValue(task.ContainerInstanceArn)]
 			taskDef := taskDefinitions[key]
@@ -328,6 +344,8 @@ func (p *Provider) listInstances(ctx context.Context, client *awsClient) ([]ecsI
 		}
 	}
 
+	p.pruneTaskDefinitions(usedTaskDefinitions)
+
 	return instances, nil
 }
 
@@ -392,8 +410,22 @@ func (p *Provider) lookupEc2Instances(ctx context.Context, client *awsClient, cl
 }
 
 func (p *Provider) lookupTaskDefinitions(ctx context.Context, client *awsClient, taskDefArns map[string]*ecs.Task) (map[string]*ecs.TaskDefinition, error) {
+	p.taskDefinitionsLock.Lock()
+	defer p.taskDefinitionsLock.Unlock()
+
+	if p.taskDefinitions == nil {
+		p.taskDefinitions = make(map[string]*ecs.TaskDefinition)
+	}
+
 	taskDef := make(map[string]*ecs.TaskDefinition)
 	for arn, task := range taskDefArns {
+		taskDefinitionArn := aws.StringValue(task.TaskDefinitionArn)
+
+		if definition, ok := p.taskDefinitions[taskDefinitionArn]; ok {
+			taskDef[arn] = definition
+			continue
+		}
+
 		resp, err := client.ecs.DescribeTaskDefinitionWithContext(ctx, &ecs.DescribeTaskDefinitionInput{
 			TaskDefinition: task.TaskDefinitionArn,
 		})
@@ -403,11 +435,25 @@ func (p *Provider) lookupTaskDefinitions(ctx context.Context, client *awsClient,
 			return nil, err
 		}
 
+		p.taskDefinitions[taskDefinitionArn] = resp.TaskDefinition
 		taskDef[arn] = resp.TaskDefinition
 	}
+
 	return taskDef, nil
 }
 
+// pruneTaskDefinitions forgets the cached task definitions which are no longer used by a running task.
+func (p *Provider) pruneTaskDefinitions(used map[string]bool) {
+	p.taskDefinitionsLock.Lock()
+	defer p.taskDefinitionsLock.Unlock()
+
+	for taskDefinitionArn := range p.taskDefinitions {
+		if !used[taskDefinitionArn] {
+			delete(p.taskDefinitions, taskDefinitionArn)
+		}
+	}
+}
+
 func (p *Provider) loadECSConfig(ctx context.Context, client *awsClient) (*types.Configuration, error) {
 	instances, err := p.listInstances(ctx, client)
 	if err != nil {
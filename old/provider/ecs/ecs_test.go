@@ -1,10 +1,13 @@
 package ecs
 
 import (
+	"context"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestChunkIDs(t *testing.T) {
@@ -86,3 +89,42 @@ func TestChunkIDs(t *testing.T) {
 		})
 	}
 }
+
+func TestLookupTaskDefinitionsFromCache(t *testing.T) {
+	definition := &ecs.TaskDefinition{TaskDefinitionArn: aws.String("arn:task-definition/foo:1")}
+
+	provider := &Provider{
+		taskDefinitions: map[string]*ecs.TaskDefinition{
+			"arn:task-definition/foo:1": definition,
+		},
+	}
+
+	tasks := map[string]*ecs.Task{
+		"arn:task/1": {TaskDefinitionArn: aws.String("arn:task-definition/foo:1")},
+		"arn:task/2": {TaskDefinitionArn: aws.String("arn:task-definition/foo:1")},
+	}
+
+	// The AWS client is not used, as the definitions are already known.
+	taskDefinitions, err := provider.lookupTaskDefinitions(context.Background(), nil, tasks)
+	require.NoError(t, err)
+
+	expected := map[string]*ecs.TaskDefinition{
+		"arn:task/1": definition,
+		"arn:task/2": definition,
+	}
+	assert.Equal(t, expected, taskDefinitions)
+}
+
+func TestPruneTaskDefinitions(t *testing.T) {
+	provider := &Provider{
+		taskDefinitions: map[string]*ecs.TaskDefinition{
+			"arn:task-definition/foo:1": {},
+			"arn:task-definition/foo:2": {},
+		},
+	}
+
+	provider.pruneTaskDefinitions(map[string]bool{"arn:task-definition/foo:2": true})
+
+	assert.Len(t, provider.taskDefinitions, 1)
+	assert.Contains(t, provider.taskDefinitions, "arn:task-definition/foo:2")
+}
@@ -33,6 +33,7 @@ const (
 	annotationKubernetesAffinity                        = "ingress.kubernetes.io/affinity"
 	annotationKubernetesSessionCookieName               = "ingress.kubernetes.io/session-cookie-name"
 	annotationKubernetesRuleType                        = "ingress.kubernetes.io/rule-type"
+	annotationKubernetesPathType                        = "ingress.kubernetes.io/path-type"
 	annotationKubernetesRedirectEntryPoint              = "ingress.kubernetes.io/redirect-entry-point"
 	annotationKubernetesRedirectPermanent               = "ingress.kubernetes.io/redirect-permanent"
 	annotationKubernetesRedirectRegex                   = "ingress.kubernetes.io/redirect-regex"
@@ -46,6 +47,13 @@ const (
 	annotationKubernetesAppRoot                         = "ingress.kubernetes.io/app-root"
 	annotationKubernetesServiceWeights                  = "ingress.kubernetes.io/service-weights"
 	annotationKubernetesRequestModifier                 = "ingress.kubernetes.io/request-modifier"
+	annotationKubernetesHealthCheckScheme               = "ingress.kubernetes.io/health-check-scheme"
+	annotationKubernetesHealthCheckPath                 = "ingress.kubernetes.io/health-check-path"
+	annotationKubernetesHealthCheckPort                 = "ingress.kubernetes.io/health-check-port"
+	annotationKubernetesHealthCheckInterval             = "ingress.kubernetes.io/health-check-interval"
+	annotationKubernetesHealthCheckTimeout              = "ingress.kubernetes.io/health-check-timeout"
+	annotationKubernetesHealthCheckHostname             = "ingress.kubernetes.io/health-check-hostname"
+	annotationKubernetesHealthCheckHeaders              = "ingress.kubernetes.io/health-check-headers"
 
 	annotationKubernetesSSLForceHost            = "ingress.kubernetes.io/ssl-force-host"
 	annotationKubernetesSSLRedirect             = "ingress.kubernetes.io/ssl-redirect"
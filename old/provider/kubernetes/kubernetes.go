@@ -46,6 +46,9 @@ const (
 	defaultFrontendRule        = "PathPrefix:/"
 	allowedProtocolHTTPS       = "https"
 	allowedProtocolH2C         = "h2c"
+	pathTypeExact              = "Exact"
+	pathTypePrefix             = "Prefix"
+	pathTypeImplSpecific       = "ImplementationSpecific"
 )
 
 // IngressEndpoint holds the endpoint information for the Kubernetes provider
@@ -336,7 +339,11 @@ func (p *Provider) loadIngresses(k8sClient Client) (*types.Configuration, error)
 				templateObjects.Backends[baseName].CircuitBreaker = getCircuitBreaker(service)
 				templateObjects.Backends[baseName].LoadBalancer = getLoadBalancer(service)
 				templateObjects.Backends[baseName].MaxConn = getMaxConn(service)
-				templateObjects.Backends[baseName].Buffering = getBuffering(service)
+				templateObjects.Backends[baseName].Buffering = getBuffering(service.Annotations)
+				if buffering := getBuffering(i.Annotations); buffering != nil {
+					templateObjects.Backends[baseName].Buffering = buffering
+				}
+				templateObjects.Backends[baseName].HealthCheck = getHealthCheck(i)
 				templateObjects.Backends[baseName].ResponseForwarding = getResponseForwarding(service)
 
 				protocol := label.DefaultProtocol
@@ -495,7 +502,11 @@ func (p *Provider) addGlobalBackend(cl Client, i *extensionsv1beta1.Ingress, tem
 	templateObjects.Backends[defaultBackendName].CircuitBreaker = getCircuitBreaker(service)
 	templateObjects.Backends[defaultBackendName].LoadBalancer = getLoadBalancer(service)
 	templateObjects.Backends[defaultBackendName].MaxConn = getMaxConn(service)
-	templateObjects.Backends[defaultBackendName].Buffering = getBuffering(service)
+	templateObjects.Backends[defaultBackendName].Buffering = getBuffering(service.Annotations)
+	if buffering := getBuffering(i.Annotations); buffering != nil {
+		templateObjects.Backends[defaultBackendName].Buffering = buffering
+	}
+	templateObjects.Backends[defaultBackendName].HealthCheck = getHealthCheck(i)
 	templateObjects.Backends[defaultBackendName].ResponseForwarding = getResponseForwarding(service)
 
 	endpoints, exists, err := cl.GetEndpoints(service.Namespace, service.Name)
@@ -567,7 +578,16 @@ func getRuleForPath(pa extensionsv1beta1.HTTPIngressPath, i *extensionsv1beta1.I
 		return "", nil
 	}
 
-	ruleType := getStringValue(i.Annotations, annotationKubernetesRuleType, ruleTypePathPrefix)
+	defaultRuleType := ruleTypePathPrefix
+	switch pathType := getStringValue(i.Annotations, annotationKubernetesPathType, ""); pathType {
+	case pathTypeExact:
+		defaultRuleType = ruleTypePath
+	case pathTypePrefix, pathTypeImplSpecific, "":
+	default:
+		return "", fmt.Errorf("unknown path type: %q", pathType)
+	}
+
+	ruleType := getStringValue(i.Annotations, annotationKubernetesRuleType, defaultRuleType)
 
 	switch ruleType {
 	case ruleTypePath, ruleTypePathPrefix, ruleTypePathStrip, ruleTypePathPrefixStrip:
@@ -980,10 +1000,10 @@ func getResponseForwarding(service *corev1.Service) *types.ResponseForwarding {
 	}
 }
 
-func getBuffering(service *corev1.Service) *types.Buffering {
+func getBuffering(annotations map[string]string) *types.Buffering {
 	var buffering *types.Buffering
 
-	bufferingRaw := getStringValue(service.Annotations, annotationKubernetesBuffering, "")
+	bufferingRaw := getStringValue(annotations, annotationKubernetesBuffering, "")
 
 	if len(bufferingRaw) > 0 {
 		buffering = &types.Buffering{}
@@ -997,6 +1017,23 @@ func getBuffering(service *corev1.Service) *types.Buffering {
 	return buffering
 }
 
+func getHealthCheck(i *extensionsv1beta1.Ingress) *types.HealthCheck {
+	path := getStringValue(i.Annotations, annotationKubernetesHealthCheckPath, "")
+	if len(path) == 0 {
+		return nil
+	}
+
+	return &types.HealthCheck{
+		Scheme:   getStringValue(i.Annotations, annotationKubernetesHealthCheckScheme, ""),
+		Path:     path,
+		Port:     getIntValue(i.Annotations, annotationKubernetesHealthCheckPort, label.DefaultBackendHealthCheckPort),
+		Interval: getStringValue(i.Annotations, annotationKubernetesHealthCheckInterval, ""),
+		Timeout:  getStringValue(i.Annotations, annotationKubernetesHealthCheckTimeout, ""),
+		Hostname: getStringValue(i.Annotations, annotationKubernetesHealthCheckHostname, ""),
+		Headers:  getMapValue(i.Annotations, annotationKubernetesHealthCheckHeaders),
+	}
+}
+
 func getLoadBalancer(service *corev1.Service) *types.LoadBalancer {
 	loadBalancer := &types.LoadBalancer{
 		Method: "wrr",
@@ -483,6 +483,147 @@ func TestRuleType(t *testing.T) {
 	}
 }
 
+func TestPathType(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		annotations      map[string]string
+		frontendRuleType string
+	}{
+		{
+			desc:             "path type annotation missing",
+			frontendRuleType: ruleTypePathPrefix,
+		},
+		{
+			desc:             "Exact path type",
+			annotations:      map[string]string{annotationKubernetesPathType: pathTypeExact},
+			frontendRuleType: ruleTypePath,
+		},
+		{
+			desc:             "Prefix path type",
+			annotations:      map[string]string{annotationKubernetesPathType: pathTypePrefix},
+			frontendRuleType: ruleTypePathPrefix,
+		},
+		{
+			desc:             "ImplementationSpecific path type",
+			annotations:      map[string]string{annotationKubernetesPathType: pathTypeImplSpecific},
+			frontendRuleType: ruleTypePathPrefix,
+		},
+		{
+			desc: "rule type annotation takes precedence over the path type",
+			annotations: map[string]string{
+				annotationKubernetesPathType: pathTypeExact,
+				annotationKubernetesRuleType: ruleTypePathPrefixStrip,
+			},
+			frontendRuleType: ruleTypePathPrefixStrip,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ingress := buildIngress(iRules(iRule(
+				iHost("host"),
+				iPaths(
+					onePath(iPath("/path"), iBackend("service", intstr.FromInt(80))),
+				),
+			)))
+			ingress.Annotations = test.annotations
+
+			service := buildService(
+				sName("service"),
+				sUID("1"),
+				sSpec(sPorts(sPort(801, "http"))),
+			)
+
+			client := clientMock{
+				ingresses: []*extensionsv1beta1.Ingress{ingress},
+				services:  []*corev1.Service{service},
+				watchChan: make(chan interface{}),
+			}
+			provider := Provider{DisablePassHostHeaders: true}
+
+			actualConfig, err := provider.loadIngresses(client)
+			require.NoError(t, err, "error loading ingresses")
+
+			expected := buildFrontends(frontend("host/path",
+				routes(
+					route("/path", fmt.Sprintf("%s:/path", test.frontendRuleType)),
+					route("host", "Host:host")),
+			))
+
+			assert.Equal(t, expected, actualConfig.Frontends)
+		})
+	}
+}
+
+func TestPathTypeUnknown(t *testing.T) {
+	ingress := buildIngress(
+		iAnnotation(annotationKubernetesPathType, "Regex"),
+		iRules(iRule(
+			iHost("host"),
+			iPaths(
+				onePath(iPath("/path"), iBackend("service", intstr.FromInt(80))),
+			),
+		)),
+	)
+
+	_, err := getRuleForPath(ingress.Spec.Rules[0].HTTP.Paths[0], ingress)
+	assert.EqualError(t, err, `unknown path type: "Regex"`)
+}
+
+func TestIngressBackendAnnotations(t *testing.T) {
+	ingress := buildIngress(
+		iNamespace("testing"),
+		iAnnotation(annotationKubernetesHealthCheckPath, "/health"),
+		iAnnotation(annotationKubernetesHealthCheckPort, "8081"),
+		iAnnotation(annotationKubernetesHealthCheckInterval, "5s"),
+		iAnnotation(annotationKubernetesHealthCheckTimeout, "2s"),
+		iAnnotation(annotationKubernetesHealthCheckHostname, "health.local"),
+		iAnnotation(annotationKubernetesHealthCheckHeaders, "X-Check:traefik"),
+		iAnnotation(annotationKubernetesBuffering, "maxrequestbodybytes: 1024"),
+		iRules(iRule(
+			iHost("host"),
+			iPaths(
+				onePath(iPath("/path"), iBackend("service", intstr.FromInt(80))),
+			),
+		)),
+	)
+
+	service := buildService(
+		sName("service"),
+		sNamespace("testing"),
+		sUID("1"),
+		sAnnotation(annotationKubernetesBuffering, "maxrequestbodybytes: 2048"),
+		sSpec(sPorts(sPort(80, "http"))),
+	)
+
+	client := clientMock{
+		ingresses: []*extensionsv1beta1.Ingress{ingress},
+		services:  []*corev1.Service{service},
+		watchChan: make(chan interface{}),
+	}
+	provider := Provider{}
+
+	actualConfig, err := provider.loadIngresses(client)
+	require.NoError(t, err, "error loading ingresses")
+
+	require.Contains(t, actualConfig.Backends, "host/path")
+	backend := actualConfig.Backends["host/path"]
+
+	expectedHealthCheck := &types.HealthCheck{
+		Path:     "/health",
+		Port:     8081,
+		Interval: "5s",
+		Timeout:  "2s",
+		Hostname: "health.local",
+		Headers:  map[string]string{"X-Check": "traefik"},
+	}
+	assert.Equal(t, expectedHealthCheck, backend.HealthCheck)
+	assert.Equal(t, &types.Buffering{MaxRequestBodyBytes: 1024}, backend.Buffering)
+}
+
 func TestRuleFails(t *testing.T) {
 	testCases := []struct {
 		desc                      string
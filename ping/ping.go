@@ -2,10 +2,12 @@ package ping
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/containous/mux"
+	"github.com/containous/traefik/log"
 )
 
 // Handler expose ping routes.
@@ -13,6 +15,7 @@ type Handler struct {
 	EntryPoint  string   `description:"Ping entryPoint" export:"true"`
 	Middlewares []string `description:"Middleware list" export:"true"`
 	terminating bool
+	readiness   *Readiness
 }
 
 // WithContext causes the ping endpoint to serve non 200 responses.
@@ -23,6 +26,11 @@ func (h *Handler) WithContext(ctx context.Context) {
 	}()
 }
 
+// SetReadiness sets the checks reported by the readiness endpoint.
+func (h *Handler) SetReadiness(readiness *Readiness) {
+	h.readiness = readiness
+}
+
 // Append adds ping routes on a router.
 func (h *Handler) Append(router *mux.Router) {
 	router.Methods(http.MethodGet, http.MethodHead).Path("/ping").
@@ -34,4 +42,26 @@ func (h *Handler) Append(router *mux.Router) {
 			response.WriteHeader(statusCode)
 			fmt.Fprint(response, http.StatusText(statusCode))
 		})
+
+	router.Methods(http.MethodGet, http.MethodHead).Path("/ready").
+		HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			status := Status{Ready: true, Checks: []CheckStatus{}}
+			if h.readiness != nil {
+				status = h.readiness.Status()
+			}
+			if h.terminating {
+				status.Ready = false
+			}
+
+			statusCode := http.StatusOK
+			if !status.Ready {
+				statusCode = http.StatusServiceUnavailable
+			}
+
+			response.Header().Set("Content-Type", "application/json")
+			response.WriteHeader(statusCode)
+			if err := json.NewEncoder(response).Encode(status); err != nil {
+				log.FromContext(request.Context()).Error(err)
+			}
+		})
 }
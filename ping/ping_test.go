@@ -0,0 +1,102 @@
+package ping
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Ready(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		checks             map[string]Check
+		terminating        bool
+		expectedStatusCode int
+		expectedStatus     Status
+	}{
+		{
+			desc:               "no readiness",
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     Status{Ready: true, Checks: []CheckStatus{}},
+		},
+		{
+			desc: "all checks pass",
+			checks: map[string]Check{
+				"configuration": func() error { return nil },
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedStatus: Status{
+				Ready:  true,
+				Checks: []CheckStatus{{Name: "configuration", Ready: true}},
+			},
+		},
+		{
+			desc: "a check fails",
+			checks: map[string]Check{
+				"acme": func() error { return errors.New("permission denied") },
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus: Status{
+				Checks: []CheckStatus{{Name: "acme", Error: "permission denied"}},
+			},
+		},
+		{
+			desc:               "terminating",
+			terminating:        true,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     Status{Checks: []CheckStatus{}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &Handler{terminating: test.terminating}
+			if test.checks != nil {
+				readiness := NewReadiness()
+				for name, check := range test.checks {
+					readiness.Add(name, check)
+				}
+				handler.SetReadiness(readiness)
+			}
+
+			router := mux.NewRouter()
+			handler.Append(router)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+			var status Status
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+			assert.Equal(t, test.expectedStatus, status)
+		})
+	}
+}
+
+func TestReadiness_Status(t *testing.T) {
+	readiness := NewReadiness()
+	readiness.Add("configuration", func() error { return errors.New("not loaded") })
+	readiness.Add("providers", func() error { return nil })
+	readiness.Add("configuration", func() error { return nil })
+
+	status := readiness.Status()
+
+	assert.Equal(t, Status{
+		Ready: true,
+		Checks: []CheckStatus{
+			{Name: "configuration", Ready: true},
+			{Name: "providers", Ready: true},
+		},
+	}, status)
+}
@@ -0,0 +1,63 @@
+package ping
+
+import (
+	"sync"
+)
+
+// Check reports an error when a dependency of Traefik is not ready.
+type Check func() error
+
+// CheckStatus is the result of a readiness check.
+type CheckStatus struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+// Status is the readiness status of Traefik.
+type Status struct {
+	Ready  bool          `json:"ready"`
+	Checks []CheckStatus `json:"checks"`
+}
+
+// Readiness holds the checks Traefik must pass before being ready to serve traffic.
+type Readiness struct {
+	lock   sync.RWMutex
+	names  []string
+	checks map[string]Check
+}
+
+// NewReadiness creates a Readiness without any check.
+func NewReadiness() *Readiness {
+	return &Readiness{checks: make(map[string]Check)}
+}
+
+// Add adds a check, or replaces the check with the same name.
+func (r *Readiness) Add(name string, check Check) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.checks[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.checks[name] = check
+}
+
+// Status runs all the checks, in the order they were added.
+func (r *Readiness) Status() Status {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	status := Status{Ready: true, Checks: []CheckStatus{}}
+	for _, name := range r.names {
+		checkStatus := CheckStatus{Name: name, Ready: true}
+		if err := r.checks[name](); err != nil {
+			checkStatus.Ready = false
+			checkStatus.Error = err.Error()
+			status.Ready = false
+		}
+		status.Checks = append(status.Checks, checkStatus)
+	}
+
+	return status
+}
@@ -0,0 +1,140 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"plugin"
+	"sync"
+
+	"github.com/containous/traefik/log"
+)
+
+// SymbolName is the name of the constructor a plugin must export.
+const SymbolName = "New"
+
+// Constructor is the constructor a plugin exports under SymbolName, as a function or as a variable.
+// It creates a middleware calling next, from the settings of the plugin merged with the ones of the middleware.
+// It only uses types of the standard library, so a plugin doesn't have to be built against the sources of Traefik.
+type Constructor = func(ctx context.Context, next http.Handler, settings map[string]string, name string) (http.Handler, error)
+
+// Descriptor describes a plugin.
+type Descriptor struct {
+	Path     string            `description:"Path to the Go plugin (.so file) providing the middleware" export:"true"`
+	Settings map[string]string `description:"Settings given to all the middlewares of the plugin"`
+}
+
+type loadedPlugin struct {
+	constructor Constructor
+	settings    map[string]string
+}
+
+// Registry holds the loaded plugins.
+type Registry struct {
+	lock    sync.RWMutex
+	plugins map[string]loadedPlugin
+	open    func(path string) (Constructor, error)
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		plugins: make(map[string]loadedPlugin),
+		open:    open,
+	}
+}
+
+// Load creates a Registry with the plugins described by the descriptors.
+// The plugins which cannot be loaded are logged and left out.
+func Load(ctx context.Context, descriptors map[string]*Descriptor) *Registry {
+	registry := NewRegistry()
+	for name, descriptor := range descriptors {
+		if err := registry.Add(name, descriptor); err != nil {
+			log.FromContext(ctx).Error(err)
+			continue
+		}
+		log.FromContext(ctx).Infof("Plugin %s loaded", name)
+	}
+	return registry
+}
+
+// Add loads the plugin described by the descriptor.
+func (r *Registry) Add(name string, descriptor *Descriptor) error {
+	if descriptor == nil || len(descriptor.Path) == 0 {
+		return fmt.Errorf("no path defined for the plugin %s", name)
+	}
+
+	constructor, err := r.open(descriptor.Path)
+	if err != nil {
+		return fmt.Errorf("unable to load the plugin %s: %v", name, err)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.plugins[name] = loadedPlugin{
+		constructor: constructor,
+		settings:    descriptor.Settings,
+	}
+
+	return nil
+}
+
+// Build creates a middleware of the plugin.
+// The settings of the middleware override the ones of the plugin.
+func (r *Registry) Build(ctx context.Context, next http.Handler, pluginName string, settings map[string]string, name string) (http.Handler, error) {
+	if r == nil {
+		return nil, fmt.Errorf("plugin %q is not loaded", pluginName)
+	}
+
+	r.lock.RLock()
+	p, ok := r.plugins[pluginName]
+	r.lock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("plugin %q is not loaded", pluginName)
+	}
+
+	merged := make(map[string]string, len(p.settings)+len(settings))
+	for k, v := range p.settings {
+		merged[k] = v
+	}
+	for k, v := range settings {
+		merged[k] = v
+	}
+
+	handler, err := p.constructor(ctx, next, merged, name)
+	if err != nil {
+		return nil, err
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("plugin %q returned no handler", pluginName)
+	}
+
+	return handler, nil
+}
+
+func open(path string) (Constructor, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, err := p.Lookup(SymbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch constructor := symbol.(type) {
+	case Constructor:
+		return constructor, nil
+	case *Constructor:
+		if *constructor == nil {
+			return nil, errors.New("nil constructor")
+		}
+		return *constructor, nil
+	default:
+		return nil, fmt.Errorf("the symbol %s has the type %T, not %T", SymbolName, symbol, Constructor(nil))
+	}
+}
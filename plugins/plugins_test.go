@@ -0,0 +1,146 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func headerPlugin(ctx context.Context, next http.Handler, settings map[string]string, name string) (http.Handler, error) {
+	if len(settings["header"]) == 0 {
+		return nil, errors.New("no header")
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set(settings["header"], settings["value"])
+		next.ServeHTTP(rw, req)
+	}), nil
+}
+
+func newTestRegistry() *Registry {
+	registry := NewRegistry()
+	registry.open = func(path string) (Constructor, error) {
+		if path != "header.so" {
+			return nil, errors.New("no such file")
+		}
+		return headerPlugin, nil
+	}
+	return registry
+}
+
+func TestRegistry_Add(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		descriptor  *Descriptor
+		expectedErr string
+	}{
+		{
+			desc:       "valid plugin",
+			descriptor: &Descriptor{Path: "header.so"},
+		},
+		{
+			desc:        "no path",
+			descriptor:  &Descriptor{},
+			expectedErr: "no path defined for the plugin foo",
+		},
+		{
+			desc:        "nil descriptor",
+			expectedErr: "no path defined for the plugin foo",
+		},
+		{
+			desc:        "unknown file",
+			descriptor:  &Descriptor{Path: "unknown.so"},
+			expectedErr: "unable to load the plugin foo: no such file",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := newTestRegistry().Add("foo", test.descriptor)
+			if len(test.expectedErr) > 0 {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRegistry_Build(t *testing.T) {
+	registry := newTestRegistry()
+	err := registry.Add("header", &Descriptor{
+		Path:     "header.so",
+		Settings: map[string]string{"header": "X-Foo", "value": "plugin"},
+	})
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	testCases := []struct {
+		desc           string
+		pluginName     string
+		settings       map[string]string
+		expectedErr    bool
+		expectedHeader string
+	}{
+		{
+			desc:           "settings of the plugin",
+			pluginName:     "header",
+			expectedHeader: "plugin",
+		},
+		{
+			desc:           "settings of the middleware override the ones of the plugin",
+			pluginName:     "header",
+			settings:       map[string]string{"value": "middleware"},
+			expectedHeader: "middleware",
+		},
+		{
+			desc:        "error of the plugin",
+			pluginName:  "header",
+			settings:    map[string]string{"header": ""},
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown plugin",
+			pluginName:  "unknown",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := registry.Build(context.Background(), next, test.pluginName, test.settings, "foo")
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, http.StatusTeapot, recorder.Code)
+			assert.Equal(t, test.expectedHeader, recorder.Header().Get("X-Foo"))
+		})
+	}
+}
+
+func TestRegistry_BuildNil(t *testing.T) {
+	var registry *Registry
+
+	_, err := registry.Build(context.Background(), http.NotFoundHandler(), "header", nil, "foo")
+	assert.EqualError(t, err, `plugin "header" is not loaded`)
+}
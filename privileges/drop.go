@@ -0,0 +1,59 @@
+// +build !windows
+
+package privileges
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/containous/traefik/log"
+)
+
+// Drop switches the process to the configured user and group, dropping the supplementary groups,
+// and refuses to go on if the process can still run as root.
+// The switch from root also clears the capabilities of the process, such as CAP_NET_BIND_SERVICE.
+// Nothing is done without configuration.
+func Drop(config *Config) error {
+	if config == nil {
+		return nil
+	}
+
+	uid, gid, err := config.ids()
+	if err != nil {
+		return err
+	}
+
+	if os.Geteuid() == uid && os.Getuid() == uid && os.Getegid() == gid && os.Getgid() == gid {
+		log.WithoutContext().Debugf("Already running as the user %d and the group %d", uid, gid)
+		return nil
+	}
+
+	// The groups are changed first, as the user can't change them anymore.
+	if err := syscall.Setgroups([]int{gid}); err != nil {
+		return fmt.Errorf("unable to set the supplementary groups: %v", err)
+	}
+	if err := syscall.Setgid(gid); err != nil {
+		return fmt.Errorf("unable to set the group %d: %v", gid, err)
+	}
+	if err := syscall.Setuid(uid); err != nil {
+		return fmt.Errorf("unable to set the user %d: %v", uid, err)
+	}
+
+	return checkUnprivileged()
+}
+
+// checkUnprivileged checks that the process doesn't run as root, and can't get back to root.
+func checkUnprivileged() error {
+	if os.Getuid() == 0 || os.Geteuid() == 0 || os.Getgid() == 0 || os.Getegid() == 0 {
+		return errors.New("still running as root")
+	}
+
+	if err := syscall.Setuid(0); err == nil {
+		return errors.New("able to get back to root")
+	}
+
+	log.WithoutContext().Infof("Running as the user %d and the group %d", os.Getuid(), os.Getgid())
+	return nil
+}
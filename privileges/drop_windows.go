@@ -0,0 +1,13 @@
+// +build windows
+
+package privileges
+
+import "errors"
+
+// Drop is not supported on Windows, it fails when configured.
+func Drop(config *Config) error {
+	if config == nil {
+		return nil
+	}
+	return errors.New("unable to change the user on Windows")
+}
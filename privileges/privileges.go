@@ -0,0 +1,90 @@
+package privileges
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// Config holds the unprivileged user and group Traefik runs as once its entry points are bound,
+// so it can listen on the privileged ports without serving the requests as root.
+type Config struct {
+	User  string `description:"User, name or ID, Traefik runs as once the entry points are bound"`
+	Group string `description:"Group, name or ID, Traefik runs as, the primary group of the user by default"`
+}
+
+// ids resolves the IDs of the user and of the group.
+// The numeric IDs unknown to the system are used as is, the group must then be set.
+func (c *Config) ids() (int, int, error) {
+	if len(c.User) == 0 {
+		return 0, 0, errors.New("no user to run as")
+	}
+
+	var uid, gid int
+	if u, err := user.LookupId(c.User); err == nil {
+		uid, gid, err = userIDs(u)
+		if err != nil {
+			return 0, 0, err
+		}
+	} else if u, err := user.Lookup(c.User); err == nil {
+		uid, gid, err = userIDs(u)
+		if err != nil {
+			return 0, 0, err
+		}
+	} else if id, errID := strconv.Atoi(c.User); errID == nil {
+		if len(c.Group) == 0 {
+			return 0, 0, fmt.Errorf("the group of the unknown user %s must be set", c.User)
+		}
+		uid = id
+	} else {
+		return 0, 0, fmt.Errorf("unknown user %s: %v", c.User, err)
+	}
+
+	if len(c.Group) > 0 {
+		var err error
+		gid, err = groupID(c.Group)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if uid == 0 || gid == 0 {
+		return 0, 0, errors.New("the user and the group to run as must not be root")
+	}
+
+	return uid, gid, nil
+}
+
+func userIDs(u *user.User) (int, int, error) {
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unsupported ID %q of the user %s", u.Uid, u.Username)
+	}
+
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unsupported ID %q of the group of the user %s", u.Gid, u.Username)
+	}
+
+	return uid, gid, nil
+}
+
+func groupID(name string) (int, error) {
+	g, err := user.LookupGroupId(name)
+	if err != nil {
+		g, err = user.LookupGroup(name)
+	}
+	if err != nil {
+		if id, errID := strconv.Atoi(name); errID == nil {
+			return id, nil
+		}
+		return 0, fmt.Errorf("unknown group %s: %v", name, err)
+	}
+
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return 0, fmt.Errorf("unsupported ID %q of the group %s", g.Gid, name)
+	}
+	return gid, nil
+}
@@ -0,0 +1,76 @@
+package privileges
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDs(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      Config
+		expectedUID int
+		expectedGID int
+		expectedErr bool
+	}{
+		{
+			desc:        "no user",
+			config:      Config{Group: "54321"},
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown user",
+			config:      Config{User: "traefik-unknown-user"},
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown user ID without group",
+			config:      Config{User: "54321"},
+			expectedErr: true,
+		},
+		{
+			desc:        "unknown IDs",
+			config:      Config{User: "54321", Group: "54322"},
+			expectedUID: 54321,
+			expectedGID: 54322,
+		},
+		{
+			desc:        "unknown group",
+			config:      Config{User: "54321", Group: "traefik-unknown-group"},
+			expectedErr: true,
+		},
+		{
+			desc:        "root",
+			config:      Config{User: "0", Group: "0"},
+			expectedErr: true,
+		},
+		{
+			desc:        "root group",
+			config:      Config{User: "54321", Group: "0"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			uid, gid, err := test.config.ids()
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedUID, uid)
+			assert.Equal(t, test.expectedGID, gid)
+		})
+	}
+}
+
+func TestDropWithoutConfig(t *testing.T) {
+	assert.NoError(t, Drop(nil))
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"sync"
 
+	"github.com/containous/traefik/encryption"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/safe"
 )
@@ -20,11 +21,17 @@ type LocalStore struct {
 	storedData   *StoredData
 	SaveDataChan chan *StoredData `json:"-"`
 	lock         sync.RWMutex
+	cipher       *encryption.Cipher
 }
 
 // NewLocalStore initializes a new LocalStore with a file name
 func NewLocalStore(filename string) *LocalStore {
-	store := &LocalStore{filename: filename, SaveDataChan: make(chan *StoredData)}
+	return NewEncryptedLocalStore(filename, nil)
+}
+
+// NewEncryptedLocalStore initializes a new LocalStore with a file name, encrypting the file with the cipher.
+func NewEncryptedLocalStore(filename string, cipher *encryption.Cipher) *LocalStore {
+	store := &LocalStore{filename: filename, SaveDataChan: make(chan *StoredData), cipher: cipher}
 	store.listenSaveAction()
 	return store
 }
@@ -55,6 +62,11 @@ func (s *LocalStore) get() (*StoredData, error) {
 				return nil, err
 			}
 
+			file, err = s.cipher.Decrypt(file)
+			if err != nil {
+				return nil, err
+			}
+
 			if len(file) > 0 {
 				if err := json.Unmarshal(file, s.storedData); err != nil {
 					return nil, err
@@ -104,6 +116,12 @@ func (s *LocalStore) listenSaveAction() {
 				logger.Error(err)
 			}
 
+			data, err = s.cipher.Encrypt(data)
+			if err != nil {
+				logger.Errorf("Unable to encrypt the storage: %v", err)
+				continue
+			}
+
 			err = ioutil.WriteFile(s.filename, data, 0600)
 			if err != nil {
 				logger.Error(err)
@@ -112,6 +130,12 @@ func (s *LocalStore) listenSaveAction() {
 	})
 }
 
+// Check checks that the storage file is accessible.
+func (s *LocalStore) Check() error {
+	_, err := CheckFile(s.filename)
+	return err
+}
+
 // GetAccount returns ACME Account
 func (s *LocalStore) GetAccount() (*Account, error) {
 	storedData, err := s.get()
@@ -0,0 +1,55 @@
+package acme
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedLocalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acme")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	filename := filepath.Join(dir, "acme.json")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(`{"Account":{"Email":"admin@example.com"}}`), 0600))
+
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	// A plain storage is read as is, and encrypted by the next save.
+	store := NewEncryptedLocalStore(filename, cipher)
+	account, err := store.GetAccount()
+	require.NoError(t, err)
+	assert.Equal(t, "admin@example.com", account.Email)
+
+	require.NoError(t, store.SaveCertificates([]*Certificate{{Certificate: []byte("cert"), Key: []byte("key")}}))
+
+	var data []byte
+	for i := 0; i < 100; i++ {
+		data, err = ioutil.ReadFile(filename)
+		require.NoError(t, err)
+		if encryption.IsEncrypted(data) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.True(t, encryption.IsEncrypted(data))
+	assert.NotContains(t, string(data), "admin@example.com")
+
+	// The encrypted storage is read with the key only.
+	certificates, err := NewEncryptedLocalStore(filename, cipher).GetCertificates()
+	require.NoError(t, err)
+	require.Len(t, certificates, 1)
+	assert.Equal(t, []byte("cert"), certificates[0].Certificate)
+
+	_, err = NewLocalStore(filename).GetCertificates()
+	assert.Equal(t, encryption.ErrNoKey, err)
+}
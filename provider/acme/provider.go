@@ -16,7 +16,9 @@ import (
 
 	"github.com/cenk/backoff"
 	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/audit"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/events"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/rules"
 	"github.com/containous/traefik/safe"
@@ -181,6 +183,20 @@ func isAccountMatchingCaServer(ctx context.Context, accountURI string, serverURI
 	return cau.Hostname() == aru.Hostname()
 }
 
+// CheckStore reports an error when the ACME storage is not accessible.
+func (p *Provider) CheckStore() error {
+	if p.Store == nil {
+		return errors.New("no store found for the ACME provider")
+	}
+
+	if checker, ok := p.Store.(interface{ Check() error }); ok {
+		return checker.Check()
+	}
+
+	_, err := p.Store.GetAccount()
+	return err
+}
+
 // Provide allows the file provider to provide configurations to traefik
 // using the given Configuration channel.
 func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
@@ -262,6 +278,7 @@ func (p *Provider) getClient() (*acme.Client, error) {
 		}
 
 		account.Registration = reg
+		audit.Record(audit.Entry{Category: audit.CategoryACME, Action: "register", User: account.Email, Resource: reg.URI, Details: map[string]string{"caServer": caServer}})
 	}
 
 	// Save the account once before all the certificates generation/storing
@@ -667,6 +684,7 @@ func (p *Provider) renewCertificates(ctx context.Context) {
 			client, err := p.getClient()
 			if err != nil {
 				logger.Infof("Error renewing certificate from LE : %+v, %v", certificate.Domain, err)
+				publishRenewalFailed(certificate.Domain, err)
 				continue
 			}
 
@@ -680,19 +698,26 @@ func (p *Provider) renewCertificates(ctx context.Context) {
 
 			if err != nil {
 				logger.Errorf("Error renewing certificate from LE: %v, %v", certificate.Domain, err)
+				publishRenewalFailed(certificate.Domain, err)
 				continue
 			}
 
 			if len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0 {
 				logger.Errorf("domains %v renew certificate with no value: %v", certificate.Domain.ToStrArray(), certificate)
+				publishRenewalFailed(certificate.Domain, errors.New("empty certificate"))
 				continue
 			}
 
 			p.addCertificateForDomain(certificate.Domain, renewedCert.Certificate, renewedCert.PrivateKey)
+			events.Publish(events.CertificateRenewed, map[string]string{"domains": strings.Join(certificate.Domain.ToStrArray(), ",")})
 		}
 	}
 }
 
+func publishRenewalFailed(domain types.Domain, err error) {
+	events.Publish(events.CertificateRenewalFailed, map[string]string{"domains": strings.Join(domain.ToStrArray(), ","), "reason": err.Error()})
+}
+
 // Get provided certificate which check a domains list (Main and SANs)
 // from static and dynamic provided certificates
 func (p *Provider) getUncheckedDomains(ctx context.Context, domainsToCheck []string, checkConfigurationDomains bool) []string {
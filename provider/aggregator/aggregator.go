@@ -2,6 +2,10 @@ package aggregator
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/config/static"
@@ -13,11 +17,20 @@ import (
 // ProviderAggregator aggregates providers.
 type ProviderAggregator struct {
 	providers []provider.Provider
+	failures  *providerFailures
+}
+
+// providerFailures holds the errors of the providers which could not be started.
+type providerFailures struct {
+	lock sync.RWMutex
+	errs map[string]error
 }
 
 // NewProviderAggregator returns an aggregate of all the providers configured in the static configuration.
 func NewProviderAggregator(conf static.Providers) ProviderAggregator {
-	p := ProviderAggregator{}
+	p := ProviderAggregator{
+		failures: &providerFailures{errs: make(map[string]error)},
+	}
 
 	if conf.File != nil {
 		p.quietAddProvider(conf.File)
@@ -27,6 +40,18 @@ func NewProviderAggregator(conf static.Providers) ProviderAggregator {
 		p.quietAddProvider(conf.Rest)
 	}
 
+	if conf.GRPC != nil {
+		p.quietAddProvider(conf.GRPC)
+	}
+
+	if conf.KubernetesCRD != nil {
+		p.quietAddProvider(conf.KubernetesCRD)
+	}
+
+	if conf.DNS != nil {
+		p.quietAddProvider(conf.DNS)
+	}
+
 	return p
 }
 
@@ -65,8 +90,42 @@ func (p ProviderAggregator) Provide(configurationChan chan<- config.Message, poo
 			err := currentProvider.Provide(configurationChan, pool)
 			if err != nil {
 				log.WithoutContext().Errorf("Cannot start the provider %T: %v", prd, err)
+				p.setFailure(currentProvider, err)
 			}
 		})
 	}
 	return nil
 }
+
+// Check reports an error when some providers could not be started.
+func (p ProviderAggregator) Check() error {
+	if p.failures == nil {
+		return nil
+	}
+
+	p.failures.lock.RLock()
+	defer p.failures.lock.RUnlock()
+
+	if len(p.failures.errs) == 0 {
+		return nil
+	}
+
+	var errs []string
+	for name, err := range p.failures.errs {
+		errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+	}
+	sort.Strings(errs)
+
+	return fmt.Errorf("providers not started: %s", strings.Join(errs, ", "))
+}
+
+func (p ProviderAggregator) setFailure(prd provider.Provider, err error) {
+	if p.failures == nil {
+		return
+	}
+
+	p.failures.lock.Lock()
+	defer p.failures.lock.Unlock()
+
+	p.failures.errs[fmt.Sprintf("%T", prd)] = err
+}
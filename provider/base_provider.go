@@ -59,6 +59,15 @@ func (p *BaseProvider) GetConfiguration(defaultTemplate string, funcMap template
 
 // CreateConfiguration creates a provider configuration from content using templating.
 func (p *BaseProvider) CreateConfiguration(tmplContent string, funcMap template.FuncMap, templateObjects interface{}) (*config.Configuration, error) {
+	renderedTemplate, err := p.RenderTemplate(tmplContent, funcMap, templateObjects)
+	if err != nil {
+		return nil, err
+	}
+	return p.DecodeConfiguration(renderedTemplate)
+}
+
+// RenderTemplate renders a template content with the default functions and the given ones.
+func (p *BaseProvider) RenderTemplate(tmplContent string, funcMap template.FuncMap, templateObjects interface{}) (string, error) {
 	var defaultFuncMap = sprig.TxtFuncMap()
 	// tolower is deprecated in favor of sprig's lower function
 	defaultFuncMap["tolower"] = strings.ToLower
@@ -72,13 +81,13 @@ func (p *BaseProvider) CreateConfiguration(tmplContent string, funcMap template.
 
 	_, err := tmpl.Parse(tmplContent)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var buffer bytes.Buffer
 	err = tmpl.Execute(&buffer, templateObjects)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var renderedTemplate = buffer.String()
@@ -86,7 +95,7 @@ func (p *BaseProvider) CreateConfiguration(tmplContent string, funcMap template.
 		log.Debugf("Template content: %s", tmplContent)
 		log.Debugf("Rendering results: %s", renderedTemplate)
 	}
-	return p.DecodeConfiguration(renderedTemplate)
+	return renderedTemplate, nil
 }
 
 // DecodeConfiguration Decodes a *types.Configuration from a content.
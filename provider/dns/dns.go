@@ -0,0 +1,216 @@
+package dns
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/provider"
+	"github.com/containous/traefik/safe"
+)
+
+const (
+	providerName      = "dns"
+	defaultMinRefresh = 5 * time.Second
+)
+
+const (
+	// RecordTypeSRV discovers the servers of a service from its SRV records.
+	RecordTypeSRV = "SRV"
+	// RecordTypeA discovers the servers of a service from its A records.
+	RecordTypeA = "A"
+)
+
+var _ provider.Provider = (*Provider)(nil)
+
+// Service is a service whose servers are discovered from DNS records.
+type Service struct {
+	Name   string `json:"name,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Port   int    `json:"port,omitempty"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// Provider is a provider.Provider implementation that periodically resolves DNS records
+// to build the list of servers of the configured services.
+// A service is resolved again when the TTL of its records expires, within the bounds of MinRefresh and MaxRefresh.
+type Provider struct {
+	ResolvConfig string         `description:"resolv.conf file used to find the DNS servers" export:"true"`
+	MinRefresh   parse.Duration `description:"Minimum duration between two resolutions of a service" export:"true"`
+	MaxRefresh   parse.Duration `description:"Maximum duration between two resolutions of a service" export:"true"`
+	MaxDropRatio float64        `description:"Ratio (between 0 and 1) of the servers of a service a resolution can drop: larger drops are applied only when the next resolution confirms them" export:"true"`
+	Services     map[string]*Service
+
+	resolver resolver
+
+	lock    sync.Mutex
+	servers map[string][]config.Server
+	pending map[string][]config.Server
+}
+
+// Init the provider.
+func (p *Provider) Init() error {
+	for name, service := range p.Services {
+		if len(service.Name) == 0 {
+			return fmt.Errorf("no record name defined for the service %s", name)
+		}
+
+		switch service.Type {
+		case RecordTypeSRV, "":
+		case RecordTypeA:
+			if service.Port <= 0 {
+				return fmt.Errorf("a port is required for the service %s, as A records do not define one", name)
+			}
+		default:
+			return fmt.Errorf("unknown record type %q for the service %s", service.Type, name)
+		}
+	}
+
+	if p.MinRefresh <= 0 {
+		p.MinRefresh = parse.Duration(defaultMinRefresh)
+	}
+
+	if p.MaxDropRatio < 0 || p.MaxDropRatio > 1 {
+		return fmt.Errorf("the max drop ratio must be between 0 and 1: %v", p.MaxDropRatio)
+	}
+
+	if p.resolver == nil {
+		dnsResolver, err := newDNSResolver(p.ResolvConfig)
+		if err != nil {
+			return err
+		}
+		p.resolver = dnsResolver
+	}
+
+	p.servers = make(map[string][]config.Server)
+	p.pending = make(map[string][]config.Server)
+
+	return nil
+}
+
+// Provide allows the provider to provide configurations to traefik
+// using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
+	for name, service := range p.Services {
+		name, service := name, service
+		pool.Go(func(stop chan bool) {
+			p.watch(name, service, configurationChan, stop)
+		})
+	}
+	return nil
+}
+
+func (p *Provider) watch(name string, service *Service, configurationChan chan<- config.Message, stop chan bool) {
+	logger := log.WithoutContext().WithField(log.ProviderName, providerName)
+
+	for {
+		servers, ttl, err := p.resolver.resolve(service)
+		if err != nil {
+			logger.Errorf("Unable to resolve the servers of the service %s, keeping the current ones: %v", name, err)
+		} else if p.update(name, servers) {
+			select {
+			case configurationChan <- config.Message{ProviderName: providerName, Configuration: p.buildConfiguration()}:
+			case <-stop:
+				return
+			}
+		}
+
+		timer := time.NewTimer(p.refreshInterval(ttl))
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// update records the servers resolved for the service, and returns true if the configuration has changed.
+// A resolution dropping more than MaxDropRatio of the servers is applied only if the next one returns the same servers,
+// so a transient DNS failure doesn't remove all the servers of a service.
+func (p *Provider) update(name string, servers []config.Server) bool {
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].URL < servers[j].URL
+	})
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	current, exists := p.servers[name]
+	if exists && reflect.DeepEqual(current, servers) {
+		delete(p.pending, name)
+		return false
+	}
+
+	if ratio := dropRatio(current, servers); exists && ratio > p.MaxDropRatio {
+		if pending, ok := p.pending[name]; !ok || !reflect.DeepEqual(pending, servers) {
+			log.WithoutContext().WithField(log.ProviderName, providerName).
+				Warnf("The resolution of the service %s drops %.0f%% of its servers, waiting for the next one to confirm it", name, ratio*100)
+			p.pending[name] = servers
+			return false
+		}
+	}
+
+	delete(p.pending, name)
+	p.servers[name] = servers
+	return true
+}
+
+func (p *Provider) buildConfiguration() *config.Configuration {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	configuration := &config.Configuration{
+		Routers:     make(map[string]*config.Router),
+		Middlewares: make(map[string]*config.Middleware),
+		Services:    make(map[string]*config.Service),
+	}
+
+	for name, servers := range p.servers {
+		configuration.Services[name] = &config.Service{
+			LoadBalancer: &config.LoadBalancerService{
+				Servers:        servers,
+				Method:         "wrr",
+				PassHostHeader: true,
+			},
+		}
+	}
+
+	return configuration
+}
+
+func (p *Provider) refreshInterval(ttl time.Duration) time.Duration {
+	if ttl < time.Duration(p.MinRefresh) {
+		return time.Duration(p.MinRefresh)
+	}
+	if p.MaxRefresh > 0 && ttl > time.Duration(p.MaxRefresh) {
+		return time.Duration(p.MaxRefresh)
+	}
+	return ttl
+}
+
+// dropRatio returns the ratio of the current servers missing from the new ones.
+func dropRatio(current, servers []config.Server) float64 {
+	if len(current) == 0 {
+		return 0
+	}
+
+	urls := make(map[string]bool)
+	for _, server := range servers {
+		urls[server.URL] = true
+	}
+
+	var dropped int
+	for _, server := range current {
+		if !urls[server.URL] {
+			dropped++
+		}
+	}
+
+	return float64(dropped) / float64(len(current))
+}
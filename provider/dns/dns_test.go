@@ -0,0 +1,179 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/safe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type resolution struct {
+	servers []config.Server
+	err     error
+}
+
+type fakeResolver struct {
+	resolutions chan resolution
+}
+
+func (r *fakeResolver) resolve(service *Service) ([]config.Server, time.Duration, error) {
+	res := <-r.resolutions
+	return res.servers, 0, res.err
+}
+
+func servers(urls ...string) []config.Server {
+	var result []config.Server
+	for _, url := range urls {
+		result = append(result, config.Server{URL: url, Weight: 1})
+	}
+	return result
+}
+
+func TestInit(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		provider    *Provider
+		expectedErr string
+	}{
+		{
+			desc: "SRV service",
+			provider: &Provider{
+				Services: map[string]*Service{"foo": {Name: "_http._tcp.foo.local"}},
+			},
+		},
+		{
+			desc: "A service",
+			provider: &Provider{
+				Services: map[string]*Service{"foo": {Name: "foo.local", Type: RecordTypeA, Port: 80}},
+			},
+		},
+		{
+			desc: "A service without port",
+			provider: &Provider{
+				Services: map[string]*Service{"foo": {Name: "foo.local", Type: RecordTypeA}},
+			},
+			expectedErr: "a port is required for the service foo, as A records do not define one",
+		},
+		{
+			desc: "unknown record type",
+			provider: &Provider{
+				Services: map[string]*Service{"foo": {Name: "foo.local", Type: "MX"}},
+			},
+			expectedErr: `unknown record type "MX" for the service foo`,
+		},
+		{
+			desc: "service without record name",
+			provider: &Provider{
+				Services: map[string]*Service{"foo": {}},
+			},
+			expectedErr: "no record name defined for the service foo",
+		},
+		{
+			desc: "invalid max drop ratio",
+			provider: &Provider{
+				MaxDropRatio: 2,
+			},
+			expectedErr: "the max drop ratio must be between 0 and 1: 2",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.provider.resolver = &fakeResolver{}
+
+			err := test.provider.Init()
+			if len(test.expectedErr) > 0 {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, parse.Duration(defaultMinRefresh), test.provider.MinRefresh)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	p := &Provider{MaxDropRatio: 0.5, resolver: &fakeResolver{}}
+	require.NoError(t, p.Init())
+
+	assert.True(t, p.update("foo", servers("http://10.0.0.2:80", "http://10.0.0.1:80", "http://10.0.0.3:80", "http://10.0.0.4:80")))
+	assert.Equal(t, servers("http://10.0.0.1:80", "http://10.0.0.2:80", "http://10.0.0.3:80", "http://10.0.0.4:80"), p.servers["foo"])
+
+	// Same servers.
+	assert.False(t, p.update("foo", servers("http://10.0.0.1:80", "http://10.0.0.2:80", "http://10.0.0.3:80", "http://10.0.0.4:80")))
+
+	// Dropping half of the servers is allowed.
+	assert.True(t, p.update("foo", servers("http://10.0.0.1:80", "http://10.0.0.2:80")))
+
+	// Dropping all the servers must be confirmed by the next resolution.
+	assert.False(t, p.update("foo", nil))
+	assert.Equal(t, servers("http://10.0.0.1:80", "http://10.0.0.2:80"), p.servers["foo"])
+
+	// The servers come back: the drop is forgotten.
+	assert.False(t, p.update("foo", servers("http://10.0.0.1:80", "http://10.0.0.2:80")))
+	assert.False(t, p.update("foo", nil))
+
+	// The drop is confirmed.
+	assert.True(t, p.update("foo", nil))
+	assert.Empty(t, p.servers["foo"])
+}
+
+func TestRefreshInterval(t *testing.T) {
+	p := &Provider{
+		MinRefresh: parse.Duration(5 * time.Second),
+		MaxRefresh: parse.Duration(time.Minute),
+	}
+
+	assert.Equal(t, 5*time.Second, p.refreshInterval(0))
+	assert.Equal(t, 30*time.Second, p.refreshInterval(30*time.Second))
+	assert.Equal(t, time.Minute, p.refreshInterval(time.Hour))
+}
+
+func TestProvide(t *testing.T) {
+	resolver := &fakeResolver{resolutions: make(chan resolution, 1)}
+
+	p := &Provider{
+		MinRefresh: parse.Duration(time.Millisecond),
+		Services:   map[string]*Service{"foo": {Name: "_http._tcp.foo.local"}},
+		resolver:   resolver,
+	}
+	require.NoError(t, p.Init())
+
+	configurationChan := make(chan config.Message)
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+	// Unblocks the pending resolution before stopping the pool.
+	defer close(resolver.resolutions)
+
+	require.NoError(t, p.Provide(configurationChan, pool))
+
+	resolver.resolutions <- resolution{servers: servers("http://10.0.0.1:80")}
+
+	select {
+	case msg := <-configurationChan:
+		assert.Equal(t, providerName, msg.ProviderName)
+		require.Contains(t, msg.Configuration.Services, "foo")
+		assert.Equal(t, servers("http://10.0.0.1:80"), msg.Configuration.Services["foo"].LoadBalancer.Servers)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the configuration")
+	}
+
+	// A failed resolution keeps the current servers, and sends nothing.
+	resolver.resolutions <- resolution{err: assert.AnError}
+	resolver.resolutions <- resolution{servers: servers("http://10.0.0.1:80", "http://10.0.0.2:80")}
+
+	select {
+	case msg := <-configurationChan:
+		assert.Equal(t, servers("http://10.0.0.1:80", "http://10.0.0.2:80"), msg.Configuration.Services["foo"].LoadBalancer.Servers)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the configuration")
+	}
+}
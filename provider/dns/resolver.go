@@ -0,0 +1,185 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/miekg/dns"
+)
+
+const defaultResolvConfig = "/etc/resolv.conf"
+
+// resolver resolves the servers of a service, and returns how long the result can be cached.
+type resolver interface {
+	resolve(service *Service) ([]config.Server, time.Duration, error)
+}
+
+type dnsResolver struct {
+	servers []string
+	client  *dns.Client
+}
+
+func newDNSResolver(resolvConfig string) (*dnsResolver, error) {
+	if len(resolvConfig) == 0 {
+		resolvConfig = defaultResolvConfig
+	}
+
+	conf, err := dns.ClientConfigFromFile(resolvConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolver configuration file %s: %v", resolvConfig, err)
+	}
+
+	r := &dnsResolver{client: &dns.Client{Timeout: 10 * time.Second}}
+	for _, server := range conf.Servers {
+		r.servers = append(r.servers, net.JoinHostPort(server, conf.Port))
+	}
+
+	return r, nil
+}
+
+func (r *dnsResolver) resolve(service *Service) ([]config.Server, time.Duration, error) {
+	scheme := service.Scheme
+	if len(scheme) == 0 {
+		scheme = "http"
+	}
+
+	if service.Type == RecordTypeA {
+		addresses, ttl, err := r.resolveA(service.Name, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var servers []config.Server
+		for _, address := range addresses {
+			servers = append(servers, config.Server{
+				URL:    scheme + "://" + net.JoinHostPort(address, strconv.Itoa(service.Port)),
+				Weight: 1,
+			})
+		}
+		return servers, ttl, nil
+	}
+
+	return r.resolveSRV(service.Name, scheme)
+}
+
+// resolveSRV returns a server for each address of the targets with the lowest priority.
+func (r *dnsResolver) resolveSRV(name, scheme string) ([]config.Server, time.Duration, error) {
+	resp, err := r.query(name, dns.TypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var records []*dns.SRV
+	for _, rr := range resp.Answer {
+		if srv, ok := rr.(*dns.SRV); ok {
+			if len(records) > 0 && srv.Priority > records[0].Priority {
+				continue
+			}
+			if len(records) > 0 && srv.Priority < records[0].Priority {
+				records = nil
+			}
+			records = append(records, srv)
+		}
+	}
+
+	ttl := minTTL(resp.Answer)
+
+	var servers []config.Server
+	for _, srv := range records {
+		addresses, addressesTTL, err := r.resolveA(srv.Target, resp.Extra)
+		if err != nil {
+			return nil, 0, err
+		}
+		if addressesTTL < ttl {
+			ttl = addressesTTL
+		}
+
+		weight := int(srv.Weight)
+		if weight == 0 {
+			weight = 1
+		}
+
+		for _, address := range addresses {
+			servers = append(servers, config.Server{
+				URL:    scheme + "://" + net.JoinHostPort(address, strconv.Itoa(int(srv.Port))),
+				Weight: weight,
+			})
+		}
+	}
+
+	return servers, ttl, nil
+}
+
+// resolveA returns the addresses of the name, from the additional records of a previous answer if they are there.
+func (r *dnsResolver) resolveA(name string, extra []dns.RR) ([]string, time.Duration, error) {
+	records := aRecords(name, extra)
+	if len(records) == 0 {
+		resp, err := r.query(name, dns.TypeA)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = aRecords(name, resp.Answer)
+	}
+
+	var addresses []string
+	var rrs []dns.RR
+	for _, record := range records {
+		addresses = append(addresses, record.A.String())
+		rrs = append(rrs, record)
+	}
+
+	return addresses, minTTL(rrs), nil
+}
+
+// query sends the question to the DNS servers in turn, until one of them answers.
+func (r *dnsResolver) query(name string, qtype uint16) (*dns.Msg, error) {
+	if len(r.servers) == 0 {
+		return nil, fmt.Errorf("no DNS server to resolve %s", name)
+	}
+
+	msg := &dns.Msg{}
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+
+	var errs []string
+	for _, server := range r.servers {
+		resp, _, err := r.client.Exchange(msg, server)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", server, err))
+			continue
+		}
+
+		if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+			errs = append(errs, fmt.Sprintf("%s: %s", server, dns.RcodeToString[resp.Rcode]))
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("unable to resolve %s %s: %s", dns.TypeToString[qtype], name, strings.Join(errs, ", "))
+}
+
+func aRecords(name string, rrs []dns.RR) []*dns.A {
+	var records []*dns.A
+	for _, rr := range rrs {
+		if a, ok := rr.(*dns.A); ok && strings.EqualFold(a.Hdr.Name, dns.Fqdn(name)) {
+			records = append(records, a)
+		}
+	}
+	return records
+}
+
+func minTTL(rrs []dns.RR) time.Duration {
+	var ttl time.Duration
+	for i, rr := range rrs {
+		rrTTL := time.Duration(rr.Header().Ttl) * time.Second
+		if i == 0 || rrTTL < ttl {
+			ttl = rrTTL
+		}
+	}
+	return ttl
+}
@@ -0,0 +1,143 @@
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRR(t *testing.T, record string) dns.RR {
+	t.Helper()
+
+	rr, err := dns.NewRR(record)
+	require.NoError(t, err)
+	return rr
+}
+
+// startDNSServer starts a DNS server answering with the given records, and the given additional records for SRV questions.
+func startDNSServer(t *testing.T, answers map[uint16][]dns.RR, extra []dns.RR) (string, func()) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{
+		PacketConn: pc,
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := &dns.Msg{}
+			m.SetReply(r)
+
+			q := r.Question[0]
+			for _, rr := range answers[q.Qtype] {
+				if rr.Header().Name == q.Name {
+					m.Answer = append(m.Answer, rr)
+				}
+			}
+			if q.Qtype == dns.TypeSRV {
+				m.Extra = extra
+			}
+			if len(m.Answer) == 0 {
+				m.Rcode = dns.RcodeNameError
+			}
+
+			_ = w.WriteMsg(m)
+		}),
+	}
+
+	started := make(chan struct{})
+	server.NotifyStartedFunc = func() { close(started) }
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+
+	return pc.LocalAddr().String(), func() { _ = server.Shutdown() }
+}
+
+func TestResolve(t *testing.T) {
+	answers := map[uint16][]dns.RR{
+		dns.TypeSRV: {
+			newRR(t, "_http._tcp.foo.local. 60 IN SRV 10 5 8080 a.foo.local."),
+			newRR(t, "_http._tcp.foo.local. 30 IN SRV 10 0 8081 b.foo.local."),
+			newRR(t, "_http._tcp.foo.local. 60 IN SRV 20 5 8082 c.foo.local."),
+		},
+		dns.TypeA: {
+			newRR(t, "b.foo.local. 20 IN A 10.0.0.2"),
+			newRR(t, "c.foo.local. 60 IN A 10.0.0.3"),
+			newRR(t, "bar.local. 45 IN A 10.0.1.1"),
+			newRR(t, "bar.local. 40 IN A 10.0.1.2"),
+		},
+	}
+	extra := []dns.RR{
+		newRR(t, "a.foo.local. 60 IN A 10.0.0.1"),
+	}
+
+	address, shutdown := startDNSServer(t, answers, extra)
+	defer shutdown()
+
+	r := &dnsResolver{
+		servers: []string{address},
+		client:  &dns.Client{Timeout: 5 * time.Second},
+	}
+
+	testCases := []struct {
+		desc            string
+		service         *Service
+		expectedServers []config.Server
+		expectedTTL     time.Duration
+		expectedErr     bool
+	}{
+		{
+			desc:    "SRV records with the lowest priority",
+			service: &Service{Name: "_http._tcp.foo.local"},
+			expectedServers: []config.Server{
+				{URL: "http://10.0.0.1:8080", Weight: 5},
+				{URL: "http://10.0.0.2:8081", Weight: 1},
+			},
+			expectedTTL: 20 * time.Second,
+		},
+		{
+			desc:    "A records",
+			service: &Service{Name: "bar.local", Type: RecordTypeA, Port: 443, Scheme: "https"},
+			expectedServers: []config.Server{
+				{URL: "https://10.0.1.1:443", Weight: 1},
+				{URL: "https://10.0.1.2:443", Weight: 1},
+			},
+			expectedTTL: 40 * time.Second,
+		},
+		{
+			desc:    "unknown name",
+			service: &Service{Name: "_http._tcp.unknown.local"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			servers, ttl, err := r.resolve(test.service)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedServers, servers)
+			assert.Equal(t, test.expectedTTL, ttl)
+		})
+	}
+}
+
+func TestResolveUnreachableServer(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	r := &dnsResolver{
+		servers: []string{pc.LocalAddr().String()},
+		client:  &dns.Client{Timeout: 100 * time.Millisecond},
+	}
+
+	_, _, err = r.resolve(&Service{Name: "_http._tcp.foo.local"})
+	assert.Error(t, err)
+}
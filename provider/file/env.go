@@ -0,0 +1,48 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envVarRegexp matches the ${VAR} and ${VAR:-default} references, and the $${ escape sequence.
+var envVarRegexp = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolateEnv replaces the environment variable references of a content by their values.
+// A reference to an unset variable without default value is an error.
+func interpolateEnv(content string) (string, error) {
+	missing := make(map[string]struct{})
+
+	result := envVarRegexp.ReplaceAllStringFunc(content, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		groups := envVarRegexp.FindStringSubmatch(match)
+		name, hasDefault, defaultValue := groups[1], len(groups[2]) > 0, groups[3]
+
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+
+		if !hasDefault {
+			missing[name] = struct{}{}
+		}
+		return defaultValue
+	})
+
+	if len(missing) > 0 {
+		var names []string
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return "", fmt.Errorf("environment variables not set: %s", strings.Join(names, ", "))
+	}
+
+	return result, nil
+}
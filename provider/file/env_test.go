@@ -0,0 +1,71 @@
+package file
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_HOST", "backend.local"))
+	defer os.Unsetenv("TRAEFIK_TEST_HOST")
+
+	testCases := []struct {
+		desc        string
+		content     string
+		expected    string
+		expectedErr string
+	}{
+		{
+			desc:     "no reference",
+			content:  `url = "http://127.0.0.1"`,
+			expected: `url = "http://127.0.0.1"`,
+		},
+		{
+			desc:     "set variable",
+			content:  `url = "http://${TRAEFIK_TEST_HOST}:8080"`,
+			expected: `url = "http://backend.local:8080"`,
+		},
+		{
+			desc:     "set variable with default",
+			content:  `url = "http://${TRAEFIK_TEST_HOST:-127.0.0.1}"`,
+			expected: `url = "http://backend.local"`,
+		},
+		{
+			desc:     "unset variable with default",
+			content:  `url = "http://${TRAEFIK_TEST_UNSET:-127.0.0.1}"`,
+			expected: `url = "http://127.0.0.1"`,
+		},
+		{
+			desc:     "unset variable with empty default",
+			content:  `password = "${TRAEFIK_TEST_UNSET:-}"`,
+			expected: `password = ""`,
+		},
+		{
+			desc:     "escaped reference",
+			content:  `rule = "$${TRAEFIK_TEST_HOST}"`,
+			expected: `rule = "${TRAEFIK_TEST_HOST}"`,
+		},
+		{
+			desc:        "unset variables",
+			content:     `url = "http://${TRAEFIK_TEST_UNSET_B}:${TRAEFIK_TEST_UNSET_A}"`,
+			expectedErr: "environment variables not set: TRAEFIK_TEST_UNSET_A, TRAEFIK_TEST_UNSET_B",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			result, err := interpolateEnv(test.content)
+			if len(test.expectedErr) > 0 {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -5,12 +5,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/format"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/provider"
 	"github.com/containous/traefik/safe"
@@ -26,7 +26,8 @@ var _ provider.Provider = (*Provider)(nil)
 // Provider holds configurations of the provider.
 type Provider struct {
 	provider.BaseProvider `mapstructure:",squash" export:"true"`
-	Directory             string `description:"Load configuration from one or more .toml files in a directory" export:"true"`
+	Directory             string   `description:"Load configuration from one or more .toml, .yaml or .json files in a directory tree" export:"true"`
+	Include               []string `description:"Glob patterns of the files loaded from the directory tree, matched against their relative path or name" export:"true"`
 	TraefikFile           string
 }
 
@@ -90,7 +91,7 @@ func (p *Provider) addWatcher(pool *safe.Pool, directory string, configurationCh
 		return fmt.Errorf("error creating file watcher: %s", err)
 	}
 
-	err = watcher.Add(directory)
+	err = p.addWatchedDirectories(watcher, directory)
 	if err != nil {
 		return fmt.Errorf("error adding file watcher: %s", err)
 	}
@@ -103,6 +104,15 @@ func (p *Provider) addWatcher(pool *safe.Pool, directory string, configurationCh
 			case <-stop:
 				return
 			case evt := <-watcher.Events:
+				if p.Directory != "" && evt.Op&fsnotify.Create == fsnotify.Create {
+					// Watch the directories created in the tree
+					if info, err := os.Stat(evt.Name); err == nil && info.IsDir() {
+						if err := p.addWatchedDirectories(watcher, evt.Name); err != nil {
+							log.WithoutContext().WithField(log.ProviderName, providerName).Errorf("Unable to watch %s: %v", evt.Name, err)
+						}
+					}
+				}
+
 				if p.Directory == "" {
 					var filename string
 					if len(p.Filename) > 0 {
@@ -127,6 +137,23 @@ func (p *Provider) addWatcher(pool *safe.Pool, directory string, configurationCh
 	return nil
 }
 
+// addWatchedDirectories adds the directory to the watcher, and all its subdirectories when it is the configuration directory tree.
+func (p *Provider) addWatchedDirectories(watcher *fsnotify.Watcher, directory string) error {
+	if p.Directory == "" {
+		return watcher.Add(directory)
+	}
+
+	return filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		return watcher.Add(path)
+	})
+}
+
 func (p *Provider) watcherCallback(configurationChan chan<- config.Message, event fsnotify.Event) {
 	watchItem := p.TraefikFile
 	if len(p.Directory) > 0 {
@@ -175,25 +202,58 @@ func (p *Provider) loadFileConfig(filename string, parseTemplate bool) (*config.
 		return nil, fmt.Errorf("error reading configuration file: %s - %s", filename, err)
 	}
 
-	var configuration *config.Configuration
 	if parseTemplate {
-		configuration, err = p.CreateConfiguration(fileContent, template.FuncMap{}, false)
-	} else {
-		configuration, err = p.DecodeConfiguration(fileContent)
+		fileContent, err = p.RenderTemplate(fileContent, template.FuncMap{}, false)
+		if err != nil {
+			return nil, err
+		}
+
+		fileContent, err = interpolateEnv(fileContent)
+		if err != nil {
+			return nil, fmt.Errorf("error reading configuration file: %s - %s", filename, err)
+		}
 	}
 
-	if err != nil {
-		return nil, err
+	configuration := new(config.Configuration)
+	if err := format.Decode(format.FromFilename(filename), []byte(fileContent), configuration); err != nil {
+		return nil, fmt.Errorf("error decoding configuration file %s: %v", filename, err)
 	}
 
-	if configuration == nil || configuration.Routers == nil && configuration.Middlewares == nil && configuration.Services == nil && configuration.TLS == nil {
+	if configuration.Routers == nil && configuration.Middlewares == nil && configuration.Services == nil && configuration.TLS == nil {
 		configuration = &config.Configuration{
 			Routers:     make(map[string]*config.Router),
 			Middlewares: make(map[string]*config.Middleware),
 			Services:    make(map[string]*config.Service),
 		}
 	}
-	return configuration, err
+	return configuration, nil
+}
+
+// isIncluded returns true if a file of the configuration directory tree must be loaded.
+func (p *Provider) isIncluded(filename string) bool {
+	if len(p.Include) == 0 {
+		for _, ext := range []string{".toml", ".yml", ".yaml", ".json", ".tmpl"} {
+			if strings.HasSuffix(filename, ext) {
+				return true
+			}
+		}
+		return false
+	}
+
+	relPath, err := filepath.Rel(p.Directory, filename)
+	if err != nil {
+		relPath = filename
+	}
+
+	for _, pattern := range p.Include {
+		if matched, _ := filepath.Match(pattern, relPath); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(filename)); matched {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory string, configuration *config.Configuration) (*config.Configuration, error) {
@@ -222,12 +282,12 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 				return configuration, fmt.Errorf("unable to load content configuration from subdirectory %s: %v", item, err)
 			}
 			continue
-		} else if !strings.HasSuffix(item.Name(), ".toml") && !strings.HasSuffix(item.Name(), ".tmpl") {
+		} else if !p.isIncluded(filepath.Join(directory, item.Name())) {
 			continue
 		}
 
 		var c *config.Configuration
-		c, err = p.loadFileConfig(path.Join(directory, item.Name()), true)
+		c, err = p.loadFileConfig(filepath.Join(directory, item.Name()), true)
 
 		if err != nil {
 			return configuration, err
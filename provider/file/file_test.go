@@ -6,12 +6,14 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/containous/traefik/config"
 	"github.com/containous/traefik/safe"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type ProvideTestCase struct {
@@ -133,6 +135,74 @@ func TestErrorWhenEmptyConfig(t *testing.T) {
 	}
 }
 
+func TestBuildConfigurationFromDirectoryTree(t *testing.T) {
+	require.NoError(t, os.Setenv("TRAEFIK_TEST_BACKEND", "172.17.0.2"))
+	defer os.Unsetenv("TRAEFIK_TEST_BACKEND")
+
+	tempDir := createTempDir(t, "testdir")
+	defer os.RemoveAll(tempDir)
+
+	subDir := filepath.Join(tempDir, "services", "internal")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	createFile(t, tempDir, "routers.toml", createRoutersConfiguration(2))
+	createFile(t, subDir, "services.yaml", `
+services:
+  application-1:
+    loadbalancer:
+      servers:
+        - url: http://${TRAEFIK_TEST_BACKEND}:80
+          weight: 1
+  application-2:
+    loadbalancer:
+      servers:
+        - url: http://${TRAEFIK_TEST_UNSET:-127.0.0.1}:80
+          weight: 1
+`)
+	createFile(t, tempDir, "ignored.toml.bak", createRoutersConfiguration(5))
+
+	testCases := []struct {
+		desc               string
+		include            []string
+		expectedNumRouter  int
+		expectedNumService int
+	}{
+		{
+			desc:               "default include",
+			expectedNumRouter:  2,
+			expectedNumService: 2,
+		},
+		{
+			desc:               "include by name",
+			include:            []string{"*.yaml"},
+			expectedNumService: 2,
+		},
+		{
+			desc:              "include by relative path",
+			include:           []string{"routers.*"},
+			expectedNumRouter: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			provider := &Provider{Directory: tempDir, Include: test.include}
+
+			configuration, err := provider.BuildConfiguration()
+			require.NoError(t, err)
+
+			assert.Len(t, configuration.Routers, test.expectedNumRouter)
+			require.Len(t, configuration.Services, test.expectedNumService)
+
+			if test.expectedNumService > 0 {
+				assert.Equal(t, "http://172.17.0.2:80", configuration.Services["application-1"].LoadBalancer.Servers[0].URL)
+				assert.Equal(t, "http://127.0.0.1:80", configuration.Services["application-2"].LoadBalancer.Servers[0].URL)
+			}
+		})
+	}
+}
+
 func getTestCases() []ProvideTestCase {
 	return []ProvideTestCase{
 		{
@@ -0,0 +1,20 @@
+package grpc
+
+import (
+	"encoding/json"
+)
+
+// jsonCodec encodes the gRPC messages in JSON, so that controllers don't need generated protobuf stubs.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) String() string {
+	return "json"
+}
@@ -0,0 +1,191 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/provider"
+	"github.com/containous/traefik/safe"
+	stdgrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var _ provider.Provider = (*Provider)(nil)
+
+const (
+	providerName = "grpc"
+
+	serviceName = "traefik.ConfigurationDiscovery"
+	streamName  = "StreamConfiguration"
+)
+
+// Update is a dynamic configuration sent by a controller.
+// Versions must be increasing: an update with an older version than the current one is rejected.
+type Update struct {
+	Version       uint64                `json:"version"`
+	Configuration *config.Configuration `json:"configuration"`
+}
+
+// Ack is sent back to the controller for each update.
+// An update which is not accepted (nack) is not applied, and Error explains why.
+type Ack struct {
+	Version  uint64 `json:"version"`
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Provider is a provider.Provider implementation that receives the dynamic configuration
+// from external controllers, through a bidirectional gRPC stream.
+// The messages are encoded in JSON.
+type Provider struct {
+	Address string `description:"Address on which the gRPC server listens" export:"true"`
+	Cert    string `description:"TLS certificate of the gRPC server"`
+	Key     string `description:"TLS key of the gRPC server"`
+	Token   string `description:"Token the controllers must send as a Bearer authorization metadata"`
+
+	configurationChan chan<- config.Message
+	listener          net.Listener
+
+	lock    sync.Mutex
+	version uint64
+}
+
+// Init the provider.
+func (p *Provider) Init() error {
+	if len(p.Address) == 0 {
+		return fmt.Errorf("no address defined for the gRPC provider")
+	}
+	if (len(p.Cert) == 0) != (len(p.Key) == 0) {
+		return fmt.Errorf("both a certificate and a key are required to enable TLS on the gRPC provider")
+	}
+	return nil
+}
+
+// Provide allows the provider to provide configurations to traefik
+// using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
+	p.configurationChan = configurationChan
+
+	opts := []stdgrpc.ServerOption{stdgrpc.CustomCodec(jsonCodec{})}
+	if len(p.Cert) > 0 {
+		creds, err := credentials.NewServerTLSFromFile(p.Cert, p.Key)
+		if err != nil {
+			return fmt.Errorf("unable to load the TLS certificate of the gRPC provider: %v", err)
+		}
+		opts = append(opts, stdgrpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", p.Address)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %v", p.Address, err)
+	}
+	p.listener = listener
+
+	server := stdgrpc.NewServer(opts...)
+	server.RegisterService(&stdgrpc.ServiceDesc{
+		ServiceName: serviceName,
+		HandlerType: (*interface{})(nil),
+		Streams: []stdgrpc.StreamDesc{{
+			StreamName:    streamName,
+			Handler:       p.streamHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		}},
+	}, p)
+
+	safe.Go(func() {
+		if err := server.Serve(listener); err != nil {
+			log.WithoutContext().WithField(log.ProviderName, providerName).Errorf("gRPC server stopped: %v", err)
+		}
+	})
+
+	pool.Go(func(stop chan bool) {
+		<-stop
+		server.Stop()
+	})
+
+	return nil
+}
+
+func (p *Provider) streamHandler(_ interface{}, stream stdgrpc.ServerStream) error {
+	if err := p.authorize(stream.Context()); err != nil {
+		return err
+	}
+
+	logger := log.WithoutContext().WithField(log.ProviderName, providerName)
+
+	for {
+		update := &Update{}
+		err := stream.RecvMsg(update)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		ack := p.apply(update)
+		if !ack.Accepted {
+			logger.Warnf("Configuration version %d rejected: %s", update.Version, ack.Error)
+		}
+
+		if err := stream.SendMsg(ack); err != nil {
+			return err
+		}
+	}
+}
+
+// apply validates the update, and sends it to Traefik if it is valid.
+func (p *Provider) apply(update *Update) *Ack {
+	ack := &Ack{Version: update.Version}
+
+	if update.Configuration == nil {
+		ack.Error = "no configuration"
+		return ack
+	}
+
+	if err := provider.ValidateConfiguration(update.Configuration); err != nil {
+		ack.Error = err.Error()
+		return ack
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if update.Version <= p.version {
+		ack.Error = fmt.Sprintf("version %d is not newer than the current version %d", update.Version, p.version)
+		return ack
+	}
+	p.version = update.Version
+
+	p.configurationChan <- config.Message{ProviderName: providerName, Configuration: update.Configuration}
+
+	ack.Accepted = true
+	return ack
+}
+
+func (p *Provider) authorize(ctx context.Context) error {
+	if len(p.Token) == 0 {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token := strings.TrimPrefix(value, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(p.Token)) == 1 {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid token")
+}
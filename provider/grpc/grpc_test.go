@@ -0,0 +1,145 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/safe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	stdgrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestProvider_Stream(t *testing.T) {
+	p := &Provider{Address: "127.0.0.1:0"}
+	configurationChan, stop := startProvider(t, p)
+	defer stop()
+
+	conn := dial(t, p)
+	defer conn.Close()
+
+	stream := openStream(context.Background(), t, conn)
+
+	validConfiguration := &config.Configuration{
+		Routers: map[string]*config.Router{
+			"foo": {Rule: "Host:foo.bar", Service: "foo"},
+		},
+		Services: map[string]*config.Service{
+			"foo":    {LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: "http://127.0.0.1:8080"}}}},
+			"bar":    {BlueGreen: &config.BlueGreenService{Blue: "foo", Green: "foo-v2", Live: "blue"}},
+			"foo-v2": {LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: "http://127.0.0.1:8081"}}}},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		update   *Update
+		expected *Ack
+	}{
+		{
+			desc:     "valid configuration",
+			update:   &Update{Version: 2, Configuration: validConfiguration},
+			expected: &Ack{Version: 2, Accepted: true},
+		},
+		{
+			desc:     "outdated version",
+			update:   &Update{Version: 1, Configuration: validConfiguration},
+			expected: &Ack{Version: 1, Error: "version 1 is not newer than the current version 2"},
+		},
+		{
+			desc: "invalid configuration",
+			update: &Update{Version: 3, Configuration: &config.Configuration{
+				Routers: map[string]*config.Router{"foo": {Service: "foo"}},
+			}},
+			expected: &Ack{Version: 3, Error: "invalid router foo: no rule defined"},
+		},
+		{
+			desc: "invalid blue/green service",
+			update: &Update{Version: 3, Configuration: &config.Configuration{
+				Services: map[string]*config.Service{
+					"bar": {BlueGreen: &config.BlueGreenService{Blue: "foo", Green: "foo-v2", Live: "red"}},
+				},
+			}},
+			expected: &Ack{Version: 3, Error: `invalid service bar: invalid live color "red", expected blue or green`},
+		},
+		{
+			desc:     "no configuration",
+			update:   &Update{Version: 3},
+			expected: &Ack{Version: 3, Error: "no configuration"},
+		},
+	}
+
+	// The cases share the stream and the provider version, so they are run in order.
+	for _, test := range testCases {
+		require.NoError(t, stream.SendMsg(test.update), test.desc)
+
+		ack := &Ack{}
+		require.NoError(t, stream.RecvMsg(ack), test.desc)
+		assert.Equal(t, test.expected, ack, test.desc)
+	}
+
+	select {
+	case msg := <-configurationChan:
+		assert.Equal(t, "grpc", msg.ProviderName)
+		assert.Equal(t, validConfiguration, msg.Configuration)
+	default:
+		t.Fatal("the valid configuration was not provided")
+	}
+	assert.Empty(t, configurationChan)
+}
+
+func TestProvider_Token(t *testing.T) {
+	p := &Provider{Address: "127.0.0.1:0", Token: "secret"}
+	_, stop := startProvider(t, p)
+	defer stop()
+
+	conn := dial(t, p)
+	defer conn.Close()
+
+	stream := openStream(context.Background(), t, conn)
+	require.NoError(t, stream.SendMsg(&Update{Version: 1}))
+	err := stream.RecvMsg(&Ack{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	stream = openStream(ctx, t, conn)
+	require.NoError(t, stream.SendMsg(&Update{Version: 1}))
+	ack := &Ack{}
+	require.NoError(t, stream.RecvMsg(ack))
+	assert.Equal(t, &Ack{Version: 1, Error: "no configuration"}, ack)
+}
+
+func startProvider(t *testing.T, p *Provider) (chan config.Message, func()) {
+	t.Helper()
+
+	require.NoError(t, p.Init())
+
+	pool := safe.NewPool(context.Background())
+	configurationChan := make(chan config.Message, 10)
+	require.NoError(t, p.Provide(configurationChan, pool))
+
+	return configurationChan, pool.Cleanup
+}
+
+func dial(t *testing.T, p *Provider) *stdgrpc.ClientConn {
+	t.Helper()
+
+	conn, err := stdgrpc.Dial(p.listener.Addr().String(), stdgrpc.WithInsecure(), stdgrpc.WithCodec(jsonCodec{}), stdgrpc.WithBlock(), stdgrpc.WithTimeout(5*time.Second))
+	require.NoError(t, err)
+
+	return conn
+}
+
+func openStream(ctx context.Context, t *testing.T, conn *stdgrpc.ClientConn) stdgrpc.ClientStream {
+	t.Helper()
+
+	stream, err := conn.NewStream(ctx, &stdgrpc.StreamDesc{ServerStreams: true, ClientStreams: true}, "/"+serviceName+"/"+streamName)
+	require.NoError(t, err)
+
+	return stream
+}
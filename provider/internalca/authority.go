@@ -0,0 +1,203 @@
+package internalca
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containous/traefik/types"
+)
+
+const (
+	rootCommonName = "Traefik Internal CA"
+	rootValidity   = 10 * 365 * 24 * time.Hour
+
+	// clockSkew backdates the certificates, so the clients with a clock slightly late accept them.
+	clockSkew = time.Minute
+)
+
+// authority signs the certificates with the key of a CA.
+type authority struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+// loadAuthority loads the CA from its certificate and key.
+func loadAuthority(certPEM, keyPEM []byte) (*authority, error) {
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !cert.IsCA {
+		return nil, fmt.Errorf("the certificate %s is not a CA", cert.Subject.CommonName)
+	}
+
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported CA key")
+	}
+
+	return &authority{cert: cert, key: key}, nil
+}
+
+// loadOrGenerateAuthority loads the CA kept in the storage file, or generates a root CA, and keeps it in the storage file if any.
+func loadOrGenerateAuthority(storage string) (*authority, error) {
+	if len(storage) > 0 {
+		content, err := ioutil.ReadFile(storage)
+		if err == nil {
+			certPEM, keyPEM := splitPEM(content)
+			return loadAuthority(certPEM, keyPEM)
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	certPEM, keyPEM, err := generateRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(storage) > 0 {
+		if err := os.MkdirAll(filepath.Dir(storage), 0700); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(storage, append(certPEM, keyPEM...), 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	return loadAuthority(certPEM, keyPEM)
+}
+
+// generateRoot generates the certificate and the key of a root CA.
+func generateRoot() ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: rootCommonName, Organization: []string{"Traefik"}},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(rootValidity),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		nil
+}
+
+// issue signs a certificate for the domain, valid for the duration, or until the expiry of the CA if it comes first.
+// The certificate is returned along with its key, PEM encoded, and its expiry date.
+func (a *authority) issue(domain types.Domain, duration time.Duration) ([]byte, []byte, time.Time, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(duration)
+	if notAfter.After(a.cert.NotAfter) {
+		notAfter = a.cert.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: domain.Main},
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	for _, name := range domain.ToStrArray() {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	// The chain holds the CA, so the clients can check it against a trusted root.
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: a.cert.Raw})...)
+
+	return certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), notAfter, nil
+}
+
+func randomSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// splitPEM splits the content of the storage file into the certificate and the key blocks.
+func splitPEM(content []byte) ([]byte, []byte) {
+	var certPEM, keyPEM []byte
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			return certPEM, keyPEM
+		}
+
+		if block.Type == "CERTIFICATE" {
+			certPEM = append(certPEM, pem.EncodeToMemory(block)...)
+		} else {
+			keyPEM = append(keyPEM, pem.EncodeToMemory(block)...)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package internalca
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/rules"
+	"github.com/containous/traefik/safe"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/containous/traefik/types"
+)
+
+const (
+	// ProviderName is the name of the configurations of the provider.
+	ProviderName = "internalca"
+
+	defaultDuration = 30 * 24 * time.Hour
+
+	// renewInterval is the interval between two checks of the certificates to renew.
+	renewInterval = time.Hour
+)
+
+// Configuration holds the configuration of the internal certificate authority,
+// issuing the certificates of the development environments and of the internal domains, which ACME can't reach.
+type Configuration struct {
+	EntryPoint string                   `description:"EntryPoint to use"`
+	CertFile   traefiktls.FileOrContent `description:"Certificate of the CA signing the certificates, a root CA is generated if empty"`
+	KeyFile    traefiktls.FileOrContent `description:"Key of the CA signing the certificates"`
+	Storage    string                   `description:"File keeping the generated root CA across restarts"`
+	Duration   parse.Duration           `description:"Validity of the issued certificates (default 720h)"`
+	OnHostRule bool                     `description:"Issue certificates for the domains of the Host rules"`
+	Domains    []types.Domain           `description:"CN and SANs (alternative domains) to each main domain the certificates are issued for"`
+}
+
+// Provider issues the certificates of the domains from an internal CA, and renews them before they expire.
+type Provider struct {
+	*Configuration
+	authority              *authority
+	configurationChan      chan<- config.Message
+	configFromListenerChan chan config.Configuration
+	lock                   sync.Mutex
+	certificates           map[string]*certificate
+}
+
+type certificate struct {
+	domain   types.Domain
+	cert     []byte
+	key      []byte
+	notAfter time.Time
+}
+
+// SetConfigListenerChan initializes the configFromListenerChan
+func (p *Provider) SetConfigListenerChan(configFromListenerChan chan config.Configuration) {
+	p.configFromListenerChan = configFromListenerChan
+}
+
+// ListenConfiguration sets a new Configuration into the configFromListenerChan
+func (p *Provider) ListenConfiguration(config config.Configuration) {
+	p.configFromListenerChan <- config
+}
+
+// Init loads the CA, or generates a root CA when none is configured.
+func (p *Provider) Init() error {
+	if len(p.EntryPoint) == 0 {
+		return errors.New("unable to initialize the internal CA provider with no entry point")
+	}
+
+	if p.Duration <= 0 {
+		p.Duration = parse.Duration(defaultDuration)
+	}
+	p.certificates = make(map[string]*certificate)
+
+	if len(p.CertFile) > 0 || len(p.KeyFile) > 0 {
+		certPEM, err := p.CertFile.Read()
+		if err != nil {
+			return err
+		}
+		keyPEM, err := p.KeyFile.Read()
+		if err != nil {
+			return err
+		}
+
+		p.authority, err = loadAuthority(certPEM, keyPEM)
+		return err
+	}
+
+	var err error
+	p.authority, err = loadOrGenerateAuthority(p.Storage)
+	if err != nil {
+		return err
+	}
+
+	if len(p.Storage) == 0 {
+		log.WithoutContext().WithField(log.ProviderName, ProviderName).
+			Warn("The generated root CA is not stored, the clients will have to trust a new one after each restart")
+	}
+	return nil
+}
+
+// Provide issues the certificates of the configured domains, and of the domains of the Host rules.
+func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
+	ctx := log.With(context.Background(), log.Str(log.ProviderName, ProviderName))
+
+	p.configurationChan = configurationChan
+
+	p.resolveCertificates(ctx, p.Domains...)
+
+	if p.configFromListenerChan != nil {
+		p.watchNewDomains(ctx, pool)
+	}
+
+	ticker := time.NewTicker(renewInterval)
+	pool.Go(func(stop chan bool) {
+		for {
+			select {
+			case <-ticker.C:
+				p.renewCertificates(ctx)
+			case <-stop:
+				ticker.Stop()
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func (p *Provider) watchNewDomains(ctx context.Context, pool *safe.Pool) {
+	pool.Go(func(stop chan bool) {
+		for {
+			select {
+			case config := <-p.configFromListenerChan:
+				var domains []types.Domain
+				for routerName, route := range config.Routers {
+					logger := log.FromContext(ctx).WithField(log.RouterName, routerName)
+
+					domainRules := rules.Rules{}
+					names, err := domainRules.ParseDomains(route.Rule)
+					if err != nil {
+						logger.Errorf("Error parsing domains in provider internal CA: %v", err)
+						continue
+					}
+
+					if len(names) == 0 {
+						continue
+					}
+
+					domain := types.Domain{}
+					domain.Set(names)
+					domains = append(domains, domain)
+				}
+
+				p.resolveCertificates(ctx, domains...)
+			case <-stop:
+				return
+			}
+		}
+	})
+}
+
+// resolveCertificates issues the certificates of the domains which don't have one yet.
+func (p *Provider) resolveCertificates(ctx context.Context, domains ...types.Domain) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	issued := false
+	for _, domain := range domains {
+		key := strings.Join(domain.ToStrArray(), ",")
+		if _, ok := p.certificates[key]; ok {
+			continue
+		}
+
+		if err := p.issue(domain); err != nil {
+			log.FromContext(ctx).Errorf("Unable to issue a certificate for the domains %q: %v", key, err)
+			continue
+		}
+		issued = true
+	}
+
+	if issued {
+		p.refreshCertificates()
+	}
+}
+
+// renewCertificates issues again the certificates which have less than a third of their validity left.
+func (p *Provider) renewCertificates(ctx context.Context) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	renewed := false
+	for key, cert := range p.certificates {
+		if time.Until(cert.notAfter) > time.Duration(p.Duration)/3 {
+			continue
+		}
+
+		if err := p.issue(cert.domain); err != nil {
+			log.FromContext(ctx).Errorf("Unable to renew the certificate of the domains %q: %v", key, err)
+			continue
+		}
+		renewed = true
+	}
+
+	if renewed {
+		p.refreshCertificates()
+	}
+}
+
+// issue issues the certificate of the domain. The lock must be held.
+func (p *Provider) issue(domain types.Domain) error {
+	cert, key, notAfter, err := p.authority.issue(domain, time.Duration(p.Duration))
+	if err != nil {
+		return err
+	}
+
+	p.certificates[strings.Join(domain.ToStrArray(), ",")] = &certificate{
+		domain:   domain,
+		cert:     cert,
+		key:      key,
+		notAfter: notAfter,
+	}
+	return nil
+}
+
+// refreshCertificates sends the configuration holding the issued certificates. The lock must be held.
+func (p *Provider) refreshCertificates() {
+	conf := config.Message{
+		ProviderName: ProviderName,
+		Configuration: &config.Configuration{
+			Routers:     map[string]*config.Router{},
+			Middlewares: map[string]*config.Middleware{},
+			Services:    map[string]*config.Service{},
+			TLS:         []*traefiktls.Configuration{},
+		},
+	}
+
+	for _, cert := range p.certificates {
+		certificate := &traefiktls.Certificate{CertFile: traefiktls.FileOrContent(cert.cert), KeyFile: traefiktls.FileOrContent(cert.key)}
+		conf.Configuration.TLS = append(conf.Configuration.TLS, &traefiktls.Configuration{Certificate: certificate, EntryPoints: []string{p.EntryPoint}})
+	}
+	p.configurationChan <- conf
+}
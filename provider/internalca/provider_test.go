@@ -0,0 +1,135 @@
+package internalca
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/safe"
+	traefiktls "github.com/containous/traefik/tls"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvide(t *testing.T) {
+	provider := &Provider{Configuration: &Configuration{
+		EntryPoint: "websecure",
+		Domains: []types.Domain{
+			{Main: "foo.internal", SANs: []string{"www.foo.internal"}},
+			{Main: "127.0.0.1"},
+		},
+	}}
+	require.NoError(t, provider.Init())
+
+	configurationChan := make(chan config.Message, 1)
+	pool := safe.NewPool(context.Background())
+	defer pool.Cleanup()
+
+	require.NoError(t, provider.Provide(configurationChan, pool))
+
+	var message config.Message
+	select {
+	case message = <-configurationChan:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no configuration received")
+	}
+
+	assert.Equal(t, ProviderName, message.ProviderName)
+	require.Len(t, message.Configuration.TLS, 2)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(provider.authority.cert)
+
+	for _, conf := range message.Configuration.TLS {
+		assert.Equal(t, []string{"websecure"}, conf.EntryPoints)
+
+		keyPair, err := tls.X509KeyPair([]byte(conf.Certificate.CertFile), []byte(conf.Certificate.KeyFile))
+		require.NoError(t, err)
+		require.Len(t, keyPair.Certificate, 2)
+
+		leaf, err := x509.ParseCertificate(keyPair.Certificate[0])
+		require.NoError(t, err)
+
+		name := leaf.Subject.CommonName
+		_, err = leaf.Verify(x509.VerifyOptions{DNSName: name, Roots: roots})
+		assert.NoError(t, err, name)
+		assert.WithinDuration(t, time.Now().Add(defaultDuration), leaf.NotAfter, time.Minute)
+
+		if name == "foo.internal" {
+			assert.Equal(t, []string{"foo.internal", "www.foo.internal"}, leaf.DNSNames)
+		} else {
+			assert.Len(t, leaf.IPAddresses, 1)
+		}
+	}
+}
+
+func TestInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "internalca")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	storage := filepath.Join(dir, "ca", "root.pem")
+
+	first := &Provider{Configuration: &Configuration{EntryPoint: "websecure", Storage: storage}}
+	require.NoError(t, first.Init())
+
+	info, err := os.Stat(storage)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// The stored root CA is kept across restarts.
+	second := &Provider{Configuration: &Configuration{EntryPoint: "websecure", Storage: storage}}
+	require.NoError(t, second.Init())
+	assert.Equal(t, first.authority.cert.Raw, second.authority.cert.Raw)
+
+	// The root CA can be configured explicitly.
+	content, err := ioutil.ReadFile(storage)
+	require.NoError(t, err)
+	certPEM, keyPEM := splitPEM(content)
+
+	third := &Provider{Configuration: &Configuration{
+		EntryPoint: "websecure",
+		CertFile:   traefiktls.FileOrContent(certPEM),
+		KeyFile:    traefiktls.FileOrContent(keyPEM),
+	}}
+	require.NoError(t, third.Init())
+	assert.Equal(t, first.authority.cert.Raw, third.authority.cert.Raw)
+
+	assert.Error(t, (&Provider{Configuration: &Configuration{}}).Init())
+}
+
+func TestRenewCertificates(t *testing.T) {
+	provider := &Provider{Configuration: &Configuration{
+		EntryPoint: "websecure",
+		Duration:   parse.Duration(time.Hour),
+	}}
+	require.NoError(t, provider.Init())
+
+	configurationChan := make(chan config.Message, 10)
+	provider.configurationChan = configurationChan
+
+	provider.resolveCertificates(context.Background(), types.Domain{Main: "foo.internal"}, types.Domain{Main: "bar.internal"})
+	require.Len(t, configurationChan, 1)
+	<-configurationChan
+
+	// Already issued certificates are not issued again.
+	provider.resolveCertificates(context.Background(), types.Domain{Main: "foo.internal"})
+	assert.Len(t, configurationChan, 0)
+
+	provider.certificates["foo.internal"].notAfter = time.Now().Add(10 * time.Minute)
+	previous := provider.certificates["foo.internal"].cert
+	unchanged := provider.certificates["bar.internal"].cert
+
+	provider.renewCertificates(context.Background())
+	assert.Len(t, configurationChan, 1)
+	assert.NotEqual(t, previous, provider.certificates["foo.internal"].cert)
+	assert.Equal(t, unchanged, provider.certificates["bar.internal"].cert)
+}
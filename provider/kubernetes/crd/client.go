@@ -0,0 +1,306 @@
+package crd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/provider/kubernetes/crd/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	kubeerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	resyncPeriod = 10 * time.Minute
+	// pollPeriod is the interval between two listings of the custom resources.
+	pollPeriod = 5 * time.Second
+)
+
+type resourceEventHandler struct {
+	ev chan<- interface{}
+}
+
+func (reh *resourceEventHandler) OnAdd(obj interface{}) {
+	eventHandlerFunc(reh.ev, obj)
+}
+
+func (reh *resourceEventHandler) OnUpdate(oldObj, newObj interface{}) {
+	eventHandlerFunc(reh.ev, newObj)
+}
+
+func (reh *resourceEventHandler) OnDelete(obj interface{}) {
+	eventHandlerFunc(reh.ev, obj)
+}
+
+// Client is a client for the Provider master.
+// WatchAll starts the watch of the Provider resources and updates the stores.
+// The stores can then be accessed via the Get* functions.
+type Client interface {
+	WatchAll(namespaces []string, stopCh <-chan struct{}) (<-chan interface{}, error)
+	GetIngressRoutes() []*v1alpha1.IngressRoute
+	GetMiddlewares() []*v1alpha1.Middleware
+	GetService(namespace, name string) (*corev1.Service, bool, error)
+	GetSecret(namespace, name string) (*corev1.Secret, bool, error)
+	GetEndpoints(namespace, name string) (*corev1.Endpoints, bool, error)
+}
+
+// clientImpl watches the core resources with informers, and lists periodically the custom resources,
+// which are not known by the informers of client-go.
+type clientImpl struct {
+	clientset      *kubernetes.Clientset
+	factories      map[string]informers.SharedInformerFactory
+	labelSelector  labels.Selector
+	isNamespaceAll bool
+
+	lock          sync.RWMutex
+	ingressRoutes []*v1alpha1.IngressRoute
+	middlewares   []*v1alpha1.Middleware
+}
+
+func newClientImpl(clientset *kubernetes.Clientset) *clientImpl {
+	return &clientImpl{
+		clientset: clientset,
+		factories: make(map[string]informers.SharedInformerFactory),
+	}
+}
+
+// newInClusterClient returns a new Provider client that is expected to run
+// inside the cluster.
+func newInClusterClient(endpoint string) (*clientImpl, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create in-cluster configuration: %s", err)
+	}
+
+	if endpoint != "" {
+		config.Host = endpoint
+	}
+
+	return createClientFromConfig(config)
+}
+
+// newExternalClusterClient returns a new Provider client that may run outside
+// of the cluster.
+// The endpoint parameter must not be empty.
+func newExternalClusterClient(endpoint, token, caFilePath string) (*clientImpl, error) {
+	if endpoint == "" {
+		return nil, errors.New("endpoint missing for external cluster client")
+	}
+
+	config := &rest.Config{
+		Host:        endpoint,
+		BearerToken: token,
+	}
+
+	if caFilePath != "" {
+		caData, err := ioutil.ReadFile(caFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file %s: %s", caFilePath, err)
+		}
+
+		config.TLSClientConfig = rest.TLSClientConfig{CAData: caData}
+	}
+
+	return createClientFromConfig(config)
+}
+
+func createClientFromConfig(c *rest.Config) (*clientImpl, error) {
+	clientset, err := kubernetes.NewForConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClientImpl(clientset), nil
+}
+
+// WatchAll starts namespace-specific controllers for the core resources, and the polling of the custom resources.
+func (c *clientImpl) WatchAll(namespaces []string, stopCh <-chan struct{}) (<-chan interface{}, error) {
+	eventCh := make(chan interface{}, 1)
+
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+		c.isNamespaceAll = true
+	}
+
+	if err := c.listCustomResources(namespaces); err != nil {
+		return nil, err
+	}
+
+	eventHandler := &resourceEventHandler{ev: eventCh}
+	for _, ns := range namespaces {
+		factory := informers.NewFilteredSharedInformerFactory(c.clientset, resyncPeriod, ns, nil)
+		factory.Core().V1().Services().Informer().AddEventHandler(eventHandler)
+		factory.Core().V1().Endpoints().Informer().AddEventHandler(eventHandler)
+		c.factories[ns] = factory
+	}
+
+	for _, ns := range namespaces {
+		c.factories[ns].Start(stopCh)
+	}
+
+	for _, ns := range namespaces {
+		for t, ok := range c.factories[ns].WaitForCacheSync(stopCh) {
+			if !ok {
+				return nil, fmt.Errorf("timed out waiting for controller caches to sync %s in namespace %q", t.String(), ns)
+			}
+		}
+	}
+
+	// Do not wait for the Secrets store to get synced since we cannot rely on
+	// users having granted RBAC permissions for this object.
+	for _, ns := range namespaces {
+		c.factories[ns].Core().V1().Secrets().Informer().AddEventHandler(eventHandler)
+		c.factories[ns].Start(stopCh)
+	}
+
+	go c.pollCustomResources(namespaces, eventCh, stopCh)
+
+	return eventCh, nil
+}
+
+func (c *clientImpl) pollCustomResources(namespaces []string, events chan<- interface{}, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(pollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			ingressRoutes, middlewares := c.GetIngressRoutes(), c.GetMiddlewares()
+
+			if err := c.listCustomResources(namespaces); err != nil {
+				log.WithoutContext().WithField(log.ProviderName, providerName).Errorf("Failed to list the custom resources: %v", err)
+				continue
+			}
+
+			if !reflect.DeepEqual(ingressRoutes, c.GetIngressRoutes()) || !reflect.DeepEqual(middlewares, c.GetMiddlewares()) {
+				eventHandlerFunc(events, c.GetIngressRoutes())
+			}
+		}
+	}
+}
+
+// listCustomResources lists the IngressRoutes and Middlewares of the namespaces, and updates the stores.
+func (c *clientImpl) listCustomResources(namespaces []string) error {
+	var ingressRoutes []*v1alpha1.IngressRoute
+	var middlewares []*v1alpha1.Middleware
+
+	for _, ns := range namespaces {
+		ingressRouteList := &v1alpha1.IngressRouteList{}
+		if err := c.list(ns, "ingressroutes", ingressRouteList); err != nil {
+			return err
+		}
+		for i := range ingressRouteList.Items {
+			if c.labelSelector == nil || c.labelSelector.Matches(labels.Set(ingressRouteList.Items[i].Labels)) {
+				ingressRoutes = append(ingressRoutes, &ingressRouteList.Items[i])
+			}
+		}
+
+		middlewareList := &v1alpha1.MiddlewareList{}
+		if err := c.list(ns, "middlewares", middlewareList); err != nil {
+			return err
+		}
+		for i := range middlewareList.Items {
+			middlewares = append(middlewares, &middlewareList.Items[i])
+		}
+	}
+
+	c.lock.Lock()
+	c.ingressRoutes = ingressRoutes
+	c.middlewares = middlewares
+	c.lock.Unlock()
+
+	return nil
+}
+
+func (c *clientImpl) list(namespace, resource string, list interface{}) error {
+	path := "/apis/" + v1alpha1.GroupName + "/" + v1alpha1.Version
+	if namespace != metav1.NamespaceAll {
+		path += "/namespaces/" + namespace
+	}
+	path += "/" + resource
+
+	body, err := c.clientset.Discovery().RESTClient().Get().AbsPath(path).DoRaw()
+	if err != nil {
+		return fmt.Errorf("failed to list %s in namespace %q: %v", resource, namespace, err)
+	}
+
+	return json.Unmarshal(body, list)
+}
+
+// GetIngressRoutes returns all IngressRoutes for observed namespaces in the cluster.
+func (c *clientImpl) GetIngressRoutes() []*v1alpha1.IngressRoute {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.ingressRoutes
+}
+
+// GetMiddlewares returns all Middlewares for observed namespaces in the cluster.
+func (c *clientImpl) GetMiddlewares() []*v1alpha1.Middleware {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.middlewares
+}
+
+// GetService returns the named service from the given namespace.
+func (c *clientImpl) GetService(namespace, name string) (*corev1.Service, bool, error) {
+	service, err := c.factories[c.lookupNamespace(namespace)].Core().V1().Services().Lister().Services(namespace).Get(name)
+	exist, err := translateNotFoundError(err)
+	return service, exist, err
+}
+
+// GetEndpoints returns the named endpoints from the given namespace.
+func (c *clientImpl) GetEndpoints(namespace, name string) (*corev1.Endpoints, bool, error) {
+	endpoint, err := c.factories[c.lookupNamespace(namespace)].Core().V1().Endpoints().Lister().Endpoints(namespace).Get(name)
+	exist, err := translateNotFoundError(err)
+	return endpoint, exist, err
+}
+
+// GetSecret returns the named secret from the given namespace.
+func (c *clientImpl) GetSecret(namespace, name string) (*corev1.Secret, bool, error) {
+	secret, err := c.factories[c.lookupNamespace(namespace)].Core().V1().Secrets().Lister().Secrets(namespace).Get(name)
+	exist, err := translateNotFoundError(err)
+	return secret, exist, err
+}
+
+// lookupNamespace returns the lookup namespace key for the given namespace.
+// When listening on all namespaces, it returns the client-go identifier ("")
+// for all-namespaces. Otherwise, it returns the given namespace.
+func (c *clientImpl) lookupNamespace(ns string) string {
+	if c.isNamespaceAll {
+		return metav1.NamespaceAll
+	}
+	return ns
+}
+
+// eventHandlerFunc will pass the obj on to the events channel or drop it.
+// This is so passing the events along won't block in the case of high volume.
+// The events are only used for signaling anyway so dropping a few is ok.
+func eventHandlerFunc(events chan<- interface{}, obj interface{}) {
+	select {
+	case events <- obj:
+	default:
+	}
+}
+
+// translateNotFoundError will translate a "not found" error to a boolean return
+// value which indicates if the resource exists and a nil error.
+func translateNotFoundError(err error) (bool, error) {
+	if kubeerror.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,54 @@
+package crd
+
+import (
+	"github.com/containous/traefik/provider/kubernetes/crd/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type clientMock struct {
+	ingressRoutes []*v1alpha1.IngressRoute
+	middlewares   []*v1alpha1.Middleware
+	services      []*corev1.Service
+	secrets       []*corev1.Secret
+	endpoints     []*corev1.Endpoints
+	watchChan     chan interface{}
+}
+
+func (c clientMock) GetIngressRoutes() []*v1alpha1.IngressRoute {
+	return c.ingressRoutes
+}
+
+func (c clientMock) GetMiddlewares() []*v1alpha1.Middleware {
+	return c.middlewares
+}
+
+func (c clientMock) GetService(namespace, name string) (*corev1.Service, bool, error) {
+	for _, service := range c.services {
+		if service.Namespace == namespace && service.Name == name {
+			return service, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (c clientMock) GetEndpoints(namespace, name string) (*corev1.Endpoints, bool, error) {
+	for _, endpoints := range c.endpoints {
+		if endpoints.Namespace == namespace && endpoints.Name == name {
+			return endpoints, true, nil
+		}
+	}
+	return &corev1.Endpoints{}, false, nil
+}
+
+func (c clientMock) GetSecret(namespace, name string) (*corev1.Secret, bool, error) {
+	for _, secret := range c.secrets {
+		if secret.Namespace == namespace && secret.Name == name {
+			return secret, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (c clientMock) WatchAll(namespaces []string, stopCh <-chan struct{}) (<-chan interface{}, error) {
+	return c.watchChan, nil
+}
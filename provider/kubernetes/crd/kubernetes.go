@@ -0,0 +1,316 @@
+package crd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/cenk/backoff"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/events"
+	"github.com/containous/traefik/job"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/provider"
+	"github.com/containous/traefik/provider/kubernetes/crd/v1alpha1"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/tls"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const providerName = "kubernetescrd"
+
+var _ provider.Provider = (*Provider)(nil)
+
+// Provider holds configurations of the provider.
+type Provider struct {
+	provider.BaseProvider `mapstructure:",squash" export:"true"`
+	Endpoint              string   `description:"Kubernetes server endpoint (required for external cluster client)"`
+	Token                 string   `description:"Kubernetes bearer token (not needed for in-cluster client)"`
+	CertAuthFilePath      string   `description:"Kubernetes certificate authority file path (not needed for in-cluster client)"`
+	Namespaces            []string `description:"Kubernetes namespaces" export:"true"`
+	LabelSelector         string   `description:"Kubernetes IngressRoute label selector to use" export:"true"`
+	lastConfiguration     safe.Safe
+}
+
+func (p *Provider) newK8sClient(ctx context.Context, labelSelector string) (*clientImpl, error) {
+	labelSel, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %q", labelSelector)
+	}
+	log.FromContext(ctx).Infof("label selector is: %q", labelSel)
+
+	withEndpoint := ""
+	if p.Endpoint != "" {
+		withEndpoint = fmt.Sprintf(" with endpoint %v", p.Endpoint)
+	}
+
+	var client *clientImpl
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != "" {
+		log.FromContext(ctx).Infof("Creating in-cluster Provider client%s", withEndpoint)
+		client, err = newInClusterClient(p.Endpoint)
+	} else {
+		log.FromContext(ctx).Infof("Creating cluster-external Provider client%s", withEndpoint)
+		client, err = newExternalClusterClient(p.Endpoint, p.Token, p.CertAuthFilePath)
+	}
+
+	if err == nil {
+		client.labelSelector = labelSel
+	}
+
+	return client, err
+}
+
+// Init the provider.
+func (p *Provider) Init() error {
+	return p.BaseProvider.Init()
+}
+
+// Provide allows the k8s provider to provide configurations to traefik
+// using the given configuration channel.
+func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
+	ctxLog := log.With(context.Background(), log.Str(log.ProviderName, providerName))
+	logger := log.FromContext(ctxLog)
+
+	// Tell glog (used by client-go) to log into STDERR. Otherwise, we risk
+	// certain kinds of API errors getting logged into a directory not
+	// available in a `FROM scratch` Docker container, causing glog to abort
+	// hard with an exit code > 0.
+	err := flag.Set("logtostderr", "true")
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := p.newK8sClient(ctxLog, p.LabelSelector)
+	if err != nil {
+		return err
+	}
+
+	pool.Go(func(stop chan bool) {
+		operation := func() error {
+			stopWatch := make(chan struct{}, 1)
+			defer close(stopWatch)
+
+			eventsChan, err := k8sClient.WatchAll(p.Namespaces, stopWatch)
+			if err != nil {
+				logger.Errorf("Error watching kubernetes events: %v", err)
+				timer := time.NewTimer(1 * time.Second)
+				select {
+				case <-timer.C:
+					return err
+				case <-stop:
+					return nil
+				}
+			}
+
+			for {
+				select {
+				case <-stop:
+					return nil
+				case event := <-eventsChan:
+					conf := p.loadConfigurationFromCRD(ctxLog, k8sClient)
+
+					if reflect.DeepEqual(p.lastConfiguration.Get(), conf) {
+						logger.Debugf("Skipping Kubernetes event kind %T", event)
+					} else {
+						p.lastConfiguration.Set(conf)
+						configurationChan <- config.Message{
+							ProviderName:  providerName,
+							Configuration: conf,
+						}
+					}
+				}
+			}
+		}
+
+		notify := func(err error, time time.Duration) {
+			logger.Errorf("Provider connection error: %s; retrying in %s", err, time)
+			events.Publish(events.ProviderDisconnected, map[string]string{"provider": providerName, "reason": err.Error()})
+		}
+		err := backoff.RetryNotify(safe.OperationWithRecover(operation), job.NewBackOff(backoff.NewExponentialBackOff()), notify)
+		if err != nil {
+			logger.Errorf("Cannot connect to Provider: %s", err)
+		}
+	})
+
+	return nil
+}
+
+func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client) *config.Configuration {
+	conf := &config.Configuration{
+		Routers:     make(map[string]*config.Router),
+		Middlewares: make(map[string]*config.Middleware),
+		Services:    make(map[string]*config.Service),
+	}
+
+	for _, middleware := range client.GetMiddlewares() {
+		spec := middleware.Spec
+		conf.Middlewares[makeID(middleware.Namespace, middleware.Name)] = &spec
+	}
+
+	for _, ingressRoute := range client.GetIngressRoutes() {
+		logger := log.FromContext(ctx).WithField("ingress", ingressRoute.Name).WithField("namespace", ingressRoute.Namespace)
+
+		if ingressRoute.Spec.TLS != nil {
+			tlsConf, err := getTLS(client, ingressRoute.Namespace, ingressRoute.Spec.TLS.SecretName)
+			if err != nil {
+				logger.Errorf("Error configuring TLS: %v", err)
+			} else {
+				tlsConf.EntryPoints = ingressRoute.Spec.EntryPoints
+				conf.TLS = append(conf.TLS, tlsConf)
+			}
+		}
+
+		for i, route := range ingressRoute.Spec.Routes {
+			if route.Kind != "Rule" {
+				logger.Errorf("Unsupported match kind: %s. Only \"Rule\" is supported for now.", route.Kind)
+				continue
+			}
+
+			if len(route.Match) == 0 {
+				logger.Errorf("Empty match rule")
+				continue
+			}
+
+			key := makeID(ingressRoute.Namespace, ingressRoute.Name+"-"+strconv.Itoa(i))
+
+			servers, err := loadServers(client, ingressRoute.Namespace, route.Services)
+			if err != nil {
+				logger.WithField(log.ServiceName, key).Errorf("Cannot create the service: %v", err)
+				continue
+			}
+
+			var middlewares []string
+			for _, middleware := range route.Middlewares {
+				namespace := middleware.Namespace
+				if len(namespace) == 0 {
+					namespace = ingressRoute.Namespace
+				}
+				middlewares = append(middlewares, makeID(namespace, middleware.Name))
+			}
+
+			conf.Routers[key] = &config.Router{
+				EntryPoints: ingressRoute.Spec.EntryPoints,
+				Middlewares: middlewares,
+				Service:     key,
+				Rule:        route.Match,
+				Priority:    route.Priority,
+			}
+
+			conf.Services[key] = &config.Service{
+				LoadBalancer: &config.LoadBalancerService{
+					Servers:        servers,
+					Method:         "wrr",
+					PassHostHeader: true,
+				},
+			}
+		}
+	}
+
+	return conf
+}
+
+// loadServers returns the servers of the endpoints of the Kubernetes services ports.
+func loadServers(client Client, namespace string, services []v1alpha1.Service) ([]config.Server, error) {
+	var servers []config.Server
+
+	for _, svc := range services {
+		service, exists, err := client.GetService(namespace, svc.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("service not found: %s/%s", namespace, svc.Name)
+		}
+
+		var portSpec *corev1.ServicePort
+		for i, p := range service.Spec.Ports {
+			if p.Port == svc.Port {
+				portSpec = &service.Spec.Ports[i]
+				break
+			}
+		}
+		if portSpec == nil {
+			return nil, fmt.Errorf("service port not found: %s/%s:%d", namespace, svc.Name, svc.Port)
+		}
+
+		endpoints, endpointsExists, err := client.GetEndpoints(namespace, svc.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !endpointsExists {
+			return nil, fmt.Errorf("endpoints not found: %s/%s", namespace, svc.Name)
+		}
+
+		weight := svc.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		scheme := svc.Scheme
+		if len(scheme) == 0 {
+			scheme = "http"
+			if portSpec.Port == 443 || portSpec.Name == "https" {
+				scheme = "https"
+			}
+		}
+
+		for _, subset := range endpoints.Subsets {
+			var port int32
+			for _, p := range subset.Ports {
+				if portSpec.Name == p.Name {
+					port = p.Port
+					break
+				}
+			}
+			if port == 0 {
+				continue
+			}
+
+			for _, addr := range subset.Addresses {
+				servers = append(servers, config.Server{
+					URL:    fmt.Sprintf("%s://%s:%d", scheme, addr.IP, port),
+					Weight: weight,
+				})
+			}
+		}
+	}
+
+	return servers, nil
+}
+
+// getTLS returns the TLS configuration holding the certificate of a Kubernetes TLS secret.
+func getTLS(client Client, namespace, secretName string) (*tls.Configuration, error) {
+	secret, exists, err := client.GetSecret(namespace, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret %s/%s: %v", namespace, secretName, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("secret %s/%s does not exist", namespace, secretName)
+	}
+
+	cert, hasCert := secret.Data[corev1.TLSCertKey]
+	key, hasKey := secret.Data[corev1.TLSPrivateKeyKey]
+	if !hasCert || !hasKey {
+		return nil, errors.New("secret must contain tls.crt and tls.key")
+	}
+
+	return &tls.Configuration{
+		Certificate: &tls.Certificate{
+			CertFile: tls.FileOrContent(cert),
+			KeyFile:  tls.FileOrContent(key),
+		},
+	}, nil
+}
+
+func makeID(namespace, name string) string {
+	if len(namespace) == 0 {
+		return name
+	}
+	return namespace + "-" + name
+}
@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GroupName is the API group of the Traefik custom resources.
+const GroupName = "traefik.containo.us"
+
+// Version is the API version of the Traefik custom resources.
+const Version = "v1alpha1"
+
+// IngressRoute is a custom resource routing the requests matching rules to Kubernetes services.
+type IngressRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IngressRouteSpec `json:"spec"`
+}
+
+// IngressRouteSpec is the specification of an IngressRoute.
+type IngressRouteSpec struct {
+	Routes      []Route  `json:"routes"`
+	EntryPoints []string `json:"entryPoints"`
+	TLS         *TLS     `json:"tls,omitempty"`
+}
+
+// Route is a rule of an IngressRoute, and the services and middlewares of the matching requests.
+type Route struct {
+	Match       string          `json:"match"`
+	Kind        string          `json:"kind"`
+	Priority    int             `json:"priority,omitempty"`
+	Services    []Service       `json:"services,omitempty"`
+	Middlewares []MiddlewareRef `json:"middlewares,omitempty"`
+}
+
+// TLS holds the certificate of an IngressRoute, stored in a Kubernetes secret.
+type TLS struct {
+	SecretName string `json:"secretName"`
+}
+
+// Service is a reference to a port of a Kubernetes service.
+type Service struct {
+	Name   string `json:"name"`
+	Port   int32  `json:"port"`
+	Scheme string `json:"scheme,omitempty"`
+	Weight int    `json:"weight,omitempty"`
+}
+
+// MiddlewareRef is a reference to a Middleware resource, in the namespace of the IngressRoute by default.
+type MiddlewareRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// IngressRouteList is a list of IngressRoutes.
+type IngressRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []IngressRoute `json:"items"`
+}
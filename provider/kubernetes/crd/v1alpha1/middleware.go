@@ -0,0 +1,22 @@
+package v1alpha1
+
+import (
+	"github.com/containous/traefik/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Middleware is a custom resource holding the configuration of a middleware, referenced by the IngressRoutes.
+type Middleware struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec config.Middleware `json:"spec"`
+}
+
+// MiddlewareList is a list of Middlewares.
+type MiddlewareList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []Middleware `json:"items"`
+}
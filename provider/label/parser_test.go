@@ -100,16 +100,18 @@ func TestDecode(t *testing.T) {
 		"traefik.middlewares.Middleware18.stripprefixregex.regex":                       "foobar, fiibar",
 		"traefik.middlewares.Middleware19.compress":                                     "true",
 
-		"traefik.routers.Router0.entrypoints": "foobar, fiibar",
-		"traefik.routers.Router0.middlewares": "foobar, fiibar",
-		"traefik.routers.Router0.priority":    "42",
-		"traefik.routers.Router0.rule":        "foobar",
-		"traefik.routers.Router0.service":     "foobar",
-		"traefik.routers.Router1.entrypoints": "foobar, fiibar",
-		"traefik.routers.Router1.middlewares": "foobar, fiibar",
-		"traefik.routers.Router1.priority":    "42",
-		"traefik.routers.Router1.rule":        "foobar",
-		"traefik.routers.Router1.service":     "foobar",
+		"traefik.routers.Router0.entrypoints":   "foobar, fiibar",
+		"traefik.routers.Router0.lowpriority":   "true",
+		"traefik.routers.Router0.middlewares":   "foobar, fiibar",
+		"traefik.routers.Router0.priority":      "42",
+		"traefik.routers.Router0.priorityclass": "foobar",
+		"traefik.routers.Router0.rule":          "foobar",
+		"traefik.routers.Router0.service":       "foobar",
+		"traefik.routers.Router1.entrypoints":   "foobar, fiibar",
+		"traefik.routers.Router1.middlewares":   "foobar, fiibar",
+		"traefik.routers.Router1.priority":      "42",
+		"traefik.routers.Router1.rule":          "foobar",
+		"traefik.routers.Router1.service":       "foobar",
 
 		"traefik.services.Service0.loadbalancer.healthcheck.headers.name0":        "foobar",
 		"traefik.services.Service0.loadbalancer.healthcheck.headers.name1":        "foobar",
@@ -156,9 +158,11 @@ func TestDecode(t *testing.T) {
 					"foobar",
 					"fiibar",
 				},
-				Service:  "foobar",
-				Rule:     "foobar",
-				Priority: 42,
+				Service:       "foobar",
+				Rule:          "foobar",
+				Priority:      42,
+				LowPriority:   true,
+				PriorityClass: "foobar",
 			},
 			"Router1": {
 				EntryPoints: []string{
@@ -469,9 +473,11 @@ func TestEncode(t *testing.T) {
 					"foobar",
 					"fiibar",
 				},
-				Service:  "foobar",
-				Rule:     "foobar",
-				Priority: 42,
+				Service:       "foobar",
+				Rule:          "foobar",
+				Priority:      42,
+				LowPriority:   true,
+				PriorityClass: "foobar",
 			},
 			"Router1": {
 				EntryPoints: []string{
@@ -841,6 +847,7 @@ func TestEncode(t *testing.T) {
 		"traefik.Middlewares.Middleware11.PassTLSClientCert.Infos.Subject.SerialNumber": "true",
 		"traefik.Middlewares.Middleware11.PassTLSClientCert.PEM":                        "true",
 		"traefik.Middlewares.Middleware12.RateLimit.ExtractorFunc":                      "foobar",
+		"traefik.Middlewares.Middleware12.RateLimit.MaxDelay":                           "0",
 		"traefik.Middlewares.Middleware12.RateLimit.RateSet.Rate0.Average":              "42",
 		"traefik.Middlewares.Middleware12.RateLimit.RateSet.Rate0.Burst":                "42",
 		"traefik.Middlewares.Middleware12.RateLimit.RateSet.Rate0.Period":               "42",
@@ -858,16 +865,19 @@ func TestEncode(t *testing.T) {
 		"traefik.Middlewares.Middleware18.StripPrefixRegex.Regex":                       "foobar, fiibar",
 		"traefik.Middlewares.Middleware19.Compress":                                     "true",
 
-		"traefik.Routers.Router0.EntryPoints": "foobar, fiibar",
-		"traefik.Routers.Router0.Middlewares": "foobar, fiibar",
-		"traefik.Routers.Router0.Priority":    "42",
-		"traefik.Routers.Router0.Rule":        "foobar",
-		"traefik.Routers.Router0.Service":     "foobar",
-		"traefik.Routers.Router1.EntryPoints": "foobar, fiibar",
-		"traefik.Routers.Router1.Middlewares": "foobar, fiibar",
-		"traefik.Routers.Router1.Priority":    "42",
-		"traefik.Routers.Router1.Rule":        "foobar",
-		"traefik.Routers.Router1.Service":     "foobar",
+		"traefik.Routers.Router0.EntryPoints":   "foobar, fiibar",
+		"traefik.Routers.Router0.LowPriority":   "true",
+		"traefik.Routers.Router0.Middlewares":   "foobar, fiibar",
+		"traefik.Routers.Router0.Priority":      "42",
+		"traefik.Routers.Router0.PriorityClass": "foobar",
+		"traefik.Routers.Router0.Rule":          "foobar",
+		"traefik.Routers.Router0.Service":       "foobar",
+		"traefik.Routers.Router1.EntryPoints":   "foobar, fiibar",
+		"traefik.Routers.Router1.LowPriority":   "false",
+		"traefik.Routers.Router1.Middlewares":   "foobar, fiibar",
+		"traefik.Routers.Router1.Priority":      "42",
+		"traefik.Routers.Router1.Rule":          "foobar",
+		"traefik.Routers.Router1.Service":       "foobar",
 
 		"traefik.Services.Service0.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
 		"traefik.Services.Service0.LoadBalancer.HealthCheck.Headers.name1":        "foobar",
@@ -879,7 +889,10 @@ func TestEncode(t *testing.T) {
 		"traefik.Services.Service0.LoadBalancer.HealthCheck.Timeout":              "foobar",
 		"traefik.Services.Service0.LoadBalancer.Method":                           "foobar",
 		"traefik.Services.Service0.LoadBalancer.PassHostHeader":                   "true",
+		"traefik.Services.Service0.LoadBalancer.RemovalGracePeriod":               "0",
+		"traefik.Services.Service0.LoadBalancer.ResponseForwarding.BufferSize":    "0",
 		"traefik.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
+		"traefik.Services.Service0.LoadBalancer.server.LastResort":                "false",
 		"traefik.Services.Service0.LoadBalancer.server.URL":                       "foobar",
 		"traefik.Services.Service0.LoadBalancer.server.Weight":                    "42",
 		"traefik.Services.Service0.LoadBalancer.Stickiness.CookieName":            "foobar",
@@ -893,7 +906,10 @@ func TestEncode(t *testing.T) {
 		"traefik.Services.Service1.LoadBalancer.HealthCheck.Timeout":              "foobar",
 		"traefik.Services.Service1.LoadBalancer.Method":                           "foobar",
 		"traefik.Services.Service1.LoadBalancer.PassHostHeader":                   "true",
+		"traefik.Services.Service1.LoadBalancer.RemovalGracePeriod":               "0",
+		"traefik.Services.Service1.LoadBalancer.ResponseForwarding.BufferSize":    "0",
 		"traefik.Services.Service1.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
+		"traefik.Services.Service1.LoadBalancer.server.LastResort":                "false",
 		"traefik.Services.Service1.LoadBalancer.server.URL":                       "foobar",
 		"traefik.Services.Service1.LoadBalancer.server.Weight":                    "42",
 	}
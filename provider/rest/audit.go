@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/audit"
+	"github.com/containous/traefik/log"
+)
+
+// maxAuditEntries is the number of changes kept in the audit log.
+const maxAuditEntries = 100
+
+// AuditEntry records a change of the configuration made through the REST API.
+type AuditEntry struct {
+	Date       time.Time `json:"date"`
+	User       string    `json:"user"`
+	RemoteAddr string    `json:"remoteAddr"`
+	Action     string    `json:"action"`
+	Resource   string    `json:"resource"`
+	Version    uint64    `json:"version"`
+}
+
+func newAuditEntry(request *http.Request, user, action, resource string) AuditEntry {
+	return AuditEntry{
+		Date:       time.Now().UTC(),
+		User:       user,
+		RemoteAddr: request.RemoteAddr,
+		Action:     action,
+		Resource:   resource,
+	}
+}
+
+// auditLog keeps the last changes of the configuration, and logs them.
+type auditLog struct {
+	lock sync.RWMutex
+	log  []AuditEntry
+}
+
+func (a *auditLog) add(entry AuditEntry) {
+	log.WithoutContext().WithField(log.ProviderName, "rest").
+		Infof("Configuration %s of %s by %s (%s), version %d", entry.Action, entry.Resource, entry.User, entry.RemoteAddr, entry.Version)
+
+	audit.Record(audit.Entry{
+		Time:       entry.Date,
+		Category:   audit.CategoryConfiguration,
+		Action:     "rest." + entry.Action,
+		User:       entry.User,
+		RemoteAddr: entry.RemoteAddr,
+		Resource:   entry.Resource,
+		Details:    map[string]string{"version": strconv.FormatUint(entry.Version, 10)},
+	})
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.log = append(a.log, entry)
+	if len(a.log) > maxAuditEntries {
+		a.log = a.log[len(a.log)-maxAuditEntries:]
+	}
+}
+
+// entries returns the recorded changes, the most recent first.
+func (a *auditLog) entries() []AuditEntry {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	entries := make([]AuditEntry, 0, len(a.log))
+	for i := len(a.log) - 1; i >= 0; i-- {
+		entries = append(entries, a.log[i])
+	}
+	return entries
+}
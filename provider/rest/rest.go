@@ -1,10 +1,15 @@
 package rest
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/containous/mux"
 	"github.com/containous/traefik/config"
@@ -16,16 +21,37 @@ import (
 
 var _ provider.Provider = (*Provider)(nil)
 
+const (
+	resourceRouters     = "routers"
+	resourceMiddlewares = "middlewares"
+	resourceServices    = "services"
+)
+
 // Provider is a provider.Provider implementation that provides a Rest API.
 type Provider struct {
-	configurationChan chan<- config.Message
-	EntryPoint        string `description:"EntryPoint" export:"true"`
+	EntryPoint        string   `description:"EntryPoint" export:"true"`
+	Token             string   `description:"Bearer token required to modify the configuration"`
+	ClientCommonNames []string `description:"Common names of the client certificates allowed to modify the configuration" export:"true"`
+
+	lock          sync.Mutex
+	configuration *config.Configuration
+	version       uint64
+	audit         auditLog
+	// updates notifies the sending of the configuration to Traefik, outside of the lock,
+	// so that a busy configuration watcher doesn't block the other changes.
+	updates chan struct{}
 }
 
 var templatesRenderer = render.New(render.Options{Directory: "nowhere"})
 
+var errNotStarted = errors.New("the REST provider is not started yet")
+
 // Init the provider.
 func (p *Provider) Init() error {
+	if len(p.Token) == 0 && len(p.ClientCommonNames) == 0 {
+		log.WithoutContext().WithField(log.ProviderName, "rest").
+			Warn("No token nor client certificate required: anyone reaching the API can modify the configuration")
+	}
 	return nil
 }
 
@@ -34,34 +60,335 @@ func (p *Provider) Append(systemRouter *mux.Router) {
 	systemRouter.
 		Methods(http.MethodPut).
 		Path("/api/providers/{provider}").
-		HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		HandlerFunc(p.putConfigurationHandler)
 
-			vars := mux.Vars(request)
-			if vars["provider"] != "rest" {
-				response.WriteHeader(http.StatusBadRequest)
-				fmt.Fprint(response, "Only 'rest' provider can be updated through the REST API")
-				return
-			}
+	systemRouter.
+		Methods(http.MethodGet).
+		Path("/api/providers/rest/version").
+		HandlerFunc(p.getVersionHandler)
 
-			configuration := new(config.Configuration)
-			body, _ := ioutil.ReadAll(request.Body)
-			err := json.Unmarshal(body, configuration)
-			if err == nil {
-				p.configurationChan <- config.Message{ProviderName: "rest", Configuration: configuration}
-				err := templatesRenderer.JSON(response, http.StatusOK, configuration)
-				if err != nil {
-					log.WithoutContext().Error(err)
-				}
-			} else {
-				log.WithoutContext().Errorf("Error parsing configuration %+v", err)
-				http.Error(response, fmt.Sprintf("%+v", err), http.StatusBadRequest)
-			}
-		})
+	systemRouter.
+		Methods(http.MethodGet).
+		Path("/api/providers/rest/audit").
+		HandlerFunc(p.getAuditHandler)
+
+	systemRouter.
+		Methods(http.MethodPut, http.MethodDelete).
+		Path("/api/providers/{provider}/{resource:routers|middlewares|services}/{name}").
+		HandlerFunc(p.resourceHandler)
 }
 
 // Provide allows the provider to provide configurations to traefik
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- config.Message, pool *safe.Pool) error {
-	p.configurationChan = configurationChan
+	updates := make(chan struct{}, 1)
+
+	pool.Go(func(stop chan bool) {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-updates:
+			}
+
+			// The configuration sent is the latest one, the changes made while a sending is pending being sent at once.
+			p.lock.Lock()
+			configuration := p.configuration
+			p.lock.Unlock()
+
+			select {
+			case <-stop:
+				return
+			case configurationChan <- config.Message{ProviderName: "rest", Configuration: configuration}:
+			}
+		}
+	})
+
+	p.lock.Lock()
+	p.updates = updates
+	p.lock.Unlock()
+
+	return nil
+}
+
+func (p *Provider) putConfigurationHandler(response http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	if vars["provider"] != "rest" {
+		response.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(response, "Only 'rest' provider can be updated through the REST API")
+		return
+	}
+
+	user, ok := p.authorize(request)
+	if !ok {
+		http.Error(response, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	configuration := new(config.Configuration)
+	body, _ := ioutil.ReadAll(request.Body)
+	if err := json.Unmarshal(body, configuration); err != nil {
+		log.WithoutContext().Errorf("Error parsing configuration %+v", err)
+		http.Error(response, fmt.Sprintf("%+v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := provider.ValidateConfiguration(configuration); err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.matchVersion(request) {
+		http.Error(response, "The configuration has been modified since the given version", http.StatusPreconditionFailed)
+		return
+	}
+
+	if err := p.apply(configuration, newAuditEntry(request, user, "replace", "configuration")); err != nil {
+		http.Error(response, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	response.Header().Set("ETag", p.etag())
+	if err := templatesRenderer.JSON(response, http.StatusOK, configuration); err != nil {
+		log.WithoutContext().Error(err)
+	}
+}
+
+func (p *Provider) resourceHandler(response http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	if vars["provider"] != "rest" {
+		response.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(response, "Only 'rest' provider can be updated through the REST API")
+		return
+	}
+
+	user, ok := p.authorize(request)
+	if !ok {
+		http.Error(response, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	resource, name := vars["resource"], vars["name"]
+
+	var value interface{}
+	if request.Method == http.MethodPut {
+		var err error
+		value, err = decodeResource(request, resource)
+		if err != nil {
+			http.Error(response, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.matchVersion(request) {
+		http.Error(response, "The configuration has been modified since the given version", http.StatusPreconditionFailed)
+		return
+	}
+
+	configuration := p.copyConfiguration()
+
+	action := "update"
+	if request.Method == http.MethodDelete {
+		action = "delete"
+		if !deleteResource(configuration, resource, name) {
+			http.NotFound(response, request)
+			return
+		}
+	} else {
+		setResource(configuration, resource, name, value)
+	}
+
+	if err := p.apply(configuration, newAuditEntry(request, user, action, resource+"/"+name)); err != nil {
+		http.Error(response, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	response.Header().Set("ETag", p.etag())
+	if request.Method == http.MethodDelete {
+		response.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := templatesRenderer.JSON(response, http.StatusOK, value); err != nil {
+		log.WithoutContext().Error(err)
+	}
+}
+
+func (p *Provider) getVersionHandler(response http.ResponseWriter, request *http.Request) {
+	p.lock.Lock()
+	version := p.version
+	etag := p.etag()
+	p.lock.Unlock()
+
+	response.Header().Set("ETag", etag)
+	if err := templatesRenderer.JSON(response, http.StatusOK, map[string]uint64{"version": version}); err != nil {
+		log.WithoutContext().Error(err)
+	}
+}
+
+func (p *Provider) getAuditHandler(response http.ResponseWriter, request *http.Request) {
+	if _, ok := p.authorize(request); !ok {
+		http.Error(response, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if err := templatesRenderer.JSON(response, http.StatusOK, p.audit.entries()); err != nil {
+		log.WithoutContext().Error(err)
+	}
+}
+
+// apply records the configuration and the change, and has the configuration sent to Traefik.
+// It must be called with the lock held.
+func (p *Provider) apply(configuration *config.Configuration, entry AuditEntry) error {
+	if p.updates == nil {
+		return errNotStarted
+	}
+
+	p.configuration = configuration
+	p.version++
+
+	entry.Version = p.version
+	p.audit.add(entry)
+
+	select {
+	case p.updates <- struct{}{}:
+	default:
+		// A sending is already pending, it sends this configuration.
+	}
 	return nil
 }
+
+// copyConfiguration returns a copy of the current configuration which can be modified.
+// It must be called with the lock held.
+func (p *Provider) copyConfiguration() *config.Configuration {
+	configuration := &config.Configuration{
+		Routers:     make(map[string]*config.Router),
+		Middlewares: make(map[string]*config.Middleware),
+		Services:    make(map[string]*config.Service),
+	}
+
+	if p.configuration == nil {
+		return configuration
+	}
+
+	for name, router := range p.configuration.Routers {
+		configuration.Routers[name] = router
+	}
+	for name, middleware := range p.configuration.Middlewares {
+		configuration.Middlewares[name] = middleware
+	}
+	for name, service := range p.configuration.Services {
+		configuration.Services[name] = service
+	}
+	configuration.TLS = p.configuration.TLS
+
+	return configuration
+}
+
+// matchVersion checks the If-Match header against the current version of the configuration.
+// It must be called with the lock held.
+func (p *Provider) matchVersion(request *http.Request) bool {
+	ifMatch := request.Header.Get("If-Match")
+	if len(ifMatch) == 0 || ifMatch == "*" {
+		return true
+	}
+
+	for _, etag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(etag) == p.etag() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Provider) etag() string {
+	return strconv.Quote(strconv.FormatUint(p.version, 10))
+}
+
+// authorize checks that the client is allowed to modify the configuration, and returns its identity.
+func (p *Provider) authorize(request *http.Request) (string, bool) {
+	if len(p.Token) == 0 && len(p.ClientCommonNames) == 0 {
+		return "anonymous", true
+	}
+
+	if request.TLS != nil && len(request.TLS.VerifiedChains) > 0 && len(request.TLS.VerifiedChains[0]) > 0 {
+		commonName := request.TLS.VerifiedChains[0][0].Subject.CommonName
+		for _, allowed := range p.ClientCommonNames {
+			if commonName == allowed {
+				return "CN=" + commonName, true
+			}
+		}
+	}
+
+	if len(p.Token) > 0 {
+		token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(p.Token)) == 1 {
+			return "token", true
+		}
+	}
+
+	return "", false
+}
+
+func decodeResource(request *http.Request, resource string) (interface{}, error) {
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resource {
+	case resourceRouters:
+		router := &config.Router{}
+		if err := json.Unmarshal(body, router); err != nil {
+			return nil, err
+		}
+		return router, provider.ValidateRouter(router)
+	case resourceMiddlewares:
+		middleware := &config.Middleware{}
+		if err := json.Unmarshal(body, middleware); err != nil {
+			return nil, err
+		}
+		return middleware, provider.ValidateMiddleware(middleware)
+	case resourceServices:
+		service := &config.Service{}
+		if err := json.Unmarshal(body, service); err != nil {
+			return nil, err
+		}
+		return service, provider.ValidateService(service)
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", resource)
+	}
+}
+
+func setResource(configuration *config.Configuration, resource, name string, value interface{}) {
+	switch resource {
+	case resourceRouters:
+		configuration.Routers[name] = value.(*config.Router)
+	case resourceMiddlewares:
+		configuration.Middlewares[name] = value.(*config.Middleware)
+	case resourceServices:
+		configuration.Services[name] = value.(*config.Service)
+	}
+}
+
+func deleteResource(configuration *config.Configuration, resource, name string) bool {
+	var exists bool
+	switch resource {
+	case resourceRouters:
+		_, exists = configuration.Routers[name]
+		delete(configuration.Routers, name)
+	case resourceMiddlewares:
+		_, exists = configuration.Middlewares[name]
+		delete(configuration.Middlewares, name)
+	case resourceServices:
+		_, exists = configuration.Services[name]
+		delete(configuration.Services, name)
+	}
+	return exists
+}
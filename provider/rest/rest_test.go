@@ -0,0 +1,289 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/safe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	routerJSON  = `{"entryPoints":["web"],"service":"foo","rule":"Host:foo.bar"}`
+	serviceJSON = `{"loadbalancer":{"servers":[{"url":"http://127.0.0.1:8080","weight":1}]}}`
+)
+
+func TestProvider_Resources(t *testing.T) {
+	p, configurationChan, pool := newTestProvider(&Provider{})
+	defer pool.Stop()
+
+	router := mux.NewRouter()
+	p.Append(router)
+
+	rw := serve(router, http.MethodPut, "/api/providers/rest/services/foo", serviceJSON, nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, `"1"`, rw.Header().Get("ETag"))
+
+	msg := <-configurationChan
+	assert.Equal(t, "rest", msg.ProviderName)
+	assert.Len(t, msg.Configuration.Services, 1)
+
+	rw = serve(router, http.MethodPut, "/api/providers/rest/routers/foo", routerJSON, map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, `"2"`, rw.Header().Get("ETag"))
+
+	msg = <-configurationChan
+	assert.Equal(t, &config.Router{EntryPoints: []string{"web"}, Service: "foo", Rule: "Host:foo.bar"}, msg.Configuration.Routers["foo"])
+	assert.Len(t, msg.Configuration.Services, 1)
+
+	// Outdated version.
+	rw = serve(router, http.MethodDelete, "/api/providers/rest/routers/foo", "", map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, http.StatusPreconditionFailed, rw.Code)
+
+	rw = serve(router, http.MethodDelete, "/api/providers/rest/routers/bar", "", nil)
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw = serve(router, http.MethodDelete, "/api/providers/rest/routers/foo", "", map[string]string{"If-Match": `"2"`})
+	require.Equal(t, http.StatusNoContent, rw.Code)
+
+	msg = <-configurationChan
+	assert.Empty(t, msg.Configuration.Routers)
+	assert.Len(t, msg.Configuration.Services, 1)
+
+	entries := p.audit.entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "delete", entries[0].Action)
+	assert.Equal(t, "routers/foo", entries[0].Resource)
+	assert.Equal(t, uint64(3), entries[0].Version)
+	assert.Equal(t, "anonymous", entries[0].User)
+}
+
+func TestProvider_Validation(t *testing.T) {
+	testCases := []struct {
+		desc string
+		path string
+		body string
+	}{
+		{
+			desc: "router without rule",
+			path: "/api/providers/rest/routers/foo",
+			body: `{"service":"foo"}`,
+		},
+		{
+			desc: "service without server",
+			path: "/api/providers/rest/services/foo",
+			body: `{"loadbalancer":{}}`,
+		},
+		{
+			desc: "service with an invalid URL",
+			path: "/api/providers/rest/services/foo",
+			body: `{"loadbalancer":{"servers":[{"url":"127.0.0.1"}]}}`,
+		},
+		{
+			desc: "blue/green service without green service",
+			path: "/api/providers/rest/services/foo",
+			body: `{"blueGreen":{"blue":"foo-blue","live":"blue"}}`,
+		},
+		{
+			desc: "blue/green service with an invalid live color",
+			path: "/api/providers/rest/services/foo",
+			body: `{"blueGreen":{"blue":"foo-blue","green":"foo-green","live":"red"}}`,
+		},
+		{
+			desc: "middleware with two types",
+			path: "/api/providers/rest/middlewares/foo",
+			body: `{"addPrefix":{"prefix":"/foo"},"stripPrefix":{"prefixes":["/bar"]}}`,
+		},
+		{
+			desc: "configuration with an invalid middleware",
+			path: "/api/providers/rest",
+			body: `{"middlewares":{"foo":{}}}`,
+		},
+		{
+			desc: "not the rest provider",
+			path: "/api/providers/file/routers/foo",
+			body: routerJSON,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p, _, pool := newTestProvider(&Provider{})
+			defer pool.Stop()
+
+			router := mux.NewRouter()
+			p.Append(router)
+
+			rw := serve(router, http.MethodPut, test.path, test.body, nil)
+			assert.Equal(t, http.StatusBadRequest, rw.Code)
+			assert.Empty(t, p.audit.entries())
+		})
+	}
+}
+
+func TestProvider_BlueGreen(t *testing.T) {
+	p, configurationChan, pool := newTestProvider(&Provider{})
+	defer pool.Stop()
+
+	router := mux.NewRouter()
+	p.Append(router)
+
+	rw := serve(router, http.MethodPut, "/api/providers/rest/services/foo", `{"blueGreen":{"blue":"foo-blue","green":"foo-green","live":"green"}}`, nil)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	msg := <-configurationChan
+	expected := &config.Service{BlueGreen: &config.BlueGreenService{Blue: "foo-blue", Green: "foo-green", Live: "green"}}
+	assert.Equal(t, expected, msg.Configuration.Services["foo"])
+}
+
+func TestProvider_NotStarted(t *testing.T) {
+	p := &Provider{}
+
+	router := mux.NewRouter()
+	p.Append(router)
+
+	rw := serve(router, http.MethodPut, "/api/providers/rest/services/foo", serviceJSON, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+	assert.Empty(t, p.audit.entries())
+}
+
+func TestProvider_BusyWatcher(t *testing.T) {
+	p := &Provider{}
+
+	// Nobody receives the configurations yet.
+	configurationChan := make(chan config.Message)
+	pool := safe.NewPool(context.Background())
+	defer pool.Stop()
+	require.NoError(t, p.Provide(configurationChan, pool))
+
+	router := mux.NewRouter()
+	p.Append(router)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		rw := serve(router, http.MethodPut, "/api/providers/rest/services/foo", serviceJSON, nil)
+		assert.Equal(t, http.StatusOK, rw.Code)
+
+		rw = serve(router, http.MethodPut, "/api/providers/rest/services/bar", serviceJSON, nil)
+		assert.Equal(t, http.StatusOK, rw.Code)
+
+		rw = serve(router, http.MethodGet, "/api/providers/rest/version", "", nil)
+		assert.Equal(t, `"2"`, rw.Header().Get("ETag"))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the changes to be made")
+	}
+
+	// The watcher gets the latest configuration.
+	var msg config.Message
+	for msg.Configuration == nil || len(msg.Configuration.Services) < 2 {
+		select {
+		case msg = <-configurationChan:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the configuration")
+		}
+	}
+	assert.Len(t, msg.Configuration.Services, 2)
+}
+
+func TestProvider_Authorize(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		provider     *Provider
+		headers      map[string]string
+		commonName   string
+		expectedUser string
+		expectedOK   bool
+	}{
+		{
+			desc:         "no authentication required",
+			provider:     &Provider{},
+			expectedUser: "anonymous",
+			expectedOK:   true,
+		},
+		{
+			desc:     "missing token",
+			provider: &Provider{Token: "secret"},
+		},
+		{
+			desc:     "wrong token",
+			provider: &Provider{Token: "secret"},
+			headers:  map[string]string{"Authorization": "Bearer foo"},
+		},
+		{
+			desc:         "valid token",
+			provider:     &Provider{Token: "secret"},
+			headers:      map[string]string{"Authorization": "Bearer secret"},
+			expectedUser: "token",
+			expectedOK:   true,
+		},
+		{
+			desc:         "allowed client certificate",
+			provider:     &Provider{ClientCommonNames: []string{"admin"}},
+			commonName:   "admin",
+			expectedUser: "CN=admin",
+			expectedOK:   true,
+		},
+		{
+			desc:       "unknown client certificate",
+			provider:   &Provider{ClientCommonNames: []string{"admin"}},
+			commonName: "guest",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/providers/rest", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			if len(test.commonName) > 0 {
+				cert := &x509.Certificate{Subject: pkix.Name{CommonName: test.commonName}}
+				req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+			}
+
+			user, ok := test.provider.authorize(req)
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedUser, user)
+		})
+	}
+}
+
+func newTestProvider(p *Provider) (*Provider, chan config.Message, *safe.Pool) {
+	configurationChan := make(chan config.Message, 10)
+	pool := safe.NewPool(context.Background())
+	_ = p.Provide(configurationChan, pool)
+	return p, configurationChan, pool
+}
+
+func serve(router http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, req)
+	return rw
+}
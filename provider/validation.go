@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/containous/traefik/bluegreen"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/server/service"
+)
+
+// ValidateConfiguration checks that all the elements of a configuration are usable.
+func ValidateConfiguration(configuration *config.Configuration) error {
+	for name, router := range configuration.Routers {
+		if err := ValidateRouter(router); err != nil {
+			return fmt.Errorf("invalid router %s: %v", name, err)
+		}
+	}
+
+	for name, middleware := range configuration.Middlewares {
+		if err := ValidateMiddleware(middleware); err != nil {
+			return fmt.Errorf("invalid middleware %s: %v", name, err)
+		}
+	}
+
+	for name, service := range configuration.Services {
+		if err := ValidateService(service); err != nil {
+			return fmt.Errorf("invalid service %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateRouter checks that a router has a rule and a service.
+func ValidateRouter(router *config.Router) error {
+	if router == nil {
+		return errors.New("empty router")
+	}
+	if len(router.Rule) == 0 {
+		return errors.New("no rule defined")
+	}
+	if len(router.Service) == 0 {
+		return errors.New("no service defined")
+	}
+	return nil
+}
+
+// ValidateMiddleware checks that exactly one type of middleware is defined.
+func ValidateMiddleware(middleware *config.Middleware) error {
+	if middleware == nil {
+		return errors.New("empty middleware")
+	}
+
+	var count int
+	value := reflect.ValueOf(middleware).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if !value.Field(i).IsNil() {
+			count++
+		}
+	}
+
+	switch count {
+	case 0:
+		return errors.New("no middleware type defined")
+	case 1:
+		return nil
+	default:
+		return errors.New("only one middleware type can be defined")
+	}
+}
+
+// ValidateService checks that a service has a load balancer with valid server URLs, a blue/green pair of services, or a function.
+func ValidateService(conf *config.Service) error {
+	if conf != nil && conf.BlueGreen != nil {
+		return validateBlueGreen(conf.BlueGreen)
+	}
+
+	if conf != nil && conf.Function != nil {
+		if conf.Function.Lambda == nil && conf.Function.CloudEvents == nil {
+			return errors.New("no function defined")
+		}
+		return nil
+	}
+
+	if conf == nil || conf.LoadBalancer == nil {
+		return errors.New("no load balancer defined")
+	}
+
+	if len(conf.LoadBalancer.Servers) == 0 {
+		return errors.New("no server defined")
+	}
+
+	for _, server := range conf.LoadBalancer.Servers {
+		u, err := service.ParseServerURL(server.URL)
+		if err != nil {
+			return fmt.Errorf("invalid server URL %q: %v", server.URL, err)
+		}
+		if len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("invalid server URL %q: scheme and host are required", server.URL)
+		}
+	}
+
+	return nil
+}
+
+// validateBlueGreen checks that a blue/green service references both of its services, and that its live color is blue or green.
+// An empty live color stands for the blue one.
+func validateBlueGreen(conf *config.BlueGreenService) error {
+	if len(conf.Blue) == 0 || len(conf.Green) == 0 {
+		return errors.New("blue and green services are required")
+	}
+
+	switch conf.Live {
+	case "", bluegreen.Blue, bluegreen.Green:
+		return nil
+	default:
+		return fmt.Errorf("invalid live color %q, expected blue or green", conf.Live)
+	}
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateService(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		url         string
+		expectedErr bool
+	}{
+		{
+			desc: "HTTP URL",
+			url:  "http://127.0.0.1:8080",
+		},
+		{
+			desc: "Unix socket URL",
+			url:  "unix:///var/run/app.sock",
+		},
+		{
+			desc:        "Unix socket URL without path",
+			url:         "unix://",
+			expectedErr: true,
+		},
+		{
+			desc:        "URL without scheme",
+			url:         "127.0.0.1",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateService(&config.Service{
+				LoadBalancer: &config.LoadBalancerService{Servers: []config.Server{{URL: test.url}}},
+			})
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
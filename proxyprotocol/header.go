@@ -0,0 +1,244 @@
+package proxyprotocol
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Types of the TLVs (Type-Length-Value) of a version 2 header.
+const (
+	TypeALPN      byte = 0x01
+	TypeAuthority byte = 0x02
+	TypeCRC32C    byte = 0x03
+	TypeNoop      byte = 0x04
+	TypeUniqueID  byte = 0x05
+	TypeSSL       byte = 0x20
+	TypeNetNS     byte = 0x30
+)
+
+const (
+	commandLocal = 0x0
+	commandProxy = 0x1
+
+	familyUnspec = 0x00
+	familyTCP4   = 0x11
+	familyTCP6   = 0x21
+
+	// v1MaxLength is the maximum length of a version 1 header, CRLF included.
+	v1MaxLength = 107
+)
+
+var (
+	v1Signature = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// TLV is an additional information of a version 2 header.
+type TLV struct {
+	Type  byte
+	Value []byte
+}
+
+// Header is a PROXY protocol header.
+// Source and Destination are nil when the header carries no address,
+// e.g. for the health checks of the proxy (LOCAL command) or an UNKNOWN protocol.
+type Header struct {
+	Version     int
+	Source      *net.TCPAddr
+	Destination *net.TCPAddr
+	TLVs        []TLV
+}
+
+// TLV returns the value of the first TLV of the given type.
+func (h *Header) TLV(tlvType byte) ([]byte, bool) {
+	for _, tlv := range h.TLVs {
+		if tlv.Type == tlvType {
+			return tlv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// TLSTLVs returns the TLVs describing a TLS connection terminated by Traefik: the server name (SNI) and the negotiated protocol (ALPN).
+func TLSTLVs(state tls.ConnectionState) []TLV {
+	var tlvs []TLV
+	if len(state.NegotiatedProtocol) > 0 {
+		tlvs = append(tlvs, TLV{Type: TypeALPN, Value: []byte(state.NegotiatedProtocol)})
+	}
+	if len(state.ServerName) > 0 {
+		tlvs = append(tlvs, TLV{Type: TypeAuthority, Value: []byte(state.ServerName)})
+	}
+	return tlvs
+}
+
+// Format returns the header encoded in the binary format of the version 2 of the protocol, whatever its Version.
+func (h *Header) Format() ([]byte, error) {
+	var family byte
+	var addresses []byte
+
+	switch {
+	case h.Source == nil || h.Destination == nil:
+		family = familyUnspec
+	case h.Source.IP.To4() != nil && h.Destination.IP.To4() != nil:
+		family = familyTCP4
+		addresses = append(addresses, h.Source.IP.To4()...)
+		addresses = append(addresses, h.Destination.IP.To4()...)
+	default:
+		family = familyTCP6
+		addresses = append(addresses, h.Source.IP.To16()...)
+		addresses = append(addresses, h.Destination.IP.To16()...)
+	}
+
+	if family != familyUnspec {
+		addresses = append(addresses, byte(h.Source.Port>>8), byte(h.Source.Port))
+		addresses = append(addresses, byte(h.Destination.Port>>8), byte(h.Destination.Port))
+	}
+
+	payload := addresses
+	for _, tlv := range h.TLVs {
+		if len(tlv.Value) > 0xffff {
+			return nil, fmt.Errorf("TLV 0x%02x too long: %d bytes", tlv.Type, len(tlv.Value))
+		}
+		payload = append(payload, tlv.Type, byte(len(tlv.Value)>>8), byte(len(tlv.Value)))
+		payload = append(payload, tlv.Value...)
+	}
+
+	if len(payload) > 0xffff {
+		return nil, fmt.Errorf("header too long: %d bytes", len(payload))
+	}
+
+	command := byte(commandProxy)
+	if family == familyUnspec {
+		command = commandLocal
+	}
+
+	buf := bytes.NewBuffer(nil)
+	buf.Write(v2Signature)
+	buf.WriteByte(0x20 | command)
+	buf.WriteByte(family)
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(payload)))
+	buf.Write(payload)
+
+	return buf.Bytes(), nil
+}
+
+// parseV1 parses the line of a version 1 header, without its CRLF.
+func parseV1(line string) (*Header, error) {
+	// PROXY <protocol> <source IP> <destination IP> <source port> <destination port>
+	parts := strings.Split(line, " ")
+	if len(parts) < 2 || parts[0] != "PROXY" {
+		return nil, fmt.Errorf("invalid header line: %s", line)
+	}
+
+	header := &Header{Version: 1}
+
+	switch parts[1] {
+	case "UNKNOWN":
+		return header, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, fmt.Errorf("unhandled address type: %s", parts[1])
+	}
+
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("invalid header line: %s", line)
+	}
+
+	var err error
+	header.Source, err = parseV1Address(parts[2], parts[4])
+	if err != nil {
+		return nil, fmt.Errorf("invalid source: %v", err)
+	}
+
+	header.Destination, err = parseV1Address(parts[3], parts[5])
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination: %v", err)
+	}
+
+	return header, nil
+}
+
+func parseV1Address(rawIP, rawPort string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(rawIP)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP: %s", rawIP)
+	}
+
+	port, err := strconv.ParseUint(rawPort, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port: %s", rawPort)
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// parseV2 parses the payload (addresses and TLVs) of a version 2 header.
+func parseV2(verCmd, family byte, payload []byte) (*Header, error) {
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("unsupported version: %d", verCmd>>4)
+	}
+
+	header := &Header{Version: 2}
+
+	var addressesLength int
+	switch family {
+	case familyTCP4:
+		addressesLength = 12
+	case familyTCP6:
+		addressesLength = 36
+	default:
+		// The addresses of the other families (UDP, UNIX sockets) are skipped.
+		addressesLength = 0
+	}
+
+	if len(payload) < addressesLength {
+		return nil, errors.New("header too short for its addresses")
+	}
+
+	switch verCmd & 0x0f {
+	case commandLocal:
+	case commandProxy:
+		if addressesLength > 0 {
+			ipLength := (addressesLength - 4) / 2
+			ports := payload[2*ipLength:]
+			header.Source = &net.TCPAddr{
+				IP:   net.IP(append([]byte(nil), payload[:ipLength]...)),
+				Port: int(binary.BigEndian.Uint16(ports[:2])),
+			}
+			header.Destination = &net.TCPAddr{
+				IP:   net.IP(append([]byte(nil), payload[ipLength:2*ipLength]...)),
+				Port: int(binary.BigEndian.Uint16(ports[2:4])),
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported command: %d", verCmd&0x0f)
+	}
+
+	if family != familyTCP4 && family != familyTCP6 {
+		// Without known addresses, the TLVs can't be located.
+		return header, nil
+	}
+
+	tlvs := payload[addressesLength:]
+	for len(tlvs) > 0 {
+		if len(tlvs) < 3 {
+			return nil, errors.New("truncated TLV")
+		}
+
+		length := int(binary.BigEndian.Uint16(tlvs[1:3]))
+		if len(tlvs) < 3+length {
+			return nil, fmt.Errorf("truncated TLV 0x%02x", tlvs[0])
+		}
+
+		header.TLVs = append(header.TLVs, TLV{Type: tlvs[0], Value: append([]byte(nil), tlvs[3:3+length]...)})
+		tlvs = tlvs[3+length:]
+	}
+
+	return header, nil
+}
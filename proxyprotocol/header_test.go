@@ -0,0 +1,181 @@
+package proxyprotocol
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseV1(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		line           string
+		expectedHeader *Header
+		expectedErr    bool
+	}{
+		{
+			desc: "TCP4",
+			line: "PROXY TCP4 192.168.0.1 192.168.0.11 56324 443",
+			expectedHeader: &Header{
+				Version:     1,
+				Source:      &net.TCPAddr{IP: net.ParseIP("192.168.0.1"), Port: 56324},
+				Destination: &net.TCPAddr{IP: net.ParseIP("192.168.0.11"), Port: 443},
+			},
+		},
+		{
+			desc: "TCP6",
+			line: "PROXY TCP6 ::1 ::2 56324 443",
+			expectedHeader: &Header{
+				Version:     1,
+				Source:      &net.TCPAddr{IP: net.ParseIP("::1"), Port: 56324},
+				Destination: &net.TCPAddr{IP: net.ParseIP("::2"), Port: 443},
+			},
+		},
+		{
+			desc:           "UNKNOWN",
+			line:           "PROXY UNKNOWN",
+			expectedHeader: &Header{Version: 1},
+		},
+		{
+			desc:        "unknown protocol",
+			line:        "PROXY UDP4 192.168.0.1 192.168.0.11 56324 443",
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid port",
+			line:        "PROXY TCP4 192.168.0.1 192.168.0.11 70000 443",
+			expectedErr: true,
+		},
+		{
+			desc:        "missing fields",
+			line:        "PROXY TCP4 192.168.0.1 192.168.0.11",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			header, err := parseV1(test.line)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedHeader, header)
+		})
+	}
+}
+
+func TestFormatParseV2(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		header *Header
+	}{
+		{
+			desc: "TCP4 with TLVs",
+			header: &Header{
+				Version:     2,
+				Source:      &net.TCPAddr{IP: net.ParseIP("192.168.0.1").To4(), Port: 56324},
+				Destination: &net.TCPAddr{IP: net.ParseIP("192.168.0.11").To4(), Port: 443},
+				TLVs: []TLV{
+					{Type: TypeAuthority, Value: []byte("foo.bar")},
+					{Type: TypeUniqueID, Value: []byte{0x01, 0x02}},
+				},
+			},
+		},
+		{
+			desc: "TCP6",
+			header: &Header{
+				Version:     2,
+				Source:      &net.TCPAddr{IP: net.ParseIP("::1"), Port: 56324},
+				Destination: &net.TCPAddr{IP: net.ParseIP("::2"), Port: 443},
+			},
+		},
+		{
+			desc:   "LOCAL",
+			header: &Header{Version: 2},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := test.header.Format()
+			require.NoError(t, err)
+
+			require.True(t, len(raw) >= len(v2Signature)+4)
+			assert.Equal(t, v2Signature, raw[:len(v2Signature)])
+
+			header, err := parseV2(raw[len(v2Signature)], raw[len(v2Signature)+1], raw[len(v2Signature)+4:])
+			require.NoError(t, err)
+			assert.Equal(t, test.header, header)
+		})
+	}
+}
+
+func TestParseV2Errors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		verCmd  byte
+		family  byte
+		payload []byte
+	}{
+		{
+			desc:   "unsupported version",
+			verCmd: 0x11,
+			family: familyTCP4,
+		},
+		{
+			desc:   "unsupported command",
+			verCmd: 0x22,
+			family: familyTCP4,
+		},
+		{
+			desc:    "truncated addresses",
+			verCmd:  0x21,
+			family:  familyTCP4,
+			payload: []byte{192, 168, 0, 1},
+		},
+		{
+			desc:    "truncated TLV",
+			verCmd:  0x21,
+			family:  familyTCP4,
+			payload: []byte{192, 168, 0, 1, 192, 168, 0, 11, 0, 80, 0, 80, TypeAuthority, 0, 10, 'f'},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseV2(test.verCmd, test.family, test.payload)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTLSTLVs(t *testing.T) {
+	tlvs := TLSTLVs(tls.ConnectionState{ServerName: "foo.bar", NegotiatedProtocol: "h2"})
+
+	header := &Header{TLVs: tlvs}
+
+	alpn, ok := header.TLV(TypeALPN)
+	require.True(t, ok)
+	assert.Equal(t, "h2", string(alpn))
+
+	authority, ok := header.TLV(TypeAuthority)
+	require.True(t, ok)
+	assert.Equal(t, "foo.bar", string(authority))
+
+	_, ok = header.TLV(TypeSSL)
+	assert.False(t, ok)
+}
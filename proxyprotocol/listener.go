@@ -0,0 +1,244 @@
+package proxyprotocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultHeaderTimeout is the time given by default to a client to send the header, or the bytes telling it has none.
+const DefaultHeaderTimeout = 10 * time.Second
+
+// SourceChecker decides whether the header sent by a source is trusted.
+// The header of an untrusted source is read and ignored: the addresses of the connection are used.
+type SourceChecker func(net.Addr) (bool, error)
+
+// Listener wraps a listener whose connections may start with a PROXY protocol header, version 1 or 2.
+// HeaderTimeout bounds the reading of the header, DefaultHeaderTimeout if it is zero.
+type Listener struct {
+	net.Listener
+	HeaderTimeout time.Duration
+	SourceCheck   SourceChecker
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	trusted := true
+	if l.SourceCheck != nil {
+		trusted, err = l.SourceCheck(conn.RemoteAddr())
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	headerTimeout := l.HeaderTimeout
+	if headerTimeout == 0 {
+		headerTimeout = DefaultHeaderTimeout
+	}
+
+	return NewConn(conn, headerTimeout, trusted), nil
+}
+
+// Conn is a connection which may start with a PROXY protocol header.
+// The header is read on the first call to Read, RemoteAddr, LocalAddr or Header.
+type Conn struct {
+	net.Conn
+	reader        *bufio.Reader
+	headerTimeout time.Duration
+	trusted       bool
+
+	once   sync.Once
+	header *Header
+	err    error
+
+	// readDeadline is the last read deadline set on the connection, restored once the header is read.
+	deadlineMu   sync.Mutex
+	readDeadline time.Time
+}
+
+// NewConn creates a new Conn. The header is ignored if the connection is not trusted.
+func NewConn(conn net.Conn, headerTimeout time.Duration, trusted bool) *Conn {
+	return &Conn{
+		Conn:          conn,
+		reader:        bufio.NewReader(conn),
+		headerTimeout: headerTimeout,
+		trusted:       trusted,
+	}
+}
+
+// Read reads data from the connection, after the header.
+func (c *Conn) Read(b []byte) (int, error) {
+	if _, err := c.Header(); err != nil {
+		return 0, err
+	}
+	return c.reader.Read(b)
+}
+
+// Header returns the header of a trusted connection, or nil if it has none.
+func (c *Conn) Header() (*Header, error) {
+	c.once.Do(func() {
+		header, err := c.readHeader()
+		if err != nil {
+			c.err = err
+			c.Conn.Close()
+			return
+		}
+		if c.trusted {
+			c.header = header
+		}
+	})
+	return c.header, c.err
+}
+
+// SetDeadline sets the read and write deadlines of the connection.
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	c.readDeadline = t
+	return c.Conn.SetDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline of the connection.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	c.readDeadline = t
+	return c.Conn.SetReadDeadline(t)
+}
+
+// RemoteAddr returns the address of the client given by the header, or the address of the peer.
+func (c *Conn) RemoteAddr() net.Addr {
+	if header, _ := c.Header(); header != nil && header.Source != nil {
+		return header.Source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the original destination given by the header, or the local address of the connection.
+func (c *Conn) LocalAddr() net.Addr {
+	if header, _ := c.Header(); header != nil && header.Destination != nil {
+		return header.Destination
+	}
+	return c.Conn.LocalAddr()
+}
+
+func (c *Conn) readHeader() (*Header, error) {
+	if c.headerTimeout > 0 {
+		if err := c.setHeaderDeadline(); err != nil {
+			return nil, err
+		}
+		defer c.restoreReadDeadline()
+	}
+
+	signature, err := c.matchSignature()
+	if err != nil || signature == nil {
+		return nil, err
+	}
+
+	if bytes.Equal(signature, v1Signature) {
+		return c.readV1()
+	}
+	return c.readV2()
+}
+
+// setHeaderDeadline bounds the reading of the header by the header timeout, unless the read deadline of the connection is earlier.
+func (c *Conn) setHeaderDeadline() error {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	deadline := time.Now().Add(c.headerTimeout)
+	if !c.readDeadline.IsZero() && c.readDeadline.Before(deadline) {
+		return nil
+	}
+	return c.Conn.SetReadDeadline(deadline)
+}
+
+// restoreReadDeadline sets back the read deadline of the connection, once the header is read.
+func (c *Conn) restoreReadDeadline() {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+
+	_ = c.Conn.SetReadDeadline(c.readDeadline)
+}
+
+// matchSignature returns the signature the connection starts with, if any.
+// It peeks one byte at a time, so a client which sends less bytes than the signature without a header doesn't block.
+func (c *Conn) matchSignature() ([]byte, error) {
+	candidates := [][]byte{v1Signature, v2Signature}
+
+	for i := 1; len(candidates) > 0; i++ {
+		buf, err := c.reader.Peek(i)
+		if err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		var matching [][]byte
+		for _, signature := range candidates {
+			if !bytes.HasPrefix(signature, buf) {
+				continue
+			}
+			if len(signature) == i {
+				return signature, nil
+			}
+			matching = append(matching, signature)
+		}
+		candidates = matching
+	}
+
+	return nil, nil
+}
+
+func (c *Conn) readV1() (*Header, error) {
+	var line []byte
+	for {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+
+		if b == '\n' {
+			break
+		}
+		if len(line) >= v1MaxLength {
+			return nil, errors.New("header line too long")
+		}
+	}
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, errors.New("header line not terminated by CRLF")
+	}
+
+	return parseV1(string(line[:len(line)-2]))
+}
+
+func (c *Conn) readV2() (*Header, error) {
+	// Signature, version and command, family, length.
+	fixed := make([]byte, len(v2Signature)+4)
+	if _, err := io.ReadFull(c.reader, fixed); err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(fixed[len(v2Signature)+2:]))
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return nil, err
+	}
+
+	return parseV2(fixed[len(v2Signature)], fixed[len(v2Signature)+1], payload)
+}
@@ -0,0 +1,180 @@
+package proxyprotocol
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConn(t *testing.T) {
+	v2Header, err := (&Header{
+		Source:      &net.TCPAddr{IP: net.ParseIP("10.0.0.1").To4(), Port: 1234},
+		Destination: &net.TCPAddr{IP: net.ParseIP("10.0.0.2").To4(), Port: 443},
+		TLVs:        []TLV{{Type: TypeAuthority, Value: []byte("foo.bar")}},
+	}).Format()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc               string
+		data               []byte
+		trusted            bool
+		expectedRemoteAddr string
+		expectedLocalAddr  string
+		expectedAuthority  string
+		expectedData       string
+		expectedErr        bool
+	}{
+		{
+			desc:               "version 1 header",
+			data:               []byte("PROXY TCP4 10.0.0.1 10.0.0.2 1234 443\r\nGET / HTTP/1.1\r\n"),
+			trusted:            true,
+			expectedRemoteAddr: "10.0.0.1:1234",
+			expectedLocalAddr:  "10.0.0.2:443",
+			expectedData:       "GET / HTTP/1.1\r\n",
+		},
+		{
+			desc:               "version 2 header",
+			data:               append(v2Header, []byte("GET / HTTP/1.1\r\n")...),
+			trusted:            true,
+			expectedRemoteAddr: "10.0.0.1:1234",
+			expectedLocalAddr:  "10.0.0.2:443",
+			expectedAuthority:  "foo.bar",
+			expectedData:       "GET / HTTP/1.1\r\n",
+		},
+		{
+			desc:         "without header",
+			data:         []byte("GET / HTTP/1.1\r\n"),
+			trusted:      true,
+			expectedData: "GET / HTTP/1.1\r\n",
+		},
+		{
+			desc:         "data shorter than the signatures",
+			data:         []byte("PRO"),
+			trusted:      true,
+			expectedData: "PRO",
+		},
+		{
+			desc:         "untrusted source",
+			data:         append(v2Header, []byte("GET / HTTP/1.1\r\n")...),
+			expectedData: "GET / HTTP/1.1\r\n",
+		},
+		{
+			desc:        "invalid header",
+			data:        []byte("PROXY TCP4 foo\r\nGET / HTTP/1.1\r\n"),
+			trusted:     true,
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, server := net.Pipe()
+			go func() {
+				_, _ = client.Write(test.data)
+				_ = client.Close()
+			}()
+
+			conn := NewConn(server, 0, test.trusted)
+			defer conn.Close()
+
+			header, err := conn.Header()
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if len(test.expectedRemoteAddr) > 0 {
+				assert.Equal(t, test.expectedRemoteAddr, conn.RemoteAddr().String())
+				assert.Equal(t, test.expectedLocalAddr, conn.LocalAddr().String())
+			} else {
+				assert.Equal(t, server.RemoteAddr(), conn.RemoteAddr())
+				assert.Equal(t, server.LocalAddr(), conn.LocalAddr())
+			}
+
+			if len(test.expectedAuthority) > 0 {
+				require.NotNil(t, header)
+				authority, ok := header.TLV(TypeAuthority)
+				require.True(t, ok)
+				assert.Equal(t, test.expectedAuthority, string(authority))
+			}
+
+			data, err := ioutil.ReadAll(conn)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedData, string(data))
+		})
+	}
+}
+
+func TestConnHeaderTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	conn := NewConn(server, 50*time.Millisecond, true)
+	defer conn.Close()
+
+	// The client sends nothing.
+	_, err := conn.Header()
+	require.Error(t, err)
+
+	netErr, ok := err.(net.Error)
+	require.True(t, ok)
+	assert.True(t, netErr.Timeout())
+}
+
+func TestConnRestoresReadDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	conn := NewConn(server, time.Minute, true)
+	defer conn.Close()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+
+	go func() {
+		_, _ = client.Write([]byte("PROXY TCP4 10.0.0.1 10.0.0.2 1234 443\r\nGET"))
+	}()
+
+	buf := make([]byte, 3)
+	_, err := io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "GET", string(buf))
+
+	// The read deadline set before the header is read still applies.
+	_, err = conn.Read(buf)
+	require.Error(t, err)
+
+	netErr, ok := err.(net.Error)
+	require.True(t, ok)
+	assert.True(t, netErr.Timeout())
+}
+
+func TestListenerDefaultHeaderTimeout(t *testing.T) {
+	netListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	listener := &Listener{Listener: netListener}
+	defer listener.Close()
+
+	go func() {
+		conn, errDial := net.Dial("tcp", netListener.Addr().String())
+		if errDial == nil {
+			defer conn.Close()
+		}
+	}()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.IsType(t, &Conn{}, conn)
+	assert.Equal(t, DefaultHeaderTimeout, conn.(*Conn).headerTimeout)
+}
@@ -27,6 +27,10 @@ func (f *Builder) Build(ctx context.Context, names []string) func(*http.Response
 				getLogger(ctx, middleName, "Headers").Debug("Creating Middleware (ResponseModifier)")
 
 				modifiers = append(modifiers, buildHeaders(conf.Headers))
+			} else if conf.SecureHeaders != nil {
+				getLogger(ctx, middleName, "SecureHeaders").Debug("Creating Middleware (ResponseModifier)")
+
+				modifiers = append(modifiers, buildHeaders(conf.SecureHeaders.Headers()))
 			} else if conf.Chain != nil {
 				getLogger(ctx, middleName, "Chain").Debug("Creating Middleware (ResponseModifier)")
 
@@ -87,6 +87,44 @@ func TestBuilderBuild(t *testing.T) {
 				assert.Equal(t, resp.Header.Get("Referrer-Policy"), "no-referrer")
 			},
 		},
+		{
+			desc:        "secure headers preset",
+			middlewares: []string{"foo"},
+			buildResponse: func(middlewares map[string]*config.Middleware) *http.Response {
+				ctx := context.Background()
+
+				var request *http.Request
+				next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					request = req
+				})
+
+				handler, err := headers.New(ctx, next, *middlewares["foo"].SecureHeaders.Headers(), "secure")
+				require.NoError(t, err)
+
+				handler.ServeHTTP(httptest.NewRecorder(),
+					httptest.NewRequest(http.MethodGet, "https://foo.com", nil))
+
+				return &http.Response{Header: make(http.Header), Request: request}
+			},
+			conf: map[string]*config.Middleware{
+				"foo": {
+					SecureHeaders: &config.SecureHeaders{
+						STSPreload:            true,
+						PermissionsPolicy:     map[string][]string{"camera": {}},
+						ContentSecurityPolicy: map[string][]string{"default-src": {"'self'"}},
+					},
+				},
+			},
+			assertResponse: func(t *testing.T, resp *http.Response) {
+				t.Helper()
+
+				assert.Equal(t, "max-age=31536000; preload", resp.Header.Get("Strict-Transport-Security"))
+				assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+				assert.Equal(t, "strict-origin-when-cross-origin", resp.Header.Get("Referrer-Policy"))
+				assert.Equal(t, "camera=()", resp.Header.Get("Permissions-Policy"))
+				assert.Equal(t, "default-src 'self'", resp.Header.Get("Content-Security-Policy"))
+			},
+		},
 		{
 			desc:          "two modifiers",
 			middlewares:   []string{"foo", "bar"},
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+)
+
+const defaultQueueTimeout = 10 * time.Second
+
+// ErrQueueTimeout is returned when a request waits in the queue longer than the queue timeout.
+var ErrQueueTimeout = errors.New("queue timeout")
+
+type contextKey struct{}
+
+// WithClass returns a handler whose requests are scheduled in the priority class.
+func WithClass(class string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), contextKey{}, class)))
+	})
+}
+
+// ClassFromContext returns the priority class of the request, if any.
+func ClassFromContext(ctx context.Context) string {
+	class, _ := ctx.Value(contextKey{}).(string)
+	return class
+}
+
+// waiter is a request waiting in the queue.
+type waiter struct {
+	priority int
+	aging    time.Duration
+	enqueued time.Time
+	ready    chan struct{}
+}
+
+// effectivePriority returns the priority of the waiter, increased by its aging.
+func (w *waiter) effectivePriority(now time.Time) int {
+	if w.aging <= 0 {
+		return w.priority
+	}
+	return w.priority + int(now.Sub(w.enqueued)/w.aging)
+}
+
+// Scheduler limits the number of requests in flight of a service.
+// The requests over the limit wait in a queue, and get the freed slots by decreasing priority of their class.
+// Among the requests of the same priority, the oldest one goes first.
+type Scheduler struct {
+	maxConcurrency int
+	queueTimeout   time.Duration
+	classes        map[string]config.PriorityClass
+
+	lock     sync.Mutex
+	inFlight int
+	queue    []*waiter
+}
+
+// New creates a new Scheduler, nil if the configuration doesn't limit the concurrency.
+func New(conf *config.Scheduling) *Scheduler {
+	if conf == nil || conf.MaxConcurrency <= 0 {
+		return nil
+	}
+
+	s := &Scheduler{
+		maxConcurrency: conf.MaxConcurrency,
+		queueTimeout:   time.Duration(conf.QueueTimeout),
+		classes:        make(map[string]config.PriorityClass),
+	}
+
+	if s.queueTimeout <= 0 {
+		s.queueTimeout = defaultQueueTimeout
+	}
+
+	for name, class := range conf.Classes {
+		if class != nil {
+			s.classes[name] = *class
+		}
+	}
+
+	return s
+}
+
+// Wrap returns a handler scheduling the requests, and answering 503 to the ones timing out in the queue.
+func (s *Scheduler) Wrap(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if err := s.acquire(req.Context(), ClassFromContext(req.Context())); err != nil {
+			log.FromContext(req.Context()).Debugf("Request not scheduled: %v", err)
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		defer s.release()
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// acquire takes a slot, waiting in the queue if none is free.
+func (s *Scheduler) acquire(ctx context.Context, className string) error {
+	s.lock.Lock()
+	if s.inFlight < s.maxConcurrency {
+		s.inFlight++
+		s.lock.Unlock()
+		return nil
+	}
+
+	class := s.classes[className]
+	w := &waiter{
+		priority: class.Priority,
+		aging:    time.Duration(class.Aging),
+		enqueued: time.Now(),
+		ready:    make(chan struct{}),
+	}
+	s.queue = append(s.queue, w)
+	s.lock.Unlock()
+
+	timer := time.NewTimer(s.queueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+		return nil
+	case <-timer.C:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, queued := range s.queue {
+		if queued == w {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return err
+		}
+	}
+
+	// The slot has been given to the waiter in the meantime.
+	return nil
+}
+
+// release gives the slot to the waiter of highest priority, or frees it.
+func (s *Scheduler) release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.queue) == 0 {
+		s.inFlight--
+		return
+	}
+
+	now := time.Now()
+	next := 0
+	for i, w := range s.queue {
+		if w.effectivePriority(now) > s.queue[next].effectivePriority(now) {
+			next = i
+		}
+	}
+
+	w := s.queue[next]
+	s.queue = append(s.queue[:next], s.queue[next+1:]...)
+	close(w.ready)
+}
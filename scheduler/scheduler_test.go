@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New(nil))
+	assert.Nil(t, New(&config.Scheduling{}))
+
+	s := New(&config.Scheduling{MaxConcurrency: 1})
+	require.NotNil(t, s)
+	assert.Equal(t, defaultQueueTimeout, s.queueTimeout)
+}
+
+// waitQueued waits until the scheduler has n requests in its queue.
+func waitQueued(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		s.lock.Lock()
+		queued := len(s.queue)
+		s.lock.Unlock()
+
+		if queued == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for %d queued requests", n)
+}
+
+func TestSchedulingOrder(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		classes       map[string]*config.PriorityClass
+		expectedOrder []string
+	}{
+		{
+			desc: "by priority",
+			classes: map[string]*config.PriorityClass{
+				"interactive": {Priority: 10},
+			},
+			expectedOrder: []string{"interactive", "bulk"},
+		},
+		{
+			desc: "same priority",
+			classes: map[string]*config.PriorityClass{
+				"interactive": {Priority: 0},
+			},
+			expectedOrder: []string{"bulk", "interactive"},
+		},
+		{
+			desc: "aging",
+			classes: map[string]*config.PriorityClass{
+				"bulk":        {Priority: 0, Aging: parse.Duration(time.Millisecond)},
+				"interactive": {Priority: 10},
+			},
+			expectedOrder: []string{"bulk", "interactive"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			s := New(&config.Scheduling{MaxConcurrency: 1, Classes: test.classes})
+			require.NoError(t, s.acquire(context.Background(), ""))
+
+			order := make(chan string, 2)
+			for i, class := range []string{"bulk", "interactive"} {
+				class := class
+				go func() {
+					if err := s.acquire(context.Background(), class); err == nil {
+						order <- class
+						s.release()
+					}
+				}()
+
+				waitQueued(t, s, i+1)
+				if class == "bulk" {
+					// Lets the bulk request age.
+					time.Sleep(20 * time.Millisecond)
+				}
+			}
+
+			s.release()
+
+			assert.Equal(t, test.expectedOrder[0], <-order)
+			assert.Equal(t, test.expectedOrder[1], <-order)
+		})
+	}
+}
+
+func TestQueueTimeout(t *testing.T) {
+	s := New(&config.Scheduling{MaxConcurrency: 1, QueueTimeout: parse.Duration(10 * time.Millisecond)})
+
+	release := make(chan struct{})
+	handler := s.Wrap(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		close(done)
+	}()
+
+	for i := 0; i < 100; i++ {
+		s.lock.Lock()
+		inFlight := s.inFlight
+		s.lock.Unlock()
+		if inFlight == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	close(release)
+	<-done
+
+	assert.Equal(t, 0, s.inFlight)
+	assert.Empty(t, s.queue)
+}
+
+func TestWithClass(t *testing.T) {
+	var class string
+	handler := WithClass("interactive", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		class = ClassFromContext(req.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "interactive", class)
+}
@@ -0,0 +1,117 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+// AWS holds the configuration of the AWS Secrets Manager secret backend.
+// A reference aws://prod/traefik#password reads the key password of the JSON secret prod/traefik,
+// and aws://prod/token reads the whole secret.
+// The credentials default to the ones of the environment, the shared credentials file, or the instance role.
+type AWS struct {
+	Region          string `description:"AWS region of the secrets" export:"true"`
+	AccessKeyID     string `description:"AWS access key ID"`
+	SecretAccessKey string `description:"AWS secret access key"`
+	Endpoint        string `description:"Custom endpoint of Secrets Manager" export:"true"`
+}
+
+type awsBackend struct {
+	endpoint string
+	region   string
+	signer   *v4.Signer
+	client   *http.Client
+}
+
+func newAWSBackend(conf *AWS) (*awsBackend, error) {
+	if len(conf.Region) == 0 {
+		return nil, errors.New("no region")
+	}
+
+	cfg := aws.NewConfig().WithRegion(conf.Region)
+	if len(conf.AccessKeyID) > 0 || len(conf.SecretAccessKey) > 0 {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(conf.AccessKeyID, conf.SecretAccessKey, ""))
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &awsBackend{
+		endpoint: conf.Endpoint,
+		region:   conf.Region,
+		signer:   v4.NewSigner(sess.Config.Credentials),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if len(b.endpoint) == 0 {
+		b.endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", conf.Region)
+	}
+
+	return b, nil
+}
+
+func (b *awsBackend) Fetch(path string) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, b.endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(payload))
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	if _, err := b.signer.Sign(req, bytes.NewReader(payload), "secretsmanager", b.region, time.Now()); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, body)
+	}
+
+	body := struct {
+		SecretString string `json:"SecretString"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	// A JSON object holds several keys, anything else is the whole secret.
+	values := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(body.SecretString), &values); err != nil {
+		return map[string]string{"": body.SecretString}, nil
+	}
+
+	secret := map[string]string{"": body.SecretString}
+	for key, value := range values {
+		if s, ok := value.(string); ok {
+			secret[key] = s
+		} else {
+			secret[key] = fmt.Sprint(value)
+		}
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,83 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// Kubernetes holds the configuration of the Kubernetes Secrets backend.
+// A reference kubernetes://default/traefik#users reads the key users of the secret traefik of the namespace default.
+// Without an endpoint, Traefik is expected to run inside the cluster.
+type Kubernetes struct {
+	Endpoint         string `description:"Kubernetes server endpoint (required for external cluster client)"`
+	Token            string `description:"Kubernetes bearer token (not needed for in-cluster client)"`
+	CertAuthFilePath string `description:"Kubernetes certificate authority file path (not needed for in-cluster client)"`
+}
+
+type kubernetesBackend struct {
+	clientset kubernetes.Interface
+}
+
+func newKubernetesBackend(conf *Kubernetes) (*kubernetesBackend, error) {
+	var restConfig *rest.Config
+
+	if len(conf.Token) > 0 || len(conf.CertAuthFilePath) > 0 {
+		if len(conf.Endpoint) == 0 {
+			return nil, errors.New("endpoint missing for external cluster client")
+		}
+
+		restConfig = &rest.Config{
+			Host:        conf.Endpoint,
+			BearerToken: conf.Token,
+		}
+
+		if len(conf.CertAuthFilePath) > 0 {
+			caData, err := ioutil.ReadFile(conf.CertAuthFilePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file %s: %s", conf.CertAuthFilePath, err)
+			}
+			restConfig.TLSClientConfig = rest.TLSClientConfig{CAData: caData}
+		}
+	} else {
+		var err error
+		restConfig, err = rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create in-cluster configuration: %s", err)
+		}
+
+		if len(conf.Endpoint) > 0 {
+			restConfig.Host = conf.Endpoint
+		}
+	}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kubernetesBackend{clientset: clientset}, nil
+}
+
+func (b *kubernetesBackend) Fetch(path string) (map[string]string, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("invalid path %q, expected <namespace>/<name>", path)
+	}
+
+	secret, err := b.clientset.CoreV1().Secrets(parts[0]).Get(parts[1], metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for key, value := range secret.Data {
+		values[key] = string(value)
+	}
+	return values, nil
+}
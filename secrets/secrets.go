@@ -0,0 +1,254 @@
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/log"
+)
+
+const defaultRefreshInterval = time.Minute
+
+// Config holds the configuration of the secret backends.
+// A secret is referenced as <backend>://<path>#<key>, e.g. vault://secret/data/traefik#users.
+type Config struct {
+	RefreshInterval parse.Duration `description:"Interval between two fetches of the referenced secrets, to pick up their rotations (default 1m)" export:"true"`
+	Vault           *Vault         `description:"Enable the Vault secret backend" export:"true"`
+	AWS             *AWS           `description:"Enable the AWS Secrets Manager secret backend" export:"true"`
+	Kubernetes      *Kubernetes    `description:"Enable the Kubernetes Secrets backend" export:"true"`
+}
+
+// Backend fetches the secrets of a secret store.
+type Backend interface {
+	// Fetch returns the keys and values of the secret at the path.
+	Fetch(path string) (map[string]string, error)
+}
+
+// Store resolves the references to the secrets, and keeps them up to date.
+type Store struct {
+	backends        map[string]Backend
+	refreshInterval time.Duration
+
+	lock    sync.RWMutex
+	secrets map[string]map[string]string
+}
+
+// New creates a Store of the backends of the configuration.
+// The file backend, reading the secrets from files, is always enabled.
+func New(config *Config) (*Store, error) {
+	s := &Store{
+		backends:        map[string]Backend{"file": fileBackend{}},
+		refreshInterval: defaultRefreshInterval,
+		secrets:         make(map[string]map[string]string),
+	}
+
+	if config == nil {
+		return s, nil
+	}
+
+	if config.RefreshInterval > 0 {
+		s.refreshInterval = time.Duration(config.RefreshInterval)
+	}
+
+	if config.Vault != nil {
+		backend, err := newVaultBackend(config.Vault)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Vault backend: %v", err)
+		}
+		s.backends["vault"] = backend
+	}
+
+	if config.AWS != nil {
+		backend, err := newAWSBackend(config.AWS)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AWS backend: %v", err)
+		}
+		s.backends["aws"] = backend
+	}
+
+	if config.Kubernetes != nil {
+		backend, err := newKubernetesBackend(config.Kubernetes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kubernetes backend: %v", err)
+		}
+		s.backends["kubernetes"] = backend
+	}
+
+	return s, nil
+}
+
+// IsReference tells whether the value is a reference to a secret, rather than the secret itself.
+func IsReference(value string) bool {
+	for _, scheme := range []string{"file", "vault", "aws", "kubernetes"} {
+		if strings.HasPrefix(value, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the value of the referenced secret.
+// A secret is fetched on its first resolution, and then refreshed periodically by Run.
+func (s *Store) Resolve(reference string) (string, error) {
+	scheme, path, key, err := parseReference(reference)
+	if err != nil {
+		return "", err
+	}
+
+	id := scheme + "://" + path
+
+	s.lock.RLock()
+	secret, ok := s.secrets[id]
+	s.lock.RUnlock()
+
+	if !ok {
+		backend, ok := s.backends[scheme]
+		if !ok {
+			return "", fmt.Errorf("no %s secret backend configured", scheme)
+		}
+
+		secret, err = backend.Fetch(path)
+		if err != nil {
+			return "", fmt.Errorf("unable to fetch the secret %s: %v", id, err)
+		}
+
+		s.lock.Lock()
+		s.secrets[id] = secret
+		s.lock.Unlock()
+	}
+
+	return lookup(secret, id, key)
+}
+
+// Run fetches again the resolved secrets periodically, until stop is closed.
+// A secret which can't be fetched keeps its previous value.
+func (s *Store) Run(stop chan bool) {
+	if s == nil {
+		return
+	}
+
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+func (s *Store) refresh() {
+	s.lock.RLock()
+	var ids []string
+	for id := range s.secrets {
+		ids = append(ids, id)
+	}
+	s.lock.RUnlock()
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		scheme, path, _, _ := parseReference(id)
+
+		secret, err := s.backends[scheme].Fetch(path)
+		if err != nil {
+			log.WithoutContext().Errorf("Unable to refresh the secret %s, keeping its previous value: %v", id, err)
+			continue
+		}
+
+		s.lock.Lock()
+		s.secrets[id] = secret
+		s.lock.Unlock()
+	}
+}
+
+// parseReference splits a reference <scheme>://<path>#<key>.
+func parseReference(reference string) (string, string, string, error) {
+	parts := strings.SplitN(reference, "://", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", "", fmt.Errorf("invalid secret reference %q, expected <backend>://<path>#<key>", reference)
+	}
+
+	path, key := parts[1], ""
+	if i := strings.LastIndex(path, "#"); i >= 0 {
+		path, key = path[:i], path[i+1:]
+	}
+
+	if len(path) == 0 {
+		return "", "", "", fmt.Errorf("invalid secret reference %q, expected <backend>://<path>#<key>", reference)
+	}
+
+	return parts[0], path, key, nil
+}
+
+// lookup returns the value of the key of the secret, its only value when no key is given.
+func lookup(secret map[string]string, id, key string) (string, error) {
+	if len(key) == 0 {
+		if value, ok := secret[""]; ok {
+			return value, nil
+		}
+		if len(secret) == 1 {
+			for _, value := range secret {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("the secret %s has several keys, one is required", id)
+	}
+
+	value, ok := secret[key]
+	if !ok {
+		return "", fmt.Errorf("no key %s in the secret %s", key, id)
+	}
+	return value, nil
+}
+
+// fileBackend reads the secrets from files, e.g. mounted by Docker or Kubernetes.
+type fileBackend struct{}
+
+func (fileBackend) Fetch(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"": strings.TrimSpace(string(data))}, nil
+}
+
+var defaultLock sync.RWMutex
+
+// defaultStore only resolves the file references, until SetDefault is called.
+var defaultStore, _ = New(nil)
+
+// SetDefault sets the Store resolving the references of the dynamic configuration.
+func SetDefault(store *Store) {
+	if store == nil {
+		return
+	}
+
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+	defaultStore = store
+}
+
+// Resolve resolves the reference with the default Store.
+func Resolve(reference string) (string, error) {
+	defaultLock.RLock()
+	store := defaultStore
+	defaultLock.RUnlock()
+
+	return store.Resolve(reference)
+}
+
+// ResolveValue returns the value itself when it is not a reference, and the referenced secret otherwise.
+func ResolveValue(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	return Resolve(value)
+}
@@ -0,0 +1,239 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	secrets map[string]map[string]string
+	err     error
+	fetches int
+}
+
+func (b *fakeBackend) Fetch(path string) (map[string]string, error) {
+	b.fetches++
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	secret, ok := b.secrets[path]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return secret, nil
+}
+
+func TestIsReference(t *testing.T) {
+	assert.True(t, IsReference("vault://secret/data/traefik#users"))
+	assert.True(t, IsReference("file:///run/secrets/users"))
+	assert.False(t, IsReference("/etc/traefik/users"))
+	assert.False(t, IsReference("http://auth.example.com"))
+}
+
+func TestStoreResolve(t *testing.T) {
+	backend := &fakeBackend{
+		secrets: map[string]map[string]string{
+			"secret/data/traefik": {"users": "admin:hash", "token": "s3cr3t"},
+			"secret/data/single":  {"token": "only"},
+		},
+	}
+
+	store := &Store{
+		backends: map[string]Backend{"vault": backend},
+		secrets:  make(map[string]map[string]string),
+	}
+
+	testCases := []struct {
+		desc          string
+		reference     string
+		expected      string
+		expectedError string
+	}{
+		{
+			desc:      "key",
+			reference: "vault://secret/data/traefik#token",
+			expected:  "s3cr3t",
+		},
+		{
+			desc:      "single key",
+			reference: "vault://secret/data/single",
+			expected:  "only",
+		},
+		{
+			desc:          "several keys",
+			reference:     "vault://secret/data/traefik",
+			expectedError: "the secret vault://secret/data/traefik has several keys, one is required",
+		},
+		{
+			desc:          "unknown key",
+			reference:     "vault://secret/data/traefik#password",
+			expectedError: "no key password in the secret vault://secret/data/traefik",
+		},
+		{
+			desc:          "unknown secret",
+			reference:     "vault://secret/data/unknown#token",
+			expectedError: "unable to fetch the secret vault://secret/data/unknown: not found",
+		},
+		{
+			desc:          "unknown backend",
+			reference:     "aws://prod/traefik#token",
+			expectedError: "no aws secret backend configured",
+		},
+		{
+			desc:          "invalid reference",
+			reference:     "vault://#token",
+			expectedError: `invalid secret reference "vault://#token", expected <backend>://<path>#<key>`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			value, err := store.Resolve(test.reference)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestStoreRefresh(t *testing.T) {
+	backend := &fakeBackend{
+		secrets: map[string]map[string]string{
+			"secret/data/traefik": {"token": "v1"},
+		},
+	}
+
+	store := &Store{
+		backends: map[string]Backend{"vault": backend},
+		secrets:  make(map[string]map[string]string),
+	}
+
+	value, err := store.Resolve("vault://secret/data/traefik#token")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	// The resolved secrets are cached.
+	_, err = store.Resolve("vault://secret/data/traefik#token")
+	require.NoError(t, err)
+	assert.Equal(t, 1, backend.fetches)
+
+	// A rotation is picked up by the refresh.
+	backend.secrets["secret/data/traefik"] = map[string]string{"token": "v2"}
+	store.refresh()
+
+	value, err = store.Resolve("vault://secret/data/traefik#token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+
+	// A failing refresh keeps the previous value.
+	backend.err = errors.New("sealed")
+	store.refresh()
+
+	value, err = store.Resolve("vault://secret/data/traefik#token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", value)
+}
+
+func TestFileBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(path, []byte("s3cr3t\n"), 0600))
+
+	store, err := New(nil)
+	require.NoError(t, err)
+
+	value, err := store.Resolve("file://" + path)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+}
+
+func TestVaultBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "root" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch req.URL.Path {
+		case "/v1/kv/traefik":
+			_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"token": "v1-token", "port": 6379},
+			})
+		case "/v1/secret/data/traefik":
+			_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]interface{}{"token": "v2-token"},
+					"metadata": map[string]interface{}{"version": 3},
+				},
+			})
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	backend, err := newVaultBackend(&Vault{Address: server.URL, Token: "root"})
+	require.NoError(t, err)
+
+	secret, err := backend.Fetch("kv/traefik")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"token": "v1-token", "port": "6379"}, secret)
+
+	secret, err = backend.Fetch("secret/data/traefik")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"token": "v2-token"}, secret)
+
+	_, err = backend.Fetch("secret/data/unknown")
+	assert.EqualError(t, err, "unexpected status code 404")
+}
+
+func TestAWSBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", req.Header.Get("X-Amz-Target"))
+		assert.Contains(t, req.Header.Get("Authorization"), "Credential=AKID/")
+
+		body := map[string]string{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+
+		switch body["SecretId"] {
+		case "prod/traefik":
+			_ = json.NewEncoder(rw).Encode(map[string]string{"SecretString": `{"password":"p4ss"}`})
+		case "prod/token":
+			_ = json.NewEncoder(rw).Encode(map[string]string{"SecretString": "t0ken"})
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	backend, err := newAWSBackend(&AWS{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "SECRET", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	secret, err := backend.Fetch("prod/traefik")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"": `{"password":"p4ss"}`, "password": "p4ss"}, secret)
+
+	secret, err = backend.Fetch("prod/token")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"": "t0ken"}, secret)
+
+	_, err = backend.Fetch("prod/unknown")
+	assert.Error(t, err)
+}
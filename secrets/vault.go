@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/config"
+)
+
+// Vault holds the configuration of the Vault secret backend.
+// A reference vault://secret/data/traefik#users reads the key users of the secret at the API path secret/data/traefik,
+// for both the versions 1 and 2 of the key/value secrets engine.
+type Vault struct {
+	Address string            `description:"Address of the Vault server" export:"true"`
+	Token   string            `description:"Token authenticating to Vault (default $VAULT_TOKEN)"`
+	TLS     *config.ClientTLS `description:"Enable TLS support" export:"true"`
+}
+
+type vaultBackend struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+func newVaultBackend(conf *Vault) (*vaultBackend, error) {
+	if len(conf.Address) == 0 {
+		return nil, errors.New("no address")
+	}
+
+	b := &vaultBackend{
+		address: strings.TrimSuffix(conf.Address, "/"),
+		token:   conf.Token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if len(b.token) == 0 {
+		b.token = os.Getenv("VAULT_TOKEN")
+	}
+
+	if conf.TLS != nil {
+		tlsConfig, err := conf.TLS.CreateTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		b.client.Transport = &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment}
+	}
+
+	return b, nil
+}
+
+func (b *vaultBackend) Fetch(path string) (map[string]string, error) {
+	req, err := http.NewRequest(http.MethodGet, b.address+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", b.token)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	data := body.Data
+
+	// The version 2 of the key/value secrets engine nests the secret, next to its metadata.
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	secret := make(map[string]string)
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			secret[key] = s
+			continue
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		secret[key] = string(raw)
+	}
+
+	return secret, nil
+}
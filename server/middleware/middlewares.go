@@ -7,32 +7,56 @@ import (
 
 	"github.com/containous/alice"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/middlewares/accesslog"
 	"github.com/containous/traefik/middlewares/addprefix"
 	"github.com/containous/traefik/middlewares/auth"
+	"github.com/containous/traefik/middlewares/bandwidth"
 	"github.com/containous/traefik/middlewares/buffering"
+	"github.com/containous/traefik/middlewares/capture"
 	"github.com/containous/traefik/middlewares/chain"
 	"github.com/containous/traefik/middlewares/circuitbreaker"
+	"github.com/containous/traefik/middlewares/collapse"
 	"github.com/containous/traefik/middlewares/compress"
+	"github.com/containous/traefik/middlewares/conditional"
+	"github.com/containous/traefik/middlewares/contentrouting"
+	"github.com/containous/traefik/middlewares/cors"
+	"github.com/containous/traefik/middlewares/csrf"
 	"github.com/containous/traefik/middlewares/customerrors"
+	"github.com/containous/traefik/middlewares/experiment"
+	"github.com/containous/traefik/middlewares/faultinjection"
+	"github.com/containous/traefik/middlewares/graphql"
+	"github.com/containous/traefik/middlewares/grpctranscoding"
 	"github.com/containous/traefik/middlewares/headers"
 	"github.com/containous/traefik/middlewares/ipwhitelist"
 	"github.com/containous/traefik/middlewares/maxconnection"
 	"github.com/containous/traefik/middlewares/passtlsclientcert"
+	"github.com/containous/traefik/middlewares/plugin"
 	"github.com/containous/traefik/middlewares/ratelimiter"
 	"github.com/containous/traefik/middlewares/redirect"
 	"github.com/containous/traefik/middlewares/replacepath"
 	"github.com/containous/traefik/middlewares/replacepathregex"
+	"github.com/containous/traefik/middlewares/requestbuffering"
+	"github.com/containous/traefik/middlewares/requestid"
 	"github.com/containous/traefik/middlewares/retry"
+	"github.com/containous/traefik/middlewares/sessiongateway"
 	"github.com/containous/traefik/middlewares/stripprefix"
 	"github.com/containous/traefik/middlewares/stripprefixregex"
+	"github.com/containous/traefik/middlewares/tenant"
+	"github.com/containous/traefik/middlewares/timewindow"
 	"github.com/containous/traefik/middlewares/tracing"
+	"github.com/containous/traefik/middlewares/upstreamauth"
+	"github.com/containous/traefik/plugins"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/pkg/errors"
 )
 
 // Builder the middleware builder
 type Builder struct {
-	configs        map[string]*config.Middleware
-	serviceBuilder serviceBuilder
+	configs         map[string]*config.Middleware
+	serviceBuilder  serviceBuilder
+	plugins         *plugins.Registry
+	metricsRegistry metrics.Registry
 }
 
 type serviceBuilder interface {
@@ -40,8 +64,15 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder
-func NewBuilder(configs map[string]*config.Middleware, serviceBuilder serviceBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder}
+// The experiments report the requests assigned to their variants to the metrics registry, which can be nil.
+func NewBuilder(configs map[string]*config.Middleware, serviceBuilder serviceBuilder, plugins *plugins.Registry, metricsRegistry metrics.Registry) *Builder {
+	return &Builder{configs: configs, serviceBuilder: serviceBuilder, plugins: plugins, metricsRegistry: metricsRegistry}
+}
+
+// Config returns the configuration of a middleware.
+func (b *Builder) Config(middlewareName string) (*config.Middleware, bool) {
+	conf, ok := b.configs[middlewareName]
+	return conf, ok
 }
 
 // BuildChain creates a middleware chain
@@ -78,6 +109,28 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// AnyAuth
+	if config.AnyAuth != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return auth.NewAnyAuth(ctx, next, *config.AnyAuth, b, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// Bandwidth
+	if config.Bandwidth != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return bandwidth.New(ctx, next, *config.Bandwidth, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// BasicAuth
 	if config.BasicAuth != nil {
 		if middleware == nil {
@@ -90,7 +143,7 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 	}
 
 	// Buffering
-	if config.Buffering != nil && config.MaxConn.Amount != 0 {
+	if config.Buffering != nil {
 		if middleware == nil {
 			middleware = func(next http.Handler) (http.Handler, error) {
 				return buffering.New(ctx, next, *config.Buffering, middlewareName)
@@ -100,6 +153,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// Capture
+	if config.Capture != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return capture.New(ctx, next, *config.Capture, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// Chain
 	if config.Chain != nil {
 		if middleware == nil {
@@ -122,6 +186,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// CollapseForwarding
+	if config.CollapseForwarding != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return collapse.New(ctx, next, *config.CollapseForwarding, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// Compress
 	if config.Compress != nil {
 		if middleware == nil {
@@ -133,6 +208,50 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// ConditionalRequests
+	if config.ConditionalRequests != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return conditional.New(ctx, next, *config.ConditionalRequests, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// ContentRouting
+	if config.ContentRouting != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return contentrouting.New(ctx, next, *config.ContentRouting, b.serviceBuilder, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// CORS
+	if config.CORS != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return cors.New(ctx, next, *config.CORS, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// CSRF
+	if config.CSRF != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return csrf.New(ctx, next, *config.CSRF, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// CustomErrors
 	if config.Errors != nil {
 		if middleware == nil {
@@ -144,6 +263,21 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// Experiment
+	if config.Experiment != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				var exposures gokitmetrics.Counter
+				if b.metricsRegistry != nil && b.metricsRegistry.IsEnabled() {
+					exposures = b.metricsRegistry.ExperimentExposuresCounter()
+				}
+				return experiment.New(ctx, next, *config.Experiment, b.serviceBuilder, exposures, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// DigestAuth
 	if config.DigestAuth != nil {
 		if middleware == nil {
@@ -155,6 +289,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// FaultInjection
+	if config.FaultInjection != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return faultinjection.New(ctx, next, *config.FaultInjection, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// ForwardAuth
 	if config.ForwardAuth != nil {
 		if middleware == nil {
@@ -166,6 +311,32 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// GraphQL
+	if config.GraphQL != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				var operations gokitmetrics.Counter
+				if b.metricsRegistry != nil && b.metricsRegistry.IsEnabled() {
+					operations = b.metricsRegistry.GraphQLOperationsCounter()
+				}
+				return graphql.New(ctx, next, *config.GraphQL, operations, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// GRPCTranscoding
+	if config.GRPCTranscoding != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return grpctranscoding.New(ctx, next, *config.GRPCTranscoding, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// Headers
 	if config.Headers != nil {
 		if middleware == nil {
@@ -210,6 +381,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// Plugin
+	if config.Plugin != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return plugin.New(ctx, next, *config.Plugin, b.plugins, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// RateLimit
 	if config.RateLimit != nil {
 		if middleware == nil {
@@ -254,12 +436,56 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// RequestBuffering
+	if config.RequestBuffering != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return requestbuffering.New(ctx, next, *config.RequestBuffering, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// RequestID
+	if config.RequestID != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return requestid.New(ctx, next, *config.RequestID, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	// Retry
 	if config.Retry != nil {
 		if middleware == nil {
 			middleware = func(next http.Handler) (http.Handler, error) {
-				// FIXME missing metrics / accessLog
-				return retry.New(ctx, next, *config.Retry, retry.Listeners{}, middlewareName)
+				// FIXME missing metrics
+				return retry.New(ctx, next, *config.Retry, retry.Listeners{&accesslog.SaveRetries{}}, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// SecureHeaders
+	if config.SecureHeaders != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return headers.New(ctx, next, *config.SecureHeaders.Headers(), middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// SessionGateway
+	if config.SessionGateway != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return sessiongateway.New(ctx, next, *config.SessionGateway, middlewareName)
 			}
 		} else {
 			return nil, badConf
@@ -288,5 +514,38 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string, c
 		}
 	}
 
+	// Tenant
+	if config.Tenant != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return tenant.New(ctx, next, *config.Tenant, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// TimeWindow
+	if config.TimeWindow != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return timewindow.New(ctx, next, *config.TimeWindow, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
+	// UpstreamAuth
+	if config.UpstreamAuth != nil {
+		if middleware == nil {
+			middleware = func(next http.Handler) (http.Handler, error) {
+				return upstreamauth.New(ctx, next, *config.UpstreamAuth, middlewareName)
+			}
+		} else {
+			return nil, badConf
+		}
+	}
+
 	return tracing.Wrap(ctx, middleware), nil
 }
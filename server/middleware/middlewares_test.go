@@ -22,7 +22,7 @@ func TestMiddlewaresRegistry_BuildMiddlewareCircuitBreaker(t *testing.T) {
 			},
 		},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, nil)
 
 	emptyHandler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
 
@@ -65,7 +65,7 @@ func TestMiddlewaresRegistry_BuildChainNilConfig(t *testing.T) {
 	testConfig := map[string]*config.Middleware{
 		"empty": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, nil)
 
 	chain, err := middlewaresBuilder.BuildChain(context.Background(), []string{"empty"})
 	require.NoError(t, err)
@@ -88,7 +88,7 @@ func TestMiddlewaresRegistry_BuildMiddlewareAddPrefix(t *testing.T) {
 		},
 	}
 
-	middlewaresBuilder := NewBuilder(testConfig, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, nil)
 
 	testCases := []struct {
 		desc          string
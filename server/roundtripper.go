@@ -3,6 +3,7 @@ package server
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"net"
 	"net/http"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/containous/traefik/config/static"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/old/configuration"
+	"github.com/containous/traefik/server/service"
 	traefiktls "github.com/containous/traefik/tls"
 	"github.com/pkg/errors"
 	"golang.org/x/net/http2"
@@ -44,6 +46,10 @@ func createHTTPTransport(transportConfiguration *static.ServersTransport) (*http
 		dialer.Timeout = time.Duration(transportConfiguration.ForwardingTimeouts.DialTimeout)
 	}
 
+	if err := service.BindDialer(dialer, transportConfiguration.LocalAddress, transportConfiguration.Interface); err != nil {
+		return nil, fmt.Errorf("error binding the connections to the servers: %v", err)
+	}
+
 	transport := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
 		DialContext:           dialer.DialContext,
@@ -53,6 +59,17 @@ func createHTTPTransport(transportConfiguration *static.ServersTransport) (*http
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
+	if transportConfiguration.Proxy != nil {
+		proxyDialer, err := service.NewProxyDialer(transportConfiguration.Proxy, dialer.DialContext)
+		if err != nil {
+			return nil, fmt.Errorf("error creating the proxy of the servers: %v", err)
+		}
+
+		// The proxy replaces the one of the environment.
+		transport.Proxy = nil
+		transport.DialContext = proxyDialer.DialContext
+	}
+
 	transport.RegisterProtocol("h2c", &h2cTransportWrapper{
 		Transport: &http2.Transport{
 			DialTLS: func(netw, addr string, cfg *tls.Config) (net.Conn, error) {
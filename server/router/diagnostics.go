@@ -0,0 +1,221 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/requestdecorator"
+	"github.com/containous/traefik/safe"
+)
+
+// SampleRequest is a request whose routing is diagnosed.
+type SampleRequest struct {
+	EntryPoint string            `json:"entryPoint,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	Host       string            `json:"host"`
+	Path       string            `json:"path,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	RemoteAddr string            `json:"remoteAddr,omitempty"`
+}
+
+// MatcherDiagnosis tells whether a matcher of a rule matches the sample request.
+type MatcherDiagnosis struct {
+	Matcher string `json:"matcher"`
+	Matched bool   `json:"matched"`
+}
+
+// RouterDiagnosis describes how a router handles the sample request.
+type RouterDiagnosis struct {
+	Name     string `json:"name"`
+	Rule     string `json:"rule"`
+	Priority int    `json:"priority"`
+	Matched  bool   `json:"matched"`
+	// Alternatives are the matchers of each alternative of the rule.
+	Alternatives [][]MatcherDiagnosis `json:"alternatives,omitempty"`
+	Error        string               `json:"error,omitempty"`
+}
+
+// RouteDiagnosis reports which router handles the sample request, and why.
+type RouteDiagnosis struct {
+	// Matched is the router handling the request.
+	Matched string `json:"matched,omitempty"`
+	// Shadowed are the other routers matching the request, with a lower priority.
+	Shadowed []string `json:"shadowed,omitempty"`
+	// Conflicts are the other routers matching the request with the same priority:
+	// which one of them handles the request is undefined.
+	Conflicts []string          `json:"conflicts,omitempty"`
+	Routers   []RouterDiagnosis `json:"routers"`
+}
+
+// DiagnoseRoute evaluates the rules of the routers against the sample request.
+// The routers are sorted by decreasing priority, the ones with the same priority by name.
+func DiagnoseRoute(routers map[string]*config.Router, sample SampleRequest) (RouteDiagnosis, error) {
+	req, err := sample.newRequest()
+	if err != nil {
+		return RouteDiagnosis{}, err
+	}
+
+	diagnosis := RouteDiagnosis{Routers: []RouterDiagnosis{}}
+	for name, router := range routers {
+		if len(sample.EntryPoint) > 0 && len(router.EntryPoints) > 0 && !contains(router.EntryPoints, sample.EntryPoint) {
+			continue
+		}
+
+		diagnosis.Routers = append(diagnosis.Routers, diagnoseRouter(req, name, router))
+	}
+
+	sort.Slice(diagnosis.Routers, func(i, j int) bool {
+		if diagnosis.Routers[i].Priority != diagnosis.Routers[j].Priority {
+			return diagnosis.Routers[i].Priority > diagnosis.Routers[j].Priority
+		}
+		return diagnosis.Routers[i].Name < diagnosis.Routers[j].Name
+	})
+
+	var matchedPriority int
+	for _, router := range diagnosis.Routers {
+		if !router.Matched {
+			continue
+		}
+
+		switch {
+		case len(diagnosis.Matched) == 0:
+			diagnosis.Matched = router.Name
+			matchedPriority = router.Priority
+		case router.Priority == matchedPriority:
+			diagnosis.Conflicts = append(diagnosis.Conflicts, router.Name)
+		default:
+			diagnosis.Shadowed = append(diagnosis.Shadowed, router.Name)
+		}
+	}
+
+	return diagnosis, nil
+}
+
+func diagnoseRouter(req *http.Request, name string, router *config.Router) RouterDiagnosis {
+	diagnosis := RouterDiagnosis{
+		Name:     name,
+		Rule:     router.Rule,
+		Priority: router.Priority,
+	}
+	if diagnosis.Priority == 0 {
+		diagnosis.Priority = len(router.Rule)
+	}
+
+	if err := validateRule(router.Rule); err != nil {
+		diagnosis.Error = err.Error()
+		return diagnosis
+	}
+
+	for _, alternative := range strings.Split(router.Rule, "||") {
+		matched := true
+
+		matchers := []MatcherDiagnosis{}
+		for _, expression := range strings.FieldsFunc(alternative, func(c rune) bool { return c == ';' }) {
+			expression = strings.TrimSpace(expression)
+			if len(expression) == 0 {
+				continue
+			}
+
+			matcher := MatcherDiagnosis{Matcher: expression, Matched: matchRule(req, expression)}
+			matched = matched && matcher.Matched
+			matchers = append(matchers, matcher)
+		}
+
+		diagnosis.Matched = diagnosis.Matched || matched
+		diagnosis.Alternatives = append(diagnosis.Alternatives, matchers)
+	}
+
+	return diagnosis
+}
+
+// matchRule tells whether the request matches the rule, as the routes of the entry points do.
+func matchRule(req *http.Request, rule string) bool {
+	router := mux.NewRouter().SkipClean(true)
+	if err := addRoute(req.Context(), router, rule, 0, http.NotFoundHandler()); err != nil {
+		return false
+	}
+
+	return router.Match(req, &mux.RouteMatch{})
+}
+
+// newRequest creates the sample request, decorated with its canonized host as by the entry points.
+func (s SampleRequest) newRequest() (*http.Request, error) {
+	if len(s.Host) == 0 {
+		return nil, fmt.Errorf("missing host")
+	}
+
+	method := s.Method
+	if len(method) == 0 {
+		method = http.MethodGet
+	}
+
+	path := s.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	req, err := http.NewRequest(method, "http://"+s.Host+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range s.Headers {
+		req.Header.Set(name, value)
+	}
+	if len(s.RemoteAddr) > 0 {
+		req.RemoteAddr = s.RemoteAddr
+	}
+
+	var decorated *http.Request
+	requestdecorator.New(nil).ServeHTTP(httptest.NewRecorder(), req, func(_ http.ResponseWriter, r *http.Request) {
+		decorated = r
+	})
+
+	return decorated, nil
+}
+
+// diagnosticsHandler exposes the diagnosis of the routing of sample requests by the current configuration.
+type diagnosticsHandler struct {
+	currentConfigurations *safe.Safe
+}
+
+// Append adds the diagnostics route on a router.
+func (h diagnosticsHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodPost).Path("/api/diagnostics/route").
+		HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			sample := SampleRequest{}
+			if err := json.NewDecoder(req.Body).Decode(&sample); err != nil {
+				http.Error(rw, fmt.Sprintf("invalid sample request: %v", err), http.StatusBadRequest)
+				return
+			}
+
+			routers := make(map[string]*config.Router)
+			if h.currentConfigurations != nil {
+				if configurations, ok := h.currentConfigurations.Get().(config.Configurations); ok {
+					for _, configuration := range configurations {
+						for name, router := range configuration.Routers {
+							routers[name] = router
+						}
+					}
+				}
+			}
+
+			diagnosis, err := DiagnoseRoute(routers, sample)
+			if err != nil {
+				http.Error(rw, fmt.Sprintf("invalid sample request: %v", err), http.StatusBadRequest)
+				return
+			}
+
+			rw.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(rw).Encode(diagnosis); err != nil {
+				log.FromContext(req.Context()).Error(err)
+			}
+		})
+}
@@ -0,0 +1,205 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/safe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnoseRoute(t *testing.T) {
+	routers := map[string]*config.Router{
+		"api": {
+			EntryPoints: []string{"web"},
+			Rule:        "Host:foo.com;PathPrefix:/api",
+		},
+		"canary": {
+			EntryPoints: []string{"web"},
+			Rule:        "Host:foo.com;Header:X-Canary=true",
+			Priority:    100,
+		},
+		"root": {
+			Rule: "Host:foo.com;PathPrefix:/",
+		},
+		"other": {
+			Rule: "Host:bar.com || Host:foo.com;Path:/other",
+		},
+		"twin": {
+			EntryPoints: []string{"websecure"},
+			Rule:        "PathPrefix:/api;Host:foo.com",
+		},
+	}
+
+	testCases := []struct {
+		desc              string
+		sample            SampleRequest
+		expectedMatched   string
+		expectedShadowed  []string
+		expectedConflicts []string
+		expectedRouters   []string
+	}{
+		{
+			desc:             "highest priority",
+			sample:           SampleRequest{EntryPoint: "web", Host: "foo.com", Path: "/api/users", Headers: map[string]string{"X-Canary": "true"}},
+			expectedMatched:  "canary",
+			expectedShadowed: []string{"api", "root"},
+			expectedRouters:  []string{"canary", "other", "api", "root"},
+		},
+		{
+			desc:             "rule length",
+			sample:           SampleRequest{EntryPoint: "web", Host: "FOO.com:80", Path: "/api/users"},
+			expectedMatched:  "api",
+			expectedShadowed: []string{"root"},
+			expectedRouters:  []string{"canary", "other", "api", "root"},
+		},
+		{
+			desc:              "same priority",
+			sample:            SampleRequest{Host: "foo.com", Path: "/api"},
+			expectedMatched:   "api",
+			expectedConflicts: []string{"twin"},
+			expectedShadowed:  []string{"root"},
+			expectedRouters:   []string{"canary", "other", "api", "twin", "root"},
+		},
+		{
+			desc:            "alternative",
+			sample:          SampleRequest{EntryPoint: "web", Host: "bar.com", Path: "/"},
+			expectedMatched: "other",
+			expectedRouters: []string{"canary", "other", "api", "root"},
+		},
+		{
+			desc:            "no match",
+			sample:          SampleRequest{EntryPoint: "web", Host: "baz.com"},
+			expectedRouters: []string{"canary", "other", "api", "root"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			diagnosis, err := DiagnoseRoute(routers, test.sample)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedMatched, diagnosis.Matched)
+			assert.Equal(t, test.expectedShadowed, diagnosis.Shadowed)
+			assert.Equal(t, test.expectedConflicts, diagnosis.Conflicts)
+
+			var names []string
+			for _, router := range diagnosis.Routers {
+				names = append(names, router.Name)
+			}
+			assert.Equal(t, test.expectedRouters, names)
+		})
+	}
+}
+
+func TestDiagnoseRouteMatchers(t *testing.T) {
+	routers := map[string]*config.Router{
+		"api": {
+			Rule:     "Host:foo.com;PathPrefix:/api || Header:X-Api=true",
+			Priority: 10,
+		},
+		"invalid": {
+			Rule: "Cookie:=on",
+		},
+	}
+
+	diagnosis, err := DiagnoseRoute(routers, SampleRequest{Host: "foo.com", Path: "/web"})
+	require.NoError(t, err)
+
+	expected := []RouterDiagnosis{
+		{
+			Name:     "api",
+			Rule:     "Host:foo.com;PathPrefix:/api || Header:X-Api=true",
+			Priority: 10,
+			Alternatives: [][]MatcherDiagnosis{
+				{
+					{Matcher: "Host:foo.com", Matched: true},
+					{Matcher: "PathPrefix:/api", Matched: false},
+				},
+				{
+					{Matcher: "Header:X-Api=true", Matched: false},
+				},
+			},
+		},
+		{
+			Name:     "invalid",
+			Rule:     "Cookie:=on",
+			Priority: 10,
+			Error:    `invalid cookie "=on", expected name=value or name`,
+		},
+	}
+	assert.Equal(t, expected, diagnosis.Routers)
+	assert.Empty(t, diagnosis.Matched)
+}
+
+func TestDiagnosticsHandler(t *testing.T) {
+	currentConfigurations := &safe.Safe{}
+	currentConfigurations.Set(config.Configurations{
+		"file": &config.Configuration{
+			Routers: map[string]*config.Router{
+				"api": {Rule: "Host:foo.com;PathPrefix:/api"},
+			},
+		},
+	})
+
+	testCases := []struct {
+		desc               string
+		body               string
+		expectedStatusCode int
+		expectedMatched    string
+	}{
+		{
+			desc:               "matched router",
+			body:               `{"host":"foo.com","path":"/api"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedMatched:    "api",
+		},
+		{
+			desc:               "no matched router",
+			body:               `{"host":"bar.com","path":"/api"}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "missing host",
+			body:               `{"path":"/api"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:               "malformed sample request",
+			body:               `{`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router := mux.NewRouter()
+			diagnosticsHandler{currentConfigurations: currentConfigurations}.Append(router)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/api/diagnostics/route", strings.NewReader(test.body))
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			if test.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			diagnosis := RouteDiagnosis{}
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&diagnosis))
+			assert.Equal(t, test.expectedMatched, diagnosis.Matched)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/requestdecorator"
+)
+
+// hostRouter routes the requests among the routes which can match their host only,
+// so that the rules of the routes of the other hosts are not evaluated for each request.
+// The routes are indexed by the hosts of their Host matcher when the configuration is applied,
+// then by their paths, by a path router.
+type hostRouter struct {
+	// all holds all the routes, for the requests whose host cannot be looked up.
+	all *mux.Router
+	// hosts holds, by literal host, its routes and the routes of its wildcard host.
+	hosts map[string]*hostRoutes
+	// wildcards holds, by parent domain of a wildcard host, as .example.com, its routes.
+	wildcards map[string]*hostRoutes
+	// others holds the routes without a host, which the requests fall through to when no route of their host matches.
+	others *pathRouter
+
+	routes []hostRoute
+}
+
+// hostRoute is an alternative of a rule, with the priority and the handler of its router.
+type hostRoute struct {
+	alternative
+	priority int
+	handler  http.Handler
+}
+
+func newHostRouter() *hostRouter {
+	return &hostRouter{
+		all:       mux.NewRouter().SkipClean(true),
+		hosts:     make(map[string]*hostRoutes),
+		wildcards: make(map[string]*hostRoutes),
+	}
+}
+
+// addRoute adds the routes of the alternatives of the rule to the handler.
+// The routes are only indexed by their host by build.
+func (r *hostRouter) addRoute(ctx context.Context, rule string, priority int, handler http.Handler) error {
+	alternatives, err := parseRule(rule)
+	if err != nil {
+		return err
+	}
+
+	if priority == 0 {
+		priority = len(rule)
+	}
+
+	for _, alt := range alternatives {
+		route, err := addAlternative(r.all, alt, priority, handler)
+		if err != nil {
+			return err
+		}
+		if route.GetError() != nil {
+			log.FromContext(ctx).Error(route.GetError())
+		}
+
+		r.routes = append(r.routes, hostRoute{alternative: alt, priority: priority, handler: handler})
+	}
+
+	return nil
+}
+
+// build indexes the routes by their host.
+func (r *hostRouter) build() {
+	var others []hostRoute
+	literals := make(map[string][]hostRoute)
+	wildcards := make(map[string][]hostRoute)
+
+	for _, route := range r.routes {
+		if len(route.hosts) == 0 {
+			others = append(others, route)
+			continue
+		}
+
+		for _, host := range route.hosts {
+			if strings.HasPrefix(host, "*.") {
+				wildcards[host[1:]] = append(wildcards[host[1:]], route)
+			} else {
+				literals[host] = append(literals[host], route)
+			}
+		}
+	}
+
+	r.all.SortRoutes()
+	r.others = newPathRouter(others)
+
+	// A route without a host can take precedence over the routes of a host when its priority is higher than one of theirs.
+	var othersPriority int
+	if len(others) > 0 {
+		othersPriority = maxPriority(others)
+	}
+	overlap := func(routes ...[]hostRoute) bool {
+		return len(others) > 0 && othersPriority > minPriority(routes...)
+	}
+
+	for parent, routes := range wildcards {
+		r.wildcards[parent] = &hostRoutes{
+			routes:  newPathRouter(routes),
+			others:  r.others,
+			overlap: overlap(routes),
+		}
+	}
+
+	for host, routes := range literals {
+		wildcardRoutes := wildcards[parentDomain(host)]
+		r.hosts[host] = &hostRoutes{
+			routes:  newPathRouter(routes, wildcardRoutes),
+			others:  r.others,
+			overlap: overlap(routes, wildcardRoutes),
+		}
+	}
+
+	r.routes = nil
+}
+
+func (r *hostRouter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	r.lookup(req).ServeHTTP(rw, req)
+}
+
+// lookup returns the handler of the routes which can match the host of the request.
+func (r *hostRouter) lookup(req *http.Request) http.Handler {
+	// A flattened CNAME can match other hosts than the one of the request.
+	if len(requestdecorator.GetCNAMEFlatten(req.Context())) > 0 {
+		return r.all
+	}
+
+	host := requestdecorator.GetCanonizedHost(req.Context())
+	if len(host) == 0 {
+		return r.all
+	}
+
+	if router, ok := r.hosts[host]; ok {
+		return router
+	}
+
+	if router, ok := r.wildcards[parentDomain(host)]; ok {
+		return router
+	}
+
+	return r.others
+}
+
+// parentDomain returns the parent domain of the host, with its leading dot, as matched by a wildcard host.
+func parentDomain(host string) string {
+	i := strings.IndexByte(host, '.')
+	if i <= 0 {
+		return ""
+	}
+	return host[i:]
+}
+
+// hostRoutes routes the requests of a host among its routes,
+// and lets the ones matching none of them fall through to the routes without a host.
+type hostRoutes struct {
+	routes *pathRouter
+	others *pathRouter
+	// overlap is true when a route without a host has a higher priority than a route of the host,
+	// so that the routes without a host must be matched as well to find the one with the highest priority.
+	overlap bool
+}
+
+func (h *hostRoutes) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var match pathMatch
+	h.routes.match(req, &match)
+	if match.router == nil || h.overlap {
+		h.others.match(req, &match)
+	}
+	match.serve(rw, req)
+}
+
+// maxPriority returns the highest priority of the routes, which can't be empty.
+func maxPriority(routes []hostRoute) int {
+	priority := routes[0].priority
+	for _, route := range routes[1:] {
+		if route.priority > priority {
+			priority = route.priority
+		}
+	}
+	return priority
+}
+
+// minPriority returns the lowest priority of the routes, which can't be empty.
+func minPriority(routes ...[]hostRoute) int {
+	var priority int
+	first := true
+	for _, rts := range routes {
+		for _, route := range rts {
+			if first || route.priority < priority {
+				priority = route.priority
+				first = false
+			}
+		}
+	}
+	return priority
+}
+
+// newRoutesRouter builds a router with the routes.
+// The errors of the routes were already reported when they were added to the router holding all the routes.
+func newRoutesRouter(routes ...[]hostRoute) *mux.Router {
+	router := mux.NewRouter().SkipClean(true)
+	for _, rts := range routes {
+		for _, route := range rts {
+			_, _ = addAlternative(router, route.alternative, route.priority, route.handler)
+		}
+	}
+	router.SortRoutes()
+	return router
+}
@@ -0,0 +1,185 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/middlewares/requestdecorator"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostRouter(t *testing.T) {
+	type route struct {
+		name     string
+		rule     string
+		priority int
+	}
+
+	testCases := []struct {
+		desc     string
+		routes   []route
+		expected map[string]string
+	}{
+		{
+			desc: "literal hosts",
+			routes: []route{
+				{name: "foo", rule: "Host:foo.com"},
+				{name: "bar", rule: "Host:bar.com,BAZ.com"},
+			},
+			expected: map[string]string{
+				"http://foo.com/":      "foo",
+				"http://FOO.com:8080/": "foo",
+				"http://bar.com/":      "bar",
+				"http://baz.com/":      "bar",
+				"http://qux.com/":      "",
+			},
+		},
+		{
+			desc: "wildcard hosts",
+			routes: []route{
+				{name: "wildcard", rule: "Host:*.foo.com"},
+				{name: "literal", rule: "Host:bar.foo.com;PathPrefix:/bar"},
+			},
+			expected: map[string]string{
+				"http://bar.foo.com/bar": "literal",
+				"http://bar.foo.com/foo": "wildcard",
+				"http://baz.foo.com/":    "wildcard",
+				"http://foo.com/":        "",
+				"http://a.b.foo.com/":    "",
+			},
+		},
+		{
+			desc: "routes without a host",
+			routes: []route{
+				{name: "host", rule: "Host:foo.com;PathPrefix:/foo", priority: 10},
+				{name: "path", rule: "PathPrefix:/", priority: 1},
+				{name: "priority", rule: "PathPrefix:/bar", priority: 100},
+				{name: "regexp", rule: "HostRegexp:{sub:[a-z]+}.bar.com", priority: 5},
+				{name: "deep", rule: "PathPrefix:/foo/bar", priority: 20},
+			},
+			expected: map[string]string{
+				"http://foo.com/foo/bar": "deep",
+				"http://foo.com/foo":     "host",
+				"http://foo.com/bar":     "priority",
+				"http://foo.com/baz":     "path",
+				"http://baz.com/foo":     "path",
+				"http://baz.bar.com/foo": "regexp",
+			},
+		},
+		{
+			desc: "routes without a host of lower priority",
+			routes: []route{
+				{name: "host", rule: "Host:foo.com;PathPrefix:/foo", priority: 100},
+				{name: "wildcard", rule: "Host:*.foo.com;PathPrefix:/foo", priority: 50},
+				{name: "path", rule: "PathPrefix:/", priority: 1},
+			},
+			expected: map[string]string{
+				"http://foo.com/foo":     "host",
+				"http://foo.com/bar":     "path",
+				"http://bar.foo.com/foo": "wildcard",
+				"http://bar.foo.com/bar": "path",
+				"http://baz.com/foo":     "path",
+			},
+		},
+		{
+			desc: "alternatives",
+			routes: []route{
+				{name: "foo", rule: "Host:foo.com || PathPrefix:/foo"},
+			},
+			expected: map[string]string{
+				"http://foo.com/":     "foo",
+				"http://bar.com/foo":  "foo",
+				"http://bar.com/bar":  "",
+				"http://foo.com/none": "foo",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router := newHostRouter()
+			for _, rt := range test.routes {
+				rt := rt
+				handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					rw.Header().Set("X-From", rt.name)
+				})
+				require.NoError(t, router.addRoute(context.Background(), rt.rule, rt.priority, handler))
+			}
+			router.build()
+
+			// RequestDecorator is necessary for the host rule
+			reqHost := requestdecorator.New(nil)
+
+			for calledURL, expected := range test.expected {
+				rw := httptest.NewRecorder()
+				reqHost.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodGet, calledURL, nil), router.ServeHTTP)
+
+				assert.Equal(t, expected, rw.Header().Get("X-From"), calledURL)
+
+				// The router holding all the routes routes the same way.
+				rw = httptest.NewRecorder()
+				reqHost.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodGet, calledURL, nil), router.all.ServeHTTP)
+
+				assert.Equal(t, expected, rw.Header().Get("X-From"), calledURL)
+			}
+		})
+	}
+}
+
+func TestHostRouterOthersNotCopied(t *testing.T) {
+	router := newHostRouter()
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	require.NoError(t, router.addRoute(context.Background(), "Host:foo.com", 0, handler))
+	require.NoError(t, router.addRoute(context.Background(), "Host:*.bar.com", 0, handler))
+	require.NoError(t, router.addRoute(context.Background(), "PathPrefix:/foo", 0, handler))
+	require.NoError(t, router.addRoute(context.Background(), "PathPrefix:/bar", 0, handler))
+	router.build()
+
+	assert.Equal(t, 1, countPathRoutes(router.hosts["foo.com"].routes.root))
+	assert.Equal(t, 1, countPathRoutes(router.wildcards[".bar.com"].routes.root))
+	assert.Equal(t, 2, countPathRoutes(router.others.root))
+}
+
+func TestParentDomain(t *testing.T) {
+	testCases := map[string]string{
+		"foo.com":     ".com",
+		"bar.foo.com": ".foo.com",
+		"localhost":   "",
+		".foo.com":    "",
+	}
+
+	for host, expected := range testCases {
+		assert.Equal(t, expected, parentDomain(host), host)
+	}
+}
+
+func BenchmarkHostRouter(b *testing.B) {
+	for _, count := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d routers", count), func(b *testing.B) {
+			router := newHostRouter()
+			handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+			for i := 0; i < count; i++ {
+				require.NoError(b, router.addRoute(context.Background(), fmt.Sprintf("Host:foo%d.com;PathPrefix:/foo", i), 0, handler))
+			}
+			router.build()
+
+			reqHost := requestdecorator.New(nil)
+			req := testhelpers.MustNewRequest(http.MethodGet, fmt.Sprintf("http://foo%d.com/foo", count/2), nil)
+			rw := httptest.NewRecorder()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				reqHost.ServeHTTP(rw, req, router.ServeHTTP)
+			}
+		})
+	}
+}
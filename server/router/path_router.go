@@ -0,0 +1,199 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/containous/mux"
+)
+
+// maxPathDepth is the number of nodes matching the path of a request, beyond which their routes are not looked up.
+// It bounds the nodes gathered without allocation, the prefixes of the paths of the routes rarely sharing as many parts.
+const maxPathDepth = 32
+
+// pathRouter routes the requests among the routes which can match their path only,
+// so that the rules of the routes of the other paths are not evaluated for each request.
+// The routes are indexed in a radix tree by the literal prefix of the paths of their first Path or PathPrefix matcher,
+// the routes without a path being held by its root.
+type pathRouter struct {
+	root *pathNode
+}
+
+// pathNode is a node of the radix tree of a path router.
+type pathNode struct {
+	// prefix is the part of the key of the node following the key of its parent.
+	prefix string
+	// children are sorted by the first byte of their prefix, which is unique among them.
+	children []*pathNode
+
+	routes []hostRoute
+	// router holds the routes whose literal prefix is the key of the node, if any.
+	router *mux.Router
+	// priority is the highest priority of the routes of the router.
+	priority int
+}
+
+// pathMatch is the router holding the route with the highest priority matching a request.
+type pathMatch struct {
+	router         *mux.Router
+	priority       int
+	methodMismatch bool
+}
+
+func newPathRouter(routes ...[]hostRoute) *pathRouter {
+	root := &pathNode{}
+	for _, rts := range routes {
+		for _, route := range rts {
+			if len(route.paths) == 0 {
+				root.insert("", route)
+				continue
+			}
+			for _, path := range route.paths {
+				root.insert(path, route)
+			}
+		}
+	}
+	root.build()
+
+	return &pathRouter{root: root}
+}
+
+func (p *pathRouter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var match pathMatch
+	p.match(req, &match)
+	match.serve(rw, req)
+}
+
+// match updates the match with the route with the highest priority among the routes which can match the path of the request,
+// when its priority is higher than the one of the match.
+func (p *pathRouter) match(req *http.Request, match *pathMatch) {
+	var nodes [maxPathDepth]*pathNode
+	depth := 0
+
+	path := req.URL.Path
+	for node := p.root; node != nil && depth < maxPathDepth; {
+		if node.router != nil {
+			nodes[depth] = node
+			depth++
+		}
+
+		node = node.child(path)
+		if node != nil {
+			path = path[len(node.prefix):]
+		}
+	}
+
+	// The deepest routes are matched first, their rules being the longest, they usually have the highest priorities.
+	for i := depth - 1; i >= 0; i-- {
+		node := nodes[i]
+		if match.router != nil && node.priority <= match.priority {
+			continue
+		}
+
+		var routeMatch mux.RouteMatch
+		if !node.router.Match(req, &routeMatch) || routeMatch.Route == nil {
+			if routeMatch.MatchErr == mux.ErrMethodMismatch {
+				match.methodMismatch = true
+			}
+			continue
+		}
+
+		if priority := routeMatch.Route.GetPriority(); match.router == nil || priority > match.priority {
+			match.router = node.router
+			match.priority = priority
+		}
+	}
+}
+
+// serve serves the request with the router of the match, which matches it again to set its variables.
+// Without a match, the request is answered as the router holding all the routes would answer it.
+func (m *pathMatch) serve(rw http.ResponseWriter, req *http.Request) {
+	switch {
+	case m.router != nil:
+		m.router.ServeHTTP(rw, req)
+	case m.methodMismatch:
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(rw, req)
+	}
+}
+
+// insert adds the route to the node of the key, splitting the nodes sharing a part of the key.
+func (n *pathNode) insert(key string, route hostRoute) {
+	for len(key) > 0 {
+		i := n.childIndex(key[0])
+		if i == len(n.children) || n.children[i].prefix[0] != key[0] {
+			child := &pathNode{prefix: key}
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = child
+			n = child
+			break
+		}
+
+		child := n.children[i]
+		common := commonPrefixLength(key, child.prefix)
+		if common < len(child.prefix) {
+			split := &pathNode{prefix: child.prefix[:common], children: []*pathNode{child}}
+			child.prefix = child.prefix[common:]
+			n.children[i] = split
+			child = split
+		}
+
+		n = child
+		key = key[common:]
+	}
+
+	n.routes = append(n.routes, route)
+}
+
+// build creates the routers of the node and of its children.
+func (n *pathNode) build() {
+	if len(n.routes) > 0 {
+		n.router = newRoutesRouter(n.routes)
+		n.priority = maxPriority(n.routes)
+		n.routes = nil
+	}
+
+	for _, child := range n.children {
+		child.build()
+	}
+}
+
+// child returns the child whose prefix starts the path, if any.
+func (n *pathNode) child(path string) *pathNode {
+	if len(path) == 0 {
+		return nil
+	}
+
+	i := n.childIndex(path[0])
+	if i == len(n.children) || !strings.HasPrefix(path, n.children[i].prefix) {
+		return nil
+	}
+	return n.children[i]
+}
+
+// childIndex returns the index of the child whose prefix starts with the byte, or where it would be inserted.
+func (n *pathNode) childIndex(b byte) int {
+	return sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].prefix[0] >= b
+	})
+}
+
+func commonPrefixLength(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// literalPathPrefix returns the literal prefix of a path template, before its first variable,
+// which starts all the paths the template matches.
+func literalPathPrefix(tpl string) string {
+	if i := strings.IndexByte(tpl, '{'); i >= 0 {
+		return tpl[:i]
+	}
+	return tpl
+}
@@ -0,0 +1,238 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/middlewares/requestdecorator"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathRouter(t *testing.T) {
+	type route struct {
+		name     string
+		rule     string
+		priority int
+	}
+
+	testCases := []struct {
+		desc           string
+		routes         []route
+		expected       map[string]string
+		expectedStatus map[string]int
+	}{
+		{
+			desc: "shared prefixes",
+			routes: []route{
+				{name: "api", rule: "PathPrefix:/api"},
+				{name: "v1", rule: "PathPrefix:/api/v1"},
+				{name: "apps", rule: "PathPrefix:/apps"},
+				{name: "exact", rule: "Path:/api/v1/status"},
+			},
+			expected: map[string]string{
+				"http://foo.com/api":              "api",
+				"http://foo.com/api/v2":           "api",
+				"http://foo.com/api/v1/orders":    "v1",
+				"http://foo.com/api/v1/status":    "exact",
+				"http://foo.com/api/v1/status/up": "v1",
+				"http://foo.com/apps/foo":         "apps",
+				"http://foo.com/ap":               "",
+				"http://foo.com/":                 "",
+			},
+		},
+		{
+			desc: "variables",
+			routes: []route{
+				{name: "user", rule: "Path:/users/{id:[0-9]+}"},
+				{name: "users", rule: "PathPrefix:/users"},
+				{name: "file", rule: "PathPrefix:/{file}.txt"},
+			},
+			expected: map[string]string{
+				"http://foo.com/users/42":  "user",
+				"http://foo.com/users/foo": "users",
+				"http://foo.com/foo.txt":   "file",
+				"http://foo.com/foo.json":  "",
+			},
+		},
+		{
+			desc: "priorities",
+			routes: []route{
+				{name: "root", rule: "PathPrefix:/", priority: 100},
+				{name: "deep", rule: "PathPrefix:/foo/bar", priority: 10},
+				{name: "method", rule: "Method:POST", priority: 1000},
+			},
+			expected: map[string]string{
+				"http://foo.com/foo/bar": "root",
+				"http://foo.com/baz":     "root",
+			},
+		},
+		{
+			desc: "several paths",
+			routes: []route{
+				{name: "foo", rule: "Path:/foo,/bar/{id}"},
+				{name: "baz", rule: "PathPrefix:/baz;Path:/baz/qux"},
+			},
+			expected: map[string]string{
+				"http://foo.com/foo":     "foo",
+				"http://foo.com/bar/1":   "foo",
+				"http://foo.com/bar":     "",
+				"http://foo.com/baz/qux": "baz",
+				"http://foo.com/baz":     "",
+			},
+		},
+		{
+			desc: "method not allowed",
+			routes: []route{
+				{name: "post", rule: "PathPrefix:/foo;Method:POST"},
+			},
+			expected: map[string]string{
+				"http://foo.com/foo": "",
+				"http://foo.com/bar": "",
+			},
+			expectedStatus: map[string]int{
+				"http://foo.com/foo": http.StatusMethodNotAllowed,
+				"http://foo.com/bar": http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			all := mux.NewRouter().SkipClean(true)
+			var routes []hostRoute
+			for _, rt := range test.routes {
+				rt := rt
+				handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					rw.Header().Set("X-From", rt.name)
+				})
+				require.NoError(t, addRoute(context.Background(), all, rt.rule, rt.priority, handler))
+
+				alternatives, err := parseRule(rt.rule)
+				require.NoError(t, err)
+
+				priority := rt.priority
+				if priority == 0 {
+					priority = len(rt.rule)
+				}
+				for _, alt := range alternatives {
+					routes = append(routes, hostRoute{alternative: alt, priority: priority, handler: handler})
+				}
+			}
+			all.SortRoutes()
+
+			router := newPathRouter(routes)
+
+			for calledURL, expected := range test.expected {
+				rw := httptest.NewRecorder()
+				router.ServeHTTP(rw, testhelpers.MustNewRequest(http.MethodGet, calledURL, nil))
+
+				assert.Equal(t, expected, rw.Header().Get("X-From"), calledURL)
+				if status, ok := test.expectedStatus[calledURL]; ok {
+					assert.Equal(t, status, rw.Code, calledURL)
+				}
+
+				// The router holding all the routes routes the same way.
+				allRW := httptest.NewRecorder()
+				all.ServeHTTP(allRW, testhelpers.MustNewRequest(http.MethodGet, calledURL, nil))
+
+				assert.Equal(t, expected, allRW.Header().Get("X-From"), calledURL)
+				assert.Equal(t, allRW.Code, rw.Code, calledURL)
+			}
+		})
+	}
+}
+
+func TestPathNodeInsert(t *testing.T) {
+	root := &pathNode{}
+	for _, key := range []string{"/api/v1", "/api/v2", "/apps", "/api", "", "/b"} {
+		root.insert(key, hostRoute{})
+	}
+
+	assert.Len(t, root.routes, 1)
+	require.Len(t, root.children, 1)
+
+	slash := root.children[0]
+	assert.Equal(t, "/", slash.prefix)
+	assert.Empty(t, slash.routes)
+	require.Len(t, slash.children, 2)
+	assert.Equal(t, "ap", slash.children[0].prefix)
+	assert.Equal(t, "b", slash.children[1].prefix)
+
+	ap := slash.children[0]
+	require.Len(t, ap.children, 2)
+	assert.Equal(t, "i", ap.children[0].prefix)
+	assert.Equal(t, "ps", ap.children[1].prefix)
+
+	api := ap.children[0]
+	assert.Len(t, api.routes, 1)
+	require.Len(t, api.children, 1)
+	assert.Equal(t, "/v", api.children[0].prefix)
+	require.Len(t, api.children[0].children, 2)
+	assert.Equal(t, "1", api.children[0].children[0].prefix)
+	assert.Equal(t, "2", api.children[0].children[1].prefix)
+}
+
+func TestLiteralPathPrefix(t *testing.T) {
+	testCases := map[string]string{
+		"/foo":                 "/foo",
+		"/users/{id:[0-9]+}":   "/users/",
+		"/{file}.txt":          "/",
+		"{path:.*}":            "",
+		"/foo/{bar}/baz/{qux}": "/foo/",
+	}
+
+	for tpl, expected := range testCases {
+		assert.Equal(t, expected, literalPathPrefix(tpl), tpl)
+	}
+}
+
+// countPathRoutes returns the number of routes of the node and of its children.
+func countPathRoutes(node *pathNode) int {
+	var count int
+	if node.router != nil {
+		_ = node.router.Walk(func(_ *mux.Route, _ *mux.Router, ancestors []*mux.Route) error {
+			// The path matchers add the routes of a subrouter.
+			if len(ancestors) == 0 {
+				count++
+			}
+			return nil
+		})
+	}
+
+	for _, child := range node.children {
+		count += countPathRoutes(child)
+	}
+	return count
+}
+
+func BenchmarkPathRouter(b *testing.B) {
+	for _, count := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d routers", count), func(b *testing.B) {
+			router := newHostRouter()
+			handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+			for i := 0; i < count; i++ {
+				require.NoError(b, router.addRoute(context.Background(), fmt.Sprintf("Host:foo.com;PathPrefix:/foo%d/", i), 0, handler))
+			}
+			router.build()
+
+			reqHost := requestdecorator.New(nil)
+			req := testhelpers.MustNewRequest(http.MethodGet, fmt.Sprintf("http://foo.com/foo%d/bar", count/2), nil)
+			rw := httptest.NewRecorder()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				reqHost.ServeHTTP(rw, req, router.ServeHTTP)
+			}
+		})
+	}
+}
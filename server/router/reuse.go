@@ -0,0 +1,283 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+)
+
+// Reuse makes the manager reuse the handlers built by the previous manager for the routers
+// whose configuration is unchanged, along with the configuration of the middlewares and services they depend on.
+// The reused routers keep the state of their middlewares, like the rate limiters, and of their services,
+// like the connections to the servers and the health checks.
+func (m *Manager) Reuse(previous *Manager) {
+	m.previous = previous
+}
+
+// routerDependencies holds the configurations a router depends on.
+// Files holds the hashes of the files its middlewares and services load when they are built,
+// so that the router is rebuilt when one of them changes.
+type routerDependencies struct {
+	Router      *config.Router                `json:"router"`
+	Middlewares map[string]*config.Middleware `json:"middlewares,omitempty"`
+	Services    map[string]*config.Service    `json:"services,omitempty"`
+	Files       map[string]string             `json:"files,omitempty"`
+}
+
+// dependencies returns the configurations of the middlewares and services a router depends on, directly or not.
+func (m *Manager) dependencies(router *config.Router) routerDependencies {
+	deps := routerDependencies{
+		Router:      router,
+		Middlewares: make(map[string]*config.Middleware),
+		Services:    make(map[string]*config.Service),
+	}
+
+	var addService func(serviceName string)
+	addService = func(serviceName string) {
+		if _, ok := deps.Services[serviceName]; ok || serviceName == "" {
+			return
+		}
+
+		conf, ok := m.serviceManager.Config(serviceName)
+		deps.Services[serviceName] = conf
+		if !ok || conf == nil {
+			return
+		}
+
+		if conf.LoadBalancer != nil && conf.LoadBalancer.Failover != nil {
+			addService(conf.LoadBalancer.Failover.Service)
+		}
+		if conf.BlueGreen != nil {
+			addService(conf.BlueGreen.Blue)
+			addService(conf.BlueGreen.Green)
+		}
+	}
+
+	var addMiddleware func(middlewareName string)
+	addMiddleware = func(middlewareName string) {
+		if _, ok := deps.Middlewares[middlewareName]; ok {
+			return
+		}
+
+		conf, ok := m.middlewaresBuilder.Config(middlewareName)
+		deps.Middlewares[middlewareName] = conf
+		if !ok || conf == nil {
+			return
+		}
+
+		if conf.Chain != nil {
+			for _, name := range conf.Chain.Middlewares {
+				addMiddleware(name)
+			}
+		}
+		if conf.AnyAuth != nil {
+			for _, name := range conf.AnyAuth.Middlewares {
+				addMiddleware(name)
+			}
+		}
+		if conf.Errors != nil {
+			addService(conf.Errors.Service)
+		}
+		if conf.ContentRouting != nil {
+			for _, route := range conf.ContentRouting.Routes {
+				addService(route.Service)
+			}
+		}
+		if conf.Experiment != nil {
+			for _, v := range conf.Experiment.Variants {
+				addService(v.Service)
+			}
+		}
+	}
+
+	addService(router.Service)
+	for _, name := range router.Middlewares {
+		addMiddleware(name)
+	}
+
+	return deps
+}
+
+// fingerprint returns the fingerprint of the configurations a router depends on, and of the files they load.
+func fingerprint(deps routerDependencies) (string, error) {
+	files, err := dependencyFiles(deps)
+	if err != nil {
+		return "", err
+	}
+	deps.Files = files
+
+	content, err := json.Marshal(deps)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(content)
+	return string(hash[:]), nil
+}
+
+// dependencyFiles returns the hashes of the content of the files loaded by the middlewares and services of a router.
+// The values which can be either a path or a content are hashed when they are the path of a file.
+func dependencyFiles(deps routerDependencies) (map[string]string, error) {
+	var paths []string
+	for _, conf := range deps.Middlewares {
+		if conf != nil {
+			paths = append(paths, middlewareFiles(conf)...)
+		}
+	}
+	for _, conf := range deps.Services {
+		if conf != nil && conf.LoadBalancer != nil && conf.LoadBalancer.ServersTLS != nil {
+			paths = append(paths, conf.LoadBalancer.ServersTLS.CA, conf.LoadBalancer.ServersTLS.Cert, conf.LoadBalancer.ServersTLS.Key)
+		}
+	}
+
+	files := make(map[string]string)
+	for _, path := range paths {
+		if len(path) == 0 {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		hash := sha256.Sum256(content)
+		files[path] = hex.EncodeToString(hash[:])
+	}
+
+	return files, nil
+}
+
+// middlewareFiles returns the paths of the files a middleware loads when it is built.
+func middlewareFiles(conf *config.Middleware) []string {
+	var paths []string
+	if conf.BasicAuth != nil {
+		paths = append(paths, conf.BasicAuth.UsersFile)
+	}
+	if conf.DigestAuth != nil {
+		paths = append(paths, conf.DigestAuth.UsersFile)
+	}
+	if conf.ForwardAuth != nil && conf.ForwardAuth.TLS != nil {
+		paths = append(paths, conf.ForwardAuth.TLS.CA, conf.ForwardAuth.TLS.Cert, conf.ForwardAuth.TLS.Key)
+	}
+	if conf.GRPCTranscoding != nil {
+		paths = append(paths, conf.GRPCTranscoding.DescriptorSet)
+	}
+	if conf.Tenant != nil {
+		paths = append(paths, conf.Tenant.PublicKey)
+	}
+	if conf.UpstreamAuth != nil {
+		paths = append(paths, conf.UpstreamAuth.Bearer)
+	}
+	return paths
+}
+
+// reuseRouterHandlers takes over the handlers of the previous manager for the unchanged routers,
+// and the state of their services.
+// A router sharing a service with a router which is rebuilt is rebuilt as well, as the service is.
+func (m *Manager) reuseRouterHandlers(ctx context.Context) {
+	routersServices := make(map[string]map[string]*config.Service)
+	for routerName, routerConfig := range m.configs {
+		deps := m.dependencies(routerConfig)
+		routersServices[routerName] = deps.Services
+
+		fp, err := fingerprint(deps)
+		if err != nil {
+			log.FromContext(log.With(ctx, log.Str(log.RouterName, routerName))).Errorf("Unable to compute the fingerprint of the router: %v", err)
+			continue
+		}
+		m.fingerprints[routerName] = fp
+	}
+
+	previous := m.previous
+	m.previous = nil
+	if previous == nil {
+		return
+	}
+
+	reused := make(map[string]bool)
+	for routerName, fp := range m.fingerprints {
+		if _, ok := previous.routerHandlers[routerName]; ok && previous.fingerprints[routerName] == fp {
+			reused[routerName] = true
+		}
+	}
+
+	// The services of the rebuilt routers are rebuilt, and so are the other routers using them.
+	rebuiltServices := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for routerName, services := range routersServices {
+			if reused[routerName] {
+				for serviceName := range services {
+					if rebuiltServices[serviceName] {
+						delete(reused, routerName)
+						break
+					}
+				}
+			}
+
+			if reused[routerName] {
+				continue
+			}
+
+			for serviceName := range services {
+				if !rebuiltServices[serviceName] {
+					rebuiltServices[serviceName] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	var routerNames []string
+	adopted := make(map[string]bool)
+	for routerName := range reused {
+		routerNames = append(routerNames, routerName)
+		m.routerHandlers[routerName] = previous.routerHandlers[routerName]
+
+		for serviceName := range routersServices[routerName] {
+			adopted[serviceName] = true
+		}
+	}
+	sort.Strings(routerNames)
+
+	var serviceNames []string
+	for serviceName := range adopted {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	m.serviceManager.Adopt(previous.serviceManager, serviceNames)
+
+	if len(routerNames) > 0 {
+		log.FromContext(ctx).Debugf("Reusing the handlers of the unchanged routers %v", routerNames)
+	}
+}
+
+// builtServices returns the services of the routers whose handlers are not reused, and are thus to be built.
+func (m *Manager) builtServices() []string {
+	services := make(map[string]bool)
+	for routerName, routerConfig := range m.configs {
+		if _, ok := m.routerHandlers[routerName]; ok {
+			continue
+		}
+		for serviceName := range m.dependencies(routerConfig).Services {
+			services[serviceName] = true
+		}
+	}
+
+	var serviceNames []string
+	for serviceName := range services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	return serviceNames
+}
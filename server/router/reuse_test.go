@@ -0,0 +1,157 @@
+package router
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/responsemodifiers"
+	"github.com/containous/traefik/server/middleware"
+	"github.com/containous/traefik/server/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reuseTestConfiguration() config.Configuration {
+	loadBalancer := func(url string) *config.Service {
+		return &config.Service{LoadBalancer: &config.LoadBalancerService{
+			Servers: []config.Server{{URL: url, Weight: 1}},
+			Method:  "wrr",
+		}}
+	}
+
+	return config.Configuration{
+		Routers: map[string]*config.Router{
+			"foo": {EntryPoints: []string{"web"}, Service: "foo-service", Rule: "Host:foo.bar", Middlewares: []string{"chain"}},
+			"bar": {EntryPoints: []string{"web"}, Service: "bar-service", Rule: "Host:bar.bar"},
+			"baz": {EntryPoints: []string{"web"}, Service: "bar-service", Rule: "Host:baz.bar"},
+			"qux": {EntryPoints: []string{"web"}, Service: "qux-service", Rule: "Host:qux.bar"},
+		},
+		Middlewares: map[string]*config.Middleware{
+			"chain":      {Chain: &config.Chain{Middlewares: []string{"add-prefix"}}},
+			"add-prefix": {AddPrefix: &config.AddPrefix{Prefix: "/foo"}},
+		},
+		Services: map[string]*config.Service{
+			"foo-service": loadBalancer("http://127.0.0.1:8081"),
+			"bar-service": loadBalancer("http://127.0.0.1:8082"),
+			"qux-service": {LoadBalancer: &config.LoadBalancerService{
+				Servers:  []config.Server{{URL: "http://127.0.0.1:8083", Weight: 1}},
+				Method:   "wrr",
+				Failover: &config.Failover{Service: "fallback-service"},
+			}},
+			"fallback-service": loadBalancer("http://127.0.0.1:8084"),
+		},
+	}
+}
+
+func buildReuseTestManager(conf config.Configuration, previous *Manager) *Manager {
+	serviceManager := service.NewManager(conf.Services, http.DefaultTransport, nil, nil, nil)
+	middlewaresBuilder := middleware.NewBuilder(conf.Middlewares, serviceManager, nil, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(conf.Middlewares)
+
+	manager := NewManager(conf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory, metrics.NewVoidRegistry(), nil, nil, nil)
+	manager.Reuse(previous)
+	manager.BuildHandlers(context.Background(), []string{"web"})
+	return manager
+}
+
+func TestManager_Reuse(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		update   func(conf config.Configuration)
+		expected []string
+	}{
+		{
+			desc:     "unchanged configuration",
+			update:   func(conf config.Configuration) {},
+			expected: []string{"foo", "bar", "baz", "qux"},
+		},
+		{
+			desc: "changed router",
+			update: func(conf config.Configuration) {
+				conf.Routers["foo"] = &config.Router{EntryPoints: []string{"web"}, Service: "foo-service", Rule: "Host:foo.com", Middlewares: []string{"chain"}}
+			},
+			expected: []string{"bar", "baz", "qux"},
+		},
+		{
+			desc: "changed middleware in a chain",
+			update: func(conf config.Configuration) {
+				conf.Middlewares["add-prefix"] = &config.Middleware{AddPrefix: &config.AddPrefix{Prefix: "/bar"}}
+			},
+			expected: []string{"bar", "baz", "qux"},
+		},
+		{
+			desc: "changed router sharing its service",
+			update: func(conf config.Configuration) {
+				conf.Routers["baz"] = &config.Router{EntryPoints: []string{"web"}, Service: "bar-service", Rule: "Host:baz.com"}
+			},
+			expected: []string{"foo", "qux"},
+		},
+		{
+			desc: "changed failover service",
+			update: func(conf config.Configuration) {
+				conf.Services["fallback-service"].LoadBalancer.Servers[0].URL = "http://127.0.0.1:8085"
+			},
+			expected: []string{"foo", "bar", "baz"},
+		},
+		{
+			desc: "removed router",
+			update: func(conf config.Configuration) {
+				delete(conf.Routers, "foo")
+			},
+			expected: []string{"bar", "baz", "qux"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			previous := buildReuseTestManager(reuseTestConfiguration(), nil)
+
+			conf := reuseTestConfiguration()
+			test.update(conf)
+			manager := buildReuseTestManager(conf, previous)
+
+			var reused []string
+			for routerName, handler := range manager.routerHandlers {
+				if handler == previous.routerHandlers[routerName] {
+					reused = append(reused, routerName)
+				}
+			}
+
+			assert.ElementsMatch(t, test.expected, reused)
+			assert.Len(t, manager.routerHandlers, len(conf.Routers))
+		})
+	}
+}
+
+func TestManager_ReuseChangedFile(t *testing.T) {
+	usersFile, err := ioutil.TempFile("", "traefik-users")
+	require.NoError(t, err)
+	defer os.Remove(usersFile.Name())
+
+	_, err = usersFile.WriteString("test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/\n")
+	require.NoError(t, err)
+	require.NoError(t, usersFile.Close())
+
+	conf := reuseTestConfiguration()
+	conf.Middlewares["auth"] = &config.Middleware{BasicAuth: &config.BasicAuth{UsersFile: usersFile.Name()}}
+	conf.Routers["bar"].Middlewares = []string{"auth"}
+
+	previous := buildReuseTestManager(conf, nil)
+
+	// The router is reused while the file is unchanged.
+	manager := buildReuseTestManager(conf, previous)
+	assert.True(t, previous.routerHandlers["bar"] == manager.routerHandlers["bar"])
+
+	require.NoError(t, ioutil.WriteFile(usersFile.Name(), []byte("test2:$apr1$d9hr9HBB$4HxwgUir3HP4EsggP/QNo0\n"), 0644))
+
+	// The router is rebuilt with the new content of the file.
+	rebuilt := buildReuseTestManager(conf, manager)
+	assert.False(t, manager.routerHandlers["bar"] == rebuilt.routerHandlers["bar"])
+	assert.True(t, manager.routerHandlers["qux"] == rebuilt.routerHandlers["qux"])
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/containous/alice"
 	"github.com/containous/mux"
@@ -33,17 +34,54 @@ func NewRouteAppenderAggregator(ctx context.Context, chainBuilder chainBuilder,
 		if err != nil {
 			logger.Error(err)
 		} else {
+			if conf.API.RBAC != nil {
+				rbacChain := chain.Append(func(next http.Handler) (http.Handler, error) {
+					return api.NewRBAC(*conf.API.RBAC, next), nil
+				})
+				chain = &rbacChain
+			}
+
 			aggregator.AddAppender(&WithMiddleware{
 				appender: api.Handler{
 					EntryPoint:            conf.API.EntryPoint,
 					Dashboard:             conf.API.Dashboard,
 					Statistics:            conf.API.Statistics,
 					DashboardAssets:       conf.API.DashboardAssets,
+					History:               conf.API.History,
+					Drains:                conf.API.Drains,
+					Switches:              conf.API.Switches,
+					Taps:                  conf.API.Taps,
+					Namespaces:            conf.API.Namespaces,
+					Certificates:          conf.API.Certificates,
+					AccessLogs:            conf.API.AccessLogs,
 					CurrentConfigurations: currentConfiguration,
 					Debug:                 conf.Global.Debug,
 				},
 				routerMiddlewares: chain,
 			})
+
+			var entryPoints []string
+			for name := range conf.EntryPoints {
+				entryPoints = append(entryPoints, name)
+			}
+
+			aggregator.AddAppender(&WithMiddleware{
+				appender:          validationHandler{entryPoints: entryPoints, plugins: conf.API.Plugins},
+				routerMiddlewares: chain,
+			})
+
+			aggregator.AddAppender(&WithMiddleware{
+				appender:          diagnosticsHandler{currentConfigurations: currentConfiguration},
+				routerMiddlewares: chain,
+			})
+
+			if conf.API.Namespaces != nil {
+				// The namespace routes are authenticated by the tokens of the namespaces.
+				aggregator.AddAppender(api.NamespaceHandler{
+					Namespaces:            conf.API.Namespaces,
+					CurrentConfigurations: currentConfiguration,
+				})
+			}
 		}
 	}
 
@@ -59,6 +97,18 @@ func NewRouteAppenderAggregator(ctx context.Context, chainBuilder chainBuilder,
 		}
 	}
 
+	if conf.Diagnostics != nil && conf.Diagnostics.EntryPoint == entryPointName {
+		chain, err := chainBuilder.BuildChain(ctx, conf.Diagnostics.Middlewares)
+		if err != nil {
+			logger.Error(err)
+		} else {
+			aggregator.AddAppender(&WithMiddleware{
+				appender:          conf.Diagnostics,
+				routerMiddlewares: chain,
+			})
+		}
+	}
+
 	if conf.Metrics != nil && conf.Metrics.Prometheus != nil && conf.Metrics.Prometheus.EntryPoint == entryPointName {
 		chain, err := chainBuilder.BuildChain(ctx, conf.Metrics.Prometheus.Middlewares)
 		if err != nil {
@@ -6,15 +6,20 @@ import (
 	"net/http"
 
 	"github.com/containous/alice"
-	"github.com/containous/mux"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/loadshedding"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/middlewares/accesslog"
+	metricsmiddleware "github.com/containous/traefik/middlewares/metrics"
 	"github.com/containous/traefik/middlewares/recovery"
 	"github.com/containous/traefik/middlewares/tracing"
 	"github.com/containous/traefik/responsemodifiers"
+	"github.com/containous/traefik/scheduler"
 	"github.com/containous/traefik/server/middleware"
 	"github.com/containous/traefik/server/service"
+	"github.com/containous/traefik/slo"
+	"github.com/containous/traefik/tap"
 )
 
 const (
@@ -24,27 +29,42 @@ const (
 // NewManager Creates a new Manager
 func NewManager(routers map[string]*config.Router,
 	serviceManager *service.Manager, middlewaresBuilder *middleware.Builder, modifierBuilder *responsemodifiers.Builder,
+	metricsRegistry metrics.Registry, taps *tap.Registry, shedder *loadshedding.Shedder, slos *slo.Registry,
 ) *Manager {
 	return &Manager{
 		routerHandlers:     make(map[string]http.Handler),
+		fingerprints:       make(map[string]string),
 		configs:            routers,
 		serviceManager:     serviceManager,
 		middlewaresBuilder: middlewaresBuilder,
 		modifierBuilder:    modifierBuilder,
+		metricsRegistry:    metricsRegistry,
+		taps:               taps,
+		shedder:            shedder,
+		slos:               slos,
 	}
 }
 
 // Manager A route/router manager
 type Manager struct {
 	routerHandlers     map[string]http.Handler
+	fingerprints       map[string]string
+	previous           *Manager
 	configs            map[string]*config.Router
 	serviceManager     *service.Manager
 	middlewaresBuilder *middleware.Builder
 	modifierBuilder    *responsemodifiers.Builder
+	metricsRegistry    metrics.Registry
+	taps               *tap.Registry
+	shedder            *loadshedding.Shedder
+	slos               *slo.Registry
 }
 
 // BuildHandlers Builds handler for all entry points
 func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) map[string]http.Handler {
+	m.reuseRouterHandlers(rootCtx)
+	m.serviceManager.Prepare(rootCtx, m.builtServices())
+
 	entryPointsRouters := m.filteredRouters(rootCtx, entryPoints)
 
 	entryPointHandlers := make(map[string]http.Handler)
@@ -60,10 +80,27 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 	}
 
 	m.serviceManager.LaunchHealthCheck()
+	m.serviceManager.RegisterDrains()
+	m.serviceManager.RegisterSwitches()
+	m.registerTaps()
 
 	return entryPointHandlers
 }
 
+// registerTaps hands the names of the routers built by the manager to the tap registry.
+func (m *Manager) registerTaps() {
+	if m.taps == nil {
+		return
+	}
+
+	var routers []string
+	for routerName := range m.routerHandlers {
+		routers = append(routers, routerName)
+	}
+
+	m.taps.SetRouters(routers)
+}
+
 func contains(entryPoints []string, entryPointName string) bool {
 	for _, name := range entryPoints {
 		if name == entryPointName {
@@ -100,8 +137,7 @@ func (m *Manager) filteredRouters(ctx context.Context, entryPoints []string) map
 }
 
 func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*config.Router) (http.Handler, error) {
-	router := mux.NewRouter().
-		SkipClean(true)
+	router := newHostRouter()
 
 	for routerName, routerConfig := range configs {
 		ctx = log.With(ctx, log.Str(log.RouterName, routerName))
@@ -113,19 +149,22 @@ func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string
 			continue
 		}
 
-		err = addRoute(ctx, router, routerConfig.Rule, routerConfig.Priority, handler)
+		err = router.addRoute(ctx, routerConfig.Rule, routerConfig.Priority, handler)
 		if err != nil {
 			logger.Error(err)
 			continue
 		}
 	}
 
-	router.SortRoutes()
+	router.build()
 
 	chain := alice.New()
 	chain = chain.Append(func(next http.Handler) (http.Handler, error) {
 		return recovery.New(ctx, next, recoveryMiddlewareName)
 	})
+	chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+		return m.shedder.Observe(next), nil
+	})
 
 	return chain.Then(router)
 }
@@ -145,14 +184,26 @@ func (m *Manager) buildRouterHandler(ctx context.Context, routerName string) (ht
 		return nil, err
 	}
 
+	if configRouter.LowPriority {
+		handler = m.shedder.Wrap(handler)
+	}
+	if len(configRouter.PriorityClass) > 0 {
+		handler = scheduler.WithClass(configRouter.PriorityClass, handler)
+	}
+
+	handler, err = m.slos.Wrap(routerName, configRouter.SLO, handler)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the latency objective: %v", err)
+	}
+
 	handlerWithAccessLog, err := alice.New(func(next http.Handler) (http.Handler, error) {
 		return accesslog.NewFieldHandler(next, accesslog.RouterName, routerName, nil), nil
 	}).Then(handler)
 	if err != nil {
 		log.FromContext(ctx).Error(err)
-		m.routerHandlers[routerName] = handler
+		m.routerHandlers[routerName] = m.taps.Wrap(routerName, handler)
 	} else {
-		m.routerHandlers[routerName] = handlerWithAccessLog
+		m.routerHandlers[routerName] = m.taps.Wrap(routerName, handlerWithAccessLog)
 	}
 
 	return m.routerHandlers[routerName], nil
@@ -179,5 +230,17 @@ func (m *Manager) buildHandler(ctx context.Context, router *config.Router, route
 		return tracing.NewForwarder(ctx, routerName, router.Service, next), nil
 	}
 
-	return alice.New().Append(alHandler).Extend(*mHandler).Append(tHandler).Then(sHandler)
+	chain := alice.New()
+	if router.Tracing != nil {
+		chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+			return tracing.NewSampling(ctx, router.Tracing.SampleRate, next), nil
+		})
+	}
+	chain = chain.Append(alHandler).Extend(*mHandler).Append(tHandler)
+
+	if m.metricsRegistry.IsEnabled() {
+		chain = chain.Append(metricsmiddleware.WrapServiceHandler(ctx, m.metricsRegistry, router.Service))
+	}
+
+	return chain.Then(sHandler)
 }
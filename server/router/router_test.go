@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/middlewares/accesslog"
 	"github.com/containous/traefik/middlewares/requestdecorator"
 	"github.com/containous/traefik/responsemodifiers"
@@ -216,11 +217,11 @@ func TestRouterManager_Get(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			serviceManager := service.NewManager(test.serviceConfig, http.DefaultTransport)
-			middlewaresBuilder := middleware.NewBuilder(test.middlewaresConfig, serviceManager)
+			serviceManager := service.NewManager(test.serviceConfig, http.DefaultTransport, nil, nil, nil)
+			middlewaresBuilder := middleware.NewBuilder(test.middlewaresConfig, serviceManager, nil, nil)
 			responseModifierFactory := responsemodifiers.NewBuilder(test.middlewaresConfig)
 
-			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory)
+			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, metrics.NewVoidRegistry(), nil, nil, nil)
 
 			handlers := routerManager.BuildHandlers(context.Background(), test.entryPoints)
 
@@ -316,11 +317,11 @@ func TestAccessLog(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.desc, func(t *testing.T) {
 
-			serviceManager := service.NewManager(test.serviceConfig, http.DefaultTransport)
-			middlewaresBuilder := middleware.NewBuilder(test.middlewaresConfig, serviceManager)
+			serviceManager := service.NewManager(test.serviceConfig, http.DefaultTransport, nil, nil, nil)
+			middlewaresBuilder := middleware.NewBuilder(test.middlewaresConfig, serviceManager, nil, nil)
 			responseModifierFactory := responsemodifiers.NewBuilder(test.middlewaresConfig)
 
-			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory)
+			routerManager := NewManager(test.routersConfig, serviceManager, middlewaresBuilder, responseModifierFactory, metrics.NewVoidRegistry(), nil, nil, nil)
 
 			handlers := routerManager.BuildHandlers(context.Background(), test.entryPoints)
 
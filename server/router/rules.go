@@ -4,15 +4,18 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 
 	"github.com/containous/mux"
+	"github.com/containous/traefik/ip"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
 	"github.com/containous/traefik/middlewares/requestdecorator"
 )
 
 func addRoute(ctx context.Context, router *mux.Router, rule string, priority int, handler http.Handler) error {
-	matchers, err := parseRule(rule)
+	alternatives, err := parseRule(rule)
 	if err != nil {
 		return err
 	}
@@ -21,9 +24,12 @@ func addRoute(ctx context.Context, router *mux.Router, rule string, priority int
 		priority = len(rule)
 	}
 
-	route := router.NewRoute().Handler(handler).Priority(priority)
-	for _, matcher := range matchers {
-		matcher(route)
+	// Each alternative of the rule is a route to the handler.
+	for _, alt := range alternatives {
+		route, err := addAlternative(router, alt, priority, handler)
+		if err != nil {
+			return err
+		}
 		if route.GetError() != nil {
 			log.FromContext(ctx).Error(route.GetError())
 		}
@@ -32,8 +38,53 @@ func addRoute(ctx context.Context, router *mux.Router, rule string, priority int
 	return nil
 }
 
-func parseRule(rule string) ([]func(*mux.Route), error) {
-	funcs := map[string]func(*mux.Route, ...string){
+// addAlternative adds the route of an alternative of a rule to the router.
+func addAlternative(router *mux.Router, alt alternative, priority int, handler http.Handler) (*mux.Route, error) {
+	route := router.NewRoute().Handler(handler).Priority(priority)
+	for _, matcher := range alt.matchers {
+		if err := matcher(route); err != nil {
+			return nil, err
+		}
+	}
+	return route, nil
+}
+
+// alternative holds the matchers of an alternative of a rule,
+// the hosts of its first Host matcher, which the alternative can only match, if any,
+// and the literal prefixes of the paths of its first Path or PathPrefix matcher, which start all the paths it can match, if any.
+type alternative struct {
+	matchers []func(*mux.Route) error
+	hosts    []string
+	paths    []string
+}
+
+// parseRule parses the alternatives of a rule, separated by ||, into the matchers of each alternative.
+// A matcher which returns an error never matches.
+func parseRule(rule string) ([]alternative, error) {
+	parts := strings.Split(rule, "||")
+
+	var alternatives []alternative
+	for _, part := range parts {
+		if len(parts) > 1 && len(strings.TrimSpace(part)) == 0 {
+			return nil, fmt.Errorf("empty alternative in the rule: %s", rule)
+		}
+
+		alt, err := parseMatchers(part)
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, alt)
+	}
+
+	return alternatives, nil
+}
+
+func parseMatchers(rule string) (alternative, error) {
+	funcs := map[string]func(*mux.Route, ...string) error{
+		"ClientIP":      clientIP,
+		"Cookie":        cookie,
+		"Header":        header,
+		"HeaderRegexp":  headerRegexp,
 		"Host":          host,
 		"HostRegexp":    hostRegexp,
 		"Path":          path,
@@ -49,9 +100,10 @@ func parseRule(rule string) ([]func(*mux.Route), error) {
 	}
 	parsedRules := strings.FieldsFunc(rule, splitRule)
 
-	var matchers []func(*mux.Route)
+	var alt alternative
 
 	for _, expression := range parsedRules {
+		expression = strings.TrimSpace(expression)
 		expParts := strings.Split(expression, ":")
 		if len(expParts) > 1 && len(expParts[1]) > 0 {
 			if fn, ok := funcs[expParts[0]]; ok {
@@ -68,41 +120,56 @@ func parseRule(rule string) ([]func(*mux.Route), error) {
 				}
 
 				// FIXME struct for onhostrule ?
-				matcher := func(rt *mux.Route) {
-					fn(rt, trimmedExp...)
+				matcher := func(rt *mux.Route) error {
+					return fn(rt, trimmedExp...)
 				}
 
-				matchers = append(matchers, matcher)
+				alt.matchers = append(alt.matchers, matcher)
+
+				if expParts[0] == "Host" && alt.hosts == nil {
+					for _, host := range trimmedExp {
+						alt.hosts = append(alt.hosts, strings.ToLower(host))
+					}
+				}
+
+				if (expParts[0] == "Path" || expParts[0] == "PathPrefix") && alt.paths == nil {
+					for _, path := range trimmedExp {
+						alt.paths = append(alt.paths, literalPathPrefix(path))
+					}
+				}
 			} else {
-				return nil, fmt.Errorf("invalid matcher: %s", expression)
+				return alternative{}, fmt.Errorf("invalid matcher: %s", expression)
 			}
 		}
 	}
 
-	return matchers, nil
+	return alt, nil
 }
 
-func path(route *mux.Route, paths ...string) {
+func path(route *mux.Route, paths ...string) error {
 	rt := route.Subrouter()
 	for _, path := range paths {
-		tmpRt := rt.Path(path)
+		// The route of a match is the innermost one, so the routes of the subrouters have the priority of their route.
+		tmpRt := rt.Path(path).Priority(route.GetPriority())
 		if tmpRt.GetError() != nil {
 			log.WithoutContext().WithField("paths", strings.Join(paths, ",")).Error(tmpRt.GetError())
 		}
 	}
+	return nil
 }
 
-func pathPrefix(route *mux.Route, paths ...string) {
+func pathPrefix(route *mux.Route, paths ...string) error {
 	rt := route.Subrouter()
 	for _, path := range paths {
-		tmpRt := rt.PathPrefix(path)
+		tmpRt := rt.PathPrefix(path).Priority(route.GetPriority())
 		if tmpRt.GetError() != nil {
 			log.WithoutContext().WithField("paths", strings.Join(paths, ",")).Error(tmpRt.GetError())
 		}
 	}
+	return nil
 }
 
-func host(route *mux.Route, hosts ...string) {
+func host(route *mux.Route, hosts ...string) error {
 	for i, host := range hosts {
 		hosts[i] = strings.ToLower(host)
 	}
@@ -117,7 +184,7 @@ func host(route *mux.Route, hosts ...string) {
 		flatH := requestdecorator.GetCNAMEFlatten(req.Context())
 		if len(flatH) > 0 {
 			for _, host := range hosts {
-				if strings.EqualFold(reqHost, host) || strings.EqualFold(flatH, host) {
+				if matchHost(reqHost, host) || matchHost(strings.ToLower(flatH), host) {
 					return true
 				}
 				log.FromContext(req.Context()).Debugf("CNAMEFlattening: request %s which resolved to %s, is not matched to route %s", reqHost, flatH, host)
@@ -126,38 +193,174 @@ func host(route *mux.Route, hosts ...string) {
 		}
 
 		for _, host := range hosts {
-			if reqHost == host {
+			if matchHost(reqHost, host) {
 				return true
 			}
 		}
 		return false
 	})
+	return nil
+}
+
+// matchHost matches the host with a literal host, or with a wildcard host as *.example.com,
+// matching the subdomains of a single label.
+func matchHost(reqHost, host string) bool {
+	if !strings.HasPrefix(host, "*.") {
+		return reqHost == host
+	}
+
+	label := strings.TrimSuffix(reqHost, host[1:])
+	return len(label) > 0 && len(label) < len(reqHost) && !strings.Contains(label, ".")
 }
 
-func hostRegexp(route *mux.Route, hosts ...string) {
+func hostRegexp(route *mux.Route, hosts ...string) error {
 	router := route.Subrouter()
 	for _, host := range hosts {
-		router.Host(host)
+		router.Host(host).Priority(route.GetPriority())
 	}
+	return nil
 }
 
-func methods(route *mux.Route, methods ...string) {
+func methods(route *mux.Route, methods ...string) error {
 	route.Methods(methods...)
+	return nil
 }
 
-func headers(route *mux.Route, headers ...string) {
+func headers(route *mux.Route, headers ...string) error {
 	route.Headers(headers...)
+	return nil
 }
 
-func headersRegexp(route *mux.Route, headers ...string) {
+func headersRegexp(route *mux.Route, headers ...string) error {
 	route.HeadersRegexp(headers...)
+	return nil
 }
 
-func query(route *mux.Route, query ...string) {
+func query(route *mux.Route, query ...string) error {
 	var queries []string
 	for _, elem := range query {
 		queries = append(queries, strings.Split(elem, "=")...)
 	}
 
 	route.Queries(queries...)
+	return nil
+}
+
+// header matches the requests having one of the headers, given as Name=Value.
+func header(route *mux.Route, headers ...string) error {
+	values := make(map[string][]string)
+	for _, elem := range headers {
+		parts := strings.SplitN(elem, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return rejectAll(route, fmt.Errorf("invalid header %q, expected Name=Value", elem))
+		}
+		name := http.CanonicalHeaderKey(parts[0])
+		values[name] = append(values[name], parts[1])
+	}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		for name, expected := range values {
+			for _, value := range req.Header[name] {
+				if contains(expected, value) {
+					return true
+				}
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// headerRegexp matches the requests having one of the headers, given as Name=regexp.
+func headerRegexp(route *mux.Route, headers ...string) error {
+	regexps := make(map[string][]*regexp.Regexp)
+	for _, elem := range headers {
+		parts := strings.SplitN(elem, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return rejectAll(route, fmt.Errorf("invalid header %q, expected Name=regexp", elem))
+		}
+
+		exp, err := regexp.Compile(parts[1])
+		if err != nil {
+			return rejectAll(route, fmt.Errorf("invalid regexp of the header %s: %v", parts[0], err))
+		}
+
+		name := http.CanonicalHeaderKey(parts[0])
+		regexps[name] = append(regexps[name], exp)
+	}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		for name, exps := range regexps {
+			for _, value := range req.Header[name] {
+				for _, exp := range exps {
+					if exp.MatchString(value) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// cookie matches the requests having one of the cookies, given as name=value, or as name to only check its presence.
+func cookie(route *mux.Route, cookies ...string) error {
+	type expectedCookie struct {
+		name  string
+		value string
+		any   bool
+	}
+
+	var expected []expectedCookie
+	for _, elem := range cookies {
+		parts := strings.SplitN(elem, "=", 2)
+		if len(parts[0]) == 0 {
+			return rejectAll(route, fmt.Errorf("invalid cookie %q, expected name=value or name", elem))
+		}
+
+		if len(parts) == 1 {
+			expected = append(expected, expectedCookie{name: parts[0], any: true})
+		} else {
+			expected = append(expected, expectedCookie{name: parts[0], value: parts[1]})
+		}
+	}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		for _, c := range req.Cookies() {
+			for _, e := range expected {
+				if c.Name == e.name && (e.any || c.Value == e.value) {
+					return true
+				}
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// clientIP matches the requests whose client IP, resolved from the trusted forwarded headers, is in one of the ranges.
+func clientIP(route *mux.Route, ranges ...string) error {
+	checker, err := ip.NewChecker(ranges)
+	if err != nil {
+		return rejectAll(route, fmt.Errorf("invalid client IP ranges %s: %v", strings.Join(ranges, ","), err))
+	}
+
+	route.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		ok, err := checker.Contains(forwardedheaders.GetClientIP(req))
+		if err != nil {
+			log.FromContext(req.Context()).Debugf("Unable to check the client IP: %v", err)
+			return false
+		}
+		return ok
+	})
+	return nil
+}
+
+// rejectAll makes the route match no request, and returns the error which invalidates it.
+func rejectAll(route *mux.Route, err error) error {
+	route.MatcherFunc(func(*http.Request, *mux.RouteMatch) bool {
+		return false
+	})
+	return err
 }
@@ -7,6 +7,9 @@ import (
 	"testing"
 
 	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/middlewares/addprefix"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
 	"github.com/containous/traefik/middlewares/requestdecorator"
 	"github.com/containous/traefik/testhelpers"
 	"github.com/stretchr/testify/assert"
@@ -204,6 +207,113 @@ func Test_addRoute(t *testing.T) {
 				"http://localhost/foo?bar=baz": http.StatusNotFound,
 			},
 		},
+		{
+			desc: "wildcard Host",
+			rule: "Host:*.localhost",
+			expected: map[string]int{
+				"http://foo.localhost/foo":     http.StatusOK,
+				"http://FOO.localhost/foo":     http.StatusOK,
+				"http://bar.foo.localhost/foo": http.StatusNotFound,
+				"http://localhost/foo":         http.StatusNotFound,
+			},
+		},
+		{
+			desc:    "Header",
+			rule:    "Header:X-Canary=true",
+			headers: map[string]string{"X-Canary": "true"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusOK,
+			},
+		},
+		{
+			desc:    "wrong Header",
+			rule:    "Header:X-Canary=true",
+			headers: map[string]string{"X-Canary": "false"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusNotFound,
+			},
+		},
+		{
+			desc:    "Header OR",
+			rule:    "Header:X-Canary=true, X-Beta=1",
+			headers: map[string]string{"X-Beta": "1"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusOK,
+			},
+		},
+		{
+			desc:    "HeaderRegexp",
+			rule:    "HeaderRegexp:User-Agent=^Mobile.*",
+			headers: map[string]string{"User-Agent": "Mobile Safari"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusOK,
+			},
+		},
+		{
+			desc:    "wrong HeaderRegexp",
+			rule:    "HeaderRegexp:User-Agent=^Mobile.*",
+			headers: map[string]string{"User-Agent": "Desktop"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusNotFound,
+			},
+		},
+		{
+			desc:    "Cookie with value",
+			rule:    "Cookie:beta=on",
+			headers: map[string]string{"Cookie": "session=123; beta=on"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusOK,
+			},
+		},
+		{
+			desc:    "Cookie with wrong value",
+			rule:    "Cookie:beta=on",
+			headers: map[string]string{"Cookie": "beta=off"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusNotFound,
+			},
+		},
+		{
+			desc:    "Cookie presence",
+			rule:    "Cookie:session",
+			headers: map[string]string{"Cookie": "session=123"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusOK,
+			},
+		},
+		{
+			desc: "missing Cookie",
+			rule: "Cookie:session",
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusNotFound,
+			},
+		},
+		{
+			desc:    "Header and PathPrefix",
+			rule:    "Header:X-Canary=true;PathPrefix:/bar",
+			headers: map[string]string{"X-Canary": "true"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusNotFound,
+				"http://localhost/bar": http.StatusOK,
+			},
+		},
+		{
+			desc:    "alternatives",
+			rule:    "Host:nope;PathPrefix:/foo || Header:X-Canary=true;PathPrefix:/bar",
+			headers: map[string]string{"X-Canary": "true"},
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusNotFound,
+				"http://localhost/bar": http.StatusOK,
+			},
+		},
+		{
+			desc: "alternatives: first alternative",
+			rule: "PathPrefix:/foo || Header:X-Canary=true;PathPrefix:/bar",
+			expected: map[string]int{
+				"http://localhost/foo": http.StatusOK,
+				"http://localhost/bar": http.StatusNotFound,
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -239,6 +349,138 @@ func Test_addRoute(t *testing.T) {
 	}
 }
 
+func Test_addRouteInvalidRule(t *testing.T) {
+	testCases := []struct {
+		desc string
+		rule string
+	}{
+		{
+			desc: "Header without value",
+			rule: "Header:X-Canary",
+		},
+		{
+			desc: "HeaderRegexp with an invalid regexp",
+			rule: "HeaderRegexp:User-Agent=(",
+		},
+		{
+			desc: "Cookie without name",
+			rule: "Cookie:=on",
+		},
+		{
+			desc: "ClientIP with an invalid range",
+			rule: "ClientIP:10.0.0.0/33",
+		},
+		{
+			desc: "empty alternative",
+			rule: "PathPrefix:/foo ||",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router := mux.NewRouter()
+			err := addRoute(context.Background(), router, test.rule, 0, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			assert.Error(t, err)
+
+			err = validateRule(test.rule)
+			assert.Error(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost/foo", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Canary", "true")
+			req.Header.Set("User-Agent", "Mobile")
+			req.AddCookie(&http.Cookie{Name: "beta", Value: "on"})
+			assert.False(t, router.Match(req, &mux.RouteMatch{}))
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		ranges     []string
+		remoteAddr string
+		clientIP   string
+		expected   bool
+	}{
+		{
+			desc:       "remote address in the range",
+			ranges:     []string{"10.0.0.0/8"},
+			remoteAddr: "10.1.2.3:1234",
+			expected:   true,
+		},
+		{
+			desc:       "remote address out of the ranges",
+			ranges:     []string{"10.0.0.0/8", "192.168.1.1"},
+			remoteAddr: "172.16.0.1:1234",
+			expected:   false,
+		},
+		{
+			desc:       "remote address equal to an IP",
+			ranges:     []string{"10.0.0.0/8", "192.168.1.1"},
+			remoteAddr: "192.168.1.1:1234",
+			expected:   true,
+		},
+		{
+			desc:       "client IP resolved from the forwarded headers",
+			ranges:     []string{"1.2.3.0/24"},
+			remoteAddr: "10.0.0.1:1234",
+			clientIP:   "1.2.3.4",
+			expected:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rt := &mux.Route{}
+			err := clientIP(rt, test.ranges...)
+			require.NoError(t, err)
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, test.expected, rt.Match(req, &mux.RouteMatch{}))
+			})
+
+			var handler http.Handler = next
+			if test.clientIP != "" {
+				handler, err = forwardedheaders.NewXForwarded(false, []string{"10.0.0.1"}, next)
+				require.NoError(t, err)
+			}
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost/foo", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.clientIP != "" {
+				req.Header.Set("X-Forwarded-For", test.clientIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		})
+	}
+}
+
+func Test_addRouteVars(t *testing.T) {
+	var path string
+	next := http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		path = req.URL.Path
+	})
+
+	handler, err := addprefix.New(context.Background(), next, config.AddPrefix{Prefix: "/tenants/{tenant}"}, "tenant")
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	err = addRoute(context.Background(), router, "HostRegexp:{tenant:[a-z]+}.example.com", 0, handler)
+	require.NoError(t, err)
+
+	req := testhelpers.MustNewRequest(http.MethodGet, "http://acme.example.com/api", nil)
+	requestdecorator.New(nil).ServeHTTP(httptest.NewRecorder(), req, router.ServeHTTP)
+
+	assert.Equal(t, "/tenants/acme/api", path)
+}
+
 func Test_addRoutePriority(t *testing.T) {
 	type Case struct {
 		xFrom    string
@@ -0,0 +1,173 @@
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	tcpservice "github.com/containous/traefik/server/service/tcp"
+	"github.com/containous/traefik/tcp"
+)
+
+// NewManager creates a new Manager.
+// The connections matching no TCP router go to the HTTP forwarder of their entry point,
+// and the TLS terminating routers use the TLS configuration of their entry point.
+func NewManager(routers map[string]*config.TCPRouter, serviceManager *tcpservice.Manager,
+	httpForwarders map[string]tcp.Handler, tlsConfigs map[string]*tls.Config,
+) *Manager {
+	return &Manager{
+		configs:        routers,
+		serviceManager: serviceManager,
+		httpForwarders: httpForwarders,
+		tlsConfigs:     tlsConfigs,
+	}
+}
+
+// Manager builds the TCP routers of the entry points.
+type Manager struct {
+	configs        map[string]*config.TCPRouter
+	serviceManager *tcpservice.Manager
+	httpForwarders map[string]tcp.Handler
+	tlsConfigs     map[string]*tls.Config
+}
+
+// BuildHandlers builds the TCP router of each entry point.
+func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) map[string]*tcp.Router {
+	entryPointsRouters := m.filteredRouters(rootCtx, entryPoints)
+
+	handlers := make(map[string]*tcp.Router)
+	for _, entryPointName := range entryPoints {
+		ctx := log.With(rootCtx, log.Str(log.EntryPointName, entryPointName))
+		handlers[entryPointName] = m.buildEntryPointHandler(ctx, entryPointName, entryPointsRouters[entryPointName])
+	}
+
+	return handlers
+}
+
+func (m *Manager) buildEntryPointHandler(ctx context.Context, entryPointName string, configs map[string]*config.TCPRouter) *tcp.Router {
+	router := tcp.NewRouter()
+	if forwarder, ok := m.httpForwarders[entryPointName]; ok {
+		router.HTTPForwarder(forwarder)
+	}
+
+	// Sorted, so that conflicting routers are resolved the same way on every reload.
+	var routerNames []string
+	for routerName := range configs {
+		routerNames = append(routerNames, routerName)
+	}
+	sort.Strings(routerNames)
+
+	for _, routerName := range routerNames {
+		routerConfig := configs[routerName]
+
+		ctxRouter := log.With(ctx, log.Str(log.RouterName, routerName))
+		logger := log.FromContext(ctxRouter)
+
+		domains, err := parseHostSNI(routerConfig.Rule)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+
+		handler, err := m.serviceManager.BuildTCP(ctxRouter, routerConfig.Service)
+		if err != nil {
+			logger.Error(err)
+			continue
+		}
+
+		switch {
+		case routerConfig.TLS == nil:
+			if len(domains) != 1 || domains[0] != "*" {
+				logger.Errorf("Without TLS, there is no server name to match: the rule must be HostSNI:*")
+				continue
+			}
+			router.AddCatchAllNoTLS(handler)
+
+		case routerConfig.TLS.Passthrough:
+			for _, domain := range domains {
+				logger.Debugf("Adding TLS passthrough route for %q", domain)
+				router.AddRoute(domain, handler)
+			}
+
+		default:
+			tlsConfig, ok := m.tlsConfigs[entryPointName]
+			if !ok || tlsConfig == nil {
+				logger.Errorf("The entry point %s has no TLS configuration to terminate TLS", entryPointName)
+				continue
+			}
+
+			tlsConfig = tlsConfig.Clone()
+			// The protocols negotiated for HTTP don't apply to the TCP servers.
+			tlsConfig.NextProtos = nil
+
+			for _, domain := range domains {
+				logger.Debugf("Adding TLS terminating route for %q", domain)
+				router.AddRouteTLS(domain, handler, tlsConfig)
+			}
+		}
+	}
+
+	return router
+}
+
+func (m *Manager) filteredRouters(ctx context.Context, entryPoints []string) map[string]map[string]*config.TCPRouter {
+	entryPointsRouters := make(map[string]map[string]*config.TCPRouter)
+
+	for rtName, rt := range m.configs {
+		eps := rt.EntryPoints
+		if len(eps) == 0 {
+			eps = entryPoints
+		}
+		for _, entryPointName := range eps {
+			if !contains(entryPoints, entryPointName) {
+				log.FromContext(log.With(ctx, log.Str(log.EntryPointName, entryPointName))).
+					Errorf("entryPoint %q doesn't exist", entryPointName)
+				continue
+			}
+
+			if _, ok := entryPointsRouters[entryPointName]; !ok {
+				entryPointsRouters[entryPointName] = make(map[string]*config.TCPRouter)
+			}
+
+			entryPointsRouters[entryPointName][rtName] = rt
+		}
+	}
+
+	return entryPointsRouters
+}
+
+// parseHostSNI returns the server names of a HostSNI:foo.com,bar.com rule.
+func parseHostSNI(rule string) ([]string, error) {
+	parts := strings.SplitN(rule, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) != "HostSNI" {
+		return nil, fmt.Errorf("invalid TCP rule %q: only HostSNI is supported", rule)
+	}
+
+	var domains []string
+	for _, domain := range strings.Split(parts[1], ",") {
+		domain = strings.TrimSpace(domain)
+		if len(domain) > 0 {
+			domains = append(domains, strings.ToLower(domain))
+		}
+	}
+
+	if len(domains) == 0 {
+		return nil, errors.New("empty HostSNI rule")
+	}
+
+	return domains, nil
+}
+
+func contains(entryPoints []string, entryPointName string) bool {
+	for _, name := range entryPoints {
+		if name == entryPointName {
+			return true
+		}
+	}
+	return false
+}
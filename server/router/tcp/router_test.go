@@ -0,0 +1,144 @@
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	tcpservice "github.com/containous/traefik/server/service/tcp"
+	"github.com/containous/traefik/tcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostSNI(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		rule            string
+		expectedDomains []string
+		expectedErr     bool
+	}{
+		{
+			desc:            "one domain",
+			rule:            "HostSNI:foo.bar",
+			expectedDomains: []string{"foo.bar"},
+		},
+		{
+			desc:            "several domains",
+			rule:            "HostSNI: foo.bar, Bar.Foo",
+			expectedDomains: []string{"foo.bar", "bar.foo"},
+		},
+		{
+			desc:            "wildcard",
+			rule:            "HostSNI:*",
+			expectedDomains: []string{"*"},
+		},
+		{
+			desc:        "HTTP matcher",
+			rule:        "Host:foo.bar",
+			expectedErr: true,
+		},
+		{
+			desc:        "empty",
+			rule:        "HostSNI:",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			domains, err := parseHostSNI(test.rule)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedDomains, domains)
+		})
+	}
+}
+
+func TestManagerBuildHandlers(t *testing.T) {
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backendListener.Close()
+
+	routed := make(chan string, 1)
+	go func() {
+		conn, err := backendListener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Close()
+		routed <- "backend"
+	}()
+
+	routers := map[string]*config.TCPRouter{
+		"passthrough": {
+			EntryPoints: []string{"web"},
+			Service:     "foo",
+			Rule:        "HostSNI:foo.bar",
+			TLS:         &config.RouterTCPTLSConfig{Passthrough: true},
+		},
+		"terminating": {
+			EntryPoints: []string{"web"},
+			Service:     "foo",
+			Rule:        "HostSNI:bar.foo",
+			TLS:         &config.RouterTCPTLSConfig{},
+		},
+	}
+	services := map[string]*config.TCPService{
+		"foo": {
+			LoadBalancer: &config.TCPLoadBalancerService{
+				Servers: []config.TCPServer{{Address: backendListener.Addr().String()}},
+			},
+		},
+	}
+	httpForwarders := map[string]tcp.Handler{
+		"web": tcp.HandlerFunc(func(conn net.Conn) {
+			conn.Close()
+			routed <- "http"
+		}),
+	}
+
+	manager := NewManager(routers, tcpservice.NewManager(services), httpForwarders, nil)
+	handlers := manager.BuildHandlers(context.Background(), []string{"web"})
+	require.Contains(t, handlers, "web")
+
+	testCases := []struct {
+		serverName string
+		expected   string
+	}{
+		{
+			serverName: "foo.bar",
+			expected:   "backend",
+		},
+		{
+			// The entry point has no TLS configuration to terminate TLS: the router is skipped.
+			serverName: "bar.foo",
+			expected:   "http",
+		},
+	}
+
+	for _, test := range testCases {
+		client, server := net.Pipe()
+		go handlers["web"].ServeTCP(server)
+		go func(serverName string) {
+			_ = tls.Client(client, &tls.Config{ServerName: serverName, InsecureSkipVerify: true}).Handshake()
+		}(test.serverName)
+
+		select {
+		case result := <-routed:
+			assert.Equal(t, test.expected, result, test.serverName)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for the connection to %s to be routed", test.serverName)
+		}
+		client.Close()
+	}
+}
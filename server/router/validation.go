@@ -0,0 +1,163 @@
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+	"github.com/containous/traefik/plugins"
+	"github.com/containous/traefik/responsemodifiers"
+	"github.com/containous/traefik/server/middleware"
+	"github.com/containous/traefik/server/service"
+	"github.com/containous/traefik/slo"
+)
+
+// ValidationError describes an invalid element of a dynamic configuration.
+type ValidationError struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// ValidationResult is the result of the validation of a dynamic configuration.
+type ValidationResult struct {
+	Valid  bool              `json:"valid"`
+	Errors []ValidationError `json:"errors"`
+}
+
+// ValidateConfiguration builds all the elements of a dynamic configuration, without applying it,
+// and returns the errors found.
+// When entryPoints is not nil, the entry points used by the routers must be part of it.
+func ValidateConfiguration(ctx context.Context, conf *config.Configuration, entryPoints []string, plugins *plugins.Registry) ValidationResult {
+	result := ValidationResult{Errors: []ValidationError{}}
+
+	addError := func(kind, name string, err error) {
+		result.Errors = append(result.Errors, ValidationError{Kind: kind, Name: name, Message: err.Error()})
+	}
+
+	serviceManager := service.NewManager(conf.Services, http.DefaultTransport, nil, nil, nil)
+	middlewaresBuilder := middleware.NewBuilder(conf.Middlewares, serviceManager, plugins, nil)
+	responseModifierFactory := responsemodifiers.NewBuilder(conf.Middlewares)
+	routerManager := NewManager(conf.Routers, serviceManager, middlewaresBuilder, responseModifierFactory, metrics.NewVoidRegistry(), nil, nil, slo.NewRegistry(nil, nil))
+
+	for name := range conf.Services {
+		if _, err := serviceManager.Build(ctx, name, nil); err != nil {
+			addError("service", name, err)
+		}
+	}
+
+	for name := range conf.Middlewares {
+		chain, err := middlewaresBuilder.BuildChain(ctx, []string{name})
+		if err == nil {
+			_, err = chain.Then(http.NotFoundHandler())
+		}
+		if err != nil {
+			addError("middleware", name, err)
+		}
+	}
+
+	for name, router := range conf.Routers {
+		if err := validateRule(router.Rule); err != nil {
+			addError("router", name, err)
+		}
+
+		if entryPoints != nil {
+			for _, entryPointName := range router.EntryPoints {
+				if !contains(entryPoints, entryPointName) {
+					addError("router", name, fmt.Errorf("entryPoint %q doesn't exist", entryPointName))
+				}
+			}
+		}
+
+		if _, err := routerManager.buildHandler(ctx, router, name); err != nil {
+			addError("router", name, err)
+		}
+
+		if _, err := routerManager.slos.Wrap(name, router.SLO, http.NotFoundHandler()); err != nil {
+			addError("router", name, fmt.Errorf("error creating the latency objective: %v", err))
+		}
+	}
+
+	for i, tlsConfiguration := range conf.TLS {
+		name := fmt.Sprintf("certificate #%d", i)
+		if tlsConfiguration.Certificate == nil {
+			addError("tls", name, fmt.Errorf("no certificate defined"))
+			continue
+		}
+
+		certs := make(map[string]map[string]*tls.Certificate)
+		if err := tlsConfiguration.Certificate.AppendCertificates(certs, "validation"); err != nil {
+			addError("tls", name, err)
+		}
+	}
+
+	sort.Slice(result.Errors, func(i, j int) bool {
+		if result.Errors[i].Kind != result.Errors[j].Kind {
+			return result.Errors[i].Kind < result.Errors[j].Kind
+		}
+		return result.Errors[i].Name < result.Errors[j].Name
+	})
+
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// validateRule checks that a rule can be parsed, and that its matchers can be built.
+func validateRule(rule string) error {
+	alternatives, err := parseRule(rule)
+	if err != nil {
+		return err
+	}
+
+	for _, alt := range alternatives {
+		route := mux.NewRouter().NewRoute()
+		for _, matcher := range alt.matchers {
+			if err := matcher(route); err != nil {
+				return err
+			}
+			if route.GetError() != nil {
+				return route.GetError()
+			}
+		}
+	}
+
+	return nil
+}
+
+// validationHandler exposes the dry-run validation of dynamic configurations.
+type validationHandler struct {
+	entryPoints []string
+	plugins     *plugins.Registry
+}
+
+// Append adds the validation route on a router.
+func (h validationHandler) Append(router *mux.Router) {
+	router.Methods(http.MethodPost).Path("/api/validate").
+		HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			conf := &config.Configuration{}
+			if err := json.NewDecoder(req.Body).Decode(conf); err != nil {
+				http.Error(rw, fmt.Sprintf("invalid configuration: %v", err), http.StatusBadRequest)
+				return
+			}
+
+			result := ValidateConfiguration(req.Context(), conf, h.entryPoints, h.plugins)
+
+			statusCode := http.StatusOK
+			if !result.Valid {
+				statusCode = http.StatusUnprocessableEntity
+			}
+
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(statusCode)
+			if err := json.NewEncoder(rw).Encode(result); err != nil {
+				log.FromContext(req.Context()).Error(err)
+			}
+		})
+}
@@ -0,0 +1,160 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/mux"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfiguration(t *testing.T) {
+	service := &config.Service{
+		LoadBalancer: &config.LoadBalancerService{
+			Servers: []config.Server{{URL: "http://127.0.0.1:8080", Weight: 1}},
+			Method:  "wrr",
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		conf     *config.Configuration
+		expected []ValidationError
+	}{
+		{
+			desc: "valid configuration",
+			conf: &config.Configuration{
+				Routers: map[string]*config.Router{
+					"foo": {EntryPoints: []string{"web"}, Service: "foo-service", Rule: "Host:foo.bar", Middlewares: []string{"prefix"}},
+				},
+				Middlewares: map[string]*config.Middleware{
+					"prefix": {AddPrefix: &config.AddPrefix{Prefix: "/foo"}},
+				},
+				Services: map[string]*config.Service{"foo-service": service},
+			},
+			expected: []ValidationError{},
+		},
+		{
+			desc: "unknown matcher",
+			conf: &config.Configuration{
+				Routers: map[string]*config.Router{
+					"foo": {EntryPoints: []string{"web"}, Service: "foo-service", Rule: "Foo:bar"},
+				},
+				Services: map[string]*config.Service{"foo-service": service},
+			},
+			expected: []ValidationError{{Kind: "router", Name: "foo", Message: "invalid matcher: Foo:bar"}},
+		},
+		{
+			desc: "missing service",
+			conf: &config.Configuration{
+				Routers: map[string]*config.Router{
+					"foo": {EntryPoints: []string{"web"}, Service: "bar-service", Rule: "Host:foo.bar"},
+				},
+			},
+			expected: []ValidationError{{Kind: "router", Name: "foo"}},
+		},
+		{
+			desc: "missing middleware",
+			conf: &config.Configuration{
+				Routers: map[string]*config.Router{
+					"foo": {EntryPoints: []string{"web"}, Service: "foo-service", Rule: "Host:foo.bar", Middlewares: []string{"unknown"}},
+				},
+				Services: map[string]*config.Service{"foo-service": service},
+			},
+			expected: []ValidationError{{Kind: "router", Name: "foo"}},
+		},
+		{
+			desc: "unknown entry point",
+			conf: &config.Configuration{
+				Routers: map[string]*config.Router{
+					"foo": {EntryPoints: []string{"websecure"}, Service: "foo-service", Rule: "Host:foo.bar"},
+				},
+				Services: map[string]*config.Service{"foo-service": service},
+			},
+			expected: []ValidationError{{Kind: "router", Name: "foo", Message: `entryPoint "websecure" doesn't exist`}},
+		},
+		{
+			desc: "invalid certificate",
+			conf: &config.Configuration{
+				TLS: []*tls.Configuration{
+					{Certificate: &tls.Certificate{CertFile: tls.FileOrContent("not a certificate"), KeyFile: tls.FileOrContent("not a key")}},
+				},
+			},
+			expected: []ValidationError{{Kind: "tls", Name: "certificate #0"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			result := ValidateConfiguration(context.Background(), test.conf, []string{"web"}, nil)
+
+			assert.Equal(t, len(test.expected) == 0, result.Valid)
+			require.Len(t, result.Errors, len(test.expected))
+			for i, expected := range test.expected {
+				assert.Equal(t, expected.Kind, result.Errors[i].Kind)
+				assert.Equal(t, expected.Name, result.Errors[i].Name)
+				assert.NotEmpty(t, result.Errors[i].Message)
+				if len(expected.Message) > 0 {
+					assert.Equal(t, expected.Message, result.Errors[i].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestValidationHandler(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		body               string
+		expectedStatusCode int
+	}{
+		{
+			desc:               "valid configuration",
+			body:               `{"Services":{"foo":{"LoadBalancer":{"Method":"wrr","Servers":[{"URL":"http://127.0.0.1","Weight":1}]}}}}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "invalid configuration",
+			body:               `{"Routers":{"foo":{"EntryPoints":["web"],"Service":"bar","Rule":"Host:foo.bar"}}}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			desc:               "malformed configuration",
+			body:               `{`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router := mux.NewRouter()
+			validationHandler{entryPoints: []string{"web"}}.Append(router)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/api/validate", strings.NewReader(test.body))
+			router.ServeHTTP(recorder, request)
+
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			if test.expectedStatusCode == http.StatusBadRequest {
+				return
+			}
+
+			result := ValidationResult{}
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+			assert.Equal(t, test.expectedStatusCode == http.StatusOK, result.Valid)
+		})
+	}
+}
@@ -3,27 +3,49 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/containous/traefik/audit"
+	"github.com/containous/traefik/bluegreen"
+	"github.com/containous/traefik/certmonitor"
 	"github.com/containous/traefik/cluster"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/history"
 	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/diagnostics"
+	"github.com/containous/traefik/drain"
+	"github.com/containous/traefik/events"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/containous/traefik/loadshedding"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/lru"
 	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/middlewares/accesslog"
 	"github.com/containous/traefik/middlewares/requestdecorator"
+	"github.com/containous/traefik/namespace"
+	"github.com/containous/traefik/notification"
+	"github.com/containous/traefik/ping"
+	"github.com/containous/traefik/plugins"
 	"github.com/containous/traefik/provider"
 	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/secrets"
 	"github.com/containous/traefik/server/middleware"
+	"github.com/containous/traefik/server/router"
+	"github.com/containous/traefik/slo"
+	"github.com/containous/traefik/tap"
 	"github.com/containous/traefik/tracing"
 	"github.com/containous/traefik/tracing/datadog"
 	"github.com/containous/traefik/tracing/jaeger"
+	"github.com/containous/traefik/tracing/opentelemetry"
 	"github.com/containous/traefik/tracing/zipkin"
 	"github.com/containous/traefik/types"
+	"github.com/containous/traefik/watchdog"
 )
 
 // Server is the reverse-proxy/load-balancer engine
@@ -35,6 +57,8 @@ type Server struct {
 	stopChan                   chan bool
 	currentConfigurations      safe.Safe
 	providerConfigUpdateMap    map[string]chan config.Message
+	reloadDebouncer            *reloadDebouncer
+	routerManager              *router.Manager
 	accessLoggerMiddleware     *accesslog.Handler
 	tracer                     *tracing.Tracing
 	routinesPool               *safe.Pool
@@ -45,8 +69,29 @@ type Server struct {
 	configurationListeners     []func(config.Configuration)
 	requestDecorator           *requestdecorator.RequestDecorator
 	providersThrottleDuration  time.Duration
+	readiness                  *ping.Readiness
+	configurationLoaded        int32
+	history                    *history.History
+	drains                     *drain.Registry
+	switches                   *bluegreen.Registry
+	plugins                    *plugins.Registry
+	taps                       *tap.Registry
+	globalLimits               *limits
+	shedder                    *loadshedding.Shedder
+	namespaces                 *namespace.Registry
+	notifier                   *notification.Notifier
+	auditLogger                *audit.Logger
+	slowLogger                 *slo.Logger
+	slos                       *slo.Registry
+	secrets                    *secrets.Store
+	certMonitor                *certmonitor.Monitor
+	profilesPusher             *diagnostics.Pusher
+	watchdog                   *watchdog.Watchdog
 }
 
+// readinessInterval is the interval between two updates of the readiness gauge.
+const readinessInterval = 5 * time.Second
+
 // RouteAppenderFactory the route appender factory interface
 type RouteAppenderFactory interface {
 	NewAppender(ctx context.Context, middlewaresBuilder *middleware.Builder, currentConfigurations *safe.Safe) types.RouteAppender
@@ -60,6 +105,8 @@ func setupTracing(conf *static.Tracing) tracing.TrackingBackend {
 		return conf.Zipkin
 	case datadog.Name:
 		return conf.DataDog
+	case opentelemetry.Name:
+		return conf.OpenTelemetry
 	default:
 		log.WithoutContext().Warnf("Could not initialize tracing: unknown tracer %q", conf.Backend)
 		return nil
@@ -83,6 +130,7 @@ func NewServer(staticConfiguration static.Configuration, provider provider.Provi
 
 	if staticConfiguration.Providers != nil {
 		server.providersThrottleDuration = time.Duration(staticConfiguration.Providers.ProvidersThrottleDuration)
+		server.reloadDebouncer = newReloadDebouncer(staticConfiguration.Providers.ReloadDebounce)
 	}
 
 	transport, err := createHTTPTransport(staticConfiguration.ServersTransport)
@@ -101,18 +149,103 @@ func NewServer(staticConfiguration static.Configuration, provider provider.Provi
 		server.tracer, err = tracing.NewTracing(staticConfiguration.Tracing.ServiceName, staticConfiguration.Tracing.SpanNameLimit, trackingBackend)
 		if err != nil {
 			log.WithoutContext().Warnf("Unable to create tracer: %v", err)
+		} else if len(staticConfiguration.Tracing.DebugHeader) > 0 {
+			if err := server.tracer.SetDebugHeader(staticConfiguration.Tracing.DebugHeader, staticConfiguration.Tracing.DebugTrustedIPs); err != nil {
+				log.WithoutContext().Errorf("Unable to set the tracing debug header: %v", err)
+			}
 		}
 	}
 
 	server.requestDecorator = requestdecorator.New(staticConfiguration.HostResolver)
 
+	server.readiness = ping.NewReadiness()
+	server.readiness.Add("configuration", server.checkConfigurationLoaded)
+	if staticConfiguration.Ping != nil {
+		staticConfiguration.Ping.SetReadiness(server.readiness)
+	}
+
+	server.plugins = plugins.Load(context.Background(), staticConfiguration.Plugins)
+
+	if staticConfiguration.API != nil {
+		server.history = history.New(staticConfiguration.API.HistorySize)
+		staticConfiguration.API.History = server.history
+		server.drains = drain.NewRegistry()
+		staticConfiguration.API.Drains = server.drains
+		server.switches = bluegreen.NewRegistry()
+		staticConfiguration.API.Switches = server.switches
+		server.taps = tap.NewRegistry()
+		staticConfiguration.API.Taps = server.taps
+		staticConfiguration.API.Plugins = server.plugins
+	}
+
 	server.metricsRegistry = registerMetricClients(staticConfiguration.Metrics)
 
+	server.setGlobalLimits(staticConfiguration.Limits)
+
+	lru.SetDefault(staticConfiguration.Caches, server.metricsRegistry.CacheEvictionsCounter())
+
+	server.watchdog = watchdog.New(staticConfiguration.Watchdog, server.metricsRegistry)
+
+	healthcheck.GetHealthCheck().Configure(staticConfiguration.HealthChecks)
+
+	shedder, err := loadshedding.New(staticConfiguration.LoadShedding)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to create the load shedder: %v", err)
+	}
+	server.shedder = shedder
+
+	namespaces, err := namespace.New(staticConfiguration.Namespaces)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to create the namespaces: %v", err)
+	}
+	server.namespaces = namespaces
+	if staticConfiguration.API != nil {
+		staticConfiguration.API.Namespaces = server.namespaces
+	}
+
+	server.secrets, err = secrets.New(staticConfiguration.Secrets)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to create the secret backends: %v", err)
+	}
+	secrets.SetDefault(server.secrets)
+
+	notifier, err := notification.New(events.Default(), staticConfiguration.Webhooks)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to create the webhooks: %v", err)
+	}
+	server.notifier = notifier
+
+	server.auditLogger, err = audit.Open(staticConfiguration.AuditLog)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to open the audit log: %v", err)
+	}
+	audit.SetDefault(server.auditLogger)
+
+	server.slowLogger, err = slo.Open(staticConfiguration.SlowLog)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to open the slow log: %v", err)
+	}
+	if staticConfiguration.Diagnostics != nil {
+		server.profilesPusher, err = diagnostics.NewPusher(staticConfiguration.Diagnostics.Push)
+		if err != nil {
+			log.WithoutContext().Errorf("Unable to create the push of the profiles: %v", err)
+		}
+	}
+
+	server.slos = slo.NewRegistry(server.slowLogger, server.metricsRegistry.RouterSLOBurnRateGauge())
+
+	server.certMonitor = certmonitor.New(staticConfiguration.CertificatesMonitor, server.servedCertificates, server.metricsRegistry.TLSCertsNotAfterGauge())
+	if staticConfiguration.API != nil {
+		staticConfiguration.API.Certificates = server.certMonitor
+	}
+
 	if staticConfiguration.AccessLog != nil {
 		var err error
 		server.accessLoggerMiddleware, err = accesslog.NewHandler(staticConfiguration.AccessLog)
 		if err != nil {
 			log.WithoutContext().Warnf("Unable to create access logger : %v", err)
+		} else if staticConfiguration.API != nil {
+			staticConfiguration.API.AccessLogs = server.accessLoggerMiddleware.Buffer()
 		}
 	}
 	return server
@@ -141,6 +274,30 @@ func (s *Server) Start(ctx context.Context) {
 	s.routinesPool.Go(func(stop chan bool) {
 		s.listenSignals(stop)
 	})
+	s.routinesPool.Go(func(stop chan bool) {
+		s.watchReadiness(stop)
+	})
+	s.routinesPool.Go(func(stop chan bool) {
+		s.watchLimits(stop)
+	})
+	s.routinesPool.Go(func(stop chan bool) {
+		s.shedder.Run(stop)
+	})
+	s.routinesPool.Go(func(stop chan bool) {
+		s.notifier.Run(stop)
+	})
+	s.routinesPool.Go(func(stop chan bool) {
+		s.secrets.Run(stop)
+	})
+	s.routinesPool.Go(func(stop chan bool) {
+		s.certMonitor.Run(stop)
+	})
+	s.routinesPool.Go(func(stop chan bool) {
+		s.profilesPusher.Run(stop)
+	})
+	s.routinesPool.Go(func(stop chan bool) {
+		s.watchdog.Run(stop)
+	})
 }
 
 // Wait blocks until server is shutted down.
@@ -195,6 +352,15 @@ func (s *Server) Close() {
 		}
 	}
 
+	audit.SetDefault(nil)
+	if err := s.auditLogger.Close(); err != nil {
+		log.WithoutContext().Errorf("Could not close the audit log file: %s", err)
+	}
+
+	if err := s.slowLogger.Close(); err != nil {
+		log.WithoutContext().Errorf("Could not close the slow log file: %s", err)
+	}
+
 	if s.tracer != nil {
 		s.tracer.Close()
 	}
@@ -245,6 +411,38 @@ func (s *Server) listenProviders(stop chan bool) {
 	}
 }
 
+// AddReadinessCheck adds a check which must pass for Traefik to be ready to serve traffic.
+func (s *Server) AddReadinessCheck(name string, check ping.Check) {
+	s.readiness.Add(name, check)
+}
+
+func (s *Server) checkConfigurationLoaded() error {
+	if atomic.LoadInt32(&s.configurationLoaded) == 0 {
+		return errors.New("no dynamic configuration loaded yet")
+	}
+	return nil
+}
+
+// watchReadiness periodically reports the readiness status as a metric.
+func (s *Server) watchReadiness(stop chan bool) {
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+
+	for {
+		ready := 0.0
+		if s.readiness.Status().Ready {
+			ready = 1
+		}
+		s.metricsRegistry.ReadyGauge().Set(ready)
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // AddListener adds a new listener function used when new configuration is provided
 func (s *Server) AddListener(listener func(config.Configuration)) {
 	if s.configurationListeners == nil {
@@ -263,10 +461,13 @@ func (s *Server) startProvider() {
 	currentProvider := s.provider
 
 	safe.Go(func() {
-		err := currentProvider.Provide(s.configurationChan, s.routinesPool)
-		if err != nil {
-			log.WithoutContext().Errorf("Error starting provider %T: %s", s.provider, err)
-		}
+		// The goroutines started by the providers are labeled as well.
+		watchdog.Do(context.Background(), watchdog.SubsystemProviders, func(context.Context) {
+			err := currentProvider.Provide(s.configurationChan, s.routinesPool)
+			if err != nil {
+				log.WithoutContext().Errorf("Error starting provider %T: %s", s.provider, err)
+			}
+		})
 	})
 }
 
@@ -277,15 +478,31 @@ func registerMetricClients(metricsConfig *types.Metrics) metrics.Registry {
 
 	var registries []metrics.Registry
 
-	if metricsConfig.Prometheus != nil {
+	prometheusConfig := metricsConfig.Prometheus
+	if prometheusConfig == nil && metricsConfig.Push != nil {
+		// The pushed metrics are the ones gathered by the Prometheus registry.
+		prometheusConfig = &types.Prometheus{}
+	}
+
+	if prometheusConfig != nil {
 		ctx := log.With(context.Background(), log.Str(log.MetricsProviderName, "prometheus"))
-		prometheusRegister := metrics.RegisterPrometheus(ctx, metricsConfig.Prometheus)
+		prometheusRegister := metrics.RegisterPrometheus(ctx, prometheusConfig)
 		if prometheusRegister != nil {
 			registries = append(registries, prometheusRegister)
 			log.FromContext(ctx).Debug("Configured Prometheus metrics")
 		}
 	}
 
+	if metricsConfig.Push != nil {
+		ctx := log.With(context.Background(), log.Str(log.MetricsProviderName, "push"))
+		if err := metrics.RegisterPush(ctx, metricsConfig.Push); err != nil {
+			log.FromContext(ctx).Errorf("Unable to push metrics: %v", err)
+		} else {
+			log.FromContext(ctx).Debugf("Configured metrics push: pushing to %s once every %s",
+				metricsConfig.Push.Address, metricsConfig.Push.PushInterval)
+		}
+	}
+
 	if metricsConfig.Datadog != nil {
 		ctx := log.With(context.Background(), log.Str(log.MetricsProviderName, "datadog"))
 		registries = append(registries, metrics.RegisterDatadog(ctx, metricsConfig.Datadog))
@@ -314,4 +531,5 @@ func stopMetricsClients() {
 	metrics.StopDatadog()
 	metrics.StopStatsd()
 	metrics.StopInfluxDB()
+	metrics.StopPush()
 }
@@ -7,28 +7,46 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/containous/alice"
 	"github.com/containous/mux"
+	"github.com/containous/traefik/audit"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/history"
+	"github.com/containous/traefik/events"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/middlewares/accesslog"
+	metricsmiddleware "github.com/containous/traefik/middlewares/metrics"
 	"github.com/containous/traefik/middlewares/requestdecorator"
 	"github.com/containous/traefik/middlewares/tracing"
 	"github.com/containous/traefik/responsemodifiers"
 	"github.com/containous/traefik/server/middleware"
 	"github.com/containous/traefik/server/router"
+	tcprouter "github.com/containous/traefik/server/router/tcp"
 	"github.com/containous/traefik/server/service"
+	tcpservice "github.com/containous/traefik/server/service/tcp"
+	"github.com/containous/traefik/tcp"
 	traefiktls "github.com/containous/traefik/tls"
 	"github.com/eapache/channels"
 	"github.com/sirupsen/logrus"
 )
 
+// slowApplyDuration is the duration of the application of the configurations above which it is logged as a warning.
+const slowApplyDuration = 10 * time.Second
+
 // loadConfiguration manages dynamically frontends, backends and TLS configurations
 func (s *Server) loadConfiguration(configMsg config.Message) {
-	logger := log.FromContext(log.With(context.Background(), log.Str(log.ProviderName, configMsg.ProviderName)))
+	s.loadConfigurations(configMsg)
+}
 
+// loadConfigurations applies the configurations of the providers together, in a single reload.
+// A configuration rejected by the namespaces is left out, the previous configuration of its provider is kept.
+func (s *Server) loadConfigurations(configMsgs ...config.Message) {
 	currentConfigurations := s.currentConfigurations.Get().(config.Configurations)
 
 	// Copy configurations to new map so we don't change current if LoadConfig fails
@@ -36,18 +54,107 @@ func (s *Server) loadConfiguration(configMsg config.Message) {
 	for k, v := range currentConfigurations {
 		newConfigurations[k] = v
 	}
-	newConfigurations[configMsg.ProviderName] = configMsg.Configuration
 
+	var applied []config.Message
+	for _, configMsg := range configMsgs {
+		logger := log.FromContext(log.With(context.Background(), log.Str(log.ProviderName, configMsg.ProviderName)))
+
+		previous, ok := newConfigurations[configMsg.ProviderName]
+		newConfigurations[configMsg.ProviderName] = configMsg.Configuration
+
+		if err := s.namespaces.Check(configMsg.ProviderName, newConfigurations); err != nil {
+			if ok {
+				newConfigurations[configMsg.ProviderName] = previous
+			} else {
+				delete(newConfigurations, configMsg.ProviderName)
+			}
+
+			logger.Errorf("Configuration rejected: %v", err)
+			events.Publish(events.ConfigurationRejected, map[string]string{"provider": configMsg.ProviderName, "reason": err.Error()})
+			audit.Record(audit.Entry{Category: audit.CategoryConfiguration, Action: "reject", Resource: configMsg.ProviderName, Details: map[string]string{"reason": err.Error()}})
+			continue
+		}
+
+		applied = append(applied, configMsg)
+	}
+
+	if len(applied) == 0 {
+		return
+	}
+
+	var providerNames []string
+	for _, configMsg := range applied {
+		providerNames = append(providerNames, configMsg.ProviderName)
+	}
+	logger := log.FromContext(log.With(context.Background(), log.Str(log.ProviderName, strings.Join(providerNames, ","))))
+
+	s.applyConfigurations(logger, newConfigurations)
+
+	for _, configMsg := range applied {
+		events.Publish(events.ConfigurationReloaded, map[string]string{"provider": configMsg.ProviderName})
+		audit.Record(audit.Entry{Category: audit.CategoryConfiguration, Action: "apply", Resource: configMsg.ProviderName})
+	}
+
+	if s.history != nil {
+		s.history.Add(strings.Join(providerNames, ","), newConfigurations)
+	}
+
+	for _, configMsg := range applied {
+		for _, listener := range s.configurationListeners {
+			listener(*configMsg.Configuration)
+		}
+	}
+
+	s.postLoadConfiguration()
+}
+
+// rollbackConfiguration applies again the configurations of a previous version.
+// The next configuration sent by a provider replaces its rolled back configuration.
+func (s *Server) rollbackConfiguration(version history.Version) {
+	logger := log.WithoutContext()
+
+	s.applyConfigurations(logger, version.Configurations)
+
+	newVersion := s.history.AddRollback(version.Version, version.Configurations)
+	logger.Warnf("Dynamic configuration rolled back to version %d (new version %d)", version.Version, newVersion)
+	events.Publish(events.ConfigurationReloaded, map[string]string{"rollback": strconv.FormatUint(version.Version, 10)})
+	audit.Record(audit.Entry{Category: audit.CategoryConfiguration, Action: "rollback", Details: map[string]string{"version": strconv.FormatUint(version.Version, 10), "newVersion": strconv.FormatUint(newVersion, 10)}})
+
+	for _, listener := range s.configurationListeners {
+		for _, configuration := range version.Configurations {
+			listener(*configuration)
+		}
+	}
+
+	s.postLoadConfiguration()
+}
+
+// applyConfigurations builds the handlers and certificates of the configurations, and updates the entry points with them.
+func (s *Server) applyConfigurations(logger log.Logger, newConfigurations config.Configurations) {
 	s.metricsRegistry.ConfigReloadsCounter().Add(1)
+	start := time.Now()
 
 	handlers, certificates := s.loadConfig(newConfigurations)
+	tcpHandlers := s.loadTCPConfig(newConfigurations)
 
 	s.metricsRegistry.LastConfigReloadSuccessGauge().Set(float64(time.Now().Unix()))
 
+	duration := time.Since(start)
+	s.metricsRegistry.ConfigReloadDurationHistogram().Observe(duration.Seconds())
+	if duration > slowApplyDuration {
+		logger.Warnf("Applying the configuration took %s, more than %s", duration, slowApplyDuration)
+	} else {
+		logger.Infof("Configuration applied in %s", duration)
+	}
+
 	for entryPointName, handler := range handlers {
 		s.entryPoints[entryPointName].httpRouter.UpdateHandler(handler)
 	}
 
+	for entryPointName, handler := range tcpHandlers {
+		s.entryPoints[entryPointName].tcpSwitcher.UpdateHandler(handler)
+	}
+
 	for entryPointName, entryPoint := range s.entryPoints {
 		eLogger := logger.WithField(log.EntryPointName, entryPointName)
 		if entryPoint.Certs == nil {
@@ -62,12 +169,27 @@ func (s *Server) loadConfiguration(configMsg config.Message) {
 	}
 
 	s.currentConfigurations.Set(newConfigurations)
+	atomic.StoreInt32(&s.configurationLoaded, 1)
+}
 
-	for _, listener := range s.configurationListeners {
-		listener(*configMsg.Configuration)
-	}
+// servedCertificates returns the certificates served by the TLS entry points, by entry point.
+func (s *Server) servedCertificates() map[string][]*tls.Certificate {
+	certificates := make(map[string][]*tls.Certificate)
+	for entryPointName, entryPoint := range s.entryPoints {
+		if entryPoint.Certs == nil {
+			continue
+		}
 
-	s.postLoadConfiguration()
+		if entryPoint.Certs.DefaultCertificate != nil {
+			certificates[entryPointName] = append(certificates[entryPointName], entryPoint.Certs.DefaultCertificate)
+		}
+
+		dynamicCerts, _ := entryPoint.Certs.DynamicCerts.Get().(map[string]*tls.Certificate)
+		for _, cert := range dynamicCerts {
+			certificates[entryPointName] = append(certificates[entryPointName], cert)
+		}
+	}
+	return certificates
 }
 
 // loadConfig returns a new gorilla.mux Route from the specified global configuration and the dynamic
@@ -107,19 +229,54 @@ func (s *Server) loadConfig(configurations config.Configurations) (map[string]ht
 	return handlers, entryPointsCertificates
 }
 
+// loadTCPConfig returns the TCP router of each entry point, from the TCP routers and services of the configurations.
+func (s *Server) loadTCPConfig(configurations config.Configurations) map[string]*tcp.Router {
+	ctx := context.TODO()
+
+	conf := config.Configuration{
+		TCPRouters:  make(map[string]*config.TCPRouter),
+		TCPServices: make(map[string]*config.TCPService),
+	}
+	for _, config := range configurations {
+		for key, value := range config.TCPServices {
+			conf.TCPServices[key] = value
+		}
+
+		for key, value := range config.TCPRouters {
+			conf.TCPRouters[key] = value
+		}
+	}
+
+	var entryPoints []string
+	httpForwarders := make(map[string]tcp.Handler)
+	tlsConfigs := make(map[string]*tls.Config)
+	for entryPointName, entryPoint := range s.entryPoints {
+		entryPoints = append(entryPoints, entryPointName)
+		httpForwarders[entryPointName] = entryPoint.httpForwarder
+		tlsConfigs[entryPointName] = entryPoint.TLSConfig()
+	}
+
+	serviceManager := tcpservice.NewManager(conf.TCPServices)
+	routerManager := tcprouter.NewManager(conf.TCPRouters, serviceManager, httpForwarders, tlsConfigs)
+
+	return routerManager.BuildHandlers(ctx, entryPoints)
+}
+
 func (s *Server) applyConfiguration(ctx context.Context, configuration config.Configuration) map[string]http.Handler {
 	var entryPoints []string
 	for entryPointName := range s.entryPoints {
 		entryPoints = append(entryPoints, entryPointName)
 	}
 
-	serviceManager := service.NewManager(configuration.Services, s.defaultRoundTripper)
-	middlewaresBuilder := middleware.NewBuilder(configuration.Middlewares, serviceManager)
+	serviceManager := service.NewManager(configuration.Services, s.defaultRoundTripper, s.drains, s.switches, s.metricsRegistry)
+	middlewaresBuilder := middleware.NewBuilder(configuration.Middlewares, serviceManager, s.plugins, s.metricsRegistry)
 	responseModifierFactory := responsemodifiers.NewBuilder(configuration.Middlewares)
 
-	routerManager := router.NewManager(configuration.Routers, serviceManager, middlewaresBuilder, responseModifierFactory)
+	routerManager := router.NewManager(configuration.Routers, serviceManager, middlewaresBuilder, responseModifierFactory, s.metricsRegistry, s.taps, s.shedder, s.slos)
+	routerManager.Reuse(s.routerManager)
 
 	handlers := routerManager.BuildHandlers(ctx, entryPoints)
+	s.routerManager = routerManager
 
 	routerHandlers := make(map[string]http.Handler)
 
@@ -150,6 +307,10 @@ func (s *Server) applyConfiguration(ctx context.Context, configuration config.Co
 			chain = chain.Append(accesslog.WrapHandler(s.accessLoggerMiddleware))
 		}
 
+		if s.metricsRegistry.IsEnabled() {
+			chain = chain.Append(metricsmiddleware.WrapEntryPointHandler(ctx, s.metricsRegistry, entryPointName))
+		}
+
 		if s.tracer != nil {
 			chain = chain.Append(tracing.WrapEntryPointHandler(ctx, s.tracer, entryPointName))
 		}
@@ -177,7 +338,7 @@ func (s *Server) preLoadConfiguration(configMsg config.Message) {
 		logger.Debugf("Configuration received from provider %s: %s", configMsg.ProviderName, string(jsonConf))
 	}
 
-	if configMsg.Configuration == nil || configMsg.Configuration.Routers == nil && configMsg.Configuration.Services == nil && configMsg.Configuration.Middlewares == nil && configMsg.Configuration.TLS == nil {
+	if configMsg.Configuration == nil || configMsg.Configuration.Routers == nil && configMsg.Configuration.Services == nil && configMsg.Configuration.Middlewares == nil && configMsg.Configuration.TCPRouters == nil && configMsg.Configuration.TCPServices == nil && configMsg.Configuration.TLS == nil {
 		logger.Infof("Skipping empty Configuration for provider %s", configMsg.ProviderName)
 		return
 	}
@@ -204,6 +365,18 @@ func (s *Server) defaultConfigurationValues(configuration *config.Configuration)
 }
 
 func (s *Server) listenConfigurations(stop chan bool) {
+	var rollbacks <-chan history.Version
+	if s.history != nil {
+		rollbacks = s.history.Rollbacks()
+	}
+
+	// The debounced reloads wait for the timer, stopped while no configuration is pending.
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+
 	for {
 		select {
 		case <-stop:
@@ -212,7 +385,32 @@ func (s *Server) listenConfigurations(stop chan bool) {
 			if !ok || configMsg.Configuration == nil {
 				return
 			}
-			s.loadConfiguration(configMsg)
+
+			if s.reloadDebouncer == nil {
+				s.loadConfiguration(configMsg)
+				continue
+			}
+
+			now := time.Now()
+			s.reloadDebouncer.add(configMsg, now)
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(s.reloadDebouncer.wait(now))
+		case <-timer.C:
+			configMsgs := s.reloadDebouncer.flush(time.Now())
+			s.loadConfigurations(configMsgs...)
+
+			var providerNames []string
+			for _, configMsg := range configMsgs {
+				providerNames = append(providerNames, configMsg.ProviderName)
+			}
+			events.Publish(events.ConfigurationSettled, map[string]string{"providers": strings.Join(providerNames, ",")})
+		case version := <-rollbacks:
+			s.rollbackConfiguration(version)
 		}
 	}
 }
@@ -250,11 +448,15 @@ func (s *Server) throttleProviderConfigReload(throttle time.Duration, publish ch
 }
 
 func (s *Server) postLoadConfiguration() {
-	// FIXME metrics
-	// if s.metricsRegistry.IsEnabled() {
-	// 	activeConfig := s.currentConfigurations.Get().(config.Configurations)
-	// 	metrics.OnConfigurationUpdate(activeConfig)
-	// }
+	if s.metricsRegistry.IsEnabled() {
+		activeConfig := s.currentConfigurations.Get().(config.Configurations)
+
+		var entryPoints []string
+		for entryPointName := range s.entryPoints {
+			entryPoints = append(entryPoints, entryPointName)
+		}
+		metrics.OnConfigurationUpdate(activeConfig, entryPoints)
+	}
 
 	// FIXME acme
 	// if s.staticConfiguration.ACME == nil || s.leadership == nil || !s.leadership.IsLeader() {
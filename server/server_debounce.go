@@ -0,0 +1,92 @@
+package server
+
+import (
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/static"
+)
+
+const (
+	defaultDebounceDelay    = 500 * time.Millisecond
+	defaultDebounceMaxDelay = 5 * time.Second
+)
+
+// reloadDebouncer holds the configurations sent by the providers until they are applied together.
+// The pending configurations are applied once no configuration has been received for the delay,
+// or once the oldest has waited for the maximum delay, but not before the minimum interval since the previous reload.
+type reloadDebouncer struct {
+	delay       time.Duration
+	maxDelay    time.Duration
+	minInterval time.Duration
+
+	// pending holds the last configuration of each provider, in the order of their first configuration.
+	pending   []config.Message
+	first     time.Time
+	last      time.Time
+	lastFlush time.Time
+}
+
+func newReloadDebouncer(conf *static.ReloadDebounce) *reloadDebouncer {
+	if conf == nil {
+		return nil
+	}
+
+	debouncer := &reloadDebouncer{
+		delay:       time.Duration(conf.Delay),
+		maxDelay:    time.Duration(conf.MaxDelay),
+		minInterval: time.Duration(conf.MinInterval),
+	}
+
+	if debouncer.delay <= 0 {
+		debouncer.delay = defaultDebounceDelay
+	}
+	if debouncer.maxDelay <= 0 {
+		debouncer.maxDelay = defaultDebounceMaxDelay
+	}
+	if debouncer.maxDelay < debouncer.delay {
+		debouncer.maxDelay = debouncer.delay
+	}
+
+	return debouncer
+}
+
+// add holds the configuration, replacing the pending configuration of its provider.
+func (d *reloadDebouncer) add(configMsg config.Message, now time.Time) {
+	if len(d.pending) == 0 {
+		d.first = now
+	}
+	d.last = now
+
+	for i, pending := range d.pending {
+		if pending.ProviderName == configMsg.ProviderName {
+			d.pending[i] = configMsg
+			return
+		}
+	}
+	d.pending = append(d.pending, configMsg)
+}
+
+// wait returns the duration before the pending configurations are due.
+func (d *reloadDebouncer) wait(now time.Time) time.Duration {
+	due := d.last.Add(d.delay)
+	if deadline := d.first.Add(d.maxDelay); due.After(deadline) {
+		due = deadline
+	}
+	if earliest := d.lastFlush.Add(d.minInterval); due.Before(earliest) {
+		due = earliest
+	}
+
+	if due.Before(now) {
+		return 0
+	}
+	return due.Sub(now)
+}
+
+// flush returns the pending configurations, which are then applied.
+func (d *reloadDebouncer) flush(now time.Time) []config.Message {
+	pending := d.pending
+	d.pending = nil
+	d.lastFlush = now
+	return pending
+}
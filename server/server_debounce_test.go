@@ -0,0 +1,94 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadDebouncer(t *testing.T) {
+	debouncer := newReloadDebouncer(&static.ReloadDebounce{
+		Delay:       parse.Duration(100 * time.Millisecond),
+		MaxDelay:    parse.Duration(250 * time.Millisecond),
+		MinInterval: parse.Duration(time.Second),
+	})
+
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	debouncer.add(config.Message{ProviderName: "file"}, at(0))
+	assert.Equal(t, 100*time.Millisecond, debouncer.wait(at(0)))
+
+	// Each configuration postpones the reload, up to the maximum delay.
+	debouncer.add(config.Message{ProviderName: "docker"}, at(80))
+	assert.Equal(t, 100*time.Millisecond, debouncer.wait(at(80)))
+
+	debouncer.add(config.Message{ProviderName: "file", Configuration: &config.Configuration{}}, at(200))
+	assert.Equal(t, 50*time.Millisecond, debouncer.wait(at(200)))
+
+	pending := debouncer.flush(at(250))
+	require.Len(t, pending, 2)
+	assert.Equal(t, "file", pending[0].ProviderName)
+	assert.NotNil(t, pending[0].Configuration, "the last configuration of a provider is kept")
+	assert.Equal(t, "docker", pending[1].ProviderName)
+
+	// The next reload waits for the minimum interval.
+	debouncer.add(config.Message{ProviderName: "file"}, at(300))
+	assert.Equal(t, 950*time.Millisecond, debouncer.wait(at(300)))
+}
+
+func TestNewReloadDebouncerDefaults(t *testing.T) {
+	assert.Nil(t, newReloadDebouncer(nil))
+
+	debouncer := newReloadDebouncer(&static.ReloadDebounce{})
+	assert.Equal(t, defaultDebounceDelay, debouncer.delay)
+	assert.Equal(t, defaultDebounceMaxDelay, debouncer.maxDelay)
+	assert.Zero(t, debouncer.minInterval)
+}
+
+func TestListenConfigurationsDebounced(t *testing.T) {
+	staticConfiguration := static.Configuration{
+		Providers: &static.Providers{
+			ReloadDebounce: &static.ReloadDebounce{Delay: parse.Duration(50 * time.Millisecond)},
+		},
+	}
+	server := NewServer(staticConfiguration, nil, nil)
+
+	subscription, unsubscribe := events.Default().Subscribe(10)
+	defer unsubscribe()
+
+	stop := make(chan bool)
+	defer close(stop)
+	go server.listenConfigurations(stop)
+
+	for _, providerName := range []string{"file", "docker", "file"} {
+		server.configurationValidatedChan <- config.Message{ProviderName: providerName, Configuration: &config.Configuration{}}
+	}
+
+	settled := false
+	timeout := time.After(5 * time.Second)
+	var reloaded []string
+	for !settled {
+		select {
+		case event := <-subscription:
+			switch event.Type {
+			case events.ConfigurationReloaded:
+				reloaded = append(reloaded, event.Attributes["provider"])
+				assert.Len(t, server.currentConfigurations.Get().(config.Configurations), 2, "the configurations are applied together")
+			case events.ConfigurationSettled:
+				assert.Equal(t, "file,docker", event.Attributes["providers"])
+				settled = true
+			}
+		case <-timeout:
+			t.Fatal("the configurations were not applied")
+		}
+	}
+
+	assert.Equal(t, []string{"file", "docker"}, reloaded)
+}
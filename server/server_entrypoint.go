@@ -4,20 +4,28 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	stdlog "log"
 	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/armon/go-proxyproto"
 	"github.com/containous/traefik/config/static"
 	"github.com/containous/traefik/h2c"
 	"github.com/containous/traefik/ip"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/middlewares"
+	"github.com/containous/traefik/middlewares/forwardedheaders"
+	"github.com/containous/traefik/middlewares/servertiming"
 	"github.com/containous/traefik/old/configuration"
+	"github.com/containous/traefik/proxyprotocol"
+	"github.com/containous/traefik/tcp"
 	traefiktls "github.com/containous/traefik/tls"
 	"github.com/containous/traefik/tls/generate"
 	"github.com/containous/traefik/types"
@@ -25,18 +33,32 @@ import (
 	"github.com/xenolf/lego/acme"
 )
 
+// unixSocketPrefix starts the addresses of the entry points listening on a Unix socket.
+const unixSocketPrefix = "unix://"
+
 // EntryPoints map of EntryPoint
 type EntryPoints map[string]*EntryPoint
 
 // NewEntryPoint creates a new EntryPoint
-func NewEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*EntryPoint, error) {
+// It uses the socket passed by systemd socket activation under the name of the entry point, if any, instead of listening on its address.
+func NewEntryPoint(ctx context.Context, name string, configuration *static.EntryPoint) (*EntryPoint, error) {
 	logger := log.FromContext(ctx)
 	var err error
 
 	router := middlewares.NewHandlerSwitcher(buildDefaultHTTPRouter())
 	tracker := newHijackConnectionTracker()
 
-	listener, err := buildListener(ctx, configuration)
+	handler, err := buildForwardedHeadersHandler(configuration, stripURLUser(router))
+	if err != nil {
+		return nil, fmt.Errorf("error creating forwarded headers handler: %v", err)
+	}
+
+	entryPointLimits, err := newLimits(configuration.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("error creating limits: %v", err)
+	}
+
+	listener, err := buildListener(ctx, name, configuration)
 	if err != nil {
 		logger.Fatalf("Error preparing server: %v", err)
 	}
@@ -55,15 +77,29 @@ func NewEntryPoint(ctx context.Context, configuration *static.EntryPoint) (*Entr
 		}
 	}
 
+	httpForwarder := tcp.NewHTTPForwarder(listener)
+
+	tcpRouter := tcp.NewRouter()
+	tcpRouter.HTTPForwarder(httpForwarder)
+
 	entryPoint := &EntryPoint{
 		httpRouter:              router,
+		tcpSwitcher:             tcp.NewHandlerSwitcher(tcpRouter),
 		transportConfiguration:  configuration.Transport,
 		hijackConnectionTracker: tracker,
 		listener:                listener,
-		httpServer:              buildServer(ctx, configuration, tlsConfig, router, tracker),
+		httpForwarder:           httpForwarder,
 		Certs:                   certificateStore,
+		limits:                  entryPointLimits,
+	}
+	httpHandler := entryPoint.limitRequests(handler)
+	if configuration.ServerTiming {
+		// The timing starts before the request limits, to measure the time spent in their queue.
+		httpHandler = servertiming.Start(entryPoint.limitRequests(servertiming.Dequeue(handler)))
 	}
 
+	entryPoint.httpServer = buildServer(ctx, configuration, tlsConfig, httpHandler, tracker)
+
 	if tlsConfig != nil {
 		tlsConfig.GetCertificate = entryPoint.getCertificate
 	}
@@ -77,30 +113,107 @@ type EntryPoint struct {
 	httpServer              *h2c.Server
 	listener                net.Listener
 	httpRouter              *middlewares.HandlerSwitcher
+	httpForwarder           *tcp.HTTPForwarder
+	tcpSwitcher             *tcp.HandlerSwitcher
 	Certs                   *traefiktls.CertificateStore
 	OnDemandListener        func(string) (*tls.Certificate, error)
 	TLSALPNGetter           func(string) (*tls.Certificate, error)
 	hijackConnectionTracker *hijackConnectionTracker
 	transportConfiguration  *static.EntryPointsTransport
+	limits                  *limits
+	globalLimits            *limits
 }
 
-// Start starts listening for traffic
+// Start starts listening for traffic.
+// The connections are routed by the TCP router of the entry point,
+// which hands the ones matching no TCP router to the HTTP server.
+// With SO_REUSEPORT listeners, each of them has its own accept loop.
 func (s *EntryPoint) Start(ctx context.Context) {
+	log.FromContext(ctx).Infof("Starting server on %s", s.httpServer.Addr)
+
+	go s.startHTTPServer(ctx)
+
+	shards, ok := s.listener.(*listenerShards)
+	if !ok {
+		s.acceptConnections(ctx, s.listener)
+		s.httpForwarder.Close()
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, shard := range shards.shards {
+		wg.Add(1)
+		go func(shard net.Listener) {
+			defer wg.Done()
+			s.acceptConnections(ctx, shard)
+		}(shard)
+	}
+	wg.Wait()
+
+	s.httpForwarder.Close()
+}
+
+// acceptConnections serves the connections of the listener until it is closed.
+func (s *EntryPoint) acceptConnections(ctx context.Context, listener net.Listener) {
 	logger := log.FromContext(ctx)
-	logger.Infof("Starting server on %s", s.httpServer.Addr)
 
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				logger.Debugf("Temporary error while accepting a connection: %v", err)
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+
+			logger.Debugf("Stopping to accept connections: %v", err)
+			return
+		}
+
+		go s.serveTCP(conn)
+	}
+}
+
+// reportShards counts the connections accepted by the SO_REUSEPORT listeners of the entry point, if any.
+func (s *EntryPoint) reportShards(registry metrics.Registry, entryPointName string) {
+	if shards, ok := s.listener.(*listenerShards); ok {
+		shards.report(registry, entryPointName)
+	}
+}
+
+// serveTCP hands the connection to the TCP router, once it gets a slot within the connection limits.
+func (s *EntryPoint) serveTCP(conn net.Conn) {
+	if s.limits == nil && s.globalLimits == nil {
+		s.tcpSwitcher.ServeTCP(conn)
+		return
+	}
+
+	if !s.acquireConnection() {
+		_ = conn.Close()
+		return
+	}
+
+	s.tcpSwitcher.ServeTCP(&limitedConn{Conn: conn, release: s.releaseConnection})
+}
+
+func (s *EntryPoint) startHTTPServer(ctx context.Context) {
 	var err error
 	if s.httpServer.TLSConfig != nil {
-		err = s.httpServer.ServeTLS(s.listener, "", "")
+		err = s.httpServer.ServeTLS(s.httpForwarder, "", "")
 	} else {
-		err = s.httpServer.Serve(s.listener)
+		err = s.httpServer.Serve(s.httpForwarder)
 	}
 
 	if err != http.ErrServerClosed {
-		logger.Error("Cannot start server: %v", err)
+		log.FromContext(ctx).Errorf("Cannot start server: %v", err)
 	}
 }
 
+// TLSConfig returns the TLS configuration of the entry point, nil if it doesn't terminate TLS.
+func (s *EntryPoint) TLSConfig() *tls.Config {
+	return s.httpServer.TLSConfig
+}
+
 // Shutdown handles the entrypoint shutdown process
 func (s EntryPoint) Shutdown(ctx context.Context) {
 	logger := log.FromContext(ctx)
@@ -164,6 +277,10 @@ func (s *EntryPoint) getCertificate(clientHello *tls.ClientHelloInfo) (*tls.Cert
 		}
 	}
 
+	if s.Certs.SniStrict && len(domainToCheck) == 0 {
+		return nil, errors.New("strict SNI enabled - No server name provided, closing connection")
+	}
+
 	bestCertificate := s.Certs.GetBestCertificate(clientHello)
 	if bestCertificate != nil {
 		return bestCertificate, nil
@@ -179,7 +296,7 @@ func (s *EntryPoint) getCertificate(clientHello *tls.ClientHelloInfo) (*tls.Cert
 	}
 
 	log.WithoutContext().Debugf("Serving default certificate for request: %q", domainToCheck)
-	return s.Certs.DefaultCertificate, nil
+	return s.Certs.GetDefaultCertificate(), nil
 }
 
 func newHijackConnectionTracker() *hijackConnectionTracker {
@@ -259,7 +376,7 @@ func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
 }
 
 func buildProxyProtocolListener(ctx context.Context, entryPoint *static.EntryPoint, listener net.Listener) (net.Listener, error) {
-	var sourceCheck func(addr net.Addr) (bool, error)
+	var sourceCheck proxyprotocol.SourceChecker
 	if entryPoint.ProxyProtocol.Insecure {
 		sourceCheck = func(_ net.Addr) (bool, error) {
 			return true, nil
@@ -282,12 +399,30 @@ func buildProxyProtocolListener(ctx context.Context, entryPoint *static.EntryPoi
 
 	log.FromContext(ctx).Infof("Enabling ProxyProtocol for trusted IPs %v", entryPoint.ProxyProtocol.TrustedIPs)
 
-	return &proxyproto.Listener{
+	return &proxyprotocol.Listener{
 		Listener:    listener,
 		SourceCheck: sourceCheck,
 	}, nil
 }
 
+// buildForwardedHeadersHandler filters the X-Forwarded-* headers of the untrusted peers of the entry point.
+// Without configuration, no peer is trusted.
+func buildForwardedHeadersHandler(entryPoint *static.EntryPoint, next http.Handler) (http.Handler, error) {
+	if entryPoint.ForwardedHeaders == nil {
+		return forwardedheaders.NewXForwarded(false, nil, next)
+	}
+	return forwardedheaders.NewXForwarded(entryPoint.ForwardedHeaders.Insecure, entryPoint.ForwardedHeaders.TrustedIPs, next)
+}
+
+// stripURLUser removes the user info of an absolute-form request URI, which is chosen by the client:
+// only the auth middlewares set the user of a request.
+func stripURLUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		req.URL.User = nil
+		next.ServeHTTP(rw, req)
+	})
+}
+
 func buildServerTimeouts(entryPointsTransport static.EntryPointsTransport) (readTimeout, writeTimeout, idleTimeout time.Duration) {
 	readTimeout = time.Duration(0)
 	writeTimeout = time.Duration(0)
@@ -305,14 +440,31 @@ func buildServerTimeouts(entryPointsTransport static.EntryPointsTransport) (read
 	return readTimeout, writeTimeout, idleTimeout
 }
 
-func buildListener(ctx context.Context, entryPoint *static.EntryPoint) (net.Listener, error) {
-	listener, err := net.Listen("tcp", entryPoint.Address)
-	if err != nil {
-		return nil, fmt.Errorf("error opening listener: %v", err)
+func buildListener(ctx context.Context, name string, entryPoint *static.EntryPoint) (net.Listener, error) {
+	var listener net.Listener
+	var err error
+	if activated := getActivatedListener(name); activated != nil {
+		log.FromContext(ctx).Infof("Using the socket %s passed by systemd", activated.Addr())
+		listener = activated
+		if tcpListener, ok := activated.(*net.TCPListener); ok {
+			listener = tcpKeepAliveListener{tcpListener}
+		}
+	} else if strings.HasPrefix(entryPoint.Address, unixSocketPrefix) {
+		listener, err = buildUnixListener(strings.TrimPrefix(entryPoint.Address, unixSocketPrefix), entryPoint.SocketMode)
+		if err != nil {
+			return nil, fmt.Errorf("error opening listener: %v", err)
+		}
+	} else if entryPoint.ReusePort != nil {
+		// The shards handle the proxy protocol themselves.
+		return buildListenerShards(ctx, entryPoint)
+	} else {
+		listener, err = net.Listen("tcp", entryPoint.Address)
+		if err != nil {
+			return nil, fmt.Errorf("error opening listener: %v", err)
+		}
+		listener = tcpKeepAliveListener{listener.(*net.TCPListener)}
 	}
 
-	listener = tcpKeepAliveListener{listener.(*net.TCPListener)}
-
 	if entryPoint.ProxyProtocol != nil {
 		listener, err = buildProxyProtocolListener(ctx, entryPoint, listener)
 		if err != nil {
@@ -322,11 +474,48 @@ func buildListener(ctx context.Context, entryPoint *static.EntryPoint) (net.List
 	return listener, nil
 }
 
+// buildUnixListener listens on the Unix socket, replacing the socket left by a previous process,
+// and sets its permissions to the octal mode when not empty.
+func buildUnixListener(path string, mode string) (net.Listener, error) {
+	var perm os.FileMode
+	if len(mode) > 0 {
+		value, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid socket mode %q: %v", mode, err)
+		}
+		perm = os.FileMode(value)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if perm != 0 {
+		if err := os.Chmod(path, perm); err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+	}
+
+	return listener, nil
+}
+
 func buildCertificateStore(tlsOption traefiktls.TLS) (*traefiktls.CertificateStore, error) {
 	certificateStore := traefiktls.NewCertificateStore()
 	certificateStore.DynamicCerts.Set(make(map[string]*tls.Certificate))
 
 	certificateStore.SniStrict = tlsOption.SniStrict
+	certificateStore.DefaultDomain = tlsOption.DefaultDomain
 
 	if tlsOption.DefaultCertificate != nil {
 		cert, err := buildDefaultCertificate(tlsOption.DefaultCertificate)
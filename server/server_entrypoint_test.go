@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildUnixListener(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-unix")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	socket := filepath.Join(dir, "traefik.sock")
+
+	// A socket left by a previous process is replaced.
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	listener, err := buildListener(context.Background(), "local", &static.EntryPoint{Address: "unix://" + socket, SocketMode: "0660"})
+	require.NoError(t, err)
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			_, _ = conn.Write([]byte("ok"))
+			_ = conn.Close()
+		}
+	}()
+
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(content))
+	require.NoError(t, conn.Close())
+	require.NoError(t, listener.Close())
+
+	// The files which are not sockets are kept.
+	file := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(file, []byte("foo"), 0600))
+	_, err = buildUnixListener(file, "")
+	assert.Error(t, err)
+
+	_, err = buildUnixListener(socket, "rw")
+	assert.Error(t, err)
+}
+
+func TestStripURLUser(t *testing.T) {
+	var user *url.Userinfo
+	server := httptest.NewServer(stripURLUser(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		user = req.URL.User
+	})))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = fmt.Fprint(conn, "GET http://admin@localhost/api/providers HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	require.NoError(t, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, user)
+}
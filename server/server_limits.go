@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+)
+
+const (
+	defaultQueueTimeout = time.Second
+	limitsInterval      = 10 * time.Second
+
+	// globalLimitsName is the entry point label of the metrics of the limits shared by all the entry points.
+	globalLimitsName = "*"
+)
+
+// concurrencyLimiter limits the number of concurrent users of a resource.
+// A nil concurrencyLimiter has no limit.
+type concurrencyLimiter struct {
+	slots        chan struct{}
+	queue        bool
+	queueTimeout time.Duration
+	rejected     int64
+}
+
+func newConcurrencyLimiter(max int, queue bool, queueTimeout time.Duration) *concurrencyLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &concurrencyLimiter{
+		slots:        make(chan struct{}, max),
+		queue:        queue,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire takes a slot, waiting for one in queue mode, and returns false if none is available.
+func (l *concurrencyLimiter) acquire(ctx context.Context) bool {
+	if l == nil {
+		return true
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.queue {
+		timer := time.NewTimer(l.queueTimeout)
+		defer timer.Stop()
+
+		select {
+		case l.slots <- struct{}{}:
+			return true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	atomic.AddInt64(&l.rejected, 1)
+	return false
+}
+
+func (l *concurrencyLimiter) release() {
+	if l != nil {
+		<-l.slots
+	}
+}
+
+// saturation returns the ratio of the slots in use.
+func (l *concurrencyLimiter) saturation() float64 {
+	return float64(len(l.slots)) / float64(cap(l.slots))
+}
+
+// limits holds the connections and requests limiters of an entry point, or of all of them.
+// A nil limits has no limit.
+type limits struct {
+	connections *concurrencyLimiter
+	requests    *concurrencyLimiter
+}
+
+func newLimits(config *static.Limits) (*limits, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	var queue bool
+	switch config.Overflow {
+	case static.OverflowReject, "":
+	case static.OverflowQueue:
+		queue = true
+	default:
+		return nil, fmt.Errorf("unknown overflow behavior %q", config.Overflow)
+	}
+
+	queueTimeout := time.Duration(config.QueueTimeout)
+	if queueTimeout <= 0 {
+		queueTimeout = defaultQueueTimeout
+	}
+
+	return &limits{
+		connections: newConcurrencyLimiter(config.MaxConnections, queue, queueTimeout),
+		requests:    newConcurrencyLimiter(config.MaxRequests, queue, queueTimeout),
+	}, nil
+}
+
+func (l *limits) connectionsLimiter() *concurrencyLimiter {
+	if l == nil {
+		return nil
+	}
+	return l.connections
+}
+
+func (l *limits) requestsLimiter() *concurrencyLimiter {
+	if l == nil {
+		return nil
+	}
+	return l.requests
+}
+
+// report sets the saturation metrics of the limits, and counts the connections and requests rejected since the last report.
+func (l *limits) report(registry metrics.Registry, entryPointName string) {
+	if l == nil {
+		return
+	}
+
+	for name, limiter := range map[string]*concurrencyLimiter{"connections": l.connections, "requests": l.requests} {
+		if limiter == nil {
+			continue
+		}
+
+		registry.EntrypointSaturationGauge().With("limit", name, "entrypoint", entryPointName).Set(limiter.saturation())
+		if rejected := atomic.SwapInt64(&limiter.rejected, 0); rejected > 0 {
+			registry.EntrypointLimitRejectsCounter().With("limit", name, "entrypoint", entryPointName).Add(float64(rejected))
+		}
+	}
+}
+
+// acquireConnection takes a connection slot of the entry point and of all the entry points.
+func (s *EntryPoint) acquireConnection() bool {
+	ctx := context.Background()
+
+	if !s.globalLimits.connectionsLimiter().acquire(ctx) {
+		return false
+	}
+	if !s.limits.connectionsLimiter().acquire(ctx) {
+		s.globalLimits.connectionsLimiter().release()
+		return false
+	}
+	return true
+}
+
+func (s *EntryPoint) releaseConnection() {
+	s.limits.connectionsLimiter().release()
+	s.globalLimits.connectionsLimiter().release()
+}
+
+// limitRequests returns a handler answering 503 to the requests over the request limits of the entry point,
+// or of all the entry points.
+func (s *EntryPoint) limitRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		global := s.globalLimits.requestsLimiter()
+		local := s.limits.requestsLimiter()
+
+		if !global.acquire(req.Context()) {
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		defer global.release()
+
+		if !local.acquire(req.Context()) {
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+		defer local.release()
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// limitedConn releases its connection slot when it is closed.
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
+
+// Unwrap returns the limited connection.
+func (c *limitedConn) Unwrap() net.Conn {
+	return c.Conn
+}
+
+// CloseWrite closes the write side of the connection when it is supported, and the whole connection otherwise.
+func (c *limitedConn) CloseWrite() error {
+	if conn, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return conn.CloseWrite()
+	}
+	return c.Close()
+}
+
+// watchLimits periodically reports the saturation of the limits, the connections accepted by the listener shards,
+// and the dropped access log lines, as metrics.
+func (s *Server) watchLimits(stop chan bool) {
+	ticker := time.NewTicker(limitsInterval)
+	defer ticker.Stop()
+
+	for {
+		s.globalLimits.report(s.metricsRegistry, globalLimitsName)
+		for entryPointName, entryPoint := range s.entryPoints {
+			entryPoint.limits.report(s.metricsRegistry, entryPointName)
+			entryPoint.reportShards(s.metricsRegistry, entryPointName)
+		}
+		if s.accessLoggerMiddleware != nil {
+			if dropped := s.accessLoggerMiddleware.TakeDropped(); dropped > 0 {
+				s.metricsRegistry.AccessLogDroppedCounter().Add(float64(dropped))
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) setGlobalLimits(config *static.Limits) {
+	globalLimits, err := newLimits(config)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to create the global limits: %v", err)
+		return
+	}
+
+	s.globalLimits = globalLimits
+	for _, entryPoint := range s.entryPoints {
+		entryPoint.globalLimits = globalLimits
+	}
+}
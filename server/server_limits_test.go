@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLimits(t *testing.T) {
+	l, err := newLimits(nil)
+	require.NoError(t, err)
+	assert.Nil(t, l)
+
+	l, err = newLimits(&static.Limits{MaxRequests: 2})
+	require.NoError(t, err)
+	assert.Nil(t, l.connections)
+	require.NotNil(t, l.requests)
+	assert.False(t, l.requests.queue)
+	assert.Equal(t, defaultQueueTimeout, l.requests.queueTimeout)
+
+	_, err = newLimits(&static.Limits{MaxRequests: 2, Overflow: "foo"})
+	assert.Error(t, err)
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		queue    bool
+		release  bool
+		expected bool
+	}{
+		{
+			desc: "reject",
+		},
+		{
+			desc:  "queue timeout",
+			queue: true,
+		},
+		{
+			desc:     "queue with a slot released",
+			queue:    true,
+			release:  true,
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			l := newConcurrencyLimiter(1, test.queue, 50*time.Millisecond)
+			require.True(t, l.acquire(context.Background()))
+			assert.Equal(t, float64(1), l.saturation())
+
+			if test.release {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					l.release()
+				}()
+			}
+
+			assert.Equal(t, test.expected, l.acquire(context.Background()))
+			if !test.expected {
+				assert.EqualValues(t, 1, l.rejected)
+			}
+		})
+	}
+}
+
+func TestLimitRequests(t *testing.T) {
+	entryPointLimits, err := newLimits(&static.Limits{MaxRequests: 1})
+	require.NoError(t, err)
+	globalLimits, err := newLimits(&static.Limits{MaxRequests: 2, Overflow: static.OverflowQueue, QueueTimeout: parse.Duration(time.Second)})
+	require.NoError(t, err)
+
+	entryPoint := &EntryPoint{limits: entryPointLimits, globalLimits: globalLimits}
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := entryPoint.limitRequests(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}))
+
+	done := make(chan int)
+	go func() {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		done <- recorder.Code
+	}()
+	<-entered
+
+	// The entry point limit is reached, while the global one is not.
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+
+	assert.Len(t, entryPointLimits.requests.slots, 0)
+	assert.Len(t, globalLimits.requests.slots, 0)
+	assert.EqualValues(t, 1, entryPointLimits.requests.rejected)
+}
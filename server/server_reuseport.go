@@ -0,0 +1,21 @@
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package server
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// reusePort lets several sockets listen on the same address, the kernel spreading the connections over them.
+func reusePort(_, _ string, c syscall.RawConn) error {
+	var err error
+	errControl := c.Control(func(fd uintptr) {
+		err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if errControl != nil {
+		return errControl
+	}
+	return err
+}
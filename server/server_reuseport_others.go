@@ -0,0 +1,12 @@
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package server
+
+import (
+	"errors"
+	"syscall"
+)
+
+func reusePort(_, _ string, _ syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on this platform")
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/containous/traefik/config/static"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+)
+
+var errShardsAccept = errors.New("the listener shards are accepted separately")
+
+// listenerShards groups the SO_REUSEPORT listeners of an entry point, the kernel spreading the connections over them.
+// Each shard has its own accept loop, and counts the connections it accepts.
+type listenerShards struct {
+	shards []*listenerShard
+}
+
+type listenerShard struct {
+	net.Listener
+	accepted int64
+}
+
+func (l *listenerShard) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		atomic.AddInt64(&l.accepted, 1)
+	}
+	return conn, err
+}
+
+// buildListenerShards opens the SO_REUSEPORT listeners of the entry point, GOMAXPROCS of them by default.
+func buildListenerShards(ctx context.Context, entryPoint *static.EntryPoint) (*listenerShards, error) {
+	count := entryPoint.ReusePort.Listeners
+	if count <= 0 {
+		count = runtime.GOMAXPROCS(0)
+	}
+
+	listenConfig := &net.ListenConfig{Control: reusePort}
+	address := entryPoint.Address
+
+	shards := &listenerShards{}
+	for i := 0; i < count; i++ {
+		listener, err := listenConfig.Listen(ctx, "tcp", address)
+		if err != nil {
+			_ = shards.Close()
+			return nil, fmt.Errorf("error opening listener: %v", err)
+		}
+		// The next shards listen on the port picked for the first one, if any.
+		address = listener.Addr().String()
+
+		var shard net.Listener = tcpKeepAliveListener{listener.(*net.TCPListener)}
+		if entryPoint.ProxyProtocol != nil {
+			shard, err = buildProxyProtocolListener(ctx, entryPoint, shard)
+			if err != nil {
+				_ = listener.Close()
+				_ = shards.Close()
+				return nil, fmt.Errorf("error creating proxy protocol listener: %v", err)
+			}
+		}
+
+		shards.shards = append(shards.shards, &listenerShard{Listener: shard})
+	}
+
+	log.FromContext(ctx).Infof("Listening on %s with %d SO_REUSEPORT listeners", address, count)
+
+	return shards, nil
+}
+
+// Accept is not supported, as each shard is accepted by its own loop.
+func (l *listenerShards) Accept() (net.Conn, error) {
+	return nil, errShardsAccept
+}
+
+// Close closes all the shards.
+func (l *listenerShards) Close() error {
+	var err error
+	for _, shard := range l.shards {
+		if errClose := shard.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}
+	return err
+}
+
+// Addr returns the address shared by the shards.
+func (l *listenerShards) Addr() net.Addr {
+	return l.shards[0].Addr()
+}
+
+// report counts the connections accepted by each shard since the last report.
+func (l *listenerShards) report(registry metrics.Registry, entryPointName string) {
+	for i, shard := range l.shards {
+		if accepted := atomic.SwapInt64(&shard.accepted, 0); accepted > 0 {
+			registry.EntrypointShardConnsCounter().With("shard", strconv.Itoa(i), "entrypoint", entryPointName).Add(float64(accepted))
+		}
+	}
+}
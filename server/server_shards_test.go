@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/containous/traefik/config/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildListenerShards(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SO_REUSEPORT is not supported on Windows")
+	}
+
+	testCases := []struct {
+		desc           string
+		listeners      int
+		expectedShards int
+	}{
+		{
+			desc:           "listeners",
+			listeners:      3,
+			expectedShards: 3,
+		},
+		{
+			desc:           "GOMAXPROCS listeners",
+			expectedShards: runtime.GOMAXPROCS(0),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			listener, err := buildListener(context.Background(), "shards", &static.EntryPoint{
+				Address:   "127.0.0.1:0",
+				ReusePort: &static.ReusePort{Listeners: test.listeners},
+			})
+			require.NoError(t, err)
+
+			shards, ok := listener.(*listenerShards)
+			require.True(t, ok)
+			require.Len(t, shards.shards, test.expectedShards)
+
+			for _, shard := range shards.shards {
+				assert.Equal(t, listener.Addr().String(), shard.Addr().String())
+			}
+
+			_, err = listener.Accept()
+			assert.Equal(t, errShardsAccept, err)
+
+			require.NoError(t, listener.Close())
+			for _, shard := range shards.shards {
+				_, err = shard.Accept()
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestListenerShardsAccept(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SO_REUSEPORT is not supported on Windows")
+	}
+
+	shards, err := buildListenerShards(context.Background(), &static.EntryPoint{
+		Address:   "127.0.0.1:0",
+		ReusePort: &static.ReusePort{Listeners: 2},
+	})
+	require.NoError(t, err)
+	defer shards.Close()
+
+	accepted := make(chan struct{})
+	for _, shard := range shards.shards {
+		go func(shard net.Listener) {
+			for {
+				conn, errAccept := shard.Accept()
+				if errAccept != nil {
+					return
+				}
+				_ = conn.Close()
+				accepted <- struct{}{}
+			}
+		}(shard)
+	}
+
+	const connections = 20
+	for i := 0; i < connections; i++ {
+		conn, errDial := net.Dial("tcp", shards.Addr().String())
+		require.NoError(t, errDial)
+		_ = conn.Close()
+	}
+
+	for i := 0; i < connections; i++ {
+		<-accepted
+	}
+
+	var total int64
+	for _, shard := range shards.shards {
+		total += atomic.LoadInt64(&shard.accepted)
+	}
+	assert.Equal(t, int64(connections), total)
+}
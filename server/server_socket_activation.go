@@ -0,0 +1,89 @@
+// +build !windows
+
+package server
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/containous/traefik/log"
+)
+
+var (
+	// listenFdsStart is the first file descriptor passed by systemd.
+	listenFdsStart = 3
+
+	activatedListenersOnce sync.Once
+	activatedListeners     map[string]net.Listener
+)
+
+// getActivatedListener returns the listener passed by systemd socket activation for the entry point, if any.
+// The sockets are matched with the entry points by their FileDescriptorName.
+func getActivatedListener(entryPointName string) net.Listener {
+	activatedListenersOnce.Do(func() {
+		activatedListeners = listenersFromEnv()
+	})
+
+	listener, ok := activatedListeners[entryPointName]
+	if !ok {
+		return nil
+	}
+
+	// A socket is only used by one entry point.
+	delete(activatedListeners, entryPointName)
+	return listener
+}
+
+// listenersFromEnv creates the listeners of the sockets passed by systemd, by their name,
+// as described by the LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES environment variables, which are unset.
+func listenersFromEnv() map[string]net.Listener {
+	defer func() {
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil
+	}
+
+	nfds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || nfds <= 0 {
+		return nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	listeners := make(map[string]net.Listener)
+	for i := 0; i < nfds; i++ {
+		fd := listenFdsStart + i
+		syscall.CloseOnExec(fd)
+
+		name := strconv.Itoa(fd)
+		if i < len(names) && len(names[i]) > 0 {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(fd), name)
+		listener, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			log.WithoutContext().Errorf("Unable to use the socket %s passed by systemd: %v", name, err)
+			continue
+		}
+
+		if _, ok := listeners[name]; ok {
+			log.WithoutContext().Errorf("Unable to use the socket %s passed by systemd: several sockets have this name", name)
+			_ = listener.Close()
+			continue
+		}
+		listeners[name] = listener
+	}
+
+	return listeners
+}
@@ -0,0 +1,50 @@
+// +build !windows
+
+package server
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenersFromEnv(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	file, err := listener.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	// The socket is passed as the first file descriptor.
+	fd, err := syscall.Dup(int(file.Fd()))
+	require.NoError(t, err)
+
+	defer func(start int) { listenFdsStart = start }(listenFdsStart)
+	listenFdsStart = fd
+
+	// The sockets passed to another process are ignored.
+	require.NoError(t, os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1)))
+	require.NoError(t, os.Setenv("LISTEN_FDS", "1"))
+	assert.Nil(t, listenersFromEnv())
+	assert.Empty(t, os.Getenv("LISTEN_FDS"))
+
+	require.NoError(t, os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid())))
+	require.NoError(t, os.Setenv("LISTEN_FDS", "1"))
+	require.NoError(t, os.Setenv("LISTEN_FDNAMES", "web"))
+
+	listeners := listenersFromEnv()
+	require.Len(t, listeners, 1)
+	require.NotNil(t, listeners["web"])
+	defer func() { _ = listeners["web"].Close() }()
+
+	assert.Equal(t, listener.Addr().String(), listeners["web"].Addr().String())
+	assert.Empty(t, os.Getenv("LISTEN_PID"))
+	assert.Empty(t, os.Getenv("LISTEN_FDNAMES"))
+}
@@ -0,0 +1,9 @@
+// +build windows
+
+package server
+
+import "net"
+
+func getActivatedListener(entryPointName string) net.Listener {
+	return nil
+}
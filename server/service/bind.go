@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+	"net"
+)
+
+// BindDialer binds the connections opened by the dialer to the local address and to the network interface, when set.
+func BindDialer(dialer *net.Dialer, localAddress, iface string) error {
+	if len(localAddress) > 0 {
+		ip := net.ParseIP(localAddress)
+		if ip == nil {
+			return fmt.Errorf("invalid local address %q", localAddress)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+
+	if len(iface) > 0 {
+		if _, err := net.InterfaceByName(iface); err != nil {
+			return fmt.Errorf("invalid interface %q: %v", iface, err)
+		}
+
+		control, err := bindToDevice(iface)
+		if err != nil {
+			return err
+		}
+		dialer.Control = control
+	}
+
+	return nil
+}
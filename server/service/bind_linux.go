@@ -0,0 +1,21 @@
+// +build linux
+
+package service
+
+import (
+	"syscall"
+)
+
+// bindToDevice binds the sockets to the network interface, so that their packets only go through it, whatever the routes.
+func bindToDevice(iface string) (func(network, address string, c syscall.RawConn) error, error) {
+	return func(_, _ string, c syscall.RawConn) error {
+		var err error
+		errControl := c.Control(func(fd uintptr) {
+			err = syscall.SetsockoptString(int(fd), syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, iface)
+		})
+		if errControl != nil {
+			return errControl
+		}
+		return err
+	}, nil
+}
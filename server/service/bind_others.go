@@ -0,0 +1,12 @@
+// +build !linux
+
+package service
+
+import (
+	"errors"
+	"syscall"
+)
+
+func bindToDevice(_ string) (func(network, address string, c syscall.RawConn) error, error) {
+	return nil, errors.New("binding the connections to an interface is only supported on Linux")
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindDialer(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		localAddress  string
+		iface         string
+		linuxOnly     bool
+		expectedLocal net.Addr
+		expectedErr   bool
+	}{
+		{
+			desc: "no binding",
+		},
+		{
+			desc:          "local address",
+			localAddress:  "127.0.0.1",
+			expectedLocal: &net.TCPAddr{IP: net.ParseIP("127.0.0.1")},
+		},
+		{
+			desc:         "invalid local address",
+			localAddress: "localhost",
+			expectedErr:  true,
+		},
+		{
+			desc:      "interface",
+			iface:     "lo",
+			linuxOnly: true,
+		},
+		{
+			desc:        "unknown interface",
+			iface:       "unknown0",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if test.linuxOnly && runtime.GOOS != "linux" {
+				t.Skip("binding the connections to an interface is only supported on Linux")
+			}
+
+			dialer := &net.Dialer{}
+			err := BindDialer(dialer, test.localAddress, test.iface)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedLocal, dialer.LocalAddr)
+			assert.Equal(t, len(test.iface) > 0, dialer.Control != nil)
+		})
+	}
+}
+
+func TestBuildRoundTripperLocalAddress(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only Linux routes the whole 127.0.0.0/8 range to the loopback interface")
+	}
+
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		host, _, _ := net.SplitHostPort(req.RemoteAddr)
+		_, _ = rw.Write([]byte(host))
+	}))
+	defer backend.Close()
+
+	manager := NewManager(nil, http.DefaultTransport, nil, nil, nil)
+	roundTripper, _, err := manager.buildRoundTripper("foo", &config.LoadBalancerService{
+		Dial: &config.Dial{LocalAddress: "127.0.0.2"},
+	})
+	require.NoError(t, err)
+
+	resp, err := roundTripper.RoundTrip(httptest.NewRequest(http.MethodGet, backend.URL, nil))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.2", string(body))
+
+	_, _, err = manager.buildRoundTripper("bar", &config.LoadBalancerService{
+		Dial: &config.Dial{LocalAddress: "invalid"},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlueGreenService(t *testing.T) {
+	blue := httptest.NewServer(handlerNamed("blue"))
+	defer blue.Close()
+
+	green := httptest.NewServer(handlerNamed("green"))
+	defer green.Close()
+
+	loadBalancer := func(url string) *config.Service {
+		return &config.Service{
+			LoadBalancer: &config.LoadBalancerService{
+				Method:  "wrr",
+				Servers: []config.Server{{URL: url, Weight: 1}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc          string
+		service       *config.BlueGreenService
+		expectedBody  string
+		expectedError string
+	}{
+		{
+			desc:         "blue by default",
+			service:      &config.BlueGreenService{Blue: "blue", Green: "green"},
+			expectedBody: "blue",
+		},
+		{
+			desc:         "green",
+			service:      &config.BlueGreenService{Blue: "blue", Green: "green", Live: "green"},
+			expectedBody: "green",
+		},
+		{
+			desc:          "invalid live color",
+			service:       &config.BlueGreenService{Blue: "blue", Green: "green", Live: "red"},
+			expectedError: `invalid live color "red" for the service "foo", expected blue or green`,
+		},
+		{
+			desc:          "missing green service",
+			service:       &config.BlueGreenService{Blue: "blue"},
+			expectedError: `error building the green service of the service "foo": no service defined`,
+		},
+		{
+			desc:          "reference loop",
+			service:       &config.BlueGreenService{Blue: "blue", Green: "foo"},
+			expectedError: `error building the green service of the service "foo": reference loop between the services "foo" and "foo"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			configs := map[string]*config.Service{
+				"foo":   {BlueGreen: test.service},
+				"blue":  loadBalancer(blue.URL),
+				"green": loadBalancer(green.URL),
+			}
+
+			manager := NewManager(configs, http.DefaultTransport, nil, nil, nil)
+
+			handler, err := manager.Build(context.Background(), "foo", nil)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -2,19 +2,23 @@ package service
 
 import "sync"
 
-const bufferPoolSize = 32 * 1024
+// defaultBufferSize is the size of the buffers copying the bodies of the responses, unless their service sets its own.
+const defaultBufferSize = 32 * 1024
 
-func newBufferPool() *bufferPool {
+func newBufferPool(size int) *bufferPool {
 	return &bufferPool{
+		size: size,
 		pool: sync.Pool{
 			New: func() interface{} {
-				return make([]byte, bufferPoolSize)
+				return make([]byte, size)
 			},
 		},
 	}
 }
 
+// bufferPool reuses the buffers copying the bodies of the responses, all of the same size.
 type bufferPool struct {
+	size int
 	pool sync.Pool
 }
 
@@ -22,6 +26,24 @@ func (b *bufferPool) Get() []byte {
 	return b.pool.Get().([]byte)
 }
 
+// Put keeps the buffer for a next copy, unless it is smaller than the size of the pool.
 func (b *bufferPool) Put(bytes []byte) {
-	b.pool.Put(bytes)
+	if cap(bytes) < b.size {
+		return
+	}
+	b.pool.Put(bytes[:b.size])
+}
+
+// getBufferPool returns the pool of the buffers of the size, shared by the services with the same buffer size.
+func (m *Manager) getBufferPool(size int) *bufferPool {
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+
+	pool, ok := m.bufferPools[size]
+	if !ok {
+		pool = newBufferPool(size)
+		m.bufferPools[size] = pool
+	}
+	return pool
 }
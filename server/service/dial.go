@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/containous/traefik/config"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+)
+
+// The address families of the servers.
+const (
+	addressFamilyIPv4       = "ipv4"
+	addressFamilyIPv6       = "ipv6"
+	addressFamilyPreferIPv6 = "preferIPv6"
+)
+
+// defaultFallbackDelay is the Connection Attempt Delay recommended by RFC 8305.
+const defaultFallbackDelay = 250 * time.Millisecond
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// hostLookupFunc returns the addresses of a hostname.
+type hostLookupFunc func(ctx context.Context, host string) ([]string, error)
+
+// serversDialer dials the addresses of the hostnames of the servers, following RFC 8305 (Happy Eyeballs):
+// the addresses of the preferred family and of the other one are dialed alternately,
+// the next one when the previous attempt fails or lasts fallbackDelay, and the first connection established wins.
+type serversDialer struct {
+	lookup        hostLookupFunc
+	dial          dialFunc
+	family        string
+	fallbackDelay time.Duration
+	// fallbacks counts the connections established with the family which isn't the preferred one, it can be nil.
+	fallbacks gokitmetrics.Counter
+}
+
+func newServersDialer(conf *config.Dial, lookup hostLookupFunc, dial dialFunc, fallbacks gokitmetrics.Counter) (*serversDialer, error) {
+	d := &serversDialer{
+		lookup:        lookup,
+		dial:          dial,
+		fallbackDelay: defaultFallbackDelay,
+		fallbacks:     fallbacks,
+	}
+
+	if conf == nil {
+		return d, nil
+	}
+
+	switch conf.AddressFamily {
+	case "", addressFamilyIPv4, addressFamilyIPv6, addressFamilyPreferIPv6:
+		d.family = conf.AddressFamily
+	default:
+		return nil, fmt.Errorf("invalid address family %q, expected %s, %s or %s", conf.AddressFamily, addressFamilyIPv4, addressFamilyIPv6, addressFamilyPreferIPv6)
+	}
+
+	if conf.FallbackDelay > 0 {
+		d.fallbackDelay = time.Duration(conf.FallbackDelay)
+	}
+
+	return d, nil
+}
+
+// DialContext dials the addresses of the hostname of the address.
+// The IP addresses are dialed as is.
+func (d *serversDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dial(ctx, network, address)
+	}
+
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs = sortAddresses(addrs, d.family)
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no %s address found for %s", d.family, host)
+	}
+
+	conn, addr, err := d.race(ctx, network, addrs, port)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.fallbacks != nil && addressFamily(addr) != addressFamily(addrs[0]) {
+		d.fallbacks.With("family", addressFamily(addr)).Add(1)
+	}
+
+	return conn, nil
+}
+
+type dialResult struct {
+	conn net.Conn
+	addr string
+	err  error
+}
+
+// race dials the addresses in turn, and returns the first connection established, along with its address.
+func (d *serversDialer) race(ctx context.Context, network string, addrs []string, port string) (net.Conn, string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, len(addrs))
+
+	var next, pending int
+	start := func() {
+		addr := addrs[next]
+		next++
+		pending++
+
+		go func() {
+			conn, err := d.dial(ctx, network, net.JoinHostPort(addr, port))
+			results <- dialResult{conn: conn, addr: addr, err: err}
+		}()
+	}
+
+	start()
+
+	timer := time.NewTimer(d.fallbackDelay)
+	defer timer.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case result := <-results:
+			pending--
+
+			if result.err == nil {
+				// The attempts still pending are canceled, the connections established meanwhile are closed.
+				go closeLateConnections(results, pending)
+				return result.conn, result.addr, nil
+			}
+
+			lastErr = result.err
+			if next < len(addrs) {
+				start()
+				resetTimer(timer, d.fallbackDelay)
+			} else if pending == 0 {
+				return nil, "", lastErr
+			}
+
+		case <-timer.C:
+			if next < len(addrs) {
+				start()
+				timer.Reset(d.fallbackDelay)
+			}
+		}
+	}
+}
+
+func closeLateConnections(results <-chan dialResult, pending int) {
+	for i := 0; i < pending; i++ {
+		if result := <-results; result.conn != nil {
+			_ = result.conn.Close()
+		}
+	}
+}
+
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
+
+// sortAddresses keeps the addresses of the family, and alternates the addresses of the preferred family and of the other one,
+// keeping their order within each family.
+func sortAddresses(addrs []string, family string) []string {
+	var ipv4, ipv6 []string
+	for _, addr := range addrs {
+		if addressFamily(addr) == addressFamilyIPv6 {
+			ipv6 = append(ipv6, addr)
+		} else {
+			ipv4 = append(ipv4, addr)
+		}
+	}
+
+	switch family {
+	case addressFamilyIPv4:
+		return ipv4
+	case addressFamilyIPv6:
+		return ipv6
+	case addressFamilyPreferIPv6:
+		return interleave(ipv6, ipv4)
+	default:
+		return interleave(ipv4, ipv6)
+	}
+}
+
+func interleave(primaries, fallbacks []string) []string {
+	result := make([]string, 0, len(primaries)+len(fallbacks))
+	for i := 0; i < len(primaries) || i < len(fallbacks); i++ {
+		if i < len(primaries) {
+			result = append(result, primaries[i])
+		}
+		if i < len(fallbacks) {
+			result = append(result, fallbacks[i])
+		}
+	}
+	return result
+}
+
+func addressFamily(addr string) string {
+	if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+		return addressFamilyIPv6
+	}
+	return addressFamilyIPv4
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortAddresses(t *testing.T) {
+	addrs := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "::1", "::2"}
+
+	testCases := []struct {
+		desc     string
+		family   string
+		expected []string
+	}{
+		{
+			desc:     "default",
+			expected: []string{"10.0.0.1", "::1", "10.0.0.2", "::2", "10.0.0.3"},
+		},
+		{
+			desc:     "preferIPv6",
+			family:   addressFamilyPreferIPv6,
+			expected: []string{"::1", "10.0.0.1", "::2", "10.0.0.2", "10.0.0.3"},
+		},
+		{
+			desc:     "ipv4",
+			family:   addressFamilyIPv4,
+			expected: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		},
+		{
+			desc:     "ipv6",
+			family:   addressFamilyIPv6,
+			expected: []string{"::1", "::2"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, sortAddresses(addrs, test.family))
+		})
+	}
+}
+
+func TestNewServersDialer(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		conf          *config.Dial
+		expectedDelay time.Duration
+		expectedErr   bool
+	}{
+		{
+			desc:          "no configuration",
+			expectedDelay: defaultFallbackDelay,
+		},
+		{
+			desc:          "fallback delay",
+			conf:          &config.Dial{AddressFamily: addressFamilyPreferIPv6, FallbackDelay: parse.Duration(time.Second)},
+			expectedDelay: time.Second,
+		},
+		{
+			desc:        "invalid address family",
+			conf:        &config.Dial{AddressFamily: "ipx"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dialer, err := newServersDialer(test.conf, nil, nil, nil)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedDelay, dialer.fallbackDelay)
+		})
+	}
+}
+
+type fakeDial struct {
+	mu     sync.Mutex
+	dialed []string
+	delays map[string]time.Duration
+	errors map[string]error
+}
+
+func (d *fakeDial) dial(ctx context.Context, _, address string) (net.Conn, error) {
+	d.mu.Lock()
+	d.dialed = append(d.dialed, address)
+	delay, err := d.delays[address], d.errors[address]
+	d.mu.Unlock()
+
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	client, server := net.Pipe()
+	_ = server.Close()
+	return client, nil
+}
+
+func (d *fakeDial) addresses() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string{}, d.dialed...)
+}
+
+// fakeCounter counts the additions whatever their labels.
+type fakeCounter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (c *fakeCounter) With(_ ...string) gokitmetrics.Counter {
+	return c
+}
+
+func (c *fakeCounter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.value += delta
+}
+
+func TestServersDialerDialContext(t *testing.T) {
+	lookup := func(_ context.Context, host string) ([]string, error) {
+		if host != "backend" {
+			return nil, errors.New("unknown host")
+		}
+		return []string{"::1", "10.0.0.1", "10.0.0.2"}, nil
+	}
+
+	testCases := []struct {
+		desc              string
+		address           string
+		delays            map[string]time.Duration
+		errors            map[string]error
+		expectedDialed    []string
+		expectedFallbacks float64
+		expectedErr       bool
+	}{
+		{
+			desc:           "IP address",
+			address:        "10.0.0.3:80",
+			expectedDialed: []string{"10.0.0.3:80"},
+		},
+		{
+			desc:           "first address",
+			address:        "backend:80",
+			expectedDialed: []string{"10.0.0.1:80"},
+		},
+		{
+			desc:              "failed attempt",
+			address:           "backend:80",
+			errors:            map[string]error{"10.0.0.1:80": errors.New("connection refused")},
+			expectedDialed:    []string{"10.0.0.1:80", "[::1]:80"},
+			expectedFallbacks: 1,
+		},
+		{
+			desc:              "slow attempt",
+			address:           "backend:80",
+			delays:            map[string]time.Duration{"10.0.0.1:80": time.Second},
+			expectedDialed:    []string{"10.0.0.1:80", "[::1]:80"},
+			expectedFallbacks: 1,
+		},
+		{
+			desc:    "failed attempts",
+			address: "backend:80",
+			errors: map[string]error{
+				"10.0.0.1:80": errors.New("connection refused"),
+				"[::1]:80":    errors.New("connection refused"),
+				"10.0.0.2:80": errors.New("connection refused"),
+			},
+			expectedDialed: []string{"10.0.0.1:80", "[::1]:80", "10.0.0.2:80"},
+			expectedErr:    true,
+		},
+		{
+			desc:           "unknown host",
+			address:        "unknown:80",
+			expectedDialed: []string{},
+			expectedErr:    true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dial := &fakeDial{delays: test.delays, errors: test.errors}
+			fallbacks := &fakeCounter{}
+
+			dialer, err := newServersDialer(&config.Dial{FallbackDelay: parse.Duration(50 * time.Millisecond)}, lookup, dial.dial, fallbacks)
+			require.NoError(t, err)
+
+			conn, err := dialer.DialContext(context.Background(), "tcp", test.address)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				_ = conn.Close()
+			}
+
+			assert.Equal(t, test.expectedDialed, dial.addresses())
+			assert.Equal(t, test.expectedFallbacks, fallbacks.value)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/miekg/dns"
+)
+
+const (
+	defaultResolvConfig = "/etc/resolv.conf"
+
+	// defaultDNSTTL is used when the TTL of the records is unknown.
+	defaultDNSTTL = 30 * time.Second
+	// minDNSTTL prevents resolving the hostnames for every request when their records have a zero TTL.
+	minDNSTTL = time.Second
+	// dnsRetryDelay is the delay before resolving a hostname again when its resolution fails.
+	dnsRetryDelay = 5 * time.Second
+	dnsTimeout    = 5 * time.Second
+)
+
+// lookupFunc returns the addresses of a hostname, and how long they are valid.
+type lookupFunc func(ctx context.Context, host string) ([]string, time.Duration, error)
+
+// dnsResolver resolves the hostnames of the servers of a service, and resolves them again once their records expire.
+// The connections are spread over the addresses of the hostnames,
+// and the idle ones are closed when the addresses change, so that the new connections go to the new addresses,
+// while the requests in flight to the removed addresses complete.
+type dnsResolver struct {
+	interval time.Duration
+	lookup   lookupFunc
+	onChange func()
+
+	mu    sync.Mutex
+	hosts map[string]*resolvedHost
+}
+
+type resolvedHost struct {
+	addrs     []string
+	expires   time.Time
+	next      int
+	resolving bool
+}
+
+func newDNSResolver(conf *config.DNSResolution, onChange func()) (*dnsResolver, error) {
+	resolvConfig := conf.ResolvConfig
+	if len(resolvConfig) == 0 {
+		resolvConfig = defaultResolvConfig
+	}
+
+	clientConfig, err := dns.ClientConfigFromFile(resolvConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolver configuration file %s: %v", resolvConfig, err)
+	}
+
+	return &dnsResolver{
+		interval: time.Duration(conf.Interval),
+		lookup:   newDNSLookup(clientConfig),
+		onChange: onChange,
+		hosts:    make(map[string]*resolvedHost),
+	}, nil
+}
+
+// refresh resolves the hostname again in the background, if its records have expired.
+func (r *dnsResolver) refresh(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, ok := r.hosts[host]
+	if !ok || h.resolving || time.Now().Before(h.expires) {
+		return
+	}
+
+	h.resolving = true
+	go r.resolve(context.Background(), host)
+}
+
+// addresses returns the addresses of the hostname, rotated to start with the next one in turn.
+// The hostname is resolved the first time, the expired addresses are used while it is resolved again.
+func (r *dnsResolver) addresses(ctx context.Context, host string) ([]string, error) {
+	r.mu.Lock()
+	h, ok := r.hosts[host]
+	if !ok || len(h.addrs) == 0 {
+		r.mu.Unlock()
+
+		if err := r.resolve(ctx, host); err != nil {
+			return nil, err
+		}
+
+		r.mu.Lock()
+		h = r.hosts[host]
+	}
+	defer r.mu.Unlock()
+
+	if !h.resolving && !time.Now().Before(h.expires) {
+		h.resolving = true
+		go r.resolve(context.Background(), host)
+	}
+
+	start := h.next % len(h.addrs)
+	h.next++
+
+	return append(append([]string{}, h.addrs[start:]...), h.addrs[:start]...), nil
+}
+
+// resolve resolves the hostname, and records its addresses until they expire.
+// On failure, the previous addresses, if any, are kept until the next attempt.
+func (r *dnsResolver) resolve(ctx context.Context, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, dnsTimeout)
+	defer cancel()
+
+	addrs, ttl, err := r.lookup(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = fmt.Errorf("no address found for %s", host)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, ok := r.hosts[host]
+	if !ok {
+		h = &resolvedHost{}
+	}
+	h.resolving = false
+
+	if err != nil {
+		if len(h.addrs) > 0 {
+			log.WithoutContext().Warnf("Unable to resolve %s again, keeping the addresses %v: %v", host, h.addrs, err)
+			h.expires = time.Now().Add(dnsRetryDelay)
+			r.hosts[host] = h
+		}
+		return err
+	}
+
+	sort.Strings(addrs)
+
+	if r.interval > 0 {
+		ttl = r.interval
+	} else if ttl < minDNSTTL {
+		ttl = minDNSTTL
+	}
+	h.expires = time.Now().Add(ttl)
+
+	changed := ok && strings.Join(h.addrs, ",") != strings.Join(addrs, ",")
+	h.addrs = addrs
+	r.hosts[host] = h
+
+	if changed {
+		log.WithoutContext().Debugf("The addresses of %s changed to %v", host, addrs)
+		if r.onChange != nil {
+			r.onChange()
+		}
+	}
+
+	return nil
+}
+
+// newDNSLookup creates a lookup querying the A and AAAA records of the hostnames, with the search domains,
+// to the name servers of the resolver configuration.
+// The addresses are valid for the lowest TTL of their records.
+func newDNSLookup(clientConfig *dns.ClientConfig) lookupFunc {
+	client := &dns.Client{Timeout: dnsTimeout}
+
+	return func(ctx context.Context, host string) ([]string, time.Duration, error) {
+		err := errors.New("no name server configured")
+
+		for _, name := range clientConfig.NameList(host) {
+			for _, server := range clientConfig.Servers {
+				var addrs []string
+				var ttl time.Duration
+				addrs, ttl, err = exchangeAddresses(ctx, client, net.JoinHostPort(server, clientConfig.Port), name)
+				if err != nil {
+					continue
+				}
+				if len(addrs) > 0 {
+					return addrs, ttl, nil
+				}
+				err = errors.New("no address found")
+				break
+			}
+		}
+
+		return nil, 0, fmt.Errorf("unable to resolve %s: %v", host, err)
+	}
+}
+
+func exchangeAddresses(ctx context.Context, client *dns.Client, server, name string) ([]string, time.Duration, error) {
+	var addrs []string
+	ttl := defaultDNSTTL
+
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		msg := &dns.Msg{}
+		msg.SetQuestion(name, qtype)
+
+		resp, _, err := client.ExchangeContext(ctx, msg, server)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, answer := range resp.Answer {
+			var addr net.IP
+			switch rr := answer.(type) {
+			case *dns.A:
+				addr = rr.A
+			case *dns.AAAA:
+				addr = rr.AAAA
+			default:
+				continue
+			}
+
+			recordTTL := time.Duration(answer.Header().Ttl) * time.Second
+			if len(addrs) == 0 || recordTTL < ttl {
+				ttl = recordTTL
+			}
+			addrs = append(addrs, addr.String())
+		}
+	}
+
+	return addrs, ttl, nil
+}
+
+// dnsResolutionRoundTripper resolves the hostnames of the servers again, in the background, once their records expire,
+// as the requests going through the kept-alive connections don't dial.
+type dnsResolutionRoundTripper struct {
+	next     http.RoundTripper
+	resolver *dnsResolver
+}
+
+func (rt *dnsResolutionRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.resolver.refresh(req.URL.Hostname())
+	return rt.next.RoundTrip(req)
+}
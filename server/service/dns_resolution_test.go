@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLookup struct {
+	mu      sync.Mutex
+	addrs   []string
+	ttl     time.Duration
+	err     error
+	lookups int
+}
+
+func (l *fakeLookup) lookup(_ context.Context, _ string) ([]string, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lookups++
+	return l.addrs, l.ttl, l.err
+}
+
+func (l *fakeLookup) set(addrs []string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.addrs = addrs
+	l.err = err
+}
+
+func (l *fakeLookup) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lookups
+}
+
+func TestDNSResolverAddresses(t *testing.T) {
+	lookup := &fakeLookup{addrs: []string{"10.0.0.3", "10.0.0.1", "10.0.0.2"}, ttl: time.Minute}
+
+	resolver := &dnsResolver{
+		lookup: lookup.lookup,
+		hosts:  make(map[string]*resolvedHost),
+	}
+
+	var addrs [][]string
+	for i := 0; i < 4; i++ {
+		a, err := resolver.addresses(context.Background(), "backend")
+		require.NoError(t, err)
+		addrs = append(addrs, a)
+	}
+
+	// The addresses are rotated, so that the connections are spread over them.
+	expected := [][]string{
+		{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		{"10.0.0.2", "10.0.0.3", "10.0.0.1"},
+		{"10.0.0.3", "10.0.0.1", "10.0.0.2"},
+		{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+	}
+	assert.Equal(t, expected, addrs)
+	assert.Equal(t, 1, lookup.count())
+
+	lookup.set(nil, errors.New("timeout"))
+	_, err := resolver.addresses(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestDNSResolverResolve(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		addrs           []string
+		err             error
+		expectedAddrs   []string
+		expectedChanged bool
+	}{
+		{
+			desc:          "same addresses",
+			addrs:         []string{"10.0.0.2", "10.0.0.1"},
+			expectedAddrs: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			desc:            "changed addresses",
+			addrs:           []string{"10.0.0.3", "10.0.0.1"},
+			expectedAddrs:   []string{"10.0.0.1", "10.0.0.3"},
+			expectedChanged: true,
+		},
+		{
+			desc:          "failed resolution",
+			err:           errors.New("timeout"),
+			expectedAddrs: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			desc:          "no address",
+			expectedAddrs: []string{"10.0.0.1", "10.0.0.2"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			lookup := &fakeLookup{addrs: []string{"10.0.0.1", "10.0.0.2"}}
+
+			var changed bool
+			resolver := &dnsResolver{
+				lookup:   lookup.lookup,
+				onChange: func() { changed = true },
+				hosts:    make(map[string]*resolvedHost),
+			}
+			require.NoError(t, resolver.resolve(context.Background(), "backend"))
+
+			lookup.set(test.addrs, test.err)
+			err := resolver.resolve(context.Background(), "backend")
+			if test.err != nil || len(test.addrs) == 0 {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expectedAddrs, resolver.hosts["backend"].addrs)
+			assert.Equal(t, test.expectedChanged, changed)
+		})
+	}
+}
+
+func TestDNSResolverExpiry(t *testing.T) {
+	lookup := &fakeLookup{addrs: []string{"10.0.0.1"}}
+
+	resolver := &dnsResolver{
+		interval: time.Hour,
+		lookup:   lookup.lookup,
+		hosts:    make(map[string]*resolvedHost),
+	}
+
+	resolver.refresh("backend")
+	assert.Equal(t, 0, lookup.count(), "unknown hostnames are resolved when dialed")
+
+	require.NoError(t, resolver.resolve(context.Background(), "backend"))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), resolver.hosts["backend"].expires, time.Minute)
+
+	resolver.refresh("backend")
+	assert.Equal(t, 1, lookup.count(), "the addresses are not expired")
+
+	resolver.mu.Lock()
+	resolver.hosts["backend"].expires = time.Now().Add(-time.Second)
+	resolver.mu.Unlock()
+
+	lookup.set([]string{"10.0.0.2"}, nil)
+	resolver.refresh("backend")
+
+	resolved := func() bool {
+		resolver.mu.Lock()
+		defer resolver.mu.Unlock()
+		return !resolver.hosts["backend"].resolving
+	}
+	for i := 0; i < 100 && !resolved(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	addrs, err := resolver.addresses(context.Background(), "backend")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2"}, addrs)
+	assert.Equal(t, 2, lookup.count())
+}
+
+func TestBuildRoundTripperDNSResolution(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(req.Host))
+	}))
+	defer backend.Close()
+
+	backendURL, err := url.Parse(backend.URL)
+	require.NoError(t, err)
+
+	tempDir, err := ioutil.TempDir("", "dns-resolution")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	resolvConfig := filepath.Join(tempDir, "resolv.conf")
+	require.NoError(t, ioutil.WriteFile(resolvConfig, []byte("nameserver 127.0.0.1\n"), 0600))
+
+	manager := NewManager(nil, http.DefaultTransport, nil, nil, nil)
+	roundTripper, _, err := manager.buildRoundTripper("foo", &config.LoadBalancerService{
+		DNSResolution: &config.DNSResolution{Interval: parse.Duration(time.Minute), ResolvConfig: resolvConfig},
+	})
+	require.NoError(t, err)
+
+	rt, ok := roundTripper.(*dnsResolutionRoundTripper)
+	require.True(t, ok)
+	rt.resolver.lookup = func(_ context.Context, host string) ([]string, time.Duration, error) {
+		if host != "backend.test" {
+			return nil, 0, errors.New("unknown host")
+		}
+		return []string{backendURL.Hostname()}, 0, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://backend.test:"+backendURL.Port(), nil)
+	resp, err := roundTripper.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "backend.test:"+backendURL.Port(), string(body))
+	assert.Equal(t, roundTripper, manager.getRoundTripper("foo"))
+
+	_, _, err = manager.buildRoundTripper("bar", &config.LoadBalancerService{
+		DNSResolution: &config.DNSResolution{ResolvConfig: filepath.Join(tempDir, "missing.conf")},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bufio"
+	"mime"
+	"net"
+	"net/http"
+)
+
+const eventStreamMediaType = "text/event-stream"
+
+// eventStreamHandler flushes the Server-Sent Events immediately,
+// whatever the flush interval of the forwarder: a client must not wait for the next flush to get an event.
+type eventStreamHandler struct {
+	next http.Handler
+}
+
+func (h *eventStreamHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h.next.ServeHTTP(newEventStreamResponseWriter(rw), req)
+}
+
+type eventStreamWithoutCloseNotify struct {
+	http.ResponseWriter
+	flush bool
+}
+
+// WriteHeader flushes the headers of an event stream right away.
+func (w *eventStreamWithoutCloseNotify) WriteHeader(code int) {
+	w.flush = isEventStream(w.Header())
+	w.ResponseWriter.WriteHeader(code)
+
+	if w.flush {
+		w.Flush()
+	}
+}
+
+// Write flushes each write of an event stream.
+func (w *eventStreamWithoutCloseNotify) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if err == nil && w.flush {
+		w.Flush()
+	}
+	return n, err
+}
+
+// Hijack hijacks the connection.
+func (w *eventStreamWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (w *eventStreamWithoutCloseNotify) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type eventStreamWithCloseNotify struct {
+	*eventStreamWithoutCloseNotify
+}
+
+func (w *eventStreamWithCloseNotify) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func newEventStreamResponseWriter(rw http.ResponseWriter) http.ResponseWriter {
+	writer := &eventStreamWithoutCloseNotify{ResponseWriter: rw}
+	if _, ok := rw.(http.CloseNotifier); ok {
+		return &eventStreamWithCloseNotify{writer}
+	}
+	return writer
+}
+
+func isEventStream(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == eventStreamMediaType
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventStreamHandler(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		contentType     string
+		expectedFlushed bool
+	}{
+		{
+			desc:            "event stream",
+			contentType:     "text/event-stream",
+			expectedFlushed: true,
+		},
+		{
+			desc:            "event stream with parameters",
+			contentType:     "text/event-stream; charset=utf-8",
+			expectedFlushed: true,
+		},
+		{
+			desc:        "HTML",
+			contentType: "text/html",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := &eventStreamHandler{
+				next: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					rw.Header().Set("Content-Type", test.contentType)
+					rw.WriteHeader(http.StatusOK)
+					_, _ = rw.Write([]byte("data: foo\n\n"))
+				}),
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+			assert.Equal(t, test.expectedFlushed, recorder.Flushed)
+			assert.Equal(t, "data: foo\n\n", recorder.Body.String())
+		})
+	}
+}
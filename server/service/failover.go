@@ -0,0 +1,85 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+// serverLister lists the servers of a load balancer.
+type serverLister interface {
+	Servers() []*url.URL
+}
+
+// unionServers lists the servers of several load balancers.
+type unionServers []serverLister
+
+func (u unionServers) Servers() []*url.URL {
+	var servers []*url.URL
+	for _, lister := range u {
+		servers = append(servers, lister.Servers()...)
+	}
+	return servers
+}
+
+// failoverHandler sends the requests to the primary handler while the load balancer of the service has servers,
+// and to the secondary handler once the health checks have removed all of them.
+// It fails back to the primary handler once the load balancer has had servers for the failback delay,
+// so a flapping server doesn't bounce the requests between the two services.
+type failoverHandler struct {
+	serviceName   string
+	secondaryName string
+	primary       http.Handler
+	secondary     http.Handler
+	balancer      serverLister
+	failbackDelay time.Duration
+
+	lock       sync.Mutex
+	failedOver bool
+	upSince    time.Time
+}
+
+func (f *failoverHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if f.useSecondary() {
+		f.secondary.ServeHTTP(rw, req)
+		return
+	}
+	f.primary.ServeHTTP(rw, req)
+}
+
+func (f *failoverHandler) useSecondary() bool {
+	up := len(f.balancer.Servers()) > 0
+	logger := log.WithoutContext().WithField(log.ServiceName, f.serviceName)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.failedOver {
+		if !up {
+			f.failedOver = true
+			f.upSince = time.Time{}
+			logger.Warnf("All the servers of the service %s are down, failing over to %s", f.serviceName, f.secondaryName)
+		}
+		return f.failedOver
+	}
+
+	if !up {
+		f.upSince = time.Time{}
+		return true
+	}
+
+	now := time.Now()
+	if f.upSince.IsZero() {
+		f.upSince = now
+	}
+	if now.Sub(f.upSince) < f.failbackDelay {
+		return true
+	}
+
+	f.failedOver = false
+	logger.Warnf("The servers of the service %s are up, failing back from %s", f.serviceName, f.secondaryName)
+	return false
+}
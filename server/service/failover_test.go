@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBalancer struct {
+	lock    sync.Mutex
+	servers []*url.URL
+}
+
+func (b *fakeBalancer) Servers() []*url.URL {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.servers
+}
+
+func (b *fakeBalancer) setUp(up bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.servers = nil
+	if up {
+		b.servers = []*url.URL{{Scheme: "http", Host: "10.0.0.1"}}
+	}
+}
+
+func handlerNamed(name string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(name))
+	})
+}
+
+func TestFailoverHandler(t *testing.T) {
+	balancer := &fakeBalancer{}
+	balancer.setUp(true)
+
+	handler := &failoverHandler{
+		serviceName:   "primary",
+		secondaryName: "secondary",
+		primary:       handlerNamed("primary"),
+		secondary:     handlerNamed("secondary"),
+		balancer:      balancer,
+		failbackDelay: 50 * time.Millisecond,
+	}
+
+	serve := func() string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		return recorder.Body.String()
+	}
+
+	assert.Equal(t, "primary", serve())
+
+	balancer.setUp(false)
+	assert.Equal(t, "secondary", serve())
+
+	// The primary is back, but not for long enough.
+	balancer.setUp(true)
+	assert.Equal(t, "secondary", serve())
+
+	// Going down again resets the failback delay.
+	time.Sleep(30 * time.Millisecond)
+	balancer.setUp(false)
+	assert.Equal(t, "secondary", serve())
+	balancer.setUp(true)
+	assert.Equal(t, "secondary", serve())
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, "secondary", serve())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, "primary", serve())
+}
+
+func TestBuildFailover(t *testing.T) {
+	secondary := httptest.NewServer(handlerNamed("secondary"))
+	defer secondary.Close()
+
+	testCases := []struct {
+		desc          string
+		configs       map[string]*config.Service
+		expectedBody  string
+		expectedError string
+	}{
+		{
+			desc: "primary without servers",
+			configs: map[string]*config.Service{
+				"primary": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:   "wrr",
+						Failover: &config.Failover{Service: "secondary", FailbackDelay: parse.Duration(time.Second)},
+					},
+				},
+				"secondary": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:  "wrr",
+						Servers: []config.Server{{URL: secondary.URL, Weight: 1}},
+					},
+				},
+			},
+			expectedBody: "secondary",
+		},
+		{
+			desc: "unknown failover service",
+			configs: map[string]*config.Service{
+				"primary": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:   "wrr",
+						Failover: &config.Failover{Service: "secondary"},
+					},
+				},
+			},
+			expectedError: `error building the failover service of the service "primary": the service "secondary" does not exits`,
+		},
+		{
+			desc: "failover loop",
+			configs: map[string]*config.Service{
+				"primary": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:   "wrr",
+						Failover: &config.Failover{Service: "secondary"},
+					},
+				},
+				"secondary": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:   "wrr",
+						Failover: &config.Failover{Service: "primary"},
+					},
+				},
+			},
+			expectedError: `error building the failover service of the service "primary": error building the failover service of the service "secondary": reference loop between the services "secondary" and "primary"`,
+		},
+		{
+			desc: "no failover service",
+			configs: map[string]*config.Service{
+				"primary": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:   "wrr",
+						Failover: &config.Failover{},
+					},
+				},
+			},
+			expectedError: `no failover service defined for the service "primary"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			manager := NewManager(test.configs, http.DefaultTransport, nil, nil, nil)
+
+			handler, err := manager.Build(context.Background(), "primary", nil)
+			if len(test.expectedError) > 0 {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
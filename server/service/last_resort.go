@@ -0,0 +1,44 @@
+package service
+
+import (
+	"net/url"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// splitServers separates the last resort servers from the other ones.
+func splitServers(servers []config.Server) ([]config.Server, []config.Server) {
+	var regular, lastResorts []config.Server
+	for _, server := range servers {
+		if server.LastResort {
+			lastResorts = append(lastResorts, server)
+		} else {
+			regular = append(regular, server)
+		}
+	}
+	return regular, lastResorts
+}
+
+// serverBalancer restricts a load balancer to one of its servers, so the server can be health checked on its own.
+type serverBalancer struct {
+	healthcheck.BalancerHandler
+	server *url.URL
+	weight int
+}
+
+// Servers returns the server, if it is in the load balancer.
+func (b *serverBalancer) Servers() []*url.URL {
+	for _, u := range b.BalancerHandler.Servers() {
+		if u.String() == b.server.String() {
+			return []*url.URL{u}
+		}
+	}
+	return nil
+}
+
+// UpsertServer adds the server back to the load balancer, with its weight.
+func (b *serverBalancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	return b.BalancerHandler.UpsertServer(u, roundrobin.Weight(b.weight))
+}
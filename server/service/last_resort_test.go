@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestLastResortServers(t *testing.T) {
+	local := httptest.NewServer(handlerNamed("local"))
+	defer local.Close()
+
+	remote := httptest.NewServer(handlerNamed("remote"))
+	defer remote.Close()
+
+	configs := map[string]*config.Service{
+		"foo": {
+			LoadBalancer: &config.LoadBalancerService{
+				Method: "wrr",
+				Servers: []config.Server{
+					{URL: local.URL, Weight: 1},
+					{URL: remote.URL, Weight: 1, LastResort: true},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(configs, http.DefaultTransport, nil, nil, nil)
+
+	handler, err := manager.Build(context.Background(), "foo", nil)
+	require.NoError(t, err)
+
+	serve := func() string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+		return recorder.Body.String()
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "local", serve())
+	}
+
+	// The health check removes the local server.
+	localURL, err := url.Parse(local.URL)
+	require.NoError(t, err)
+	require.NoError(t, manager.balancers["foo"][0].RemoveServer(localURL))
+
+	assert.Equal(t, "remote", serve())
+
+	require.NoError(t, manager.balancers["foo"][0].UpsertServer(localURL))
+
+	assert.Equal(t, "local", serve())
+}
+
+func TestServerBalancer(t *testing.T) {
+	lb, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+
+	foo, err := url.Parse("http://foo")
+	require.NoError(t, err)
+	bar, err := url.Parse("http://bar")
+	require.NoError(t, err)
+
+	require.NoError(t, lb.UpsertServer(foo, roundrobin.Weight(3)))
+	require.NoError(t, lb.UpsertServer(bar, roundrobin.Weight(1)))
+
+	balancer := &serverBalancer{BalancerHandler: lb, server: foo, weight: 3}
+	assert.Equal(t, []*url.URL{foo}, balancer.Servers())
+
+	require.NoError(t, balancer.RemoveServer(foo))
+	assert.Empty(t, balancer.Servers())
+	assert.Equal(t, []*url.URL{bar}, lb.Servers())
+
+	// The health check adds the server back with a weight of 1.
+	require.NoError(t, balancer.UpsertServer(foo, roundrobin.Weight(1)))
+	assert.Equal(t, []*url.URL{foo}, balancer.Servers())
+
+	weight, ok := lb.ServerWeight(foo)
+	require.True(t, ok)
+	assert.Equal(t, 3, weight)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+// prepareProgressInterval is the interval between the logs of the progress of the preparation of the services.
+var prepareProgressInterval = 5 * time.Second
+
+// preparedRoundTripper is the round tripper of the servers of a service, with its TLS configuration, built ahead of the service.
+type preparedRoundTripper struct {
+	roundTripper http.RoundTripper
+	tlsConfig    *tls.Config
+	err          error
+}
+
+// Prepare builds the round trippers of the load balancer services concurrently, with a worker per CPU,
+// as their TLS configurations, DNS resolutions and dialers take long to build one after the other for large configurations.
+// Each prepared round tripper is used by the next build of its service.
+func (m *Manager) Prepare(ctx context.Context, serviceNames []string) {
+	var names []string
+	for _, serviceName := range serviceNames {
+		conf, ok := m.configs[serviceName]
+		if !ok || conf.LoadBalancer == nil {
+			continue
+		}
+		// The adopted services keep the round tripper of the previous manager.
+		if _, ok := m.balancers[serviceName]; ok {
+			continue
+		}
+		names = append(names, serviceName)
+	}
+
+	if len(names) == 0 {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	start := time.Now()
+
+	workers := runtime.NumCPU()
+	if workers > len(names) {
+		workers = len(names)
+	}
+
+	var mu sync.Mutex
+	prepared := make(map[string]*preparedRoundTripper, len(names))
+	var done int64
+
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for serviceName := range queue {
+				roundTripper, tlsConfig, err := m.newRoundTripper(serviceName, m.configs[serviceName].LoadBalancer)
+
+				mu.Lock()
+				prepared[serviceName] = &preparedRoundTripper{roundTripper: roundTripper, tlsConfig: tlsConfig, err: err}
+				mu.Unlock()
+
+				atomic.AddInt64(&done, 1)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(prepareProgressInterval)
+	defer ticker.Stop()
+
+	for _, serviceName := range names {
+		for queued := false; !queued; {
+			select {
+			case queue <- serviceName:
+				queued = true
+			case <-ticker.C:
+				logger.Infof("Prepared the servers of %d/%d services", atomic.LoadInt64(&done), len(names))
+			}
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	m.prepared = prepared
+	logger.Debugf("Prepared the servers of %d services in %s", len(names), time.Since(start))
+}
+
+// preparedRoundTripper returns, only once, the round tripper of a service built by Prepare, if any.
+func (m *Manager) preparedRoundTripper(serviceName string) (*preparedRoundTripper, bool) {
+	prepared, ok := m.prepared[serviceName]
+	if ok {
+		delete(m.prepared, serviceName)
+	}
+	return prepared, ok
+}
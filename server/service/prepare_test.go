@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepare(t *testing.T) {
+	configs := map[string]*config.Service{
+		"invalid": {LoadBalancer: &config.LoadBalancerService{
+			ServersTLS: &config.ServersTLS{SessionCacheSize: -1},
+		}},
+		"adopted": {LoadBalancer: &config.LoadBalancerService{
+			ServersTLS: &config.ServersTLS{ServerName: "adopted.com"},
+		}},
+		"default":  {LoadBalancer: &config.LoadBalancerService{}},
+		"function": {Function: &config.FunctionService{}},
+	}
+
+	var serviceNames []string
+	for i := 0; i < 20; i++ {
+		serviceName := fmt.Sprintf("tls%d", i)
+		configs[serviceName] = &config.Service{LoadBalancer: &config.LoadBalancerService{
+			ServersTLS: &config.ServersTLS{ServerName: serviceName + ".com"},
+		}}
+		serviceNames = append(serviceNames, serviceName)
+	}
+	serviceNames = append(serviceNames, "invalid", "adopted", "default", "function", "missing")
+
+	manager := NewManager(configs, http.DefaultTransport, nil, nil, nil)
+	manager.balancers["adopted"] = []healthcheck.BalancerHandler{}
+
+	manager.Prepare(context.Background(), serviceNames)
+
+	assert.Len(t, manager.prepared, 22)
+	assert.NotContains(t, manager.prepared, "adopted")
+	assert.NotContains(t, manager.prepared, "function")
+	assert.NotContains(t, manager.prepared, "missing")
+
+	for i := 0; i < 20; i++ {
+		serviceName := fmt.Sprintf("tls%d", i)
+		prepared := manager.prepared[serviceName]
+		require.NoError(t, prepared.err)
+
+		roundTripper, tlsConfig, err := manager.buildRoundTripper(serviceName, configs[serviceName].LoadBalancer)
+		require.NoError(t, err)
+
+		assert.Equal(t, serviceName+".com", tlsConfig.ServerName)
+		assert.Equal(t, prepared.roundTripper, roundTripper)
+		assert.Equal(t, roundTripper, manager.getRoundTripper(serviceName))
+		assert.NotContains(t, manager.prepared, serviceName)
+	}
+
+	_, _, err := manager.buildRoundTripper("invalid", configs["invalid"].LoadBalancer)
+	assert.Error(t, err)
+
+	roundTripper, _, err := manager.buildRoundTripper("default", configs["default"].LoadBalancer)
+	require.NoError(t, err)
+	assert.Equal(t, http.DefaultTransport, roundTripper)
+	assert.Empty(t, manager.prepared)
+}
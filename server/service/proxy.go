@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/containous/traefik/config"
+	"golang.org/x/net/proxy"
+)
+
+// ProxyDialer opens the connections to the servers through an outbound proxy,
+// apart from the connections to the hosts of the no-proxy list, which are opened directly.
+// With an HTTP proxy, the connections are tunneled with CONNECT.
+type ProxyDialer struct {
+	proxyURL *url.URL
+	noProxy  *noProxy
+	dial     dialFunc
+}
+
+// NewProxyDialer creates a ProxyDialer, dialing the proxy and the hosts of the no-proxy list with dial.
+func NewProxyDialer(conf *config.Proxy, dial func(ctx context.Context, network, address string) (net.Conn, error)) (*ProxyDialer, error) {
+	proxyURL, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %v", conf.URL, err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q, expected an http, https or socks5 scheme", conf.URL)
+	}
+
+	if len(proxyURL.Hostname()) == 0 {
+		return nil, fmt.Errorf("invalid proxy URL %q, no host", conf.URL)
+	}
+	if len(proxyURL.Port()) == 0 {
+		proxyURL.Host = net.JoinHostPort(proxyURL.Hostname(), defaultProxyPort(proxyURL.Scheme))
+	}
+
+	noProxy, err := newNoProxy(conf.NoProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProxyDialer{proxyURL: proxyURL, noProxy: noProxy, dial: dial}, nil
+}
+
+// DialContext connects to the address through the proxy, or directly if its host is in the no-proxy list.
+func (d *ProxyDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.noProxy.matches(host) {
+		return d.dial(ctx, network, address)
+	}
+
+	if d.proxyURL.Scheme == "socks5" {
+		return d.dialSOCKS5(ctx, network, address)
+	}
+	return d.dialCONNECT(ctx, network, address)
+}
+
+func (d *ProxyDialer) dialSOCKS5(ctx context.Context, network, address string) (net.Conn, error) {
+	var auth *proxy.Auth
+	if d.proxyURL.User != nil {
+		password, _ := d.proxyURL.User.Password()
+		auth = &proxy.Auth{User: d.proxyURL.User.Username(), Password: password}
+	}
+
+	dialer, err := proxy.SOCKS5("tcp", d.proxyURL.Host, auth, &contextDialer{ctx: ctx, dial: d.dial})
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialer.Dial(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s through the SOCKS5 proxy %s: %v", address, d.proxyURL.Host, err)
+	}
+	return conn, nil
+}
+
+func (d *ProxyDialer) dialCONNECT(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dial(ctx, "tcp", d.proxyURL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: d.proxyURL.Hostname()})
+		if err = tlsConn.Handshake(); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	// The tunnel is aborted when the context is done before it is established.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if d.proxyURL.User != nil {
+		password, _ := d.proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err = req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unable to connect to %s through the proxy %s: %s", address, d.proxyURL.Host, resp.Status)
+	}
+
+	select {
+	case <-ctx.Done():
+		_ = conn.Close()
+		return nil, ctx.Err()
+	default:
+	}
+
+	return conn, nil
+}
+
+func defaultProxyPort(scheme string) string {
+	switch scheme {
+	case "https":
+		return "443"
+	case "socks5":
+		return "1080"
+	default:
+		return "80"
+	}
+}
+
+// contextDialer dials the SOCKS5 proxy with the context of the connection.
+type contextDialer struct {
+	ctx  context.Context
+	dial dialFunc
+}
+
+func (d *contextDialer) Dial(network, address string) (net.Conn, error) {
+	return d.dial(d.ctx, network, address)
+}
+
+// noProxy holds the hosts connected to directly:
+// the hostnames, the domains, with a leading dot, matching their subdomains, the IP addresses and the CIDR ranges.
+// A "*" matches all the hosts.
+type noProxy struct {
+	all      bool
+	hosts    map[string]bool
+	domains  []string
+	ips      []net.IP
+	networks []*net.IPNet
+}
+
+func newNoProxy(entries []string) (*noProxy, error) {
+	n := &noProxy{hosts: make(map[string]bool)}
+
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+
+		switch {
+		case len(entry) == 0:
+		case entry == "*":
+			n.all = true
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid no-proxy range %q: %v", entry, err)
+			}
+			n.networks = append(n.networks, network)
+		case net.ParseIP(entry) != nil:
+			n.ips = append(n.ips, net.ParseIP(entry))
+		case strings.HasPrefix(entry, "."):
+			n.domains = append(n.domains, entry)
+		default:
+			n.hosts[entry] = true
+		}
+	}
+
+	return n, nil
+}
+
+func (n *noProxy) matches(host string) bool {
+	if n.all {
+		return true
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range n.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		for _, noProxyIP := range n.ips {
+			if noProxyIP.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	host = strings.ToLower(host)
+	if n.hosts[host] {
+		return true
+	}
+	for _, domain := range n.domains {
+		if strings.HasSuffix(host, domain) || host == domain[1:] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/healthcheck"
+)
+
+// Config returns the configuration of a service.
+func (m *Manager) Config(serviceName string) (*config.Service, bool) {
+	conf, ok := m.configs[serviceName]
+	return conf, ok
+}
+
+// Adopt takes over the state the previous manager holds for the services, whose handlers are reused:
+// their load balancers, schedulers, round trippers and health checks,
+// so that the drains, the health checks and the disabled servers carry on across the configuration reload.
+func (m *Manager) Adopt(previous *Manager, serviceNames []string) {
+	for _, serviceName := range serviceNames {
+		if balancers, ok := previous.balancers[serviceName]; ok {
+			m.balancers[serviceName] = balancers
+		}
+		if lastResorts, ok := previous.lastResorts[serviceName]; ok {
+			m.lastResorts[serviceName] = lastResorts
+		}
+		if sched, ok := previous.schedulers[serviceName]; ok {
+			m.schedulers[serviceName] = sched
+		}
+		if roundTripper, ok := previous.roundTrippers[serviceName]; ok {
+			m.roundTrippers[serviceName] = roundTripper
+		}
+		if healthChecks, ok := previous.healthChecks[serviceName]; ok {
+			m.healthChecks[serviceName] = healthChecks
+		}
+	}
+}
+
+// backendHealthCheck returns the health check of a backend of a service,
+// the one of the previous manager when the service was adopted, so that the servers it disabled stay disabled.
+func (m *Manager) backendHealthCheck(serviceName, backendName string, options healthcheck.Options) *healthcheck.BackendConfig {
+	if backend, ok := m.healthChecks[serviceName][backendName]; ok {
+		return backend
+	}
+
+	backend := healthcheck.NewBackendConfig(options, backendName)
+	if _, ok := m.healthChecks[serviceName]; !ok {
+		m.healthChecks[serviceName] = make(map[string]*healthcheck.BackendConfig)
+	}
+	m.healthChecks[serviceName][backendName] = backend
+	return backend
+}
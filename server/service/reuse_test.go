@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/healthcheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Adopt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	defer healthcheck.GetHealthCheck().SetBackendsConfiguration(context.Background(), nil)
+
+	configs := func() map[string]*config.Service {
+		return map[string]*config.Service{
+			"kept": {LoadBalancer: &config.LoadBalancerService{
+				Servers:     []config.Server{{URL: server.URL, Weight: 1}},
+				HealthCheck: &config.HealthCheck{Path: "/health", Interval: "1h"},
+			}},
+			"rebuilt": {LoadBalancer: &config.LoadBalancerService{
+				Servers:     []config.Server{{URL: server.URL, Weight: 1}},
+				HealthCheck: &config.HealthCheck{Path: "/health", Interval: "1h"},
+			}},
+		}
+	}
+
+	previous := NewManager(configs(), http.DefaultTransport, nil, nil, nil)
+	for serviceName := range previous.configs {
+		_, err := previous.Build(context.Background(), serviceName, nil)
+		require.NoError(t, err)
+	}
+	previous.LaunchHealthCheck()
+
+	manager := NewManager(configs(), http.DefaultTransport, nil, nil, nil)
+	manager.Adopt(previous, []string{"kept"})
+	_, err := manager.Build(context.Background(), "rebuilt", nil)
+	require.NoError(t, err)
+	manager.LaunchHealthCheck()
+
+	assert.Equal(t, previous.balancers["kept"], manager.balancers["kept"])
+	assert.Equal(t, previous.schedulers["kept"], manager.schedulers["kept"])
+	assert.True(t, previous.healthChecks["kept"]["kept"] == manager.healthChecks["kept"]["kept"])
+
+	assert.False(t, previous.balancers["rebuilt"][0] == manager.balancers["rebuilt"][0])
+	assert.False(t, previous.healthChecks["rebuilt"]["rebuilt"] == manager.healthChecks["rebuilt"]["rebuilt"])
+}
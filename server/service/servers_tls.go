@@ -0,0 +1,345 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	traefiktls "github.com/containous/traefik/tls"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"golang.org/x/net/http2"
+)
+
+// errPinMismatch is returned when no certificate of a server matches the pinned SPKI hashes of its service.
+var errPinMismatch = errors.New("no certificate of the server matches the pinned SPKI hashes")
+
+// The reasons of the failed verifications of the certificates of the servers.
+const (
+	reasonPin                = "pin"
+	reasonUnknownAuthority   = "unknown_authority"
+	reasonHostname           = "hostname"
+	reasonInvalidCertificate = "invalid_certificate"
+)
+
+// buildRoundTripper creates the round tripper of the servers of a service, along with its TLS configuration,
+// or takes the one prepared for the service, and keeps it for the service.
+func (m *Manager) buildRoundTripper(serviceName string, service *config.LoadBalancerService) (http.RoundTripper, *tls.Config, error) {
+	var roundTripper http.RoundTripper
+	var tlsConfig *tls.Config
+	var err error
+	if prepared, ok := m.preparedRoundTripper(serviceName); ok {
+		roundTripper, tlsConfig, err = prepared.roundTripper, prepared.tlsConfig, prepared.err
+	} else {
+		roundTripper, tlsConfig, err = m.newRoundTripper(serviceName, service)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if roundTripper != m.defaultRoundTripper {
+		m.roundTrippers[serviceName] = roundTripper
+	}
+	return roundTripper, tlsConfig, nil
+}
+
+// newRoundTripper creates the round tripper of the servers of a service, along with its TLS configuration.
+// The services without their own TLS configuration, DNS resolution, dialing, proxy nor Unix socket servers use the default round tripper.
+// It does not change the state of the manager, so that the round trippers of several services can be created concurrently.
+func (m *Manager) newRoundTripper(serviceName string, service *config.LoadBalancerService) (http.RoundTripper, *tls.Config, error) {
+	roundTripper := m.defaultRoundTripper
+
+	var tlsConfig *tls.Config
+	var transport *http.Transport
+	if service.ServersTLS != nil {
+		var err error
+		tlsConfig, err = buildServersTLSConfig(service.ServersTLS)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating the TLS configuration of the servers: %v", err)
+		}
+
+		transport, err = newServersTransport(m.defaultRoundTripper, tlsConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		roundTripper = transport
+		if m.metricsRegistry != nil && m.metricsRegistry.IsEnabled() {
+			roundTripper = &verificationRoundTripper{
+				next:        transport,
+				serviceName: serviceName,
+				failures:    m.metricsRegistry.BackendTLSVerificationFailuresCounter(),
+			}
+		}
+	}
+
+	if service.DNSResolution != nil || service.Dial != nil {
+		if transport == nil {
+			transport = newTransport(m.defaultRoundTripper)
+			roundTripper = transport
+		}
+
+		dial := transport.DialContext
+		if dial == nil || service.Proxy != nil || binds(service.Dial) {
+			// The proxy and the binding of the service, if any, replace the default ones.
+			var err error
+			dial, err = newDirectDial(service.Dial)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error binding the connections to the servers: %v", err)
+			}
+		}
+
+		var lookup hostLookupFunc = net.DefaultResolver.LookupHost
+		if service.DNSResolution != nil {
+			resolver, err := newDNSResolver(service.DNSResolution, transport.CloseIdleConnections)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error creating the DNS resolution of the servers: %v", err)
+			}
+
+			lookup = resolver.addresses
+			roundTripper = &dnsResolutionRoundTripper{next: roundTripper, resolver: resolver}
+		}
+
+		var fallbacks gokitmetrics.Counter
+		if m.metricsRegistry != nil && m.metricsRegistry.IsEnabled() {
+			fallbacks = m.metricsRegistry.BackendDialFallbacksCounter().With("backend", serviceName)
+		}
+
+		dialer, err := newServersDialer(service.Dial, lookup, dial, fallbacks)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating the dialer of the servers: %v", err)
+		}
+		transport.DialContext = dialer.DialContext
+	}
+
+	if service.Proxy != nil {
+		if transport == nil {
+			transport = newTransport(m.defaultRoundTripper)
+			roundTripper = transport
+		}
+
+		dial := transport.DialContext
+		if service.DNSResolution == nil && service.Dial == nil {
+			var err error
+			dial, err = newDirectDial(nil)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		proxyDialer, err := NewProxyDialer(service.Proxy, dial)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating the proxy of the servers: %v", err)
+		}
+
+		transport.Proxy = nil
+		transport.DialContext = proxyDialer.DialContext
+	}
+
+	sockets, err := buildUnixSocketTransports(m.defaultRoundTripper, service.Servers)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(sockets) > 0 {
+		roundTripper = &unixSocketRoundTripper{next: roundTripper, sockets: sockets}
+	}
+
+	return roundTripper, tlsConfig, nil
+}
+
+// newDirectDial creates the dial of the connections opened directly, without the dial of the default transport,
+// bound to the local address and to the interface of the dial configuration, if any.
+func newDirectDial(conf *config.Dial) (dialFunc, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	if conf != nil {
+		if err := BindDialer(dialer, conf.LocalAddress, conf.Interface); err != nil {
+			return nil, err
+		}
+	}
+
+	return dialer.DialContext, nil
+}
+
+func binds(conf *config.Dial) bool {
+	return conf != nil && (len(conf.LocalAddress) > 0 || len(conf.Interface) > 0)
+}
+
+// getRoundTripper returns the round tripper of the servers of a service.
+func (m *Manager) getRoundTripper(serviceName string) http.RoundTripper {
+	if roundTripper, ok := m.roundTrippers[serviceName]; ok {
+		return roundTripper
+	}
+	return m.defaultRoundTripper
+}
+
+// buildServersTLSConfig creates the TLS configuration of the connections to the servers of a service.
+func buildServersTLSConfig(conf *config.ServersTLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         conf.ServerName,
+		InsecureSkipVerify: conf.InsecureSkipVerify,
+		NextProtos:         conf.ALPN,
+	}
+
+	if conf.SessionCacheSize < 0 {
+		return nil, fmt.Errorf("invalid session cache size %d", conf.SessionCacheSize)
+	}
+	if conf.SessionCacheSize > 0 {
+		tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(conf.SessionCacheSize)
+	}
+
+	if len(conf.CA) > 0 {
+		ca, err := traefiktls.FileOrContent(conf.CA).Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA: %v", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, errors.New("unable to parse the CA")
+		}
+	}
+
+	if len(conf.Cert) > 0 || len(conf.Key) > 0 {
+		cert, err := traefiktls.FileOrContent(conf.Cert).Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client certificate: %v", err)
+		}
+		key, err := traefiktls.FileOrContent(conf.Key).Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the client key: %v", err)
+		}
+
+		keyPair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+
+	if len(conf.PinnedSPKI) > 0 {
+		pins := make(map[string]bool)
+		for _, pin := range conf.PinnedSPKI {
+			hash, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(hash) != sha256.Size {
+				return nil, fmt.Errorf("invalid pinned SPKI hash %q, expected a base64 encoded SHA-256 hash", pin)
+			}
+			pins[string(hash)] = true
+		}
+
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			return verifyPins(pins, rawCerts, verifiedChains)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyPins checks that a certificate of the verified chains matches one of the pins.
+// Without verified chains, the chain presented by the server can't be trusted, and only its leaf certificate is checked.
+func verifyPins(pins map[string]bool, rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	matches := func(cert *x509.Certificate) bool {
+		hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		return pins[string(hash[:])]
+	}
+
+	if len(verifiedChains) == 0 {
+		if len(rawCerts) == 0 {
+			return errPinMismatch
+		}
+
+		leaf, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return err
+		}
+
+		if matches(leaf) {
+			return nil
+		}
+		return errPinMismatch
+	}
+
+	for _, chain := range verifiedChains {
+		for _, cert := range chain {
+			if matches(cert) {
+				return nil
+			}
+		}
+	}
+	return errPinMismatch
+}
+
+// newServersTransport creates the transport of the servers of a service with its own TLS configuration.
+// HTTP/2 is enabled unless the TLS configuration offers other protocols only.
+func newServersTransport(defaultRoundTripper http.RoundTripper, tlsConfig *tls.Config) (*http.Transport, error) {
+	transport := newTransport(defaultRoundTripper)
+	transport.TLSClientConfig = tlsConfig
+
+	if len(tlsConfig.NextProtos) > 0 && !offers(tlsConfig.NextProtos, http2.NextProtoTLS) {
+		return transport, nil
+	}
+
+	if err := http2.ConfigureTransport(transport); err != nil {
+		return nil, err
+	}
+	return transport, nil
+}
+
+func offers(protocols []string, protocol string) bool {
+	for _, p := range protocols {
+		if p == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// verificationRoundTripper counts the connections to the servers of a service which failed the verification of their certificate.
+type verificationRoundTripper struct {
+	next        http.RoundTripper
+	serviceName string
+	failures    gokitmetrics.Counter
+}
+
+func (rt *verificationRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		if reason := verificationFailure(err); len(reason) > 0 {
+			log.FromContext(req.Context()).Debugf("The certificate of the server %s of the service %s failed the verification: %v", req.URL.Host, rt.serviceName, err)
+			rt.failures.With("reason", reason, "backend", rt.serviceName).Add(1)
+		}
+	}
+	return resp, err
+}
+
+// verificationFailure returns the reason of the failed verification of a certificate behind the error, if any.
+func verificationFailure(err error) string {
+	for err != nil {
+		switch err.(type) {
+		case x509.UnknownAuthorityError:
+			return reasonUnknownAuthority
+		case x509.HostnameError:
+			return reasonHostname
+		case x509.CertificateInvalidError:
+			return reasonInvalidCertificate
+		}
+
+		if err == errPinMismatch {
+			return reasonPin
+		}
+
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return ""
+		}
+		err = wrapper.Unwrap()
+	}
+	return ""
+}
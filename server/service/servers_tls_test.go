@@ -0,0 +1,236 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/containous/traefik/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func TestServersTLS(t *testing.T) {
+	clientCert, clientKey, err := generate.KeyPair("client.local", time.Time{})
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if len(req.TLS.PeerCertificates) > 0 {
+			rw.Header().Set("X-Client-Cert", req.TLS.PeerCertificates[0].DNSNames[0])
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	hash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+
+	otherHash := sha256.Sum256([]byte("other"))
+	otherPin := base64.StdEncoding.EncodeToString(otherHash[:])
+
+	testCases := []struct {
+		desc               string
+		serversTLS         *config.ServersTLS
+		expectedReason     string
+		expectedClientCert string
+	}{
+		{
+			desc:           "unknown authority",
+			serversTLS:     &config.ServersTLS{},
+			expectedReason: reasonUnknownAuthority,
+		},
+		{
+			desc:       "custom CA",
+			serversTLS: &config.ServersTLS{CA: ca},
+		},
+		{
+			desc:           "wrong server name",
+			serversTLS:     &config.ServersTLS{CA: ca, ServerName: "foo.com"},
+			expectedReason: reasonHostname,
+		},
+		{
+			desc:       "pinned SPKI",
+			serversTLS: &config.ServersTLS{CA: ca, PinnedSPKI: []string{otherPin, pin}},
+		},
+		{
+			desc:           "pin mismatch",
+			serversTLS:     &config.ServersTLS{CA: ca, PinnedSPKI: []string{otherPin}},
+			expectedReason: reasonPin,
+		},
+		{
+			desc:       "pinned SPKI without CA",
+			serversTLS: &config.ServersTLS{InsecureSkipVerify: true, PinnedSPKI: []string{pin}},
+		},
+		{
+			desc:           "pin mismatch without CA",
+			serversTLS:     &config.ServersTLS{InsecureSkipVerify: true, PinnedSPKI: []string{otherPin}},
+			expectedReason: reasonPin,
+		},
+		{
+			desc:               "client certificate",
+			serversTLS:         &config.ServersTLS{CA: ca, Cert: string(clientCert), Key: string(clientKey)},
+			expectedClientCert: "client.local",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			manager := NewManager(nil, http.DefaultTransport, nil, nil, nil)
+
+			roundTripper, tlsConfig, err := manager.buildRoundTripper("foo", &config.LoadBalancerService{ServersTLS: test.serversTLS})
+			require.NoError(t, err)
+			require.NotNil(t, tlsConfig)
+			assert.Equal(t, roundTripper, manager.getRoundTripper("foo"))
+
+			failures := &testhelpers.CollectingCounter{}
+			roundTripper = &verificationRoundTripper{next: roundTripper, serviceName: "foo", failures: failures}
+
+			resp, err := roundTripper.RoundTrip(testhelpers.MustNewRequest(http.MethodGet, server.URL, nil))
+
+			if len(test.expectedReason) > 0 {
+				require.Error(t, err)
+				assert.Equal(t, float64(1), failures.CounterValue)
+				assert.Equal(t, []string{"reason", test.expectedReason, "backend", "foo"}, failures.LastLabelValues)
+				return
+			}
+
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, test.expectedClientCert, resp.Header.Get("X-Client-Cert"))
+			assert.Zero(t, failures.CounterValue)
+		})
+	}
+}
+
+func TestBuildServersTLSConfig(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		serversTLS *config.ServersTLS
+	}{
+		{
+			desc:       "invalid CA",
+			serversTLS: &config.ServersTLS{CA: "-----BEGIN CERTIFICATE-----\nfoo\n-----END CERTIFICATE-----"},
+		},
+		{
+			desc:       "certificate without key",
+			serversTLS: &config.ServersTLS{Cert: "foo"},
+		},
+		{
+			desc:       "negative session cache size",
+			serversTLS: &config.ServersTLS{SessionCacheSize: -1},
+		},
+		{
+			desc:       "invalid pin",
+			serversTLS: &config.ServersTLS{PinnedSPKI: []string{"Zm9v"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := buildServersTLSConfig(test.serversTLS)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBuildRoundTripperDefault(t *testing.T) {
+	manager := NewManager(nil, http.DefaultTransport, nil, nil, nil)
+
+	roundTripper, tlsConfig, err := manager.buildRoundTripper("foo", &config.LoadBalancerService{})
+	require.NoError(t, err)
+
+	assert.Nil(t, tlsConfig)
+	assert.Equal(t, http.DefaultTransport, roundTripper)
+	assert.Equal(t, http.DefaultTransport, manager.getRoundTripper("foo"))
+}
+
+func TestServersTLSSessionAndALPN(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Proto", req.Proto)
+		rw.Header().Set("X-Resumed", strconv.FormatBool(req.TLS.DidResume))
+		rw.Header().Set("X-Server-Name", req.TLS.ServerName)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	require.NoError(t, http2.ConfigureServer(server.Config, nil))
+	server.TLS = server.Config.TLSConfig
+	server.StartTLS()
+	defer server.Close()
+
+	testCases := []struct {
+		desc            string
+		serversTLS      *config.ServersTLS
+		expectedProto   string
+		expectedResumed string
+	}{
+		{
+			desc:            "defaults",
+			serversTLS:      &config.ServersTLS{InsecureSkipVerify: true},
+			expectedProto:   "HTTP/2.0",
+			expectedResumed: "false",
+		},
+		{
+			desc:            "HTTP/1.1 only",
+			serversTLS:      &config.ServersTLS{InsecureSkipVerify: true, ALPN: []string{"http/1.1"}},
+			expectedProto:   "HTTP/1.1",
+			expectedResumed: "false",
+		},
+		{
+			desc:            "session resumption",
+			serversTLS:      &config.ServersTLS{InsecureSkipVerify: true, SessionCacheSize: 10, ServerName: "backend.internal", ALPN: []string{"http/1.1"}},
+			expectedProto:   "HTTP/1.1",
+			expectedResumed: "true",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			manager := NewManager(nil, http.DefaultTransport, nil, nil, nil)
+
+			roundTripper, _, err := manager.buildRoundTripper("foo", &config.LoadBalancerService{ServersTLS: test.serversTLS})
+			require.NoError(t, err)
+
+			transport, ok := roundTripper.(*http.Transport)
+			require.True(t, ok)
+
+			var resp *http.Response
+			for i := 0; i < 2; i++ {
+				// The idle HTTP/1.1 connections are closed, so the second request opens a new one.
+				transport.CloseIdleConnections()
+
+				resp, err = transport.RoundTrip(testhelpers.MustNewRequest(http.MethodGet, server.URL, nil))
+				require.NoError(t, err)
+				_, _ = ioutil.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+			}
+
+			assert.Equal(t, test.expectedProto, resp.Header.Get("X-Proto"))
+			assert.Equal(t, test.expectedResumed, resp.Header.Get("X-Resumed"))
+			assert.Equal(t, test.serversTLS.ServerName, resp.Header.Get("X-Server-Name"))
+		})
+	}
+}
@@ -2,19 +2,26 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"time"
 
 	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/bluegreen"
 	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/drain"
+	"github.com/containous/traefik/function"
 	"github.com/containous/traefik/healthcheck"
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
 	"github.com/containous/traefik/middlewares/emptybackendhandler"
+	"github.com/containous/traefik/middlewares/servertiming"
 	"github.com/containous/traefik/old/middlewares/pipelining"
+	"github.com/containous/traefik/scheduler"
 	"github.com/containous/traefik/server/cookie"
 	"github.com/vulcand/oxy/forward"
 	"github.com/vulcand/oxy/roundrobin"
@@ -36,22 +43,46 @@ type balancerHandler interface {
 	Next() http.Handler
 }
 
-// NewManager creates a new Manager
-func NewManager(configs map[string]*config.Service, defaultRoundTripper http.RoundTripper) *Manager {
+// NewManager creates a new Manager.
+// The drained servers of the registry are not added to the load balancers, drains can be nil.
+// The blue/green services get their live color from the switches, which can be nil.
+// The function services report the durations of their invocations to the metrics registry, which can be nil.
+func NewManager(configs map[string]*config.Service, defaultRoundTripper http.RoundTripper, drains *drain.Registry, switches *bluegreen.Registry, metricsRegistry metrics.Registry) *Manager {
 	return &Manager{
-		bufferPool:          newBufferPool(),
+		bufferPools:         make(map[int]*bufferPool),
 		defaultRoundTripper: defaultRoundTripper,
 		balancers:           make(map[string][]healthcheck.BalancerHandler),
 		configs:             configs,
+		drains:              drains,
+		switches:            switches,
+		metricsRegistry:     metricsRegistry,
+		schedulers:          make(map[string]*scheduler.Scheduler),
+		lastResorts:         make(map[string][]healthcheck.BalancerHandler),
+		roundTrippers:       make(map[string]http.RoundTripper),
+		healthChecks:        make(map[string]map[string]*healthcheck.BackendConfig),
+		building:            make(map[string]bool),
 	}
 }
 
 // Manager The service manager
 type Manager struct {
-	bufferPool          httputil.BufferPool
+	bufferPools         map[int]*bufferPool
 	defaultRoundTripper http.RoundTripper
 	balancers           map[string][]healthcheck.BalancerHandler
 	configs             map[string]*config.Service
+	drains              *drain.Registry
+	switches            *bluegreen.Registry
+	metricsRegistry     metrics.Registry
+	schedulers          map[string]*scheduler.Scheduler
+	lastResorts         map[string][]healthcheck.BalancerHandler
+	// roundTrippers holds the round trippers of the services with their own TLS configuration.
+	roundTrippers map[string]http.RoundTripper
+	// healthChecks holds the health checks of the backends of each service.
+	healthChecks map[string]map[string]*healthcheck.BackendConfig
+	// building holds the services being built, to detect the failover loops.
+	building map[string]bool
+	// prepared holds the round trippers built ahead of their services by Prepare.
+	prepared map[string]*preparedRoundTripper
 }
 
 // Build Creates a http.Handler for a service configuration.
@@ -61,6 +92,12 @@ func (m *Manager) Build(rootCtx context.Context, serviceName string, responseMod
 	// TODO refactor ?
 	if conf, ok := m.configs[serviceName]; ok {
 		// FIXME Should handle multiple service types
+		if conf.BlueGreen != nil {
+			return m.getBlueGreenServiceHandler(ctx, serviceName, conf.BlueGreen, responseModifier)
+		}
+		if conf.Function != nil {
+			return function.New(serviceName, conf.Function, m.defaultRoundTripper, m.metricsRegistry)
+		}
 		if conf.LoadBalancer != nil {
 			return m.getLoadBalancerServiceHandler(ctx, serviceName, conf.LoadBalancer, responseModifier)
 		}
@@ -76,11 +113,20 @@ func (m *Manager) getLoadBalancerServiceHandler(
 	responseModifier func(*http.Response) error,
 ) (http.Handler, error) {
 
-	fwd, err := m.buildForwarder(service.PassHostHeader, service.ResponseForwarding, responseModifier)
+	roundTripper, tlsConfig, err := m.buildRoundTripper(serviceName, service)
 	if err != nil {
 		return nil, err
 	}
 
+	fwd, err := m.buildForwarder(service.PassHostHeader, service.ResponseForwarding, roundTripper, tlsConfig, responseModifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.drains != nil {
+		fwd = m.drains.Track(serviceName, fwd)
+	}
+
 	fwd = pipelining.NewPipelining(fwd)
 
 	rr, err := roundrobin.New(fwd)
@@ -97,7 +143,117 @@ func (m *Manager) getLoadBalancerServiceHandler(
 	m.balancers[serviceName] = append(m.balancers[serviceName], balancer)
 
 	// Empty (backend with no servers)
-	return emptybackendhandler.New(balancer), nil
+	handler := emptybackendhandler.New(balancer)
+
+	// Only the first load balancers of the service are health checked.
+	var up serverLister = m.balancers[serviceName][0]
+
+	if _, lastResorts := splitServers(service.Servers); len(lastResorts) > 0 {
+		lastResort, err := roundrobin.New(fwd)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.upsertServers(ctx, serviceName, lastResort, lastResorts); err != nil {
+			return nil, fmt.Errorf("error configuring the last resort load balancer for service %s: %v", serviceName, err)
+		}
+		m.lastResorts[serviceName] = append(m.lastResorts[serviceName], lastResort)
+
+		handler = &failoverHandler{
+			serviceName:   serviceName,
+			secondaryName: "its last resort servers",
+			primary:       handler,
+			secondary:     emptybackendhandler.New(lastResort),
+			balancer:      up,
+		}
+		up = unionServers{up, m.lastResorts[serviceName][0]}
+	}
+
+	if service.Failover != nil {
+		handler, err = m.buildFailover(ctx, serviceName, service.Failover, handler, up, responseModifier)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The routers of the service share its scheduler.
+	sched, ok := m.schedulers[serviceName]
+	if !ok {
+		sched = scheduler.New(service.Scheduling)
+		m.schedulers[serviceName] = sched
+	}
+
+	return sched.Wrap(handler), nil
+}
+
+func (m *Manager) buildFailover(
+	ctx context.Context,
+	serviceName string,
+	failover *config.Failover,
+	primary http.Handler,
+	up serverLister,
+	responseModifier func(*http.Response) error,
+) (http.Handler, error) {
+	if failover.Service == "" {
+		return nil, fmt.Errorf("no failover service defined for the service %q", serviceName)
+	}
+
+	secondary, err := m.buildReference(ctx, serviceName, failover.Service, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building the failover service of the service %q: %v", serviceName, err)
+	}
+
+	log.FromContext(ctx).Debugf("Failing over to the service %s", failover.Service)
+
+	return &failoverHandler{
+		serviceName:   serviceName,
+		secondaryName: "the service " + failover.Service,
+		primary:       primary,
+		secondary:     secondary,
+		balancer:      up,
+		failbackDelay: time.Duration(failover.FailbackDelay),
+	}, nil
+}
+
+func (m *Manager) getBlueGreenServiceHandler(
+	ctx context.Context,
+	serviceName string,
+	service *config.BlueGreenService,
+	responseModifier func(*http.Response) error,
+) (http.Handler, error) {
+	switch service.Live {
+	case "", bluegreen.Blue, bluegreen.Green:
+	default:
+		return nil, fmt.Errorf("invalid live color %q for the service %q, expected blue or green", service.Live, serviceName)
+	}
+
+	blue, err := m.buildReference(ctx, serviceName, service.Blue, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building the blue service of the service %q: %v", serviceName, err)
+	}
+
+	green, err := m.buildReference(ctx, serviceName, service.Green, responseModifier)
+	if err != nil {
+		return nil, fmt.Errorf("error building the green service of the service %q: %v", serviceName, err)
+	}
+
+	return m.switches.Switch(serviceName, service.Live).Handler(blue, green), nil
+}
+
+// buildReference builds a service referenced by another one, detecting the reference loops.
+func (m *Manager) buildReference(ctx context.Context, serviceName, reference string, responseModifier func(*http.Response) error) (http.Handler, error) {
+	if reference == "" {
+		return nil, errors.New("no service defined")
+	}
+
+	m.building[serviceName] = true
+	defer delete(m.building, serviceName)
+
+	if m.building[reference] {
+		return nil, fmt.Errorf("reference loop between the services %q and %q", serviceName, reference)
+	}
+
+	return m.Build(ctx, reference, responseModifier)
 }
 
 // LaunchHealthCheck Launches the health checks.
@@ -118,8 +274,8 @@ func (m *Manager) LaunchHealthCheck() {
 		if hcOpts := buildHealthCheckOptions(ctx, balancer, serviceName, service.HealthCheck); hcOpts != nil {
 			log.FromContext(ctx).Debugf("Setting up healthcheck for service %s with %s", serviceName, *hcOpts)
 
-			hcOpts.Transport = m.defaultRoundTripper
-			backendHealthCheck = healthcheck.NewBackendConfig(*hcOpts, serviceName)
+			hcOpts.Transport = m.getRoundTripper(serviceName)
+			backendHealthCheck = m.backendHealthCheck(serviceName, serviceName, *hcOpts)
 		}
 
 		if backendHealthCheck != nil {
@@ -127,10 +283,75 @@ func (m *Manager) LaunchHealthCheck() {
 		}
 	}
 
+	for serviceName, balancers := range m.lastResorts {
+		ctx := log.With(context.Background(), log.Str(log.ServiceName, serviceName))
+
+		service := m.configs[serviceName].LoadBalancer
+
+		_, lastResorts := splitServers(service.Servers)
+		for _, server := range lastResorts {
+			u, err := ParseServerURL(server.URL)
+			if err != nil {
+				continue
+			}
+
+			hc := server.HealthCheck
+			if hc == nil {
+				hc = service.HealthCheck
+			}
+
+			// Each last resort server is health checked on its own, as it can have its own health check.
+			name := serviceName + "@" + server.URL
+			lb := &serverBalancer{BalancerHandler: balancers[0], server: u, weight: server.Weight}
+			if hcOpts := buildHealthCheckOptions(ctx, lb, name, hc); hcOpts != nil {
+				log.FromContext(ctx).Debugf("Setting up healthcheck for last resort server %s with %s", server.URL, *hcOpts)
+
+				hcOpts.Transport = m.getRoundTripper(serviceName)
+				backendConfigs[name] = m.backendHealthCheck(serviceName, name, *hcOpts)
+			}
+		}
+	}
+
 	// FIXME metrics and context
 	healthcheck.GetHealthCheck().SetBackendsConfiguration(context.TODO(), backendConfigs)
 }
 
+// RegisterDrains hands the load balancers built by the manager to the drain registry.
+func (m *Manager) RegisterDrains() {
+	if m.drains == nil {
+		return
+	}
+
+	services := make(map[string]drain.Service)
+	for serviceName, balancers := range m.balancers {
+		// The last resort servers are not in the load balancers of the service, they can't be drained.
+		conf := m.configs[serviceName].LoadBalancer
+		servers, _ := splitServers(conf.Servers)
+		service := drain.Service{Servers: servers, RemovalGracePeriod: time.Duration(conf.RemovalGracePeriod)}
+		if m.metricsRegistry != nil && m.metricsRegistry.IsEnabled() {
+			service.DrainedRequests = m.metricsRegistry.BackendDrainedRequestsCounter()
+		}
+		for _, balancer := range balancers {
+			service.Balancers = append(service.Balancers, balancer)
+		}
+		services[serviceName] = service
+	}
+
+	m.drains.SetServices(services)
+}
+
+// RegisterSwitches forgets the switches of the services which are no longer blue/green services.
+func (m *Manager) RegisterSwitches() {
+	var serviceNames []string
+	for serviceName, conf := range m.configs {
+		if conf.BlueGreen != nil {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+
+	m.switches.SetServices(serviceNames)
+}
+
 func buildHealthCheckOptions(ctx context.Context, lb healthcheck.BalancerHandler, backend string, hc *config.HealthCheck) *healthcheck.Options {
 	if hc == nil || hc.Path == "" {
 		return nil
@@ -226,22 +447,28 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 		}
 	}
 
-	if err := m.upsertServers(ctx, lb, service.Servers); err != nil {
+	servers, _ := splitServers(service.Servers)
+	if err := m.upsertServers(ctx, serviceName, lb, servers); err != nil {
 		return nil, fmt.Errorf("error configuring load balancer for service %s: %v", serviceName, err)
 	}
 
 	return lb, nil
 }
 
-func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHandler, servers []config.Server) error {
+func (m *Manager) upsertServers(ctx context.Context, serviceName string, lb healthcheck.BalancerHandler, servers []config.Server) error {
 	logger := log.FromContext(ctx)
 
 	for name, srv := range servers {
-		u, err := url.Parse(srv.URL)
+		u, err := ParseServerURL(srv.URL)
 		if err != nil {
 			return fmt.Errorf("error parsing server URL %s: %v", srv.URL, err)
 		}
 
+		if m.drains != nil && m.drains.IsDrained(serviceName, srv.URL) {
+			logger.WithField(log.ServerName, name).Debugf("Skipping drained server %s", u)
+			continue
+		}
+
 		logger.WithField(log.ServerName, name).Debugf("Creating server %d at %s with weight %d", name, u, srv.Weight)
 
 		if err := lb.UpsertServer(u, roundrobin.Weight(srv.Weight)); err != nil {
@@ -253,22 +480,30 @@ func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHand
 	return nil
 }
 
-func (m *Manager) buildForwarder(passHostHeader bool, responseForwarding *config.ResponseForwarding, responseModifier func(*http.Response) error) (http.Handler, error) {
+func (m *Manager) buildForwarder(passHostHeader bool, responseForwarding *config.ResponseForwarding, roundTripper http.RoundTripper, tlsConfig *tls.Config, responseModifier func(*http.Response) error) (http.Handler, error) {
 
 	var flushInterval parse.Duration
+	var bufferSize int
 	if responseForwarding != nil {
 		err := flushInterval.Set(responseForwarding.FlushInterval)
 		if err != nil {
 			return nil, fmt.Errorf("error creating flush interval: %v", err)
 		}
+
+		if responseForwarding.BufferSize < 0 {
+			return nil, fmt.Errorf("invalid buffer size %d", responseForwarding.BufferSize)
+		}
+		bufferSize = responseForwarding.BufferSize
 	}
 
-	return forward.New(
+	fwd, err := forward.New(
 		forward.Stream(true),
 		forward.PassHostHeader(passHostHeader),
-		forward.RoundTripper(m.defaultRoundTripper),
+		forward.RoundTripper(roundTripper),
+		// The websocket connections don't go through the round tripper, they need the TLS configuration of the servers too.
+		forward.WebsocketTLSClientConfig(tlsConfig),
 		forward.ResponseModifier(responseModifier),
-		forward.BufferPool(m.bufferPool),
+		forward.BufferPool(m.getBufferPool(bufferSize)),
 		forward.StreamingFlushInterval(time.Duration(flushInterval)),
 		forward.WebsocketConnectionClosedHook(func(req *http.Request, conn net.Conn) {
 			server := req.Context().Value(http.ServerContextKey).(*http.Server)
@@ -280,4 +515,31 @@ func (m *Manager) buildForwarder(passHostHeader bool, responseForwarding *config
 			}
 		}),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return servertiming.TraceForwarder(&eventStreamHandler{next: &websocketHandler{next: fwd}}), nil
+}
+
+// newTransport creates a transport with the settings of the default transport.
+func newTransport(defaultRoundTripper http.RoundTripper) *http.Transport {
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if defaultTransport, ok := defaultRoundTripper.(*http.Transport); ok {
+		transport.Proxy = defaultTransport.Proxy
+		transport.DialContext = defaultTransport.DialContext
+		transport.MaxIdleConnsPerHost = defaultTransport.MaxIdleConnsPerHost
+		transport.IdleConnTimeout = defaultTransport.IdleConnTimeout
+		transport.TLSHandshakeTimeout = defaultTransport.TLSHandshakeTimeout
+		transport.ExpectContinueTimeout = defaultTransport.ExpectContinueTimeout
+		transport.ResponseHeaderTimeout = defaultTransport.ResponseHeaderTimeout
+	}
+
+	return transport
 }
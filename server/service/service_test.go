@@ -132,7 +132,7 @@ func TestGetLoadBalancer(t *testing.T) {
 }
 
 func TestGetLoadBalancerServiceHandler(t *testing.T) {
-	sm := NewManager(nil, http.DefaultTransport)
+	sm := NewManager(nil, http.DefaultTransport, nil, nil, nil)
 
 	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-From", "first")
@@ -325,3 +325,19 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 }
 
 // FIXME Add healthcheck tests
+
+func TestGetBufferPool(t *testing.T) {
+	manager := NewManager(nil, http.DefaultTransport, nil, nil, nil)
+
+	pool := manager.getBufferPool(0)
+	assert.Len(t, pool.Get(), defaultBufferSize)
+	assert.Equal(t, pool, manager.getBufferPool(defaultBufferSize))
+
+	large := manager.getBufferPool(256 * 1024)
+	assert.NotEqual(t, pool, large)
+	assert.Len(t, large.Get(), 256*1024)
+
+	// The buffers smaller than the size of the pool are not kept.
+	large.Put(make([]byte, 1024))
+	assert.Len(t, large.Get(), 256*1024)
+}
@@ -0,0 +1,51 @@
+package tcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/tcp"
+)
+
+// Manager builds the TCP handlers of the services.
+type Manager struct {
+	configs map[string]*config.TCPService
+}
+
+// NewManager creates a new Manager.
+func NewManager(configs map[string]*config.TCPService) *Manager {
+	return &Manager{
+		configs: configs,
+	}
+}
+
+// BuildTCP creates a tcp.Handler for a service configuration.
+func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Handler, error) {
+	ctx := log.With(rootCtx, log.Str(log.ServiceName, serviceName))
+
+	conf, ok := m.configs[serviceName]
+	if !ok {
+		return nil, fmt.Errorf("the service %q does not exist", serviceName)
+	}
+	if conf.LoadBalancer == nil {
+		return nil, fmt.Errorf("the service %q doesn't have any TCP load balancer", serviceName)
+	}
+
+	logger := log.FromContext(ctx)
+
+	loadBalancer := tcp.NewRRLoadBalancer()
+	for name, server := range conf.LoadBalancer.Servers {
+		handler, err := tcp.NewProxy(server.Address, conf.LoadBalancer.Splice, conf.LoadBalancer.ProxyProtocol)
+		if err != nil {
+			logger.Errorf("In service %q server %q: %v", serviceName, server.Address, err)
+			continue
+		}
+
+		logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s", name, server.Address)
+		loadBalancer.AddServer(handler)
+	}
+
+	return loadBalancer, nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/containous/traefik/config"
+)
+
+const (
+	unixScheme = "unix"
+
+	// unixSocketHostSuffix ends the hosts standing for the Unix sockets in the server URLs.
+	unixSocketHostSuffix = ".sock"
+
+	// unixSocketHostHeader is sent to the servers behind a Unix socket, when the host of the request is not passed.
+	unixSocketHostHeader = "localhost"
+)
+
+// ParseServerURL parses the URL of a server.
+// A Unix socket URL, unix:///path/to.sock, is turned into an HTTP URL with a host standing for the socket,
+// which the round tripper of the service dials.
+func ParseServerURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != unixScheme {
+		return u, nil
+	}
+
+	if len(u.Path) == 0 {
+		return nil, errors.New("missing the path of the Unix socket")
+	}
+
+	return &url.URL{Scheme: "http", Host: unixSocketHost(u.Path)}, nil
+}
+
+// unixSocketHost returns the host standing for the Unix socket in the server URLs.
+func unixSocketHost(path string) string {
+	hash := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(hash[:8]) + unixSocketHostSuffix
+}
+
+// buildUnixSocketTransports creates the transports of the Unix socket servers, by the host standing for their socket.
+func buildUnixSocketTransports(defaultRoundTripper http.RoundTripper, servers []config.Server) (map[string]http.RoundTripper, error) {
+	sockets := make(map[string]http.RoundTripper)
+
+	for _, server := range servers {
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		if u.Scheme != unixScheme || len(u.Path) == 0 {
+			continue
+		}
+
+		path := u.Path
+		transport := newTransport(defaultRoundTripper)
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			dialer := &net.Dialer{}
+			return dialer.DialContext(ctx, unixScheme, path)
+		}
+
+		sockets[unixSocketHost(path)] = transport
+	}
+
+	return sockets, nil
+}
+
+// unixSocketRoundTripper sends the requests to the Unix socket servers through their transport,
+// and the other requests to the next round tripper.
+type unixSocketRoundTripper struct {
+	next    http.RoundTripper
+	sockets map[string]http.RoundTripper
+}
+
+func (rt *unixSocketRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport, ok := rt.sockets[req.URL.Host]
+	if !ok {
+		return rt.next.RoundTrip(req)
+	}
+
+	if req.Host != req.URL.Host && len(req.Host) > 0 {
+		return transport.RoundTrip(req)
+	}
+
+	// The host standing for the socket means nothing to the server.
+	outReq := new(http.Request)
+	*outReq = *req
+	outReq.Host = unixSocketHostHeader
+	return transport.RoundTrip(outReq)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocketServers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-unix")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	socket := filepath.Join(dir, "backend.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Host", req.Host)
+		_, _ = rw.Write([]byte("unix"))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	tcp := httptest.NewServer(handlerNamed("tcp"))
+	defer tcp.Close()
+
+	testCases := []struct {
+		desc           string
+		passHostHeader bool
+		expectedHost   string
+	}{
+		{
+			desc:         "host of the socket",
+			expectedHost: unixSocketHostHeader,
+		},
+		{
+			desc:           "host of the request",
+			passHostHeader: true,
+			expectedHost:   "callme",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			configs := map[string]*config.Service{
+				"unix": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:         "wrr",
+						PassHostHeader: test.passHostHeader,
+						Servers:        []config.Server{{URL: "unix://" + socket, Weight: 1}},
+					},
+				},
+				"tcp": {
+					LoadBalancer: &config.LoadBalancerService{
+						Method:  "wrr",
+						Servers: []config.Server{{URL: tcp.URL, Weight: 1}},
+					},
+				},
+			}
+
+			manager := NewManager(configs, http.DefaultTransport, nil, nil, nil)
+
+			handler, err := manager.Build(context.Background(), "unix", nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "unix", recorder.Body.String())
+			assert.Equal(t, test.expectedHost, recorder.Header().Get("X-Host"))
+
+			// The services without Unix socket servers keep the default round tripper.
+			_, err = manager.Build(context.Background(), "tcp", nil)
+			require.NoError(t, err)
+			assert.Equal(t, http.DefaultTransport, manager.getRoundTripper("tcp"))
+		})
+	}
+}
+
+func TestParseServerURL(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		rawURL      string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			desc:     "HTTP",
+			rawURL:   "http://10.0.0.1:80",
+			expected: "http://10.0.0.1:80",
+		},
+		{
+			desc:     "Unix socket",
+			rawURL:   "unix:///var/run/app.sock",
+			expected: "http://" + unixSocketHost("/var/run/app.sock"),
+		},
+		{
+			desc:        "Unix socket without path",
+			rawURL:      "unix://",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := ParseServerURL(test.rawURL)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, u.String())
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/containous/traefik/watchdog"
+	"github.com/vulcand/oxy/forward"
+)
+
+// websocketHandler labels the goroutines proxying a websocket connection, for the watchdog to count them.
+type websocketHandler struct {
+	next http.Handler
+}
+
+func (h *websocketHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !forward.IsWebsocketRequest(req) {
+		h.next.ServeHTTP(rw, req)
+		return
+	}
+
+	watchdog.Do(req.Context(), watchdog.SubsystemWebsockets, func(context.Context) {
+		h.next.ServeHTTP(rw, req)
+	})
+}
@@ -0,0 +1,328 @@
+package slo
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/middlewares/servertiming"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+)
+
+const (
+	// DefaultObjective is the ratio of the requests of a router expected under its latency threshold, when none is given.
+	DefaultObjective = 0.99
+	// DefaultWindow is the duration over which the burn rate of a router is computed, when none is given.
+	DefaultWindow = time.Hour
+
+	// bucketCount is the number of buckets the window of a router is split into.
+	bucketCount = 60
+)
+
+// Config holds the slow log configuration.
+type Config struct {
+	FilePath string `description:"Slow log file path" export:"true"`
+}
+
+// Entry is a request slower than the latency threshold of its router, with the breakdown of its processing time.
+// The queue duration is only known when the entry point of the request times the requests.
+type Entry struct {
+	Time       time.Time     `json:"time"`
+	Router     string        `json:"router"`
+	Method     string        `json:"method"`
+	Host       string        `json:"host"`
+	Path       string        `json:"path"`
+	RemoteAddr string        `json:"remoteAddr,omitempty"`
+	Status     int           `json:"status"`
+	Duration   time.Duration `json:"duration"`
+	Threshold  time.Duration `json:"threshold"`
+	Queue      time.Duration `json:"queue"`
+	Middleware time.Duration `json:"middleware"`
+	Connect    time.Duration `json:"connect,omitempty"`
+	TTFB       time.Duration `json:"ttfb,omitempty"`
+	Transfer   time.Duration `json:"transfer,omitempty"`
+}
+
+// Logger writes the slow requests as JSON lines.
+// A nil Logger records nothing.
+type Logger struct {
+	lock   sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+// NewLogger creates a Logger writing to the writer.
+func NewLogger(writer io.Writer) *Logger {
+	return &Logger{writer: writer}
+}
+
+// Open creates a Logger appending to the file, nil if the configuration is nil.
+func Open(config *Config) (*Logger, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	if len(config.FilePath) == 0 {
+		return nil, errors.New("no slow log file path")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.FilePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create slow log path %s: %v", filepath.Dir(config.FilePath), err)
+	}
+
+	file, err := os.OpenFile(config.FilePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0664)
+	if err != nil {
+		return nil, fmt.Errorf("error opening slow log file %s: %v", config.FilePath, err)
+	}
+
+	logger := NewLogger(file)
+	logger.closer = file
+	return logger, nil
+}
+
+// Record writes the entry.
+func (l *Logger) Record(entry Entry) {
+	if l == nil {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.WithoutContext().Errorf("Unable to record the slow request of the router %s: %v", entry.Router, err)
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, err := l.writer.Write(append(data, '\n')); err != nil {
+		log.WithoutContext().Errorf("Unable to write the slow request of the router %s: %v", entry.Router, err)
+	}
+}
+
+// Close closes the file of the Logger.
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// Registry tracks the latency objectives of the routers.
+// The windows of the routers are kept across the configuration reloads, as long as their duration doesn't change.
+// A nil Registry tracks nothing.
+type Registry struct {
+	logger   *Logger
+	burnRate gokitmetrics.Gauge
+
+	lock    sync.Mutex
+	windows map[string]*window
+}
+
+// NewRegistry creates a new Registry, logging the slow requests to the logger,
+// and reporting the burn rates of the routers to the gauge, if any.
+func NewRegistry(logger *Logger, burnRate gokitmetrics.Gauge) *Registry {
+	return &Registry{
+		logger:   logger,
+		burnRate: burnRate,
+		windows:  make(map[string]*window),
+	}
+}
+
+// Wrap returns a handler tracking the latency objective of the router, next if the router has none.
+func (r *Registry) Wrap(routerName string, conf *config.RouterSLO, next http.Handler) (http.Handler, error) {
+	if r == nil || conf == nil {
+		return next, nil
+	}
+
+	threshold := time.Duration(conf.Latency)
+	if threshold <= 0 {
+		return nil, fmt.Errorf("invalid latency threshold %s", threshold)
+	}
+
+	objective := conf.Objective
+	if objective == 0 {
+		objective = DefaultObjective
+	}
+	if objective <= 0 || objective >= 1 {
+		return nil, fmt.Errorf("invalid objective %v, expected a ratio between 0 and 1", conf.Objective)
+	}
+
+	duration := time.Duration(conf.Window)
+	if duration == 0 {
+		duration = DefaultWindow
+	}
+	if duration < bucketCount*time.Millisecond {
+		return nil, fmt.Errorf("invalid window %s", duration)
+	}
+
+	return &handler{
+		next:       next,
+		routerName: routerName,
+		threshold:  threshold,
+		budget:     1 - objective,
+		window:     r.window(routerName, duration),
+		logger:     r.logger,
+		burnRate:   r.burnRate,
+	}, nil
+}
+
+func (r *Registry) window(routerName string, duration time.Duration) *window {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	w, ok := r.windows[routerName]
+	if !ok || w.width != duration/bucketCount {
+		w = &window{width: duration / bucketCount}
+		r.windows[routerName] = w
+	}
+	return w
+}
+
+type bucket struct {
+	index int64
+	total int64
+	slow  int64
+}
+
+// window counts the requests of a router, and the slow ones, over a sliding window of buckets.
+type window struct {
+	lock    sync.Mutex
+	width   time.Duration
+	buckets [bucketCount]bucket
+}
+
+// add counts a request, and returns the ratio of the slow requests over the window.
+func (w *window) add(now time.Time, slow bool) float64 {
+	index := now.UnixNano() / int64(w.width)
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	b := &w.buckets[index%bucketCount]
+	if b.index != index {
+		*b = bucket{index: index}
+	}
+	b.total++
+	if slow {
+		b.slow++
+	}
+
+	var total, slowCount int64
+	for _, b := range w.buckets {
+		if b.index > index-bucketCount {
+			total += b.total
+			slowCount += b.slow
+		}
+	}
+	return float64(slowCount) / float64(total)
+}
+
+// handler tracks the latency objective of a router.
+type handler struct {
+	next       http.Handler
+	routerName string
+	threshold  time.Duration
+	budget     float64
+	window     *window
+	logger     *Logger
+	burnRate   gokitmetrics.Gauge
+}
+
+func (h *handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx, timing := servertiming.Track(req.Context())
+	start := time.Now()
+
+	recorder := newResponseRecorder(rw)
+	h.next.ServeHTTP(recorder, req.WithContext(ctx))
+
+	end := time.Now()
+	duration := end.Sub(start)
+	slow := duration > h.threshold
+
+	ratio := h.window.add(end, slow)
+	if h.burnRate != nil {
+		h.burnRate.With("router", h.routerName).Set(ratio / h.budget)
+	}
+
+	if !slow {
+		return
+	}
+
+	durations := timing.Durations()
+	h.logger.Record(Entry{
+		Time:       start.UTC(),
+		Router:     h.routerName,
+		Method:     req.Method,
+		Host:       req.Host,
+		Path:       req.URL.Path,
+		RemoteAddr: req.RemoteAddr,
+		Status:     recorder.statusCode,
+		Duration:   duration,
+		Threshold:  h.threshold,
+		Queue:      durations.Queue,
+		Middleware: durations.Middleware,
+		Connect:    durations.Connect,
+		TTFB:       durations.TTFB,
+		Transfer:   durations.Transfer,
+	})
+}
+
+// responseRecorder captures the status code of the response.
+type responseRecorder struct {
+	rw         http.ResponseWriter
+	statusCode int
+}
+
+func newResponseRecorder(rw http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{rw: rw, statusCode: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.rw.Header()
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.rw.Write(b)
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (r *responseRecorder) WriteHeader(status int) {
+	r.rw.WriteHeader(status)
+	r.statusCode = status
+}
+
+// Hijack hijacks the connection.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.rw.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("not a hijacker: %T", r.rw)
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone
+// away.
+func (r *responseRecorder) CloseNotify() <-chan bool {
+	if c, ok := r.rw.(http.CloseNotifier); ok {
+		return c.CloseNotify()
+	}
+	return nil
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorder) Flush() {
+	if f, ok := r.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
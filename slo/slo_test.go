@@ -0,0 +1,117 @@
+package slo
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/config"
+	"github.com/containous/traefik/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryWrapErrors(t *testing.T) {
+	testCases := []struct {
+		desc string
+		conf config.RouterSLO
+	}{
+		{
+			desc: "no latency",
+			conf: config.RouterSLO{},
+		},
+		{
+			desc: "objective over 1",
+			conf: config.RouterSLO{Latency: parse.Duration(time.Second), Objective: 1.5},
+		},
+		{
+			desc: "negative objective",
+			conf: config.RouterSLO{Latency: parse.Duration(time.Second), Objective: -0.5},
+		},
+		{
+			desc: "window too short",
+			conf: config.RouterSLO{Latency: parse.Duration(time.Second), Window: parse.Duration(time.Millisecond)},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewRegistry(nil, nil).Wrap("router", &test.conf, http.NotFoundHandler())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRegistryWrap(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	gauge := &testhelpers.CollectingGauge{}
+	registry := NewRegistry(NewLogger(buffer), gauge)
+
+	var delay time.Duration
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(delay)
+		rw.WriteHeader(http.StatusAccepted)
+	})
+
+	handler, err := registry.Wrap("api", &config.RouterSLO{Latency: parse.Duration(20 * time.Millisecond), Objective: 0.9}, next)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/fast", nil))
+	}
+	assert.Empty(t, buffer.String())
+	assert.Equal(t, []string{"router", "api"}, gauge.LastLabelValues)
+	assert.Equal(t, float64(0), gauge.GaugeValue)
+
+	delay = 30 * time.Millisecond
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/slow", nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	// 1 slow request out of 4, for a budget of 10%.
+	assert.InDelta(t, 2.5, gauge.GaugeValue, 0.0001)
+
+	entry := Entry{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, "api", entry.Router)
+	assert.Equal(t, http.MethodGet, entry.Method)
+	assert.Equal(t, "/slow", entry.Path)
+	assert.Equal(t, http.StatusAccepted, entry.Status)
+	assert.Equal(t, 20*time.Millisecond, entry.Threshold)
+	assert.True(t, entry.Duration >= delay)
+	assert.True(t, entry.Middleware >= delay)
+
+	// The window of the router is kept when its handler is rebuilt.
+	handler, err = registry.Wrap("api", &config.RouterSLO{Latency: parse.Duration(time.Second), Objective: 0.9}, next)
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/slow", nil))
+	assert.InDelta(t, 2, gauge.GaugeValue, 0.0001)
+}
+
+func TestWindow(t *testing.T) {
+	w := &window{width: time.Second}
+	now := time.Unix(1000, 0)
+
+	assert.Equal(t, float64(1), w.add(now, true))
+	assert.Equal(t, 0.5, w.add(now.Add(30*time.Second), false))
+
+	// The first request leaves the window.
+	assert.Equal(t, float64(0), w.add(now.Add(bucketCount*time.Second), false))
+}
+
+func TestNilRegistry(t *testing.T) {
+	var registry *Registry
+
+	next := http.NotFoundHandler()
+	handler, err := registry.Wrap("api", &config.RouterSLO{}, next)
+	require.NoError(t, err)
+	assert.NotNil(t, handler)
+}
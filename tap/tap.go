@@ -0,0 +1,368 @@
+package tap
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCount is the number of requests captured when none is given.
+	DefaultCount = 10
+	// MaxCount is the maximum number of requests a tap can capture.
+	MaxCount = 1000
+	// DefaultMaxBodyBytes is the size of the captured bodies when none is given.
+	DefaultMaxBodyBytes = 4096
+)
+
+var (
+	// ErrRouterNotFound is returned when tapping an unknown router.
+	ErrRouterNotFound = errors.New("router not found")
+	// ErrTapNotFound is returned when getting or stopping a router which is not tapped.
+	ErrTapNotFound = errors.New("tap not found")
+)
+
+// Request is a request captured by a tap.
+type Request struct {
+	StartTime             time.Time     `json:"startTime"`
+	Method                string        `json:"method"`
+	URL                   string        `json:"url"`
+	Proto                 string        `json:"proto"`
+	Host                  string        `json:"host"`
+	RemoteAddr            string        `json:"remoteAddr"`
+	Headers               http.Header   `json:"headers,omitempty"`
+	Body                  string        `json:"body,omitempty"`
+	BodyTruncated         bool          `json:"bodyTruncated,omitempty"`
+	Status                int           `json:"status"`
+	ResponseHeaders       http.Header   `json:"responseHeaders,omitempty"`
+	ResponseBody          string        `json:"responseBody,omitempty"`
+	ResponseBodyTruncated bool          `json:"responseBodyTruncated,omitempty"`
+	ResponseSize          int64         `json:"responseSize"`
+	TimeToFirstByte       time.Duration `json:"timeToFirstByte"`
+	Duration              time.Duration `json:"duration"`
+}
+
+// Tap captures the next requests of a router.
+type Tap struct {
+	Router       string    `json:"router"`
+	StartTime    time.Time `json:"startTime"`
+	Count        int       `json:"count"`
+	MaxBodyBytes int64     `json:"maxBodyBytes"`
+	Active       bool      `json:"active"`
+	Requests     []Request `json:"requests"`
+
+	remaining int
+	inFlight  int
+}
+
+// Registry keeps track of the taps started through the API.
+// A tap captures the next Count requests of a router, and is then disabled: its requests stay available until it is removed.
+type Registry struct {
+	lock    sync.RWMutex
+	taps    map[string]*Tap
+	routers map[string]bool
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		taps:    make(map[string]*Tap),
+		routers: make(map[string]bool),
+	}
+}
+
+// SetRouters replaces the routers which can be tapped, when a new configuration is applied.
+func (r *Registry) SetRouters(routers []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.routers = make(map[string]bool)
+	for _, router := range routers {
+		r.routers[router] = true
+	}
+}
+
+// Start starts capturing the next count requests of the router, replacing its previous tap.
+func (r *Registry) Start(routerName string, count int, maxBodyBytes int64) error {
+	if count <= 0 {
+		count = DefaultCount
+	}
+	if count > MaxCount {
+		return fmt.Errorf("a tap captures at most %d requests", MaxCount)
+	}
+	if maxBodyBytes < 0 {
+		return fmt.Errorf("invalid max body bytes: %d", maxBodyBytes)
+	}
+	if maxBodyBytes == 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.routers[routerName] {
+		return ErrRouterNotFound
+	}
+
+	r.taps[routerName] = &Tap{
+		Router:       routerName,
+		StartTime:    time.Now().UTC(),
+		Count:        count,
+		MaxBodyBytes: maxBodyBytes,
+		Active:       true,
+		Requests:     []Request{},
+		remaining:    count,
+	}
+
+	return nil
+}
+
+// Remove stops the tap of the router and forgets its requests.
+func (r *Registry) Remove(routerName string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.taps[routerName]; !ok {
+		return ErrTapNotFound
+	}
+	delete(r.taps, routerName)
+
+	return nil
+}
+
+// Get returns a copy of the tap of the router.
+func (r *Registry) Get(routerName string) (Tap, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	t, ok := r.taps[routerName]
+	if !ok {
+		return Tap{}, ErrTapNotFound
+	}
+
+	tap := *t
+	tap.Requests = append([]Request{}, t.Requests...)
+	return tap, nil
+}
+
+// List returns the taps, without their requests, sorted by router.
+func (r *Registry) List() []Tap {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	taps := []Tap{}
+	for _, t := range r.taps {
+		tap := *t
+		tap.Requests = nil
+		taps = append(taps, tap)
+	}
+
+	sort.Slice(taps, func(i, j int) bool {
+		return taps[i].Router < taps[j].Router
+	})
+
+	return taps
+}
+
+// reserve returns the tap of the router if it still has to capture a request.
+func (r *Registry) reserve(routerName string) *Tap {
+	// Most requests are not tapped: a read lock is enough to find it out.
+	r.lock.RLock()
+	t, ok := r.taps[routerName]
+	active := ok && t.remaining > 0
+	r.lock.RUnlock()
+
+	if !active {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if t.remaining <= 0 {
+		return nil
+	}
+	t.remaining--
+	t.inFlight++
+
+	return t
+}
+
+func (r *Registry) record(t *Tap, request Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	t.Requests = append(t.Requests, request)
+	t.inFlight--
+	t.Active = t.remaining > 0 || t.inFlight > 0
+}
+
+// Wrap returns a handler capturing the requests of the router while it is tapped.
+func (r *Registry) Wrap(routerName string, next http.Handler) http.Handler {
+	if r == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t := r.reserve(routerName)
+		if t == nil {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		start := time.Now()
+		request := Request{
+			StartTime:  start.UTC(),
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			Proto:      req.Proto,
+			Host:       req.Host,
+			RemoteAddr: req.RemoteAddr,
+			Headers:    cloneHeader(req.Header),
+		}
+
+		body, truncated, err := peekBody(req, t.MaxBodyBytes)
+		if err != nil {
+			request.Body = fmt.Sprintf("unable to read the body: %v", err)
+		} else {
+			request.Body, request.BodyTruncated = string(body), truncated
+		}
+
+		recorder := newRecorder(rw, t.MaxBodyBytes, start)
+		defer func() {
+			request.Status = recorder.status
+			if request.Status == 0 {
+				request.Status = http.StatusOK
+			}
+			request.ResponseHeaders = cloneHeader(rw.Header())
+			request.ResponseBody = recorder.body.String()
+			request.ResponseBodyTruncated = recorder.truncated
+			request.ResponseSize = recorder.size
+			request.TimeToFirstByte = recorder.timeToFirstByte
+			request.Duration = time.Since(start)
+
+			r.record(t, request)
+		}()
+
+		next.ServeHTTP(recorder, req)
+	})
+}
+
+// peekBody reads at most maxBytes of the body, and restores the body for the next handlers.
+func peekBody(req *http.Request, maxBytes int64) ([]byte, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, false, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if seeker, ok := req.Body.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, false, err
+		}
+	} else {
+		req.Body = &readCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), req.Body),
+			Closer: req.Body,
+		}
+	}
+
+	if int64(len(body)) > maxBytes {
+		return body[:maxBytes], true, nil
+	}
+	return body, false, nil
+}
+
+func cloneHeader(header http.Header) http.Header {
+	clone := make(http.Header, len(header))
+	for name, values := range header {
+		clone[name] = append([]string{}, values...)
+	}
+	return clone
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// recorder records the status, the beginning of the body and the timings of a response.
+type recorder struct {
+	http.ResponseWriter
+	maxBodyBytes    int64
+	start           time.Time
+	status          int
+	body            bytes.Buffer
+	truncated       bool
+	size            int64
+	timeToFirstByte time.Duration
+}
+
+func newRecorder(rw http.ResponseWriter, maxBodyBytes int64, start time.Time) *recorder {
+	return &recorder{ResponseWriter: rw, maxBodyBytes: maxBodyBytes, start: start}
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.timeToFirstByte = time.Since(r.start)
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if remaining := r.maxBodyBytes - int64(r.body.Len()); remaining > 0 {
+		if int64(len(p)) > remaining {
+			r.body.Write(p[:remaining])
+			r.truncated = true
+		} else {
+			r.body.Write(p)
+		}
+	} else if len(p) > 0 {
+		r.truncated = true
+	}
+
+	n, err := r.ResponseWriter.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Flush sends any buffered data to the client.
+func (r *recorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (r *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// CloseNotify returns a channel that receives at most a single value (true)
+// when the client connection has gone away.
+func (r *recorder) CloseNotify() <-chan bool {
+	if notifier, ok := r.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(<-chan bool)
+}
@@ -0,0 +1,124 @@
+package tap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Start(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		router               string
+		count                int
+		maxBodyBytes         int64
+		expectedErr          error
+		expectedCount        int
+		expectedMaxBodyBytes int64
+	}{
+		{
+			desc:                 "default values",
+			router:               "foo",
+			expectedCount:        DefaultCount,
+			expectedMaxBodyBytes: DefaultMaxBodyBytes,
+		},
+		{
+			desc:                 "custom values",
+			router:               "foo",
+			count:                3,
+			maxBodyBytes:         12,
+			expectedCount:        3,
+			expectedMaxBodyBytes: 12,
+		},
+		{
+			desc:        "unknown router",
+			router:      "bar",
+			expectedErr: ErrRouterNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			registry := NewRegistry()
+			registry.SetRouters([]string{"foo"})
+
+			err := registry.Start(test.router, test.count, test.maxBodyBytes)
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+
+			tap, err := registry.Get(test.router)
+			require.NoError(t, err)
+
+			assert.True(t, tap.Active)
+			assert.Equal(t, test.expectedCount, tap.Count)
+			assert.Equal(t, test.expectedMaxBodyBytes, tap.MaxBodyBytes)
+		})
+	}
+}
+
+func TestRegistry_Wrap(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetRouters([]string{"foo", "bar"})
+	require.NoError(t, registry.Start("foo", 2, 5))
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		rw.Header().Set("X-Foo", "bar")
+		rw.WriteHeader(http.StatusCreated)
+		_, _ = rw.Write(body)
+	})
+
+	foo := registry.Wrap("foo", next)
+	bar := registry.Wrap("bar", next)
+
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		foo.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/foo", strings.NewReader("hello world")))
+
+		// The captured request and response bodies are truncated, not the forwarded ones.
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "hello world", recorder.Body.String())
+
+		bar.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bar", nil))
+	}
+
+	tap, err := registry.Get("foo")
+	require.NoError(t, err)
+
+	assert.False(t, tap.Active)
+	require.Len(t, tap.Requests, 2)
+
+	request := tap.Requests[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "/foo", request.URL)
+	assert.Equal(t, "hello", request.Body)
+	assert.True(t, request.BodyTruncated)
+	assert.Equal(t, http.StatusCreated, request.Status)
+	assert.Equal(t, "bar", request.ResponseHeaders.Get("X-Foo"))
+	assert.Equal(t, "hello", request.ResponseBody)
+	assert.True(t, request.ResponseBodyTruncated)
+	assert.EqualValues(t, len("hello world"), request.ResponseSize)
+
+	_, err = registry.Get("bar")
+	assert.Equal(t, ErrTapNotFound, err)
+}
+
+func TestRegistry_WrapNil(t *testing.T) {
+	var registry *Registry
+
+	next := http.NotFoundHandler()
+	assert.NotNil(t, registry.Wrap("foo", next))
+}
@@ -0,0 +1,107 @@
+package tcp
+
+import (
+	"io"
+	"net"
+	"sync"
+)
+
+const copyBufferSize = 32 * 1024
+
+var copyBuffers = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, copyBufferSize)
+		return &buf
+	},
+}
+
+// UnwrapConn is implemented by the connections wrapping another one without altering its bytes,
+// so that the bytes of the connection they wrap can be spliced.
+type UnwrapConn interface {
+	Unwrap() net.Conn
+}
+
+// copyConn copies the bytes of src to dst.
+// With splice, the bytes are copied by the kernel when both connections are, or wrap, TCP connections, on Linux.
+// Otherwise, they are copied through a pooled buffer.
+func copyConn(dst, src net.Conn, splice bool) (int64, error) {
+	if splice {
+		if dstConn := unwrapTCPDst(dst); dstConn != nil {
+			written, srcConn, err := unwrapTCPConn(dstConn, src)
+			if err != nil {
+				return written, err
+			}
+			if srcConn != nil {
+				n, err := dstConn.ReadFrom(srcConn)
+				return written + n, err
+			}
+		}
+	}
+
+	buf := copyBuffers.Get().(*[]byte)
+	defer copyBuffers.Put(buf)
+
+	// Hiding ReadFrom and WriteTo makes the copy use the buffer, instead of allocating its own.
+	return io.CopyBuffer(writerOnly{dst}, readerOnly{src}, *buf)
+}
+
+// unwrapTCPConn returns the TCP connection src wraps, if any, once the bytes peeked from it are written to dst.
+func unwrapTCPConn(dst io.Writer, src net.Conn) (int64, *net.TCPConn, error) {
+	var written int64
+	for {
+		switch conn := src.(type) {
+		case *net.TCPConn:
+			return written, conn, nil
+
+		case *Conn:
+			if buffered := conn.Peeked.Buffered(); buffered > 0 {
+				peeked, err := conn.Peeked.Peek(buffered)
+				if err != nil {
+					return written, nil, err
+				}
+
+				n, err := dst.Write(peeked)
+				written += int64(n)
+				if err != nil {
+					return written, nil, err
+				}
+
+				if _, err = conn.Peeked.Discard(n); err != nil {
+					return written, nil, err
+				}
+			}
+			src = conn.Conn
+
+		case UnwrapConn:
+			src = conn.Unwrap()
+
+		default:
+			return written, nil, nil
+		}
+	}
+}
+
+// unwrapTCPDst returns the TCP connection dst wraps, if any.
+// The bytes written to a peeked connection go to the connection it wraps, so it is unwrapped as well.
+func unwrapTCPDst(dst net.Conn) *net.TCPConn {
+	for {
+		switch conn := dst.(type) {
+		case *net.TCPConn:
+			return conn
+
+		case UnwrapConn:
+			dst = conn.Unwrap()
+
+		default:
+			return nil
+		}
+	}
+}
+
+type writerOnly struct {
+	io.Writer
+}
+
+type readerOnly struct {
+	io.Reader
+}
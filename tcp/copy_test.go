@@ -0,0 +1,144 @@
+package tcp
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type wrappedConn struct {
+	net.Conn
+}
+
+func (c *wrappedConn) Unwrap() net.Conn {
+	return c.Conn
+}
+
+// tcpConnPair returns the two ends of a TCP connection.
+func tcpConnPair(t *testing.T) (*net.TCPConn, *net.TCPConn) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, errAccept := listener.Accept()
+		if errAccept != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+	}()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	server, ok := <-accepted
+	require.True(t, ok)
+
+	return client.(*net.TCPConn), server.(*net.TCPConn)
+}
+
+func TestCopyConn(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		splice  bool
+		wrap    func(conn net.Conn) net.Conn
+		wrapDst func(conn net.Conn) net.Conn
+	}{
+		{
+			desc: "buffer",
+			wrap: func(conn net.Conn) net.Conn { return conn },
+		},
+		{
+			desc:   "splice",
+			splice: true,
+			wrap:   func(conn net.Conn) net.Conn { return conn },
+		},
+		{
+			desc:   "splice of wrapped connection",
+			splice: true,
+			wrap:   func(conn net.Conn) net.Conn { return &wrappedConn{Conn: conn} },
+		},
+		{
+			desc:   "splice of peeked connection",
+			splice: true,
+			wrap: func(conn net.Conn) net.Conn {
+				br := bufio.NewReader(conn)
+				_, _ = br.Peek(5)
+				return &Conn{Conn: &wrappedConn{Conn: conn}, Peeked: br}
+			},
+		},
+		{
+			desc:    "splice to wrapped connection",
+			splice:  true,
+			wrap:    func(conn net.Conn) net.Conn { return conn },
+			wrapDst: func(conn net.Conn) net.Conn { return &Conn{Conn: &wrappedConn{Conn: conn}} },
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srcClient, srcServer := tcpConnPair(t)
+			defer srcServer.Close()
+			dstClient, dstServer := tcpConnPair(t)
+			defer dstClient.Close()
+
+			_, err := srcClient.Write([]byte("hello world"))
+			require.NoError(t, err)
+			require.NoError(t, srcClient.Close())
+
+			src := test.wrap(srcServer)
+
+			var dst net.Conn = dstServer
+			if test.wrapDst != nil {
+				dst = test.wrapDst(dstServer)
+			}
+
+			written, err := copyConn(dst, src, test.splice)
+			require.NoError(t, err)
+			assert.Equal(t, int64(11), written)
+			require.NoError(t, dstServer.Close())
+
+			received, err := ioutil.ReadAll(dstClient)
+			require.NoError(t, err)
+			assert.Equal(t, "hello world", string(received))
+		})
+	}
+}
+
+func TestUnwrapTCPConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	written, conn, err := unwrapTCPConn(ioutil.Discard, &wrappedConn{Conn: server})
+	require.NoError(t, err)
+	assert.Zero(t, written)
+	assert.Nil(t, conn, "pipes can't be spliced")
+}
+
+func TestUnwrapTCPDst(t *testing.T) {
+	client, server := tcpConnPair(t)
+	defer client.Close()
+	defer server.Close()
+
+	assert.Equal(t, server, unwrapTCPDst(server))
+	assert.Equal(t, server, unwrapTCPDst(&wrappedConn{Conn: server}))
+	assert.Equal(t, server, unwrapTCPDst(&Conn{Conn: &wrappedConn{Conn: server}}))
+
+	pipeClient, pipeServer := net.Pipe()
+	defer pipeClient.Close()
+	defer pipeServer.Close()
+
+	assert.Nil(t, unwrapTCPDst(&wrappedConn{Conn: pipeServer}), "pipes can't be spliced")
+}
@@ -0,0 +1,42 @@
+package tcp
+
+import (
+	"net"
+
+	"github.com/containous/traefik/safe"
+)
+
+// Handler is the TCP counterpart of http.Handler.
+type Handler interface {
+	ServeTCP(conn net.Conn)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as handlers.
+type HandlerFunc func(conn net.Conn)
+
+// ServeTCP serves the connection.
+func (f HandlerFunc) ServeTCP(conn net.Conn) {
+	f(conn)
+}
+
+// HandlerSwitcher allows hot switching of the TCP handler of an entry point.
+type HandlerSwitcher struct {
+	handler *safe.Safe
+}
+
+// NewHandlerSwitcher builds a new instance of HandlerSwitcher.
+func NewHandlerSwitcher(handler Handler) *HandlerSwitcher {
+	return &HandlerSwitcher{
+		handler: safe.New(handler),
+	}
+}
+
+// ServeTCP serves the connection with the current handler.
+func (s *HandlerSwitcher) ServeTCP(conn net.Conn) {
+	s.handler.Get().(Handler).ServeTCP(conn)
+}
+
+// UpdateHandler safely updates the current handler.
+func (s *HandlerSwitcher) UpdateHandler(handler Handler) {
+	s.handler.Set(handler)
+}
@@ -0,0 +1,56 @@
+package tcp
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+var errForwarderClosed = errors.New("HTTP forwarder closed")
+
+// HTTPForwarder is a listener handing the connections it serves to an HTTP server.
+// Closing it closes the listener it wraps.
+type HTTPForwarder struct {
+	net.Listener
+	connChan  chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewHTTPForwarder creates a new HTTPForwarder, wrapping the listener of an entry point.
+func NewHTTPForwarder(ln net.Listener) *HTTPForwarder {
+	return &HTTPForwarder{
+		Listener: ln,
+		connChan: make(chan net.Conn),
+		done:     make(chan struct{}),
+	}
+}
+
+// ServeTCP hands the connection to the HTTP server.
+func (h *HTTPForwarder) ServeTCP(conn net.Conn) {
+	select {
+	case h.connChan <- conn:
+	case <-h.done:
+		conn.Close()
+	}
+}
+
+// Accept returns the next connection for the HTTP server.
+func (h *HTTPForwarder) Accept() (net.Conn, error) {
+	select {
+	case conn := <-h.connChan:
+		return conn, nil
+	case <-h.done:
+		return nil, errForwarderClosed
+	}
+}
+
+// Close stops the forwarder, and closes the listener it wraps.
+func (h *HTTPForwarder) Close() error {
+	var err error
+	h.closeOnce.Do(func() {
+		close(h.done)
+		err = h.Listener.Close()
+	})
+	return err
+}
@@ -0,0 +1,100 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/proxyprotocol"
+)
+
+const defaultDialTimeout = 30 * time.Second
+
+// Proxy forwards a TCP connection to a server, without touching the bytes.
+// With splice, the bytes of the connections not terminated by Traefik are copied by the kernel, on Linux.
+// With proxyProtocol, the connection to the server starts with a PROXY protocol version 2 header.
+type Proxy struct {
+	address       string
+	splice        bool
+	proxyProtocol bool
+}
+
+// NewProxy creates a new Proxy.
+func NewProxy(address string, splice, proxyProtocol bool) (*Proxy, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, err
+	}
+	return &Proxy{address: address, splice: splice, proxyProtocol: proxyProtocol}, nil
+}
+
+// ServeTCP forwards the connection to the server, until one of them closes it.
+func (p *Proxy) ServeTCP(conn net.Conn) {
+	logger := log.WithoutContext()
+	defer conn.Close()
+
+	backendConn, err := net.DialTimeout("tcp", p.address, defaultDialTimeout)
+	if err != nil {
+		logger.Errorf("Error while connecting to the server %s: %v", p.address, err)
+		return
+	}
+	defer backendConn.Close()
+
+	if p.proxyProtocol {
+		if err := writeProxyHeader(backendConn, conn); err != nil {
+			logger.Debugf("Error while sending the PROXY protocol header to the server %s: %v", p.address, err)
+			return
+		}
+	}
+
+	errChan := make(chan error, 2)
+	go p.connCopy(conn, backendConn, errChan)
+	go p.connCopy(backendConn, conn, errChan)
+
+	if err := <-errChan; err != nil {
+		logger.Debugf("Error while forwarding a connection to the server %s: %v", p.address, err)
+	}
+}
+
+// connCopy copies the data from src to dst, then closes the write side of dst when it can,
+// so that the peer sees the end of the stream while the other direction goes on.
+func (p *Proxy) connCopy(dst, src net.Conn, errChan chan<- error) {
+	_, err := copyConn(dst, src, p.splice)
+	errChan <- err
+
+	if conn, ok := dst.(interface{ CloseWrite() error }); ok {
+		_ = conn.CloseWrite()
+	} else {
+		_ = dst.Close()
+	}
+}
+
+// writeProxyHeader writes to the server the PROXY protocol header describing the client connection:
+// its source and original destination and, for TLS, the server name (SNI) and the negotiated protocol (ALPN).
+func writeProxyHeader(backendConn, conn net.Conn) error {
+	header := &proxyprotocol.Header{Version: 2}
+	header.Source, _ = conn.RemoteAddr().(*net.TCPAddr)
+	header.Destination, _ = conn.LocalAddr().(*net.TCPAddr)
+
+	switch c := conn.(type) {
+	case *tls.Conn:
+		// The handshake of a terminated connection gives its server name and negotiated protocol.
+		if err := c.Handshake(); err != nil {
+			return err
+		}
+		header.TLVs = proxyprotocol.TLSTLVs(c.ConnectionState())
+	case *Conn:
+		// Only the server name of a passthrough connection is known, the protocol being negotiated by the server.
+		if len(c.ServerName) > 0 {
+			header.TLVs = []proxyprotocol.TLV{{Type: proxyprotocol.TypeAuthority, Value: []byte(c.ServerName)}}
+		}
+	}
+
+	raw, err := header.Format()
+	if err != nil {
+		return err
+	}
+
+	_, err = backendConn.Write(raw)
+	return err
+}
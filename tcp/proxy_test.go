@@ -0,0 +1,196 @@
+package tcp
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/proxyprotocol"
+	"github.com/containous/traefik/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backendListener.Close()
+
+	// Echo server.
+	go func() {
+		conn, err := backendListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	proxy, err := NewProxy(backendListener.Addr().String(), false, false)
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go proxy.ServeTCP(server)
+
+	_, err = client.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(client, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
+
+func TestProxySpliceWrappedConn(t *testing.T) {
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backendListener.Close()
+
+	// Echo server.
+	go func() {
+		conn, err := backendListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	proxy, err := NewProxy(backendListener.Addr().String(), true, false)
+	require.NoError(t, err)
+
+	client, server := tcpConnPair(t)
+	defer client.Close()
+
+	// The connection routed by its peeked bytes is spliced in both directions.
+	_, err = client.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	peeked := bufio.NewReader(server)
+	_, err = peeked.Peek(4)
+	require.NoError(t, err)
+
+	go proxy.ServeTCP(&Conn{Conn: &wrappedConn{Conn: server}, Peeked: peeked})
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(client, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	_, err = client.Write([]byte("pong"))
+	require.NoError(t, err)
+
+	_, err = io.ReadFull(client, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+}
+
+func TestProxyProxyProtocol(t *testing.T) {
+	cert, err := generate.DefaultCertificate()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc              string
+		tls               bool
+		wrap              func(conn net.Conn) net.Conn
+		expectedAuthority string
+		expectedALPN      string
+	}{
+		{
+			desc: "plain connection",
+			wrap: func(conn net.Conn) net.Conn { return conn },
+		},
+		{
+			desc: "passthrough connection",
+			tls:  true,
+			wrap: func(conn net.Conn) net.Conn {
+				return &Conn{Conn: conn, Peeked: bufio.NewReader(conn), ServerName: "foo.bar"}
+			},
+			expectedAuthority: "foo.bar",
+		},
+		{
+			desc: "terminated connection",
+			tls:  true,
+			wrap: func(conn net.Conn) net.Conn {
+				return tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{*cert}, NextProtos: []string{"h2"}})
+			},
+			expectedAuthority: "foo.bar",
+			expectedALPN:      "h2",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer backendListener.Close()
+
+			headers := make(chan *proxyprotocol.Header, 1)
+			go func() {
+				conn, err := backendListener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				header, _ := proxyprotocol.NewConn(conn, time.Second, true).Header()
+				headers <- header
+			}()
+
+			proxy, err := NewProxy(backendListener.Addr().String(), false, true)
+			require.NoError(t, err)
+
+			client, server := tcpConnPair(t)
+			defer client.Close()
+
+			go proxy.ServeTCP(test.wrap(server))
+
+			if test.tls {
+				go func() {
+					tlsClient := tls.Client(client, &tls.Config{ServerName: "foo.bar", NextProtos: []string{"h2"}, InsecureSkipVerify: true})
+					_ = tlsClient.Handshake()
+				}()
+			}
+
+			select {
+			case header := <-headers:
+				require.NotNil(t, header)
+				assert.Equal(t, client.LocalAddr().String(), header.Source.String())
+				assert.Equal(t, server.LocalAddr().String(), header.Destination.String())
+
+				authority, _ := header.TLV(proxyprotocol.TypeAuthority)
+				assert.Equal(t, test.expectedAuthority, string(authority))
+
+				alpn, _ := header.TLV(proxyprotocol.TypeALPN)
+				assert.Equal(t, test.expectedALPN, string(alpn))
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for the PROXY protocol header")
+			}
+		})
+	}
+}
+
+func TestNewProxyInvalidAddress(t *testing.T) {
+	_, err := NewProxy("foo", false, false)
+	assert.Error(t, err)
+}
+
+func TestRRLoadBalancer(t *testing.T) {
+	var served []string
+
+	lb := NewRRLoadBalancer()
+	lb.AddServer(HandlerFunc(func(conn net.Conn) { served = append(served, "a") }))
+	lb.AddServer(HandlerFunc(func(conn net.Conn) { served = append(served, "b") }))
+
+	for i := 0; i < 3; i++ {
+		lb.ServeTCP(nil)
+	}
+
+	assert.Equal(t, []string{"a", "b", "a"}, served)
+}
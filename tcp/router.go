@@ -0,0 +1,213 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+const (
+	// recordTypeHandshake is the type of the TLS record holding the ClientHello.
+	recordTypeHandshake = 0x16
+	recordHeaderLen     = 5
+	// maxRecordLen is the maximum length of the plaintext of a TLS record, which bounds the length of the ClientHello record.
+	maxRecordLen = 16384
+
+	// defaultPeekTimeout is the time given to a client to send the beginning of its connection, before it is routed.
+	defaultPeekTimeout = 10 * time.Second
+)
+
+// Router routes the TCP connections of an entry point, on the server name (SNI) of their TLS ClientHello.
+// The connections matching no route are handed to the HTTP server of the entry point.
+type Router struct {
+	routingTable      map[string]Handler
+	catchAllNoTLS     Handler
+	httpForwarder     Handler
+	hasTLSTerminators bool
+	peekTimeout       time.Duration
+}
+
+// NewRouter creates a new Router.
+func NewRouter() *Router {
+	return &Router{routingTable: make(map[string]Handler), peekTimeout: defaultPeekTimeout}
+}
+
+// AddRoute routes the TLS connections for the server name to the handler, which gets the TLS bytes untouched (passthrough).
+// The server name "*" matches all the TLS connections.
+func (r *Router) AddRoute(serverName string, target Handler) {
+	r.routingTable[strings.ToLower(serverName)] = target
+}
+
+// AddRouteTLS routes the TLS connections for the server name to the handler, after the termination of TLS with the given configuration.
+func (r *Router) AddRouteTLS(serverName string, target Handler, config *tls.Config) {
+	r.AddRoute(serverName, &TLSHandler{Next: target, Config: config})
+	r.hasTLSTerminators = true
+}
+
+// AddCatchAllNoTLS routes all the non-TLS connections to the handler.
+func (r *Router) AddCatchAllNoTLS(handler Handler) {
+	r.catchAllNoTLS = handler
+}
+
+// HTTPForwarder sets the handler of the connections matching no route.
+func (r *Router) HTTPForwarder(handler Handler) {
+	r.httpForwarder = handler
+}
+
+// ServeTCP routes the connection.
+func (r *Router) ServeTCP(conn net.Conn) {
+	// Without TLS routes, there is nothing to peek: protocols where the server speaks first work with a catch-all route.
+	if r.catchAllNoTLS != nil && len(r.routingTable) == 0 {
+		r.catchAllNoTLS.ServeTCP(conn)
+		return
+	}
+
+	// The handlers bound the reading of the connection once it is routed, an idle client must not hold it before.
+	if err := conn.SetReadDeadline(time.Now().Add(r.peekTimeout)); err != nil {
+		log.WithoutContext().Debugf("Error while setting the read deadline of a TCP connection: %v", err)
+		conn.Close()
+		return
+	}
+
+	br := bufio.NewReaderSize(conn, recordHeaderLen+maxRecordLen)
+	serverName, isTLS, err := clientHelloServerName(br)
+	if err != nil {
+		log.WithoutContext().Debugf("Error while reading the beginning of a TCP connection: %v", err)
+		conn.Close()
+		return
+	}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		log.WithoutContext().Debugf("Error while clearing the read deadline of a TCP connection: %v", err)
+		conn.Close()
+		return
+	}
+
+	peeked := &Conn{Conn: conn, Peeked: br, ServerName: serverName}
+
+	if !isTLS {
+		if r.catchAllNoTLS != nil {
+			r.catchAllNoTLS.ServeTCP(peeked)
+			return
+		}
+		r.forward(peeked)
+		return
+	}
+
+	if target, ok := r.routingTable[strings.ToLower(serverName)]; ok {
+		target.ServeTCP(peeked)
+		return
+	}
+
+	if target, ok := r.routingTable["*"]; ok {
+		target.ServeTCP(peeked)
+		return
+	}
+
+	r.forward(peeked)
+}
+
+func (r *Router) forward(conn net.Conn) {
+	if r.httpForwarder == nil {
+		conn.Close()
+		return
+	}
+	r.httpForwarder.ServeTCP(conn)
+}
+
+// TLSHandler terminates TLS before handing the connection to the next handler.
+type TLSHandler struct {
+	Next   Handler
+	Config *tls.Config
+}
+
+// ServeTCP terminates TLS on the connection.
+func (t *TLSHandler) ServeTCP(conn net.Conn) {
+	t.Next.ServeTCP(tls.Server(conn, t.Config))
+}
+
+// Conn is a connection whose first bytes have been peeked by the router.
+// ServerName is the server name of its TLS ClientHello, if any.
+type Conn struct {
+	net.Conn
+	Peeked     *bufio.Reader
+	ServerName string
+}
+
+// Read reads the peeked bytes first.
+func (c *Conn) Read(p []byte) (int, error) {
+	return c.Peeked.Read(p)
+}
+
+// Unwrap returns the connection the bytes are peeked from.
+func (c *Conn) Unwrap() net.Conn {
+	return c.Conn
+}
+
+// CloseWrite closes the write side of the connection when it is supported, and the whole connection otherwise.
+func (c *Conn) CloseWrite() error {
+	if conn, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return conn.CloseWrite()
+	}
+	return c.Conn.Close()
+}
+
+// clientHelloServerName returns the server name of the TLS ClientHello the connection starts with,
+// and false if the connection doesn't start with a TLS handshake.
+// The reader must be able to buffer a whole TLS record.
+func clientHelloServerName(br *bufio.Reader) (string, bool, error) {
+	hdr, err := br.Peek(1)
+	if err != nil {
+		if err == io.EOF {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	if hdr[0] != recordTypeHandshake {
+		return "", false, nil
+	}
+
+	hdr, err = br.Peek(recordHeaderLen)
+	if err != nil {
+		return "", true, err
+	}
+
+	recordLen := int(hdr[3])<<8 | int(hdr[4])
+	if recordLen > maxRecordLen {
+		return "", true, fmt.Errorf("TLS record too long: %d bytes", recordLen)
+	}
+	helloBytes, err := br.Peek(recordHeaderLen + recordLen)
+	if err != nil {
+		return "", true, err
+	}
+
+	var serverName string
+	server := tls.Server(sniSniffConn{r: bytes.NewReader(helloBytes)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, nil
+		},
+	})
+	// The handshake fails once the ClientHello has been read, as the connection is read-only.
+	_ = server.Handshake()
+
+	return serverName, true, nil
+}
+
+// sniSniffConn is a read-only net.Conn over the peeked bytes of a connection.
+type sniSniffConn struct {
+	r io.Reader
+	net.Conn
+}
+
+func (c sniSniffConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+func (sniSniffConn) Write(p []byte) (int, error) { return 0, io.EOF }
@@ -0,0 +1,227 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a handler recording its name and the data it reads.
+func recorder(name string, results chan<- string) Handler {
+	return HandlerFunc(func(conn net.Conn) {
+		defer conn.Close()
+
+		buf := make([]byte, 5)
+		n, _ := conn.Read(buf)
+		results <- name + ":" + string(buf[:n])
+	})
+}
+
+func TestRouterServeTCP(t *testing.T) {
+	// Protocols making the ClientHello longer than the default size of a bufio.Reader.
+	var largeNextProtos []string
+	for i := 0; i < 40; i++ {
+		largeNextProtos = append(largeNextProtos, fmt.Sprintf("%03d%s", i, strings.Repeat("x", 200)))
+	}
+
+	testCases := []struct {
+		desc       string
+		serverName string
+		nextProtos []string
+		tls        bool
+		catchAll   bool
+		expected   string
+	}{
+		{
+			desc:       "TLS route matching the server name",
+			serverName: "foo.bar",
+			tls:        true,
+			expected:   "foo:",
+		},
+		{
+			desc:       "TLS route matching the server name case insensitively",
+			serverName: "FOO.bar",
+			tls:        true,
+			expected:   "foo:",
+		},
+		{
+			desc:       "TLS wildcard route",
+			serverName: "other.bar",
+			tls:        true,
+			expected:   "wildcard:",
+		},
+		{
+			desc:       "TLS route matching the server name of a large ClientHello",
+			serverName: "foo.bar",
+			nextProtos: largeNextProtos,
+			tls:        true,
+			expected:   "foo:",
+		},
+		{
+			desc:     "non-TLS connection to the HTTP forwarder",
+			expected: "http:GET /",
+		},
+		{
+			desc:     "non-TLS connection to the catch-all route",
+			catchAll: true,
+			expected: "catchAll:GET /",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			results := make(chan string, 1)
+
+			router := NewRouter()
+			router.AddRoute("foo.bar", recorder("foo", results))
+			router.AddRoute("*", recorder("wildcard", results))
+			router.HTTPForwarder(recorder("http", results))
+			if test.catchAll {
+				router.AddCatchAllNoTLS(recorder("catchAll", results))
+			}
+
+			client, server := net.Pipe()
+			defer client.Close()
+
+			go router.ServeTCP(server)
+
+			if test.tls {
+				go func() {
+					_ = tls.Client(client, &tls.Config{ServerName: test.serverName, NextProtos: test.nextProtos, InsecureSkipVerify: true}).Handshake()
+				}()
+			} else {
+				go func() {
+					_, _ = client.Write([]byte("GET / HTTP/1.1\r\n"))
+				}()
+			}
+
+			select {
+			case result := <-results:
+				if test.tls {
+					// The handler reads the beginning of the ClientHello, not checked here.
+					assert.Equal(t, test.expected, result[:len(test.expected)])
+				} else {
+					assert.Equal(t, test.expected, result)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for the connection to be routed")
+			}
+		})
+	}
+}
+
+func TestRouterServeTCPWithoutHTTPForwarder(t *testing.T) {
+	router := NewRouter()
+
+	client, server := net.Pipe()
+	go router.ServeTCP(server)
+
+	_, err := client.Write([]byte("GET / HTTP/1.1\r\n"))
+	require.NoError(t, err)
+
+	// The connection matching no route is closed.
+	_, err = ioutil.ReadAll(client)
+	assert.NoError(t, err)
+}
+
+func TestRouterServeTCPPeekTimeout(t *testing.T) {
+	results := make(chan string, 1)
+
+	router := NewRouter()
+	router.peekTimeout = 50 * time.Millisecond
+	router.AddRoute("foo.bar", recorder("foo", results))
+	router.HTTPForwarder(recorder("http", results))
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeTCP(server)
+		close(done)
+	}()
+
+	// The client sends nothing: its connection is closed without being routed.
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the idle connection to be closed")
+	}
+
+	_, err := ioutil.ReadAll(client)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestRouterServeTCPClearsPeekDeadline(t *testing.T) {
+	served := make(chan error, 1)
+
+	router := NewRouter()
+	router.peekTimeout = 50 * time.Millisecond
+	router.AddRoute("foo.bar", recorder("foo", nil))
+	router.HTTPForwarder(HandlerFunc(func(conn net.Conn) {
+		defer conn.Close()
+
+		buf := make([]byte, 4)
+		_, err := io.ReadFull(conn, buf)
+		served <- err
+	}))
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go router.ServeTCP(server)
+
+	go func() {
+		_, _ = client.Write([]byte("GE"))
+		// The routed connection is read after the peek timeout.
+		time.Sleep(200 * time.Millisecond)
+		_, _ = client.Write([]byte("T "))
+	}()
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the connection to be served")
+	}
+}
+
+func TestRouterServeTCPTerminatingTLS(t *testing.T) {
+	cert, err := generate.DefaultCertificate()
+	require.NoError(t, err)
+
+	results := make(chan string, 1)
+
+	router := NewRouter()
+	router.AddRouteTLS("foo.bar", recorder("foo", results), &tls.Config{Certificates: []tls.Certificate{*cert}})
+
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go router.ServeTCP(server)
+
+	go func() {
+		tlsClient := tls.Client(client, &tls.Config{ServerName: "foo.bar", InsecureSkipVerify: true})
+		_, _ = tlsClient.Write([]byte("hello"))
+	}()
+
+	select {
+	case result := <-results:
+		assert.Equal(t, "foo:hello", result)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the connection to be routed")
+	}
+}
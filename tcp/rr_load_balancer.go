@@ -0,0 +1,52 @@
+package tcp
+
+import (
+	"net"
+	"sync"
+
+	"github.com/containous/traefik/log"
+)
+
+// RRLoadBalancer is a round robin load balancer of TCP handlers.
+type RRLoadBalancer struct {
+	servers []Handler
+	lock    sync.Mutex
+	current int
+}
+
+// NewRRLoadBalancer creates a new RRLoadBalancer.
+func NewRRLoadBalancer() *RRLoadBalancer {
+	return &RRLoadBalancer{}
+}
+
+// AddServer adds a handler to the load balancer.
+func (b *RRLoadBalancer) AddServer(server Handler) {
+	b.servers = append(b.servers, server)
+}
+
+// ServeTCP forwards the connection to the next handler.
+func (b *RRLoadBalancer) ServeTCP(conn net.Conn) {
+	server := b.next()
+	if server == nil {
+		log.WithoutContext().Error("No server available for the TCP connection")
+		conn.Close()
+		return
+	}
+	server.ServeTCP(conn)
+}
+
+func (b *RRLoadBalancer) next() Handler {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 {
+		return nil
+	}
+
+	if b.current >= len(b.servers) {
+		b.current = 0
+	}
+	server := b.servers[b.current]
+	b.current++
+	return server
+}
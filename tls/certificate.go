@@ -2,11 +2,9 @@ package tls
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"sort"
 	"strings"
 
 	"github.com/containous/traefik/log"
@@ -145,35 +143,11 @@ func (c *Certificate) AppendCertificates(certs map[string]map[string]*tls.Certif
 	if err != nil {
 		return fmt.Errorf("unable to read KeyFile : %v", err)
 	}
-	tlsCert, err := tls.X509KeyPair(certContent, keyContent)
+	parsed, err := parsedCertificates.get(certContent, keyContent)
 	if err != nil {
-		return fmt.Errorf("unable to generate TLS certificate : %v", err)
+		return err
 	}
-
-	parsedCert, _ := x509.ParseCertificate(tlsCert.Certificate[0])
-
-	var SANs []string
-	if parsedCert.Subject.CommonName != "" {
-		SANs = append(SANs, strings.ToLower(parsedCert.Subject.CommonName))
-	}
-	if parsedCert.DNSNames != nil {
-		sort.Strings(parsedCert.DNSNames)
-		for _, dnsName := range parsedCert.DNSNames {
-			if dnsName != parsedCert.Subject.CommonName {
-				SANs = append(SANs, strings.ToLower(dnsName))
-			}
-		}
-
-	}
-	if parsedCert.IPAddresses != nil {
-		for _, ip := range parsedCert.IPAddresses {
-			if ip.String() != parsedCert.Subject.CommonName {
-				SANs = append(SANs, strings.ToLower(ip.String()))
-			}
-		}
-
-	}
-	certKey := strings.Join(SANs, ",")
+	certKey := parsed.domains
 
 	certExists := false
 	if certs[ep] == nil {
@@ -190,10 +164,10 @@ func (c *Certificate) AppendCertificates(certs map[string]map[string]*tls.Certif
 		log.Warnf("Into EntryPoint %s, try to add certificate for domains which already have this certificate (%s). The new certificate will not be append to the EntryPoint.", ep, certKey)
 	} else {
 		log.Debugf("Add certificate for domains %s", certKey)
-		certs[ep][certKey] = &tlsCert
+		certs[ep][certKey] = parsed.certificate
 	}
 
-	return err
+	return nil
 }
 
 func (c *Certificate) getTruncatedCertificateName() string {
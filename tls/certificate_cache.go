@@ -0,0 +1,120 @@
+package tls
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxParsedCertificates bounds the number of certificates of a generation of the cache.
+const maxParsedCertificates = 10000
+
+// parsedCertificates caches the certificates parsed from their content, by hash of their content,
+// so that the certificates of the configurations are not parsed again on each reload, nor for each entry point.
+var parsedCertificates = newCertificateCache(maxParsedCertificates)
+
+// parsedCertificate is a certificate with its domains, joined by commas.
+type parsedCertificate struct {
+	certificate *tls.Certificate
+	domains     string
+}
+
+// certificateCache holds the parsed certificates in two generations:
+// when the current generation is full, it becomes the previous one, and the certificates used since then are moved back to the current one.
+// The certificates unused during a whole generation are thus dropped.
+type certificateCache struct {
+	mu       sync.Mutex
+	max      int
+	current  map[[sha256.Size]byte]*parsedCertificate
+	previous map[[sha256.Size]byte]*parsedCertificate
+}
+
+func newCertificateCache(max int) *certificateCache {
+	return &certificateCache{
+		max:     max,
+		current: make(map[[sha256.Size]byte]*parsedCertificate),
+	}
+}
+
+// get returns the certificate parsed from the PEM encoded certificate and key, parsing it on the first call only.
+func (c *certificateCache) get(certContent, keyContent []byte) (*parsedCertificate, error) {
+	hash := sha256.New()
+	hash.Write(certContent)
+	// The PEM encoded content never holds a null byte, so that it separates the certificate from the key.
+	hash.Write([]byte{0})
+	hash.Write(keyContent)
+
+	var key [sha256.Size]byte
+	copy(key[:], hash.Sum(nil))
+
+	c.mu.Lock()
+	parsed, ok := c.current[key]
+	if !ok {
+		parsed, ok = c.previous[key]
+		if ok {
+			c.store(key, parsed)
+		}
+	}
+	c.mu.Unlock()
+
+	if ok {
+		return parsed, nil
+	}
+
+	parsed, err := parseCertificate(certContent, keyContent)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.store(key, parsed)
+	c.mu.Unlock()
+
+	return parsed, nil
+}
+
+func (c *certificateCache) store(key [sha256.Size]byte, parsed *parsedCertificate) {
+	if len(c.current) >= c.max {
+		c.previous = c.current
+		c.current = make(map[[sha256.Size]byte]*parsedCertificate)
+	}
+	c.current[key] = parsed
+}
+
+func parseCertificate(certContent, keyContent []byte) (*parsedCertificate, error) {
+	tlsCert, err := tls.X509KeyPair(certContent, keyContent)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate TLS certificate : %v", err)
+	}
+
+	parsedCert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse TLS certificate : %v", err)
+	}
+
+	var SANs []string
+	if parsedCert.Subject.CommonName != "" {
+		SANs = append(SANs, strings.ToLower(parsedCert.Subject.CommonName))
+	}
+	if parsedCert.DNSNames != nil {
+		sort.Strings(parsedCert.DNSNames)
+		for _, dnsName := range parsedCert.DNSNames {
+			if dnsName != parsedCert.Subject.CommonName {
+				SANs = append(SANs, strings.ToLower(dnsName))
+			}
+		}
+	}
+	if parsedCert.IPAddresses != nil {
+		for _, ip := range parsedCert.IPAddresses {
+			if ip.String() != parsedCert.Subject.CommonName {
+				SANs = append(SANs, strings.ToLower(ip.String()))
+			}
+		}
+	}
+
+	return &parsedCertificate{certificate: &tlsCert, domains: strings.Join(SANs, ",")}, nil
+}
@@ -0,0 +1,51 @@
+package tls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateCache(t *testing.T) {
+	cache := newCertificateCache(2)
+
+	for _, domain := range []string{"foo.com", "bar.com", "baz.com"} {
+		certContent, keyContent, err := generate.KeyPair(domain, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		parsed, err := cache.get(certContent, keyContent)
+		require.NoError(t, err)
+		assert.Equal(t, "traefik default cert,"+domain, parsed.domains)
+
+		cached, err := cache.get(certContent, keyContent)
+		require.NoError(t, err)
+		assert.True(t, parsed == cached, "the certificate of %s is parsed again", domain)
+	}
+
+	// The first certificate moved to the previous generation, the other ones are in the current one.
+	assert.Len(t, cache.current, 1)
+	assert.Len(t, cache.previous, 2)
+
+	_, err := cache.get([]byte("foo"), []byte("bar"))
+	require.Error(t, err)
+	assert.Len(t, cache.current, 1)
+}
+
+func TestCertificateCacheDifferentKeys(t *testing.T) {
+	cache := newCertificateCache(maxParsedCertificates)
+
+	certContent, keyContent, err := generate.KeyPair("foo.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, otherKeyContent, err := generate.KeyPair("foo.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	_, err = cache.get(certContent, keyContent)
+	require.NoError(t, err)
+
+	// The key does not match the certificate, which must not be served from the cache.
+	_, err = cache.get(certContent, otherKeyContent)
+	require.Error(t, err)
+}
@@ -9,15 +9,19 @@ import (
 	"time"
 
 	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/lru"
 	"github.com/containous/traefik/safe"
-	"github.com/patrickmn/go-cache"
 )
 
+// certificateCacheTTL is the duration the best match certificate of a domain is cached.
+const certificateCacheTTL = time.Hour
+
 // CertificateStore store for dynamic and static certificates
 type CertificateStore struct {
 	DynamicCerts       *safe.Safe
 	DefaultCertificate *tls.Certificate
-	CertCache          *cache.Cache
+	DefaultDomain      string
+	CertCache          *lru.Cache
 	SniStrict          bool
 }
 
@@ -25,7 +29,7 @@ type CertificateStore struct {
 func NewCertificateStore() *CertificateStore {
 	return &CertificateStore{
 		DynamicCerts: &safe.Safe{},
-		CertCache:    cache.New(1*time.Hour, 10*time.Minute),
+		CertCache:    lru.New("certificates"),
 	}
 }
 
@@ -81,6 +85,23 @@ func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *
 		domainToCheck = strings.TrimSpace(host)
 	}
 
+	return c.getCertificate(domainToCheck)
+}
+
+// GetDefaultCertificate returns the certificate served when no certificate matches the server name:
+// the certificate matching the default domain if there is one, otherwise the default certificate.
+func (c CertificateStore) GetDefaultCertificate() *tls.Certificate {
+	if len(c.DefaultDomain) > 0 {
+		if cert := c.getCertificate(strings.ToLower(strings.TrimSpace(c.DefaultDomain))); cert != nil {
+			return cert
+		}
+	}
+
+	return c.DefaultCertificate
+}
+
+// getCertificate returns the best match certificate for the domain, and caches the response
+func (c CertificateStore) getCertificate(domainToCheck string) *tls.Certificate {
 	if cert, ok := c.CertCache.Get(domainToCheck); ok {
 		return cert.(*tls.Certificate)
 	}
@@ -105,7 +126,7 @@ func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *
 		sort.Strings(keys)
 
 		// cache best match
-		c.CertCache.SetDefault(domainToCheck, matchedCerts[keys[len(keys)-1]])
+		c.CertCache.Set(domainToCheck, matchedCerts[keys[len(keys)-1]], certificateCacheTTL)
 		return matchedCerts[keys[len(keys)-1]]
 	}
 
@@ -115,7 +136,7 @@ func (c CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo) *
 // ResetCache clears the cache in the store
 func (c CertificateStore) ResetCache() {
 	if c.CertCache != nil {
-		c.CertCache.Flush()
+		c.CertCache.Purge()
 	}
 }
 
@@ -5,10 +5,9 @@ import (
 	"fmt"
 	"strings"
 	"testing"
-	"time"
 
+	"github.com/containous/traefik/lru"
 	"github.com/containous/traefik/safe"
-	"github.com/patrickmn/go-cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -69,7 +68,7 @@ func TestGetBestCertificate(t *testing.T) {
 
 			store := &CertificateStore{
 				DynamicCerts: safe.New(dynamicMap),
-				CertCache:    cache.New(1*time.Hour, 10*time.Minute),
+				CertCache:    lru.New("certificates"),
 			}
 
 			var expected *tls.Certificate
@@ -89,6 +88,64 @@ func TestGetBestCertificate(t *testing.T) {
 	}
 }
 
+func TestGetDefaultCertificate(t *testing.T) {
+	defaultCert, err := loadTestCert("snitest.org", false)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		defaultDomain string
+		dynamicCert   string
+		expectedCert  string
+	}{
+		{
+			desc:         "No default domain",
+			dynamicCert:  "snitest.com",
+			expectedCert: "snitest.org",
+		},
+		{
+			desc:          "Default domain",
+			defaultDomain: "snitest.com",
+			dynamicCert:   "snitest.com",
+			expectedCert:  "snitest.com",
+		},
+		{
+			desc:          "Default domain matching a wildcard, case insensitive",
+			defaultDomain: "WWW.snitest.com",
+			dynamicCert:   "*.snitest.com",
+			expectedCert:  "*.snitest.com",
+		},
+		{
+			desc:          "Default domain without certificate",
+			defaultDomain: "snitest.net",
+			dynamicCert:   "snitest.com",
+			expectedCert:  "snitest.org",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cert, err := loadTestCert(test.dynamicCert, false)
+			require.NoError(t, err)
+
+			store := &CertificateStore{
+				DynamicCerts:       safe.New(map[string]*tls.Certificate{test.dynamicCert: cert}),
+				DefaultCertificate: defaultCert,
+				DefaultDomain:      test.defaultDomain,
+				CertCache:          lru.New("certificates"),
+			}
+
+			expected, err := loadTestCert(test.expectedCert, false)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, store.GetDefaultCertificate())
+		})
+	}
+}
+
 func loadTestCert(certName string, uppercase bool) (*tls.Certificate, error) {
 	replacement := "wildcard"
 	if uppercase {
@@ -26,7 +26,8 @@ type TLS struct {
 	CipherSuites       []string
 	ClientCA           ClientCA
 	DefaultCertificate *Certificate
-	SniStrict          bool `export:"true"`
+	DefaultDomain      string `export:"true"`
+	SniStrict          bool   `export:"true"`
 }
 
 // FilesOrContents hold the CA we want to have in root
@@ -0,0 +1,140 @@
+package opentelemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	defaultGRPCEndpoint = "localhost:4317"
+	defaultHTTPEndpoint = "http://localhost:4318/v1/traces"
+
+	exportMethod = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+)
+
+// httpClient exports spans with OTLP/HTTP using protobuf payloads.
+type httpClient struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+func newHTTPClient(endpoint string, headers map[string]string) (*httpClient, error) {
+	if len(endpoint) == 0 {
+		endpoint = defaultHTTPEndpoint
+	}
+
+	return &httpClient{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{},
+	}, nil
+}
+
+func (c *httpClient) export(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code from collector: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *httpClient) close() error {
+	return nil
+}
+
+// grpcClient exports spans with OTLP/gRPC.
+// The payload is already encoded, so a pass-through codec is used instead of generated stubs.
+type grpcClient struct {
+	conn    *grpc.ClientConn
+	headers metadata.MD
+}
+
+func newGRPCClient(endpoint string, insecure bool, headers map[string]string) (*grpcClient, error) {
+	if len(endpoint) == 0 {
+		endpoint = defaultGRPCEndpoint
+	}
+
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if !insecure {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))}
+	}
+
+	conn, err := grpc.Dial(endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to OTLP collector %s: %v", endpoint, err)
+	}
+
+	return &grpcClient{
+		conn:    conn,
+		headers: metadata.New(headers),
+	}, nil
+}
+
+func (c *grpcClient) export(ctx context.Context, payload []byte) error {
+	if len(c.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, c.headers)
+	}
+
+	return c.conn.Invoke(ctx, exportMethod, &rawMessage{data: payload}, &rawMessage{}, grpc.CallCustomCodec(rawCodec{}))
+}
+
+func (c *grpcClient) close() error {
+	return c.conn.Close()
+}
+
+type rawMessage struct {
+	data []byte
+}
+
+// rawCodec sends and receives already encoded protobuf messages.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return nil, errors.New("unexpected message type")
+	}
+	return msg.data, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return errors.New("unexpected message type")
+	}
+	msg.data = data
+	return nil
+}
+
+func (rawCodec) String() string {
+	return "raw"
+}
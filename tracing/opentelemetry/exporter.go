@@ -0,0 +1,144 @@
+package opentelemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+const (
+	defaultBatchSize     = 512
+	defaultFlushInterval = 5 * time.Second
+	defaultExportTimeout = 10 * time.Second
+)
+
+// client sends encoded OTLP export requests to a collector.
+type client interface {
+	export(ctx context.Context, payload []byte) error
+	close() error
+}
+
+// exporter batches finished spans and sends them to the collector in the background.
+type exporter struct {
+	client        client
+	serviceName   string
+	batchSize     int
+	flushInterval time.Duration
+	timeout       time.Duration
+
+	queue     chan *span
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+func newExporter(clt client, serviceName string, batchSize int, flushInterval, timeout time.Duration) *exporter {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+	if timeout <= 0 {
+		timeout = defaultExportTimeout
+	}
+
+	e := &exporter{
+		client:        clt,
+		serviceName:   serviceName,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		timeout:       timeout,
+		queue:         make(chan *span, 4*batchSize),
+		done:          make(chan struct{}),
+	}
+
+	e.wg.Add(1)
+	go e.run()
+
+	return e
+}
+
+// enqueue schedules a finished span for export, dropping it if the queue is full.
+func (e *exporter) enqueue(s *span) {
+	select {
+	case <-e.done:
+	case e.queue <- s:
+	default:
+		log.WithoutContext().WithField(log.TracingProviderName, Name).Debug("Export queue is full, dropping span")
+	}
+}
+
+func (e *exporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*span, 0, e.batchSize)
+	for {
+		select {
+		case s := <-e.queue:
+			batch = append(batch, s)
+			if len(batch) >= e.batchSize {
+				e.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			e.flush(batch)
+			batch = batch[:0]
+		case <-e.done:
+			for {
+				select {
+				case s := <-e.queue:
+					batch = append(batch, s)
+					if len(batch) >= e.batchSize {
+						e.flush(batch)
+						batch = batch[:0]
+					}
+				default:
+					e.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *exporter) flush(batch []*span) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	if err := e.client.export(ctx, encodeExportRequest(e.serviceName, batch)); err != nil {
+		log.WithoutContext().WithField(log.TracingProviderName, Name).Errorf("Unable to export %d spans: %v", len(batch), err)
+	}
+}
+
+// Close flushes the pending spans and shuts the exporter down.
+func (e *exporter) Close() error {
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.done)
+
+		flushed := make(chan struct{})
+		go func() {
+			e.wg.Wait()
+			close(flushed)
+		}()
+
+		select {
+		case <-flushed:
+		case <-time.After(e.timeout):
+			log.WithoutContext().WithField(log.TracingProviderName, Name).Warn("Timeout while flushing pending spans")
+		}
+
+		err = e.client.close()
+	})
+	return err
+}
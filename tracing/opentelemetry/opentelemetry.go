@@ -0,0 +1,69 @@
+package opentelemetry
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/log"
+	"github.com/opentracing/opentracing-go"
+)
+
+// Name sets the name of this tracer.
+const Name = "opentelemetry"
+
+const (
+	// ProtocolGRPC exports spans with OTLP over gRPC.
+	ProtocolGRPC = "grpc"
+	// ProtocolHTTP exports spans with OTLP over HTTP (protobuf payloads).
+	ProtocolHTTP = "http"
+)
+
+// Config provides configuration settings for an OpenTelemetry (OTLP) tracer.
+type Config struct {
+	Endpoint      string            `description:"OTLP collector endpoint: host:port for gRPC, URL for HTTP." export:"false"`
+	Protocol      string            `description:"OTLP transport protocol (grpc/http)." export:"true"`
+	Insecure      bool              `description:"Connect to the collector without TLS." export:"true"`
+	Headers       map[string]string `description:"Headers (gRPC metadata) sent with the export requests." export:"false"`
+	Sampler       string            `description:"Sampler to use (parentbased_ratio/ratio/always_on/always_off)." export:"true"`
+	SamplingRatio float64           `description:"The rate between 0.0 and 1.0 of traces to sample." export:"true"`
+	BatchSize     int               `description:"Maximum number of spans sent in a single export request." export:"true"`
+	FlushInterval parse.Duration    `description:"Maximum duration spans are kept before being exported." export:"true"`
+	ExportTimeout parse.Duration    `description:"Timeout of a single export request, also bounds the final flush on shutdown." export:"true"`
+}
+
+// Setup sets up the tracer
+func (c *Config) Setup(serviceName string) (opentracing.Tracer, io.Closer, error) {
+	smplr, err := newSampler(c.Sampler, c.SamplingRatio)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var clt client
+	switch c.Protocol {
+	case ProtocolGRPC, "":
+		clt, err = newGRPCClient(c.Endpoint, c.Insecure, c.Headers)
+	case ProtocolHTTP:
+		clt, err = newHTTPClient(c.Endpoint, c.Headers)
+	default:
+		return nil, nil, fmt.Errorf("unknown OTLP protocol: %s", c.Protocol)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exp := newExporter(clt, serviceName, c.BatchSize, time.Duration(c.FlushInterval), time.Duration(c.ExportTimeout))
+
+	tracer := &tracer{
+		sampler:  smplr,
+		exporter: exp,
+	}
+
+	// Without this, child spans are getting the NOOP tracer
+	opentracing.SetGlobalTracer(tracer)
+
+	log.WithoutContext().Debug("OpenTelemetry tracer configured")
+
+	return tracer, exp, nil
+}
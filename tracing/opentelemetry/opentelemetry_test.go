@@ -0,0 +1,252 @@
+package opentelemetry
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/tracing"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		headers         map[string]string
+		expectedErr     error
+		expectedSampled bool
+		expectedBaggage map[string]string
+	}{
+		{
+			desc:        "no traceparent",
+			headers:     map[string]string{},
+			expectedErr: opentracing.ErrSpanContextNotFound,
+		},
+		{
+			desc: "sampled traceparent with baggage",
+			headers: map[string]string{
+				"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"Tracestate":  "foo=bar",
+				"Baggage":     "userId=alice,serverNode=DF%2028;prop=1",
+			},
+			expectedSampled: true,
+			expectedBaggage: map[string]string{"userId": "alice", "serverNode": "DF 28"},
+		},
+		{
+			desc: "not sampled traceparent",
+			headers: map[string]string{
+				"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			},
+		},
+		{
+			desc: "invalid version",
+			headers: map[string]string{
+				"Traceparent": "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			expectedErr: opentracing.ErrSpanContextCorrupted,
+		},
+		{
+			desc: "zero trace ID",
+			headers: map[string]string{
+				"Traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			},
+			expectedErr: opentracing.ErrSpanContextCorrupted,
+		},
+		{
+			desc: "uppercase hex",
+			headers: map[string]string{
+				"Traceparent": "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			},
+			expectedErr: opentracing.ErrSpanContextCorrupted,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			header := make(http.Header)
+			for k, v := range test.headers {
+				header.Set(k, v)
+			}
+
+			tr := &tracer{}
+			spanCtx, err := tr.Extract(opentracing.HTTPHeaders, tracing.HTTPHeadersCarrier(header))
+			if test.expectedErr != nil {
+				assert.Equal(t, test.expectedErr, err)
+				assert.Nil(t, spanCtx)
+				return
+			}
+			require.NoError(t, err)
+
+			sc := spanCtx.(spanContext)
+			assert.Equal(t, test.expectedSampled, sc.sampled)
+			assert.Equal(t, test.expectedBaggage, sc.baggage)
+			assert.Equal(t, test.headers["Tracestate"], sc.traceState)
+		})
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	smplr, err := newSampler(SamplerAlwaysOn, 0)
+	require.NoError(t, err)
+
+	tr := &tracer{sampler: smplr}
+
+	parent := tr.StartSpan("parent")
+	parent.SetBaggageItem("tenant", "a b")
+
+	header := make(http.Header)
+	err = tr.Inject(parent.Context(), opentracing.HTTPHeaders, tracing.HTTPHeadersCarrier(header))
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, header.Get("traceparent"))
+	assert.Equal(t, "tenant=a%20b", header.Get("baggage"))
+
+	extracted, err := tr.Extract(opentracing.HTTPHeaders, tracing.HTTPHeadersCarrier(header))
+	require.NoError(t, err)
+
+	child := tr.StartSpan("child", ext.RPCServerOption(extracted)).(*span)
+
+	parentCtx := parent.Context().(spanContext)
+	assert.Equal(t, parentCtx.traceID, child.context.traceID)
+	assert.Equal(t, parentCtx.spanID, child.parentSpanID)
+	assert.NotEqual(t, parentCtx.spanID, child.context.spanID)
+	assert.Equal(t, "a b", child.BaggageItem("tenant"))
+	assert.EqualValues(t, 2, child.kind())
+}
+
+func TestSampler(t *testing.T) {
+	id := traceID{0, 0, 0, 0, 0, 0, 0, 0, 0x10, 0, 0, 0, 0, 0, 0, 0}
+
+	testCases := []struct {
+		desc     string
+		name     string
+		ratio    float64
+		parent   *spanContext
+		expected bool
+	}{
+		{
+			desc:     "always on",
+			name:     SamplerAlwaysOn,
+			expected: true,
+		},
+		{
+			desc: "always off",
+			name: SamplerAlwaysOff,
+		},
+		{
+			desc:     "ratio below the trace ID bound",
+			name:     SamplerRatio,
+			ratio:    0.5,
+			expected: true,
+		},
+		{
+			desc:  "ratio above the trace ID bound",
+			name:  SamplerRatio,
+			ratio: 0.01,
+		},
+		{
+			desc:     "parent based follows a sampled parent",
+			name:     SamplerParentBasedRatio,
+			ratio:    0,
+			parent:   &spanContext{sampled: true},
+			expected: true,
+		},
+		{
+			desc:   "parent based follows a not sampled parent",
+			name:   SamplerParentBasedRatio,
+			ratio:  1,
+			parent: &spanContext{sampled: false},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			smplr, err := newSampler(test.name, test.ratio)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, smplr(test.parent, id))
+		})
+	}
+}
+
+func TestSetup_HTTPExport(t *testing.T) {
+	var mu sync.Mutex
+	var payloads [][]byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", req.Header.Get("X-Api-Token"))
+
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		payloads = append(payloads, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Endpoint:      server.URL,
+		Protocol:      ProtocolHTTP,
+		Headers:       map[string]string{"X-Api-Token": "secret"},
+		Sampler:       SamplerAlwaysOn,
+		BatchSize:     10,
+		FlushInterval: parse.Duration(60e9),
+	}
+
+	tr, closer, err := config.Setup("traefik-test")
+	require.NoError(t, err)
+
+	span := tr.StartSpan("EntryPoint web", ext.SpanKindRPCServer)
+	span.SetTag("router.name", "my-router")
+	ext.Error.Set(span, true)
+	span.LogKV("event", "something happened")
+	span.Finish()
+
+	// Close must flush the pending span even though the flush interval has not elapsed.
+	require.NoError(t, closer.Close())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, payloads, 1)
+
+	assert.True(t, bytes.Contains(payloads[0], []byte("traefik-test")))
+	assert.True(t, bytes.Contains(payloads[0], []byte("EntryPoint web")))
+	assert.True(t, bytes.Contains(payloads[0], []byte("my-router")))
+	assert.True(t, bytes.Contains(payloads[0], []byte("something happened")))
+	assert.False(t, bytes.Contains(payloads[0], []byte("span.kind")))
+}
+
+func TestSamplingPriority(t *testing.T) {
+	smplr, err := newSampler(SamplerRatio, 0)
+	require.NoError(t, err)
+
+	tr := &tracer{sampler: smplr}
+
+	root := tr.StartSpan("root")
+	assert.False(t, root.Context().(spanContext).sampled)
+
+	ext.SamplingPriority.Set(root, 1)
+	assert.True(t, root.Context().(spanContext).sampled)
+	assert.NotContains(t, root.(*span).tags, string(ext.SamplingPriority))
+
+	child := tr.StartSpan("child", opentracing.ChildOf(root.Context()))
+	assert.True(t, child.Context().(spanContext).sampled)
+
+	ext.SamplingPriority.Set(child, 0)
+	assert.False(t, child.Context().(spanContext).sampled)
+}
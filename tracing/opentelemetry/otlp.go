@@ -0,0 +1,210 @@
+package opentelemetry
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// instrumentationName is reported as the OTLP instrumentation scope.
+const instrumentationName = "github.com/containous/traefik"
+
+// OTLP status codes.
+const (
+	statusCodeUnset = 0
+	statusCodeError = 2
+)
+
+// pbBuffer is a minimal protocol buffers writer, enough to encode the OTLP trace messages.
+type pbBuffer []byte
+
+func (b *pbBuffer) key(field int, wireType int) {
+	b.varintRaw(uint64(field<<3 | wireType))
+}
+
+func (b *pbBuffer) varintRaw(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	*b = append(*b, tmp[:n]...)
+}
+
+func (b *pbBuffer) varint(field int, v uint64) {
+	b.key(field, 0)
+	b.varintRaw(v)
+}
+
+func (b *pbBuffer) fixed64(field int, v uint64) {
+	b.key(field, 1)
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	*b = append(*b, tmp[:]...)
+}
+
+func (b *pbBuffer) bytes(field int, v []byte) {
+	b.key(field, 2)
+	b.varintRaw(uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *pbBuffer) string(field int, v string) {
+	b.bytes(field, []byte(v))
+}
+
+func (b *pbBuffer) message(field int, encode func(*pbBuffer)) {
+	var nested pbBuffer
+	encode(&nested)
+	b.bytes(field, nested)
+}
+
+// encodeExportRequest encodes an opentelemetry.proto.collector.trace.v1.ExportTraceServiceRequest.
+func encodeExportRequest(serviceName string, spans []*span) []byte {
+	var b pbBuffer
+
+	// resource_spans
+	b.message(1, func(rs *pbBuffer) {
+		// resource
+		rs.message(1, func(r *pbBuffer) {
+			encodeAttribute(r, 1, "service.name", serviceName)
+		})
+
+		// scope_spans
+		rs.message(2, func(ss *pbBuffer) {
+			ss.message(1, func(scope *pbBuffer) {
+				scope.string(1, instrumentationName)
+			})
+
+			for _, s := range spans {
+				ss.message(2, s.encode)
+			}
+		})
+	})
+
+	return b
+}
+
+// encode encodes the span as an opentelemetry.proto.trace.v1.Span.
+func (s *span) encode(b *pbBuffer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b.bytes(1, s.context.traceID[:])
+	b.bytes(2, s.context.spanID[:])
+	if len(s.context.traceState) > 0 {
+		b.string(3, s.context.traceState)
+	}
+	if s.parentSpanID != (spanID{}) {
+		b.bytes(4, s.parentSpanID[:])
+	}
+	b.string(5, s.operationName)
+	b.varint(6, s.kind())
+	b.fixed64(7, uint64(s.startTime.UnixNano()))
+	b.fixed64(8, uint64(s.endTime.UnixNano()))
+
+	statusCode := statusCodeUnset
+	keys := make([]string, 0, len(s.tags))
+	for k := range s.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := s.tags[k]
+		switch k {
+		case string(ext.SpanKind):
+			continue
+		case string(ext.Error):
+			if isError, ok := v.(bool); ok && isError {
+				statusCode = statusCodeError
+			}
+		}
+		encodeAttribute(b, 9, k, v)
+	}
+
+	for _, e := range s.events {
+		encodeEvent(b, e)
+	}
+
+	if statusCode != statusCodeUnset {
+		b.message(15, func(status *pbBuffer) {
+			status.varint(3, uint64(statusCode))
+		})
+	}
+}
+
+func encodeEvent(b *pbBuffer, e event) {
+	b.message(11, func(eb *pbBuffer) {
+		t := e.time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		eb.fixed64(1, uint64(t.UnixNano()))
+
+		name := "log"
+		for _, f := range e.fields {
+			if f.Key() == "event" {
+				name = fmt.Sprint(f.Value())
+				continue
+			}
+			encodeAttribute(eb, 3, f.Key(), f.Value())
+		}
+		eb.string(2, name)
+	})
+}
+
+// encodeAttribute encodes an opentelemetry.proto.common.v1.KeyValue in the given field.
+func encodeAttribute(b *pbBuffer, field int, key string, value interface{}) {
+	b.message(field, func(kv *pbBuffer) {
+		kv.string(1, key)
+		kv.message(2, func(av *pbBuffer) {
+			encodeAnyValue(av, value)
+		})
+	})
+}
+
+func encodeAnyValue(b *pbBuffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		b.string(1, v)
+	case bool:
+		if v {
+			b.varint(2, 1)
+		} else {
+			b.varint(2, 0)
+		}
+	case int:
+		b.varint(3, uint64(v))
+	case int8:
+		b.varint(3, uint64(v))
+	case int16:
+		b.varint(3, uint64(v))
+	case int32:
+		b.varint(3, uint64(v))
+	case int64:
+		b.varint(3, uint64(v))
+	case uint:
+		b.varint(3, uint64(v))
+	case uint8:
+		b.varint(3, uint64(v))
+	case uint16:
+		b.varint(3, uint64(v))
+	case uint32:
+		b.varint(3, uint64(v))
+	case uint64:
+		b.varint(3, v)
+	case float32:
+		b.fixed64(4, math.Float64bits(float64(v)))
+	case float64:
+		b.fixed64(4, math.Float64bits(v))
+	case error:
+		b.string(1, v.Error())
+	case otlog.Field:
+		b.string(1, v.String())
+	default:
+		b.string(1, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,150 @@
+package opentelemetry
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+// W3C trace context and baggage headers.
+const (
+	traceParentHeader = "traceparent"
+	traceStateHeader  = "tracestate"
+	baggageHeader     = "baggage"
+
+	supportedVersion = "00"
+	sampledFlag      = 0x01
+)
+
+func inject(sc spanContext, writer opentracing.TextMapWriter) {
+	flags := byte(0)
+	if sc.sampled {
+		flags |= sampledFlag
+	}
+
+	writer.Set(traceParentHeader, fmt.Sprintf("%s-%s-%s-%02x",
+		supportedVersion, hex.EncodeToString(sc.traceID[:]), hex.EncodeToString(sc.spanID[:]), flags))
+
+	if len(sc.traceState) > 0 {
+		writer.Set(traceStateHeader, sc.traceState)
+	}
+
+	if len(sc.baggage) > 0 {
+		var members []string
+		for k, v := range sc.baggage {
+			members = append(members, k+"="+url.PathEscape(v))
+		}
+		sort.Strings(members)
+		writer.Set(baggageHeader, strings.Join(members, ","))
+	}
+}
+
+func extract(reader opentracing.TextMapReader) (spanContext, error) {
+	var traceParent, traceState, baggage string
+	err := reader.ForeachKey(func(key, val string) error {
+		switch strings.ToLower(key) {
+		case traceParentHeader:
+			traceParent = val
+		case traceStateHeader:
+			traceState = val
+		case baggageHeader:
+			if len(baggage) > 0 {
+				baggage += ","
+			}
+			baggage += val
+		}
+		return nil
+	})
+	if err != nil {
+		return spanContext{}, err
+	}
+
+	if len(traceParent) == 0 {
+		return spanContext{}, opentracing.ErrSpanContextNotFound
+	}
+
+	sc, err := parseTraceParent(traceParent)
+	if err != nil {
+		return spanContext{}, err
+	}
+
+	sc.traceState = traceState
+	sc.baggage = parseBaggage(baggage)
+
+	return sc, nil
+}
+
+// parseTraceParent parses a traceparent header: version-traceid-spanid-flags.
+func parseTraceParent(value string) (spanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return spanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+
+	version := parts[0]
+	if len(version) != 2 || version == "ff" || (version == supportedVersion && len(parts) != 4) {
+		return spanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+
+	var sc spanContext
+	if err := decodeHex(parts[1], sc.traceID[:]); err != nil || sc.traceID == (traceID{}) {
+		return spanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+
+	if err := decodeHex(parts[2], sc.spanID[:]); err != nil || sc.spanID == (spanID{}) {
+		return spanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+
+	var flags [1]byte
+	if err := decodeHex(parts[3], flags[:]); err != nil {
+		return spanContext{}, opentracing.ErrSpanContextCorrupted
+	}
+	sc.sampled = flags[0]&sampledFlag == sampledFlag
+
+	return sc, nil
+}
+
+func decodeHex(value string, dst []byte) error {
+	if len(value) != hex.EncodedLen(len(dst)) || strings.ToLower(value) != value {
+		return fmt.Errorf("invalid hex value: %q", value)
+	}
+	_, err := hex.Decode(dst, []byte(value))
+	return err
+}
+
+// parseBaggage parses a W3C baggage header, ignoring member properties.
+func parseBaggage(value string) map[string]string {
+	if len(value) == 0 {
+		return nil
+	}
+
+	baggage := make(map[string]string)
+	for _, member := range strings.Split(value, ",") {
+		member = strings.SplitN(member, ";", 2)[0]
+
+		kv := strings.SplitN(member, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(kv[0])
+		if len(key) == 0 {
+			continue
+		}
+
+		val, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+		if err != nil {
+			continue
+		}
+		baggage[key] = val
+	}
+
+	if len(baggage) == 0 {
+		return nil
+	}
+	return baggage
+}
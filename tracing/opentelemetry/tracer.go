@@ -0,0 +1,361 @@
+package opentelemetry
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// Sampler names.
+const (
+	SamplerParentBasedRatio = "parentbased_ratio"
+	SamplerRatio            = "ratio"
+	SamplerAlwaysOn         = "always_on"
+	SamplerAlwaysOff        = "always_off"
+)
+
+type traceID [16]byte
+
+type spanID [8]byte
+
+// spanContext is the propagated part of a span.
+type spanContext struct {
+	traceID traceID
+	spanID  spanID
+	sampled bool
+	// prioritized is set when the sampling decision is overridden through the sampling priority,
+	// which the local child spans follow instead of the sampler.
+	prioritized bool
+	traceState  string
+	baggage     map[string]string
+}
+
+// ForeachBaggageItem conforms to the opentracing.SpanContext interface.
+func (c spanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c.baggage {
+		if !handler(k, v) {
+			break
+		}
+	}
+}
+
+func (c spanContext) withBaggageItem(key, value string) spanContext {
+	baggage := make(map[string]string, len(c.baggage)+1)
+	for k, v := range c.baggage {
+		baggage[k] = v
+	}
+	baggage[key] = value
+	c.baggage = baggage
+	return c
+}
+
+// sampler decides whether a new trace (or child span) is recorded.
+type sampler func(parent *spanContext, id traceID) bool
+
+func newSampler(name string, ratio float64) (sampler, error) {
+	switch name {
+	case SamplerAlwaysOn:
+		return func(_ *spanContext, _ traceID) bool { return true }, nil
+	case SamplerAlwaysOff:
+		return func(_ *spanContext, _ traceID) bool { return false }, nil
+	case SamplerRatio:
+		return ratioSampler(ratio), nil
+	case SamplerParentBasedRatio, "":
+		root := ratioSampler(ratio)
+		return func(parent *spanContext, id traceID) bool {
+			if parent != nil {
+				return parent.sampled
+			}
+			return root(nil, id)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown sampler: %s", name)
+	}
+}
+
+// ratioSampler samples deterministically on the trace ID,
+// so that every participant of a trace takes the same decision.
+func ratioSampler(ratio float64) sampler {
+	if ratio >= 1 {
+		return func(_ *spanContext, _ traceID) bool { return true }
+	}
+	if ratio <= 0 {
+		return func(_ *spanContext, _ traceID) bool { return false }
+	}
+
+	upperBound := uint64(ratio * (1 << 63))
+	return func(_ *spanContext, id traceID) bool {
+		return binary.BigEndian.Uint64(id[8:16])>>1 < upperBound
+	}
+}
+
+type tracer struct {
+	sampler  sampler
+	exporter *exporter
+}
+
+// StartSpan conforms to the opentracing.Tracer interface.
+func (t *tracer) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	sso := opentracing.StartSpanOptions{}
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+
+	var parent *spanContext
+	for _, ref := range sso.References {
+		if sc, ok := ref.ReferencedContext.(spanContext); ok {
+			parent = &sc
+			break
+		}
+	}
+
+	s := &span{
+		tracer:        t,
+		operationName: operationName,
+		startTime:     sso.StartTime,
+		tags:          make(map[string]interface{}, len(sso.Tags)),
+	}
+	if s.startTime.IsZero() {
+		s.startTime = time.Now()
+	}
+
+	if parent != nil {
+		s.parentSpanID = parent.spanID
+		s.context = spanContext{
+			traceID:    parent.traceID,
+			traceState: parent.traceState,
+			baggage:    parent.baggage,
+		}
+	} else {
+		s.context.traceID = newTraceID()
+	}
+	s.context.spanID = newSpanID()
+
+	if parent != nil && parent.prioritized {
+		s.context.sampled = parent.sampled
+		s.context.prioritized = true
+	} else {
+		s.context.sampled = t.sampler(parent, s.context.traceID)
+	}
+
+	for k, v := range sso.Tags {
+		s.tags[k] = v
+	}
+
+	return s
+}
+
+// Inject conforms to the opentracing.Tracer interface.
+func (t *tracer) Inject(sm opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	sc, ok := sm.(spanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+
+	switch format {
+	case opentracing.HTTPHeaders, opentracing.TextMap:
+		writer, ok := carrier.(opentracing.TextMapWriter)
+		if !ok {
+			return opentracing.ErrInvalidCarrier
+		}
+		inject(sc, writer)
+		return nil
+	default:
+		return opentracing.ErrUnsupportedFormat
+	}
+}
+
+// Extract conforms to the opentracing.Tracer interface.
+func (t *tracer) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	switch format {
+	case opentracing.HTTPHeaders, opentracing.TextMap:
+		reader, ok := carrier.(opentracing.TextMapReader)
+		if !ok {
+			return nil, opentracing.ErrInvalidCarrier
+		}
+
+		sc, err := extract(reader)
+		if err != nil {
+			return nil, err
+		}
+		return sc, nil
+	default:
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+}
+
+type event struct {
+	time   time.Time
+	fields []otlog.Field
+}
+
+type span struct {
+	tracer *tracer
+
+	mu            sync.Mutex
+	context       spanContext
+	parentSpanID  spanID
+	operationName string
+	startTime     time.Time
+	endTime       time.Time
+	tags          map[string]interface{}
+	events        []event
+	finished      bool
+}
+
+// Finish conforms to the opentracing.Span interface.
+func (s *span) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// FinishWithOptions conforms to the opentracing.Span interface.
+func (s *span) FinishWithOptions(opts opentracing.FinishOptions) {
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+
+	s.endTime = opts.FinishTime
+	if s.endTime.IsZero() {
+		s.endTime = time.Now()
+	}
+
+	for _, record := range opts.LogRecords {
+		s.events = append(s.events, event{time: record.Timestamp, fields: record.Fields})
+	}
+	for _, data := range opts.BulkLogData {
+		record := data.ToLogRecord()
+		s.events = append(s.events, event{time: record.Timestamp, fields: record.Fields})
+	}
+	s.mu.Unlock()
+
+	if s.context.sampled {
+		s.tracer.exporter.enqueue(s)
+	}
+}
+
+// Context conforms to the opentracing.Span interface.
+func (s *span) Context() opentracing.SpanContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.context
+}
+
+// SetOperationName conforms to the opentracing.Span interface.
+func (s *span) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operationName = operationName
+	return s
+}
+
+// SetTag conforms to the opentracing.Span interface.
+func (s *span) SetTag(key string, value interface{}) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if priority, ok := value.(uint16); ok && key == string(ext.SamplingPriority) {
+		s.context.sampled = priority != 0
+		s.context.prioritized = true
+		return s
+	}
+
+	s.tags[key] = value
+	return s
+}
+
+// LogFields conforms to the opentracing.Span interface.
+func (s *span) LogFields(fields ...otlog.Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event{time: time.Now(), fields: fields})
+}
+
+// LogKV conforms to the opentracing.Span interface.
+func (s *span) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := otlog.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(otlog.Error(err), otlog.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// SetBaggageItem conforms to the opentracing.Span interface.
+func (s *span) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.context = s.context.withBaggageItem(restrictedKey, value)
+	return s
+}
+
+// BaggageItem conforms to the opentracing.Span interface.
+func (s *span) BaggageItem(restrictedKey string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.context.baggage[restrictedKey]
+}
+
+// Tracer conforms to the opentracing.Span interface.
+func (s *span) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// LogEvent conforms to the opentracing.Span interface.
+func (s *span) LogEvent(event string) {
+	s.LogFields(otlog.String("event", event))
+}
+
+// LogEventWithPayload conforms to the opentracing.Span interface.
+func (s *span) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(otlog.String("event", event), otlog.Object("payload", payload))
+}
+
+// Log conforms to the opentracing.Span interface.
+func (s *span) Log(data opentracing.LogData) {
+	record := data.ToLogRecord()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event{time: record.Timestamp, fields: record.Fields})
+}
+
+// kind maps the OpenTracing span.kind tag to the OTLP span kind.
+func (s *span) kind() uint64 {
+	switch s.tags[string(ext.SpanKind)] {
+	case ext.SpanKindRPCServerEnum, string(ext.SpanKindRPCServerEnum):
+		return 2
+	case ext.SpanKindRPCClientEnum, string(ext.SpanKindRPCClientEnum):
+		return 3
+	case ext.SpanKindProducerEnum, string(ext.SpanKindProducerEnum):
+		return 4
+	case ext.SpanKindConsumerEnum, string(ext.SpanKindConsumerEnum):
+		return 5
+	default:
+		return 1
+	}
+}
+
+func newTraceID() traceID {
+	var id traceID
+	for id == (traceID{}) {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() spanID {
+	var id spanID
+	for id == (spanID{}) {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
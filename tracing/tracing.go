@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/containous/traefik/ip"
 	"github.com/containous/traefik/log"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -47,8 +48,10 @@ type Tracing struct {
 	ServiceName   string `description:"Set the name for this service" export:"true"`
 	SpanNameLimit int    `description:"Set the maximum character limit for Span names (default 0 = no limit)" export:"true"`
 
-	tracer opentracing.Tracer
-	closer io.Closer
+	tracer      opentracing.Tracer
+	closer      io.Closer
+	debugHeader string
+	debugIPs    *ip.Checker
 }
 
 // NewTracing Creates a Tracing.
@@ -66,6 +69,27 @@ func NewTracing(serviceName string, spanNameLimit int, trackingBackend TrackingB
 	return tracing, nil
 }
 
+// SetDebugHeader makes the requests holding the header, sent from the trusted IPs, always sampled.
+func (t *Tracing) SetDebugHeader(header string, trustedIPs []string) error {
+	checker, err := ip.NewChecker(trustedIPs)
+	if err != nil {
+		return err
+	}
+
+	t.debugHeader = header
+	t.debugIPs = checker
+	return nil
+}
+
+// IsDebugRequest returns whether the request holds the debug header, and is sent from a trusted IP.
+func (t *Tracing) IsDebugRequest(req *http.Request) bool {
+	if t == nil || len(t.debugHeader) == 0 || len(req.Header.Get(t.debugHeader)) == 0 {
+		return false
+	}
+
+	return t.debugIPs.IsAuthorized(req.RemoteAddr) == nil
+}
+
 // StartSpan delegates to opentracing.Tracer.
 func (t *Tracing) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
 	return t.tracer.StartSpan(operationName, opts...)
@@ -125,6 +149,21 @@ func LogResponseCode(span opentracing.Span, code int) {
 	}
 }
 
+// SetSampled overrides the sampling decision of the trace of the span,
+// through the sampling priority honored by the tracing backends.
+// The spans started afterwards in the trace follow the decision.
+func SetSampled(span opentracing.Span, sampled bool) {
+	if span == nil {
+		return
+	}
+
+	var priority uint16
+	if sampled {
+		priority = 1
+	}
+	ext.SamplingPriority.Set(span, priority)
+}
+
 // GetSpan used to retrieve span from request context.
 func GetSpan(r *http.Request) opentracing.Span {
 	return opentracing.SpanFromContext(r.Context())
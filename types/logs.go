@@ -25,18 +25,59 @@ type TraefikLog struct {
 
 // AccessLog holds the configuration settings for the access logger (middlewares/accesslog).
 type AccessLog struct {
-	FilePath      string            `json:"file,omitempty" description:"Access log file path. Stdout is used when omitted or empty" export:"true"`
-	Format        string            `json:"format,omitempty" description:"Access log format: json | common" export:"true"`
-	Filters       *AccessLogFilters `json:"filters,omitempty" description:"Access log filters, used to keep only specific access logs" export:"true"`
-	Fields        *AccessLogFields  `json:"fields,omitempty" description:"AccessLogFields" export:"true"`
-	BufferingSize int64             `json:"bufferingSize,omitempty" description:"Number of access log lines to process in a buffered way. Default 0." export:"true"`
+	FilePath      string             `json:"file,omitempty" description:"Access log file path. Stdout is used when omitted or empty and no other sink is configured" export:"true"`
+	Format        string             `json:"format,omitempty" description:"Access log format: json | logfmt | common" export:"true"`
+	Filters       *AccessLogFilters  `json:"filters,omitempty" description:"Access log filters, used to keep only specific access logs" export:"true"`
+	Fields        *AccessLogFields   `json:"fields,omitempty" description:"AccessLogFields" export:"true"`
+	StaticFields  map[string]string  `json:"staticFields,omitempty" description:"Fields added to every access log, like the name of the instance" export:"true"`
+	BufferingSize int64              `json:"bufferingSize,omitempty" description:"Number of access log lines to process in a buffered way. Default 0." export:"true"`
+	DropWhenFull  bool               `json:"dropWhenFull,omitempty" description:"Drop and count the access log lines when the buffering queue is full, instead of waiting for room in it" export:"true"`
+	Rotation      *AccessLogRotation `json:"rotation,omitempty" description:"Size based rotation of the access log file" export:"true"`
+	Syslog        *AccessLogSyslog   `json:"syslog,omitempty" description:"Send access logs to a syslog daemon" export:"true"`
+	Kafka         *AccessLogKafka    `json:"kafka,omitempty" description:"Send access logs to a Kafka topic" export:"true"`
+	HTTP          *AccessLogHTTP     `json:"http,omitempty" description:"Send access logs in bulk to an HTTP endpoint" export:"true"`
+	Buffer        *AccessLogBuffer   `json:"buffer,omitempty" description:"Keep the last access logs in memory, queryable through the API" export:"true"`
 }
 
 // AccessLogFilters holds filters configuration
 type AccessLogFilters struct {
-	StatusCodes   StatusCodes    `json:"statusCodes,omitempty" description:"Keep access logs with status codes in the specified range" export:"true"`
-	RetryAttempts bool           `json:"retryAttempts,omitempty" description:"Keep access logs when at least one retry happened" export:"true"`
-	MinDuration   parse.Duration `json:"duration,omitempty" description:"Keep access logs when request took longer than the specified duration" export:"true"`
+	StatusCodes     StatusCodes    `json:"statusCodes,omitempty" description:"Keep access logs with status codes in the specified range" export:"true"`
+	RetryAttempts   bool           `json:"retryAttempts,omitempty" description:"Keep access logs when at least one retry happened" export:"true"`
+	MinDuration     parse.Duration `json:"duration,omitempty" description:"Keep access logs when request took longer than the specified duration" export:"true"`
+	ExcludedRouters []string       `json:"excludedRouters,omitempty" description:"Drop access logs of the specified routers" export:"true"`
+	SuccessSampling float64        `json:"successSampling,omitempty" description:"Rate between 0.0 and 1.0 of successful (non 4xx/5xx) access logs to keep, among the ones kept by the other filters. Disabled when 0." export:"true"`
+}
+
+// AccessLogRotation holds the size based rotation configuration of the access log file
+type AccessLogRotation struct {
+	MaxSize    int64 `json:"maxSize,omitempty" description:"Maximum size in megabytes of the access log file before it gets rotated" export:"true"`
+	MaxBackups int   `json:"maxBackups,omitempty" description:"Maximum number of rotated access log files to retain. Default 0 (keep all)." export:"true"`
+}
+
+// AccessLogSyslog holds the syslog sink configuration
+type AccessLogSyslog struct {
+	Network string `json:"network,omitempty" description:"Network used to reach the syslog daemon: udp | tcp. The local daemon is used when empty" export:"true"`
+	Address string `json:"address,omitempty" description:"Address of the syslog daemon" export:"true"`
+	Tag     string `json:"tag,omitempty" description:"Tag of the syslog messages" export:"true"`
+}
+
+// AccessLogKafka holds the Kafka sink configuration
+type AccessLogKafka struct {
+	Brokers []string `json:"brokers,omitempty" description:"Addresses of the Kafka brokers" export:"true"`
+	Topic   string   `json:"topic,omitempty" description:"Kafka topic the access logs are produced to" export:"true"`
+}
+
+// AccessLogHTTP holds the HTTP bulk sink configuration
+type AccessLogHTTP struct {
+	Endpoint      string            `json:"endpoint,omitempty" description:"URL the access logs are posted to" export:"false"`
+	Headers       map[string]string `json:"headers,omitempty" description:"Headers added to the requests posting the access logs, like an API key" export:"false"`
+	BatchSize     int               `json:"batchSize,omitempty" description:"Maximum number of access log lines sent in a single request" export:"true"`
+	FlushInterval parse.Duration    `json:"flushInterval,omitempty" description:"Maximum duration access log lines are kept before being sent" export:"true"`
+}
+
+// AccessLogBuffer holds the in-memory buffer configuration
+type AccessLogBuffer struct {
+	Size int `json:"size,omitempty" description:"Number of access logs kept in memory. Default 1000." export:"true"`
 }
 
 // FieldHeaders holds configuration for access log headers
@@ -182,6 +223,20 @@ func (f *AccessLogFields) KeepHeader(header string) string {
 	return defaultValue
 }
 
+// DropsAllHeaders checks if all the headers are dropped, whatever their name
+func (f *AccessLogFields) DropsAllHeaders() bool {
+	if f == nil || f.Headers == nil || checkFieldHeaderValue(f.Headers.DefaultMode, AccessLogKeep) != AccessLogDrop {
+		return false
+	}
+
+	for _, v := range f.Headers.Names {
+		if checkFieldHeaderValue(v, AccessLogDrop) != AccessLogDrop {
+			return false
+		}
+	}
+	return true
+}
+
 func checkFieldValue(value string, defaultKeep bool) bool {
 	switch value {
 	case AccessLogKeep:
@@ -417,3 +417,63 @@ func TestFieldsHeadersNamesSetValue(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessLogFieldsDropsAllHeaders(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		fields   *AccessLogFields
+		expected bool
+	}{
+		{
+			desc: "Should keep the headers without fields",
+		},
+		{
+			desc:   "Should keep the headers without headers fields",
+			fields: &AccessLogFields{},
+		},
+		{
+			desc:   "Should keep the headers in keep mode",
+			fields: &AccessLogFields{Headers: &FieldHeaders{DefaultMode: AccessLogKeep}},
+		},
+		{
+			desc:   "Should keep the headers in redact mode",
+			fields: &AccessLogFields{Headers: &FieldHeaders{DefaultMode: AccessLogRedact}},
+		},
+		{
+			desc:     "Should drop the headers in drop mode",
+			fields:   &AccessLogFields{Headers: &FieldHeaders{DefaultMode: AccessLogDrop}},
+			expected: true,
+		},
+		{
+			desc: "Should drop the headers in drop mode with dropped names",
+			fields: &AccessLogFields{Headers: &FieldHeaders{
+				DefaultMode: AccessLogDrop,
+				Names:       FieldHeaderNames{"X-HEADER-1": AccessLogDrop, "X-HEADER-2": "foo"},
+			}},
+			expected: true,
+		},
+		{
+			desc: "Should keep the headers in drop mode with a kept name",
+			fields: &AccessLogFields{Headers: &FieldHeaders{
+				DefaultMode: AccessLogDrop,
+				Names:       FieldHeaderNames{"X-HEADER-1": AccessLogDrop, "X-HEADER-2": AccessLogKeep},
+			}},
+		},
+		{
+			desc: "Should keep the headers in drop mode with a redacted name",
+			fields: &AccessLogFields{Headers: &FieldHeaders{
+				DefaultMode: AccessLogDrop,
+				Names:       FieldHeaderNames{"X-HEADER-1": AccessLogRedact},
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, test.fields.DropsAllHeaders())
+		})
+	}
+}
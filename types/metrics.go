@@ -12,13 +12,17 @@ type Metrics struct {
 	Datadog    *Datadog    `description:"DataDog metrics exporter type" export:"true"`
 	StatsD     *Statsd     `description:"StatsD metrics exporter type" export:"true"`
 	InfluxDB   *InfluxDB   `description:"InfluxDB metrics exporter type"`
+	Push       *Push       `description:"Push the Prometheus metrics to a remote endpoint" export:"true"`
 }
 
 // Prometheus can contain specific configuration used by the Prometheus Metrics exporter
 type Prometheus struct {
-	Buckets     Buckets  `description:"Buckets for latency metrics" export:"true"`
-	EntryPoint  string   `description:"EntryPoint" export:"true"`
-	Middlewares []string `description:"Middlewares" export:"true"`
+	Buckets                  Buckets  `description:"Buckets for latency metrics" export:"true"`
+	EntryPoint               string   `description:"EntryPoint" export:"true"`
+	Middlewares              []string `description:"Middlewares" export:"true"`
+	Labels                   []string `description:"Labels of the request metrics to keep (entrypoint, backend, method, protocol, code). All are kept when empty" export:"true"`
+	DisableEntryPointMetrics bool     `description:"Disable the entry points request metrics" export:"true"`
+	DisableBackendMetrics    bool     `description:"Disable the backends request metrics" export:"true"`
 }
 
 // Datadog contains address and metrics pushing interval configuration
@@ -44,6 +48,16 @@ type InfluxDB struct {
 	Password        string `description:"InfluxDB password (only with http)" export:"true"`
 }
 
+// Push contains the configuration to push the Prometheus metrics, for environments where they cannot be scraped
+type Push struct {
+	Protocol     string `description:"Push protocol: pushgateway | remotewrite | otlp" export:"true"`
+	Address      string `description:"URL of the Pushgateway, of the remote write endpoint, or of the OTLP/HTTP metrics endpoint"`
+	Job          string `description:"Job name of the pushed metrics, also used as the OTLP service name" export:"true"`
+	PushInterval string `description:"Push interval" export:"true"`
+	BatchSize    int    `description:"Maximum number of series (remotewrite) or metrics (otlp) sent in a single request" export:"true"`
+	MaxRetries   int    `description:"Maximum number of retries of a failed push" export:"true"`
+}
+
 // Statistics provides options for monitoring request and response stats
 type Statistics struct {
 	RecentErrors int `description:"Number of recent errors logged" export:"true"`
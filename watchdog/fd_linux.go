@@ -0,0 +1,37 @@
+// +build linux
+
+package watchdog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const fileDescriptorsDir = "/proc/self/fd"
+
+// openFileDescriptors returns the paths of the open file descriptors of the process, whose links are their targets.
+func openFileDescriptors() ([]string, error) {
+	dir, err := os.Open(fileDescriptorsDir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = dir.Close() }()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file descriptor of the directory itself is not counted.
+	self := strconv.Itoa(int(dir.Fd()))
+
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != self {
+			paths = append(paths, filepath.Join(fileDescriptorsDir, name))
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package watchdog
+
+import "errors"
+
+// openFileDescriptors returns the paths of the open file descriptors of the process, whose links are their targets.
+func openFileDescriptors() ([]string, error) {
+	return nil, errors.New("counting the open file descriptors is only supported on Linux")
+}
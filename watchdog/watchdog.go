@@ -0,0 +1,281 @@
+package watchdog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/flaeg/parse"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/metrics"
+)
+
+const (
+	// SubsystemProviders labels the goroutines of the providers.
+	SubsystemProviders = "providers"
+	// SubsystemHealthChecks labels the goroutines of the health checks of the services.
+	SubsystemHealthChecks = "healthchecks"
+	// SubsystemWebsockets labels the goroutines of the proxied websocket connections.
+	SubsystemWebsockets = "websockets"
+	// SubsystemOther stands for the goroutines of no subsystem.
+	SubsystemOther = "other"
+
+	subsystemLabel = "subsystem"
+
+	defaultInterval = time.Minute
+)
+
+var subsystemLabelRegexp = regexp.MustCompile(`"` + subsystemLabel + `":"([^"]*)"`)
+
+// Config holds the interval of the checks of the watchdog, and the growths above which it logs the leaking resources.
+type Config struct {
+	Interval                 parse.Duration `description:"Interval between two checks of the goroutines and of the file descriptors (default 1m)" export:"true"`
+	MaxGoroutinesGrowth      int            `description:"Growth of the goroutines of a subsystem, since their lowest count, above which their stacks are logged" export:"true"`
+	MaxFileDescriptorsGrowth int            `description:"Growth of the open file descriptors, since their lowest count, above which they are logged" export:"true"`
+}
+
+// Do calls f with the current goroutine labeled as part of the subsystem,
+// as well as all the goroutines it starts, so that the watchdog counts them under the subsystem.
+func Do(ctx context.Context, subsystem string, f func(context.Context)) {
+	pprof.Do(ctx, pprof.Labels(subsystemLabel, subsystem), f)
+}
+
+// Watchdog periodically counts the goroutines of each subsystem and the open file descriptors, exports them as metrics,
+// and logs the stacks of the goroutines of a subsystem, or the open file descriptors, when they grow above their thresholds,
+// to catch the leaks of the long-running instances.
+// A nil Watchdog does nothing.
+type Watchdog struct {
+	interval                 time.Duration
+	maxGoroutinesGrowth      int
+	maxFileDescriptorsGrowth int
+	registry                 metrics.Registry
+
+	goroutines      lowestCounts
+	fileDescriptors lowestCounts
+
+	goroutineProfile    func() ([]byte, error)
+	openFileDescriptors func() ([]string, error)
+}
+
+// New creates a new Watchdog, nil if the configuration is nil.
+func New(config *Config, registry metrics.Registry) *Watchdog {
+	if config == nil {
+		return nil
+	}
+
+	w := &Watchdog{
+		interval:                 time.Duration(config.Interval),
+		maxGoroutinesGrowth:      config.MaxGoroutinesGrowth,
+		maxFileDescriptorsGrowth: config.MaxFileDescriptorsGrowth,
+		registry:                 registry,
+		goroutines:               make(lowestCounts),
+		fileDescriptors:          make(lowestCounts),
+		goroutineProfile:         goroutineProfile,
+		openFileDescriptors:      openFileDescriptors,
+	}
+
+	if w.interval <= 0 {
+		w.interval = defaultInterval
+	}
+
+	return w
+}
+
+// Run checks the goroutines and the file descriptors periodically, until stop is closed.
+func (w *Watchdog) Run(stop chan bool) {
+	if w == nil {
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.check()
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watchdog) check() {
+	logger := log.WithoutContext()
+
+	profile, err := w.goroutineProfile()
+	if err != nil {
+		logger.Errorf("Unable to count the goroutines: %v", err)
+	} else {
+		w.checkGoroutines(parseGoroutineProfile(profile))
+	}
+
+	fileDescriptors, err := w.openFileDescriptors()
+	if err != nil {
+		logger.Debugf("Unable to count the open file descriptors: %v", err)
+	} else {
+		w.checkFileDescriptors(fileDescriptors)
+	}
+}
+
+func (w *Watchdog) checkGoroutines(records []goroutineRecord) {
+	counts := map[string]int{SubsystemProviders: 0, SubsystemHealthChecks: 0, SubsystemWebsockets: 0, SubsystemOther: 0}
+	for _, record := range records {
+		counts[record.subsystem] += record.count
+	}
+	// The subsystems gone since the previous check are reported without goroutines.
+	for subsystem := range w.goroutines {
+		if _, ok := counts[subsystem]; !ok {
+			counts[subsystem] = 0
+		}
+	}
+
+	for subsystem, count := range counts {
+		w.registry.GoroutinesGauge().With(subsystemLabel, subsystem).Set(float64(count))
+
+		growth, exceeded := w.goroutines.grow(subsystem, count, w.maxGoroutinesGrowth)
+		if !exceeded {
+			continue
+		}
+
+		var stacks []string
+		for _, record := range records {
+			if record.subsystem == subsystem {
+				stacks = append(stacks, record.stack)
+			}
+		}
+		log.WithoutContext().Warnf("The goroutines of the %s grew by %d to %d, their stacks:\n%s", subsystem, growth, count, strings.Join(stacks, "\n"))
+	}
+}
+
+func (w *Watchdog) checkFileDescriptors(fileDescriptors []string) {
+	count := len(fileDescriptors)
+	w.registry.FileDescriptorsGauge().Set(float64(count))
+
+	growth, exceeded := w.fileDescriptors.grow("", count, w.maxFileDescriptorsGrowth)
+	if !exceeded {
+		return
+	}
+
+	kinds := make(map[string]int)
+	for _, fileDescriptor := range fileDescriptors {
+		target, err := os.Readlink(fileDescriptor)
+		if err != nil {
+			// The file descriptor was closed since it was counted.
+			continue
+		}
+		kinds[fileDescriptorKind(target)]++
+	}
+
+	var summary []string
+	for kind, n := range kinds {
+		summary = append(summary, fmt.Sprintf("%s: %d", kind, n))
+	}
+	sort.Strings(summary)
+
+	log.WithoutContext().Warnf("The open file descriptors grew by %d to %d (%s)", growth, count, strings.Join(summary, ", "))
+}
+
+// lowestCounts holds the lowest counts of resources, from which their growth is measured.
+type lowestCounts map[string]int
+
+// grow records the count of a resource, and returns its growth, and whether it exceeds the max growth.
+// An exceeding count becomes the new reference, so that the same growth is reported once.
+func (l lowestCounts) grow(name string, count, max int) (int, bool) {
+	lowest, ok := l[name]
+	if !ok || count < lowest {
+		l[name] = count
+		return 0, false
+	}
+
+	growth := count - lowest
+	if max <= 0 || growth <= max {
+		return growth, false
+	}
+
+	l[name] = count
+	return growth, true
+}
+
+// goroutineRecord is a group of goroutines with the same stack and labels, in a goroutine profile.
+type goroutineRecord struct {
+	count     int
+	subsystem string
+	stack     string
+}
+
+func goroutineProfile() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseGoroutineProfile parses the goroutine profile in its text format, each record starting with the number of goroutines,
+// followed by their labels, if any, and their stack.
+func parseGoroutineProfile(profile []byte) []goroutineRecord {
+	var records []goroutineRecord
+
+	scanner := bufio.NewScanner(bytes.NewReader(profile))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var current *goroutineRecord
+	var stack []string
+	flush := func() {
+		if current != nil {
+			current.stack = strings.Join(stack, "\n")
+			records = append(records, *current)
+		}
+		current = nil
+		stack = nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if i := strings.Index(line, " @ "); i > 0 && !strings.HasPrefix(line, "#") {
+			count, err := strconv.Atoi(line[:i])
+			if err != nil {
+				continue
+			}
+			flush()
+			current = &goroutineRecord{count: count, subsystem: SubsystemOther}
+			stack = append(stack, line)
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		if strings.HasPrefix(line, "# labels: ") {
+			if match := subsystemLabelRegexp.FindStringSubmatch(line); match != nil {
+				current.subsystem = match[1]
+			}
+		}
+		if len(line) > 0 {
+			stack = append(stack, line)
+		}
+	}
+	flush()
+
+	return records
+}
+
+// fileDescriptorKind returns the kind of the target of a file descriptor: socket, pipe, anon_inode or file.
+func fileDescriptorKind(target string) string {
+	if i := strings.Index(target, ":"); i > 0 && !strings.HasPrefix(target, "/") {
+		return target[:i]
+	}
+	return "file"
+}
@@ -0,0 +1,141 @@
+package watchdog
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoroutineProfile(t *testing.T) {
+	stop := make(chan struct{})
+	var started sync.WaitGroup
+
+	Do(context.Background(), "test", func(context.Context) {
+		for i := 0; i < 3; i++ {
+			started.Add(1)
+			go func() {
+				started.Done()
+				<-stop
+			}()
+		}
+	})
+	defer close(stop)
+	started.Wait()
+
+	profile, err := goroutineProfile()
+	require.NoError(t, err)
+
+	counts := make(map[string]int)
+	for _, record := range parseGoroutineProfile(profile) {
+		counts[record.subsystem] += record.count
+		if record.subsystem == "test" {
+			assert.Contains(t, record.stack, "TestParseGoroutineProfile")
+		}
+	}
+
+	assert.Equal(t, 3, counts["test"])
+	assert.NotZero(t, counts[SubsystemOther])
+}
+
+func TestLowestCountsGrow(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		counts           []int
+		max              int
+		expectedGrowth   int
+		expectedExceeded bool
+	}{
+		{
+			desc:   "first count",
+			counts: []int{10},
+			max:    5,
+		},
+		{
+			desc:           "growth under the max",
+			counts:         []int{10, 15},
+			max:            5,
+			expectedGrowth: 5,
+		},
+		{
+			desc:             "growth over the max",
+			counts:           []int{10, 16},
+			max:              5,
+			expectedGrowth:   6,
+			expectedExceeded: true,
+		},
+		{
+			desc:             "growth from the lowest count",
+			counts:           []int{10, 4, 12},
+			max:              5,
+			expectedGrowth:   8,
+			expectedExceeded: true,
+		},
+		{
+			desc:           "growth reported once",
+			counts:         []int{10, 16, 18},
+			max:            5,
+			expectedGrowth: 2,
+		},
+		{
+			desc:           "no max",
+			counts:         []int{10, 1000},
+			expectedGrowth: 990,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			lowest := make(lowestCounts)
+			var growth int
+			var exceeded bool
+			for _, count := range test.counts {
+				growth, exceeded = lowest.grow("test", count, test.max)
+			}
+
+			assert.Equal(t, test.expectedGrowth, growth)
+			assert.Equal(t, test.expectedExceeded, exceeded)
+		})
+	}
+}
+
+func TestOpenFileDescriptors(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("counting the open file descriptors is only supported on Linux")
+	}
+
+	before, err := openFileDescriptors()
+	require.NoError(t, err)
+
+	file, err := ioutil.TempFile("", "watchdog")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	after, err := openFileDescriptors()
+	require.NoError(t, err)
+	assert.Len(t, after, len(before)+1)
+
+	require.NoError(t, file.Close())
+}
+
+func TestFileDescriptorKind(t *testing.T) {
+	testCases := map[string]string{
+		"socket:[12345]":       "socket",
+		"pipe:[12345]":         "pipe",
+		"anon_inode:[eventfd]": "anon_inode",
+		"/var/log/traefik.log": "file",
+		"/tmp/foo:bar":         "file",
+	}
+
+	for target, expected := range testCases {
+		assert.Equal(t, expected, fileDescriptorKind(target), target)
+	}
+}